@@ -0,0 +1,103 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"strconv"
+
+	"github.com/lifei6671/plaindoc/apps/server/internal/config"
+	"github.com/lifei6671/plaindoc/apps/server/internal/database"
+	"github.com/lifei6671/plaindoc/apps/server/internal/migrate"
+)
+
+const usage = `usage: migrate <command> [args]
+
+commands:
+  up           apply all pending migrations
+  down [n]     roll back the latest n migrations (default 1, "all" rolls back everything)
+  version      print the current version and dirty state
+  force <v>    set the version to v and clear the dirty flag without running scripts
+
+configuration is read the same way as the server (APP_CONFIG, DB_DSN, ...).`
+
+func main() {
+	if len(os.Args) < 2 {
+		fmt.Fprintln(os.Stderr, usage)
+		os.Exit(2)
+	}
+	if err := run(context.Background(), os.Args[1], os.Args[2:]); err != nil {
+		fmt.Fprintln(os.Stderr, "error:", err)
+		os.Exit(1)
+	}
+}
+
+func run(ctx context.Context, command string, args []string) error {
+	switch command {
+	case "up", "down", "version", "force":
+	default:
+		return fmt.Errorf("unknown command %q\n\n%s", command, usage)
+	}
+
+	cfg, err := config.LoadFromFile(os.Getenv("APP_CONFIG"))
+	if err != nil {
+		return err
+	}
+
+	db, dialect, err := database.Open(cfg)
+	if err != nil {
+		return err
+	}
+	defer db.Close()
+
+	migrator, err := migrate.New(db, dialect, cfg.DBMigrateLockTimeout)
+	if err != nil {
+		return err
+	}
+
+	switch command {
+	case "up":
+		if err := migrator.Up(ctx); err != nil {
+			return err
+		}
+	case "down":
+		steps := 1
+		if len(args) > 0 && args[0] == "all" {
+			steps = len(migrator.Migrations())
+		} else if len(args) > 0 {
+			if steps, err = strconv.Atoi(args[0]); err != nil || steps <= 0 {
+				return fmt.Errorf("down: %q is not a positive number", args[0])
+			}
+		}
+		if err := migrator.Down(ctx, steps); err != nil {
+			return err
+		}
+	case "version":
+	case "force":
+		if len(args) != 1 {
+			return errors.New("force: exactly one version is required")
+		}
+		version, err := strconv.ParseUint(args[0], 10, 64)
+		if err != nil {
+			return fmt.Errorf("force: %q is not a valid version", args[0])
+		}
+		if err := migrator.Force(ctx, uint(version)); err != nil {
+			return err
+		}
+	}
+
+	return printVersion(ctx, migrator)
+}
+
+func printVersion(ctx context.Context, migrator *migrate.Migrator) error {
+	version, dirty, err := migrator.Version(ctx)
+	if err != nil {
+		return err
+	}
+	fmt.Printf("version: %d (latest: %d)\n", version, migrator.Latest())
+	if dirty {
+		fmt.Printf("dirty: true — migration %d failed halfway; fix the schema, then run `migrate force <version>`\n", version)
+	}
+	return nil
+}
@@ -0,0 +1,87 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"net"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/lifei6671/plaindoc/apps/server/internal/config"
+	"golang.org/x/crypto/acme/autocert"
+)
+
+// listen 按配置以 HTTP、证书文件 HTTPS 或 autocert HTTPS 启动 srv，阻塞直到服务退出。
+// APP_ADDR 以 unix: 开头时监听 Unix domain socket，否则监听 TCP。
+func listen(srv *http.Server, cfg config.Config, manager *autocert.Manager) error {
+	ln, err := newListener(cfg)
+	if err != nil {
+		return err
+	}
+	if strings.HasPrefix(cfg.Addr, config.UnixAddrPrefix) {
+		srv.Handler = unixRemoteAddr(srv.Handler)
+	}
+	switch {
+	case manager != nil:
+		srv.TLSConfig = manager.TLSConfig()
+		return srv.ServeTLS(ln, "", "")
+	case cfg.TLSCertFile != "":
+		return srv.ServeTLS(ln, cfg.TLSCertFile, cfg.TLSKeyFile)
+	default:
+		return srv.Serve(ln)
+	}
+}
+
+func newListener(cfg config.Config) (net.Listener, error) {
+	path, ok := strings.CutPrefix(cfg.Addr, config.UnixAddrPrefix)
+	if !ok {
+		return net.Listen("tcp", cfg.Addr)
+	}
+	if err := removeStaleSocket(path); err != nil {
+		return nil, err
+	}
+	// net.UnixListener 关闭时会删除 socket 文件，服务正常退出后不会留下残留。
+	ln, err := net.Listen("unix", path)
+	if err != nil {
+		return nil, err
+	}
+	if err := os.Chmod(path, cfg.SocketMode); err != nil {
+		ln.Close()
+		return nil, fmt.Errorf("chmod %s: %w", path, err)
+	}
+	return ln, nil
+}
+
+// removeStaleSocket 删除上次异常退出残留的 socket 文件；仍有进程在该 socket 上监听，
+// 或路径是普通文件时返回错误，避免误删。
+func removeStaleSocket(path string) error {
+	info, err := os.Lstat(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	if info.Mode()&fs.ModeSocket == 0 {
+		return fmt.Errorf("%s exists and is not a socket", path)
+	}
+	if conn, err := net.DialTimeout("unix", path, time.Second); err == nil {
+		conn.Close()
+		return fmt.Errorf("%s is already in use by another process", path)
+	}
+	return os.Remove(path)
+}
+
+// unixRemoteAddr 为经 Unix socket 进入的请求补上回环地址：这类请求的 RemoteAddr 为空，
+// gin 无法解析客户端 IP 也不会信任反向代理传来的 X-Forwarded-For，限流会把所有请求算到同一个客户端。
+func unixRemoteAddr(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if _, _, err := net.SplitHostPort(r.RemoteAddr); err != nil {
+			r.RemoteAddr = "127.0.0.1:0"
+		}
+		next.ServeHTTP(w, r)
+	})
+}
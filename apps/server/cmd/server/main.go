@@ -1,19 +1,320 @@
 package main
 
 import (
-	"log"
+	"context"
+	"errors"
+	"log/slog"
+	"net/http"
+	"os"
+	"os/signal"
+	"sync"
+	"syscall"
+	"time"
 
+	"github.com/lifei6671/plaindoc/apps/server/internal/backup"
+	"github.com/lifei6671/plaindoc/apps/server/internal/bootstrap"
+	"github.com/lifei6671/plaindoc/apps/server/internal/cache"
+	"github.com/lifei6671/plaindoc/apps/server/internal/collab"
 	"github.com/lifei6671/plaindoc/apps/server/internal/config"
+	"github.com/lifei6671/plaindoc/apps/server/internal/database"
+	"github.com/lifei6671/plaindoc/apps/server/internal/health"
+	"github.com/lifei6671/plaindoc/apps/server/internal/jobs"
+	"github.com/lifei6671/plaindoc/apps/server/internal/logging"
+	"github.com/lifei6671/plaindoc/apps/server/internal/mailer"
+	"github.com/lifei6671/plaindoc/apps/server/internal/metrics"
+	"github.com/lifei6671/plaindoc/apps/server/internal/migrate"
+	"github.com/lifei6671/plaindoc/apps/server/internal/search"
 	"github.com/lifei6671/plaindoc/apps/server/internal/server"
+	"github.com/lifei6671/plaindoc/apps/server/internal/storage"
+	"github.com/lifei6671/plaindoc/apps/server/internal/store"
+	"github.com/lifei6671/plaindoc/apps/server/internal/views"
+	"github.com/lifei6671/plaindoc/apps/server/internal/webhook"
 )
 
+const migrateRetryInterval = 5 * time.Second
+
 func main() {
-	cfg := config.Load()
+	// 配置加载完成前先用默认级别的 JSON logger，保证启动失败的日志格式一致。
+	logger := slog.New(slog.NewJSONHandler(os.Stdout, nil))
+
+	cfg, err := config.LoadFromFile(os.Getenv("APP_CONFIG"))
+	if err != nil {
+		fatal(logger, "load config failed", err)
+	}
+	if err := cfg.Validate(); err != nil {
+		fatal(logger, "invalid config", err)
+	}
+
+	logger = logging.New(cfg)
+	slog.SetDefault(logger)
+
+	db, dialect, err := database.Open(cfg)
+	if err != nil {
+		fatal(logger, "open database failed", err)
+	}
+	defer db.Close()
+	logger.Info("database pool configured",
+		"driver", dialect,
+		"max_open_conns", cfg.DBMaxOpenConns,
+		"max_idle_conns", cfg.DBMaxIdleConns,
+		"conn_max_lifetime", cfg.DBConnMaxLifetime.String(),
+		"conn_max_idle_time", cfg.DBConnMaxIdleTime.String(),
+	)
+
+	migrator, err := migrate.New(db, dialect, cfg.DBMigrateLockTimeout)
+	if err != nil {
+		fatal(logger, "load migrations failed", err)
+	}
+	// 迁移在开始监听之前同步执行，版本对齐之前不对外提供服务。
+	if cfg.MigrateOnStart {
+		runMigrations(context.Background(), logger, migrator)
+	} else {
+		checkSchema(context.Background(), logger, migrator)
+	}
+
+	uploads, err := storage.NewLocal(cfg.UploadDir, cfg.UploadBaseURL)
+	if err != nil {
+		fatal(logger, "prepare upload directory failed", err)
+	}
+
+	// 就绪探针检查的依赖：邮件与渲染缓存不可用时服务仍能降级运行，注册为可选依赖。
+	checks := health.NewRegistry()
+	checks.Register(health.DB(db), health.Options{})
+	checks.Register(health.Migrations(migrator), health.Options{})
+	checks.Register(health.Func("storage", uploads.Ping), health.Options{})
+
+	var collector *metrics.Metrics
+	if cfg.MetricsEnabled {
+		collector = metrics.New(db)
+	}
+
+	mailTemplates, err := mailer.LoadTemplates()
+	if err != nil {
+		fatal(logger, "load mail templates failed", err)
+	}
+	var mailSender mailer.Sender = mailer.NewLogSender(logger)
+	if cfg.MailEnabled {
+		smtpSender, err := mailer.NewSMTP(mailer.SMTPConfig{
+			Host:     cfg.SMTPHost,
+			Port:     cfg.SMTPPort,
+			Username: cfg.SMTPUsername,
+			Password: cfg.SMTPPassword,
+			From:     cfg.SMTPFrom,
+		})
+		if err != nil {
+			fatal(logger, "configure smtp failed", err)
+		}
+		mailSender = smtpSender
+		checks.Register(health.Func("smtp", smtpSender.Ping), health.Options{Timeout: 5 * time.Second, Optional: true})
+	}
+	mailQueue := mailer.NewQueue(mailSender, mailTemplates, logger)
+
+	var renderCache cache.Cache
+	switch {
+	case cfg.RenderCacheTTL <= 0:
+		logger.Info("render cache disabled")
+	case cfg.RedisURL != "":
+		redisCache, err := cache.NewRedis(cfg.RedisURL)
+		if err != nil {
+			fatal(logger, "configure redis failed", err)
+		}
+		defer redisCache.Close()
+		renderCache = redisCache
+		checks.Register(health.Func("redis", redisCache.Ping), health.Options{Optional: true})
+		logger.Info("render cache configured", "backend", "redis", "ttl", cfg.RenderCacheTTL.String())
+	default:
+		renderCache = cache.NewMemory(cfg.RenderCacheEntries)
+		logger.Info("render cache configured", "backend", "memory", "entries", cfg.RenderCacheEntries, "ttl", cfg.RenderCacheTTL.String())
+	}
+
+	st := store.New(db, dialect, store.Options{SlowQueryThreshold: cfg.DBSlowQueryThreshold}, logger)
+	hub := collab.NewHub(cfg.CollabMaxPeers)
+	webhooks := webhook.NewDispatcher(st, logger)
+	viewCounter := views.New(st, logger)
+	backups, err := backup.New(st, migrator, string(dialect), backup.Options{
+		Dir:     cfg.BackupDir,
+		Keep:    cfg.BackupKeep,
+		Exclude: cfg.BackupExcludeTables,
+	}, logger)
+	if err != nil {
+		fatal(logger, "configure backups failed", err)
+	}
+	jobQueue := jobs.NewQueue(st, jobs.QueueOptions{
+		Workers:   cfg.JobWorkers,
+		Size:      cfg.JobQueueSize,
+		Dir:       cfg.JobDir,
+		Retention: cfg.JobRetention,
+	}, logger)
+	indexer := search.NewDBIndexer(st)
+	router := server.NewRouter(cfg, server.Dependencies{
+		Logger:   logger,
+		DB:       db,
+		Store:    st,
+		Indexer:  indexer,
+		Storage:  uploads,
+		Metrics:  collector,
+		Collab:   hub,
+		Mailer:   mailQueue,
+		Webhooks: webhooks,
+		Cache:    renderCache,
+		Backups:  backups,
+		Views:    viewCounter,
+		Jobs:     jobQueue,
+		Health:   checks,
+	})
+
+	// 迁移已完成，接着在后台运行 webhook 投递、浏览量写入、异步作业、定时发布、定时备份、内部链接补建与回收站清理任务。
+	// 这些任务使用单独的 ctx，关闭时等请求全部结束后再取消，并在关闭超时内等待它们退出。
+	background, cancelBackground := context.WithCancel(context.Background())
+	defer cancelBackground()
+	var tasks sync.WaitGroup
+	runTask := func(run func(ctx context.Context)) {
+		tasks.Add(1)
+		go func() {
+			defer tasks.Done()
+			run(background)
+		}()
+	}
+	if err := bootstrap.EnsureAdmin(background, st, cfg, logger); err != nil {
+		logger.Error("bootstrap admin account failed", "error", err)
+	}
+	runTask(webhooks.Run)
+	runTask(viewCounter.Run)
+	runTask(jobQueue.Run)
+	runTask(func(ctx context.Context) { jobs.PublishScheduledDocuments(ctx, st, indexer, webhooks, logger) })
+	runTask(func(ctx context.Context) { jobs.ScheduleBackups(ctx, backups, cfg.BackupSchedule, logger) })
+	runTask(func(ctx context.Context) { jobs.BackfillDocumentLinks(ctx, st, logger) })
+	runTask(func(ctx context.Context) { jobs.CleanupTrash(ctx, st, cfg.TrashRetentionDays, logger) })
 
-	router := server.NewRouter(cfg)
+	srv := &http.Server{
+		Addr:    cfg.Addr,
+		Handler: router,
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	defer stop()
+
+	manager := newAutocert(cfg, logger)
+	serveErr := make(chan error, 3)
+	go func() {
+		logger.Info("server starting", "addr", cfg.Addr, "env", cfg.Env,
+			"tls", manager != nil || cfg.TLSCertFile != "")
+		serveErr <- listen(srv, cfg, manager)
+	}()
+
+	// 开启 TLS 时可以额外监听一个 HTTP 端口，把明文请求跳转到 HTTPS。
+	var redirectSrv *http.Server
+	if cfg.HTTPRedirectAddr != "" {
+		redirectSrv = newRedirectServer(cfg, manager)
+		go func() {
+			logger.Info("https redirect server starting", "addr", cfg.HTTPRedirectAddr)
+			serveErr <- redirectSrv.ListenAndServe()
+		}()
+	}
+
+	// 指标单独监听时通常绑定内网地址，只提供 /metrics。
+	var metricsSrv *http.Server
+	if collector != nil && cfg.MetricsAddr != "" {
+		mux := http.NewServeMux()
+		mux.Handle("/metrics", collector.Handler())
+		metricsSrv = &http.Server{Addr: cfg.MetricsAddr, Handler: mux}
+		go func() {
+			logger.Info("metrics server starting", "addr", cfg.MetricsAddr)
+			serveErr <- metricsSrv.ListenAndServe()
+		}()
+	}
 
-	log.Printf("server starting on %s (env=%s)", cfg.Addr, cfg.Env)
-	if err := router.Run(cfg.Addr); err != nil {
-		log.Fatalf("server exited: %v", err)
+	select {
+	case err := <-serveErr:
+		if !errors.Is(err, http.ErrServerClosed) {
+			fatal(logger, "server exited", err)
+		}
+		return
+	case <-ctx.Done():
+	}
+	stop()
+
+	logger.Info("shutdown signal received, waiting for in-flight requests", "timeout", cfg.ShutdownTimeout.String())
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), cfg.ShutdownTimeout)
+	defer cancel()
+	if metricsSrv != nil {
+		_ = metricsSrv.Shutdown(shutdownCtx)
+	}
+	if redirectSrv != nil {
+		_ = redirectSrv.Shutdown(shutdownCtx)
+	}
+	hub.Close()
+	if err := srv.Shutdown(shutdownCtx); err != nil {
+		logger.Warn("graceful shutdown incomplete, forcing close", "error", err)
+		srv.Close()
+		return
+	}
+	// 运行中的作业与 webhook 投递在取消后记录结果再退出，不必等心跳或租约过期。
+	cancelBackground()
+	if err := wait(shutdownCtx, &tasks); err != nil {
+		logger.Warn("background tasks not stopped before shutdown", "error", err)
+	}
+	if err := viewCounter.Flush(shutdownCtx); err != nil {
+		logger.Warn("document views not flushed before shutdown", "error", err)
 	}
+	// 请求全部结束后不会再有新邮件入队，剩余的邮件在关闭超时内继续投递。
+	if err := mailQueue.Close(shutdownCtx); err != nil {
+		logger.Warn("mail queue not drained before shutdown", "error", err)
+	}
+	logger.Info("server stopped")
+}
+
+// runMigrations 重试直到迁移成功；遇到 dirty 状态或等待其他实例持有的迁移锁超时则退出进程。
+func runMigrations(ctx context.Context, logger *slog.Logger, migrator *migrate.Migrator) {
+	for {
+		err := migrator.Up(ctx)
+		if err == nil {
+			if err := migrator.Check(ctx); errors.Is(err, migrate.ErrAhead) {
+				logger.Warn("database schema is newer than this build", "error", err)
+			} else {
+				logger.Info("migrations up to date", "version", migrator.Latest())
+			}
+			return
+		}
+		if errors.Is(err, migrate.ErrDirty) || errors.Is(err, migrate.ErrLockTimeout) {
+			fatal(logger, "migrations stopped", err)
+		}
+		logger.Warn("migrations failed, will retry", "retry_in", migrateRetryInterval.String(), "error", err)
+		time.Sleep(migrateRetryInterval)
+	}
+}
+
+// checkSchema 在关闭启动迁移时校验数据库版本：落后或 dirty 时退出并提示手动迁移，比代码新时只告警。
+func checkSchema(ctx context.Context, logger *slog.Logger, migrator *migrate.Migrator) {
+	err := migrator.Check(ctx)
+	switch {
+	case err == nil:
+		logger.Info("migrations up to date", "version", migrator.Latest())
+	case errors.Is(err, migrate.ErrAhead):
+		logger.Warn("database schema is newer than this build", "error", err)
+	case errors.Is(err, migrate.ErrBehind):
+		fatal(logger, "database schema is behind, run `go run ./cmd/migrate up` or set MIGRATE_ON_START=true", err)
+	default:
+		fatal(logger, "check migrations failed", err)
+	}
+}
+
+// wait 等待 wg 归零，ctx 先结束时返回 ctx 的错误。
+func wait(ctx context.Context, wg *sync.WaitGroup) error {
+	done := make(chan struct{})
+	go func() {
+		wg.Wait()
+		close(done)
+	}()
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+func fatal(logger *slog.Logger, msg string, err error) {
+	logger.Error(msg, "error", err)
+	os.Exit(1)
 }
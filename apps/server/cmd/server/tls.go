@@ -0,0 +1,49 @@
+package main
+
+import (
+	"log/slog"
+	"net"
+	"net/http"
+
+	"github.com/lifei6671/plaindoc/apps/server/internal/config"
+	"golang.org/x/crypto/acme/autocert"
+)
+
+// newAutocert 在配置了 TLS_AUTOCERT_DOMAINS 时返回 Let's Encrypt 证书管理器，只为白名单中的域名申请证书。
+func newAutocert(cfg config.Config, logger *slog.Logger) *autocert.Manager {
+	if len(cfg.TLSAutocertDomains) == 0 {
+		return nil
+	}
+	logger.Info("autocert enabled", "domains", cfg.TLSAutocertDomains, "cache_dir", cfg.TLSAutocertCacheDir)
+	return &autocert.Manager{
+		Prompt:     autocert.AcceptTOS,
+		HostPolicy: autocert.HostWhitelist(cfg.TLSAutocertDomains...),
+		Cache:      autocert.DirCache(cfg.TLSAutocertCacheDir),
+		Email:      cfg.TLSAutocertEmail,
+	}
+}
+
+// newRedirectServer 返回监听 HTTP_REDIRECT_ADDR 的跳转服务：GET/HEAD 以 301、其他方法以 308 跳转到
+// 同一路径的 HTTPS 地址。使用 autocert 时该端口同时响应 ACME HTTP-01 校验请求。
+func newRedirectServer(cfg config.Config, manager *autocert.Manager) *http.Server {
+	_, port, _ := net.SplitHostPort(cfg.Addr)
+	var handler http.Handler = http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		host := r.Host
+		if h, _, err := net.SplitHostPort(host); err == nil {
+			host = h
+		}
+		if port != "" && port != "443" {
+			host = net.JoinHostPort(host, port)
+		}
+		status := http.StatusMovedPermanently
+		if r.Method != http.MethodGet && r.Method != http.MethodHead {
+			// 301 会让客户端把 POST 改成 GET，308 保留原方法与请求体。
+			status = http.StatusPermanentRedirect
+		}
+		http.Redirect(w, r, "https://"+host+r.URL.RequestURI(), status)
+	})
+	if manager != nil {
+		handler = manager.HTTPHandler(handler)
+	}
+	return &http.Server{Addr: cfg.HTTPRedirectAddr, Handler: handler}
+}
@@ -0,0 +1,66 @@
+package acl
+
+import (
+	"context"
+
+	"github.com/lifei6671/plaindoc/apps/server/internal/auth"
+	"github.com/lifei6671/plaindoc/apps/server/internal/store"
+)
+
+// Policy 集中决定用户对文档的读写权限：全局角色与文档级授权取并集。
+// 文档的私有设置与授权都来自其权限来源（store.ACLSource），继承父文档时即最近的不继承祖先。
+// 非管理员只能读写可访问空间（公开空间或自己加入的私有空间）中的文档，作者被移出私有空间后同样不能访问。
+type Policy struct {
+	store *store.Store
+}
+
+func New(s *store.Store) *Policy {
+	return &Policy{store: s}
+}
+
+// CanRead 判断 viewer 能否阅读 doc：公开文档人人可读，私有文档仅限管理员、作者与被授权者；
+// 草稿只有能修改文档的人可读。
+func (p *Policy) CanRead(ctx context.Context, viewer store.Viewer, doc *store.Document) (bool, error) {
+	if viewer.Role == auth.RoleAdmin {
+		return true, nil
+	}
+	if accessible, err := p.store.CanAccessSpace(ctx, viewer, doc.Space); err != nil || !accessible {
+		return false, err
+	}
+	if viewer.ID != 0 && viewer.ID == doc.AuthorID {
+		return true, nil
+	}
+	if doc.Status == store.DocStatusDraft {
+		return p.CanWrite(ctx, viewer, doc)
+	}
+	source := doc
+	if doc.ACLDocID != nil {
+		var err error
+		if source, err = p.store.GetDocument(ctx, *doc.ACLDocID); err != nil {
+			return false, err
+		}
+	}
+	if !source.IsPrivate {
+		return true, nil
+	}
+	return p.store.HasDocPermission(ctx, source.ID, viewer, store.PermissionRead)
+}
+
+// CanWrite 判断 viewer 能否修改 doc 的内容：管理员、身为作者的编辑者，或在权限来源上有 write 授权的用户与角色。
+func (p *Policy) CanWrite(ctx context.Context, viewer store.Viewer, doc *store.Document) (bool, error) {
+	if viewer.Role == auth.RoleAdmin {
+		return true, nil
+	}
+	if accessible, err := p.store.CanAccessSpace(ctx, viewer, doc.Space); err != nil || !accessible {
+		return false, err
+	}
+	if p.CanManage(viewer, doc) {
+		return true, nil
+	}
+	return p.store.HasDocPermission(ctx, store.ACLSource(doc), viewer, store.PermissionWrite)
+}
+
+// CanManage 判断 viewer 能否删除、移动文档以及调整其权限，只有管理员与身为作者的编辑者可以，不受授权影响。
+func (p *Policy) CanManage(viewer store.Viewer, doc *store.Document) bool {
+	return viewer.Role == auth.RoleAdmin || (viewer.Role == auth.RoleEditor && viewer.ID == doc.AuthorID)
+}
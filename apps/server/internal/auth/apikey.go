@@ -0,0 +1,30 @@
+package auth
+
+import "strings"
+
+// API Key 的权限范围：read 只能发起 GET/HEAD/OPTIONS 请求，write 与所属用户的权限相同。
+const (
+	APIKeyScopeRead  = "read"
+	APIKeyScopeWrite = "write"
+)
+
+// apiKeyPrefix 让 API Key 在日志与代码仓库中容易被识别，也用来和 JWT 区分。
+const apiKeyPrefix = "pdk_"
+
+// APIKeyDisplayLength 是列表中展示的 key 前缀长度，足以辨认又不泄露 key 本身。
+const APIKeyDisplayLength = len(apiKeyPrefix) + 8
+
+// NewAPIKey 生成随机 API Key，返回明文与用于落库的哈希。
+func NewAPIKey() (string, string, error) {
+	token, _, err := NewOpaqueToken()
+	if err != nil {
+		return "", "", err
+	}
+	key := apiKeyPrefix + token
+	return key, HashToken(key), nil
+}
+
+// IsAPIKey 判断凭证是否具有 API Key 的格式。
+func IsAPIKey(key string) bool {
+	return strings.HasPrefix(key, apiKeyPrefix)
+}
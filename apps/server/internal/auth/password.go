@@ -0,0 +1,50 @@
+package auth
+
+import (
+	"crypto/rand"
+	"math/big"
+	"sync"
+
+	"golang.org/x/crypto/bcrypt"
+)
+
+func HashPassword(password string) (string, error) {
+	hash, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.DefaultCost)
+	if err != nil {
+		return "", err
+	}
+	return string(hash), nil
+}
+
+// dummyHash 是与真实密码相同代价的哈希，账号不存在或没有密码时用它比对，使响应耗时与密码错误一致，避免按耗时探测账号。
+var dummyHash = sync.OnceValue(func() []byte {
+	hash, err := bcrypt.GenerateFromPassword([]byte("plaindoc-dummy-password"), bcrypt.DefaultCost)
+	if err != nil {
+		panic(err)
+	}
+	return hash
+})
+
+// CheckPassword 比对明文与哈希，hash 为空（账号不存在或仅第三方登录的账号）时始终失败，但仍付出一次 bcrypt 比对的耗时。
+func CheckPassword(hash string, password string) bool {
+	if hash == "" {
+		_ = bcrypt.CompareHashAndPassword(dummyHash(), []byte(password))
+		return false
+	}
+	return bcrypt.CompareHashAndPassword([]byte(hash), []byte(password)) == nil
+}
+
+const generatedPasswordAlphabet = "ABCDEFGHJKLMNPQRSTUVWXYZabcdefghijkmnopqrstuvwxyz23456789!@#$%^&*-_"
+
+// GeneratePassword 生成指定长度的随机强密码。
+func GeneratePassword(length int) (string, error) {
+	buf := make([]byte, length)
+	for i := range buf {
+		n, err := rand.Int(rand.Reader, big.NewInt(int64(len(generatedPasswordAlphabet))))
+		if err != nil {
+			return "", err
+		}
+		buf[i] = generatedPasswordAlphabet[n.Int64()]
+	}
+	return string(buf), nil
+}
@@ -0,0 +1,103 @@
+package auth
+
+import (
+	"bufio"
+	_ "embed"
+	"errors"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// 密码不满足策略时返回的错误，每个对应一条规则，调用方据此提示缺少的规则。
+var (
+	ErrPasswordTooShort  = errors.New("password is too short")
+	ErrPasswordTooLong   = errors.New("password is too long")
+	ErrPasswordNumeric   = errors.New("password must not be all digits")
+	ErrPasswordNoUpper   = errors.New("password must contain an uppercase letter")
+	ErrPasswordNoLower   = errors.New("password must contain a lowercase letter")
+	ErrPasswordNoDigit   = errors.New("password must contain a digit")
+	ErrPasswordNoSymbol  = errors.New("password must contain a special character")
+	ErrPasswordTooCommon = errors.New("password is too common")
+)
+
+// MaxPasswordBytes 是 bcrypt 能处理的密码长度上限，按 UTF-8 字节数计算，一个汉字占 3 个字节。
+const MaxPasswordBytes = 72
+
+//go:embed common_passwords.txt
+var commonPasswordList string
+
+// commonPasswords 是内置的常见弱密码表，键为小写形式。
+var commonPasswords = parseCommonPasswords(commonPasswordList)
+
+// PasswordPolicy 是注册与重置密码时校验的强度规则，由部署方通过 PASSWORD_* 配置放宽或收紧。
+type PasswordPolicy struct {
+	// MinLength 按字符数而不是字节数计算。
+	MinLength     int
+	RequireUpper  bool
+	RequireLower  bool
+	RequireDigit  bool
+	RequireSymbol bool
+	// RejectNumeric 拒绝全部由数字组成的密码。
+	RejectNumeric bool
+	// RejectCommon 拒绝内置弱密码表中的密码，不区分大小写。
+	RejectCommon bool
+}
+
+// ValidatePassword 按策略校验密码，依次检查长度、纯数字、字符类别与弱密码表，返回第一条不满足的规则对应的错误。
+func ValidatePassword(policy PasswordPolicy, password string) error {
+	if utf8.RuneCountInString(password) < policy.MinLength {
+		return ErrPasswordTooShort
+	}
+	if len(password) > MaxPasswordBytes {
+		return ErrPasswordTooLong
+	}
+
+	var upper, lower, digit, symbol, other bool
+	for _, r := range password {
+		switch {
+		case unicode.IsUpper(r):
+			upper = true
+		case unicode.IsLower(r):
+			lower = true
+		case unicode.IsDigit(r):
+			digit = true
+		case unicode.IsSpace(r) || unicode.IsLetter(r):
+			// 空白与没有大小写之分的文字（如汉字）不计入任何类别。
+			other = true
+		default:
+			symbol = true
+		}
+	}
+	switch {
+	case policy.RejectNumeric && digit && !upper && !lower && !symbol && !other:
+		return ErrPasswordNumeric
+	case policy.RequireUpper && !upper:
+		return ErrPasswordNoUpper
+	case policy.RequireLower && !lower:
+		return ErrPasswordNoLower
+	case policy.RequireDigit && !digit:
+		return ErrPasswordNoDigit
+	case policy.RequireSymbol && !symbol:
+		return ErrPasswordNoSymbol
+	}
+	if policy.RejectCommon {
+		if _, ok := commonPasswords[strings.ToLower(password)]; ok {
+			return ErrPasswordTooCommon
+		}
+	}
+	return nil
+}
+
+func parseCommonPasswords(list string) map[string]struct{} {
+	passwords := map[string]struct{}{}
+	scanner := bufio.NewScanner(strings.NewReader(list))
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		passwords[strings.ToLower(line)] = struct{}{}
+	}
+	return passwords
+}
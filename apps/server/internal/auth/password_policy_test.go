@@ -0,0 +1,81 @@
+package auth
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestValidatePassword(t *testing.T) {
+	defaults := PasswordPolicy{MinLength: 8, RejectNumeric: true, RejectCommon: true}
+	strict := PasswordPolicy{MinLength: 8, RequireUpper: true, RequireLower: true, RequireDigit: true, RequireSymbol: true}
+
+	tests := []struct {
+		name     string
+		policy   PasswordPolicy
+		password string
+		want     error
+	}{
+		{"empty", defaults, "", ErrPasswordTooShort},
+		{"one short", defaults, "quietfo", ErrPasswordTooShort},
+		{"min length", defaults, "quietfox", nil},
+		// 长度按字符计算：8 个汉字是 24 个字节，7 个汉字加 1 个字母也够 8 个字符。
+		{"min length in characters", defaults, "文档编辑器测试用", nil},
+		{"cjk one short", defaults, "文档编辑器测试", ErrPasswordTooShort},
+		{"cjk and ascii", defaults, "文档编辑器测试a", nil},
+		{"no min length", PasswordPolicy{}, "", nil},
+
+		{"all digits", defaults, "20241014", ErrPasswordNumeric},
+		{"full width digits", defaults, "２０２４１０１４", ErrPasswordNumeric},
+		{"digits and space", defaults, "2024 1014", nil},
+		{"digits and cjk", defaults, "2024文档1014", nil},
+		{"all digits allowed", PasswordPolicy{MinLength: 8}, "20241014", nil},
+
+		{"strict ok", strict, "Plain-Doc1", nil},
+		{"no upper", strict, "plain-doc1", ErrPasswordNoUpper},
+		{"no lower", strict, "PLAIN-DOC1", ErrPasswordNoLower},
+		{"no digit", strict, "Plain-Doc!", ErrPasswordNoDigit},
+		{"no symbol", strict, "PlainDoc12", ErrPasswordNoSymbol},
+		// 空白与汉字不属于任何类别，不能代替特殊字符。
+		{"space is not a symbol", strict, "Plain Doc1", ErrPasswordNoSymbol},
+		{"cjk is not a symbol", strict, "Plain文档Doc1", ErrPasswordNoSymbol},
+		{"cjk is not lowercase", strict, "PLAIN文档-1", ErrPasswordNoLower},
+		{"full width punctuation is a symbol", strict, "PlainDoc1！", nil},
+
+		{"common", defaults, "password", ErrPasswordTooCommon},
+		{"common ignores case", defaults, "PassWord", ErrPasswordTooCommon},
+		{"common with digit", defaults, "PASSWORD1", ErrPasswordTooCommon},
+		{"common allowed", PasswordPolicy{MinLength: 8}, "password", nil},
+		{"common numeric reported as numeric", defaults, "12345678", ErrPasswordNumeric},
+
+		{"max bytes", defaults, strings.Repeat("a", MaxPasswordBytes), nil},
+		{"one byte over", defaults, strings.Repeat("a", MaxPasswordBytes+1), ErrPasswordTooLong},
+		{"max bytes in cjk", defaults, strings.Repeat("文", MaxPasswordBytes/3), nil},
+		// 34 个字符能通过 max=72 的字符数校验，但 94 个字节超过了 bcrypt 的上限。
+		{"cjk over max bytes", strict, strings.Repeat("文", 30) + "Aa1!", ErrPasswordTooLong},
+		{"too long checked without policy", PasswordPolicy{}, strings.Repeat("a", MaxPasswordBytes+1), ErrPasswordTooLong},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := ValidatePassword(tt.policy, tt.password); !errors.Is(err, tt.want) {
+				t.Errorf("ValidatePassword(%q) = %v, want %v", tt.password, err, tt.want)
+			}
+		})
+	}
+}
+
+// 通过策略的密码都必须能被 bcrypt 哈希。
+func TestValidatePasswordHashable(t *testing.T) {
+	for _, password := range []string{
+		strings.Repeat("a", MaxPasswordBytes),
+		strings.Repeat("文", MaxPasswordBytes/3),
+		strings.Repeat("文", 23) + "Aa1",
+	} {
+		if err := ValidatePassword(PasswordPolicy{}, password); err != nil {
+			t.Fatalf("ValidatePassword(%q) = %v", password, err)
+		}
+		if _, err := HashPassword(password); err != nil {
+			t.Errorf("HashPassword(%q) = %v", password, err)
+		}
+	}
+}
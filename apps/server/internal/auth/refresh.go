@@ -0,0 +1,27 @@
+package auth
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+)
+
+// RefreshTokenCookie 是 refresh token 的 HttpOnly cookie 名，仅在 /api/v1/auth 路径下发送。
+const RefreshTokenCookie = "refresh_token"
+
+// NewOpaqueToken 生成随机不透明令牌，返回明文与用于落库的哈希。
+func NewOpaqueToken() (string, string, error) {
+	buf := make([]byte, 32)
+	if _, err := rand.Read(buf); err != nil {
+		return "", "", err
+	}
+	token := base64.RawURLEncoding.EncodeToString(buf)
+	return token, HashToken(token), nil
+}
+
+// HashToken 计算令牌的 SHA-256 十六进制摘要，数据库中只保存摘要。
+func HashToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
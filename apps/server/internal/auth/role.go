@@ -0,0 +1,17 @@
+package auth
+
+import "slices"
+
+// 全局角色：admin 管理系统与用户；editor 可以创建文档并修改自己的文档；viewer 只读。
+const (
+	RoleAdmin  = "admin"
+	RoleEditor = "editor"
+	RoleViewer = "viewer"
+)
+
+// Roles 按权限从高到低列出全部角色。
+var Roles = []string{RoleAdmin, RoleEditor, RoleViewer}
+
+func ValidRole(role string) bool {
+	return slices.Contains(Roles, role)
+}
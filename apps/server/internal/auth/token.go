@@ -0,0 +1,111 @@
+package auth
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// AccessTokenCookie 是浏览器端携带 access token 的 cookie 名。
+const AccessTokenCookie = "access_token"
+
+var (
+	ErrTokenInvalid = errors.New("token is invalid")
+	ErrTokenExpired = errors.New("token is expired")
+)
+
+// Claims 是 access token 的载荷，Subject 为用户 ID。
+type Claims struct {
+	Role string `json:"role"`
+	// SessionID 是签发时所属的会话；升级前签发的 token 没有该字段。
+	SessionID int64 `json:"sid,omitempty"`
+	jwt.RegisteredClaims
+}
+
+// UserID 从 Subject 解析用户 ID。
+func (c *Claims) UserID() int64 {
+	id, _ := strconv.ParseInt(c.Subject, 10, 64)
+	return id
+}
+
+// IssueAccessToken 使用 HS256 签发 access token。
+func IssueAccessToken(secret string, userID int64, role string, sessionID int64, ttl time.Duration) (string, time.Time, error) {
+	now := time.Now()
+	expiresAt := now.Add(ttl)
+	claims := Claims{
+		Role:      role,
+		SessionID: sessionID,
+		RegisteredClaims: jwt.RegisteredClaims{
+			Subject:   strconv.FormatInt(userID, 10),
+			IssuedAt:  jwt.NewNumericDate(now),
+			ExpiresAt: jwt.NewNumericDate(expiresAt),
+		},
+	}
+
+	token, err := jwt.NewWithClaims(jwt.SigningMethodHS256, claims).SignedString([]byte(secret))
+	if err != nil {
+		return "", time.Time{}, fmt.Errorf("sign access token: %w", err)
+	}
+	return token, expiresAt, nil
+}
+
+// ParseAccessToken 校验签名与过期时间；过期返回 ErrTokenExpired，其余失败返回 ErrTokenInvalid。
+func ParseAccessToken(secret string, token string) (*Claims, error) {
+	claims := &Claims{}
+	_, err := jwt.ParseWithClaims(token, claims, func(*jwt.Token) (any, error) {
+		return []byte(secret), nil
+	}, jwt.WithValidMethods([]string{jwt.SigningMethodHS256.Alg()}), jwt.WithExpirationRequired())
+	if errors.Is(err, jwt.ErrTokenExpired) {
+		return nil, ErrTokenExpired
+	}
+	// 两步验证的中间 token 带有 audience，不能当作 access token 使用。
+	if err != nil || claims.UserID() <= 0 || len(claims.Audience) > 0 {
+		return nil, ErrTokenInvalid
+	}
+	return claims, nil
+}
+
+// 两步验证中间 token 的用途，写入 audience。
+const (
+	// ChallengeLogin 表示密码已通过，还需提交验证码或恢复码。
+	ChallengeLogin = "2fa_login"
+	// ChallengeSetup 表示密码已通过，但账号的角色要求先完成两步验证的绑定。
+	ChallengeSetup = "2fa_setup"
+)
+
+// IssueChallengeToken 签发密码校验通过后、两步验证完成前使用的短期 token。
+func IssueChallengeToken(secret string, userID int64, purpose string, ttl time.Duration) (string, time.Time, error) {
+	now := time.Now()
+	expiresAt := now.Add(ttl)
+	claims := jwt.RegisteredClaims{
+		Subject:   strconv.FormatInt(userID, 10),
+		Audience:  jwt.ClaimStrings{purpose},
+		IssuedAt:  jwt.NewNumericDate(now),
+		ExpiresAt: jwt.NewNumericDate(expiresAt),
+	}
+
+	token, err := jwt.NewWithClaims(jwt.SigningMethodHS256, claims).SignedString([]byte(secret))
+	if err != nil {
+		return "", time.Time{}, fmt.Errorf("sign challenge token: %w", err)
+	}
+	return token, expiresAt, nil
+}
+
+// ParseChallengeToken 校验用途为 purpose 的中间 token 并返回用户 ID，错误语义与 ParseAccessToken 相同。
+func ParseChallengeToken(secret string, token string, purpose string) (int64, error) {
+	claims := &jwt.RegisteredClaims{}
+	_, err := jwt.ParseWithClaims(token, claims, func(*jwt.Token) (any, error) {
+		return []byte(secret), nil
+	}, jwt.WithValidMethods([]string{jwt.SigningMethodHS256.Alg()}), jwt.WithExpirationRequired(), jwt.WithAudience(purpose))
+	if errors.Is(err, jwt.ErrTokenExpired) {
+		return 0, ErrTokenExpired
+	}
+	id, _ := strconv.ParseInt(claims.Subject, 10, 64)
+	if err != nil || id <= 0 {
+		return 0, ErrTokenInvalid
+	}
+	return id, nil
+}
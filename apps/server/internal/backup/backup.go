@@ -0,0 +1,306 @@
+// Package backup 把数据库中的业务表导出为备份文件，并支持从备份文件整体恢复。
+//
+// 备份文件是 gzip 压缩的 JSON Lines：第一行是 Header；随后每张表先输出一行 {"table", "columns"}，
+// 再每行输出一个与列一一对应的值数组。时间值编码为 {"$time": "RFC 3339"}，其余值按 JSON 原样保存。
+package backup
+
+import (
+	"compress/gzip"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"slices"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/lifei6671/plaindoc/apps/server/internal/migrate"
+	"github.com/lifei6671/plaindoc/apps/server/internal/store"
+)
+
+const (
+	Format        = "plaindoc-backup"
+	FormatVersion = 1
+	// ContentType 与 FileExt 用于下载与保存备份文件。
+	ContentType = "application/gzip"
+	FileExt     = ".jsonl.gz"
+
+	filePrefix = "plaindoc-"
+)
+
+var (
+	ErrInvalidFile  = errors.New("not a valid backup file")
+	ErrUnknownTable = errors.New("unknown table")
+	ErrBusy         = errors.New("another backup or restore is in progress")
+)
+
+// SchemaMismatchError 表示备份文件的迁移版本与当前数据库不一致，需要先迁移到相同版本再恢复。
+type SchemaMismatchError struct {
+	Backup, Current uint
+}
+
+func (e *SchemaMismatchError) Error() string {
+	return fmt.Sprintf("backup schema version %d does not match database version %d", e.Backup, e.Current)
+}
+
+// DriverMismatchError 表示备份文件来自另一种数据库；不同数据库的布尔值与时间类型不完全兼容，不支持跨库恢复。
+type DriverMismatchError struct {
+	Backup, Current string
+}
+
+func (e *DriverMismatchError) Error() string {
+	return fmt.Sprintf("backup was made from %s and cannot be restored into %s", e.Backup, e.Current)
+}
+
+// Header 是备份文件的第一行。
+type Header struct {
+	Format        string    `json:"format"`
+	Version       int       `json:"version"`
+	Driver        string    `json:"driver"`
+	SchemaVersion uint      `json:"schema_version"`
+	CreatedAt     time.Time `json:"created_at"`
+	Tables        []string  `json:"tables"`
+}
+
+// File 是备份目录中保存的一份备份。
+type File struct {
+	Name      string    `json:"name"`
+	Size      int64     `json:"size"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// RestoreResult 汇总一次恢复写入的数据。
+type RestoreResult struct {
+	Header *Header        `json:"backup"`
+	Rows   map[string]int `json:"rows"`
+}
+
+// Options 配置备份目录、保留份数与默认排除的表。
+type Options struct {
+	Dir string
+	// Keep 是备份目录中保留的最近备份份数，保存新备份后删除更早的文件。
+	Keep int
+	// Exclude 是未指定排除列表时默认不备份的表，如审计日志这类体积大、可丢弃的表。
+	Exclude []string
+}
+
+// Service 执行备份与恢复；同一时间只允许一个备份或恢复任务运行。
+type Service struct {
+	store    *store.Store
+	migrator *migrate.Migrator
+	driver   string
+	opts     Options
+	logger   *slog.Logger
+	running  sync.Mutex
+}
+
+func New(s *store.Store, migrator *migrate.Migrator, driver string, opts Options, logger *slog.Logger) (*Service, error) {
+	if _, err := Tables(opts.Exclude); err != nil {
+		return nil, err
+	}
+	return &Service{store: s, migrator: migrator, driver: driver, opts: opts, logger: logger}, nil
+}
+
+// DefaultExclude 返回默认排除的表。
+func (s *Service) DefaultExclude() []string {
+	return s.opts.Exclude
+}
+
+// Tables 返回排除 exclude 之后参与备份的表，exclude 中有未知的表时返回 ErrUnknownTable。
+func Tables(exclude []string) ([]store.BackupTable, error) {
+	for _, name := range exclude {
+		if _, ok := store.LookupBackupTable(name); !ok {
+			return nil, fmt.Errorf("%w %q", ErrUnknownTable, name)
+		}
+	}
+	tables := make([]store.BackupTable, 0, len(store.BackupTables))
+	for _, table := range store.BackupTables {
+		if !slices.Contains(exclude, table.Name) {
+			tables = append(tables, table)
+		}
+	}
+	return tables, nil
+}
+
+// TableNames 返回全部可备份的表名，用于错误提示。
+func TableNames() []string {
+	names := make([]string, 0, len(store.BackupTables))
+	for _, table := range store.BackupTables {
+		names = append(names, table.Name)
+	}
+	return names
+}
+
+// FileName 返回 createdAt 时刻生成的备份文件名，按文件名排序即按时间排序。
+func FileName(createdAt time.Time) string {
+	return filePrefix + createdAt.UTC().Format("20060102T150405.000") + "Z" + FileExt
+}
+
+// Write 把排除 exclude 之后的全部表写入 w。
+func (s *Service) Write(ctx context.Context, w io.Writer, exclude []string) (*Header, error) {
+	if !s.running.TryLock() {
+		return nil, ErrBusy
+	}
+	defer s.running.Unlock()
+	return s.write(ctx, w, exclude, time.Now().UTC())
+}
+
+func (s *Service) write(ctx context.Context, w io.Writer, exclude []string, now time.Time) (*Header, error) {
+	tables, err := Tables(exclude)
+	if err != nil {
+		return nil, err
+	}
+	version, dirty, err := s.migrator.Version(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if dirty {
+		return nil, fmt.Errorf("database schema version %d is dirty", version)
+	}
+	header := &Header{
+		Format:        Format,
+		Version:       FormatVersion,
+		Driver:        s.driver,
+		SchemaVersion: version,
+		CreatedAt:     now,
+	}
+	for _, table := range tables {
+		header.Tables = append(header.Tables, table.Name)
+	}
+
+	compressed := gzip.NewWriter(w)
+	enc := &encoder{json: json.NewEncoder(compressed)}
+	if err := enc.json.Encode(header); err != nil {
+		return nil, err
+	}
+	if err := s.store.DumpTables(ctx, tables, enc); err != nil {
+		return nil, err
+	}
+	return header, compressed.Close()
+}
+
+// Save 把备份保存到备份目录，并删除超出保留份数的旧备份。
+func (s *Service) Save(ctx context.Context, exclude []string) (*File, error) {
+	if !s.running.TryLock() {
+		return nil, ErrBusy
+	}
+	defer s.running.Unlock()
+
+	if err := os.MkdirAll(s.opts.Dir, 0o750); err != nil {
+		return nil, err
+	}
+	now := time.Now().UTC()
+	name := FileName(now)
+	// 先写入临时文件再改名，备份中途失败时不会留下不完整的备份。
+	tmp, err := os.CreateTemp(s.opts.Dir, ".tmp-"+filePrefix+"*")
+	if err != nil {
+		return nil, err
+	}
+	defer os.Remove(tmp.Name())
+	if _, err := s.write(ctx, tmp, exclude, now); err != nil {
+		tmp.Close()
+		return nil, err
+	}
+	if err := tmp.Close(); err != nil {
+		return nil, err
+	}
+	path := filepath.Join(s.opts.Dir, name)
+	if err := os.Rename(tmp.Name(), path); err != nil {
+		return nil, err
+	}
+	info, err := os.Stat(path)
+	if err != nil {
+		return nil, err
+	}
+	s.prune()
+	return &File{Name: name, Size: info.Size(), CreatedAt: now}, nil
+}
+
+// List 按时间倒序返回备份目录中的备份。
+func (s *Service) List() ([]File, error) {
+	entries, err := os.ReadDir(s.opts.Dir)
+	if errors.Is(err, os.ErrNotExist) {
+		return []File{}, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	files := []File{}
+	for _, entry := range entries {
+		name := entry.Name()
+		if entry.IsDir() || !strings.HasPrefix(name, filePrefix) || !strings.HasSuffix(name, FileExt) {
+			continue
+		}
+		info, err := entry.Info()
+		if err != nil {
+			continue
+		}
+		files = append(files, File{Name: name, Size: info.Size(), CreatedAt: info.ModTime().UTC()})
+	}
+	sort.Slice(files, func(i, j int) bool { return files[i].Name > files[j].Name })
+	return files, nil
+}
+
+// prune 删除最近 Keep 份之外的备份，失败只记录日志。
+func (s *Service) prune() {
+	if s.opts.Keep <= 0 {
+		return
+	}
+	files, err := s.List()
+	if err != nil {
+		s.logger.Error("list backups failed", "dir", s.opts.Dir, "error", err)
+		return
+	}
+	for _, file := range files[min(len(files), s.opts.Keep):] {
+		if err := os.Remove(filepath.Join(s.opts.Dir, file.Name)); err != nil {
+			s.logger.Error("remove old backup failed", "file", file.Name, "error", err)
+			continue
+		}
+		s.logger.Info("removed old backup", "file", file.Name)
+	}
+}
+
+// Restore 用 r 中的备份替换数据库中的全部业务数据。备份必须来自同一种数据库且迁移版本与当前一致；
+// 恢复在一个事务中完成，失败时数据库保持原样。
+func (s *Service) Restore(ctx context.Context, r io.Reader) (*RestoreResult, error) {
+	if !s.running.TryLock() {
+		return nil, ErrBusy
+	}
+	defer s.running.Unlock()
+
+	compressed, err := gzip.NewReader(r)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidFile, err)
+	}
+	defer compressed.Close()
+	dec := newDecoder(compressed)
+	header, err := dec.header()
+	if err != nil {
+		return nil, err
+	}
+	if header.Driver != s.driver {
+		return nil, &DriverMismatchError{Backup: header.Driver, Current: s.driver}
+	}
+	version, _, err := s.migrator.Version(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if header.SchemaVersion != version {
+		return nil, &SchemaMismatchError{Backup: header.SchemaVersion, Current: version}
+	}
+
+	rows, err := s.store.RestoreTables(ctx, dec)
+	if err != nil {
+		if errors.Is(err, store.ErrInvalidBackupData) {
+			return nil, fmt.Errorf("%w: %v", ErrInvalidFile, err)
+		}
+		return nil, err
+	}
+	return &RestoreResult{Header: header, Rows: rows}, nil
+}
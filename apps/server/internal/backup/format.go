@@ -0,0 +1,176 @@
+package backup
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"time"
+)
+
+// maxLine 是备份文件单行的上限，一行对应一条记录，需要容纳最大的文档正文。
+const maxLine = 64 << 20
+
+// tableLine 是每张表数据之前的表头行。
+type tableLine struct {
+	Table   string   `json:"table"`
+	Columns []string `json:"columns"`
+}
+
+// timeValue 是时间值的编码，与普通字符串区分，恢复时按时间写入。
+type timeValue struct {
+	Time string `json:"$time"`
+}
+
+// encoder 把 store.DumpTables 读出的数据逐行编码为 JSON。
+type encoder struct {
+	json *json.Encoder
+	row  []any
+}
+
+func (e *encoder) Table(name string, columns []string) error {
+	return e.json.Encode(tableLine{Table: name, Columns: columns})
+}
+
+func (e *encoder) Row(values []any) error {
+	e.row = e.row[:0]
+	for _, value := range values {
+		if t, ok := value.(time.Time); ok {
+			value = timeValue{Time: t.UTC().Format(time.RFC3339Nano)}
+		}
+		e.row = append(e.row, value)
+	}
+	return e.json.Encode(e.row)
+}
+
+// decoder 逐行读取备份文件，实现 store.TableSource。
+type decoder struct {
+	lines *bufio.Scanner
+	// pending 是 NextRow 读到的下一张表的表头，留给下一次 NextTable。
+	pending *tableLine
+}
+
+func newDecoder(r io.Reader) *decoder {
+	lines := bufio.NewScanner(r)
+	lines.Buffer(make([]byte, 0, 64<<10), maxLine)
+	return &decoder{lines: lines}
+}
+
+// next 返回下一个非空行，文件结束时返回 io.EOF。
+func (d *decoder) next() ([]byte, error) {
+	for d.lines.Scan() {
+		if line := bytes.TrimSpace(d.lines.Bytes()); len(line) > 0 {
+			return line, nil
+		}
+	}
+	if err := d.lines.Err(); err != nil {
+		return nil, invalid(err)
+	}
+	return nil, io.EOF
+}
+
+func (d *decoder) header() (*Header, error) {
+	line, err := d.next()
+	if err != nil {
+		return nil, invalid(err)
+	}
+	var header Header
+	if err := json.Unmarshal(line, &header); err != nil || header.Format != Format {
+		return nil, ErrInvalidFile
+	}
+	if header.Version != FormatVersion {
+		return nil, fmt.Errorf("%w: unsupported format version %d", ErrInvalidFile, header.Version)
+	}
+	return &header, nil
+}
+
+func (d *decoder) NextTable() (string, []string, error) {
+	table := d.pending
+	d.pending = nil
+	if table == nil {
+		line, err := d.next()
+		if err != nil {
+			return "", nil, err
+		}
+		if table, err = parseTable(line); err != nil {
+			return "", nil, err
+		}
+	}
+	return table.Table, table.Columns, nil
+}
+
+func (d *decoder) NextRow() ([]any, error) {
+	if d.pending != nil {
+		return nil, io.EOF
+	}
+	line, err := d.next()
+	if err != nil {
+		return nil, err
+	}
+	if line[0] == '{' {
+		if d.pending, err = parseTable(line); err != nil {
+			return nil, err
+		}
+		return nil, io.EOF
+	}
+
+	dec := json.NewDecoder(bytes.NewReader(line))
+	dec.UseNumber()
+	var raw []any
+	if err := dec.Decode(&raw); err != nil {
+		return nil, invalid(err)
+	}
+	values := make([]any, len(raw))
+	for i, value := range raw {
+		if values[i], err = decodeValue(value); err != nil {
+			return nil, err
+		}
+	}
+	return values, nil
+}
+
+func parseTable(line []byte) (*tableLine, error) {
+	var table tableLine
+	if line[0] != '{' || json.Unmarshal(line, &table) != nil || table.Table == "" || len(table.Columns) == 0 {
+		return nil, fmt.Errorf("%w: expected a table header", ErrInvalidFile)
+	}
+	return &table, nil
+}
+
+// decodeValue 把 JSON 值还原为可以直接写入数据库的值：整数为 int64，其余数字为 float64。
+func decodeValue(value any) (any, error) {
+	switch v := value.(type) {
+	case json.Number:
+		if n, err := v.Int64(); err == nil {
+			return n, nil
+		}
+		f, err := v.Float64()
+		if err != nil {
+			return nil, invalid(err)
+		}
+		return f, nil
+	case map[string]any:
+		s, ok := v["$time"].(string)
+		if !ok || len(v) != 1 {
+			return nil, fmt.Errorf("%w: unexpected object value", ErrInvalidFile)
+		}
+		t, err := time.Parse(time.RFC3339Nano, s)
+		if err != nil {
+			return nil, invalid(err)
+		}
+		return t, nil
+	case []any:
+		return nil, fmt.Errorf("%w: unexpected array value", ErrInvalidFile)
+	default:
+		return v, nil
+	}
+}
+
+func invalid(err error) error {
+	if errors.Is(err, io.EOF) || errors.Is(err, io.ErrUnexpectedEOF) {
+		return fmt.Errorf("%w: unexpected end of file", ErrInvalidFile)
+	}
+	return fmt.Errorf("%w: %v", ErrInvalidFile, err)
+}
@@ -0,0 +1,87 @@
+package bootstrap
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"time"
+
+	"github.com/lifei6671/plaindoc/apps/server/internal/auth"
+	"github.com/lifei6671/plaindoc/apps/server/internal/config"
+	"github.com/lifei6671/plaindoc/apps/server/internal/store"
+)
+
+const (
+	defaultAdminEmail       = "admin@plaindoc.local"
+	defaultAdminName        = "Administrator"
+	generatedPasswordLength = 20
+)
+
+// EnsureAdmin 保证系统中存在可登录的管理员，重复执行是幂等的：
+//   - 未设置 ADMIN_EMAIL 且已有管理员时不做任何事；
+//   - 目标邮箱的账号已存在时不覆盖，除非 ADMIN_FORCE_RESET=true；
+//   - 未设置 ADMIN_PASSWORD 时随机生成强密码，并只在本次启动日志中打印一次。
+func EnsureAdmin(ctx context.Context, s *store.Store, cfg config.Config, logger *slog.Logger) error {
+	email := cfg.AdminEmail
+	if email == "" {
+		count, err := s.CountUsersByRole(ctx, auth.RoleAdmin)
+		if err != nil {
+			return err
+		}
+		if count > 0 {
+			if cfg.AdminForceReset {
+				logger.Warn("ADMIN_FORCE_RESET ignored: set ADMIN_EMAIL to choose which account to reset")
+			}
+			return nil
+		}
+		email = defaultAdminEmail
+	}
+
+	existing, err := s.GetUserByEmail(ctx, email)
+	if err != nil && !errors.Is(err, store.ErrNotFound) {
+		return err
+	}
+	if existing != nil && !cfg.AdminForceReset {
+		logger.Info("admin account already exists, skip bootstrap", "email", existing.Email)
+		return nil
+	}
+
+	password, generated := cfg.AdminPassword, false
+	if password == "" {
+		if password, err = auth.GeneratePassword(generatedPasswordLength); err != nil {
+			return err
+		}
+		generated = true
+	}
+	hash, err := auth.HashPassword(password)
+	if err != nil {
+		return err
+	}
+
+	if existing != nil {
+		if err := s.ResetUserCredentials(ctx, existing.ID, hash, auth.RoleAdmin); err != nil {
+			return err
+		}
+		if err := s.RevokeUserRefreshTokens(ctx, existing.ID, store.RevokeLogout); err != nil {
+			return err
+		}
+		// 同时关闭两步验证，丢失设备与恢复码的管理员可以借此重新登录。
+		if err := s.DisableTOTP(ctx, existing.ID); err != nil {
+			return err
+		}
+		logger.Warn("admin account credentials reset by ADMIN_FORCE_RESET", "email", existing.Email)
+	} else {
+		now := time.Now().UTC()
+		user := &store.User{Email: email, EmailVerifiedAt: &now, Name: defaultAdminName, Role: auth.RoleAdmin, PasswordHash: hash}
+		if err := s.CreateUser(ctx, user); err != nil {
+			return err
+		}
+		logger.Warn("admin account created", "email", user.Email)
+	}
+
+	if generated {
+		logger.Warn("!!! GENERATED ADMIN PASSWORD — shown only once, log in and change it immediately !!!",
+			"email", store.NormalizeEmail(email), "password", password)
+	}
+	return nil
+}
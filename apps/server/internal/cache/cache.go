@@ -0,0 +1,52 @@
+// Package cache 提供可替换的键值缓存：进程内 LRU 适合单实例，Redis 可在多实例间共享。
+// 缓存只用于加速，调用方应在缓存出错时回退到直接计算，而不是让请求失败。
+package cache
+
+import (
+	"context"
+	"time"
+)
+
+// Cache 是字节值的键值缓存，可并发使用。ttl<=0 表示不过期（仍可能被容量淘汰）。
+type Cache interface {
+	// Get 返回 key 对应的值；不存在或已过期时第二个返回值为 false。
+	Get(ctx context.Context, key string) ([]byte, bool, error)
+	Set(ctx context.Context, key string, value []byte, ttl time.Duration) error
+	Delete(ctx context.Context, key string) error
+}
+
+// 查询结果，用于命中率统计。
+const (
+	ResultHit   = "hit"
+	ResultMiss  = "miss"
+	ResultError = "error"
+)
+
+// Observer 接收每次 Get 的结果，metrics.Metrics 实现了该接口。
+type Observer interface {
+	CacheLookup(name string, result string)
+}
+
+// Observe 包装 c，把每次 Get 的结果以 name 为标签报告给 observer。
+func Observe(c Cache, name string, observer Observer) Cache {
+	return &observed{Cache: c, name: name, observer: observer}
+}
+
+type observed struct {
+	Cache
+	name     string
+	observer Observer
+}
+
+func (o *observed) Get(ctx context.Context, key string) ([]byte, bool, error) {
+	value, ok, err := o.Cache.Get(ctx, key)
+	switch {
+	case err != nil:
+		o.observer.CacheLookup(o.name, ResultError)
+	case ok:
+		o.observer.CacheLookup(o.name, ResultHit)
+	default:
+		o.observer.CacheLookup(o.name, ResultMiss)
+	}
+	return value, ok, err
+}
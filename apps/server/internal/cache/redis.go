@@ -0,0 +1,58 @@
+package cache
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// redisKeyPrefix 隔离与其他应用共用的 Redis 实例中的键。
+const redisKeyPrefix = "plaindoc:"
+
+// Redis 把缓存保存在 Redis 中，多个实例共享同一份缓存；淘汰策略由 Redis 的 maxmemory-policy 决定。
+type Redis struct {
+	client *redis.Client
+}
+
+// NewRedis 按 redis://[:password@]host:port/db 形式的地址创建客户端，不会立即建连。
+func NewRedis(rawURL string) (*Redis, error) {
+	options, err := redis.ParseURL(rawURL)
+	if err != nil {
+		return nil, fmt.Errorf("parse REDIS_URL: %w", err)
+	}
+	return &Redis{client: redis.NewClient(options)}, nil
+}
+
+func (r *Redis) Get(ctx context.Context, key string) ([]byte, bool, error) {
+	value, err := r.client.Get(ctx, redisKeyPrefix+key).Bytes()
+	if errors.Is(err, redis.Nil) {
+		return nil, false, nil
+	}
+	if err != nil {
+		return nil, false, err
+	}
+	return value, true, nil
+}
+
+func (r *Redis) Set(ctx context.Context, key string, value []byte, ttl time.Duration) error {
+	if ttl < 0 {
+		ttl = 0
+	}
+	return r.client.Set(ctx, redisKeyPrefix+key, value, ttl).Err()
+}
+
+func (r *Redis) Delete(ctx context.Context, key string) error {
+	return r.client.Del(ctx, redisKeyPrefix+key).Err()
+}
+
+// Ping 探测 Redis 是否可用。
+func (r *Redis) Ping(ctx context.Context) error {
+	return r.client.Ping(ctx).Err()
+}
+
+func (r *Redis) Close() error {
+	return r.client.Close()
+}
@@ -0,0 +1,126 @@
+package collab
+
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/gorilla/websocket"
+)
+
+const (
+	writeWait  = 10 * time.Second
+	pongWait   = 60 * time.Second
+	pingPeriod = pongWait * 9 / 10
+	// maxMessageSize 限制单条消息大小，内容变更会携带全文。
+	maxMessageSize = 4 << 20
+	// sendBuffer 写满说明客户端长时间读不动，直接断开而不是阻塞整个房间。
+	sendBuffer = 64
+)
+
+const (
+	typeWelcome = "welcome"
+	typeJoin    = "join"
+	typeLeave   = "leave"
+	typeCursor  = "cursor"
+	typeUpdate  = "update"
+	typeError   = "error"
+)
+
+// message 是收发双方共用的消息格式，按 Type 使用其中的部分字段。
+type message struct {
+	Type     string          `json:"type"`
+	ClientID string          `json:"client_id,omitempty"`
+	Peer     *presence       `json:"peer,omitempty"`
+	Peers    []presence      `json:"peers,omitempty"`
+	Seq      int64           `json:"seq,omitempty"`
+	Content  *string         `json:"content,omitempty"`
+	Cursor   json.RawMessage `json:"cursor,omitempty"`
+	Code     string          `json:"code,omitempty"`
+	Message  string          `json:"message,omitempty"`
+}
+
+// presence 是在线列表中的一项；同一用户多个标签页各自有独立的 client_id。
+type presence struct {
+	ClientID string `json:"client_id"`
+	UserID   int64  `json:"user_id"`
+	Name     string `json:"name"`
+	CanWrite bool   `json:"can_write"`
+}
+
+type client struct {
+	id    string
+	peer  Peer
+	docID int64
+	conn  *websocket.Conn
+	// send 只在持有 Hub.mu 时写入与关闭。
+	send chan []byte
+}
+
+func (c *client) presence() *presence {
+	return &presence{ClientID: c.id, UserID: c.peer.UserID, Name: c.peer.Name, CanWrite: c.peer.CanWrite}
+}
+
+// deliver 非阻塞地投递消息，缓冲区已满时断开连接；调用方需持有 Hub.mu。
+func (c *client) deliver(data []byte) {
+	select {
+	case c.send <- data:
+	default:
+		// 关闭帧的写入可能阻塞到 writeWait，不能在持有 Hub.mu 时同步等待。
+		go c.closeWith(websocket.ClosePolicyViolation, "client too slow")
+	}
+}
+
+// closeWith 发送关闭帧并断开底层连接，readPump 随之退出；WriteControl 与 Close 可与其他写操作并发调用。
+func (c *client) closeWith(code int, reason string) {
+	_ = c.conn.WriteControl(websocket.CloseMessage, websocket.FormatCloseMessage(code, reason), time.Now().Add(writeWait))
+	_ = c.conn.Close()
+}
+
+func (c *client) readPump(h *Hub) {
+	c.conn.SetReadLimit(maxMessageSize)
+	_ = c.conn.SetReadDeadline(time.Now().Add(pongWait))
+	c.conn.SetPongHandler(func(string) error {
+		return c.conn.SetReadDeadline(time.Now().Add(pongWait))
+	})
+	for {
+		_, data, err := c.conn.ReadMessage()
+		if err != nil {
+			return
+		}
+		var msg message
+		if err := json.Unmarshal(data, &msg); err != nil {
+			h.mu.Lock()
+			c.deliver(encode(message{Type: typeError, Code: "invalid_message", Message: "message must be a JSON object"}))
+			h.mu.Unlock()
+			continue
+		}
+		h.handle(c, msg)
+	}
+}
+
+// writePump 是唯一写数据帧的 goroutine，同时定期发送 ping 保活；send 被关闭时退出。
+func (c *client) writePump() {
+	ticker := time.NewTicker(pingPeriod)
+	defer func() {
+		ticker.Stop()
+		_ = c.conn.Close()
+	}()
+	for {
+		select {
+		case data, ok := <-c.send:
+			_ = c.conn.SetWriteDeadline(time.Now().Add(writeWait))
+			if !ok {
+				_ = c.conn.WriteMessage(websocket.CloseMessage, websocket.FormatCloseMessage(websocket.CloseNormalClosure, ""))
+				return
+			}
+			if err := c.conn.WriteMessage(websocket.TextMessage, data); err != nil {
+				return
+			}
+		case <-ticker.C:
+			_ = c.conn.SetWriteDeadline(time.Now().Add(writeWait))
+			if err := c.conn.WriteMessage(websocket.PingMessage, nil); err != nil {
+				return
+			}
+		}
+	}
+}
@@ -0,0 +1,178 @@
+// Package collab 实现文档的实时协作：同一文档的连接加入同一个房间，
+// 服务端转发光标与内容变更并维护在线用户列表。内容冲突采用 last-write-wins，
+// 以服务端收到的顺序为准，持久化仍由客户端调用 PUT /docs/:id 完成。
+package collab
+
+import (
+	"encoding/json"
+	"errors"
+	"strconv"
+	"sync"
+	"sync/atomic"
+
+	"github.com/gorilla/websocket"
+)
+
+// ErrRoomFull 表示文档房间已达人数上限。
+var ErrRoomFull = errors.New("collaboration room is full")
+
+// Peer 是加入房间的用户；CanWrite 为 false 时只能查看与发送光标，内容变更会被拒绝。
+type Peer struct {
+	UserID   int64
+	Name     string
+	CanWrite bool
+}
+
+// Hub 管理所有文档房间的生命周期：第一个连接加入时创建房间，最后一个连接离开时销毁。
+type Hub struct {
+	maxPeers int
+	nextID   atomic.Int64
+
+	mu     sync.Mutex
+	rooms  map[int64]*room
+	closed bool
+}
+
+// room 的字段都由 Hub.mu 保护。
+type room struct {
+	clients map[*client]struct{}
+	// content 是本次协作会话中最后一次收到的内容，新加入者据此同步；nil 表示还没有人修改。
+	content *string
+	seq     int64
+}
+
+func NewHub(maxPeers int) *Hub {
+	return &Hub{maxPeers: maxPeers, rooms: map[int64]*room{}}
+}
+
+// Available 判断文档房间是否还能加入新连接。
+func (h *Hub) Available(docID int64) bool {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	r, ok := h.rooms[docID]
+	return !ok || h.maxPeers <= 0 || len(r.clients) < h.maxPeers
+}
+
+// Serve 让已完成握手的连接加入 docID 的房间并阻塞到连接断开；房间已满时返回 ErrRoomFull 并关闭连接。
+func (h *Hub) Serve(conn *websocket.Conn, docID int64, peer Peer) error {
+	c := &client{
+		id:    "c" + strconv.FormatInt(h.nextID.Add(1), 10),
+		peer:  peer,
+		docID: docID,
+		conn:  conn,
+		send:  make(chan []byte, sendBuffer),
+	}
+	if err := h.join(c); err != nil {
+		c.closeWith(websocket.ClosePolicyViolation, err.Error())
+		return err
+	}
+	go c.writePump()
+	c.readPump(h)
+	h.leave(c)
+	return nil
+}
+
+// Close 断开所有连接，用于服务关闭；http.Server.Shutdown 不会等待已升级的 WebSocket 连接。
+func (h *Hub) Close() {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.closed = true
+	for _, r := range h.rooms {
+		for c := range r.clients {
+			c.closeWith(websocket.CloseGoingAway, "server shutting down")
+		}
+	}
+}
+
+func (h *Hub) join(c *client) error {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if h.closed {
+		return errors.New("server shutting down")
+	}
+	r, ok := h.rooms[c.docID]
+	if !ok {
+		r = &room{clients: map[*client]struct{}{}}
+		h.rooms[c.docID] = r
+	}
+	if h.maxPeers > 0 && len(r.clients) >= h.maxPeers {
+		return ErrRoomFull
+	}
+	r.clients[c] = struct{}{}
+
+	welcome := message{Type: typeWelcome, ClientID: c.id, Peers: r.presence(), Seq: r.seq, Content: r.content}
+	c.deliver(encode(welcome))
+	h.broadcast(r, c, message{Type: typeJoin, Peer: c.presence()})
+	return nil
+}
+
+func (h *Hub) leave(c *client) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	r, ok := h.rooms[c.docID]
+	if !ok {
+		return
+	}
+	if _, ok := r.clients[c]; !ok {
+		return
+	}
+	delete(r.clients, c)
+	close(c.send)
+	if len(r.clients) == 0 {
+		delete(h.rooms, c.docID)
+		return
+	}
+	h.broadcast(r, nil, message{Type: typeLeave, Peer: c.presence()})
+}
+
+// handle 处理客户端发来的一条消息。
+func (h *Hub) handle(c *client, msg message) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	r, ok := h.rooms[c.docID]
+	if !ok {
+		return
+	}
+	switch msg.Type {
+	case typeCursor:
+		h.broadcast(r, c, message{Type: typeCursor, Peer: c.presence(), Cursor: msg.Cursor})
+	case typeUpdate:
+		if !c.peer.CanWrite {
+			c.deliver(encode(message{Type: typeError, Code: "forbidden", Message: "you are not allowed to modify this document"}))
+			return
+		}
+		if msg.Content == nil {
+			c.deliver(encode(message{Type: typeError, Code: "invalid_message", Message: "update requires content"}))
+			return
+		}
+		r.seq++
+		r.content = msg.Content
+		// 发送者也会收到带 seq 的回执，用来确认自己的修改在服务端的先后顺序。
+		h.broadcast(r, nil, message{Type: typeUpdate, Peer: c.presence(), Seq: r.seq, Content: msg.Content})
+	default:
+		c.deliver(encode(message{Type: typeError, Code: "invalid_message", Message: "unsupported message type"}))
+	}
+}
+
+// broadcast 把消息发给房间内除 except 以外的所有连接，调用方需持有 h.mu。
+func (h *Hub) broadcast(r *room, except *client, msg message) {
+	data := encode(msg)
+	for c := range r.clients {
+		if c != except {
+			c.deliver(data)
+		}
+	}
+}
+
+func (r *room) presence() []presence {
+	peers := make([]presence, 0, len(r.clients))
+	for c := range r.clients {
+		peers = append(peers, *c.presence())
+	}
+	return peers
+}
+
+func encode(msg message) []byte {
+	data, _ := json.Marshal(msg)
+	return data
+}
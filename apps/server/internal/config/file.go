@@ -0,0 +1,73 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/goccy/go-yaml"
+	"github.com/pelletier/go-toml/v2"
+)
+
+// values 是配置文件展开后的键值：嵌套键以下划线拼接并转小写，
+// 例如 `db: {dsn: ...}` 与 `db_dsn: ...` 都对应环境变量 DB_DSN。
+type values map[string]string
+
+// LoadFromFile 读取 YAML/TOML 配置文件，环境变量优先级高于文件中的同名字段。
+// 文件不存在时回退到纯环境变量配置；文件存在但无法解析时返回错误。
+func LoadFromFile(path string) (Config, error) {
+	if path == "" {
+		return Load(), nil
+	}
+
+	data, err := os.ReadFile(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return Load(), nil
+	}
+	if err != nil {
+		return Config{}, fmt.Errorf("read config file %s: %w", path, err)
+	}
+
+	raw := map[string]any{}
+	switch ext := strings.ToLower(filepath.Ext(path)); ext {
+	case ".yaml", ".yml":
+		err = yaml.Unmarshal(data, &raw)
+	case ".toml":
+		err = toml.Unmarshal(data, &raw)
+	default:
+		return Config{}, fmt.Errorf("config file %s: unsupported format %q (want .yaml, .yml or .toml)", path, ext)
+	}
+	if err != nil {
+		return Config{}, fmt.Errorf("parse config file %s: %w", path, err)
+	}
+
+	file := values{}
+	flatten(file, "", raw)
+	return load(file), nil
+}
+
+func flatten(dst values, prefix string, raw map[string]any) {
+	for key, value := range raw {
+		name := strings.ToLower(key)
+		if prefix != "" {
+			name = prefix + "_" + name
+		}
+
+		switch typed := value.(type) {
+		case map[string]any:
+			flatten(dst, name, typed)
+		case []any:
+			items := make([]string, 0, len(typed))
+			for _, item := range typed {
+				items = append(items, fmt.Sprint(item))
+			}
+			dst[name] = strings.Join(items, ",")
+		case nil:
+		default:
+			dst[name] = fmt.Sprint(typed)
+		}
+	}
+}
@@ -0,0 +1,320 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"net/mail"
+	"net/url"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/lifei6671/plaindoc/apps/server/internal/i18n"
+	"github.com/robfig/cron/v3"
+)
+
+var (
+	validEnvs      = []string{"development", "production", "test"}
+	validLogLevels = []string{"debug", "info", "warn", "error"}
+	validDBDrivers = []string{"mysql", "postgres", "sqlite"}
+)
+
+// Validate 校验启动必需的配置项，一次性返回所有不合法字段，便于集中修改。
+func (c Config) Validate() error {
+	errs := append([]error(nil), c.errs...)
+
+	if !slices.Contains(validEnvs, c.Env) {
+		errs = append(errs, fmt.Errorf("APP_ENV: %q is invalid, must be one of %v", c.Env, validEnvs))
+	}
+
+	if path, ok := strings.CutPrefix(c.Addr, UnixAddrPrefix); ok {
+		if path == "" {
+			errs = append(errs, errors.New("APP_ADDR: unix socket path must not be empty"))
+		}
+	} else if err := validateAddr(c.Addr); err != nil {
+		errs = append(errs, fmt.Errorf("APP_ADDR: %w", err))
+	}
+
+	if len(c.WebOrigins) == 0 {
+		errs = append(errs, errors.New("WEB_ORIGIN: at least one origin is required"))
+	}
+	for _, origin := range c.WebOrigins {
+		if err := validateOrigin(origin); err != nil {
+			errs = append(errs, fmt.Errorf("WEB_ORIGIN: %w", err))
+		}
+	}
+
+	for _, proxy := range c.TrustedProxies {
+		if !validProxy(proxy) {
+			errs = append(errs, fmt.Errorf("TRUSTED_PROXIES: %q is not an IP address or CIDR", proxy))
+		}
+	}
+
+	if c.LogLevel != "" && !slices.Contains(validLogLevels, strings.ToLower(c.LogLevel)) {
+		errs = append(errs, fmt.Errorf("LOG_LEVEL: %q is invalid, must be one of %v", c.LogLevel, validLogLevels))
+	}
+
+	if !i18n.Supported(c.DefaultLanguage) {
+		errs = append(errs, fmt.Errorf("DEFAULT_LANGUAGE: %q has no message catalog", c.DefaultLanguage))
+	}
+
+	if !slices.Contains(validDBDrivers, c.DBDriver) {
+		errs = append(errs, fmt.Errorf("DB_DRIVER: %q is invalid, must be one of %v", c.DBDriver, validDBDrivers))
+	}
+	if c.DBDSN == "" {
+		errs = append(errs, errors.New("DB_DSN: is required"))
+	}
+	if c.DBMaxOpenConns <= 0 {
+		errs = append(errs, fmt.Errorf("DB_MAX_OPEN_CONNS: must be positive, got %d", c.DBMaxOpenConns))
+	}
+	if c.DBMaxIdleConns < 0 || c.DBMaxIdleConns > c.DBMaxOpenConns {
+		errs = append(errs, fmt.Errorf("DB_MAX_IDLE_CONNS: must be between 0 and DB_MAX_OPEN_CONNS (%d), got %d", c.DBMaxOpenConns, c.DBMaxIdleConns))
+	}
+	if c.DBConnMaxLifetime < 0 {
+		errs = append(errs, fmt.Errorf("DB_CONN_MAX_LIFETIME: must not be negative, got %s", c.DBConnMaxLifetime))
+	}
+	if c.DBConnMaxIdleTime < 0 {
+		errs = append(errs, fmt.Errorf("DB_CONN_MAX_IDLE_TIME: must not be negative, got %s", c.DBConnMaxIdleTime))
+	}
+	if c.DBMigrateLockTimeout < 0 {
+		errs = append(errs, fmt.Errorf("DB_MIGRATE_LOCK_TIMEOUT: must not be negative, got %s", c.DBMigrateLockTimeout))
+	}
+	if c.DBSlowQueryThreshold < 0 {
+		errs = append(errs, fmt.Errorf("DB_SLOW_QUERY_THRESHOLD: must not be negative, got %s", c.DBSlowQueryThreshold))
+	}
+
+	if c.ShutdownTimeout <= 0 {
+		errs = append(errs, fmt.Errorf("SHUTDOWN_TIMEOUT: must be positive, got %s", c.ShutdownTimeout))
+	}
+	if c.RequestTimeout < 0 {
+		errs = append(errs, fmt.Errorf("REQUEST_TIMEOUT: must not be negative, got %s", c.RequestTimeout))
+	}
+	if c.LongRequestTimeout < 0 {
+		errs = append(errs, fmt.Errorf("REQUEST_TIMEOUT_LONG: must not be negative, got %s", c.LongRequestTimeout))
+	}
+	if c.RequestMaxBodySize < 0 {
+		errs = append(errs, fmt.Errorf("REQUEST_MAX_BODY_SIZE: must not be negative, got %d", c.RequestMaxBodySize))
+	}
+	if c.CompressLevel < 0 || c.CompressLevel > 9 {
+		errs = append(errs, fmt.Errorf("COMPRESS_LEVEL: must be between 0 and 9, got %d", c.CompressLevel))
+	}
+	if c.CompressMinSize < 0 {
+		errs = append(errs, fmt.Errorf("COMPRESS_MIN_SIZE: must not be negative, got %d", c.CompressMinSize))
+	}
+
+	if c.Env == "production" && (c.JWTSecret == DevJWTSecret || len(c.JWTSecret) < 32) {
+		errs = append(errs, errors.New("JWT_SECRET: must be set to a random value of at least 32 characters in production"))
+	}
+
+	if c.AccessTokenTTL <= 0 {
+		errs = append(errs, fmt.Errorf("ACCESS_TOKEN_TTL: must be positive, got %s", c.AccessTokenTTL))
+	}
+
+	if c.RefreshTokenTTL <= c.AccessTokenTTL {
+		errs = append(errs, fmt.Errorf("REFRESH_TOKEN_TTL: must be longer than ACCESS_TOKEN_TTL (%s), got %s", c.AccessTokenTTL, c.RefreshTokenTTL))
+	}
+
+	if c.PasswordResetTTL <= 0 {
+		errs = append(errs, fmt.Errorf("PASSWORD_RESET_TTL: must be positive, got %s", c.PasswordResetTTL))
+	}
+	// 密码最长 72 个字符（bcrypt 的输入上限），最短长度不能超过它。
+	if c.PasswordMinLength < 1 || c.PasswordMinLength > 72 {
+		errs = append(errs, fmt.Errorf("PASSWORD_MIN_LENGTH: must be between 1 and 72, got %d", c.PasswordMinLength))
+	}
+	if c.LoginMaxFailures < 0 {
+		errs = append(errs, fmt.Errorf("LOGIN_MAX_FAILURES: must not be negative, got %d", c.LoginMaxFailures))
+	}
+	if c.LoginIPMaxFailures < 0 {
+		errs = append(errs, fmt.Errorf("LOGIN_IP_MAX_FAILURES: must not be negative, got %d", c.LoginIPMaxFailures))
+	}
+	if c.LoginLockDuration <= 0 {
+		errs = append(errs, fmt.Errorf("LOGIN_LOCK_DURATION: must be positive, got %s", c.LoginLockDuration))
+	}
+
+	if c.UploadDir == "" {
+		errs = append(errs, errors.New("UPLOAD_DIR: must not be empty"))
+	}
+	if c.UploadMaxSize <= 0 {
+		errs = append(errs, fmt.Errorf("UPLOAD_MAX_SIZE: must be positive, got %d", c.UploadMaxSize))
+	}
+
+	if c.ImportMaxSize <= 0 {
+		errs = append(errs, fmt.Errorf("IMPORT_MAX_SIZE: must be positive, got %d", c.ImportMaxSize))
+	}
+	if c.PublicURL != "" {
+		if u, err := url.Parse(c.PublicURL); err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			errs = append(errs, fmt.Errorf("PUBLIC_URL: %q must be an absolute http(s) URL", c.PublicURL))
+		}
+	}
+	if c.ExportTimeout <= 0 {
+		errs = append(errs, fmt.Errorf("EXPORT_TIMEOUT: must be positive, got %s", c.ExportTimeout))
+	}
+	if c.ExportImageMaxSize <= 0 {
+		errs = append(errs, fmt.Errorf("EXPORT_IMAGE_MAX_SIZE: must be positive, got %d", c.ExportImageMaxSize))
+	}
+
+	if c.MetricsAddr != "" {
+		if err := validateAddr(c.MetricsAddr); err != nil {
+			errs = append(errs, fmt.Errorf("METRICS_ADDR: %w", err))
+		} else if c.MetricsAddr == c.Addr {
+			errs = append(errs, errors.New("METRICS_ADDR: must differ from APP_ADDR"))
+		}
+	}
+
+	if (c.TLSCertFile == "") != (c.TLSKeyFile == "") {
+		errs = append(errs, errors.New("TLS_CERT_FILE, TLS_KEY_FILE: must be set together"))
+	}
+	if c.TLSCertFile != "" && len(c.TLSAutocertDomains) > 0 {
+		errs = append(errs, errors.New("TLS_AUTOCERT_DOMAINS: cannot be combined with TLS_CERT_FILE/TLS_KEY_FILE"))
+	}
+	if len(c.TLSAutocertDomains) > 0 && c.TLSAutocertCacheDir == "" {
+		errs = append(errs, errors.New("TLS_AUTOCERT_CACHE_DIR: must not be empty when TLS_AUTOCERT_DOMAINS is set"))
+	}
+	if c.HTTPRedirectAddr != "" {
+		if c.TLSCertFile == "" && len(c.TLSAutocertDomains) == 0 {
+			errs = append(errs, errors.New("HTTP_REDIRECT_ADDR: requires TLS_CERT_FILE/TLS_KEY_FILE or TLS_AUTOCERT_DOMAINS"))
+		} else if err := validateAddr(c.HTTPRedirectAddr); err != nil {
+			errs = append(errs, fmt.Errorf("HTTP_REDIRECT_ADDR: %w", err))
+		} else if c.HTTPRedirectAddr == c.Addr || c.HTTPRedirectAddr == c.MetricsAddr {
+			errs = append(errs, errors.New("HTTP_REDIRECT_ADDR: must differ from APP_ADDR and METRICS_ADDR"))
+		}
+	}
+
+	if c.BackupDir == "" {
+		errs = append(errs, errors.New("BACKUP_DIR: must not be empty"))
+	}
+	if c.BackupSchedule != "" {
+		if _, err := cron.ParseStandard(c.BackupSchedule); err != nil {
+			errs = append(errs, fmt.Errorf("BACKUP_SCHEDULE: %q is not a valid cron expression: %w", c.BackupSchedule, err))
+		}
+	}
+	if c.BackupKeep <= 0 {
+		errs = append(errs, fmt.Errorf("BACKUP_KEEP: must be positive, got %d", c.BackupKeep))
+	}
+	if c.BackupMaxSize <= 0 {
+		errs = append(errs, fmt.Errorf("BACKUP_MAX_SIZE: must be positive, got %d", c.BackupMaxSize))
+	}
+
+	if c.JobWorkers <= 0 || c.JobWorkers > 64 {
+		errs = append(errs, fmt.Errorf("JOB_WORKERS: must be between 1 and 64, got %d", c.JobWorkers))
+	}
+	if c.JobQueueSize <= 0 {
+		errs = append(errs, fmt.Errorf("JOB_QUEUE_SIZE: must be positive, got %d", c.JobQueueSize))
+	}
+	if c.JobDir == "" {
+		errs = append(errs, errors.New("JOB_DIR: must not be empty"))
+	}
+	if c.JobRetention <= 0 {
+		errs = append(errs, fmt.Errorf("JOB_RETENTION: must be positive, got %s", c.JobRetention))
+	}
+
+	if !strings.HasPrefix(c.PublicDocPath, "/") || !(strings.Contains(c.PublicDocPath, "{id}") || strings.Contains(c.PublicDocPath, "{slug}")) {
+		errs = append(errs, fmt.Errorf("PUBLIC_DOC_PATH: %q must start with / and contain {id} or {slug}", c.PublicDocPath))
+	}
+	if c.SitemapCacheTTL < 0 {
+		errs = append(errs, fmt.Errorf("SITEMAP_CACHE_TTL: must not be negative, got %s", c.SitemapCacheTTL))
+	}
+	if c.FeedSize <= 0 || c.FeedSize > 100 {
+		errs = append(errs, fmt.Errorf("FEED_SIZE: must be between 1 and 100, got %d", c.FeedSize))
+	}
+	if c.FeedCacheTTL < 0 {
+		errs = append(errs, fmt.Errorf("FEED_CACHE_TTL: must not be negative, got %s", c.FeedCacheTTL))
+	}
+
+	for _, host := range c.SanitizeIframeHosts {
+		if u, err := url.Parse("https://" + host); err != nil || u.Host != host || u.Port() != "" || strings.Contains(host, "*") {
+			errs = append(errs, fmt.Errorf("SANITIZE_IFRAME_HOSTS: %q must be a host name such as www.youtube.com", host))
+		}
+	}
+
+	if c.BatchMaxDocs <= 0 || c.BatchMaxDocs > 1000 {
+		errs = append(errs, fmt.Errorf("BATCH_MAX_DOCS: must be between 1 and 1000, got %d", c.BatchMaxDocs))
+	}
+
+	if c.TrashRetentionDays < 0 {
+		errs = append(errs, fmt.Errorf("TRASH_RETENTION_DAYS: must not be negative, got %d", c.TrashRetentionDays))
+	}
+
+	if c.DocLockTTL <= 0 {
+		errs = append(errs, fmt.Errorf("DOC_LOCK_TTL: must be positive, got %s", c.DocLockTTL))
+	}
+	if c.CollabMaxPeers <= 0 {
+		errs = append(errs, fmt.Errorf("COLLAB_MAX_PEERS: must be positive, got %d", c.CollabMaxPeers))
+	}
+
+	if c.RedisURL != "" {
+		if u, err := url.Parse(c.RedisURL); err != nil || (u.Scheme != "redis" && u.Scheme != "rediss") || u.Host == "" {
+			errs = append(errs, fmt.Errorf("REDIS_URL: %q must be a redis:// or rediss:// URL", c.RedisURL))
+		}
+	}
+	if c.RenderCacheTTL < 0 {
+		errs = append(errs, fmt.Errorf("RENDER_CACHE_TTL: must not be negative, got %s", c.RenderCacheTTL))
+	}
+	if c.RenderCacheEntries <= 0 {
+		errs = append(errs, fmt.Errorf("RENDER_CACHE_ENTRIES: must be positive, got %d", c.RenderCacheEntries))
+	}
+
+	for _, pair := range []struct{ name, id, secret string }{
+		{"GITHUB", c.OAuthGitHubClientID, c.OAuthGitHubClientSecret},
+		{"GOOGLE", c.OAuthGoogleClientID, c.OAuthGoogleClientSecret},
+	} {
+		if (pair.id == "") != (pair.secret == "") {
+			errs = append(errs, fmt.Errorf("OAUTH_%s_CLIENT_ID / OAUTH_%s_CLIENT_SECRET: both must be set to enable the provider", pair.name, pair.name))
+		}
+	}
+	if c.OAuthRedirectURL != "" {
+		if u, err := url.Parse(c.OAuthRedirectURL); err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			errs = append(errs, fmt.Errorf("OAUTH_REDIRECT_URL: %q must be an absolute http(s) URL", c.OAuthRedirectURL))
+		}
+	}
+
+	if c.MailEnabled {
+		if c.SMTPHost == "" {
+			errs = append(errs, errors.New("SMTP_HOST: is required when MAIL_ENABLED is true"))
+		}
+		if c.SMTPPort <= 0 || c.SMTPPort > 65535 {
+			errs = append(errs, fmt.Errorf("SMTP_PORT: must be between 1 and 65535, got %d", c.SMTPPort))
+		}
+		if _, err := mail.ParseAddress(c.SMTPFrom); err != nil {
+			errs = append(errs, fmt.Errorf("SMTP_FROM: %q must be an email address such as \"PlainDoc <noreply@example.com>\"", c.SMTPFrom))
+		}
+	}
+
+	return errors.Join(errs...)
+}
+
+func validateAddr(addr string) error {
+	_, port, err := net.SplitHostPort(addr)
+	if err != nil {
+		return fmt.Errorf("%q is not a valid host:port or :port address: %w", addr, err)
+	}
+	if n, err := strconv.Atoi(port); err != nil || n < 0 || n > 65535 {
+		return fmt.Errorf("%q has invalid port %q", addr, port)
+	}
+	return nil
+}
+
+// validProxy 判断 TRUSTED_PROXIES 中的一项是否为 IP 或 CIDR，规则与 gin.Engine.SetTrustedProxies 一致。
+func validProxy(proxy string) bool {
+	if strings.Contains(proxy, "/") {
+		_, _, err := net.ParseCIDR(proxy)
+		return err == nil
+	}
+	return net.ParseIP(proxy) != nil
+}
+
+// validateOrigin 校验单个来源，通配子域 https://*.example.com 按 https://x.example.com 解析。
+func validateOrigin(origin string) error {
+	u, err := url.Parse(strings.Replace(origin, "://*.", "://x.", 1))
+	if err != nil {
+		return fmt.Errorf("%q is not a valid URL: %w", origin, err)
+	}
+	if u.Scheme == "" || u.Host == "" {
+		return fmt.Errorf("%q must include scheme and host, e.g. https://example.com", origin)
+	}
+	if (u.Path != "" && u.Path != "/") || u.RawQuery != "" {
+		return fmt.Errorf("%q must be an origin without path or query", origin)
+	}
+	return nil
+}
@@ -0,0 +1,86 @@
+package config
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestValidate(t *testing.T) {
+	tests := []struct {
+		name string
+		// file 是配置文件中的取值，键与环境变量同名；未给出的项取默认值。
+		file values
+		// want 为空表示校验通过，否则是错误信息中应出现的片段。
+		want []string
+	}{
+		{name: "defaults"},
+		{name: "postgres", file: values{"db_driver": "postgres"}},
+		{name: "sqlite", file: values{"db_driver": "sqlite"}},
+		{name: "unix socket", file: values{"app_addr": "unix:/run/plaindoc.sock"}},
+		{name: "wildcard origin", file: values{"web_origin": "https://docs.example.com,https://*.example.com"}},
+		{name: "proxies", file: values{"trusted_proxies": "10.0.0.0/8,127.0.0.1"}},
+		{name: "production secret", file: values{"app_env": "production", "jwt_secret": strings.Repeat("s", 32)}},
+		{name: "password length bounds", file: values{"password_min_length": "72"}},
+		{name: "mail", file: values{"mail_enabled": "true", "smtp_host": "smtp.example.com", "smtp_from": "PlainDoc <noreply@example.com>"}},
+		{name: "tls with redirect", file: values{"tls_cert_file": "cert.pem", "tls_key_file": "key.pem", "http_redirect_addr": ":80"}},
+
+		{name: "env", file: values{"app_env": "staging"}, want: []string{"APP_ENV:"}},
+		{name: "addr", file: values{"app_addr": "8080"}, want: []string{"APP_ADDR:"}},
+		{name: "empty unix socket", file: values{"app_addr": "unix:"}, want: []string{"APP_ADDR: unix socket path must not be empty"}},
+		{name: "origin with path", file: values{"web_origin": "https://example.com/app"}, want: []string{"WEB_ORIGIN:", "without path"}},
+		{name: "origin without scheme", file: values{"web_origin": "example.com"}, want: []string{"WEB_ORIGIN:", "scheme and host"}},
+		{name: "proxy", file: values{"trusted_proxies": "proxy.local"}, want: []string{"TRUSTED_PROXIES:"}},
+		{name: "log level", file: values{"log_level": "verbose"}, want: []string{"LOG_LEVEL:"}},
+		{name: "language", file: values{"default_language": "xx"}, want: []string{"DEFAULT_LANGUAGE:"}},
+		{name: "driver", file: values{"db_driver": "oracle"}, want: []string{"DB_DRIVER:"}},
+		{name: "idle conns", file: values{"db_max_open_conns": "5", "db_max_idle_conns": "10"}, want: []string{"DB_MAX_IDLE_CONNS:"}},
+		{name: "slow query threshold", file: values{"db_slow_query_threshold": "-1s"}, want: []string{"DB_SLOW_QUERY_THRESHOLD: must not be negative"}},
+		{name: "unparsable duration", file: values{"shutdown_timeout": "soon"}, want: []string{"SHUTDOWN_TIMEOUT:", "not a valid duration"}},
+		{name: "unparsable integer", file: values{"job_workers": "many"}, want: []string{"JOB_WORKERS:", "not a valid integer"}},
+		{name: "unparsable size", file: values{"upload_max_size": "huge"}, want: []string{"UPLOAD_MAX_SIZE:", "not a valid size"}},
+		{name: "compress level", file: values{"compress_level": "10"}, want: []string{"COMPRESS_LEVEL:"}},
+		{name: "dev secret in production", file: values{"app_env": "production"}, want: []string{"JWT_SECRET:"}},
+		{name: "short secret in production", file: values{"app_env": "production", "jwt_secret": "short"}, want: []string{"JWT_SECRET:"}},
+		{name: "refresh ttl", file: values{"access_token_ttl": "1h", "refresh_token_ttl": "1h"}, want: []string{"REFRESH_TOKEN_TTL:"}},
+		{name: "password too short", file: values{"password_min_length": "0"}, want: []string{"PASSWORD_MIN_LENGTH:"}},
+		{name: "password too long", file: values{"password_min_length": "73"}, want: []string{"PASSWORD_MIN_LENGTH:"}},
+		{name: "login failures", file: values{"login_max_failures": "-1"}, want: []string{"LOGIN_MAX_FAILURES:"}},
+		{name: "public url", file: values{"public_url": "docs.example.com"}, want: []string{"PUBLIC_URL:"}},
+		{name: "metrics addr", file: values{"app_addr": ":8080", "metrics_addr": ":8080"}, want: []string{"METRICS_ADDR: must differ"}},
+		{name: "tls half configured", file: values{"tls_cert_file": "cert.pem"}, want: []string{"TLS_CERT_FILE, TLS_KEY_FILE"}},
+		{name: "redirect without tls", file: values{"http_redirect_addr": ":80"}, want: []string{"HTTP_REDIRECT_ADDR: requires"}},
+		{name: "backup schedule", file: values{"backup_schedule": "every day"}, want: []string{"BACKUP_SCHEDULE:"}},
+		{name: "job workers", file: values{"job_workers": "65"}, want: []string{"JOB_WORKERS:"}},
+		{name: "public doc path", file: values{"public_doc_path": "/docs/{space}"}, want: []string{"PUBLIC_DOC_PATH:"}},
+		{name: "iframe wildcard", file: values{"sanitize_iframe_hosts": "*.youtube.com"}, want: []string{"SANITIZE_IFRAME_HOSTS:"}},
+		{name: "iframe port", file: values{"sanitize_iframe_hosts": "www.youtube.com:443"}, want: []string{"SANITIZE_IFRAME_HOSTS:"}},
+		{name: "redis url", file: values{"redis_url": "http://127.0.0.1:6379"}, want: []string{"REDIS_URL:"}},
+		{name: "oauth half configured", file: values{"oauth_github_client_id": "id"}, want: []string{"OAUTH_GITHUB_CLIENT_ID"}},
+		{name: "mail without host", file: values{"mail_enabled": "true", "smtp_from": "noreply@example.com"}, want: []string{"SMTP_HOST:"}},
+		{name: "mail sender", file: values{"mail_enabled": "true", "smtp_host": "smtp.example.com", "smtp_from": "noreply"}, want: []string{"SMTP_FROM:"}},
+		{
+			name: "all errors reported at once",
+			file: values{"app_env": "staging", "db_driver": "oracle", "feed_size": "0"},
+			want: []string{"APP_ENV:", "DB_DRIVER:", "FEED_SIZE:"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := load(tt.file).Validate()
+			if len(tt.want) == 0 {
+				if err != nil {
+					t.Fatalf("Validate() = %v, want nil", err)
+				}
+				return
+			}
+			if err == nil {
+				t.Fatalf("Validate() = nil, want an error containing %q", tt.want)
+			}
+			for _, want := range tt.want {
+				if !strings.Contains(err.Error(), want) {
+					t.Errorf("Validate() = %v, want it to contain %q", err, want)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,95 @@
+package database
+
+import (
+	"database/sql"
+	"fmt"
+	"net/url"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/go-sql-driver/mysql"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/stdlib"
+	"github.com/lifei6671/plaindoc/apps/server/internal/config"
+	_ "modernc.org/sqlite"
+)
+
+// Open 按配置创建数据库连接池，返回连接池及对应的方言；sql.Open 不会立即建连，连通性由健康检查负责探测。
+// 生命周期参数为 0 表示不限制。
+func Open(cfg config.Config) (*sql.DB, Dialect, error) {
+	dialect := Dialect(cfg.DBDriver)
+	var (
+		db  *sql.DB
+		err error
+	)
+	switch dialect {
+	case MySQL:
+		db, err = openMySQL(cfg.DBDSN)
+	case Postgres:
+		db, err = openPostgres(cfg.DBDSN)
+	case SQLite:
+		db, err = openSQLite(cfg.DBDSN)
+	default:
+		return nil, "", fmt.Errorf("unsupported DB_DRIVER %q", cfg.DBDriver)
+	}
+	if err != nil {
+		return nil, "", err
+	}
+	db.SetMaxOpenConns(cfg.DBMaxOpenConns)
+	db.SetMaxIdleConns(cfg.DBMaxIdleConns)
+	db.SetConnMaxLifetime(cfg.DBConnMaxLifetime)
+	db.SetConnMaxIdleTime(cfg.DBConnMaxIdleTime)
+	return db, dialect, nil
+}
+
+func openMySQL(raw string) (*sql.DB, error) {
+	dsn, err := mysql.ParseDSN(raw)
+	if err != nil {
+		return nil, fmt.Errorf("parse DB_DSN: %w", err)
+	}
+	// 统一以 UTC 读写时间字段，避免 DATETIME 被扫描成 []byte。
+	dsn.ParseTime = true
+	dsn.Loc = time.UTC
+	// RowsAffected 返回匹配行数而不是实际变化行数，否则写入相同的值会被 requireAffected 误判为记录不存在。
+	dsn.ClientFoundRows = true
+
+	db, err := sql.Open("mysql", dsn.FormatDSN())
+	if err != nil {
+		return nil, fmt.Errorf("open database: %w", err)
+	}
+	return db, nil
+}
+
+// openPostgres 的时间列使用不带时区的 TIMESTAMP，写入前已统一转换为 UTC，读出时同样按 UTC 解释。
+func openPostgres(raw string) (*sql.DB, error) {
+	connConfig, err := pgx.ParseConfig(raw)
+	if err != nil {
+		return nil, fmt.Errorf("parse DB_DSN: %w", err)
+	}
+	connConfig.RuntimeParams["timezone"] = "UTC"
+	return stdlib.OpenDB(*connConfig), nil
+}
+
+// openSQLite 的 DB_DSN 是数据库文件路径，父目录不存在时自动创建，文件由 SQLite 在首次连接时创建。
+// 每个连接都显式开启外键约束（SQLite 默认关闭）；写事务以 BEGIN IMMEDIATE 开始并在锁冲突时等待，
+// 避免多个读事务同时升级为写事务时直接返回 SQLITE_BUSY。
+func openSQLite(path string) (*sql.DB, error) {
+	if dir := filepath.Dir(path); dir != "." {
+		if err := os.MkdirAll(dir, 0o755); err != nil {
+			return nil, fmt.Errorf("create database directory: %w", err)
+		}
+	}
+	query := url.Values{}
+	query.Add("_pragma", "foreign_keys(1)")
+	query.Add("_pragma", "busy_timeout(5000)")
+	query.Add("_pragma", "journal_mode(WAL)")
+	query.Set("_txlock", "immediate")
+	query.Set("_time_format", "sqlite")
+
+	db, err := sql.Open("sqlite", "file:"+path+"?"+query.Encode())
+	if err != nil {
+		return nil, fmt.Errorf("open database: %w", err)
+	}
+	return db, nil
+}
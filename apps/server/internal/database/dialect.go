@@ -0,0 +1,91 @@
+package database
+
+import (
+	"database/sql"
+	"errors"
+	"strconv"
+	"strings"
+
+	"github.com/go-sql-driver/mysql"
+	"github.com/jackc/pgx/v5/pgconn"
+	"modernc.org/sqlite"
+	sqlite3 "modernc.org/sqlite/lib"
+)
+
+// Dialect 屏蔽不同数据库在占位符、自增主键与错误码上的差异。
+// 业务 SQL 统一按 MySQL 的 ? 占位符书写，执行前由 Rebind 转换。
+type Dialect string
+
+const (
+	MySQL    Dialect = "mysql"
+	Postgres Dialect = "postgres"
+	SQLite   Dialect = "sqlite"
+)
+
+// Rebind 把 ? 占位符转换为当前数据库的写法；引号内的 ? 保持不变。
+func (d Dialect) Rebind(query string) string {
+	if d != Postgres || !strings.Contains(query, "?") {
+		return query
+	}
+	var (
+		b     strings.Builder
+		n     int
+		quote rune
+	)
+	b.Grow(len(query) + 8)
+	for _, r := range query {
+		switch {
+		case quote != 0:
+			if r == quote {
+				quote = 0
+			}
+		case r == '\'' || r == '"':
+			quote = r
+		case r == '?':
+			n++
+			b.WriteByte('$')
+			b.WriteString(strconv.Itoa(n))
+			continue
+		}
+		b.WriteRune(r)
+	}
+	return b.String()
+}
+
+// Returning 表示 INSERT 需要通过 RETURNING 子句取回自增主键，而不是依赖 LastInsertId。
+func (d Dialect) Returning() bool {
+	return d == Postgres
+}
+
+// IsDuplicate 判断 err 是否为唯一键冲突。
+func (d Dialect) IsDuplicate(err error) bool {
+	switch d {
+	case Postgres:
+		var pgErr *pgconn.PgError
+		return errors.As(err, &pgErr) && pgErr.Code == "23505"
+	case SQLite:
+		var sqliteErr *sqlite.Error
+		return errors.As(err, &sqliteErr) &&
+			(sqliteErr.Code() == sqlite3.SQLITE_CONSTRAINT_UNIQUE || sqliteErr.Code() == sqlite3.SQLITE_CONSTRAINT_PRIMARYKEY)
+	default:
+		var mysqlErr *mysql.MySQLError
+		return errors.As(err, &mysqlErr) && mysqlErr.Number == 1062
+	}
+}
+
+// SnapshotIsolation 返回读取一致性快照所需的事务隔离级别；SQLite 的事务本身就是串行化的。
+func (d Dialect) SnapshotIsolation() sql.IsolationLevel {
+	if d == SQLite {
+		return sql.LevelDefault
+	}
+	return sql.LevelRepeatableRead
+}
+
+// ResetSequence 返回在显式写入主键后把 table 的自增序列推进到当前最大值之后的语句，不需要时返回空串：
+// MySQL 与 SQLite 会随显式写入的主键自动推进，PostgreSQL 的 IDENTITY 序列不会。
+func (d Dialect) ResetSequence(table, column string) string {
+	if d != Postgres || column == "" {
+		return ""
+	}
+	return "SELECT setval(pg_get_serial_sequence('" + table + "', '" + column + "'), COALESCE(MAX(" + column + "), 0) + 1, false) FROM " + table
+}
@@ -0,0 +1,135 @@
+package diff
+
+import "strings"
+
+const (
+	OpEqual  = "equal"
+	OpInsert = "insert"
+	OpDelete = "delete"
+)
+
+// Line 是行级 diff 的一行；OldLine/NewLine 为 1 起的行号，0 表示该侧不存在。
+type Line struct {
+	Op      string `json:"op"`
+	OldLine int    `json:"old_line,omitempty"`
+	NewLine int    `json:"new_line,omitempty"`
+	Text    string `json:"text"`
+}
+
+// Lines 基于 Myers 算法计算两段文本的最短行级编辑序列。
+func Lines(oldText string, newText string) []Line {
+	a, b := splitLines(oldText), splitLines(newText)
+
+	// 先剥离公共前后缀，绝大多数编辑只涉及中间少量行。
+	prefix := 0
+	for prefix < len(a) && prefix < len(b) && a[prefix] == b[prefix] {
+		prefix++
+	}
+	suffix := 0
+	for suffix < len(a)-prefix && suffix < len(b)-prefix && a[len(a)-1-suffix] == b[len(b)-1-suffix] {
+		suffix++
+	}
+
+	lines := make([]Line, 0, len(a)+len(b))
+	for i := 0; i < prefix; i++ {
+		lines = append(lines, Line{Op: OpEqual, OldLine: i + 1, NewLine: i + 1, Text: a[i]})
+	}
+	for _, line := range myers(a[prefix:len(a)-suffix], b[prefix:len(b)-suffix]) {
+		if line.OldLine > 0 {
+			line.OldLine += prefix
+		}
+		if line.NewLine > 0 {
+			line.NewLine += prefix
+		}
+		lines = append(lines, line)
+	}
+	for i := suffix; i > 0; i-- {
+		oldIndex, newIndex := len(a)-i, len(b)-i
+		lines = append(lines, Line{Op: OpEqual, OldLine: oldIndex + 1, NewLine: newIndex + 1, Text: a[oldIndex]})
+	}
+	return lines
+}
+
+func splitLines(text string) []string {
+	if text == "" {
+		return nil
+	}
+	return strings.Split(strings.TrimSuffix(text, "\n"), "\n")
+}
+
+// myers 只为每一步保存 [-d, d] 范围内的前沿，内存为 O(D²) 而不是 O(D·(N+M))。
+func myers(a []string, b []string) []Line {
+	n, m := len(a), len(b)
+	if n == 0 && m == 0 {
+		return nil
+	}
+
+	offset := n + m
+	v := make([]int, 2*offset+2)
+	var trace [][]int
+
+search:
+	for d := 0; d <= n+m; d++ {
+		snapshot := make([]int, 2*d+1)
+		copy(snapshot, v[offset-d:offset+d+1])
+		trace = append(trace, snapshot)
+
+		for k := -d; k <= d; k += 2 {
+			var x int
+			if k == -d || (k != d && v[offset+k-1] < v[offset+k+1]) {
+				x = v[offset+k+1]
+			} else {
+				x = v[offset+k-1] + 1
+			}
+			y := x - k
+			for x < n && y < m && a[x] == b[y] {
+				x++
+				y++
+			}
+			v[offset+k] = x
+			if x >= n && y >= m {
+				break search
+			}
+		}
+	}
+
+	var reversed []Line
+	x, y := n, m
+	for d := len(trace) - 1; d > 0; d-- {
+		frontier := trace[d]
+		at := func(k int) int { return frontier[k+d] }
+
+		k := x - y
+		var prevK int
+		if k == -d || (k != d && at(k-1) < at(k+1)) {
+			prevK = k + 1
+		} else {
+			prevK = k - 1
+		}
+		prevX := at(prevK)
+		prevY := prevX - prevK
+
+		for x > prevX && y > prevY {
+			reversed = append(reversed, Line{Op: OpEqual, OldLine: x, NewLine: y, Text: a[x-1]})
+			x--
+			y--
+		}
+		if x == prevX {
+			reversed = append(reversed, Line{Op: OpInsert, NewLine: y, Text: b[y-1]})
+		} else {
+			reversed = append(reversed, Line{Op: OpDelete, OldLine: x, Text: a[x-1]})
+		}
+		x, y = prevX, prevY
+	}
+	for x > 0 && y > 0 {
+		reversed = append(reversed, Line{Op: OpEqual, OldLine: x, NewLine: y, Text: a[x-1]})
+		x--
+		y--
+	}
+
+	lines := make([]Line, len(reversed))
+	for i, line := range reversed {
+		lines[len(reversed)-1-i] = line
+	}
+	return lines
+}
@@ -0,0 +1,53 @@
+package export
+
+import (
+	"archive/zip"
+	"context"
+	"errors"
+	"io"
+	"io/fs"
+	"path"
+
+	"github.com/lifei6671/plaindoc/apps/server/internal/storage"
+)
+
+// zipAssets 把正文引用的上传文件复制到 zip 的 assets/ 目录。
+type zipAssets struct {
+	zw      *zip.Writer
+	backend storage.Backend
+	// paths 记录已写入的上传文件 key 与其在 zip 中的路径，同一文件只打包一次；存储中不存在的文件记为空串。
+	paths map[string]string
+}
+
+func newZipAssets(zw *zip.Writer, backend storage.Backend) *zipAssets {
+	return &zipAssets{zw: zw, backend: backend, paths: map[string]string{}}
+}
+
+// add 写入 key 对应的上传文件并返回其在 zip 中的路径，文件已不存在时返回空串。
+func (z *zipAssets) add(ctx context.Context, key string) (string, error) {
+	if assetPath, ok := z.paths[key]; ok {
+		return assetPath, nil
+	}
+
+	file, err := z.backend.Open(ctx, key)
+	if errors.Is(err, fs.ErrNotExist) {
+		z.paths[key] = ""
+		return "", nil
+	}
+	if err != nil {
+		return "", err
+	}
+	defer file.Close()
+
+	// 上传文件名本身是内容哈希，直接取文件名即可避免重名。
+	assetPath := AssetsDir + "/" + path.Base(key)
+	w, err := z.zw.CreateHeader(&zip.FileHeader{Name: assetPath, Method: zip.Store})
+	if err != nil {
+		return "", err
+	}
+	if _, err := io.Copy(w, file); err != nil {
+		return "", err
+	}
+	z.paths[key] = assetPath
+	return assetPath, nil
+}
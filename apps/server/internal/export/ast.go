@@ -0,0 +1,212 @@
+package export
+
+import (
+	"bytes"
+	"strings"
+
+	"github.com/lifei6671/plaindoc/apps/server/internal/wikilink"
+	"github.com/yuin/goldmark"
+	"github.com/yuin/goldmark/ast"
+	"github.com/yuin/goldmark/extension"
+	east "github.com/yuin/goldmark/extension/ast"
+	"github.com/yuin/goldmark/text"
+	"github.com/yuin/goldmark/util"
+)
+
+// markdownParser 与文档渲染使用相同的语法扩展，保证导出的结构与页面上看到的一致。
+var markdownParser = goldmark.New(goldmark.WithExtensions(extension.GFM, wikilink.Extension)).Parser()
+
+// Node 是正文 AST 中的一个节点，按 JSON 导出，供其他系统不依赖 Markdown 解析器重建内容。
+//
+// Type 取值：块级 document、heading（attrs.level）、paragraph、text_block（紧凑列表项中的段落）、blockquote、
+// list（attrs.ordered、attrs.start、attrs.tight）、list_item（任务项带 attrs.checked）、code_block（attrs.language）、
+// table（attrs.align）、table_row（表头带 attrs.header）、table_cell（attrs.align）、thematic_break、html_block；
+// 行内 text、soft_break、hard_break、emphasis、strong、strikethrough、code、link（attrs.href、attrs.title）、
+// image（attrs.src、attrs.title、attrs.alt）、wikilink（attrs.slug，目标在导出范围内时另带 attrs.doc_id）、html。
+// 代码、文本与原始 HTML 的内容放在 Text 中；引用上传文件的 link 与 image 另带 attrs.asset，为附件在导出包中的路径。
+type Node struct {
+	Type     string         `json:"type"`
+	Text     string         `json:"text,omitempty"`
+	Attrs    map[string]any `json:"attrs,omitempty"`
+	Children []*Node        `json:"children,omitempty"`
+}
+
+// ParseMarkdown 把 Markdown 正文解析为 AST，根节点的 Type 为 document。
+func ParseMarkdown(source []byte) *Node {
+	root := markdownParser.Parse(text.NewReader(source))
+	return convertNode(root, source)
+}
+
+func convertNode(node ast.Node, source []byte) *Node {
+	out := &Node{}
+	switch n := node.(type) {
+	case *ast.Document:
+		out.Type = "document"
+	case *ast.Heading:
+		out.Type, out.Attrs = "heading", map[string]any{"level": n.Level}
+	case *ast.Paragraph:
+		out.Type = "paragraph"
+	case *ast.TextBlock:
+		out.Type = "text_block"
+	case *ast.Blockquote:
+		out.Type = "blockquote"
+	case *ast.List:
+		out.Type, out.Attrs = "list", map[string]any{"ordered": n.IsOrdered(), "tight": n.IsTight}
+		if n.IsOrdered() && n.Start != 1 {
+			out.Attrs["start"] = n.Start
+		}
+	case *ast.ListItem:
+		out.Type = "list_item"
+		if first := n.FirstChild(); first != nil {
+			if box, ok := first.FirstChild().(*east.TaskCheckBox); ok {
+				out.Attrs = map[string]any{"checked": box.IsChecked}
+			}
+		}
+	case *ast.FencedCodeBlock:
+		out.Type, out.Text = "code_block", string(n.Lines().Value(source))
+		if language := n.Language(source); len(language) > 0 {
+			out.Attrs = map[string]any{"language": string(language)}
+		}
+		return out
+	case *ast.CodeBlock:
+		out.Type, out.Text = "code_block", string(n.Lines().Value(source))
+		return out
+	case *ast.ThematicBreak:
+		out.Type = "thematic_break"
+		return out
+	case *ast.HTMLBlock:
+		raw := n.Lines().Value(source)
+		if n.HasClosure() {
+			raw = append(raw, n.ClosureLine.Value(source)...)
+		}
+		out.Type, out.Text = "html_block", string(raw)
+		return out
+	case *east.Table:
+		align := make([]string, len(n.Alignments))
+		for i, alignment := range n.Alignments {
+			if alignment != east.AlignNone {
+				align[i] = alignment.String()
+			}
+		}
+		out.Type, out.Attrs = "table", map[string]any{"align": align}
+	case *east.TableHeader:
+		out.Type, out.Attrs = "table_row", map[string]any{"header": true}
+	case *east.TableRow:
+		out.Type = "table_row"
+	case *east.TableCell:
+		out.Type = "table_cell"
+		if n.Alignment != east.AlignNone {
+			out.Attrs = map[string]any{"align": n.Alignment.String()}
+		}
+	case *ast.Text:
+		out.Type, out.Text = "text", textValue(n.Segment.Value(source), n.IsRaw())
+		return out
+	case *ast.String:
+		out.Type, out.Text = "text", textValue(n.Value, n.IsRaw() || n.IsCode())
+		return out
+	case *ast.CodeSpan:
+		var buf bytes.Buffer
+		for child := n.FirstChild(); child != nil; child = child.NextSibling() {
+			switch typed := child.(type) {
+			case *ast.Text:
+				buf.Write(typed.Segment.Value(source))
+			case *ast.String:
+				buf.Write(typed.Value)
+			}
+		}
+		out.Type, out.Text = "code", buf.String()
+		return out
+	case *ast.Emphasis:
+		out.Type = "emphasis"
+		if n.Level >= 2 {
+			out.Type = "strong"
+		}
+	case *east.Strikethrough:
+		out.Type = "strikethrough"
+	case *ast.Link:
+		out.Type, out.Attrs = "link", map[string]any{"href": string(n.Destination)}
+		if len(n.Title) > 0 {
+			out.Attrs["title"] = string(n.Title)
+		}
+	case *ast.AutoLink:
+		href := string(n.URL(source))
+		if n.AutoLinkType == ast.AutoLinkEmail && !strings.HasPrefix(strings.ToLower(href), "mailto:") {
+			href = "mailto:" + href
+		}
+		out.Type, out.Attrs = "link", map[string]any{"href": href}
+		out.Children = []*Node{{Type: "text", Text: string(n.Label(source))}}
+		return out
+	case *ast.Image:
+		out.Type, out.Attrs = "image", map[string]any{"src": string(n.Destination), "alt": nodeText(n, source)}
+		if len(n.Title) > 0 {
+			out.Attrs["title"] = string(n.Title)
+		}
+		return out
+	case *ast.RawHTML:
+		var buf bytes.Buffer
+		for i := 0; i < n.Segments.Len(); i++ {
+			segment := n.Segments.At(i)
+			buf.Write(segment.Value(source))
+		}
+		out.Type, out.Text = "html", buf.String()
+		return out
+	case *wikilink.Node:
+		out.Type, out.Text, out.Attrs = "wikilink", n.Label, map[string]any{"slug": n.Slug}
+		return out
+	default:
+		// 未识别的扩展节点保留 goldmark 的类型名，由各导出格式决定如何报告。
+		out.Type = strings.ToLower(node.Kind().String())
+	}
+
+	for child := node.FirstChild(); child != nil; child = child.NextSibling() {
+		if _, ok := child.(*east.TaskCheckBox); ok {
+			continue
+		}
+		converted := convertNode(child, source)
+		out.Children = appendInline(out.Children, converted)
+		if text, ok := child.(*ast.Text); ok {
+			switch {
+			case text.HardLineBreak():
+				out.Children = append(out.Children, &Node{Type: "hard_break"})
+			case text.SoftLineBreak():
+				out.Children = append(out.Children, &Node{Type: "soft_break"})
+			}
+		}
+	}
+	return out
+}
+
+// appendInline 追加子节点，相邻的文本节点合并为一个。
+func appendInline(children []*Node, node *Node) []*Node {
+	if n := len(children); n > 0 && node.Type == "text" && children[n-1].Type == "text" {
+		children[n-1].Text += node.Text
+		return children
+	}
+	return append(children, node)
+}
+
+// textValue 按 goldmark 渲染 HTML 时的规则处理转义与字符引用，raw 文本原样保留。
+func textValue(value []byte, raw bool) string {
+	if raw {
+		return string(value)
+	}
+	value = util.UnescapePunctuations(value)
+	value = util.ResolveNumericReferences(value)
+	return string(util.ResolveEntityNames(value))
+}
+
+// nodeText 拼接节点内的纯文本，用于图片的替代文本。
+func nodeText(node ast.Node, source []byte) string {
+	var buf strings.Builder
+	for child := node.FirstChild(); child != nil; child = child.NextSibling() {
+		switch typed := child.(type) {
+		case *ast.Text:
+			buf.WriteString(textValue(typed.Segment.Value(source), typed.IsRaw()))
+		case *ast.String:
+			buf.WriteString(string(typed.Value))
+		default:
+			buf.WriteString(nodeText(child, source))
+		}
+	}
+	return buf.String()
+}
@@ -0,0 +1,242 @@
+package export
+
+import (
+	"html"
+	"path"
+	"strconv"
+	"strings"
+)
+
+// 导出报告中记录的问题类型。
+const (
+	IssueRawHTML      = "raw_html"
+	IssueBrokenLink   = "broken_wikilink"
+	IssueMissingAsset = "missing_asset"
+	IssueTaskList     = "mixed_task_list"
+	IssueUnsupported  = "unsupported_node"
+)
+
+// confluenceWriter 把正文 AST 转换为 Confluence storage format（XHTML）。
+// 代码块转为 code 宏，上传的图片与附件转为页面附件引用，内部链接转为按标题引用的页面链接；
+// 没有对应元素的内容按文本保留，并通过 issue 报告，不会静默丢弃。
+type confluenceWriter struct {
+	buf   strings.Builder
+	pages map[string]PageRef
+	issue func(kind, detail string)
+}
+
+// confluencePage 返回 root 对应的页面正文。
+func confluencePage(root *Node, pages map[string]PageRef, issue func(kind, detail string)) string {
+	w := &confluenceWriter{pages: pages, issue: issue}
+	w.children(root)
+	return w.buf.String()
+}
+
+func (w *confluenceWriter) children(node *Node) {
+	for _, child := range node.Children {
+		w.node(child)
+	}
+}
+
+func (w *confluenceWriter) node(node *Node) {
+	switch node.Type {
+	case "heading":
+		tag := "h" + strconv.Itoa(node.Attrs["level"].(int))
+		w.wrap(tag, "", node)
+	case "paragraph":
+		w.wrap("p", "", node)
+	case "text_block":
+		w.children(node)
+	case "blockquote":
+		w.wrap("blockquote", "", node)
+	case "list":
+		w.list(node)
+	case "code_block":
+		w.codeMacro(attrString(node, "language"), node.Text)
+	case "thematic_break":
+		w.buf.WriteString("<hr />")
+	case "table":
+		w.table(node)
+	case "html_block", "html":
+		w.rawHTML(node)
+	case "text":
+		w.text(node.Text)
+	case "soft_break":
+		w.buf.WriteString("\n")
+	case "hard_break":
+		w.buf.WriteString("<br />")
+	case "emphasis":
+		w.wrap("em", "", node)
+	case "strong":
+		w.wrap("strong", "", node)
+	case "strikethrough":
+		w.wrap("del", "", node)
+	case "code":
+		w.buf.WriteString("<code>")
+		w.text(node.Text)
+		w.buf.WriteString("</code>")
+	case "link":
+		w.link(node)
+	case "image":
+		w.image(node)
+	case "wikilink":
+		w.wikilink(node)
+	default:
+		w.issue(IssueUnsupported, node.Type)
+		w.children(node)
+	}
+}
+
+// wrap 输出以 tag 包裹的子节点，attrs 是已转义的属性串。
+func (w *confluenceWriter) wrap(tag string, attrs string, node *Node) {
+	w.buf.WriteString("<" + tag + attrs + ">")
+	w.children(node)
+	w.buf.WriteString("</" + tag + ">")
+}
+
+func (w *confluenceWriter) text(s string) {
+	w.buf.WriteString(html.EscapeString(s))
+}
+
+// list 输出列表；全部由任务项组成的列表转为 Confluence 任务列表，部分是任务项时按普通列表输出并在文本前标出勾选状态。
+func (w *confluenceWriter) list(node *Node) {
+	tasks := 0
+	for _, item := range node.Children {
+		if _, ok := item.Attrs["checked"]; ok {
+			tasks++
+		}
+	}
+	if tasks > 0 && tasks == len(node.Children) {
+		w.buf.WriteString("<ac:task-list>")
+		for _, item := range node.Children {
+			status := "incomplete"
+			if item.Attrs["checked"].(bool) {
+				status = "complete"
+			}
+			w.buf.WriteString("<ac:task><ac:task-status>" + status + "</ac:task-status><ac:task-body>")
+			w.children(item)
+			w.buf.WriteString("</ac:task-body></ac:task>")
+		}
+		w.buf.WriteString("</ac:task-list>")
+		return
+	}
+	if tasks > 0 {
+		w.issue(IssueTaskList, "list mixes task items and plain items, checkboxes are kept as [x] / [ ] text")
+	}
+
+	tag, attrs := "ul", ""
+	if node.Attrs["ordered"] == true {
+		tag = "ol"
+		if start, ok := node.Attrs["start"].(int); ok {
+			attrs = ` start="` + strconv.Itoa(start) + `"`
+		}
+	}
+	w.buf.WriteString("<" + tag + attrs + ">")
+	for _, item := range node.Children {
+		w.buf.WriteString("<li>")
+		if checked, ok := item.Attrs["checked"].(bool); ok {
+			if checked {
+				w.buf.WriteString("[x] ")
+			} else {
+				w.buf.WriteString("[ ] ")
+			}
+		}
+		w.children(item)
+		w.buf.WriteString("</li>")
+	}
+	w.buf.WriteString("</" + tag + ">")
+}
+
+func (w *confluenceWriter) table(node *Node) {
+	w.buf.WriteString("<table><tbody>")
+	for _, row := range node.Children {
+		cell := "td"
+		if row.Attrs["header"] == true {
+			cell = "th"
+		}
+		w.buf.WriteString("<tr>")
+		for _, c := range row.Children {
+			attrs := ""
+			if align := attrString(c, "align"); align != "" {
+				attrs = ` style="text-align: ` + align + `;"`
+			}
+			w.wrap(cell, attrs, c)
+		}
+		w.buf.WriteString("</tr>")
+	}
+	w.buf.WriteString("</tbody></table>")
+}
+
+// codeMacro 输出 code 宏；CDATA 中不能出现 ]]>，需要拆成两段。
+func (w *confluenceWriter) codeMacro(language string, code string) {
+	w.buf.WriteString(`<ac:structured-macro ac:name="code">`)
+	if language != "" {
+		w.buf.WriteString(`<ac:parameter ac:name="language">` + html.EscapeString(language) + `</ac:parameter>`)
+	}
+	w.buf.WriteString(`<ac:plain-text-body><![CDATA[` + strings.ReplaceAll(code, "]]>", "]]]]><![CDATA[>") + `]]></ac:plain-text-body>`)
+	w.buf.WriteString(`</ac:structured-macro>`)
+}
+
+// rawHTML 处理正文中的原始 HTML：Confluence 的 html 宏通常被禁用，块级 HTML 转为代码块保留源码，行内 HTML 按文本保留。
+func (w *confluenceWriter) rawHTML(node *Node) {
+	snippet := strings.TrimSpace(node.Text)
+	if runes := []rune(snippet); len(runes) > 80 {
+		snippet = string(runes[:80]) + "…"
+	}
+	w.issue(IssueRawHTML, snippet)
+	if node.Type == "html_block" {
+		w.codeMacro("html", node.Text)
+		return
+	}
+	w.text(node.Text)
+}
+
+func (w *confluenceWriter) link(node *Node) {
+	href := attrString(node, "href")
+	if asset := attrString(node, "asset"); asset != "" {
+		w.buf.WriteString(`<ac:link><ri:attachment ri:filename="` + html.EscapeString(path.Base(asset)) + `" /><ac:link-body>`)
+		w.children(node)
+		w.buf.WriteString(`</ac:link-body></ac:link>`)
+		return
+	}
+	attrs := ` href="` + html.EscapeString(href) + `"`
+	if title := attrString(node, "title"); title != "" {
+		attrs += ` title="` + html.EscapeString(title) + `"`
+	}
+	w.wrap("a", attrs, node)
+}
+
+func (w *confluenceWriter) image(node *Node) {
+	attrs := ""
+	if alt := attrString(node, "alt"); alt != "" {
+		attrs += ` ac:alt="` + html.EscapeString(alt) + `"`
+	}
+	if title := attrString(node, "title"); title != "" {
+		attrs += ` ac:title="` + html.EscapeString(title) + `"`
+	}
+	w.buf.WriteString("<ac:image" + attrs + ">")
+	if asset := attrString(node, "asset"); asset != "" {
+		w.buf.WriteString(`<ri:attachment ri:filename="` + html.EscapeString(path.Base(asset)) + `" />`)
+	} else {
+		w.buf.WriteString(`<ri:url ri:value="` + html.EscapeString(attrString(node, "src")) + `" />`)
+	}
+	w.buf.WriteString("</ac:image>")
+}
+
+// wikilink 转为按标题引用的页面链接；目标不在本次导出中时只保留链接文本，断链已由 annotate 报告。
+func (w *confluenceWriter) wikilink(node *Node) {
+	slug := attrString(node, "slug")
+	page, ok := w.pages[slug]
+	if !ok {
+		w.text(node.Text)
+		return
+	}
+	w.buf.WriteString(`<ac:link><ri:page ri:content-title="` + html.EscapeString(page.Title) + `" /><ac:plain-text-link-body><![CDATA[`)
+	w.buf.WriteString(strings.ReplaceAll(node.Text, "]]>", "]]]]><![CDATA[>"))
+	w.buf.WriteString(`]]></ac:plain-text-link-body></ac:link>`)
+}
+
+func attrString(node *Node, key string) string {
+	value, _ := node.Attrs[key].(string)
+	return value
+}
@@ -0,0 +1,58 @@
+package export
+
+import (
+	"bytes"
+	"html/template"
+
+	"github.com/lifei6671/plaindoc/apps/server/internal/render"
+)
+
+// pageCSS 是导出页面共用的正文样式。
+const pageCSS = `body { font-family: -apple-system, "Segoe UI", "PingFang SC", "Microsoft YaHei", "Noto Sans CJK SC", sans-serif; font-size: 14px; line-height: 1.7; color: #1f2328; margin: 0; }
+h1, h2, h3, h4, h5, h6 { line-height: 1.3; margin: 1.4em 0 0.6em; page-break-after: avoid; }
+h1 { font-size: 2em; border-bottom: 1px solid #d1d9e0; padding-bottom: 0.3em; }
+h2 { font-size: 1.5em; border-bottom: 1px solid #d1d9e0; padding-bottom: 0.3em; }
+pre { background: #f6f8fa; padding: 12px 16px; border-radius: 6px; white-space: pre-wrap; word-wrap: break-word; page-break-inside: avoid; }
+code { font-family: "SFMono-Regular", Consolas, "Liberation Mono", monospace; font-size: 0.9em; }
+table { border-collapse: collapse; margin: 1em 0; page-break-inside: avoid; }
+th, td { border: 1px solid #d1d9e0; padding: 6px 12px; }
+th { background: #f6f8fa; }
+img { max-width: 100%; page-break-inside: avoid; }
+blockquote { margin: 0; padding: 0 1em; color: #59636e; border-left: 4px solid #d1d9e0; }
+a.wikilink-broken { color: #d1242f; text-decoration: underline dashed; }
+`
+
+// pageTemplate 是导出用的独立 HTML 页面；<base> 让正文中 /uploads/... 等站内相对路径解析为绝对地址。
+var pageTemplate = template.Must(template.New("page").Parse(`<!DOCTYPE html>
+<html>
+<head>
+<meta charset="utf-8">
+{{if .BaseURL}}<base href="{{.BaseURL}}">{{end}}
+<title>{{.Title}}</title>
+<style>
+{{.CSS}}
+</style>
+</head>
+<body>
+<h1>{{.Title}}</h1>
+{{.Body}}
+</body>
+</html>
+`))
+
+// HTMLPage 把已消毒的正文 HTML 包装成带样式的完整页面。
+func HTMLPage(title string, body string, baseURL string) ([]byte, error) {
+	var buf bytes.Buffer
+	err := pageTemplate.Execute(&buf, struct {
+		Title   string
+		BaseURL string
+		Body    template.HTML
+		CSS     template.CSS
+	}{
+		Title:   title,
+		BaseURL: baseURL,
+		Body:    template.HTML(body),
+		CSS:     template.CSS(pageCSS + render.HighlightCSS()),
+	})
+	return buf.Bytes(), err
+}
@@ -0,0 +1,129 @@
+package export
+
+import (
+	"archive/zip"
+	"bytes"
+	"context"
+	"io"
+	"path"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/goccy/go-yaml"
+	"github.com/lifei6671/plaindoc/apps/server/internal/storage"
+)
+
+// AssetsDir 是 zip 内存放图片与附件的目录。
+const AssetsDir = "assets"
+
+// FrontMatter 是每个导出 .md 文件头部的 YAML 元数据，导入时按同样的字段读取。
+type FrontMatter struct {
+	Title       string `yaml:"title"`
+	Slug        string `yaml:"slug"`
+	UpdatedAt   string `yaml:"updated_at"`
+	Author      string `yaml:"author,omitempty"`
+	AuthorEmail string `yaml:"author_email,omitempty"`
+	// SortOrder 记录文档在同级中的顺序，导入时据此还原排序。
+	SortOrder int64 `yaml:"sort_order,omitempty"`
+}
+
+// ParseDocument 拆分 .md 文件头部的 front-matter 与正文；没有 front-matter 时返回零值元数据与原文。
+func ParseDocument(data []byte) (FrontMatter, string, error) {
+	var meta FrontMatter
+	text := strings.TrimPrefix(string(data), "\ufeff")
+	text = strings.ReplaceAll(text, "\r\n", "\n")
+	rest, ok := strings.CutPrefix(text, "---\n")
+	if !ok {
+		return meta, text, nil
+	}
+	header, body, ok := strings.Cut(rest, "\n---\n")
+	if !ok {
+		// 文件只有 front-matter、结尾没有换行的情况。
+		if header, ok = strings.CutSuffix(rest, "\n---"); !ok {
+			return meta, text, nil
+		}
+	}
+	if err := yaml.Unmarshal([]byte(header), &meta); err != nil {
+		return meta, "", err
+	}
+	return meta, strings.TrimPrefix(body, "\n"), nil
+}
+
+// MarkdownArchive 把文档逐篇写入 zip 流：每篇一个 .md 文件，引用的上传文件复制到 assets/ 并改写为相对路径。
+// 每篇文档写完即释放，内存占用与文档总数无关。
+type MarkdownArchive struct {
+	zw        *zip.Writer
+	backend   storage.Backend
+	publicURL string
+	links     *regexp.Regexp
+	assets    *zipAssets
+}
+
+// NewMarkdownArchive 创建写往 w 的归档；publicURL 非空时，带站点前缀的绝对上传地址也会被识别。
+func NewMarkdownArchive(w io.Writer, backend storage.Backend, publicURL string, uploadBaseURL string) *MarkdownArchive {
+	pattern := regexp.QuoteMeta(uploadBaseURL) + `/[^\s()<>"']+`
+	if publicURL != "" && strings.HasPrefix(uploadBaseURL, "/") {
+		pattern = `(?:` + regexp.QuoteMeta(publicURL) + `)?` + pattern
+	}
+	zw := zip.NewWriter(w)
+	return &MarkdownArchive{
+		zw:        zw,
+		backend:   backend,
+		publicURL: publicURL,
+		links:     regexp.MustCompile(pattern),
+		assets:    newZipAssets(zw, backend),
+	}
+}
+
+// AddDocument 写入一篇文档，name 是不带扩展名的 zip 内路径（可包含目录）。
+func (a *MarkdownArchive) AddDocument(ctx context.Context, name string, meta FrontMatter, updatedAt time.Time, content string) error {
+	name = path.Clean(name) + ".md"
+	content, err := a.rewriteLinks(ctx, name, content)
+	if err != nil {
+		return err
+	}
+
+	meta.UpdatedAt = updatedAt.UTC().Format(time.RFC3339)
+	header, err := yaml.Marshal(meta)
+	if err != nil {
+		return err
+	}
+
+	w, err := a.zw.CreateHeader(&zip.FileHeader{Name: name, Method: zip.Deflate, Modified: updatedAt})
+	if err != nil {
+		return err
+	}
+	var buf bytes.Buffer
+	buf.WriteString("---\n")
+	buf.Write(header)
+	buf.WriteString("---\n\n")
+	buf.WriteString(content)
+	_, err = w.Write(buf.Bytes())
+	return err
+}
+
+func (a *MarkdownArchive) Close() error {
+	return a.zw.Close()
+}
+
+// rewriteLinks 把正文中的上传地址替换为相对于 docPath 的 assets/ 路径；存储中已不存在的文件保留原链接。
+func (a *MarkdownArchive) rewriteLinks(ctx context.Context, docPath string, content string) (string, error) {
+	var firstErr error
+	rewritten := a.links.ReplaceAllStringFunc(content, func(link string) string {
+		key, ok := a.backend.KeyFromURL(strings.TrimPrefix(link, a.publicURL))
+		if !ok || firstErr != nil {
+			return link
+		}
+		assetPath, err := a.assets.add(ctx, key)
+		if err != nil {
+			firstErr = err
+			return link
+		}
+		if assetPath == "" {
+			return link
+		}
+		return strings.Repeat("../", strings.Count(docPath, "/")) + assetPath
+	})
+	return rewritten, firstErr
+}
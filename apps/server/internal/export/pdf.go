@@ -0,0 +1,49 @@
+package export
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"os/exec"
+	"strings"
+)
+
+var (
+	ErrConverterUnavailable = errors.New("pdf converter is not installed")
+	ErrTimeout              = errors.New("export timed out")
+)
+
+// PDFConverter 调用 wkhtmltopdf 把 HTML 转为 PDF，HTML 经 stdin 传入、PDF 从 stdout 读出，不落临时文件。
+type PDFConverter struct {
+	binary string
+}
+
+func NewPDFConverter(binary string) *PDFConverter {
+	return &PDFConverter{binary: binary}
+}
+
+// Convert 在 ctx 超时或取消时终止转换进程；只有进程成功退出才返回完整的 PDF。
+func (p *PDFConverter) Convert(ctx context.Context, html []byte) ([]byte, error) {
+	cmd := exec.CommandContext(ctx, p.binary,
+		"--quiet",
+		"--encoding", "utf-8",
+		"--print-media-type",
+		// 单张图片加载失败不应导致整篇导出失败。
+		"--load-media-error-handling", "ignore",
+		"-", "-")
+	cmd.Stdin = bytes.NewReader(html)
+	var stdout, stderr bytes.Buffer
+	cmd.Stdout, cmd.Stderr = &stdout, &stderr
+
+	if err := cmd.Run(); err != nil {
+		if errors.Is(ctx.Err(), context.DeadlineExceeded) {
+			return nil, ErrTimeout
+		}
+		if errors.Is(err, exec.ErrNotFound) {
+			return nil, fmt.Errorf("%w: %s", ErrConverterUnavailable, p.binary)
+		}
+		return nil, fmt.Errorf("wkhtmltopdf: %w: %s", err, strings.TrimSpace(stderr.String()))
+	}
+	return stdout.Bytes(), nil
+}
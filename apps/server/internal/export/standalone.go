@@ -0,0 +1,189 @@
+package export
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"errors"
+	"html"
+	"html/template"
+	"io"
+	"io/fs"
+	"mime"
+	"net/http"
+	"path"
+	"regexp"
+	"strings"
+
+	"github.com/lifei6671/plaindoc/apps/server/internal/render"
+	"github.com/lifei6671/plaindoc/apps/server/internal/storage"
+)
+
+// standaloneCSS 是单文件 HTML 的页面布局：宽屏时目录固定在左侧，打印时隐藏目录。
+const standaloneCSS = `
+main { max-width: 860px; margin: 0 auto; padding: 24px 32px 64px; }
+nav.toc { font-size: 13px; padding: 16px 20px; border-bottom: 1px solid #d1d9e0; }
+nav.toc ul { list-style: none; margin: 0; padding: 0; }
+nav.toc li { margin: 4px 0; }
+nav.toc a { color: #59636e; text-decoration: none; }
+nav.toc a:hover { color: #0969da; }
+nav.toc .toc-h2 { padding-left: 1em; }
+nav.toc .toc-h3 { padding-left: 2em; }
+nav.toc .toc-h4, nav.toc .toc-h5, nav.toc .toc-h6 { padding-left: 3em; }
+@media (min-width: 1200px) {
+  nav.toc { position: fixed; top: 0; bottom: 0; left: 0; width: 260px; overflow-y: auto; border-bottom: 0; border-right: 1px solid #d1d9e0; }
+  main { margin-left: max(292px, calc((100% - 860px) / 2)); }
+}
+@media print { nav.toc { display: none; } main { margin: 0; max-width: none; } }
+`
+
+// standaloneTemplate 是不引用任何外部资源的单文件页面。不使用 <base>，否则目录中的 #锚点会跳到站点地址。
+var standaloneTemplate = template.Must(template.New("standalone").Parse(`<!DOCTYPE html>
+<html>
+<head>
+<meta charset="utf-8">
+<meta name="viewport" content="width=device-width, initial-scale=1">
+<title>{{.Title}}</title>
+<style>
+{{.CSS}}
+</style>
+</head>
+<body>
+{{if .TOC}}<nav class="toc"><ul>
+{{range .TOC}}<li class="toc-h{{.Level}}"><a href="#{{.ID}}">{{.Text}}</a></li>
+{{end}}</ul></nav>
+{{end}}<main>
+<h1>{{.Title}}</h1>
+{{.Body}}
+</main>
+</body>
+</html>
+`))
+
+var (
+	imgTagPattern  = regexp.MustCompile(`<img\b[^>]*>`)
+	imgSrcPattern  = regexp.MustCompile(`\ssrc="([^"]*)"`)
+	imgAltPattern  = regexp.MustCompile(`\salt="([^"]*)"`)
+	sitePathPrefix = regexp.MustCompile(`(\s(?:href|src)=")(/[^/"])`)
+)
+
+// StandaloneHTML 把文档生成可离线打开的单个 HTML 文件：样式内联、引用的上传图片以 data URI 内嵌、带目录导航。
+type StandaloneHTML struct {
+	backend   storage.Backend
+	publicURL string
+	siteURL   string
+	// maxImageSize 是单张内嵌图片的大小上限，base64 编码后约膨胀为原来的 4/3。
+	maxImageSize int64
+}
+
+// NewStandaloneHTML 创建导出器；siteURL 用于把正文中的站内相对链接改写为绝对地址（不含末尾 /）。
+func NewStandaloneHTML(backend storage.Backend, publicURL string, siteURL string, maxImageSize int64) *StandaloneHTML {
+	return &StandaloneHTML{backend: backend, publicURL: publicURL, siteURL: siteURL, maxImageSize: maxImageSize}
+}
+
+// inlinedImage 是一张上传图片的内嵌结果，同一图片在正文中多次出现时只读取一次。
+type inlinedImage struct {
+	dataURI string
+	// tooLarge 表示图片超过大小上限，需要降级为链接。
+	tooLarge bool
+}
+
+// Page 生成页面，同时返回超过大小上限、降级为链接而未内嵌的图片地址。存储中不存在或不是图片的文件保留原地址。
+func (e *StandaloneHTML) Page(ctx context.Context, title string, rendered *render.Result) ([]byte, []string, error) {
+	var (
+		skipped  []string
+		firstErr error
+		images   = map[string]inlinedImage{}
+	)
+	body := imgTagPattern.ReplaceAllStringFunc(rendered.HTML, func(tag string) string {
+		if firstErr != nil {
+			return tag
+		}
+		match := imgSrcPattern.FindStringSubmatch(tag)
+		if match == nil {
+			return tag
+		}
+		src := html.UnescapeString(match[1])
+		key, ok := e.backend.KeyFromURL(strings.TrimPrefix(src, e.publicURL))
+		if !ok {
+			return tag
+		}
+		image, ok := images[key]
+		if !ok {
+			var err error
+			if image, err = e.inline(ctx, key); err != nil {
+				firstErr = err
+				return tag
+			}
+			images[key] = image
+		}
+		switch {
+		case image.tooLarge:
+			skipped = append(skipped, src)
+			return e.imageLink(tag, src, key)
+		case image.dataURI != "":
+			return strings.Replace(tag, match[0], ` src="`+image.dataURI+`"`, 1)
+		}
+		return tag
+	})
+	if firstErr != nil {
+		return nil, nil, firstErr
+	}
+	body = sitePathPrefix.ReplaceAllString(body, "${1}"+html.EscapeString(e.siteURL)+"${2}")
+
+	var buf bytes.Buffer
+	err := standaloneTemplate.Execute(&buf, struct {
+		Title string
+		TOC   []render.Heading
+		Body  template.HTML
+		CSS   template.CSS
+	}{
+		Title: title,
+		TOC:   rendered.TOC,
+		Body:  template.HTML(body),
+		CSS:   template.CSS(pageCSS + standaloneCSS + render.HighlightCSS()),
+	})
+	return buf.Bytes(), skipped, err
+}
+
+// inline 读取上传文件并编码为 data URI；文件不存在或不是图片时返回零值。
+func (e *StandaloneHTML) inline(ctx context.Context, key string) (inlinedImage, error) {
+	file, err := e.backend.Open(ctx, key)
+	if errors.Is(err, fs.ErrNotExist) {
+		return inlinedImage{}, nil
+	}
+	if err != nil {
+		return inlinedImage{}, err
+	}
+	defer file.Close()
+
+	// 多读一个字节用于判断是否超限，超限的文件不会整个读入内存。
+	data, err := io.ReadAll(io.LimitReader(file, e.maxImageSize+1))
+	if err != nil {
+		return inlinedImage{}, err
+	}
+	if int64(len(data)) > e.maxImageSize {
+		return inlinedImage{tooLarge: true}, nil
+	}
+	contentType := mime.TypeByExtension(path.Ext(key))
+	if contentType == "" {
+		contentType = http.DetectContentType(data)
+	}
+	contentType, _, _ = strings.Cut(contentType, ";")
+	if !strings.HasPrefix(contentType, "image/") {
+		return inlinedImage{}, nil
+	}
+	return inlinedImage{dataURI: "data:" + contentType + ";base64," + base64.StdEncoding.EncodeToString(data)}, nil
+}
+
+// imageLink 把图片替换为指向原地址的链接，链接文字取图片的 alt，没有时取文件名。
+func (e *StandaloneHTML) imageLink(tag string, src string, key string) string {
+	text := path.Base(key)
+	if match := imgAltPattern.FindStringSubmatch(tag); match != nil && match[1] != "" {
+		text = html.UnescapeString(match[1])
+	}
+	if strings.HasPrefix(src, "/") {
+		src = e.siteURL + src
+	}
+	return `<a class="image-link" href="` + html.EscapeString(src) + `">` + html.EscapeString(text) + `</a>`
+}
@@ -0,0 +1,240 @@
+package export
+
+import (
+	"archive/zip"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"path"
+	"strings"
+	"time"
+
+	"github.com/lifei6671/plaindoc/apps/server/internal/storage"
+)
+
+// 结构化导出支持的格式。
+const (
+	// FormatJSON 每篇文档导出为一个 .json 文件，包含元数据、Markdown 原文与正文 AST。
+	FormatJSON = "json"
+	// FormatConfluence 每篇文档导出为一个 Confluence storage format 的 .xhtml 文件。
+	FormatConfluence = "confluence"
+)
+
+// ManifestFile 与 ReportFile 是结构化导出包根目录下的清单与导出报告。
+const (
+	ManifestFile = "manifest.json"
+	ReportFile   = "report.json"
+)
+
+// PageRef 是导出范围内的一篇文档，用于把内部链接映射为目标页面。
+type PageRef struct {
+	ID    int64
+	Title string
+}
+
+// Page 是写入结构化导出包的一篇文档的元数据。
+type Page struct {
+	ID          int64     `json:"id"`
+	ParentID    *int64    `json:"parent_id"`
+	Title       string    `json:"title"`
+	Slug        string    `json:"slug"`
+	SortOrder   int64     `json:"sort_order"`
+	Author      string    `json:"author,omitempty"`
+	AuthorEmail string    `json:"author_email,omitempty"`
+	UpdatedAt   time.Time `json:"updated_at"`
+}
+
+// Attachment 是文档引用的一个上传文件：Filename 是 Confluence 页面中引用的附件名，Path 是文件在导出包中的路径。
+type Attachment struct {
+	Filename string `json:"filename"`
+	Path     string `json:"path"`
+}
+
+// ManifestEntry 记录文档 id 与导出包内文件路径的对应关系，对端按 parent_id 与 sort_order 重建层级。
+type ManifestEntry struct {
+	Page
+	Path        string       `json:"path"`
+	Attachments []Attachment `json:"attachments"`
+}
+
+// Manifest 是 manifest.json 的内容，Documents 按写入顺序排列。
+type Manifest struct {
+	Format     string          `json:"format"`
+	Space      string          `json:"space"`
+	ExportedAt time.Time       `json:"exported_at"`
+	Documents  []ManifestEntry `json:"documents"`
+}
+
+// Issue 是一处无法无损转换的内容，Detail 给出具体的元素，例如内部链接的 slug 或原始 HTML 片段。
+type Issue struct {
+	DocumentID int64  `json:"document_id"`
+	Path       string `json:"path"`
+	Kind       string `json:"kind"`
+	Detail     string `json:"detail"`
+}
+
+// Report 是 report.json 的内容。
+type Report struct {
+	Issues []Issue `json:"issues"`
+}
+
+// jsonDocument 是 json 格式下每篇文档文件的内容。
+type jsonDocument struct {
+	Page
+	Markdown string `json:"markdown"`
+	AST      *Node  `json:"ast"`
+}
+
+// StructuredArchive 把文档逐篇写入 zip 流：文档文件按目录树存放，引用的上传文件复制到 assets/，
+// Close 时写入 manifest.json 与记录转换问题的 report.json。
+type StructuredArchive struct {
+	zw        *zip.Writer
+	format    string
+	space     string
+	backend   storage.Backend
+	publicURL string
+	pages     map[string]PageRef
+	assets    *zipAssets
+	manifest  []ManifestEntry
+	issues    []Issue
+}
+
+// NewStructuredArchive 创建写往 w 的归档；pages 是导出范围内按 slug 索引的文档，不在其中的内部链接视为断链。
+func NewStructuredArchive(w io.Writer, format string, space string, backend storage.Backend, publicURL string, pages map[string]PageRef) (*StructuredArchive, error) {
+	if format != FormatJSON && format != FormatConfluence {
+		return nil, fmt.Errorf("unsupported export format %q", format)
+	}
+	zw := zip.NewWriter(w)
+	return &StructuredArchive{
+		zw:        zw,
+		format:    format,
+		space:     space,
+		backend:   backend,
+		publicURL: publicURL,
+		pages:     pages,
+		assets:    newZipAssets(zw, backend),
+	}, nil
+}
+
+// AddDocument 写入一篇文档，name 是不带扩展名的 zip 内路径（可包含目录）。
+func (a *StructuredArchive) AddDocument(ctx context.Context, name string, page Page, content string) error {
+	name = path.Clean(name)
+	if a.format == FormatJSON {
+		name += ".json"
+	} else {
+		name += ".xhtml"
+	}
+	issue := func(kind, detail string) {
+		a.issues = append(a.issues, Issue{DocumentID: page.ID, Path: name, Kind: kind, Detail: detail})
+	}
+
+	root := ParseMarkdown([]byte(content))
+	attachments, err := a.annotate(ctx, root, issue)
+	if err != nil {
+		return err
+	}
+
+	var body []byte
+	if a.format == FormatJSON {
+		body, err = json.MarshalIndent(jsonDocument{Page: page, Markdown: content, AST: root}, "", "  ")
+		if err != nil {
+			return err
+		}
+	} else {
+		body = []byte(confluencePage(root, a.pages, issue))
+	}
+	w, err := a.zw.CreateHeader(&zip.FileHeader{Name: name, Method: zip.Deflate, Modified: page.UpdatedAt})
+	if err != nil {
+		return err
+	}
+	if _, err := w.Write(body); err != nil {
+		return err
+	}
+	a.manifest = append(a.manifest, ManifestEntry{Page: page, Path: name, Attachments: attachments})
+	return nil
+}
+
+// Issues 返回目前为止记录的转换问题数。
+func (a *StructuredArchive) Issues() int {
+	return len(a.issues)
+}
+
+// Close 写入清单与导出报告并结束 zip。
+func (a *StructuredArchive) Close() error {
+	manifest := Manifest{Format: a.format, Space: a.space, ExportedAt: time.Now().UTC(), Documents: a.manifest}
+	if manifest.Documents == nil {
+		manifest.Documents = []ManifestEntry{}
+	}
+	if err := a.writeJSON(ManifestFile, manifest); err != nil {
+		return err
+	}
+	report := Report{Issues: a.issues}
+	if report.Issues == nil {
+		report.Issues = []Issue{}
+	}
+	if err := a.writeJSON(ReportFile, report); err != nil {
+		return err
+	}
+	return a.zw.Close()
+}
+
+func (a *StructuredArchive) writeJSON(name string, v any) error {
+	data, err := json.MarshalIndent(v, "", "  ")
+	if err != nil {
+		return err
+	}
+	w, err := a.zw.CreateHeader(&zip.FileHeader{Name: name, Method: zip.Deflate, Modified: time.Now()})
+	if err != nil {
+		return err
+	}
+	_, err = w.Write(data)
+	return err
+}
+
+// annotate 把 link 与 image 引用的上传文件写入 assets/，在节点上记下 attrs.asset 并返回文档的附件列表，
+// 存储中已不存在的文件保留原地址；内部链接记下目标文档的 attrs.doc_id。找不到的附件与链接目标都会报告。
+func (a *StructuredArchive) annotate(ctx context.Context, node *Node, issue func(kind, detail string)) ([]Attachment, error) {
+	attachments := []Attachment{}
+	seen := map[string]bool{}
+	var walk func(node *Node) error
+	walk = func(node *Node) error {
+		if node.Type == "wikilink" {
+			slug := attrString(node, "slug")
+			if page, ok := a.pages[slug]; ok {
+				node.Attrs["doc_id"] = page.ID
+			} else {
+				issue(IssueBrokenLink, slug)
+			}
+		}
+		if node.Type == "link" || node.Type == "image" {
+			attr := "href"
+			if node.Type == "image" {
+				attr = "src"
+			}
+			link := attrString(node, attr)
+			if key, ok := a.backend.KeyFromURL(strings.TrimPrefix(link, a.publicURL)); ok {
+				assetPath, err := a.assets.add(ctx, key)
+				if err != nil {
+					return err
+				}
+				if assetPath == "" {
+					issue(IssueMissingAsset, link)
+				} else {
+					node.Attrs["asset"] = assetPath
+					if !seen[assetPath] {
+						seen[assetPath] = true
+						attachments = append(attachments, Attachment{Filename: path.Base(assetPath), Path: assetPath})
+					}
+				}
+			}
+		}
+		for _, child := range node.Children {
+			if err := walk(child); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+	return attachments, walk(node)
+}
@@ -0,0 +1,211 @@
+// Package feed 生成最近更新的公开文档的 Atom 订阅源（RFC 4287）。
+package feed
+
+import (
+	"bytes"
+	"context"
+	"encoding/xml"
+	"errors"
+	"net/url"
+	"strconv"
+	"time"
+
+	"github.com/lifei6671/plaindoc/apps/server/internal/cache"
+	"github.com/lifei6671/plaindoc/apps/server/internal/render"
+	"github.com/lifei6671/plaindoc/apps/server/internal/settings"
+	"github.com/lifei6671/plaindoc/apps/server/internal/store"
+)
+
+const (
+	// Path 是订阅源的地址，相对 PUBLIC_URL。
+	Path         = "/feed.xml"
+	ContentType  = "application/atom+xml; charset=utf-8"
+	namespace    = "http://www.w3.org/2005/Atom"
+	summaryRunes = 200
+	// cacheEntries 限制按空间、标签组合缓存的订阅源份数。
+	cacheEntries = 256
+)
+
+type atomFeed struct {
+	XMLName xml.Name    `xml:"feed"`
+	Xmlns   string      `xml:"xmlns,attr"`
+	ID      string      `xml:"id"`
+	Title   string      `xml:"title"`
+	Updated string      `xml:"updated"`
+	Links   []atomLink  `xml:"link"`
+	Entries []atomEntry `xml:"entry"`
+}
+
+type atomLink struct {
+	Rel  string `xml:"rel,attr,omitempty"`
+	Type string `xml:"type,attr,omitempty"`
+	Href string `xml:"href,attr"`
+}
+
+type atomEntry struct {
+	ID        string     `xml:"id"`
+	Title     string     `xml:"title"`
+	Link      atomLink   `xml:"link"`
+	Published string     `xml:"published,omitempty"`
+	Updated   string     `xml:"updated"`
+	Author    atomAuthor `xml:"author"`
+	Summary   string     `xml:"summary"`
+}
+
+type atomAuthor struct {
+	Name string `xml:"name"`
+}
+
+// Query 限定订阅源中的文档，字段为空表示不限制。
+type Query struct {
+	Space string
+	// Tag 为归一化后的标签名。
+	Tag string
+}
+
+// Options 配置站点地址、文档页面地址、篇数与缓存时间。
+type Options struct {
+	// BaseURL 是站点对外地址（PUBLIC_URL），不带末尾的 /。
+	BaseURL string
+	// DocumentURL 返回文档页面的完整地址，通常为 config.Config.DocumentURL。
+	DocumentURL func(id int64, space string, slug string) string
+	Size        int
+	// TTL 是生成结果的缓存时间，0 表示每次都重新生成。
+	TTL time.Duration
+}
+
+// Service 生成订阅源，结果按 Query 缓存 TTL。
+type Service struct {
+	store    *store.Store
+	settings *settings.Service
+	renderer *render.Renderer
+	opts     Options
+	cache    cache.Cache
+}
+
+func New(s *store.Store, settingsService *settings.Service, renderer *render.Renderer, opts Options) *Service {
+	return &Service{store: s, settings: settingsService, renderer: renderer, opts: opts, cache: cache.NewMemory(cacheEntries)}
+}
+
+// Atom 返回 query 对应的 Atom 文档。
+func (s *Service) Atom(ctx context.Context, query Query) ([]byte, error) {
+	key := selfQuery(query)
+	if s.opts.TTL > 0 {
+		// 进程内缓存不会出错，命中失败时直接重新生成。
+		if body, ok, _ := s.cache.Get(ctx, key); ok {
+			return body, nil
+		}
+	}
+	body, err := s.build(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+	if s.opts.TTL > 0 {
+		_ = s.cache.Set(ctx, key, body, s.opts.TTL)
+	}
+	return body, nil
+}
+
+func (s *Service) build(ctx context.Context, query Query) ([]byte, error) {
+	docs, _, err := s.store.ListDocuments(ctx, store.DocumentFilter{
+		Space:       query.Space,
+		Tag:         query.Tag,
+		Status:      store.DocStatusPublished,
+		Viewer:      store.Viewer{},
+		WithContent: true,
+		Limit:       s.opts.Size,
+	})
+	if err != nil {
+		return nil, err
+	}
+	snapshot, err := s.settings.Get(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	self := s.opts.BaseURL + Path
+	if params := selfQuery(query); params != "" {
+		self += "?" + params
+	}
+	feed := atomFeed{
+		Xmlns: namespace,
+		ID:    self,
+		Title: snapshot.String(settings.SiteName),
+		Links: []atomLink{
+			{Rel: "self", Type: "application/atom+xml", Href: self},
+			{Rel: "alternate", Type: "text/html", Href: s.opts.BaseURL + "/"},
+		},
+		Entries: []atomEntry{},
+	}
+	// 没有文档时 updated 取固定值，避免每次生成都改变订阅源。
+	updated := time.Unix(0, 0).UTC()
+	authors := map[int64]string{}
+	host := hostOf(s.opts.BaseURL)
+	for _, doc := range docs {
+		author, err := s.authorName(ctx, authors, doc.AuthorID)
+		if err != nil {
+			return nil, err
+		}
+		if author == "" {
+			author = feed.Title
+		}
+		link := s.opts.DocumentURL(doc.ID, doc.Space, doc.Slug)
+		entry := atomEntry{
+			// tag URI（RFC 4151）不随标题与 slug 变化，订阅客户端据此识别同一篇文档。
+			ID:      "tag:" + host + "," + doc.CreatedAt.UTC().Format("2006-01-02") + ":doc/" + strconv.FormatInt(doc.ID, 10),
+			Title:   doc.Title,
+			Link:    atomLink{Rel: "alternate", Type: "text/html", Href: link},
+			Updated: doc.UpdatedAt.UTC().Format(time.RFC3339),
+			Author:  atomAuthor{Name: author},
+			Summary: s.renderer.Excerpt([]byte(doc.Content), summaryRunes),
+		}
+		if doc.PublishedAt != nil {
+			entry.Published = doc.PublishedAt.UTC().Format(time.RFC3339)
+		}
+		feed.Entries = append(feed.Entries, entry)
+		if doc.UpdatedAt.After(updated) {
+			updated = doc.UpdatedAt.UTC()
+		}
+	}
+	feed.Updated = updated.Format(time.RFC3339)
+
+	var buf bytes.Buffer
+	buf.WriteString(xml.Header)
+	if err := xml.NewEncoder(&buf).Encode(feed); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// authorName 读取作者名并记入 names；作者已被删除时返回空名，不影响订阅源生成。
+func (s *Service) authorName(ctx context.Context, names map[int64]string, id int64) (string, error) {
+	if name, ok := names[id]; ok {
+		return name, nil
+	}
+	user, err := s.store.GetUser(ctx, id)
+	if err != nil && !errors.Is(err, store.ErrNotFound) {
+		return "", err
+	}
+	if user != nil {
+		names[id] = user.Name
+	}
+	return names[id], nil
+}
+
+func selfQuery(query Query) string {
+	params := url.Values{}
+	if query.Space != "" {
+		params.Set("space", query.Space)
+	}
+	if query.Tag != "" {
+		params.Set("tag", query.Tag)
+	}
+	return params.Encode()
+}
+
+func hostOf(baseURL string) string {
+	if u, err := url.Parse(baseURL); err == nil && u.Hostname() != "" {
+		return u.Hostname()
+	}
+	return "localhost"
+}
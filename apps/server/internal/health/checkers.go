@@ -0,0 +1,48 @@
+package health
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+
+	"github.com/lifei6671/plaindoc/apps/server/internal/migrate"
+)
+
+// DB 探测数据库连通性。
+func DB(db *sql.DB) Checker {
+	return Func("db", db.PingContext)
+}
+
+// Migrations 检查数据库是否已迁移到代码内置的最新版本，未完成或处于 dirty 状态时失败。
+// 数据库版本比代码还新（回滚代码而没有回滚数据库）时不阻断流量，只在 details 中标为 ahead。
+func Migrations(migrator *migrate.Migrator) Checker {
+	return migrations{migrator: migrator}
+}
+
+type migrations struct {
+	migrator *migrate.Migrator
+}
+
+func (migrations) Name() string { return "migrations" }
+
+func (m migrations) Check(ctx context.Context) (Details, error) {
+	version, dirty, err := m.migrator.Version(ctx)
+	if err != nil {
+		return nil, err
+	}
+	latest := m.migrator.Latest()
+	details := Details{"version": version, "latest": latest}
+	switch {
+	case dirty:
+		details["state"] = "dirty"
+		return details, fmt.Errorf("migration %d is dirty", version)
+	case version > latest:
+		details["state"] = "ahead"
+	case version < latest:
+		details["state"] = "pending"
+		return details, fmt.Errorf("database is at version %d, want %d", version, latest)
+	default:
+		details["state"] = "current"
+	}
+	return details, nil
+}
@@ -0,0 +1,185 @@
+package health
+
+import (
+	"context"
+	"errors"
+	"sort"
+	"sync"
+	"time"
+)
+
+// DefaultTimeout 是注册时未指定超时的 checker 单次探测的最长耗时。
+const DefaultTimeout = 2 * time.Second
+
+// 单个依赖的探测状态。
+const (
+	StatusUp   = "up"
+	StatusDown = "down"
+)
+
+// 整体的就绪状态：任一关键依赖不可用时为 not_ready，只有非关键依赖不可用时为 degraded。
+const (
+	StatusReady    = "ready"
+	StatusDegraded = "degraded"
+	StatusNotReady = "not_ready"
+)
+
+// Details 是探测结果中随依赖附带的额外信息，例如迁移版本，会原样出现在响应中。
+type Details map[string]any
+
+// Checker 探测一个外部依赖，返回 nil 错误表示可用；ctx 带有注册时指定的超时，实现需要在 ctx 结束时尽快返回。
+type Checker interface {
+	Name() string
+	Check(ctx context.Context) (Details, error)
+}
+
+// Func 把只需返回错误的探测函数包装为 Checker，例如 db.PingContext。
+func Func(name string, check func(ctx context.Context) error) Checker {
+	return funcChecker{name: name, check: check}
+}
+
+type funcChecker struct {
+	name  string
+	check func(ctx context.Context) error
+}
+
+func (f funcChecker) Name() string { return f.name }
+
+func (f funcChecker) Check(ctx context.Context) (Details, error) {
+	return nil, f.check(ctx)
+}
+
+// Options 控制 checker 的探测方式。
+type Options struct {
+	// Timeout 为 0 时使用 DefaultTimeout。
+	Timeout time.Duration
+	// Optional 表示依赖不可用时服务仍能降级运行，例如渲染缓存与邮件投递，失败时整体状态为 degraded 而不摘除流量。
+	Optional bool
+}
+
+// Result 是单个依赖的探测结果。
+type Result struct {
+	Status     string  `json:"status"`
+	DurationMS int64   `json:"duration_ms"`
+	Optional   bool    `json:"optional,omitempty"`
+	Error      string  `json:"error,omitempty"`
+	Details    Details `json:"details,omitempty"`
+}
+
+// Report 汇总所有依赖的探测结果，Failing 按名称排序。
+type Report struct {
+	Status  string            `json:"status"`
+	Checks  map[string]Result `json:"checks"`
+	Failing []string          `json:"failing,omitempty"`
+}
+
+// Ready 表示没有关键依赖不可用，可以接收流量。
+func (r Report) Ready() bool {
+	return r.Status != StatusNotReady
+}
+
+// Registry 保存已注册的 checker，新增依赖时在启动阶段调用 Register 即可出现在就绪探针中。
+type Registry struct {
+	mu      sync.RWMutex
+	entries []entry
+}
+
+type entry struct {
+	checker Checker
+	opts    Options
+}
+
+func NewRegistry() *Registry {
+	return &Registry{}
+}
+
+// Register 注册一个 checker；名称重复时 panic，因为结果按名称区分。
+func (r *Registry) Register(checker Checker, opts Options) {
+	if opts.Timeout <= 0 {
+		opts.Timeout = DefaultTimeout
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for _, e := range r.entries {
+		if e.checker.Name() == checker.Name() {
+			panic("health: duplicate checker " + checker.Name())
+		}
+	}
+	r.entries = append(r.entries, entry{checker: checker, opts: opts})
+}
+
+// Run 并行执行所有 checker，每个 checker 使用各自的超时，慢的依赖只会让自己超时失败，不影响其他结果。
+func (r *Registry) Run(ctx context.Context) Report {
+	r.mu.RLock()
+	entries := append([]entry(nil), r.entries...)
+	r.mu.RUnlock()
+
+	results := make([]Result, len(entries))
+	var wg sync.WaitGroup
+	for i, e := range entries {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			results[i] = run(ctx, e)
+		}()
+	}
+	wg.Wait()
+
+	report := Report{Status: StatusReady, Checks: make(map[string]Result, len(entries))}
+	for i, e := range entries {
+		result := results[i]
+		report.Checks[e.checker.Name()] = result
+		if result.Status == StatusUp {
+			continue
+		}
+		report.Failing = append(report.Failing, e.checker.Name())
+		switch {
+		case !e.opts.Optional:
+			report.Status = StatusNotReady
+		case report.Status == StatusReady:
+			report.Status = StatusDegraded
+		}
+	}
+	sort.Strings(report.Failing)
+	return report
+}
+
+type outcome struct {
+	details Details
+	err     error
+}
+
+// run 在独立的 goroutine 中执行 checker，超时后立即返回结果；不响应 ctx 的 checker 会在结束后被丢弃。
+func run(ctx context.Context, e entry) Result {
+	ctx, cancel := context.WithTimeout(ctx, e.opts.Timeout)
+	defer cancel()
+
+	start := time.Now()
+	done := make(chan outcome, 1)
+	go func() {
+		details, err := e.checker.Check(ctx)
+		done <- outcome{details: details, err: err}
+	}()
+
+	var out outcome
+	select {
+	case out = <-done:
+	case <-ctx.Done():
+		out.err = ctx.Err()
+	}
+	if errors.Is(out.err, context.DeadlineExceeded) {
+		out.err = errors.New("timed out after " + e.opts.Timeout.String())
+	}
+
+	result := Result{
+		Status:     StatusUp,
+		DurationMS: time.Since(start).Milliseconds(),
+		Optional:   e.opts.Optional,
+		Details:    out.details,
+	}
+	if out.err != nil {
+		result.Status = StatusDown
+		result.Error = out.err.Error()
+	}
+	return result
+}
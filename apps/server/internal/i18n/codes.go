@@ -0,0 +1,148 @@
+package i18n
+
+// 错误消息键。键的点号之前是响应中的 code（前端据此做分支判断，发布后不再修改），
+// 点号之后区分同一错误码下的不同提示；消息文本维护在 locales/<lang>.json 中。
+const (
+	InternalError   = "internal_error"
+	RateLimited     = "rate_limited"
+	RequestTimeout  = "request_timeout"
+	RequestTooLarge = "request_too_large"
+
+	InvalidRequest          = "invalid_request"
+	InvalidRequestBody      = "invalid_request.body"
+	FieldRequired           = "invalid_request.required"
+	FieldBlank              = "invalid_request.blank"
+	FieldMin                = "invalid_request.min"
+	FieldMax                = "invalid_request.max"
+	FieldLength             = "invalid_request.length"
+	FieldEmail              = "invalid_request.email"
+	FieldOneOf              = "invalid_request.oneof"
+	FieldInvalid            = "invalid_request.invalid"
+	ConfigKeysRequired      = "invalid_request.config_keys"
+	SearchTermsRequired     = "invalid_request.search_terms"
+	FileEmpty               = "invalid_request.file_empty"
+	GranteeRequired         = "invalid_request.grantee"
+	DraftFieldsOnly         = "invalid_request.draft_fields"
+	UnknownBackupTable      = "invalid_request.backup_table"
+	BatchTooLarge           = "invalid_request.batch_size"
+	InvalidID               = "invalid_id"
+	InvalidPage             = "invalid_pagination.page"
+	InvalidPageSize         = "invalid_pagination.page_size"
+	InvalidVersion          = "invalid_version"
+	InvalidRole             = "invalid_role"
+	InvalidTheme            = "invalid_theme"
+	InvalidThemeSetting     = "invalid_theme.setting"
+	InvalidConfig           = "invalid_config"
+	InvalidTag              = "invalid_tag"
+	InvalidSlug             = "invalid_slug"
+	InvalidColor            = "invalid_color"
+	InvalidParentDocument   = "invalid_parent"
+	InvalidParentComment    = "invalid_parent.comment"
+	InvalidArchive          = "invalid_archive"
+	InvalidBackup           = "invalid_backup"
+	InvalidSchedule         = "invalid_schedule"
+	BackupDriverMismatch    = "invalid_backup.driver"
+	BackupSchemaMismatch    = "invalid_backup.schema"
+	InvalidWebhookURL       = "invalid_webhook.url"
+	InvalidWebhookEvents    = "invalid_webhook.events"
+	FileTooLarge            = "file_too_large"
+	ArchiveTooLarge         = "file_too_large.archive"
+	BackupTooLarge          = "file_too_large.backup"
+	UnsupportedFileType     = "unsupported_file_type"
+	PermissionsInherited    = "permissions_inherited"
+	TreeCycle               = "tree_cycle"
+	DocHasChildren          = "doc_has_children"
+	DocHasChildrenInTrash   = "doc_has_children.trash"
+	SlugConflict            = "slug_conflict"
+	NothingToPublish        = "nothing_to_publish"
+	DocLocked               = "doc_locked"
+	VersionConflict         = "version_conflict"
+	VersionRequired         = "version_required"
+	TemplateNameTaken       = "template_name_taken"
+	SpaceKeyTaken           = "space_key_taken"
+	LastSpaceOwner          = "last_space_owner"
+	EmailTaken              = "email_taken"
+	WeakPassword            = "weak_password"
+	WeakPasswordLength      = "weak_password.length"
+	WeakPasswordTooLong     = "weak_password.too_long"
+	WeakPasswordNumeric     = "weak_password.numeric"
+	WeakPasswordUpper       = "weak_password.upper"
+	WeakPasswordLower       = "weak_password.lower"
+	WeakPasswordDigit       = "weak_password.digit"
+	WeakPasswordSymbol      = "weak_password.symbol"
+	WeakPasswordCommon      = "weak_password.common"
+	LastAdmin               = "last_admin"
+	RoomFull                = "room_full"
+	BackupInProgress        = "backup_in_progress"
+	JobNotFinished          = "job_not_finished"
+	ConfirmationRequired    = "confirmation_required"
+	RegistrationDisabled    = "registration_disabled"
+	TwoFactorAlreadyEnabled = "two_factor_already_enabled"
+	TwoFactorNotEnabled     = "two_factor_not_enabled"
+	TwoFactorNotStarted     = "two_factor_not_started"
+	TwoFactorEnforced       = "two_factor_enforced"
+	OAuthProviderNotFound   = "oauth_provider_not_found"
+	OAuthFailed             = "oauth_failed"
+	ExportTimeout           = "export_timeout"
+	ExportUnavailable       = "export_unavailable"
+	ExportFailed            = "export_failed"
+	JobQueueFull            = "job_queue_full"
+	DocNotFound             = "doc_not_found"
+	DocNotFoundInTrash      = "doc_not_found.trash"
+	VersionNotFound         = "version_not_found"
+	DraftNotFound           = "draft_not_found"
+	UserNotFound            = "user_not_found"
+	CommentNotFound         = "comment_not_found"
+	TagNotFound             = "tag_not_found"
+	PermissionNotFound      = "permission_not_found"
+	WebhookNotFound         = "webhook_not_found"
+	TemplateNotFound        = "template_not_found"
+	SpaceNotFound           = "space_not_found"
+	JobNotFound             = "job_not_found"
+	JobFileNotFound         = "job_not_found.file"
+	SessionNotFound         = "session_not_found"
+	APIKeyNotFound          = "api_key_not_found"
+	Unauthorized            = "unauthorized"
+	InvalidAccessToken      = "unauthorized.invalid_token"
+	InvalidAPIKey           = "unauthorized.invalid_api_key"
+	UserGone                = "unauthorized.user_gone"
+	TokenExpired            = "token_expired"
+	InvalidCredentials      = "invalid_credentials"
+	EmailNotVerified        = "email_not_verified"
+	AccountLocked           = "account_locked"
+	LoginIPLocked           = "account_locked.ip"
+	InvalidRefreshToken     = "invalid_refresh_token"
+	RefreshTokenRequired    = "invalid_refresh_token.missing"
+	RefreshTokenUserGone    = "invalid_refresh_token.user_gone"
+	RefreshTokenReused      = "refresh_token_reused"
+	RefreshTokenExpired     = "refresh_token_expired"
+	InvalidResetToken       = "invalid_reset_token"
+	InvalidVerificationLink = "invalid_verification_token"
+	InvalidChallengeToken   = "invalid_challenge_token"
+	ChallengeTokenExpired   = "challenge_token_expired"
+	InvalidTwoFactorCode    = "invalid_two_factor_code"
+	Forbidden               = "forbidden"
+	ForbiddenModifyDocument = "forbidden.modify_doc"
+	ForbiddenManageDocument = "forbidden.manage_doc"
+	ForbiddenDeleteComment  = "forbidden.delete_comment"
+	ForbiddenManageSpace    = "forbidden.manage_space"
+	ForbiddenReleaseLock    = "forbidden.release_lock"
+	ForbiddenSpaceMembers   = "forbidden.space_members"
+	APIKeyReadOnly          = "forbidden.api_key_read_only"
+	APIKeyNotAllowed        = "forbidden.api_key"
+	InsufficientRole        = "insufficient_role"
+
+	// 找回密码依赖 PUBLIC_URL 生成邮件中的链接。
+	PasswordResetUnavailable = "password_reset_unavailable.public_url"
+)
+
+// 字段级校验提示，用于 invalid_request 响应 errors 中每个字段的 message，不作为 code 使用。
+const (
+	ValidationRequired = "validation.required"
+	ValidationEmail    = "validation.email"
+	ValidationOneOf    = "validation.oneof"
+	ValidationMin      = "validation.min"
+	ValidationMax      = "validation.max"
+	ValidationInvalid  = "validation.invalid"
+	ValidationType     = "validation.type"
+)
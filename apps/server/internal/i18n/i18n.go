@@ -0,0 +1,112 @@
+package i18n
+
+import (
+	"embed"
+	"encoding/json"
+	"fmt"
+	"path"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Lang 是消息目录支持的语言，取 BCP 47 语言标签的主标签。
+type Lang string
+
+const (
+	EN Lang = "en"
+	ZH Lang = "zh"
+)
+
+// Base 是基准语言：其目录包含全部消息，其他语言缺少某条消息时回退到这里。
+const Base = EN
+
+//go:embed locales/*.json
+var localeFiles embed.FS
+
+var catalogs = mustLoad()
+
+func mustLoad() map[Lang]map[string]string {
+	entries, err := localeFiles.ReadDir("locales")
+	if err != nil {
+		panic(err)
+	}
+	result := make(map[Lang]map[string]string, len(entries))
+	for _, entry := range entries {
+		content, err := localeFiles.ReadFile(path.Join("locales", entry.Name()))
+		if err != nil {
+			panic(err)
+		}
+		messages := map[string]string{}
+		if err := json.Unmarshal(content, &messages); err != nil {
+			panic(fmt.Sprintf("i18n: parse %s: %v", entry.Name(), err))
+		}
+		result[Lang(strings.TrimSuffix(entry.Name(), ".json"))] = messages
+	}
+	if result[Base] == nil {
+		panic("i18n: missing base catalog " + string(Base))
+	}
+	return result
+}
+
+// Supported 报告 lang 是否有对应的消息目录。
+func Supported(lang string) bool {
+	_, ok := catalogs[Lang(lang)]
+	return ok
+}
+
+// Message 按语言取出 key 对应的消息模板并用 args 填充（fmt 格式）；
+// 该语言缺少这条消息时回退到基准语言，都没有时原样返回 key。
+func Message(lang Lang, key string, args ...any) string {
+	template, ok := catalogs[lang][key]
+	if !ok {
+		template, ok = catalogs[Base][key]
+	}
+	if !ok {
+		return key
+	}
+	if len(args) == 0 {
+		return template
+	}
+	return fmt.Sprintf(template, args...)
+}
+
+// CodeOf 返回消息键对应的响应错误码，即 "code.detail" 中点号之前的部分。
+func CodeOf(key string) string {
+	code, _, _ := strings.Cut(key, ".")
+	return code
+}
+
+// Negotiate 按 Accept-Language 的权重选出第一个受支持的语言，zh-CN、zh-Hant 等统一匹配到主标签 zh；
+// 请求头为空或没有受支持的语言时返回 fallback。
+func Negotiate(header string, fallback Lang) Lang {
+	type candidate struct {
+		lang    Lang
+		quality float64
+	}
+	var candidates []candidate
+	for _, part := range strings.Split(header, ",") {
+		tag, params, _ := strings.Cut(strings.TrimSpace(part), ";")
+		quality := 1.0
+		if value, ok := strings.CutPrefix(strings.TrimSpace(params), "q="); ok {
+			parsed, err := strconv.ParseFloat(value, 64)
+			if err != nil {
+				continue
+			}
+			quality = parsed
+		}
+		primary, _, _ := strings.Cut(strings.ToLower(strings.TrimSpace(tag)), "-")
+		primary, _, _ = strings.Cut(primary, "_")
+		if quality <= 0 || !Supported(primary) {
+			continue
+		}
+		candidates = append(candidates, candidate{lang: Lang(primary), quality: quality})
+	}
+	if len(candidates) == 0 {
+		return fallback
+	}
+	sort.SliceStable(candidates, func(i, j int) bool {
+		return candidates[i].quality > candidates[j].quality
+	})
+	return candidates[0].lang
+}
@@ -0,0 +1,40 @@
+package jobs
+
+import (
+	"context"
+	"log/slog"
+	"time"
+
+	"github.com/lifei6671/plaindoc/apps/server/internal/backup"
+	"github.com/robfig/cron/v3"
+)
+
+// ScheduleBackups 按 cron 表达式 spec 定时把备份保存到备份目录（并清理超出保留份数的旧备份），直到 ctx 取消；spec 为空时直接返回。
+func ScheduleBackups(ctx context.Context, service *backup.Service, spec string, logger *slog.Logger) {
+	if spec == "" {
+		return
+	}
+	schedule, err := cron.ParseStandard(spec)
+	if err != nil {
+		logger.Error("invalid backup schedule", "schedule", spec, "error", err)
+		return
+	}
+	logger.Info("scheduled backups enabled", "schedule", spec)
+
+	for {
+		timer := time.NewTimer(time.Until(schedule.Next(time.Now())))
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return
+		case <-timer.C:
+		}
+
+		file, err := service.Save(ctx, service.DefaultExclude())
+		if err != nil {
+			logger.Error("scheduled backup failed", "error", err)
+			continue
+		}
+		logger.Info("scheduled backup saved", "file", file.Name, "size", file.Size)
+	}
+}
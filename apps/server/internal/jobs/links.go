@@ -0,0 +1,20 @@
+package jobs
+
+import (
+	"context"
+	"log/slog"
+
+	"github.com/lifei6671/plaindoc/apps/server/internal/store"
+)
+
+// BackfillDocumentLinks 为还没有内部链接记录的文档（如引入 [[slug]] 语法之前创建的文档）补建链接，启动时执行一次。
+func BackfillDocumentLinks(ctx context.Context, s *store.Store, logger *slog.Logger) {
+	count, err := s.BackfillDocumentLinks(ctx)
+	if err != nil {
+		logger.Error("backfill document links failed", "error", err)
+		return
+	}
+	if count > 0 {
+		logger.Info("backfilled document links", "documents", count)
+	}
+}
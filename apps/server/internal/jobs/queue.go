@@ -0,0 +1,273 @@
+package jobs
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/lifei6671/plaindoc/apps/server/internal/store"
+)
+
+const (
+	pollInterval = 5 * time.Second
+	// heartbeatInterval 是运行中作业刷新 updated_at 的间隔，超过 staleAfter 没有刷新的作业视为已中断。
+	heartbeatInterval = 30 * time.Second
+	staleAfter        = 2 * time.Minute
+	maintainInterval  = time.Minute
+	purgeInterval     = time.Hour
+)
+
+// ErrQueueFull 表示排队中的作业数已达到上限。
+var ErrQueueFull = errors.New("job queue is full")
+
+// errInterrupted 是服务关闭或进程退出时运行中作业记录的失败原因。
+var errInterrupted = errors.New("interrupted")
+
+// Handler 执行一个作业，返回值序列化为作业的 result；返回错误时作业记为失败，错误信息保存在作业上。
+type Handler func(ctx context.Context, task *Task) (any, error)
+
+// QueueOptions 配置 worker 数、排队上限、作业文件目录与作业记录的保留时间。
+type QueueOptions struct {
+	Workers int
+	Size    int
+	Dir     string
+	// Retention 之后删除已结束的作业及其文件。
+	Retention time.Duration
+}
+
+// Queue 持久化地排队并执行异步作业：作业先写入 jobs 表，再由 worker 按提交顺序认领执行。
+// 服务重启后排队中的作业继续执行；正常关闭时运行中的作业立即标记为失败，进程异常退出时在心跳过期后标记。
+type Queue struct {
+	store    *store.Store
+	opts     QueueOptions
+	logger   *slog.Logger
+	handlers map[string]Handler
+	wake     chan struct{}
+}
+
+func NewQueue(s *store.Store, opts QueueOptions, logger *slog.Logger) *Queue {
+	return &Queue{
+		store:    s,
+		opts:     opts,
+		logger:   logger,
+		handlers: map[string]Handler{},
+		wake:     make(chan struct{}, opts.Workers),
+	}
+}
+
+// Register 注册 kind 类作业的执行逻辑，需要在 Run 之前调用。
+func (q *Queue) Register(kind string, handler Handler) {
+	q.handlers[kind] = handler
+}
+
+// Submit 提交一个作业并唤醒空闲的 worker，不等待执行结果；排队中的作业已达上限时返回 ErrQueueFull。
+func (q *Queue) Submit(ctx context.Context, kind string, userID int64, params any) (*store.Job, error) {
+	if _, ok := q.handlers[kind]; !ok {
+		return nil, fmt.Errorf("unknown job kind %q", kind)
+	}
+	pending, err := q.store.CountPendingJobs(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if pending >= q.opts.Size {
+		return nil, ErrQueueFull
+	}
+	body, err := json.Marshal(params)
+	if err != nil {
+		return nil, err
+	}
+	job := &store.Job{Kind: kind, Params: body, CreatedBy: &userID}
+	if err := q.store.CreateJob(ctx, job); err != nil {
+		return nil, err
+	}
+	select {
+	case q.wake <- struct{}{}:
+	default:
+	}
+	return job, nil
+}
+
+// FilePath 返回作业 id 生成的文件 name 的保存路径。
+func (q *Queue) FilePath(id int64, name string) string {
+	return filepath.Join(q.jobDir(id), filepath.Base(name))
+}
+
+func (q *Queue) jobDir(id int64) string {
+	return filepath.Join(q.opts.Dir, strconv.FormatInt(id, 10))
+}
+
+// Run 启动 worker 执行排队中的作业，并定期回收中断的作业、清理过期的作业，直到 ctx 取消；
+// 返回前等待所有 worker 退出，运行中的作业会记为中断。
+func (q *Queue) Run(ctx context.Context) {
+	var workers sync.WaitGroup
+	defer workers.Wait()
+	for i := 0; i < q.opts.Workers; i++ {
+		workers.Add(1)
+		go func() {
+			defer workers.Done()
+			q.work(ctx)
+		}()
+	}
+	q.logger.Info("job queue started", "workers", q.opts.Workers)
+
+	ticker := time.NewTicker(maintainInterval)
+	defer ticker.Stop()
+	lastPurge := time.Time{}
+	for {
+		if failed, err := q.store.FailStaleJobs(ctx, time.Now().UTC().Add(-staleAfter), errInterrupted.Error()); err != nil {
+			q.logger.Error("recover interrupted jobs failed", "error", err)
+		} else if failed > 0 {
+			q.logger.Warn("marked interrupted jobs as failed", "count", failed)
+		}
+		if time.Since(lastPurge) > purgeInterval {
+			lastPurge = time.Now()
+			q.purge(ctx)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// work 逐个认领并执行作业，没有待执行的作业时等待唤醒或下一次轮询。
+func (q *Queue) work(ctx context.Context) {
+	ticker := time.NewTicker(pollInterval)
+	defer ticker.Stop()
+
+	for {
+		for ctx.Err() == nil {
+			job, err := q.store.ClaimNextJob(ctx)
+			if errors.Is(err, store.ErrNotFound) {
+				break
+			}
+			if err != nil {
+				q.logger.Error("claim job failed", "error", err)
+				break
+			}
+			q.execute(ctx, job)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		case <-q.wake:
+		}
+	}
+}
+
+func (q *Queue) execute(ctx context.Context, job *store.Job) {
+	task := &Task{Job: job, queue: q}
+	stop := make(chan struct{})
+	defer close(stop)
+	go task.heartbeat(ctx, stop)
+
+	result, err := q.call(ctx, task)
+	if err == nil {
+		job.Result, err = json.Marshal(result)
+	}
+	if err != nil && ctx.Err() != nil {
+		err = errInterrupted
+	}
+	if err != nil {
+		job.Status, job.Result, job.Error = store.JobFailed, nil, store.ErrorText(err)
+		q.logger.Warn("job failed", "job_id", job.ID, "kind", job.Kind, "error", err)
+		if err := os.RemoveAll(q.jobDir(job.ID)); err != nil {
+			q.logger.Error("remove job files failed", "job_id", job.ID, "error", err)
+		}
+	} else {
+		job.Status = store.JobSucceeded
+		q.logger.Info("job succeeded", "job_id", job.ID, "kind", job.Kind)
+	}
+	// 服务关闭时 ctx 已取消，结果仍要写入，否则作业要等心跳过期才会被标记为失败。
+	if err := q.store.FinishJob(context.WithoutCancel(ctx), job); err != nil {
+		q.logger.Error("save job result failed", "job_id", job.ID, "error", err)
+	}
+}
+
+// call 执行作业，把 panic 转为作业失败，避免一个作业拖垮整个进程。
+func (q *Queue) call(ctx context.Context, task *Task) (result any, err error) {
+	handler, ok := q.handlers[task.Job.Kind]
+	if !ok {
+		return nil, fmt.Errorf("unknown job kind %q", task.Job.Kind)
+	}
+	defer func() {
+		if recovered := recover(); recovered != nil {
+			err = fmt.Errorf("panic: %v", recovered)
+		}
+	}()
+	return handler(ctx, task)
+}
+
+// purge 删除保留期之前结束的作业及其文件。
+func (q *Queue) purge(ctx context.Context) {
+	ids, err := q.store.PurgeJobsBefore(ctx, time.Now().UTC().Add(-q.opts.Retention))
+	for _, id := range ids {
+		if err := os.RemoveAll(q.jobDir(id)); err != nil {
+			q.logger.Error("remove job files failed", "job_id", id, "error", err)
+		}
+	}
+	if err != nil {
+		q.logger.Error("purge expired jobs failed", "error", err)
+	} else if len(ids) > 0 {
+		q.logger.Info("purged expired jobs", "count", len(ids))
+	}
+}
+
+// Task 是传给 Handler 的运行中作业。
+type Task struct {
+	Job      *store.Job
+	queue    *Queue
+	progress atomic.Int32
+}
+
+// Params 把作业参数解析到 v。
+func (t *Task) Params(v any) error {
+	return json.Unmarshal(t.Job.Params, v)
+}
+
+// Progress 记录作业进度（0-99，结束时由队列记为 100），与上次记录相同时不写数据库。
+func (t *Task) Progress(ctx context.Context, percent int) error {
+	percent = min(max(percent, 0), 99)
+	if int(t.progress.Swap(int32(percent))) == percent {
+		return nil
+	}
+	return t.queue.store.UpdateJobProgress(ctx, t.Job.ID, percent)
+}
+
+// CreateFile 在作业目录中创建文件 name，作业成功后可通过 Queue.FilePath 读取，失败或过期时随作业一起删除。
+func (t *Task) CreateFile(name string) (*os.File, error) {
+	if err := os.MkdirAll(t.queue.jobDir(t.Job.ID), 0o755); err != nil {
+		return nil, err
+	}
+	return os.Create(t.queue.FilePath(t.Job.ID, name))
+}
+
+// heartbeat 定期刷新作业的 updated_at，直到 stop 关闭，表明作业仍在运行。
+func (t *Task) heartbeat(ctx context.Context, stop <-chan struct{}) {
+	ticker := time.NewTicker(heartbeatInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-stop:
+			return
+		case <-ticker.C:
+			if err := t.queue.store.UpdateJobProgress(ctx, t.Job.ID, int(t.progress.Load())); err != nil {
+				t.queue.logger.Error("job heartbeat failed", "job_id", t.Job.ID, "error", err)
+			}
+		}
+	}
+}
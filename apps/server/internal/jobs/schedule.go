@@ -0,0 +1,69 @@
+package jobs
+
+import (
+	"context"
+	"log/slog"
+	"time"
+
+	"github.com/lifei6671/plaindoc/apps/server/internal/search"
+	"github.com/lifei6671/plaindoc/apps/server/internal/store"
+	"github.com/lifei6671/plaindoc/apps/server/internal/webhook"
+)
+
+const (
+	scheduledPublishInterval = 30 * time.Second
+	scheduledPublishBatch    = 100
+)
+
+// PublishScheduledDocuments 每 30 秒发布一次到期的定时发布文档，直到 ctx 取消；启动时立即执行一次，补发服务停止期间到期的文档。
+// 发布后同步检索后端并通知 webhook，feed 与 sitemap 在缓存过期后包含新发布的文档。
+func PublishScheduledDocuments(ctx context.Context, s *store.Store, indexer search.Indexer, webhooks *webhook.Dispatcher, logger *slog.Logger) {
+	ticker := time.NewTicker(scheduledPublishInterval)
+	defer ticker.Stop()
+
+	for {
+		for ctx.Err() == nil {
+			docs, err := s.ListDueScheduledDocuments(ctx, time.Now().UTC(), scheduledPublishBatch)
+			if err != nil {
+				logger.Error("list scheduled documents failed", "error", err)
+				break
+			}
+			handled := 0
+			for i := range docs {
+				if publishScheduled(ctx, s, indexer, webhooks, &docs[i], logger) {
+					handled++
+				}
+			}
+			// 整批都失败时等下一轮再试，避免反复重试同一批文档。
+			if len(docs) < scheduledPublishBatch || handled == 0 {
+				break
+			}
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// publishScheduled 发布一篇到期的文档，返回是否已处理（发布成功，或已被手动发布、取消）。
+func publishScheduled(ctx context.Context, s *store.Store, indexer search.Indexer, webhooks *webhook.Dispatcher, doc *store.Document, logger *slog.Logger) bool {
+	published, err := s.PublishScheduledDocument(ctx, doc, time.Now().UTC())
+	if err != nil {
+		logger.Error("publish scheduled document failed", "doc_id", doc.ID, "error", err)
+		return false
+	}
+	if !published {
+		return true
+	}
+	logger.Info("published scheduled document", "doc_id", doc.ID)
+	if err := indexer.Index(ctx, search.NewDocument(doc)); err != nil {
+		logger.Error("index scheduled document failed", "doc_id", doc.ID, "error", err)
+	}
+	if err := webhooks.PublishDocument(ctx, webhook.EventDocPublished, doc); err != nil {
+		logger.Error("notify webhooks failed", "doc_id", doc.ID, "error", err)
+	}
+	return true
+}
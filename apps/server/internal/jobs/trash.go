@@ -0,0 +1,37 @@
+// Package jobs 包含随服务进程运行的后台定时任务，以及执行用户提交的异步作业的队列。
+package jobs
+
+import (
+	"context"
+	"log/slog"
+	"time"
+
+	"github.com/lifei6671/plaindoc/apps/server/internal/store"
+)
+
+const trashCleanupInterval = time.Hour
+
+// CleanupTrash 每小时彻底删除一次移入回收站超过 retentionDays 天的文档，直到 ctx 取消；retentionDays<=0 时直接返回。
+func CleanupTrash(ctx context.Context, s *store.Store, retentionDays int, logger *slog.Logger) {
+	if retentionDays <= 0 {
+		return
+	}
+	retention := time.Duration(retentionDays) * 24 * time.Hour
+	ticker := time.NewTicker(trashCleanupInterval)
+	defer ticker.Stop()
+
+	for {
+		purged, err := s.PurgeTrashBefore(ctx, time.Now().UTC().Add(-retention))
+		if err != nil {
+			logger.Error("purge expired trash failed", "error", err)
+		} else if purged > 0 {
+			logger.Info("purged expired trash", "count", purged, "retention_days", retentionDays)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
@@ -0,0 +1,34 @@
+package logging
+
+import (
+	"log/slog"
+	"os"
+	"strings"
+
+	"github.com/lifei6671/plaindoc/apps/server/internal/config"
+)
+
+// New 创建输出 JSON 的 slog logger；未配置 LOG_LEVEL 时生产环境默认 info，其余环境默认 debug。
+func New(cfg config.Config) *slog.Logger {
+	level := ParseLevel(cfg.LogLevel, cfg.Env)
+	handler := slog.NewJSONHandler(os.Stdout, &slog.HandlerOptions{Level: level})
+	return slog.New(handler)
+}
+
+// ParseLevel 解析 LOG_LEVEL，取值已由 config.Validate 校验。
+func ParseLevel(raw string, env string) slog.Level {
+	switch strings.ToLower(raw) {
+	case "debug":
+		return slog.LevelDebug
+	case "info":
+		return slog.LevelInfo
+	case "warn":
+		return slog.LevelWarn
+	case "error":
+		return slog.LevelError
+	}
+	if env == "production" {
+		return slog.LevelInfo
+	}
+	return slog.LevelDebug
+}
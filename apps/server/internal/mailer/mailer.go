@@ -0,0 +1,169 @@
+// Package mailer 负责发送通知邮件：模板用 html/template 渲染，投递在后台队列中异步进行并带重试，
+// 不阻塞发起请求的 handler。
+package mailer
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/mail"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	queueSize   = 256
+	workers     = 2
+	maxAttempts = 3
+	// retryDelay 是第一次重试前的等待时间，之后每次翻倍。
+	retryDelay  = 2 * time.Second
+	sendTimeout = 30 * time.Second
+)
+
+var (
+	// ErrQueueFull 表示待发送的邮件已堆满队列，本封邮件被丢弃。
+	ErrQueueFull = errors.New("mail queue is full")
+	// ErrClosed 表示 Mailer 已关闭，不再接收新邮件。
+	ErrClosed = errors.New("mailer is closed")
+)
+
+// Mailer 按模板发送邮件；SendTemplate 只负责渲染与入队，投递结果记录在日志中。
+type Mailer interface {
+	SendTemplate(to string, templateName string, data any) error
+}
+
+// Message 是渲染完成、待投递的一封邮件，HTML 为正文。
+type Message struct {
+	To      string
+	Subject string
+	HTML    string
+}
+
+// Sender 负责投递单封邮件，由队列在后台调用。
+type Sender interface {
+	Send(ctx context.Context, msg Message) error
+}
+
+// Queue 是 Mailer 的异步实现：固定数量的 worker 从有界队列中取出邮件投递，失败时按指数退避重试。
+type Queue struct {
+	sender    Sender
+	templates *Templates
+	logger    *slog.Logger
+
+	mu     sync.RWMutex
+	jobs   chan Message
+	closed bool
+	done   chan struct{}
+	wg     sync.WaitGroup
+}
+
+func NewQueue(sender Sender, templates *Templates, logger *slog.Logger) *Queue {
+	q := &Queue{
+		sender:    sender,
+		templates: templates,
+		logger:    logger,
+		jobs:      make(chan Message, queueSize),
+		done:      make(chan struct{}),
+	}
+	for range workers {
+		q.wg.Add(1)
+		go q.work()
+	}
+	return q
+}
+
+// SendTemplate 在调用方 goroutine 中校验收件人并渲染模板，错误立即返回；入队后即返回 nil。
+func (q *Queue) SendTemplate(to string, templateName string, data any) error {
+	address, err := mail.ParseAddress(to)
+	if err != nil {
+		return fmt.Errorf("invalid recipient %q: %w", to, err)
+	}
+	subject, body, err := q.templates.Render(templateName, data)
+	if err != nil {
+		return err
+	}
+	msg := Message{To: address.Address, Subject: subject, HTML: body}
+
+	q.mu.RLock()
+	defer q.mu.RUnlock()
+	if q.closed {
+		return ErrClosed
+	}
+	select {
+	case q.jobs <- msg:
+		return nil
+	default:
+		return ErrQueueFull
+	}
+}
+
+// Close 停止接收新邮件并等待队列中的邮件投递完；ctx 到期时放弃等待，未投递的邮件随进程退出丢失。
+func (q *Queue) Close(ctx context.Context) error {
+	q.mu.Lock()
+	if !q.closed {
+		q.closed = true
+		close(q.jobs)
+	}
+	q.mu.Unlock()
+
+	finished := make(chan struct{})
+	go func() {
+		q.wg.Wait()
+		close(finished)
+	}()
+	select {
+	case <-finished:
+		return nil
+	case <-ctx.Done():
+		// 通知 worker 放弃剩余的重试等待。
+		close(q.done)
+		return ctx.Err()
+	}
+}
+
+func (q *Queue) work() {
+	defer q.wg.Done()
+	for msg := range q.jobs {
+		q.deliver(msg)
+	}
+}
+
+func (q *Queue) deliver(msg Message) {
+	delay := retryDelay
+	for attempt := 1; ; attempt++ {
+		ctx, cancel := context.WithTimeout(context.Background(), sendTimeout)
+		err := q.sender.Send(ctx, msg)
+		cancel()
+		if err == nil {
+			return
+		}
+		if attempt == maxAttempts {
+			q.logger.Error("send mail failed", "to", msg.To, "subject", msg.Subject, "attempts", attempt, "error", err)
+			return
+		}
+		q.logger.Warn("send mail failed, will retry", "to", msg.To, "attempt", attempt, "retry_in", delay.String(), "error", err)
+		select {
+		case <-time.After(delay):
+		case <-q.done:
+			q.logger.Error("mail dropped on shutdown", "to", msg.To, "subject", msg.Subject)
+			return
+		}
+		delay *= 2
+	}
+}
+
+// LogSender 只把邮件内容写入日志，用于关闭邮件功能的开发环境。
+type LogSender struct {
+	logger *slog.Logger
+}
+
+func NewLogSender(logger *slog.Logger) *LogSender {
+	return &LogSender{logger: logger}
+}
+
+func (s *LogSender) Send(ctx context.Context, msg Message) error {
+	s.logger.Info("mail disabled, message not sent", "to", msg.To, "subject", msg.Subject, "body", strings.TrimSpace(msg.HTML))
+	return nil
+}
@@ -0,0 +1,152 @@
+package mailer
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"crypto/tls"
+	"encoding/hex"
+	"fmt"
+	"mime"
+	"mime/quotedprintable"
+	"net"
+	"net/mail"
+	"net/smtp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// implicitTLSPort 是 SMTPS 端口，连接建立时即进行 TLS 握手；其他端口在服务器支持时通过 STARTTLS 升级。
+const implicitTLSPort = 465
+
+// SMTPConfig 描述 SMTP 服务器与发件人；Username 为空时不进行认证。
+type SMTPConfig struct {
+	Host     string
+	Port     int
+	Username string
+	Password string
+	From     string
+}
+
+// SMTP 通过 SMTP 服务器投递邮件，每封邮件使用一个独立连接。
+type SMTP struct {
+	cfg  SMTPConfig
+	from *mail.Address
+}
+
+func NewSMTP(cfg SMTPConfig) (*SMTP, error) {
+	from, err := mail.ParseAddress(cfg.From)
+	if err != nil {
+		return nil, fmt.Errorf("invalid sender %q: %w", cfg.From, err)
+	}
+	return &SMTP{cfg: cfg, from: from}, nil
+}
+
+func (s *SMTP) Send(ctx context.Context, msg Message) error {
+	conn, err := s.dial(ctx)
+	if err != nil {
+		return err
+	}
+	if deadline, ok := ctx.Deadline(); ok {
+		_ = conn.SetDeadline(deadline)
+	}
+	client, err := smtp.NewClient(conn, s.cfg.Host)
+	if err != nil {
+		conn.Close()
+		return err
+	}
+	defer client.Close()
+
+	if s.cfg.Port != implicitTLSPort {
+		if ok, _ := client.Extension("STARTTLS"); ok {
+			if err := client.StartTLS(&tls.Config{ServerName: s.cfg.Host}); err != nil {
+				return err
+			}
+		}
+	}
+	// PlainAuth 拒绝在未加密的非本机连接上发送密码。
+	if s.cfg.Username != "" {
+		if err := client.Auth(smtp.PlainAuth("", s.cfg.Username, s.cfg.Password, s.cfg.Host)); err != nil {
+			return err
+		}
+	}
+	if err := client.Mail(s.from.Address); err != nil {
+		return err
+	}
+	if err := client.Rcpt(msg.To); err != nil {
+		return err
+	}
+	w, err := client.Data()
+	if err != nil {
+		return err
+	}
+	if _, err := w.Write(s.compose(msg)); err != nil {
+		w.Close()
+		return err
+	}
+	if err := w.Close(); err != nil {
+		return err
+	}
+	return client.Quit()
+}
+
+// Ping 连接 SMTP 服务器并完成握手后退出，不投递邮件，用于健康检查。
+func (s *SMTP) Ping(ctx context.Context) error {
+	conn, err := s.dial(ctx)
+	if err != nil {
+		return err
+	}
+	if deadline, ok := ctx.Deadline(); ok {
+		_ = conn.SetDeadline(deadline)
+	}
+	client, err := smtp.NewClient(conn, s.cfg.Host)
+	if err != nil {
+		conn.Close()
+		return err
+	}
+	defer client.Close()
+	if err := client.Noop(); err != nil {
+		return err
+	}
+	return client.Quit()
+}
+
+func (s *SMTP) dial(ctx context.Context) (net.Conn, error) {
+	addr := net.JoinHostPort(s.cfg.Host, strconv.Itoa(s.cfg.Port))
+	dialer := &net.Dialer{}
+	if s.cfg.Port == implicitTLSPort {
+		tlsDialer := &tls.Dialer{NetDialer: dialer, Config: &tls.Config{ServerName: s.cfg.Host}}
+		return tlsDialer.DialContext(ctx, "tcp", addr)
+	}
+	return dialer.DialContext(ctx, "tcp", addr)
+}
+
+// compose 生成 RFC 5322 格式的邮件；标题使用 RFC 2047 编码，正文使用 quoted-printable。
+func (s *SMTP) compose(msg Message) []byte {
+	var buf bytes.Buffer
+	header := func(key, value string) {
+		buf.WriteString(key + ": " + value + "\r\n")
+	}
+	header("From", s.from.String())
+	header("To", msg.To)
+	header("Subject", mime.QEncoding.Encode("utf-8", msg.Subject))
+	header("Date", time.Now().Format(time.RFC1123Z))
+	header("Message-ID", s.messageID())
+	header("MIME-Version", "1.0")
+	header("Content-Type", "text/html; charset=utf-8")
+	header("Content-Transfer-Encoding", "quoted-printable")
+	buf.WriteString("\r\n")
+
+	qp := quotedprintable.NewWriter(&buf)
+	_, _ = qp.Write([]byte(msg.HTML))
+	_ = qp.Close()
+	return buf.Bytes()
+}
+
+func (s *SMTP) messageID() string {
+	random := make([]byte, 12)
+	_, _ = rand.Read(random)
+	domain := s.from.Address[strings.LastIndex(s.from.Address, "@")+1:]
+	return "<" + hex.EncodeToString(random) + "@" + domain + ">"
+}
@@ -0,0 +1,60 @@
+package mailer
+
+import (
+	"bytes"
+	"embed"
+	"fmt"
+	"html"
+	"html/template"
+	"io/fs"
+	"path"
+	"strings"
+)
+
+//go:embed templates/*.html
+var templateFiles embed.FS
+
+// Templates 是内置的邮件模板。每个 templates/<name>.html 用 {{define "subject"}} 定义标题，
+// 其余内容作为正文；模板之间互不可见，同名的 define 不会冲突。
+type Templates struct {
+	byName map[string]*template.Template
+}
+
+func LoadTemplates() (*Templates, error) {
+	names, err := fs.Glob(templateFiles, "templates/*.html")
+	if err != nil {
+		return nil, err
+	}
+	t := &Templates{byName: make(map[string]*template.Template, len(names))}
+	for _, file := range names {
+		parsed, err := template.ParseFS(templateFiles, file)
+		if err != nil {
+			return nil, fmt.Errorf("parse mail template %s: %w", file, err)
+		}
+		if parsed.Lookup("subject") == nil {
+			return nil, fmt.Errorf("mail template %s: missing subject", file)
+		}
+		t.byName[strings.TrimSuffix(path.Base(file), ".html")] = parsed
+	}
+	return t, nil
+}
+
+// Render 返回模板渲染出的标题与 HTML 正文。标题按 HTML 上下文转义后再还原为纯文本，并压缩为单行，
+// 避免数据中的换行被写进邮件头。
+func (t *Templates) Render(name string, data any) (subject string, body string, err error) {
+	tmpl, ok := t.byName[name]
+	if !ok {
+		return "", "", fmt.Errorf("mail template %q not found", name)
+	}
+	var buf bytes.Buffer
+	if err := tmpl.ExecuteTemplate(&buf, "subject", data); err != nil {
+		return "", "", fmt.Errorf("render mail template %s subject: %w", name, err)
+	}
+	subject = strings.Join(strings.Fields(html.UnescapeString(buf.String())), " ")
+
+	buf.Reset()
+	if err := tmpl.Execute(&buf, data); err != nil {
+		return "", "", fmt.Errorf("render mail template %s: %w", name, err)
+	}
+	return subject, buf.String(), nil
+}
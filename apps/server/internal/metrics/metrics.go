@@ -0,0 +1,82 @@
+package metrics
+
+import (
+	"database/sql"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+const namespace = "plaindoc"
+
+// Metrics 持有服务的 Prometheus 指标；使用独立的 Registry，只暴露这里显式注册的指标。
+type Metrics struct {
+	registry *prometheus.Registry
+	requests *prometheus.CounterVec
+	duration *prometheus.HistogramVec
+	inFlight prometheus.Gauge
+	cache    *prometheus.CounterVec
+}
+
+// New 注册 HTTP 指标、Go 运行时与进程指标；db 不为 nil 时同时采集连接池状态。
+func New(db *sql.DB) *Metrics {
+	m := &Metrics{
+		registry: prometheus.NewRegistry(),
+		requests: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "http_requests_total",
+			Help:      "Total number of HTTP requests by method, route and status code.",
+		}, []string{"method", "path", "status"}),
+		duration: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: namespace,
+			Name:      "http_request_duration_seconds",
+			Help:      "HTTP request latency by method and route.",
+			Buckets:   prometheus.DefBuckets,
+		}, []string{"method", "path"}),
+		inFlight: prometheus.NewGauge(prometheus.GaugeOpts{
+			Namespace: namespace,
+			Name:      "http_requests_in_flight",
+			Help:      "Number of HTTP requests currently being served.",
+		}),
+		cache: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "cache_lookups_total",
+			Help:      "Total number of cache lookups by cache name and result (hit, miss, error).",
+		}, []string{"cache", "result"}),
+	}
+	m.registry.MustRegister(
+		m.requests, m.duration, m.inFlight, m.cache,
+		collectors.NewGoCollector(),
+		collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}),
+	)
+	if db != nil {
+		// 包含 open/in_use/idle 连接数以及 wait_count、wait_duration 等连接池等待情况。
+		m.registry.MustRegister(collectors.NewDBStatsCollector(db, namespace))
+	}
+	return m
+}
+
+// Handler 返回 Prometheus 抓取用的 HTTP 处理器。
+func (m *Metrics) Handler() http.Handler {
+	return promhttp.HandlerFor(m.registry, promhttp.HandlerOpts{})
+}
+
+func (m *Metrics) RequestStarted() {
+	m.inFlight.Inc()
+}
+
+// RequestFinished 记录一个已完成的请求；route 应是路由模板（如 /api/v1/docs/:id）而不是原始路径，避免标签基数失控。
+func (m *Metrics) RequestFinished(method string, route string, status int, elapsed time.Duration) {
+	m.inFlight.Dec()
+	m.requests.WithLabelValues(method, route, strconv.Itoa(status)).Inc()
+	m.duration.WithLabelValues(method, route).Observe(elapsed.Seconds())
+}
+
+// CacheLookup 记录一次缓存查询，实现 cache.Observer。
+func (m *Metrics) CacheLookup(name string, result string) {
+	m.cache.WithLabelValues(name, result).Inc()
+}
@@ -0,0 +1,111 @@
+package migrate
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/lifei6671/plaindoc/apps/server/internal/database"
+)
+
+// ErrLockTimeout 表示在 lockTimeout 内没有拿到迁移锁，通常是另一个实例的迁移耗时过长或异常持有锁。
+var ErrLockTimeout = errors.New("timed out waiting for the migration lock")
+
+const (
+	// lockName 是 MySQL GET_LOCK 的锁名，同一个 MySQL 实例上的所有库共享锁名空间，因此带上应用前缀。
+	lockName = "plaindoc_schema_migrations"
+	// advisoryLockKey 是 PostgreSQL advisory lock 的键（lockName 的 FNV-1a 64 位哈希）。
+	advisoryLockKey int64 = 0x7d7fe25133edd4c5
+	// lockPollInterval 是未拿到锁时重试的间隔。
+	lockPollInterval = time.Second
+	// unlockTimeout 限制释放锁的耗时；释放不依赖调用方的 ctx，调用方取消后也能释放。
+	unlockTimeout = 10 * time.Second
+)
+
+// withLock 持有迁移锁执行 fn，fn 返回或 panic 后都会释放锁，保证多个实例同时启动时迁移只由一个实例执行，
+// 其余实例等到锁释放后再读取版本，此时已没有待执行的迁移。
+// 锁绑定在一个专用连接上：MySQL 使用 GET_LOCK，PostgreSQL 使用会话级 advisory lock，连接断开时数据库也会释放锁。
+// SQLite 不支持多个实例共享同一个数据库文件，不加锁。
+func (m *Migrator) withLock(ctx context.Context, fn func() error) (err error) {
+	if m.dialect == database.SQLite {
+		return fn()
+	}
+	conn, err := m.db.Conn(ctx)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+
+	if err := m.lock(ctx, conn); err != nil {
+		return err
+	}
+	defer func() {
+		if unlockErr := m.unlock(conn); unlockErr != nil {
+			// 释放失败时让连接池丢弃该连接，由数据库在会话结束时回收锁，避免持锁的连接被复用。
+			_ = conn.Raw(func(any) error { return driver.ErrBadConn })
+			if err == nil {
+				err = fmt.Errorf("release migration lock: %w", unlockErr)
+			}
+		}
+	}()
+	return fn()
+}
+
+// lock 轮询获取锁直到成功、超时或 ctx 取消；lockTimeout 为 0 时一直等待。
+func (m *Migrator) lock(ctx context.Context, conn *sql.Conn) error {
+	waitCtx := ctx
+	if m.lockTimeout > 0 {
+		var cancel context.CancelFunc
+		waitCtx, cancel = context.WithTimeout(ctx, m.lockTimeout)
+		defer cancel()
+	}
+	query, arg := "SELECT GET_LOCK(?, 0)", any(lockName)
+	if m.dialect == database.Postgres {
+		query, arg = "SELECT pg_try_advisory_lock($1)", advisoryLockKey
+	}
+
+	ticker := time.NewTicker(lockPollInterval)
+	defer ticker.Stop()
+	for {
+		// GET_LOCK 返回 1/0，出错时返回 NULL；pg_try_advisory_lock 返回布尔值。
+		var acquired sql.NullBool
+		err := conn.QueryRowContext(waitCtx, query, arg).Scan(&acquired)
+		if err == nil && acquired.Bool {
+			return nil
+		}
+		if err == nil && !acquired.Valid {
+			err = errors.New("GET_LOCK returned NULL")
+		}
+		if err != nil && waitCtx.Err() == nil {
+			return fmt.Errorf("acquire migration lock: %w", err)
+		}
+		select {
+		case <-waitCtx.Done():
+			if ctx.Err() != nil {
+				return ctx.Err()
+			}
+			return fmt.Errorf("%w after %s", ErrLockTimeout, m.lockTimeout)
+		case <-ticker.C:
+		}
+	}
+}
+
+func (m *Migrator) unlock(conn *sql.Conn) error {
+	ctx, cancel := context.WithTimeout(context.Background(), unlockTimeout)
+	defer cancel()
+	query, arg := "SELECT RELEASE_LOCK(?)", any(lockName)
+	if m.dialect == database.Postgres {
+		query, arg = "SELECT pg_advisory_unlock($1)", advisoryLockKey
+	}
+	var released sql.NullBool
+	if err := conn.QueryRowContext(ctx, query, arg).Scan(&released); err != nil {
+		return err
+	}
+	if !released.Bool {
+		return errors.New("lock was not held by this connection")
+	}
+	return nil
+}
@@ -0,0 +1,357 @@
+package migrate
+
+import (
+	"context"
+	"database/sql"
+	"embed"
+	"errors"
+	"fmt"
+	"io/fs"
+	"path"
+	"sort"
+	"strconv"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	"github.com/lifei6671/plaindoc/apps/server/internal/database"
+)
+
+//go:embed migrations
+var migrationFiles embed.FS
+
+var (
+	// ErrDirty 表示上一次迁移中途失败，需要人工修复后再继续。
+	ErrDirty = errors.New("database schema is dirty")
+	// ErrBehind 表示数据库还有未执行的迁移，代码可能引用尚不存在的表或列。
+	ErrBehind = errors.New("database schema is behind this build")
+	// ErrAhead 表示数据库版本比代码内置的最新迁移还新，通常是回滚了代码但没有回滚数据库。
+	ErrAhead = errors.New("database schema is ahead of this build")
+)
+
+// Migration 对应 migrations/<driver> 目录下的一对 NNNNNN_name.up.sql / NNNNNN_name.down.sql 文件。
+type Migration struct {
+	Version uint
+	Name    string
+	Up      string
+	Down    string
+}
+
+// Migrator 基于 schema_migrations 表记录当前版本与 dirty 状态。
+// 各驱动的迁移脚本分目录维护，版本号与语义保持一一对应。
+type Migrator struct {
+	db         *sql.DB
+	dialect    database.Dialect
+	migrations []Migration
+	ensured    atomic.Bool
+	// lockTimeout 是 Up、Down、Force 等待迁移锁的最长时间，0 表示一直等待。
+	lockTimeout time.Duration
+}
+
+func New(db *sql.DB, dialect database.Dialect, lockTimeout time.Duration) (*Migrator, error) {
+	migrations, err := load(migrationFiles, path.Join("migrations", string(dialect)))
+	if err != nil {
+		return nil, err
+	}
+	if len(migrations) == 0 {
+		return nil, fmt.Errorf("no migrations for database driver %q", dialect)
+	}
+	return &Migrator{db: db, dialect: dialect, migrations: migrations, lockTimeout: lockTimeout}, nil
+}
+
+// Latest 返回代码中内置的最新迁移版本，没有迁移时为 0。
+func (m *Migrator) Latest() uint {
+	if len(m.migrations) == 0 {
+		return 0
+	}
+	return m.migrations[len(m.migrations)-1].Version
+}
+
+// Version 返回数据库当前迁移版本，尚未执行过迁移时为 0。
+func (m *Migrator) Version(ctx context.Context) (uint, bool, error) {
+	if err := m.ensureTable(ctx); err != nil {
+		return 0, false, err
+	}
+
+	var (
+		version uint
+		dirty   bool
+	)
+	err := m.db.QueryRowContext(ctx, "SELECT version, dirty FROM schema_migrations LIMIT 1").Scan(&version, &dirty)
+	if errors.Is(err, sql.ErrNoRows) {
+		return 0, false, nil
+	}
+	if err != nil {
+		return 0, false, fmt.Errorf("read schema version: %w", err)
+	}
+	return version, dirty, nil
+}
+
+// Check 比较数据库当前版本与代码内置的最新版本，一致时返回 nil，否则返回包装了 ErrDirty、ErrBehind 或 ErrAhead 的错误。
+func (m *Migrator) Check(ctx context.Context) error {
+	current, dirty, err := m.Version(ctx)
+	if err != nil {
+		return err
+	}
+	latest := m.Latest()
+	switch {
+	case dirty:
+		return dirtyError(current)
+	case current < latest:
+		return fmt.Errorf("%w: database is at version %d, this build expects %d", ErrBehind, current, latest)
+	case current > latest:
+		return fmt.Errorf("%w: database is at version %d, this build only knows up to %d", ErrAhead, current, latest)
+	}
+	return nil
+}
+
+// Up 按版本顺序执行所有尚未应用的迁移。
+func (m *Migrator) Up(ctx context.Context) error {
+	return m.withLock(ctx, func() error { return m.up(ctx) })
+}
+
+func (m *Migrator) up(ctx context.Context) error {
+	current, dirty, err := m.Version(ctx)
+	if err != nil {
+		return err
+	}
+	if dirty {
+		return dirtyError(current)
+	}
+
+	for _, migration := range m.migrations {
+		if migration.Version <= current {
+			continue
+		}
+		if err := m.apply(ctx, migration.Version, migration.Up); err != nil {
+			return fmt.Errorf("migration %d_%s: %w", migration.Version, migration.Name, err)
+		}
+	}
+	return nil
+}
+
+// Down 回滚最近 n 个已应用的迁移。
+func (m *Migrator) Down(ctx context.Context, n int) error {
+	if n <= 0 {
+		return fmt.Errorf("down steps must be positive, got %d", n)
+	}
+	return m.withLock(ctx, func() error { return m.down(ctx, n) })
+}
+
+func (m *Migrator) down(ctx context.Context, n int) error {
+	current, dirty, err := m.Version(ctx)
+	if err != nil {
+		return err
+	}
+	if dirty {
+		return dirtyError(current)
+	}
+
+	for step := 0; step < n && current > 0; step++ {
+		index := m.indexOf(current)
+		if index < 0 {
+			return fmt.Errorf("database version %d is not a known migration, cannot roll back", current)
+		}
+		migration := m.migrations[index]
+		if migration.Down == "" {
+			return fmt.Errorf("migration %d_%s has no down script", migration.Version, migration.Name)
+		}
+
+		var previous uint
+		if index > 0 {
+			previous = m.migrations[index-1].Version
+		}
+		if err := m.revert(ctx, migration, previous); err != nil {
+			return fmt.Errorf("rollback %d_%s: %w", migration.Version, migration.Name, err)
+		}
+		current = previous
+	}
+	return nil
+}
+
+// Force 直接把版本记录改为 version 并清除 dirty 标记，不执行任何迁移脚本。
+// 用于人工修复失败的迁移后校正状态；version 为 0 表示回到未迁移状态。
+func (m *Migrator) Force(ctx context.Context, version uint) error {
+	if version != 0 && m.indexOf(version) < 0 {
+		return fmt.Errorf("version %d is not a known migration", version)
+	}
+	return m.withLock(ctx, func() error {
+		if err := m.ensureTable(ctx); err != nil {
+			return err
+		}
+		return m.setVersion(ctx, version, false)
+	})
+}
+
+// Migrations 返回按版本排序的全部内置迁移。
+func (m *Migrator) Migrations() []Migration {
+	return m.migrations
+}
+
+func (m *Migrator) indexOf(version uint) int {
+	for i, migration := range m.migrations {
+		if migration.Version == version {
+			return i
+		}
+	}
+	return -1
+}
+
+// revert 回滚期间保持当前版本为 dirty，成功后记录为上一个版本。
+func (m *Migrator) revert(ctx context.Context, migration Migration, previous uint) error {
+	if err := m.setVersion(ctx, migration.Version, true); err != nil {
+		return err
+	}
+	if err := m.run(ctx, migration.Down); err != nil {
+		return err
+	}
+	return m.setVersion(ctx, previous, false)
+}
+
+func dirtyError(version uint) error {
+	return fmt.Errorf("%w at version %d: a previous migration failed halfway; "+
+		"fix the schema by hand, then run `migrate force <version>` with the last version that is fully applied", ErrDirty, version)
+}
+
+// apply 先把目标版本标记为 dirty，全部语句成功后再清除标记。
+func (m *Migrator) apply(ctx context.Context, version uint, script string) error {
+	if err := m.setVersion(ctx, version, true); err != nil {
+		return err
+	}
+	if err := m.run(ctx, script); err != nil {
+		return err
+	}
+	return m.setVersion(ctx, version, false)
+}
+
+// run 在同一个连接上依次执行脚本中的语句：SQLite 重建表时需要用 PRAGMA foreign_keys 临时关闭外键，
+// 该设置只对当前连接生效。
+func (m *Migrator) run(ctx context.Context, script string) error {
+	conn, err := m.db.Conn(ctx)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+
+	for _, statement := range splitStatements(script) {
+		if _, err := conn.ExecContext(ctx, statement); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (m *Migrator) ensureTable(ctx context.Context) error {
+	if m.ensured.Load() {
+		return nil
+	}
+	_, err := m.db.ExecContext(ctx, `CREATE TABLE IF NOT EXISTS schema_migrations (
+	version BIGINT NOT NULL PRIMARY KEY,
+	dirty BOOLEAN NOT NULL
+)`)
+	if err != nil {
+		return fmt.Errorf("create schema_migrations: %w", err)
+	}
+	m.ensured.Store(true)
+	return nil
+}
+
+func (m *Migrator) setVersion(ctx context.Context, version uint, dirty bool) error {
+	tx, err := m.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.ExecContext(ctx, "DELETE FROM schema_migrations"); err != nil {
+		return fmt.Errorf("reset schema version: %w", err)
+	}
+	if version > 0 {
+		if _, err := tx.ExecContext(ctx, m.dialect.Rebind("INSERT INTO schema_migrations (version, dirty) VALUES (?, ?)"), version, dirty); err != nil {
+			return fmt.Errorf("write schema version: %w", err)
+		}
+	}
+	return tx.Commit()
+}
+
+func load(fsys fs.FS, dir string) ([]Migration, error) {
+	entries, err := fs.ReadDir(fsys, dir)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("read migrations: %w", err)
+	}
+
+	byVersion := map[uint]*Migration{}
+	for _, entry := range entries {
+		name := entry.Name()
+		var direction string
+		switch {
+		case strings.HasSuffix(name, ".up.sql"):
+			direction = "up"
+		case strings.HasSuffix(name, ".down.sql"):
+			direction = "down"
+		default:
+			continue
+		}
+
+		prefix, rest, ok := strings.Cut(strings.TrimSuffix(name, "."+direction+".sql"), "_")
+		version, err := strconv.ParseUint(prefix, 10, 64)
+		if !ok || err != nil || version == 0 {
+			return nil, fmt.Errorf("migration %s: file name must look like 000001_name.up.sql", name)
+		}
+
+		content, err := fs.ReadFile(fsys, path.Join(dir, name))
+		if err != nil {
+			return nil, fmt.Errorf("read migration %s: %w", name, err)
+		}
+
+		migration := byVersion[uint(version)]
+		if migration == nil {
+			migration = &Migration{Version: uint(version), Name: rest}
+			byVersion[uint(version)] = migration
+		}
+		if direction == "up" {
+			migration.Up = string(content)
+		} else {
+			migration.Down = string(content)
+		}
+	}
+
+	migrations := make([]Migration, 0, len(byVersion))
+	for _, migration := range byVersion {
+		if migration.Up == "" {
+			return nil, fmt.Errorf("migration %d_%s: missing up script", migration.Version, migration.Name)
+		}
+		migrations = append(migrations, *migration)
+	}
+	sort.Slice(migrations, func(i, j int) bool {
+		return migrations[i].Version < migrations[j].Version
+	})
+	return migrations, nil
+}
+
+// splitStatements 以行尾分号切分语句，驱动层无需开启 multiStatements。
+func splitStatements(script string) []string {
+	var (
+		statements []string
+		current    strings.Builder
+	)
+	for _, line := range strings.Split(script, "\n") {
+		trimmed := strings.TrimSpace(line)
+		if trimmed == "" || strings.HasPrefix(trimmed, "--") {
+			continue
+		}
+		current.WriteString(line)
+		current.WriteString("\n")
+		if strings.HasSuffix(trimmed, ";") {
+			statements = append(statements, strings.TrimSpace(current.String()))
+			current.Reset()
+		}
+	}
+	if rest := strings.TrimSpace(current.String()); rest != "" {
+		statements = append(statements, rest)
+	}
+	return statements
+}
@@ -0,0 +1,274 @@
+package migrate
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/lifei6671/plaindoc/apps/server/internal/config"
+	"github.com/lifei6671/plaindoc/apps/server/internal/database"
+)
+
+// testDSNs 是在 PostgreSQL 与 MySQL 上运行测试所用连接串的环境变量，未设置时跳过对应方言的测试。
+// 测试会回滚库中的全部迁移，请指向专用的空库，并用 -p 1 避免多个包同时操作同一个库。
+var testDSNs = map[database.Dialect]string{
+	database.Postgres: "PLAINDOC_TEST_POSTGRES_DSN",
+	database.MySQL:    "PLAINDOC_TEST_MYSQL_DSN",
+}
+
+// newMigrator 连接 dialect 的测试库：SQLite 在临时目录中创建全新的数据库，其他方言读取 testDSNs 中的环境变量。
+func newMigrator(t *testing.T, dialect database.Dialect) (*Migrator, *sql.DB) {
+	t.Helper()
+	dsn := filepath.Join(t.TempDir(), "plaindoc.db")
+	if dialect != database.SQLite {
+		if dsn = os.Getenv(testDSNs[dialect]); dsn == "" {
+			t.Skipf("%s not set", testDSNs[dialect])
+		}
+	}
+	db, dialect, err := database.Open(config.Config{DBDriver: string(dialect), DBDSN: dsn})
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { db.Close() })
+	migrator, err := New(db, dialect, time.Minute)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return migrator, db
+}
+
+func assertVersion(t *testing.T, m *Migrator, want uint) {
+	t.Helper()
+	version, dirty, err := m.Version(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if version != want || dirty {
+		t.Fatalf("Version() = %d, dirty %v, want %d, clean", version, dirty, want)
+	}
+}
+
+// tableQueries 按方言列出当前库中除 schema_migrations 以外的表名。
+var tableQueries = map[database.Dialect]string{
+	database.SQLite:   "SELECT name FROM sqlite_master WHERE type = 'table' AND name NOT LIKE 'sqlite_%' AND name <> 'schema_migrations' ORDER BY name",
+	database.Postgres: "SELECT table_name FROM information_schema.tables WHERE table_schema = current_schema() AND table_name <> 'schema_migrations' ORDER BY table_name",
+	database.MySQL:    "SELECT table_name FROM information_schema.tables WHERE table_schema = DATABASE() AND table_name <> 'schema_migrations' ORDER BY table_name",
+}
+
+func tables(t *testing.T, m *Migrator, db *sql.DB) []string {
+	t.Helper()
+	rows, err := db.Query(tableQueries[m.dialect])
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer rows.Close()
+	var names []string
+	for rows.Next() {
+		var name string
+		if err := rows.Scan(&name); err != nil {
+			t.Fatal(err)
+		}
+		names = append(names, name)
+	}
+	if err := rows.Err(); err != nil {
+		t.Fatal(err)
+	}
+	return names
+}
+
+func TestUpDownSQLite(t *testing.T) {
+	ctx := context.Background()
+	probe, _ := newMigrator(t, database.SQLite)
+	total := len(probe.Migrations())
+
+	tests := []struct {
+		name  string
+		steps int
+	}{
+		{"one step", 1},
+		{"three steps", 3},
+		{"half", total / 2},
+		{"all", total},
+		{"more than applied", total + 5},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m, db := newMigrator(t, database.SQLite)
+			if err := m.Up(ctx); err != nil {
+				t.Fatalf("Up() = %v", err)
+			}
+			assertVersion(t, m, m.Latest())
+			if err := m.Check(ctx); err != nil {
+				t.Fatalf("Check() after Up = %v", err)
+			}
+			applied := tables(t, m, db)
+
+			if err := m.Down(ctx, tt.steps); err != nil {
+				t.Fatalf("Down(%d) = %v", tt.steps, err)
+			}
+			var want uint
+			if remaining := total - tt.steps; remaining > 0 {
+				want = m.Migrations()[remaining-1].Version
+			}
+			assertVersion(t, m, want)
+			if err := m.Check(ctx); !errors.Is(err, ErrBehind) {
+				t.Fatalf("Check() after Down = %v, want ErrBehind", err)
+			}
+			if want == 0 {
+				if left := tables(t, m, db); len(left) != 0 {
+					t.Fatalf("tables left after rolling back everything: %v", left)
+				}
+			}
+
+			// 回滚后再次升级应得到完全相同的表。
+			if err := m.Up(ctx); err != nil {
+				t.Fatalf("Up() after Down = %v", err)
+			}
+			assertVersion(t, m, m.Latest())
+			if again := tables(t, m, db); !reflect.DeepEqual(again, applied) {
+				t.Fatalf("tables after re-applying = %v, want %v", again, applied)
+			}
+		})
+	}
+}
+
+// 每种方言都要能在空库上执行全部迁移、全部回滚后再次执行，得到相同的表。
+func TestFromScratch(t *testing.T) {
+	ctx := context.Background()
+	for _, dialect := range []database.Dialect{database.SQLite, database.Postgres, database.MySQL} {
+		t.Run(string(dialect), func(t *testing.T) {
+			m, db := newMigrator(t, dialect)
+			total := len(m.Migrations())
+
+			// 测试库可能留有上次运行的表，先升到最新再全部回滚，保证从空库开始。
+			if err := m.Up(ctx); err != nil {
+				t.Fatalf("Up() before reset = %v", err)
+			}
+			if err := m.Down(ctx, total); err != nil {
+				t.Fatalf("Down(all) before reset = %v", err)
+			}
+			assertVersion(t, m, 0)
+			if left := tables(t, m, db); len(left) != 0 {
+				t.Fatalf("tables left after rolling back everything: %v", left)
+			}
+
+			if err := m.Up(ctx); err != nil {
+				t.Fatalf("Up() on empty database = %v", err)
+			}
+			assertVersion(t, m, m.Latest())
+			if err := m.Check(ctx); err != nil {
+				t.Fatalf("Check() after Up = %v", err)
+			}
+			applied := tables(t, m, db)
+			if len(applied) == 0 {
+				t.Fatal("no tables created")
+			}
+
+			if err := m.Down(ctx, total); err != nil {
+				t.Fatalf("Down(all) = %v", err)
+			}
+			assertVersion(t, m, 0)
+			if left := tables(t, m, db); len(left) != 0 {
+				t.Fatalf("tables left after Down(all): %v", left)
+			}
+
+			if err := m.Up(ctx); err != nil {
+				t.Fatalf("Up() after Down(all) = %v", err)
+			}
+			assertVersion(t, m, m.Latest())
+			if again := tables(t, m, db); !reflect.DeepEqual(again, applied) {
+				t.Fatalf("tables after re-applying = %v, want %v", again, applied)
+			}
+		})
+	}
+}
+
+func TestUpRefusesDirtySchema(t *testing.T) {
+	ctx := context.Background()
+	m, _ := newMigrator(t, database.SQLite)
+	if err := m.Up(ctx); err != nil {
+		t.Fatal(err)
+	}
+	if err := m.setVersion(ctx, m.Latest(), true); err != nil {
+		t.Fatal(err)
+	}
+	if err := m.Up(ctx); !errors.Is(err, ErrDirty) {
+		t.Fatalf("Up() on dirty schema = %v, want ErrDirty", err)
+	}
+	if err := m.Down(ctx, 1); !errors.Is(err, ErrDirty) {
+		t.Fatalf("Down() on dirty schema = %v, want ErrDirty", err)
+	}
+	if err := m.Force(ctx, m.Latest()); err != nil {
+		t.Fatal(err)
+	}
+	if err := m.Check(ctx); err != nil {
+		t.Fatalf("Check() after Force = %v", err)
+	}
+}
+
+// 每个迁移都必须在所有方言目录中存在且同名，否则换驱动后的表结构会与代码不一致。
+func TestDialectsInSync(t *testing.T) {
+	reference, err := load(migrationFiles, "migrations/"+string(database.MySQL))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, dialect := range []database.Dialect{database.Postgres, database.SQLite} {
+		t.Run(string(dialect), func(t *testing.T) {
+			migrations, err := load(migrationFiles, "migrations/"+string(dialect))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(migrations) != len(reference) {
+				t.Fatalf("%s has %d migrations, mysql has %d", dialect, len(migrations), len(reference))
+			}
+			for i, migration := range migrations {
+				if migration.Version != reference[i].Version || migration.Name != reference[i].Name {
+					t.Errorf("%s migration %d_%s, mysql has %d_%s", dialect, migration.Version, migration.Name, reference[i].Version, reference[i].Name)
+				}
+				if migration.Down == "" {
+					t.Errorf("%s migration %d_%s has no down script", dialect, migration.Version, migration.Name)
+				}
+			}
+		})
+	}
+}
+
+func TestSplitStatements(t *testing.T) {
+	tests := []struct {
+		name   string
+		script string
+		want   []string
+	}{
+		{"empty", "", nil},
+		{"comments only", "-- comment\n  -- another\n", nil},
+		{"single", "CREATE TABLE a (id INT);\n", []string{"CREATE TABLE a (id INT);"}},
+		{
+			name:   "multi line",
+			script: "CREATE TABLE a (\n  id INT\n);\nDROP TABLE b;",
+			want:   []string{"CREATE TABLE a (\n  id INT\n);", "DROP TABLE b;"},
+		},
+		{
+			name:   "comments and blank lines skipped",
+			script: "-- header\n\nCREATE INDEX i ON a (id);\n\n-- next\nDROP INDEX i;\n",
+			want:   []string{"CREATE INDEX i ON a (id);", "DROP INDEX i;"},
+		},
+		{
+			name:   "semicolon inside a line does not split",
+			script: "INSERT INTO a VALUES ('x;y');\n",
+			want:   []string{"INSERT INTO a VALUES ('x;y');"},
+		},
+		{"trailing statement without semicolon", "DROP TABLE a", []string{"DROP TABLE a"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := splitStatements(tt.script); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("splitStatements() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,11 @@
+CREATE TABLE users (
+  user_id BIGINT UNSIGNED NOT NULL AUTO_INCREMENT,
+  email VARCHAR(191) NOT NULL,
+  name VARCHAR(64) NOT NULL,
+  role VARCHAR(16) NOT NULL DEFAULT 'editor',
+  password_hash VARCHAR(255) NOT NULL DEFAULT '',
+  created_at DATETIME(3) NOT NULL,
+  updated_at DATETIME(3) NOT NULL,
+  PRIMARY KEY (user_id),
+  UNIQUE KEY uk_users_email (email)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci;
@@ -0,0 +1,21 @@
+CREATE TABLE refresh_tokens (
+  token_id BIGINT UNSIGNED NOT NULL AUTO_INCREMENT,
+  user_id BIGINT UNSIGNED NOT NULL,
+  token_hash CHAR(64) NOT NULL,
+  expires_at DATETIME(3) NOT NULL,
+  created_at DATETIME(3) NOT NULL,
+  PRIMARY KEY (token_id),
+  UNIQUE KEY uk_refresh_tokens_hash (token_hash),
+  KEY idx_refresh_tokens_user (user_id),
+  CONSTRAINT fk_refresh_tokens_user FOREIGN KEY (user_id) REFERENCES users (user_id) ON DELETE CASCADE
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci;
+
+CREATE TABLE refresh_token_blacklist (
+  token_hash CHAR(64) NOT NULL,
+  user_id BIGINT UNSIGNED NOT NULL,
+  reason VARCHAR(16) NOT NULL,
+  revoked_at DATETIME(3) NOT NULL,
+  PRIMARY KEY (token_hash),
+  KEY idx_refresh_token_blacklist_user (user_id),
+  CONSTRAINT fk_refresh_token_blacklist_user FOREIGN KEY (user_id) REFERENCES users (user_id) ON DELETE CASCADE
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci;
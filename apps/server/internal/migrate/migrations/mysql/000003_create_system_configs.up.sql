@@ -0,0 +1,11 @@
+-- updated_by_user_id 必须与 users.user_id 定义完全一致（BIGINT UNSIGNED、同字符集的表），
+-- 否则 MySQL 会报 Error 3780 外键列不兼容；系统初始配置没有操作人，因此允许 NULL。
+CREATE TABLE system_configs (
+  config_key VARCHAR(128) NOT NULL,
+  config_value TEXT NOT NULL,
+  updated_by_user_id BIGINT UNSIGNED NULL DEFAULT NULL,
+  updated_at DATETIME(3) NOT NULL,
+  PRIMARY KEY (config_key),
+  KEY idx_system_configs_updated_by (updated_by_user_id),
+  CONSTRAINT fk_system_configs_updated_by FOREIGN KEY (updated_by_user_id) REFERENCES users (user_id) ON DELETE SET NULL
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci;
@@ -0,0 +1,15 @@
+CREATE TABLE docs (
+  doc_id BIGINT UNSIGNED NOT NULL AUTO_INCREMENT,
+  space VARCHAR(64) NOT NULL DEFAULT 'default',
+  title VARCHAR(255) NOT NULL,
+  slug VARCHAR(191) NOT NULL,
+  content MEDIUMTEXT NOT NULL,
+  author_id BIGINT UNSIGNED NOT NULL,
+  created_at DATETIME(3) NOT NULL,
+  updated_at DATETIME(3) NOT NULL,
+  PRIMARY KEY (doc_id),
+  UNIQUE KEY uk_docs_space_slug (space, slug),
+  KEY idx_docs_updated_at (updated_at),
+  KEY idx_docs_author (author_id),
+  CONSTRAINT fk_docs_author FOREIGN KEY (author_id) REFERENCES users (user_id)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci;
@@ -0,0 +1,22 @@
+ALTER TABLE docs ADD COLUMN version INT UNSIGNED NOT NULL DEFAULT 1 AFTER content;
+
+-- 每个版本保存全量快照：读取与回滚都是单行查询，不需要回放差异链；
+-- 代价是存储随版本数线性增长，Markdown 正文通常只有几十 KB，可以接受。
+CREATE TABLE doc_versions (
+  version_id BIGINT UNSIGNED NOT NULL AUTO_INCREMENT,
+  doc_id BIGINT UNSIGNED NOT NULL,
+  version INT UNSIGNED NOT NULL,
+  title VARCHAR(255) NOT NULL,
+  content MEDIUMTEXT NOT NULL,
+  editor_id BIGINT UNSIGNED NOT NULL,
+  summary VARCHAR(255) NOT NULL DEFAULT '',
+  created_at DATETIME(3) NOT NULL,
+  PRIMARY KEY (version_id),
+  UNIQUE KEY uk_doc_versions_doc_version (doc_id, version),
+  KEY idx_doc_versions_editor (editor_id),
+  CONSTRAINT fk_doc_versions_doc FOREIGN KEY (doc_id) REFERENCES docs (doc_id) ON DELETE CASCADE,
+  CONSTRAINT fk_doc_versions_editor FOREIGN KEY (editor_id) REFERENCES users (user_id)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci;
+
+INSERT INTO doc_versions (doc_id, version, title, content, editor_id, summary, created_at)
+SELECT doc_id, 1, title, content, author_id, 'initial version', updated_at FROM docs;
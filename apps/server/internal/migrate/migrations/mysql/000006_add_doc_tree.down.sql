@@ -0,0 +1,5 @@
+ALTER TABLE docs
+  DROP FOREIGN KEY fk_docs_parent,
+  DROP KEY idx_docs_space_parent_sort,
+  DROP COLUMN sort_order,
+  DROP COLUMN parent_id;
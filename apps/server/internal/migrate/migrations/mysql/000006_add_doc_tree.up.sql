@@ -0,0 +1,9 @@
+-- sort_order 采用稀疏整数（默认间隔 1024）：移动节点时取相邻两项的中间值，
+-- 只有间隔耗尽时才重排同级节点，避免每次拖动都改写整组兄弟节点。
+ALTER TABLE docs
+  ADD COLUMN parent_id BIGINT UNSIGNED NULL AFTER space,
+  ADD COLUMN sort_order BIGINT NOT NULL DEFAULT 0 AFTER parent_id,
+  ADD KEY idx_docs_space_parent_sort (space, parent_id, sort_order),
+  ADD CONSTRAINT fk_docs_parent FOREIGN KEY (parent_id) REFERENCES docs (doc_id);
+
+UPDATE docs SET sort_order = doc_id * 1024;
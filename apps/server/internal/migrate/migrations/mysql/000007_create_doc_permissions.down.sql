@@ -0,0 +1,7 @@
+DROP TABLE IF EXISTS doc_permissions;
+
+ALTER TABLE docs
+  DROP KEY idx_docs_acl_doc,
+  DROP COLUMN acl_doc_id,
+  DROP COLUMN inherit_permissions,
+  DROP COLUMN is_private;
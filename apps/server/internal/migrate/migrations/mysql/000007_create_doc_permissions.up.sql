@@ -0,0 +1,28 @@
+-- acl_doc_id 指向实际生效的权限来源：继承父文档权限时为最近一个不继承的祖先（或树根），
+-- 为 NULL 表示使用文档自身的设置；冗余存储是为了列表与检索能在一条 SQL 里完成可见性过滤。
+ALTER TABLE docs
+  ADD COLUMN is_private TINYINT(1) NOT NULL DEFAULT 0 AFTER sort_order,
+  ADD COLUMN inherit_permissions TINYINT(1) NOT NULL DEFAULT 1 AFTER is_private,
+  ADD COLUMN acl_doc_id BIGINT UNSIGNED NULL DEFAULT NULL AFTER inherit_permissions,
+  ADD KEY idx_docs_acl_doc (acl_doc_id);
+
+-- 授权对象是某个用户（user_id）或某个全局角色（role），二者只填其一；
+-- grantee 是 "user:<id>" / "role:<name>" 形式的归一化键，用于保证同一对象在一篇文档上只有一条授权。
+CREATE TABLE doc_permissions (
+  permission_id BIGINT UNSIGNED NOT NULL AUTO_INCREMENT,
+  doc_id BIGINT UNSIGNED NOT NULL,
+  user_id BIGINT UNSIGNED NULL DEFAULT NULL,
+  role VARCHAR(16) NULL DEFAULT NULL,
+  grantee VARCHAR(80) NOT NULL,
+  permission VARCHAR(8) NOT NULL,
+  granted_by_user_id BIGINT UNSIGNED NULL DEFAULT NULL,
+  created_at DATETIME(3) NOT NULL,
+  PRIMARY KEY (permission_id),
+  UNIQUE KEY uk_doc_permissions_grantee (doc_id, grantee),
+  KEY idx_doc_permissions_user (user_id),
+  KEY idx_doc_permissions_role (role),
+  KEY idx_doc_permissions_granted_by (granted_by_user_id),
+  CONSTRAINT fk_doc_permissions_doc FOREIGN KEY (doc_id) REFERENCES docs (doc_id) ON DELETE CASCADE,
+  CONSTRAINT fk_doc_permissions_user FOREIGN KEY (user_id) REFERENCES users (user_id) ON DELETE CASCADE,
+  CONSTRAINT fk_doc_permissions_granted_by FOREIGN KEY (granted_by_user_id) REFERENCES users (user_id) ON DELETE SET NULL
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci;
@@ -0,0 +1,14 @@
+-- 第三方账号与本地用户的绑定关系；以 provider 内不变的 subject 识别账号，
+-- 第三方邮箱可被用户修改，只在首次登录时用于匹配已有的本地用户。
+CREATE TABLE user_identities (
+  identity_id BIGINT UNSIGNED NOT NULL AUTO_INCREMENT,
+  user_id BIGINT UNSIGNED NOT NULL,
+  provider VARCHAR(32) NOT NULL,
+  subject VARCHAR(191) NOT NULL,
+  email VARCHAR(191) NOT NULL DEFAULT '',
+  created_at DATETIME(3) NOT NULL,
+  PRIMARY KEY (identity_id),
+  UNIQUE KEY uk_user_identities_provider_subject (provider, subject),
+  KEY idx_user_identities_user (user_id),
+  CONSTRAINT fk_user_identities_user FOREIGN KEY (user_id) REFERENCES users (user_id) ON DELETE CASCADE
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci;
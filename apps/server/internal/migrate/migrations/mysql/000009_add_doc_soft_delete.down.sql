@@ -0,0 +1,14 @@
+-- 回收站中的文档无法满足原来的 (space, slug) 唯一键，回滚前直接清除；
+-- 回收站文档的子文档也都在回收站中，先断开父子关系以免外键限制删除顺序。
+UPDATE docs SET parent_id = NULL WHERE deleted_at IS NOT NULL;
+DELETE FROM docs WHERE deleted_at IS NOT NULL;
+
+ALTER TABLE docs
+  DROP FOREIGN KEY fk_docs_deleted_by,
+  DROP KEY idx_docs_deleted_by,
+  DROP KEY idx_docs_deleted_at,
+  DROP KEY uk_docs_space_slug,
+  ADD UNIQUE KEY uk_docs_space_slug (space, slug),
+  DROP COLUMN deleted_key,
+  DROP COLUMN deleted_by_user_id,
+  DROP COLUMN deleted_at;
@@ -0,0 +1,11 @@
+-- 软删除：deleted_key 对未删除文档恒为 0，删除时写入 doc_id，
+-- 唯一键 (space, slug, deleted_key) 因此只约束未删除的文档，回收站中的 slug 可以被新文档复用。
+ALTER TABLE docs
+  ADD COLUMN deleted_at DATETIME(3) NULL DEFAULT NULL AFTER updated_at,
+  ADD COLUMN deleted_by_user_id BIGINT UNSIGNED NULL DEFAULT NULL AFTER deleted_at,
+  ADD COLUMN deleted_key BIGINT UNSIGNED NOT NULL DEFAULT 0 AFTER deleted_by_user_id,
+  DROP KEY uk_docs_space_slug,
+  ADD UNIQUE KEY uk_docs_space_slug (space, slug, deleted_key),
+  ADD KEY idx_docs_deleted_at (deleted_at),
+  ADD KEY idx_docs_deleted_by (deleted_by_user_id),
+  ADD CONSTRAINT fk_docs_deleted_by FOREIGN KEY (deleted_by_user_id) REFERENCES users (user_id) ON DELETE SET NULL;
@@ -0,0 +1,14 @@
+-- 系统配置的变更历史；敏感配置的 old_value/new_value 写入前已脱敏，不保存明文。
+-- old_value 为 NULL 表示该配置此前不存在。
+CREATE TABLE config_audit_logs (
+  audit_id BIGINT UNSIGNED NOT NULL AUTO_INCREMENT,
+  config_key VARCHAR(128) NOT NULL,
+  old_value TEXT NULL,
+  new_value TEXT NOT NULL,
+  updated_by_user_id BIGINT UNSIGNED NULL DEFAULT NULL,
+  created_at DATETIME(3) NOT NULL,
+  PRIMARY KEY (audit_id),
+  KEY idx_config_audit_logs_key (config_key, audit_id),
+  KEY idx_config_audit_logs_updated_by (updated_by_user_id),
+  CONSTRAINT fk_config_audit_logs_updated_by FOREIGN KEY (updated_by_user_id) REFERENCES users (user_id) ON DELETE SET NULL
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci;
@@ -0,0 +1,2 @@
+-- 用户的个人偏好主题，空串表示跟随全站默认主题。
+ALTER TABLE users ADD COLUMN theme VARCHAR(32) NOT NULL DEFAULT '' AFTER role;
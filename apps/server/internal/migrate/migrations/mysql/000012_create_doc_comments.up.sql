@@ -0,0 +1,19 @@
+-- 文档评论只支持一层回复：回复的 parent_comment_id 指向顶层评论，删除顶层评论时回复一并删除。
+-- content 保存原始 Markdown，content_html 是写入时渲染并消毒后的结果，读取时不再重复渲染。
+CREATE TABLE doc_comments (
+  comment_id BIGINT UNSIGNED NOT NULL AUTO_INCREMENT,
+  doc_id BIGINT UNSIGNED NOT NULL,
+  parent_comment_id BIGINT UNSIGNED NULL DEFAULT NULL,
+  author_id BIGINT UNSIGNED NOT NULL,
+  anchor VARCHAR(255) NOT NULL DEFAULT '',
+  content TEXT NOT NULL,
+  content_html MEDIUMTEXT NOT NULL,
+  created_at DATETIME(3) NOT NULL,
+  PRIMARY KEY (comment_id),
+  KEY idx_doc_comments_doc (doc_id, parent_comment_id, created_at),
+  KEY idx_doc_comments_parent (parent_comment_id),
+  KEY idx_doc_comments_author (author_id),
+  CONSTRAINT fk_doc_comments_doc FOREIGN KEY (doc_id) REFERENCES docs (doc_id) ON DELETE CASCADE,
+  CONSTRAINT fk_doc_comments_parent FOREIGN KEY (parent_comment_id) REFERENCES doc_comments (comment_id) ON DELETE CASCADE,
+  CONSTRAINT fk_doc_comments_author FOREIGN KEY (author_id) REFERENCES users (user_id)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci;
@@ -0,0 +1,19 @@
+-- 标签名写入前已归一化（合并空白、转小写），唯一索引保证同名标签只有一条记录。
+CREATE TABLE tags (
+  tag_id BIGINT UNSIGNED NOT NULL AUTO_INCREMENT,
+  name VARCHAR(64) NOT NULL,
+  color VARCHAR(7) NOT NULL DEFAULT '',
+  created_at DATETIME(3) NOT NULL,
+  PRIMARY KEY (tag_id),
+  UNIQUE KEY uk_tags_name (name)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci;
+
+-- 文档彻底删除时级联清理关联，标签本身保留；移入回收站的文档保留关联以便恢复。
+CREATE TABLE doc_tags (
+  doc_id BIGINT UNSIGNED NOT NULL,
+  tag_id BIGINT UNSIGNED NOT NULL,
+  PRIMARY KEY (doc_id, tag_id),
+  KEY idx_doc_tags_tag (tag_id),
+  CONSTRAINT fk_doc_tags_doc FOREIGN KEY (doc_id) REFERENCES docs (doc_id) ON DELETE CASCADE,
+  CONSTRAINT fk_doc_tags_tag FOREIGN KEY (tag_id) REFERENCES tags (tag_id) ON DELETE CASCADE
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci;
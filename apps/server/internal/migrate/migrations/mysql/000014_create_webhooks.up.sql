@@ -0,0 +1,33 @@
+-- events 是逗号分隔的订阅事件；secret 用于计算投递请求的 HMAC 签名，需要明文保存。
+CREATE TABLE webhooks (
+  webhook_id BIGINT UNSIGNED NOT NULL AUTO_INCREMENT,
+  url VARCHAR(2048) NOT NULL,
+  secret VARCHAR(255) NOT NULL,
+  events VARCHAR(255) NOT NULL,
+  is_active TINYINT(1) NOT NULL DEFAULT 1,
+  created_by_user_id BIGINT UNSIGNED NULL DEFAULT NULL,
+  created_at DATETIME(3) NOT NULL,
+  updated_at DATETIME(3) NOT NULL,
+  PRIMARY KEY (webhook_id),
+  KEY idx_webhooks_created_by (created_by_user_id),
+  CONSTRAINT fk_webhooks_created_by FOREIGN KEY (created_by_user_id) REFERENCES users (user_id) ON DELETE SET NULL
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci;
+
+-- 每次事件对每个订阅的 webhook 生成一条投递记录；status 为 pending 时由后台任务在 next_attempt_at 到期后投递。
+CREATE TABLE webhook_deliveries (
+  delivery_id BIGINT UNSIGNED NOT NULL AUTO_INCREMENT,
+  webhook_id BIGINT UNSIGNED NOT NULL,
+  event VARCHAR(64) NOT NULL,
+  payload MEDIUMTEXT NOT NULL,
+  status VARCHAR(16) NOT NULL,
+  attempts INT NOT NULL DEFAULT 0,
+  response_status INT NULL DEFAULT NULL,
+  last_error VARCHAR(1024) NOT NULL DEFAULT '',
+  next_attempt_at DATETIME(3) NULL DEFAULT NULL,
+  created_at DATETIME(3) NOT NULL,
+  updated_at DATETIME(3) NOT NULL,
+  PRIMARY KEY (delivery_id),
+  KEY idx_webhook_deliveries_due (status, next_attempt_at),
+  KEY idx_webhook_deliveries_webhook (webhook_id, delivery_id),
+  CONSTRAINT fk_webhook_deliveries_webhook FOREIGN KEY (webhook_id) REFERENCES webhooks (webhook_id) ON DELETE CASCADE
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci;
@@ -0,0 +1,5 @@
+DROP TABLE IF EXISTS doc_drafts;
+ALTER TABLE docs
+  DROP KEY idx_docs_status,
+  DROP COLUMN published_at,
+  DROP COLUMN status;
@@ -0,0 +1,19 @@
+-- status 为 draft（未发布）、published 或 archived；已有文档视为已发布，published_at 取创建时间。
+ALTER TABLE docs
+  ADD COLUMN status VARCHAR(16) NOT NULL DEFAULT 'published' AFTER version,
+  ADD COLUMN published_at DATETIME(3) NULL DEFAULT NULL AFTER status,
+  ADD KEY idx_docs_status (status);
+UPDATE docs SET published_at = created_at;
+
+-- 已发布文档尚未发布的修改，每篇文档最多一份；发布时写回 docs 并删除。
+CREATE TABLE doc_drafts (
+  doc_id BIGINT UNSIGNED NOT NULL,
+  title VARCHAR(255) NOT NULL,
+  content MEDIUMTEXT NOT NULL,
+  editor_id BIGINT UNSIGNED NOT NULL,
+  updated_at DATETIME(3) NOT NULL,
+  PRIMARY KEY (doc_id),
+  KEY idx_doc_drafts_editor (editor_id),
+  CONSTRAINT fk_doc_drafts_doc FOREIGN KEY (doc_id) REFERENCES docs (doc_id) ON DELETE CASCADE,
+  CONSTRAINT fk_doc_drafts_editor FOREIGN KEY (editor_id) REFERENCES users (user_id)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci;
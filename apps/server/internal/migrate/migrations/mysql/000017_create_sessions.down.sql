@@ -0,0 +1,5 @@
+ALTER TABLE refresh_tokens
+  DROP FOREIGN KEY fk_refresh_tokens_session,
+  DROP KEY idx_refresh_tokens_session,
+  DROP COLUMN session_id;
+DROP TABLE IF EXISTS sessions;
@@ -0,0 +1,17 @@
+-- 每次登录创建一个会话，refresh token 轮换时沿用同一个会话；升级前签发的 refresh token 没有会话，下次刷新时补建。
+CREATE TABLE sessions (
+  session_id BIGINT UNSIGNED NOT NULL AUTO_INCREMENT,
+  user_id BIGINT UNSIGNED NOT NULL,
+  user_agent VARCHAR(255) NOT NULL DEFAULT '',
+  ip VARCHAR(45) NOT NULL DEFAULT '',
+  created_at DATETIME(3) NOT NULL,
+  last_active_at DATETIME(3) NOT NULL,
+  PRIMARY KEY (session_id),
+  KEY idx_sessions_user (user_id),
+  CONSTRAINT fk_sessions_user FOREIGN KEY (user_id) REFERENCES users (user_id) ON DELETE CASCADE
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci;
+
+ALTER TABLE refresh_tokens
+  ADD COLUMN session_id BIGINT UNSIGNED NULL DEFAULT NULL AFTER user_id,
+  ADD KEY idx_refresh_tokens_session (session_id),
+  ADD CONSTRAINT fk_refresh_tokens_session FOREIGN KEY (session_id) REFERENCES sessions (session_id) ON DELETE CASCADE;
@@ -0,0 +1,11 @@
+-- 主键保证同一用户对同一文档只收藏一次；文档彻底删除时级联清理收藏，移入回收站的文档保留收藏以便恢复。
+CREATE TABLE user_favorites (
+  user_id BIGINT UNSIGNED NOT NULL,
+  doc_id BIGINT UNSIGNED NOT NULL,
+  created_at DATETIME(3) NOT NULL,
+  PRIMARY KEY (user_id, doc_id),
+  KEY idx_user_favorites_user_created (user_id, created_at),
+  KEY idx_user_favorites_doc (doc_id),
+  CONSTRAINT fk_user_favorites_user FOREIGN KEY (user_id) REFERENCES users (user_id) ON DELETE CASCADE,
+  CONSTRAINT fk_user_favorites_doc FOREIGN KEY (doc_id) REFERENCES docs (doc_id) ON DELETE CASCADE
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci;
@@ -0,0 +1,15 @@
+-- 只保存 key 的 SHA-256 摘要与用于辨认的前缀，明文只在创建时返回一次；吊销即删除记录，立即失效。
+CREATE TABLE api_keys (
+  key_id BIGINT UNSIGNED NOT NULL AUTO_INCREMENT,
+  user_id BIGINT UNSIGNED NOT NULL,
+  name VARCHAR(64) NOT NULL,
+  key_prefix VARCHAR(16) NOT NULL,
+  key_hash CHAR(64) NOT NULL,
+  scope VARCHAR(16) NOT NULL,
+  last_used_at DATETIME(3) NULL,
+  created_at DATETIME(3) NOT NULL,
+  PRIMARY KEY (key_id),
+  UNIQUE KEY uk_api_keys_hash (key_hash),
+  KEY idx_api_keys_user (user_id),
+  CONSTRAINT fk_api_keys_user FOREIGN KEY (user_id) REFERENCES users (user_id) ON DELETE CASCADE
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci;
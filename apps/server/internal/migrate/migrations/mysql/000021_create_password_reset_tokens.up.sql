@@ -0,0 +1,12 @@
+-- 只保存重置 token 的 SHA-256 摘要；token 使用后或用户重置成功后删除，同一用户重新申请时替换之前未使用的 token。
+CREATE TABLE password_reset_tokens (
+  token_id BIGINT UNSIGNED NOT NULL AUTO_INCREMENT,
+  user_id BIGINT UNSIGNED NOT NULL,
+  token_hash CHAR(64) NOT NULL,
+  expires_at DATETIME(3) NOT NULL,
+  created_at DATETIME(3) NOT NULL,
+  PRIMARY KEY (token_id),
+  UNIQUE KEY uk_password_reset_tokens_hash (token_hash),
+  KEY idx_password_reset_tokens_user (user_id),
+  CONSTRAINT fk_password_reset_tokens_user FOREIGN KEY (user_id) REFERENCES users (user_id) ON DELETE CASCADE
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci;
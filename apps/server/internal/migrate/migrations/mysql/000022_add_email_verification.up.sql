@@ -0,0 +1,16 @@
+-- email_verified_at 为空表示邮箱尚未验证；已有用户视为已验证，升级后不会被拦在登录之外。
+ALTER TABLE users ADD COLUMN email_verified_at DATETIME(3) NULL DEFAULT NULL AFTER email;
+UPDATE users SET email_verified_at = created_at;
+
+-- 验证 token 只保存 SHA-256 摘要，验证成功后删除该用户的全部 token，重发时替换之前未使用的 token。
+CREATE TABLE email_verification_tokens (
+  token_id BIGINT UNSIGNED NOT NULL AUTO_INCREMENT,
+  user_id BIGINT UNSIGNED NOT NULL,
+  token_hash CHAR(64) NOT NULL,
+  expires_at DATETIME(3) NOT NULL,
+  created_at DATETIME(3) NOT NULL,
+  PRIMARY KEY (token_id),
+  UNIQUE KEY uk_email_verification_tokens_hash (token_hash),
+  KEY idx_email_verification_tokens_user (user_id),
+  CONSTRAINT fk_email_verification_tokens_user FOREIGN KEY (user_id) REFERENCES users (user_id) ON DELETE CASCADE
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci;
@@ -0,0 +1,28 @@
+-- 文档模板：content 是带 {{date}}、{{author}}、{{title}} 占位符的 Markdown，创建文档时替换。
+-- 私有模板只对创建者、管理员与 doc_template_grants 中的授权对象可见。
+CREATE TABLE doc_templates (
+  template_id BIGINT UNSIGNED NOT NULL AUTO_INCREMENT,
+  name VARCHAR(100) NOT NULL,
+  description VARCHAR(255) NOT NULL DEFAULT '',
+  content MEDIUMTEXT NOT NULL,
+  is_private TINYINT(1) NOT NULL DEFAULT 0,
+  created_by_user_id BIGINT UNSIGNED NULL DEFAULT NULL,
+  created_at DATETIME(3) NOT NULL,
+  updated_at DATETIME(3) NOT NULL,
+  PRIMARY KEY (template_id),
+  UNIQUE KEY uk_doc_templates_name (name),
+  KEY idx_doc_templates_created_by (created_by_user_id),
+  CONSTRAINT fk_doc_templates_created_by FOREIGN KEY (created_by_user_id) REFERENCES users (user_id) ON DELETE SET NULL
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci;
+
+-- 与 doc_permissions 一样，授权对象是某个用户（user_id）或某个全局角色（role），grantee 为归一化键。
+CREATE TABLE doc_template_grants (
+  template_id BIGINT UNSIGNED NOT NULL,
+  user_id BIGINT UNSIGNED NULL DEFAULT NULL,
+  role VARCHAR(16) NULL DEFAULT NULL,
+  grantee VARCHAR(80) NOT NULL,
+  PRIMARY KEY (template_id, grantee),
+  KEY idx_doc_template_grants_user (user_id),
+  CONSTRAINT fk_doc_template_grants_template FOREIGN KEY (template_id) REFERENCES doc_templates (template_id) ON DELETE CASCADE,
+  CONSTRAINT fk_doc_template_grants_user FOREIGN KEY (user_id) REFERENCES users (user_id) ON DELETE CASCADE
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci;
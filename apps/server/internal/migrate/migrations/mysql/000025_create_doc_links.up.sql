@@ -0,0 +1,12 @@
+-- 文档正文中 [[slug]] 内部链接的关系表，保存文档时重建。链接按 slug 指向源文档所在空间的文档，
+-- target_doc_id 是当前解析到的目标，为 NULL 表示断链（目标不存在、已移入回收站或改了 slug），之后出现同 slug 的文档时自动接上。
+CREATE TABLE doc_links (
+  source_doc_id BIGINT UNSIGNED NOT NULL,
+  target_slug VARCHAR(191) NOT NULL,
+  target_doc_id BIGINT UNSIGNED NULL DEFAULT NULL,
+  PRIMARY KEY (source_doc_id, target_slug),
+  KEY idx_doc_links_target (target_doc_id),
+  KEY idx_doc_links_target_slug (target_slug),
+  CONSTRAINT fk_doc_links_source FOREIGN KEY (source_doc_id) REFERENCES docs (doc_id) ON DELETE CASCADE,
+  CONSTRAINT fk_doc_links_target FOREIGN KEY (target_doc_id) REFERENCES docs (doc_id) ON DELETE SET NULL
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci;
@@ -0,0 +1,18 @@
+-- 各空间的同名标签合并回一个全局标签（保留 default 空间中的那个）。
+INSERT INTO tags (space, name, color, created_at)
+SELECT 'default', t.name, MIN(t.color), MIN(t.created_at) FROM tags t
+WHERE t.space <> 'default' AND NOT EXISTS (SELECT 1 FROM (SELECT name FROM tags WHERE space = 'default') d WHERE d.name = t.name)
+GROUP BY t.name;
+UPDATE IGNORE doc_tags dt
+JOIN tags t ON t.tag_id = dt.tag_id
+JOIN tags d ON d.space = 'default' AND d.name = t.name
+SET dt.tag_id = d.tag_id
+WHERE t.space <> 'default';
+DELETE FROM tags WHERE space <> 'default';
+ALTER TABLE tags
+  DROP INDEX uk_tags_space_name,
+  DROP COLUMN space,
+  ADD UNIQUE KEY uk_tags_name (name);
+
+DROP TABLE IF EXISTS space_members;
+DROP TABLE IF EXISTS spaces;
@@ -0,0 +1,20 @@
+-- 异步作业：kind 决定执行逻辑，params 与 result 为 JSON。status 依次为 pending、running，结束后为 succeeded 或 failed；
+-- 运行中的作业定期刷新 updated_at，进程重启后长时间未刷新的作业标记为 failed。
+CREATE TABLE jobs (
+  job_id BIGINT UNSIGNED NOT NULL AUTO_INCREMENT,
+  kind VARCHAR(64) NOT NULL,
+  status VARCHAR(16) NOT NULL,
+  progress INT NOT NULL DEFAULT 0,
+  params TEXT NOT NULL,
+  result TEXT NULL DEFAULT NULL,
+  error VARCHAR(1024) NOT NULL DEFAULT '',
+  created_by_user_id BIGINT UNSIGNED NULL DEFAULT NULL,
+  created_at DATETIME(3) NOT NULL,
+  started_at DATETIME(3) NULL DEFAULT NULL,
+  finished_at DATETIME(3) NULL DEFAULT NULL,
+  updated_at DATETIME(3) NOT NULL,
+  PRIMARY KEY (job_id),
+  KEY idx_jobs_status (status, job_id),
+  KEY idx_jobs_created_by (created_by_user_id, job_id),
+  CONSTRAINT fk_jobs_created_by FOREIGN KEY (created_by_user_id) REFERENCES users (user_id) ON DELETE SET NULL
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci;
@@ -0,0 +1,3 @@
+ALTER TABLE docs
+  DROP KEY idx_docs_publish_at,
+  DROP COLUMN publish_at;
@@ -0,0 +1,4 @@
+-- publish_at 是定时发布时间（UTC），到期后由后台任务发布文档并清空；为 NULL 表示没有定时发布。
+ALTER TABLE docs
+  ADD COLUMN publish_at DATETIME(3) NULL DEFAULT NULL AFTER published_at,
+  ADD KEY idx_docs_publish_at (publish_at);
@@ -0,0 +1,11 @@
+-- 文档编辑锁：每篇文档最多一把，expires_at 之前由 user_id 持有，持有者定期续期；过期的锁视为已释放，下次加锁时覆盖。
+CREATE TABLE doc_locks (
+  doc_id BIGINT UNSIGNED NOT NULL,
+  user_id BIGINT UNSIGNED NOT NULL,
+  acquired_at DATETIME(3) NOT NULL,
+  expires_at DATETIME(3) NOT NULL,
+  PRIMARY KEY (doc_id),
+  KEY idx_doc_locks_user (user_id),
+  CONSTRAINT fk_doc_locks_doc FOREIGN KEY (doc_id) REFERENCES docs (doc_id) ON DELETE CASCADE,
+  CONSTRAINT fk_doc_locks_user FOREIGN KEY (user_id) REFERENCES users (user_id) ON DELETE CASCADE
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci;
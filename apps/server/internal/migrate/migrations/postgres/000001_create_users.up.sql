@@ -0,0 +1,12 @@
+-- PostgreSQL 版本与 migrations/mysql 按版本号一一对应：自增主键用 IDENTITY，时间列用不带时区的 TIMESTAMP(3) 并统一写入 UTC。
+CREATE TABLE users (
+  user_id BIGINT GENERATED BY DEFAULT AS IDENTITY,
+  email VARCHAR(191) NOT NULL,
+  name VARCHAR(64) NOT NULL,
+  role VARCHAR(16) NOT NULL DEFAULT 'editor',
+  password_hash VARCHAR(255) NOT NULL DEFAULT '',
+  created_at TIMESTAMP(3) NOT NULL,
+  updated_at TIMESTAMP(3) NOT NULL,
+  PRIMARY KEY (user_id),
+  CONSTRAINT uk_users_email UNIQUE (email)
+);
@@ -0,0 +1,21 @@
+CREATE TABLE refresh_tokens (
+  token_id BIGINT GENERATED BY DEFAULT AS IDENTITY,
+  user_id BIGINT NOT NULL,
+  token_hash CHAR(64) NOT NULL,
+  expires_at TIMESTAMP(3) NOT NULL,
+  created_at TIMESTAMP(3) NOT NULL,
+  PRIMARY KEY (token_id),
+  CONSTRAINT uk_refresh_tokens_hash UNIQUE (token_hash),
+  CONSTRAINT fk_refresh_tokens_user FOREIGN KEY (user_id) REFERENCES users (user_id) ON DELETE CASCADE
+);
+CREATE INDEX idx_refresh_tokens_user ON refresh_tokens (user_id);
+
+CREATE TABLE refresh_token_blacklist (
+  token_hash CHAR(64) NOT NULL,
+  user_id BIGINT NOT NULL,
+  reason VARCHAR(16) NOT NULL,
+  revoked_at TIMESTAMP(3) NOT NULL,
+  PRIMARY KEY (token_hash),
+  CONSTRAINT fk_refresh_token_blacklist_user FOREIGN KEY (user_id) REFERENCES users (user_id) ON DELETE CASCADE
+);
+CREATE INDEX idx_refresh_token_blacklist_user ON refresh_token_blacklist (user_id);
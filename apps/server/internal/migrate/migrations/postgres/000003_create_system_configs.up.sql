@@ -0,0 +1,10 @@
+-- 系统初始配置没有操作人，因此 updated_by_user_id 允许 NULL。
+CREATE TABLE system_configs (
+  config_key VARCHAR(128) NOT NULL,
+  config_value TEXT NOT NULL,
+  updated_by_user_id BIGINT NULL DEFAULT NULL,
+  updated_at TIMESTAMP(3) NOT NULL,
+  PRIMARY KEY (config_key),
+  CONSTRAINT fk_system_configs_updated_by FOREIGN KEY (updated_by_user_id) REFERENCES users (user_id) ON DELETE SET NULL
+);
+CREATE INDEX idx_system_configs_updated_by ON system_configs (updated_by_user_id);
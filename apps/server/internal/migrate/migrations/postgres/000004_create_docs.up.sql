@@ -0,0 +1,15 @@
+CREATE TABLE docs (
+  doc_id BIGINT GENERATED BY DEFAULT AS IDENTITY,
+  space VARCHAR(64) NOT NULL DEFAULT 'default',
+  title VARCHAR(255) NOT NULL,
+  slug VARCHAR(191) NOT NULL,
+  content TEXT NOT NULL,
+  author_id BIGINT NOT NULL,
+  created_at TIMESTAMP(3) NOT NULL,
+  updated_at TIMESTAMP(3) NOT NULL,
+  PRIMARY KEY (doc_id),
+  CONSTRAINT uk_docs_space_slug UNIQUE (space, slug),
+  CONSTRAINT fk_docs_author FOREIGN KEY (author_id) REFERENCES users (user_id)
+);
+CREATE INDEX idx_docs_updated_at ON docs (updated_at);
+CREATE INDEX idx_docs_author ON docs (author_id);
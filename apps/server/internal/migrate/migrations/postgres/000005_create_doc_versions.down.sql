@@ -0,0 +1,2 @@
+DROP TABLE IF EXISTS doc_versions;
+ALTER TABLE docs DROP COLUMN version;
@@ -0,0 +1,21 @@
+ALTER TABLE docs ADD COLUMN version INT NOT NULL DEFAULT 1;
+
+-- 每个版本保存全量快照，读取与回滚都是单行查询。
+CREATE TABLE doc_versions (
+  version_id BIGINT GENERATED BY DEFAULT AS IDENTITY,
+  doc_id BIGINT NOT NULL,
+  version INT NOT NULL,
+  title VARCHAR(255) NOT NULL,
+  content TEXT NOT NULL,
+  editor_id BIGINT NOT NULL,
+  summary VARCHAR(255) NOT NULL DEFAULT '',
+  created_at TIMESTAMP(3) NOT NULL,
+  PRIMARY KEY (version_id),
+  CONSTRAINT uk_doc_versions_doc_version UNIQUE (doc_id, version),
+  CONSTRAINT fk_doc_versions_doc FOREIGN KEY (doc_id) REFERENCES docs (doc_id) ON DELETE CASCADE,
+  CONSTRAINT fk_doc_versions_editor FOREIGN KEY (editor_id) REFERENCES users (user_id)
+);
+CREATE INDEX idx_doc_versions_editor ON doc_versions (editor_id);
+
+INSERT INTO doc_versions (doc_id, version, title, content, editor_id, summary, created_at)
+SELECT doc_id, 1, title, content, author_id, 'initial version', updated_at FROM docs;
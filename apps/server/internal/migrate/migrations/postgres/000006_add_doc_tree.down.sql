@@ -0,0 +1,5 @@
+DROP INDEX IF EXISTS idx_docs_space_parent_sort;
+ALTER TABLE docs
+  DROP CONSTRAINT fk_docs_parent,
+  DROP COLUMN sort_order,
+  DROP COLUMN parent_id;
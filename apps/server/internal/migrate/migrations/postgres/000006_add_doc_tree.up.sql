@@ -0,0 +1,8 @@
+-- sort_order 采用稀疏整数（默认间隔 1024），移动节点时取相邻两项的中间值。
+ALTER TABLE docs
+  ADD COLUMN parent_id BIGINT NULL,
+  ADD COLUMN sort_order BIGINT NOT NULL DEFAULT 0,
+  ADD CONSTRAINT fk_docs_parent FOREIGN KEY (parent_id) REFERENCES docs (doc_id);
+CREATE INDEX idx_docs_space_parent_sort ON docs (space, parent_id, sort_order);
+
+UPDATE docs SET sort_order = doc_id * 1024;
@@ -0,0 +1,7 @@
+DROP TABLE IF EXISTS doc_permissions;
+
+DROP INDEX IF EXISTS idx_docs_acl_doc;
+ALTER TABLE docs
+  DROP COLUMN acl_doc_id,
+  DROP COLUMN inherit_permissions,
+  DROP COLUMN is_private;
@@ -0,0 +1,13 @@
+-- 以 provider 内不变的 subject 识别第三方账号，邮箱只在首次登录时用于匹配已有的本地用户。
+CREATE TABLE user_identities (
+  identity_id BIGINT GENERATED BY DEFAULT AS IDENTITY,
+  user_id BIGINT NOT NULL,
+  provider VARCHAR(32) NOT NULL,
+  subject VARCHAR(191) NOT NULL,
+  email VARCHAR(191) NOT NULL DEFAULT '',
+  created_at TIMESTAMP(3) NOT NULL,
+  PRIMARY KEY (identity_id),
+  CONSTRAINT uk_user_identities_provider_subject UNIQUE (provider, subject),
+  CONSTRAINT fk_user_identities_user FOREIGN KEY (user_id) REFERENCES users (user_id) ON DELETE CASCADE
+);
+CREATE INDEX idx_user_identities_user ON user_identities (user_id);
@@ -0,0 +1,13 @@
+-- 回收站中的文档无法满足原来的 (space, slug) 唯一约束，回滚前直接清除。
+UPDATE docs SET parent_id = NULL WHERE deleted_at IS NOT NULL;
+DELETE FROM docs WHERE deleted_at IS NOT NULL;
+
+DROP INDEX IF EXISTS idx_docs_deleted_by;
+DROP INDEX IF EXISTS idx_docs_deleted_at;
+ALTER TABLE docs
+  DROP CONSTRAINT fk_docs_deleted_by,
+  DROP CONSTRAINT uk_docs_space_slug,
+  ADD CONSTRAINT uk_docs_space_slug UNIQUE (space, slug),
+  DROP COLUMN deleted_key,
+  DROP COLUMN deleted_by_user_id,
+  DROP COLUMN deleted_at;
@@ -0,0 +1,10 @@
+-- deleted_key 对未删除文档恒为 0，删除时写入 doc_id，唯一约束因此只约束未删除的文档。
+ALTER TABLE docs
+  ADD COLUMN deleted_at TIMESTAMP(3) NULL DEFAULT NULL,
+  ADD COLUMN deleted_by_user_id BIGINT NULL DEFAULT NULL,
+  ADD COLUMN deleted_key BIGINT NOT NULL DEFAULT 0,
+  DROP CONSTRAINT uk_docs_space_slug,
+  ADD CONSTRAINT uk_docs_space_slug UNIQUE (space, slug, deleted_key),
+  ADD CONSTRAINT fk_docs_deleted_by FOREIGN KEY (deleted_by_user_id) REFERENCES users (user_id) ON DELETE SET NULL;
+CREATE INDEX idx_docs_deleted_at ON docs (deleted_at);
+CREATE INDEX idx_docs_deleted_by ON docs (deleted_by_user_id);
@@ -0,0 +1,13 @@
+-- 敏感配置的 old_value/new_value 写入前已脱敏；old_value 为 NULL 表示该配置此前不存在。
+CREATE TABLE config_audit_logs (
+  audit_id BIGINT GENERATED BY DEFAULT AS IDENTITY,
+  config_key VARCHAR(128) NOT NULL,
+  old_value TEXT NULL,
+  new_value TEXT NOT NULL,
+  updated_by_user_id BIGINT NULL DEFAULT NULL,
+  created_at TIMESTAMP(3) NOT NULL,
+  PRIMARY KEY (audit_id),
+  CONSTRAINT fk_config_audit_logs_updated_by FOREIGN KEY (updated_by_user_id) REFERENCES users (user_id) ON DELETE SET NULL
+);
+CREATE INDEX idx_config_audit_logs_key ON config_audit_logs (config_key, audit_id);
+CREATE INDEX idx_config_audit_logs_updated_by ON config_audit_logs (updated_by_user_id);
@@ -0,0 +1,19 @@
+-- 标签名写入前已归一化（合并空白、转小写），唯一约束保证同名标签只有一条记录。
+CREATE TABLE tags (
+  tag_id BIGINT GENERATED BY DEFAULT AS IDENTITY,
+  name VARCHAR(64) NOT NULL,
+  color VARCHAR(7) NOT NULL DEFAULT '',
+  created_at TIMESTAMP(3) NOT NULL,
+  PRIMARY KEY (tag_id),
+  CONSTRAINT uk_tags_name UNIQUE (name)
+);
+
+-- 文档彻底删除时级联清理关联，标签本身保留。
+CREATE TABLE doc_tags (
+  doc_id BIGINT NOT NULL,
+  tag_id BIGINT NOT NULL,
+  PRIMARY KEY (doc_id, tag_id),
+  CONSTRAINT fk_doc_tags_doc FOREIGN KEY (doc_id) REFERENCES docs (doc_id) ON DELETE CASCADE,
+  CONSTRAINT fk_doc_tags_tag FOREIGN KEY (tag_id) REFERENCES tags (tag_id) ON DELETE CASCADE
+);
+CREATE INDEX idx_doc_tags_tag ON doc_tags (tag_id);
@@ -0,0 +1,5 @@
+DROP TABLE IF EXISTS doc_drafts;
+DROP INDEX IF EXISTS idx_docs_status;
+ALTER TABLE docs
+  DROP COLUMN published_at,
+  DROP COLUMN status;
@@ -0,0 +1,16 @@
+-- totp_secret 在绑定完成前也会写入，totp_enabled_at 非空才表示已开启两步验证；
+-- totp_last_step 记录最近一次通过校验的时间步，同一个验证码不能重复使用。
+ALTER TABLE users
+  ADD COLUMN totp_secret VARCHAR(64) NOT NULL DEFAULT '',
+  ADD COLUMN totp_enabled_at TIMESTAMP(3) NULL DEFAULT NULL,
+  ADD COLUMN totp_last_step BIGINT NOT NULL DEFAULT 0;
+
+-- 一次性恢复码只保存 SHA-256 摘要，used_at 非空表示已使用。
+CREATE TABLE user_recovery_codes (
+  user_id BIGINT NOT NULL,
+  code_hash CHAR(64) NOT NULL,
+  used_at TIMESTAMP(3) NULL DEFAULT NULL,
+  created_at TIMESTAMP(3) NOT NULL,
+  PRIMARY KEY (user_id, code_hash),
+  CONSTRAINT fk_user_recovery_codes_user FOREIGN KEY (user_id) REFERENCES users (user_id) ON DELETE CASCADE
+);
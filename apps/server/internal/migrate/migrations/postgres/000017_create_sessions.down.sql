@@ -0,0 +1,5 @@
+DROP INDEX IF EXISTS idx_refresh_tokens_session;
+ALTER TABLE refresh_tokens
+  DROP CONSTRAINT fk_refresh_tokens_session,
+  DROP COLUMN session_id;
+DROP TABLE IF EXISTS sessions;
@@ -0,0 +1,17 @@
+-- 每次登录创建一个会话，refresh token 轮换时沿用同一个会话；升级前签发的 refresh token 没有会话，下次刷新时补建。
+CREATE TABLE sessions (
+  session_id BIGINT GENERATED BY DEFAULT AS IDENTITY,
+  user_id BIGINT NOT NULL,
+  user_agent VARCHAR(255) NOT NULL DEFAULT '',
+  ip VARCHAR(45) NOT NULL DEFAULT '',
+  created_at TIMESTAMP(3) NOT NULL,
+  last_active_at TIMESTAMP(3) NOT NULL,
+  PRIMARY KEY (session_id),
+  CONSTRAINT fk_sessions_user FOREIGN KEY (user_id) REFERENCES users (user_id) ON DELETE CASCADE
+);
+CREATE INDEX idx_sessions_user ON sessions (user_id);
+
+ALTER TABLE refresh_tokens
+  ADD COLUMN session_id BIGINT NULL DEFAULT NULL,
+  ADD CONSTRAINT fk_refresh_tokens_session FOREIGN KEY (session_id) REFERENCES sessions (session_id) ON DELETE CASCADE;
+CREATE INDEX idx_refresh_tokens_session ON refresh_tokens (session_id);
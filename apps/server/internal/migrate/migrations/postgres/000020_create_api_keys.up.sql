@@ -0,0 +1,15 @@
+-- 只保存 key 的 SHA-256 摘要与用于辨认的前缀，明文只在创建时返回一次；吊销即删除记录，立即失效。
+CREATE TABLE api_keys (
+  key_id BIGINT GENERATED BY DEFAULT AS IDENTITY,
+  user_id BIGINT NOT NULL,
+  name VARCHAR(64) NOT NULL,
+  key_prefix VARCHAR(16) NOT NULL,
+  key_hash CHAR(64) NOT NULL,
+  scope VARCHAR(16) NOT NULL,
+  last_used_at TIMESTAMP(3) NULL,
+  created_at TIMESTAMP(3) NOT NULL,
+  PRIMARY KEY (key_id),
+  CONSTRAINT uk_api_keys_hash UNIQUE (key_hash),
+  CONSTRAINT fk_api_keys_user FOREIGN KEY (user_id) REFERENCES users (user_id) ON DELETE CASCADE
+);
+CREATE INDEX idx_api_keys_user ON api_keys (user_id);
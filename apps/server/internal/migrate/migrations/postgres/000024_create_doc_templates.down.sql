@@ -0,0 +1,2 @@
+DROP TABLE IF EXISTS doc_template_grants;
+DROP TABLE IF EXISTS doc_templates;
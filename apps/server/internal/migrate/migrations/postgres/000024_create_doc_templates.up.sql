@@ -0,0 +1,27 @@
+-- 文档模板：content 是带 {{date}}、{{author}}、{{title}} 占位符的 Markdown，私有模板只对授权对象可见。
+CREATE TABLE doc_templates (
+  template_id BIGINT GENERATED BY DEFAULT AS IDENTITY,
+  name VARCHAR(100) NOT NULL,
+  description VARCHAR(255) NOT NULL DEFAULT '',
+  content TEXT NOT NULL,
+  is_private BOOLEAN NOT NULL DEFAULT FALSE,
+  created_by_user_id BIGINT NULL DEFAULT NULL,
+  created_at TIMESTAMP(3) NOT NULL,
+  updated_at TIMESTAMP(3) NOT NULL,
+  PRIMARY KEY (template_id),
+  CONSTRAINT uk_doc_templates_name UNIQUE (name),
+  CONSTRAINT fk_doc_templates_created_by FOREIGN KEY (created_by_user_id) REFERENCES users (user_id) ON DELETE SET NULL
+);
+CREATE INDEX idx_doc_templates_created_by ON doc_templates (created_by_user_id);
+
+-- grantee 是 "user:<id>" / "role:<name>" 形式的归一化键。
+CREATE TABLE doc_template_grants (
+  template_id BIGINT NOT NULL,
+  user_id BIGINT NULL DEFAULT NULL,
+  role VARCHAR(16) NULL DEFAULT NULL,
+  grantee VARCHAR(80) NOT NULL,
+  PRIMARY KEY (template_id, grantee),
+  CONSTRAINT fk_doc_template_grants_template FOREIGN KEY (template_id) REFERENCES doc_templates (template_id) ON DELETE CASCADE,
+  CONSTRAINT fk_doc_template_grants_user FOREIGN KEY (user_id) REFERENCES users (user_id) ON DELETE CASCADE
+);
+CREATE INDEX idx_doc_template_grants_user ON doc_template_grants (user_id);
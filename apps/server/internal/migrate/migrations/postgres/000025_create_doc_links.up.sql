@@ -0,0 +1,11 @@
+-- 文档正文中 [[slug]] 内部链接的关系表，target_doc_id 为 NULL 表示断链，之后出现同 slug 的文档时自动接上。
+CREATE TABLE doc_links (
+  source_doc_id BIGINT NOT NULL,
+  target_slug VARCHAR(191) NOT NULL,
+  target_doc_id BIGINT NULL DEFAULT NULL,
+  PRIMARY KEY (source_doc_id, target_slug),
+  CONSTRAINT fk_doc_links_source FOREIGN KEY (source_doc_id) REFERENCES docs (doc_id) ON DELETE CASCADE,
+  CONSTRAINT fk_doc_links_target FOREIGN KEY (target_doc_id) REFERENCES docs (doc_id) ON DELETE SET NULL
+);
+CREATE INDEX idx_doc_links_target ON doc_links (target_doc_id);
+CREATE INDEX idx_doc_links_target_slug ON doc_links (target_slug);
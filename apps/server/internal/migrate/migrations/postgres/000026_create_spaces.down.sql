@@ -0,0 +1,16 @@
+-- 各空间的同名标签合并回一个全局标签（保留 default 空间中的那个）。
+INSERT INTO tags (space, name, color, created_at)
+SELECT 'default', name, MIN(color), MIN(created_at) FROM tags t
+WHERE space <> 'default' AND NOT EXISTS (SELECT 1 FROM tags d WHERE d.space = 'default' AND d.name = t.name)
+GROUP BY name;
+UPDATE doc_tags SET tag_id = d.tag_id
+FROM tags t, tags d
+WHERE t.tag_id = doc_tags.tag_id AND t.space <> 'default' AND d.space = 'default' AND d.name = t.name
+  AND NOT EXISTS (SELECT 1 FROM doc_tags o WHERE o.doc_id = doc_tags.doc_id AND o.tag_id = d.tag_id);
+DELETE FROM tags WHERE space <> 'default';
+ALTER TABLE tags DROP CONSTRAINT uk_tags_space_name;
+ALTER TABLE tags DROP COLUMN space;
+ALTER TABLE tags ADD CONSTRAINT uk_tags_name UNIQUE (name);
+
+DROP TABLE IF EXISTS space_members;
+DROP TABLE IF EXISTS spaces;
@@ -0,0 +1,44 @@
+-- 空间：space_key 即 docs.space 与 tags.space 中的取值。公开空间人人可见，私有空间只对成员与管理员可见。
+CREATE TABLE spaces (
+  space_id BIGINT GENERATED BY DEFAULT AS IDENTITY,
+  space_key VARCHAR(64) NOT NULL,
+  name VARCHAR(100) NOT NULL,
+  description VARCHAR(255) NOT NULL DEFAULT '',
+  is_private BOOLEAN NOT NULL DEFAULT FALSE,
+  created_by_user_id BIGINT NULL DEFAULT NULL,
+  created_at TIMESTAMP(3) NOT NULL,
+  updated_at TIMESTAMP(3) NOT NULL,
+  PRIMARY KEY (space_id),
+  CONSTRAINT uk_spaces_key UNIQUE (space_key),
+  CONSTRAINT fk_spaces_created_by FOREIGN KEY (created_by_user_id) REFERENCES users (user_id) ON DELETE SET NULL
+);
+CREATE INDEX idx_spaces_created_by ON spaces (created_by_user_id);
+
+-- role 为 owner（可管理空间与成员）或 member。
+CREATE TABLE space_members (
+  space_id BIGINT NOT NULL,
+  user_id BIGINT NOT NULL,
+  role VARCHAR(16) NOT NULL,
+  created_at TIMESTAMP(3) NOT NULL,
+  PRIMARY KEY (space_id, user_id),
+  CONSTRAINT fk_space_members_space FOREIGN KEY (space_id) REFERENCES spaces (space_id) ON DELETE CASCADE,
+  CONSTRAINT fk_space_members_user FOREIGN KEY (user_id) REFERENCES users (user_id) ON DELETE CASCADE
+);
+CREATE INDEX idx_space_members_user ON space_members (user_id);
+
+-- 已有文档所在的空间登记为公开空间，保持升级前的可见范围。
+INSERT INTO spaces (space_key, name, created_at, updated_at) VALUES ('default', 'default', (NOW() AT TIME ZONE 'UTC'), (NOW() AT TIME ZONE 'UTC'));
+INSERT INTO spaces (space_key, name, created_at, updated_at)
+SELECT space, space, MIN(created_at), MIN(created_at) FROM docs WHERE space <> 'default' GROUP BY space;
+
+-- 标签改为按空间区分：被其他空间的文档使用的标签复制到对应空间，再把这些文档的关联指向副本。
+ALTER TABLE tags ADD COLUMN space VARCHAR(64) NOT NULL DEFAULT 'default';
+ALTER TABLE tags DROP CONSTRAINT uk_tags_name;
+ALTER TABLE tags ADD CONSTRAINT uk_tags_space_name UNIQUE (space, name);
+INSERT INTO tags (space, name, color, created_at)
+SELECT DISTINCT d.space, t.name, t.color, t.created_at FROM doc_tags dt
+JOIN docs d ON d.doc_id = dt.doc_id JOIN tags t ON t.tag_id = dt.tag_id WHERE d.space <> t.space;
+UPDATE doc_tags SET tag_id = n.tag_id
+FROM docs d, tags o, tags n
+WHERE d.doc_id = doc_tags.doc_id AND o.tag_id = doc_tags.tag_id AND o.space <> d.space
+  AND n.space = d.space AND n.name = o.name;
@@ -0,0 +1,20 @@
+-- 异步作业：kind 决定执行逻辑，params 与 result 为 JSON。status 依次为 pending、running，结束后为 succeeded 或 failed；
+-- 运行中的作业定期刷新 updated_at，进程重启后长时间未刷新的作业标记为 failed。
+CREATE TABLE jobs (
+  job_id BIGINT GENERATED BY DEFAULT AS IDENTITY,
+  kind VARCHAR(64) NOT NULL,
+  status VARCHAR(16) NOT NULL,
+  progress INT NOT NULL DEFAULT 0,
+  params TEXT NOT NULL,
+  result TEXT NULL DEFAULT NULL,
+  error VARCHAR(1024) NOT NULL DEFAULT '',
+  created_by_user_id BIGINT NULL DEFAULT NULL,
+  created_at TIMESTAMP(3) NOT NULL,
+  started_at TIMESTAMP(3) NULL DEFAULT NULL,
+  finished_at TIMESTAMP(3) NULL DEFAULT NULL,
+  updated_at TIMESTAMP(3) NOT NULL,
+  PRIMARY KEY (job_id),
+  CONSTRAINT fk_jobs_created_by FOREIGN KEY (created_by_user_id) REFERENCES users (user_id) ON DELETE SET NULL
+);
+CREATE INDEX idx_jobs_status ON jobs (status, job_id);
+CREATE INDEX idx_jobs_created_by ON jobs (created_by_user_id, job_id);
@@ -0,0 +1,2 @@
+DROP INDEX IF EXISTS idx_docs_publish_at;
+ALTER TABLE docs DROP COLUMN publish_at;
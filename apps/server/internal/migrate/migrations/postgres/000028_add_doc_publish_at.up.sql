@@ -0,0 +1,3 @@
+-- publish_at 是定时发布时间（UTC），到期后由后台任务发布文档并清空；为 NULL 表示没有定时发布。
+ALTER TABLE docs ADD COLUMN publish_at TIMESTAMP(3) NULL DEFAULT NULL;
+CREATE INDEX idx_docs_publish_at ON docs (publish_at);
@@ -0,0 +1,11 @@
+-- 文档编辑锁：每篇文档最多一把，expires_at 之前由 user_id 持有，持有者定期续期；过期的锁视为已释放，下次加锁时覆盖。
+CREATE TABLE doc_locks (
+  doc_id BIGINT NOT NULL,
+  user_id BIGINT NOT NULL,
+  acquired_at TIMESTAMP(3) NOT NULL,
+  expires_at TIMESTAMP(3) NOT NULL,
+  PRIMARY KEY (doc_id),
+  CONSTRAINT fk_doc_locks_doc FOREIGN KEY (doc_id) REFERENCES docs (doc_id) ON DELETE CASCADE,
+  CONSTRAINT fk_doc_locks_user FOREIGN KEY (user_id) REFERENCES users (user_id) ON DELETE CASCADE
+);
+CREATE INDEX idx_doc_locks_user ON doc_locks (user_id);
@@ -0,0 +1,13 @@
+-- SQLite 版本与 migrations/mysql 按版本号一一对应：主键用 INTEGER PRIMARY KEY AUTOINCREMENT（不复用已删除的 id），
+-- 时间列声明为 DATETIME 以便驱动按时间类型读出；SQLite 的 ALTER TABLE 每条语句只能添加一列，
+-- 回滚需要删除带外键的列时按官方推荐的方式重建表。
+CREATE TABLE users (
+  user_id INTEGER PRIMARY KEY AUTOINCREMENT,
+  email VARCHAR(191) NOT NULL,
+  name VARCHAR(64) NOT NULL,
+  role VARCHAR(16) NOT NULL DEFAULT 'editor',
+  password_hash VARCHAR(255) NOT NULL DEFAULT '',
+  created_at DATETIME NOT NULL,
+  updated_at DATETIME NOT NULL
+);
+CREATE UNIQUE INDEX uk_users_email ON users (email);
@@ -0,0 +1,19 @@
+CREATE TABLE refresh_tokens (
+  token_id INTEGER PRIMARY KEY AUTOINCREMENT,
+  user_id INTEGER NOT NULL,
+  token_hash CHAR(64) NOT NULL,
+  expires_at DATETIME NOT NULL,
+  created_at DATETIME NOT NULL,
+  CONSTRAINT fk_refresh_tokens_user FOREIGN KEY (user_id) REFERENCES users (user_id) ON DELETE CASCADE
+);
+CREATE UNIQUE INDEX uk_refresh_tokens_hash ON refresh_tokens (token_hash);
+CREATE INDEX idx_refresh_tokens_user ON refresh_tokens (user_id);
+
+CREATE TABLE refresh_token_blacklist (
+  token_hash CHAR(64) NOT NULL PRIMARY KEY,
+  user_id INTEGER NOT NULL,
+  reason VARCHAR(16) NOT NULL,
+  revoked_at DATETIME NOT NULL,
+  CONSTRAINT fk_refresh_token_blacklist_user FOREIGN KEY (user_id) REFERENCES users (user_id) ON DELETE CASCADE
+);
+CREATE INDEX idx_refresh_token_blacklist_user ON refresh_token_blacklist (user_id);
@@ -0,0 +1,14 @@
+CREATE TABLE docs (
+  doc_id INTEGER PRIMARY KEY AUTOINCREMENT,
+  space VARCHAR(64) NOT NULL DEFAULT 'default',
+  title VARCHAR(255) NOT NULL,
+  slug VARCHAR(191) NOT NULL,
+  content TEXT NOT NULL,
+  author_id INTEGER NOT NULL,
+  created_at DATETIME NOT NULL,
+  updated_at DATETIME NOT NULL,
+  CONSTRAINT fk_docs_author FOREIGN KEY (author_id) REFERENCES users (user_id)
+);
+CREATE UNIQUE INDEX uk_docs_space_slug ON docs (space, slug);
+CREATE INDEX idx_docs_updated_at ON docs (updated_at);
+CREATE INDEX idx_docs_author ON docs (author_id);
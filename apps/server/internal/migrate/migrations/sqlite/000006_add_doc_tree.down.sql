@@ -0,0 +1,25 @@
+-- parent_id 带外键，SQLite 无法直接删除，只能重建 docs 表；重建期间关闭外键，
+-- 否则 DROP TABLE 会级联删除 doc_versions 中的快照。
+PRAGMA foreign_keys = OFF;
+
+CREATE TABLE docs_rebuild (
+  doc_id INTEGER PRIMARY KEY AUTOINCREMENT,
+  space VARCHAR(64) NOT NULL DEFAULT 'default',
+  title VARCHAR(255) NOT NULL,
+  slug VARCHAR(191) NOT NULL,
+  content TEXT NOT NULL,
+  version INTEGER NOT NULL DEFAULT 1,
+  author_id INTEGER NOT NULL,
+  created_at DATETIME NOT NULL,
+  updated_at DATETIME NOT NULL,
+  CONSTRAINT fk_docs_author FOREIGN KEY (author_id) REFERENCES users (user_id)
+);
+INSERT INTO docs_rebuild (doc_id, space, title, slug, content, version, author_id, created_at, updated_at)
+SELECT doc_id, space, title, slug, content, version, author_id, created_at, updated_at FROM docs;
+DROP TABLE docs;
+ALTER TABLE docs_rebuild RENAME TO docs;
+CREATE UNIQUE INDEX uk_docs_space_slug ON docs (space, slug);
+CREATE INDEX idx_docs_updated_at ON docs (updated_at);
+CREATE INDEX idx_docs_author ON docs (author_id);
+
+PRAGMA foreign_keys = ON;
@@ -0,0 +1,6 @@
+DROP TABLE IF EXISTS doc_permissions;
+
+DROP INDEX IF EXISTS idx_docs_acl_doc;
+ALTER TABLE docs DROP COLUMN acl_doc_id;
+ALTER TABLE docs DROP COLUMN inherit_permissions;
+ALTER TABLE docs DROP COLUMN is_private;
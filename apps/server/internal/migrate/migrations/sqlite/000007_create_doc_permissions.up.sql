@@ -0,0 +1,24 @@
+-- acl_doc_id 指向实际生效的权限来源，为 NULL 表示使用文档自身的设置。
+ALTER TABLE docs ADD COLUMN is_private BOOLEAN NOT NULL DEFAULT 0;
+ALTER TABLE docs ADD COLUMN inherit_permissions BOOLEAN NOT NULL DEFAULT 1;
+ALTER TABLE docs ADD COLUMN acl_doc_id INTEGER NULL DEFAULT NULL;
+CREATE INDEX idx_docs_acl_doc ON docs (acl_doc_id);
+
+-- grantee 是 "user:<id>" / "role:<name>" 形式的归一化键，保证同一对象在一篇文档上只有一条授权。
+CREATE TABLE doc_permissions (
+  permission_id INTEGER PRIMARY KEY AUTOINCREMENT,
+  doc_id INTEGER NOT NULL,
+  user_id INTEGER NULL DEFAULT NULL,
+  role VARCHAR(16) NULL DEFAULT NULL,
+  grantee VARCHAR(80) NOT NULL,
+  permission VARCHAR(8) NOT NULL,
+  granted_by_user_id INTEGER NULL DEFAULT NULL,
+  created_at DATETIME NOT NULL,
+  CONSTRAINT fk_doc_permissions_doc FOREIGN KEY (doc_id) REFERENCES docs (doc_id) ON DELETE CASCADE,
+  CONSTRAINT fk_doc_permissions_user FOREIGN KEY (user_id) REFERENCES users (user_id) ON DELETE CASCADE,
+  CONSTRAINT fk_doc_permissions_granted_by FOREIGN KEY (granted_by_user_id) REFERENCES users (user_id) ON DELETE SET NULL
+);
+CREATE UNIQUE INDEX uk_doc_permissions_grantee ON doc_permissions (doc_id, grantee);
+CREATE INDEX idx_doc_permissions_user ON doc_permissions (user_id);
+CREATE INDEX idx_doc_permissions_role ON doc_permissions (role);
+CREATE INDEX idx_doc_permissions_granted_by ON doc_permissions (granted_by_user_id);
@@ -0,0 +1,37 @@
+-- 回收站中的文档无法满足原来的 (space, slug) 唯一索引，回滚前直接清除。
+UPDATE docs SET parent_id = NULL WHERE deleted_at IS NOT NULL;
+DELETE FROM docs WHERE deleted_at IS NOT NULL;
+
+-- deleted_by_user_id 带外键，只能重建 docs 表；重建期间关闭外键，避免 DROP TABLE 级联删除关联数据。
+PRAGMA foreign_keys = OFF;
+
+CREATE TABLE docs_rebuild (
+  doc_id INTEGER PRIMARY KEY AUTOINCREMENT,
+  space VARCHAR(64) NOT NULL DEFAULT 'default',
+  parent_id INTEGER NULL DEFAULT NULL CONSTRAINT fk_docs_parent REFERENCES docs (doc_id),
+  sort_order INTEGER NOT NULL DEFAULT 0,
+  is_private BOOLEAN NOT NULL DEFAULT 0,
+  inherit_permissions BOOLEAN NOT NULL DEFAULT 1,
+  acl_doc_id INTEGER NULL DEFAULT NULL,
+  title VARCHAR(255) NOT NULL,
+  slug VARCHAR(191) NOT NULL,
+  content TEXT NOT NULL,
+  version INTEGER NOT NULL DEFAULT 1,
+  author_id INTEGER NOT NULL,
+  created_at DATETIME NOT NULL,
+  updated_at DATETIME NOT NULL,
+  CONSTRAINT fk_docs_author FOREIGN KEY (author_id) REFERENCES users (user_id)
+);
+INSERT INTO docs_rebuild (doc_id, space, parent_id, sort_order, is_private, inherit_permissions, acl_doc_id,
+  title, slug, content, version, author_id, created_at, updated_at)
+SELECT doc_id, space, parent_id, sort_order, is_private, inherit_permissions, acl_doc_id,
+  title, slug, content, version, author_id, created_at, updated_at FROM docs;
+DROP TABLE docs;
+ALTER TABLE docs_rebuild RENAME TO docs;
+CREATE UNIQUE INDEX uk_docs_space_slug ON docs (space, slug);
+CREATE INDEX idx_docs_updated_at ON docs (updated_at);
+CREATE INDEX idx_docs_author ON docs (author_id);
+CREATE INDEX idx_docs_space_parent_sort ON docs (space, parent_id, sort_order);
+CREATE INDEX idx_docs_acl_doc ON docs (acl_doc_id);
+
+PRAGMA foreign_keys = ON;
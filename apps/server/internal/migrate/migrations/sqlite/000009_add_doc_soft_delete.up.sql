@@ -0,0 +1,9 @@
+-- deleted_key 对未删除文档恒为 0，删除时写入 doc_id，唯一索引因此只约束未删除的文档。
+ALTER TABLE docs ADD COLUMN deleted_at DATETIME NULL DEFAULT NULL;
+ALTER TABLE docs ADD COLUMN deleted_by_user_id INTEGER NULL DEFAULT NULL
+  CONSTRAINT fk_docs_deleted_by REFERENCES users (user_id) ON DELETE SET NULL;
+ALTER TABLE docs ADD COLUMN deleted_key INTEGER NOT NULL DEFAULT 0;
+DROP INDEX uk_docs_space_slug;
+CREATE UNIQUE INDEX uk_docs_space_slug ON docs (space, slug, deleted_key);
+CREATE INDEX idx_docs_deleted_at ON docs (deleted_at);
+CREATE INDEX idx_docs_deleted_by ON docs (deleted_by_user_id);
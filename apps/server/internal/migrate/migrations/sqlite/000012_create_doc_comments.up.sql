@@ -0,0 +1,17 @@
+-- 文档评论只支持一层回复，删除顶层评论时回复一并删除；content_html 是写入时渲染并消毒后的结果。
+CREATE TABLE doc_comments (
+  comment_id INTEGER PRIMARY KEY AUTOINCREMENT,
+  doc_id INTEGER NOT NULL,
+  parent_comment_id INTEGER NULL DEFAULT NULL,
+  author_id INTEGER NOT NULL,
+  anchor VARCHAR(255) NOT NULL DEFAULT '',
+  content TEXT NOT NULL,
+  content_html TEXT NOT NULL,
+  created_at DATETIME NOT NULL,
+  CONSTRAINT fk_doc_comments_doc FOREIGN KEY (doc_id) REFERENCES docs (doc_id) ON DELETE CASCADE,
+  CONSTRAINT fk_doc_comments_parent FOREIGN KEY (parent_comment_id) REFERENCES doc_comments (comment_id) ON DELETE CASCADE,
+  CONSTRAINT fk_doc_comments_author FOREIGN KEY (author_id) REFERENCES users (user_id)
+);
+CREATE INDEX idx_doc_comments_doc ON doc_comments (doc_id, parent_comment_id, created_at);
+CREATE INDEX idx_doc_comments_parent ON doc_comments (parent_comment_id);
+CREATE INDEX idx_doc_comments_author ON doc_comments (author_id);
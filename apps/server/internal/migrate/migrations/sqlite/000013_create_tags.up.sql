@@ -0,0 +1,18 @@
+-- 标签名写入前已归一化（合并空白、转小写），唯一索引保证同名标签只有一条记录。
+CREATE TABLE tags (
+  tag_id INTEGER PRIMARY KEY AUTOINCREMENT,
+  name VARCHAR(64) NOT NULL,
+  color VARCHAR(7) NOT NULL DEFAULT '',
+  created_at DATETIME NOT NULL
+);
+CREATE UNIQUE INDEX uk_tags_name ON tags (name);
+
+-- 文档彻底删除时级联清理关联，标签本身保留。
+CREATE TABLE doc_tags (
+  doc_id INTEGER NOT NULL,
+  tag_id INTEGER NOT NULL,
+  PRIMARY KEY (doc_id, tag_id),
+  CONSTRAINT fk_doc_tags_doc FOREIGN KEY (doc_id) REFERENCES docs (doc_id) ON DELETE CASCADE,
+  CONSTRAINT fk_doc_tags_tag FOREIGN KEY (tag_id) REFERENCES tags (tag_id) ON DELETE CASCADE
+);
+CREATE INDEX idx_doc_tags_tag ON doc_tags (tag_id);
@@ -0,0 +1,31 @@
+-- events 是逗号分隔的订阅事件；secret 用于计算投递请求的 HMAC 签名，需要明文保存。
+CREATE TABLE webhooks (
+  webhook_id INTEGER PRIMARY KEY AUTOINCREMENT,
+  url VARCHAR(2048) NOT NULL,
+  secret VARCHAR(255) NOT NULL,
+  events VARCHAR(255) NOT NULL,
+  is_active BOOLEAN NOT NULL DEFAULT 1,
+  created_by_user_id INTEGER NULL DEFAULT NULL,
+  created_at DATETIME NOT NULL,
+  updated_at DATETIME NOT NULL,
+  CONSTRAINT fk_webhooks_created_by FOREIGN KEY (created_by_user_id) REFERENCES users (user_id) ON DELETE SET NULL
+);
+CREATE INDEX idx_webhooks_created_by ON webhooks (created_by_user_id);
+
+-- 每次事件对每个订阅的 webhook 生成一条投递记录；status 为 pending 时由后台任务在 next_attempt_at 到期后投递。
+CREATE TABLE webhook_deliveries (
+  delivery_id INTEGER PRIMARY KEY AUTOINCREMENT,
+  webhook_id INTEGER NOT NULL,
+  event VARCHAR(64) NOT NULL,
+  payload TEXT NOT NULL,
+  status VARCHAR(16) NOT NULL,
+  attempts INTEGER NOT NULL DEFAULT 0,
+  response_status INTEGER NULL DEFAULT NULL,
+  last_error VARCHAR(1024) NOT NULL DEFAULT '',
+  next_attempt_at DATETIME NULL DEFAULT NULL,
+  created_at DATETIME NOT NULL,
+  updated_at DATETIME NOT NULL,
+  CONSTRAINT fk_webhook_deliveries_webhook FOREIGN KEY (webhook_id) REFERENCES webhooks (webhook_id) ON DELETE CASCADE
+);
+CREATE INDEX idx_webhook_deliveries_due ON webhook_deliveries (status, next_attempt_at);
+CREATE INDEX idx_webhook_deliveries_webhook ON webhook_deliveries (webhook_id, delivery_id);
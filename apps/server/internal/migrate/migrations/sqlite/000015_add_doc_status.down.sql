@@ -0,0 +1,4 @@
+DROP TABLE IF EXISTS doc_drafts;
+DROP INDEX IF EXISTS idx_docs_status;
+ALTER TABLE docs DROP COLUMN published_at;
+ALTER TABLE docs DROP COLUMN status;
@@ -0,0 +1,17 @@
+-- status 为 draft（未发布）、published 或 archived；已有文档视为已发布，published_at 取创建时间。
+ALTER TABLE docs ADD COLUMN status VARCHAR(16) NOT NULL DEFAULT 'published';
+ALTER TABLE docs ADD COLUMN published_at DATETIME NULL DEFAULT NULL;
+CREATE INDEX idx_docs_status ON docs (status);
+UPDATE docs SET published_at = created_at;
+
+-- 已发布文档尚未发布的修改，每篇文档最多一份；发布时写回 docs 并删除。
+CREATE TABLE doc_drafts (
+  doc_id INTEGER NOT NULL PRIMARY KEY,
+  title VARCHAR(255) NOT NULL,
+  content TEXT NOT NULL,
+  editor_id INTEGER NOT NULL,
+  updated_at DATETIME NOT NULL,
+  CONSTRAINT fk_doc_drafts_doc FOREIGN KEY (doc_id) REFERENCES docs (doc_id) ON DELETE CASCADE,
+  CONSTRAINT fk_doc_drafts_editor FOREIGN KEY (editor_id) REFERENCES users (user_id)
+);
+CREATE INDEX idx_doc_drafts_editor ON doc_drafts (editor_id);
@@ -0,0 +1,15 @@
+-- 每次登录创建一个会话，refresh token 轮换时沿用同一个会话；升级前签发的 refresh token 没有会话，下次刷新时补建。
+CREATE TABLE sessions (
+  session_id INTEGER PRIMARY KEY AUTOINCREMENT,
+  user_id INTEGER NOT NULL,
+  user_agent VARCHAR(255) NOT NULL DEFAULT '',
+  ip VARCHAR(45) NOT NULL DEFAULT '',
+  created_at DATETIME NOT NULL,
+  last_active_at DATETIME NOT NULL,
+  CONSTRAINT fk_sessions_user FOREIGN KEY (user_id) REFERENCES users (user_id) ON DELETE CASCADE
+);
+CREATE INDEX idx_sessions_user ON sessions (user_id);
+
+-- SQLite 不能删除带外键约束的列，这里不加外键，撤销会话时由代码一并删除对应的 refresh token。
+ALTER TABLE refresh_tokens ADD COLUMN session_id INTEGER NULL DEFAULT NULL;
+CREATE INDEX idx_refresh_tokens_session ON refresh_tokens (session_id);
@@ -0,0 +1,11 @@
+-- 主键保证同一用户对同一文档只收藏一次；文档彻底删除时级联清理收藏，移入回收站的文档保留收藏以便恢复。
+CREATE TABLE user_favorites (
+  user_id INTEGER NOT NULL,
+  doc_id INTEGER NOT NULL,
+  created_at DATETIME NOT NULL,
+  PRIMARY KEY (user_id, doc_id),
+  CONSTRAINT fk_user_favorites_user FOREIGN KEY (user_id) REFERENCES users (user_id) ON DELETE CASCADE,
+  CONSTRAINT fk_user_favorites_doc FOREIGN KEY (doc_id) REFERENCES docs (doc_id) ON DELETE CASCADE
+);
+CREATE INDEX idx_user_favorites_user_created ON user_favorites (user_id, created_at);
+CREATE INDEX idx_user_favorites_doc ON user_favorites (doc_id);
@@ -0,0 +1,9 @@
+-- 浏览量按天汇总，服务端先在内存中去重与累加，再定期批量写入；文档彻底删除时级联清理。
+CREATE TABLE doc_views (
+  doc_id INTEGER NOT NULL,
+  view_date DATE NOT NULL,
+  views INTEGER NOT NULL DEFAULT 0,
+  PRIMARY KEY (doc_id, view_date),
+  CONSTRAINT fk_doc_views_doc FOREIGN KEY (doc_id) REFERENCES docs (doc_id) ON DELETE CASCADE
+);
+CREATE INDEX idx_doc_views_date ON doc_views (view_date);
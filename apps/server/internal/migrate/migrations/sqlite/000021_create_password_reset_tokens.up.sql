@@ -0,0 +1,11 @@
+-- 只保存重置 token 的 SHA-256 摘要；token 使用后或用户重置成功后删除，同一用户重新申请时替换之前未使用的 token。
+CREATE TABLE password_reset_tokens (
+  token_id INTEGER PRIMARY KEY AUTOINCREMENT,
+  user_id INTEGER NOT NULL,
+  token_hash CHAR(64) NOT NULL,
+  expires_at DATETIME NOT NULL,
+  created_at DATETIME NOT NULL,
+  CONSTRAINT uk_password_reset_tokens_hash UNIQUE (token_hash),
+  CONSTRAINT fk_password_reset_tokens_user FOREIGN KEY (user_id) REFERENCES users (user_id) ON DELETE CASCADE
+);
+CREATE INDEX idx_password_reset_tokens_user ON password_reset_tokens (user_id);
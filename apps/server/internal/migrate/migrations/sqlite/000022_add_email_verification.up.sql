@@ -0,0 +1,15 @@
+-- email_verified_at 为空表示邮箱尚未验证；已有用户视为已验证，升级后不会被拦在登录之外。
+ALTER TABLE users ADD COLUMN email_verified_at DATETIME NULL DEFAULT NULL;
+UPDATE users SET email_verified_at = created_at;
+
+-- 验证 token 只保存 SHA-256 摘要，验证成功后删除该用户的全部 token，重发时替换之前未使用的 token。
+CREATE TABLE email_verification_tokens (
+  token_id INTEGER PRIMARY KEY AUTOINCREMENT,
+  user_id INTEGER NOT NULL,
+  token_hash CHAR(64) NOT NULL,
+  expires_at DATETIME NOT NULL,
+  created_at DATETIME NOT NULL,
+  CONSTRAINT uk_email_verification_tokens_hash UNIQUE (token_hash),
+  CONSTRAINT fk_email_verification_tokens_user FOREIGN KEY (user_id) REFERENCES users (user_id) ON DELETE CASCADE
+);
+CREATE INDEX idx_email_verification_tokens_user ON email_verification_tokens (user_id);
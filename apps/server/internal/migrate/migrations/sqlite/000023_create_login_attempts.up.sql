@@ -0,0 +1,9 @@
+-- 登录失败计数：attempt_key 为 email:<小写邮箱> 或 ip:<客户端 IP>，两个维度分别计数；次数达到上限后写入 locked_until 并清零，
+-- 成功登录后删除账号维度的记录。
+CREATE TABLE login_attempts (
+  attempt_key VARCHAR(191) NOT NULL PRIMARY KEY,
+  failures INTEGER NOT NULL DEFAULT 0,
+  locked_until DATETIME NULL DEFAULT NULL,
+  updated_at DATETIME NOT NULL
+);
+CREATE INDEX idx_login_attempts_updated ON login_attempts (updated_at);
@@ -0,0 +1,16 @@
+-- 各空间的同名标签合并回一个全局标签（保留 default 空间中的那个）。
+INSERT INTO tags (space, name, color, created_at)
+SELECT 'default', name, MIN(color), MIN(created_at) FROM tags t
+WHERE space <> 'default' AND NOT EXISTS (SELECT 1 FROM tags d WHERE d.space = 'default' AND d.name = t.name)
+GROUP BY name;
+UPDATE OR IGNORE doc_tags SET tag_id = (
+  SELECT d.tag_id FROM tags d JOIN tags t ON t.name = d.name WHERE d.space = 'default' AND t.tag_id = doc_tags.tag_id
+)
+WHERE tag_id IN (SELECT tag_id FROM tags WHERE space <> 'default');
+DELETE FROM tags WHERE space <> 'default';
+DROP INDEX uk_tags_space_name;
+ALTER TABLE tags DROP COLUMN space;
+CREATE UNIQUE INDEX uk_tags_name ON tags (name);
+
+DROP TABLE IF EXISTS space_members;
+DROP TABLE IF EXISTS spaces;
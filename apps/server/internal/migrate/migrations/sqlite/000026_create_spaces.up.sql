@@ -0,0 +1,45 @@
+-- 空间：space_key 即 docs.space 与 tags.space 中的取值。公开空间人人可见，私有空间只对成员与管理员可见。
+CREATE TABLE spaces (
+  space_id INTEGER PRIMARY KEY AUTOINCREMENT,
+  space_key VARCHAR(64) NOT NULL,
+  name VARCHAR(100) NOT NULL,
+  description VARCHAR(255) NOT NULL DEFAULT '',
+  is_private BOOLEAN NOT NULL DEFAULT 0,
+  created_by_user_id INTEGER NULL DEFAULT NULL,
+  created_at DATETIME NOT NULL,
+  updated_at DATETIME NOT NULL,
+  CONSTRAINT fk_spaces_created_by FOREIGN KEY (created_by_user_id) REFERENCES users (user_id) ON DELETE SET NULL
+);
+CREATE UNIQUE INDEX uk_spaces_key ON spaces (space_key);
+
+-- role 为 owner（可管理空间与成员）或 member。
+CREATE TABLE space_members (
+  space_id INTEGER NOT NULL,
+  user_id INTEGER NOT NULL,
+  role VARCHAR(16) NOT NULL,
+  created_at DATETIME NOT NULL,
+  PRIMARY KEY (space_id, user_id),
+  CONSTRAINT fk_space_members_space FOREIGN KEY (space_id) REFERENCES spaces (space_id) ON DELETE CASCADE,
+  CONSTRAINT fk_space_members_user FOREIGN KEY (user_id) REFERENCES users (user_id) ON DELETE CASCADE
+);
+CREATE INDEX idx_space_members_user ON space_members (user_id);
+
+-- 已有文档所在的空间登记为公开空间，保持升级前的可见范围。
+INSERT INTO spaces (space_key, name, created_at, updated_at) VALUES ('default', 'default', CURRENT_TIMESTAMP, CURRENT_TIMESTAMP);
+INSERT INTO spaces (space_key, name, created_at, updated_at)
+SELECT space, space, MIN(created_at), MIN(created_at) FROM docs WHERE space <> 'default' GROUP BY space;
+
+-- 标签改为按空间区分：被其他空间的文档使用的标签复制到对应空间，再把这些文档的关联指向副本。
+ALTER TABLE tags ADD COLUMN space VARCHAR(64) NOT NULL DEFAULT 'default';
+DROP INDEX uk_tags_name;
+CREATE UNIQUE INDEX uk_tags_space_name ON tags (space, name);
+INSERT INTO tags (space, name, color, created_at)
+SELECT DISTINCT d.space, t.name, t.color, t.created_at FROM doc_tags dt
+JOIN docs d ON d.doc_id = dt.doc_id JOIN tags t ON t.tag_id = dt.tag_id WHERE d.space <> t.space;
+UPDATE doc_tags SET tag_id = (
+  SELECT n.tag_id FROM tags n JOIN tags o ON o.name = n.name JOIN docs d ON d.space = n.space
+  WHERE o.tag_id = doc_tags.tag_id AND d.doc_id = doc_tags.doc_id
+)
+WHERE EXISTS (
+  SELECT 1 FROM docs d JOIN tags t ON t.space <> d.space WHERE d.doc_id = doc_tags.doc_id AND t.tag_id = doc_tags.tag_id
+);
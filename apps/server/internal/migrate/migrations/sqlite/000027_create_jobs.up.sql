@@ -0,0 +1,19 @@
+-- 异步作业：kind 决定执行逻辑，params 与 result 为 JSON。status 依次为 pending、running，结束后为 succeeded 或 failed；
+-- 运行中的作业定期刷新 updated_at，进程重启后长时间未刷新的作业标记为 failed。
+CREATE TABLE jobs (
+  job_id INTEGER PRIMARY KEY AUTOINCREMENT,
+  kind VARCHAR(64) NOT NULL,
+  status VARCHAR(16) NOT NULL,
+  progress INTEGER NOT NULL DEFAULT 0,
+  params TEXT NOT NULL,
+  result TEXT NULL DEFAULT NULL,
+  error VARCHAR(1024) NOT NULL DEFAULT '',
+  created_by_user_id INTEGER NULL DEFAULT NULL,
+  created_at DATETIME NOT NULL,
+  started_at DATETIME NULL DEFAULT NULL,
+  finished_at DATETIME NULL DEFAULT NULL,
+  updated_at DATETIME NOT NULL,
+  CONSTRAINT fk_jobs_created_by FOREIGN KEY (created_by_user_id) REFERENCES users (user_id) ON DELETE SET NULL
+);
+CREATE INDEX idx_jobs_status ON jobs (status, job_id);
+CREATE INDEX idx_jobs_created_by ON jobs (created_by_user_id, job_id);
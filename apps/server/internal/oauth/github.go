@@ -0,0 +1,55 @@
+package oauth
+
+import (
+	"context"
+	"net/http"
+	"strconv"
+
+	"golang.org/x/oauth2"
+	"golang.org/x/oauth2/endpoints"
+)
+
+func GitHub(clientID string, clientSecret string) *Provider {
+	return &Provider{
+		Name: "github",
+		config: oauth2.Config{
+			ClientID:     clientID,
+			ClientSecret: clientSecret,
+			Endpoint:     endpoints.GitHub,
+			Scopes:       []string{"read:user", "user:email"},
+		},
+		profile: githubProfile,
+	}
+}
+
+// githubProfile 读取 GitHub 用户资料；公开资料里的邮箱可能为空或未验证，因此以 /user/emails 中的主邮箱为准。
+func githubProfile(ctx context.Context, client *http.Client) (*Identity, error) {
+	var user struct {
+		ID    int64  `json:"id"`
+		Login string `json:"login"`
+		Name  string `json:"name"`
+	}
+	if err := getJSON(ctx, client, "https://api.github.com/user", &user); err != nil {
+		return nil, err
+	}
+	var emails []struct {
+		Email    string `json:"email"`
+		Primary  bool   `json:"primary"`
+		Verified bool   `json:"verified"`
+	}
+	if err := getJSON(ctx, client, "https://api.github.com/user/emails", &emails); err != nil {
+		return nil, err
+	}
+
+	identity := &Identity{Subject: strconv.FormatInt(user.ID, 10), Name: user.Name}
+	if identity.Name == "" {
+		identity.Name = user.Login
+	}
+	for _, email := range emails {
+		if email.Primary {
+			identity.Email, identity.EmailVerified = email.Email, email.Verified
+			break
+		}
+	}
+	return identity, nil
+}
@@ -0,0 +1,35 @@
+package oauth
+
+import (
+	"context"
+	"net/http"
+
+	"golang.org/x/oauth2"
+	"golang.org/x/oauth2/endpoints"
+)
+
+func Google(clientID string, clientSecret string) *Provider {
+	return &Provider{
+		Name: "google",
+		config: oauth2.Config{
+			ClientID:     clientID,
+			ClientSecret: clientSecret,
+			Endpoint:     endpoints.Google,
+			Scopes:       []string{"openid", "email", "profile"},
+		},
+		profile: googleProfile,
+	}
+}
+
+func googleProfile(ctx context.Context, client *http.Client) (*Identity, error) {
+	var user struct {
+		Subject       string `json:"sub"`
+		Email         string `json:"email"`
+		EmailVerified bool   `json:"email_verified"`
+		Name          string `json:"name"`
+	}
+	if err := getJSON(ctx, client, "https://openidconnect.googleapis.com/v1/userinfo", &user); err != nil {
+		return nil, err
+	}
+	return &Identity{Subject: user.Subject, Email: user.Email, EmailVerified: user.EmailVerified, Name: user.Name}, nil
+}
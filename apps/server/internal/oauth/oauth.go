@@ -0,0 +1,79 @@
+package oauth
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+
+	"golang.org/x/oauth2"
+)
+
+// maxProfileBytes 限制读取第三方用户资料接口的响应大小。
+const maxProfileBytes = 1 << 20
+
+// ErrNoEmail 表示第三方账号没有可用的邮箱，无法匹配或创建本地用户。
+var ErrNoEmail = errors.New("oauth account has no email address")
+
+// Identity 是第三方账号的用户资料；Subject 是该 provider 内不变的用户 id，邮箱可能被用户修改。
+type Identity struct {
+	Provider      string
+	Subject       string
+	Email         string
+	EmailVerified bool
+	Name          string
+}
+
+// Provider 是一个 OAuth2 登录方，可并发使用。
+type Provider struct {
+	Name    string
+	config  oauth2.Config
+	profile func(ctx context.Context, client *http.Client) (*Identity, error)
+}
+
+// AuthCodeURL 返回跳转到第三方授权页的地址，redirectURL 是本站的回调地址。
+func (p *Provider) AuthCodeURL(state string, redirectURL string) string {
+	config := p.config
+	config.RedirectURL = redirectURL
+	return config.AuthCodeURL(state)
+}
+
+// Exchange 用回调中的 code 换取 access token 并读取用户资料。
+func (p *Provider) Exchange(ctx context.Context, code string, redirectURL string) (*Identity, error) {
+	config := p.config
+	config.RedirectURL = redirectURL
+	token, err := config.Exchange(ctx, code)
+	if err != nil {
+		return nil, fmt.Errorf("exchange %s code: %w", p.Name, err)
+	}
+	identity, err := p.profile(ctx, config.Client(ctx, token))
+	if err != nil {
+		return nil, err
+	}
+	identity.Provider = p.Name
+	if identity.Email == "" {
+		return nil, ErrNoEmail
+	}
+	return identity, nil
+}
+
+// getJSON 以 GET 请求读取 JSON 接口并解码到 dst。
+func getJSON(ctx context.Context, client *http.Client, url string, dst any) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Accept", "application/json")
+	resp, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("GET %s: unexpected status %s", url, resp.Status)
+	}
+	return json.NewDecoder(io.LimitReader(resp.Body, maxProfileBytes)).Decode(dst)
+}
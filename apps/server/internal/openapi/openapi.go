@@ -0,0 +1,414 @@
+// Package openapi 根据已注册的路由与手工登记的接口元信息生成 OpenAPI 3.0 规范。
+// 请求与响应结构由结构体的 json/binding 标签反射得到，登记与路由不一致时 Build 返回错误，避免规范与实现脱节。
+package openapi
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/lifei6671/plaindoc/apps/server/internal/auth"
+	"github.com/lifei6671/plaindoc/apps/server/internal/i18n"
+	"github.com/lifei6671/plaindoc/apps/server/internal/server/httpx"
+)
+
+// Version 是生成的规范遵循的 OpenAPI 版本。
+const Version = "3.0.3"
+
+// Auth 是访问接口需要的身份。
+type Auth int
+
+const (
+	// AuthNone 是公开接口，携带凭证时按登录用户处理（如可以读到自己的私有文档）。
+	AuthNone Auth = iota
+	// AuthUser 需要登录，接受 access token 与 API Key。
+	AuthUser
+	// AuthSession 需要登录会话，不接受 API Key。
+	AuthSession
+)
+
+// Operation 是登记的一个接口。
+type Operation struct {
+	Tag         string
+	Summary     string
+	Description string
+	Auth        Auth
+	// Roles 非空时只有这些全局角色可以访问。
+	Roles []string
+	Query []Param
+	// Body 是 JSON 请求体的零值，nil 表示没有 JSON 请求体；BodyOptional 表示请求体可以省略。
+	Body         any
+	BodyOptional bool
+	// Form 是 multipart/form-data 请求体的字段，与 Body 二选一。
+	Form []Param
+	// Status 是成功时的状态码，默认没有响应体时为 204，否则为 200。
+	Status int
+	// Response 是成功时 JSON 响应体的零值，可以用 Object、OneOf、Paged 与 Items 描述没有对应结构体的响应。
+	Response any
+	// ContentType 非空时成功响应是该类型的原始内容（如下载文件），不使用 Response。
+	ContentType string
+	// Errors 是接口自身可能返回的错误；鉴权、请求体校验、分页参数、限流与 500 由 Build 按登记的信息补充。
+	Errors []Error
+}
+
+// Param 是查询参数或表单字段。
+type Param struct {
+	Name        string
+	Description string
+	// Type 是 string、integer、boolean，表单中的文件为 file，默认 string。
+	Type     string
+	Enum     []string
+	Required bool
+}
+
+// Error 是接口以 status 返回的错误，Keys 是 i18n 消息键，规范中列出对应的 code 与基准语言的消息。
+type Error struct {
+	Status int
+	Keys   []string
+}
+
+// E 是 Error 的简写。
+func E(status int, keys ...string) Error {
+	return Error{Status: status, Keys: keys}
+}
+
+// Pagination 是分页列表接口共用的 page/page_size 查询参数。
+func Pagination(params ...Param) []Param {
+	return append([]Param{
+		{Name: "page", Type: "integer", Description: "页码，从 1 开始"},
+		{Name: "page_size", Type: "integer", Description: fmt.Sprintf("每页条数，默认 %d，最大 %d", httpx.DefaultPageSize, httpx.MaxPageSize)},
+	}, params...)
+}
+
+// Options 是规范的基本信息，Prefix 是登记的接口共同的路由前缀，同时作为规范的 server 地址。
+type Options struct {
+	Title       string
+	Version     string
+	Description string
+	Prefix      string
+}
+
+// Document 是生成的规范，直接序列化为 JSON 输出。
+type Document struct {
+	OpenAPI    string                                 `json:"openapi"`
+	Info       info                                   `json:"info"`
+	Servers    []server                               `json:"servers"`
+	Tags       []tag                                  `json:"tags"`
+	Paths      map[string]map[string]*operationObject `json:"paths"`
+	Components components                             `json:"components"`
+}
+
+type info struct {
+	Title       string `json:"title"`
+	Version     string `json:"version"`
+	Description string `json:"description,omitempty"`
+}
+
+type server struct {
+	URL string `json:"url"`
+}
+
+type tag struct {
+	Name string `json:"name"`
+}
+
+type components struct {
+	Schemas         map[string]*Schema        `json:"schemas"`
+	SecuritySchemes map[string]securityScheme `json:"securitySchemes"`
+}
+
+type securityScheme struct {
+	Type         string `json:"type"`
+	Description  string `json:"description,omitempty"`
+	Scheme       string `json:"scheme,omitempty"`
+	BearerFormat string `json:"bearerFormat,omitempty"`
+	Name         string `json:"name,omitempty"`
+	In           string `json:"in,omitempty"`
+}
+
+type operationObject struct {
+	OperationID string                `json:"operationId"`
+	Tags        []string              `json:"tags,omitempty"`
+	Summary     string                `json:"summary,omitempty"`
+	Description string                `json:"description,omitempty"`
+	Parameters  []parameter           `json:"parameters,omitempty"`
+	RequestBody *requestBody          `json:"requestBody,omitempty"`
+	Responses   map[string]response   `json:"responses"`
+	Security    []map[string][]string `json:"security"`
+}
+
+type parameter struct {
+	Name        string  `json:"name"`
+	In          string  `json:"in"`
+	Description string  `json:"description,omitempty"`
+	Required    bool    `json:"required,omitempty"`
+	Schema      *Schema `json:"schema"`
+}
+
+type requestBody struct {
+	Required bool                 `json:"required"`
+	Content  map[string]mediaType `json:"content"`
+}
+
+type mediaType struct {
+	Schema *Schema `json:"schema"`
+}
+
+type response struct {
+	Description string               `json:"description"`
+	Content     map[string]mediaType `json:"content,omitempty"`
+}
+
+const (
+	bearerScheme = "bearerAuth"
+	apiKeyScheme = "apiKeyAuth"
+	cookieScheme = "cookieAuth"
+	errorSchema  = "#/components/schemas/ErrorResponse"
+)
+
+var (
+	pathParam = regexp.MustCompile(`[:*]([A-Za-z0-9_]+)`)
+	fmtVerb   = regexp.MustCompile(`%[-+# 0]*[0-9]*(\.[0-9]+)?[a-zA-Z]`)
+	// integerParams 是取值为数据库 ID 或版本号的路径参数。
+	integerParams = map[string]bool{"id": true, "v": true, "pid": true, "uid": true}
+)
+
+// Build 生成 routes 中以 Prefix 开头的接口的规范，operations 的键为 "方法 路径"（路径不含前缀，如 "GET /docs/:id"）。
+// 已注册但未登记、或已登记但未注册的接口都会返回错误。
+func Build(opts Options, routes gin.RoutesInfo, operations map[string]Operation) (*Document, error) {
+	registered := map[string]bool{}
+	var missing []string
+	for _, route := range routes {
+		path, ok := strings.CutPrefix(route.Path, opts.Prefix)
+		if !ok || route.Method == http.MethodHead {
+			continue
+		}
+		key := route.Method + " " + path
+		registered[key] = true
+		if _, ok := operations[key]; !ok {
+			missing = append(missing, key)
+		}
+	}
+	var stale []string
+	for key := range operations {
+		if !registered[key] {
+			stale = append(stale, key)
+		}
+	}
+	var errs []error
+	if len(missing) > 0 {
+		sort.Strings(missing)
+		errs = append(errs, fmt.Errorf("routes without openapi operation: %s", strings.Join(missing, ", ")))
+	}
+	if len(stale) > 0 {
+		sort.Strings(stale)
+		errs = append(errs, fmt.Errorf("openapi operations without route: %s", strings.Join(stale, ", ")))
+	}
+	if len(errs) > 0 {
+		return nil, errors.Join(errs...)
+	}
+
+	doc := &Document{
+		OpenAPI: Version,
+		Info:    info{Title: opts.Title, Version: opts.Version, Description: opts.Description},
+		Servers: []server{{URL: opts.Prefix}},
+		Paths:   map[string]map[string]*operationObject{},
+		Components: components{
+			SecuritySchemes: map[string]securityScheme{
+				bearerScheme: {
+					Type: "http", Scheme: "bearer", BearerFormat: "JWT",
+					Description: "登录返回的 access token；API Key 也可以用 `Authorization: ApiKey <key>` 传递",
+				},
+				apiKeyScheme: {Type: "apiKey", In: "header", Name: "X-API-Key", Description: "API Key，只读 key 只能调用 GET 接口"},
+				cookieScheme: {Type: "apiKey", In: "cookie", Name: auth.AccessTokenCookie, Description: "浏览器登录后写入的 HttpOnly cookie"},
+			},
+		},
+	}
+	schemas := newSchemas()
+	schemas.value(httpx.ErrorResponse{})
+
+	keys := make([]string, 0, len(operations))
+	for key := range operations {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	tags := map[string]bool{}
+	for _, key := range keys {
+		method, path, _ := strings.Cut(key, " ")
+		op := operations[key]
+		template := pathParam.ReplaceAllString(path, "{$1}")
+		if doc.Paths[template] == nil {
+			doc.Paths[template] = map[string]*operationObject{}
+		}
+		doc.Paths[template][strings.ToLower(method)] = buildOperation(schemas, method, path, op)
+		if op.Tag != "" && !tags[op.Tag] {
+			tags[op.Tag] = true
+			doc.Tags = append(doc.Tags, tag{Name: op.Tag})
+		}
+	}
+	sort.Slice(doc.Tags, func(i, j int) bool { return doc.Tags[i].Name < doc.Tags[j].Name })
+	doc.Components.Schemas = schemas.components
+	return doc, nil
+}
+
+func buildOperation(schemas *schemas, method string, path string, op Operation) *operationObject {
+	out := &operationObject{
+		OperationID: operationID(method, path),
+		Summary:     op.Summary,
+		Description: op.Description,
+		Responses:   map[string]response{},
+	}
+	if op.Tag != "" {
+		out.Tags = []string{op.Tag}
+	}
+	if len(op.Roles) > 0 {
+		out.Description = strings.TrimSpace(out.Description + "\n\n需要角色：" + strings.Join(op.Roles, "、"))
+	}
+
+	for _, match := range pathParam.FindAllStringSubmatch(path, -1) {
+		schema := &Schema{Type: "string"}
+		if integerParams[match[1]] {
+			schema = &Schema{Type: "integer", Format: "int64"}
+		}
+		out.Parameters = append(out.Parameters, parameter{Name: match[1], In: "path", Required: true, Schema: schema})
+	}
+	for _, param := range op.Query {
+		out.Parameters = append(out.Parameters, parameter{
+			Name: param.Name, In: "query", Description: param.Description, Required: param.Required, Schema: paramSchema(param),
+		})
+	}
+
+	errs := append([]Error(nil), op.Errors...)
+	switch {
+	case op.Body != nil:
+		out.RequestBody = &requestBody{Required: !op.BodyOptional, Content: map[string]mediaType{"application/json": {Schema: schemas.value(op.Body)}}}
+		errs = append(errs, E(http.StatusBadRequest, i18n.InvalidRequest), E(http.StatusRequestEntityTooLarge, i18n.RequestTooLarge))
+	case len(op.Form) > 0:
+		form := &Schema{Type: "object", Properties: map[string]*Schema{}}
+		for _, field := range op.Form {
+			form.Properties[field.Name] = paramSchema(field)
+			if field.Required {
+				form.Required = append(form.Required, field.Name)
+			}
+		}
+		out.RequestBody = &requestBody{Required: true, Content: map[string]mediaType{"multipart/form-data": {Schema: form}}}
+	}
+	for _, param := range op.Query {
+		if param.Name == "page" {
+			errs = append(errs, E(http.StatusBadRequest, i18n.InvalidPage, i18n.InvalidPageSize))
+			break
+		}
+	}
+
+	switch op.Auth {
+	case AuthNone:
+		// 空的安全要求表示也可以不携带凭证。
+		out.Security = []map[string][]string{{}, {bearerScheme: {}}, {apiKeyScheme: {}}, {cookieScheme: {}}}
+	case AuthUser:
+		out.Security = []map[string][]string{{bearerScheme: {}}, {apiKeyScheme: {}}, {cookieScheme: {}}}
+	case AuthSession:
+		out.Security = []map[string][]string{{bearerScheme: {}}, {cookieScheme: {}}}
+		errs = append(errs, E(http.StatusForbidden, i18n.APIKeyNotAllowed))
+	}
+	if op.Auth != AuthNone {
+		errs = append(errs, E(http.StatusUnauthorized, i18n.Unauthorized, i18n.InvalidAccessToken, i18n.InvalidAPIKey, i18n.TokenExpired))
+	}
+	if op.Auth != AuthSession && method != http.MethodGet {
+		errs = append(errs, E(http.StatusForbidden, i18n.APIKeyReadOnly))
+	}
+	if len(op.Roles) > 0 {
+		errs = append(errs, E(http.StatusForbidden, i18n.InsufficientRole))
+	}
+	errs = append(errs, E(http.StatusTooManyRequests, i18n.RateLimited), E(http.StatusInternalServerError, i18n.InternalError))
+
+	status := op.Status
+	if status == 0 {
+		status = http.StatusOK
+		if op.Response == nil && op.ContentType == "" {
+			status = http.StatusNoContent
+		}
+	}
+	success := response{Description: http.StatusText(status)}
+	switch {
+	case op.ContentType != "":
+		success.Content = map[string]mediaType{op.ContentType: {Schema: &Schema{Type: "string", Format: "binary"}}}
+	case op.Response != nil:
+		success.Content = map[string]mediaType{"application/json": {Schema: schemas.value(op.Response)}}
+	}
+	out.Responses[fmt.Sprint(status)] = success
+	for status, keys := range groupErrors(errs) {
+		out.Responses[fmt.Sprint(status)] = errorResponse(status, keys)
+	}
+	return out
+}
+
+func paramSchema(param Param) *Schema {
+	switch param.Type {
+	case "", "string":
+		return &Schema{Type: "string", Enum: param.Enum}
+	case "integer":
+		return &Schema{Type: "integer", Format: "int64"}
+	case "file":
+		return &Schema{Type: "string", Format: "binary"}
+	}
+	return &Schema{Type: param.Type}
+}
+
+// groupErrors 按状态码合并消息键并去重，保持登记的顺序。
+func groupErrors(errs []Error) map[int][]string {
+	grouped := map[int][]string{}
+	seen := map[string]bool{}
+	for _, err := range errs {
+		for _, key := range err.Keys {
+			id := fmt.Sprint(err.Status, key)
+			if !seen[id] {
+				seen[id] = true
+				grouped[err.Status] = append(grouped[err.Status], key)
+			}
+		}
+	}
+	return grouped
+}
+
+// errorResponse 生成 status 的错误响应：code 限定为 keys 对应的错误码，说明中逐条列出基准语言的消息。
+func errorResponse(status int, keys []string) response {
+	var codes []string
+	seen := map[string]bool{}
+	lines := []string{http.StatusText(status)}
+	for _, key := range keys {
+		code := i18n.CodeOf(key)
+		if !seen[code] {
+			seen[code] = true
+			codes = append(codes, code)
+		}
+		lines = append(lines, fmt.Sprintf("- `%s`: %s", code, fmtVerb.ReplaceAllString(i18n.Message(i18n.Base, key), "…")))
+	}
+	schema := &Schema{AllOf: []*Schema{
+		{Ref: errorSchema},
+		{Type: "object", Properties: map[string]*Schema{"code": {Type: "string", Enum: codes}}},
+	}}
+	return response{
+		Description: strings.Join(lines, "\n"),
+		Content:     map[string]mediaType{"application/json": {Schema: schema}},
+	}
+}
+
+// operationID 由方法与路径生成，如 POST /docs/:id/publish 为 postDocsByIdPublish。
+func operationID(method string, path string) string {
+	var b strings.Builder
+	b.WriteString(strings.ToLower(method))
+	for _, segment := range strings.Split(path, "/") {
+		if name, ok := strings.CutPrefix(segment, ":"); ok {
+			b.WriteString("By")
+			segment = name
+		}
+		for _, word := range strings.FieldsFunc(segment, func(r rune) bool { return r == '-' || r == '_' || r == '.' }) {
+			b.WriteString(exportedName(word))
+		}
+	}
+	return b.String()
+}
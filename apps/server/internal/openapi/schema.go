@@ -0,0 +1,245 @@
+package openapi
+
+import (
+	"encoding/json"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+)
+
+// Schema 是 OpenAPI 3.0 的 Schema Object，只包含生成规范用到的字段。
+type Schema struct {
+	Ref                  string             `json:"$ref,omitempty"`
+	Type                 string             `json:"type,omitempty"`
+	Format               string             `json:"format,omitempty"`
+	Description          string             `json:"description,omitempty"`
+	Nullable             bool               `json:"nullable,omitempty"`
+	Enum                 []string           `json:"enum,omitempty"`
+	MinLength            *int               `json:"minLength,omitempty"`
+	MaxLength            *int               `json:"maxLength,omitempty"`
+	Minimum              *float64           `json:"minimum,omitempty"`
+	Maximum              *float64           `json:"maximum,omitempty"`
+	MinItems             *int               `json:"minItems,omitempty"`
+	MaxItems             *int               `json:"maxItems,omitempty"`
+	Items                *Schema            `json:"items,omitempty"`
+	Properties           map[string]*Schema `json:"properties,omitempty"`
+	Required             []string           `json:"required,omitempty"`
+	AdditionalProperties *Schema            `json:"additionalProperties,omitempty"`
+	AllOf                []*Schema          `json:"allOf,omitempty"`
+	OneOf                []*Schema          `json:"oneOf,omitempty"`
+}
+
+// Object 描述 gin.H 这类没有对应结构体的 JSON 对象：键为字段名，值为字段类型的零值。
+type Object map[string]any
+
+// OneOf 描述按情况返回几种结构之一的响应，如登录时直接签发会话或要求两步验证。
+type OneOf []any
+
+// Paged 描述 httpx.PagedResponse 分页响应，item 是列表元素类型的零值。
+func Paged(item any) Object {
+	return Object{
+		"items":     reflect.New(reflect.SliceOf(reflect.TypeOf(item))).Elem().Interface(),
+		"total":     0,
+		"page":      0,
+		"page_size": 0,
+	}
+}
+
+// Items 描述 {"items": [...]} 形式的不分页列表响应。
+func Items(item any) Object {
+	return Object{"items": reflect.New(reflect.SliceOf(reflect.TypeOf(item))).Elem().Interface()}
+}
+
+var (
+	timeType       = reflect.TypeOf(time.Time{})
+	rawMessageType = reflect.TypeOf(json.RawMessage{})
+	byteSliceType  = reflect.TypeOf([]byte{})
+)
+
+// schemas 把 Go 类型转换为 Schema：具名结构体登记到 components 并以 $ref 引用，同名的不同类型加上包名区分。
+type schemas struct {
+	components map[string]*Schema
+	names      map[reflect.Type]string
+}
+
+func newSchemas() *schemas {
+	return &schemas{components: map[string]*Schema{}, names: map[reflect.Type]string{}}
+}
+
+// value 生成示例值 v 的 Schema，v 可以是 Object、OneOf 或任意类型的零值。
+func (s *schemas) value(v any) *Schema {
+	switch v := v.(type) {
+	case Object:
+		schema := &Schema{Type: "object", Properties: map[string]*Schema{}}
+		for name, field := range v {
+			schema.Properties[name] = s.value(field)
+		}
+		return schema
+	case OneOf:
+		schema := &Schema{}
+		for _, item := range v {
+			schema.OneOf = append(schema.OneOf, s.value(item))
+		}
+		return schema
+	case nil:
+		return &Schema{}
+	}
+	return s.typeOf(reflect.TypeOf(v))
+}
+
+func (s *schemas) typeOf(t reflect.Type) *Schema {
+	switch t {
+	case timeType:
+		return &Schema{Type: "string", Format: "date-time"}
+	case rawMessageType:
+		return &Schema{}
+	case byteSliceType:
+		return &Schema{Type: "string", Format: "byte"}
+	}
+	switch t.Kind() {
+	case reflect.Pointer:
+		schema := s.typeOf(t.Elem())
+		if schema.Ref != "" {
+			return &Schema{AllOf: []*Schema{schema}, Nullable: true}
+		}
+		schema.Nullable = true
+		return schema
+	case reflect.Bool:
+		return &Schema{Type: "boolean"}
+	case reflect.Int, reflect.Int64, reflect.Uint, reflect.Uint64:
+		return &Schema{Type: "integer", Format: "int64"}
+	case reflect.Int8, reflect.Int16, reflect.Int32, reflect.Uint8, reflect.Uint16, reflect.Uint32:
+		return &Schema{Type: "integer", Format: "int32"}
+	case reflect.Float32, reflect.Float64:
+		return &Schema{Type: "number"}
+	case reflect.String:
+		return &Schema{Type: "string"}
+	case reflect.Slice, reflect.Array:
+		return &Schema{Type: "array", Items: s.typeOf(t.Elem())}
+	case reflect.Map:
+		return &Schema{Type: "object", AdditionalProperties: s.typeOf(t.Elem())}
+	case reflect.Struct:
+		if t.Name() == "" {
+			return s.object(t)
+		}
+		return &Schema{Ref: "#/components/schemas/" + s.component(t)}
+	}
+	return &Schema{}
+}
+
+// component 登记具名结构体并返回其在 components 中的名称。
+func (s *schemas) component(t reflect.Type) string {
+	if name, ok := s.names[t]; ok {
+		return name
+	}
+	name := exportedName(t.Name())
+	if _, taken := s.components[name]; taken {
+		pkg := t.PkgPath()
+		name = exportedName(pkg[strings.LastIndex(pkg, "/")+1:]) + name
+	}
+	s.names[t] = name
+	// 先占位，结构体引用自身（如树形节点）时不会无限递归。
+	s.components[name] = &Schema{}
+	*s.components[name] = *s.object(t)
+	return name
+}
+
+// object 按 json 标签生成结构体的属性，匿名嵌入的结构体展开到外层，binding 标签转换为必填与取值约束。
+func (s *schemas) object(t reflect.Type) *Schema {
+	schema := &Schema{Type: "object", Properties: map[string]*Schema{}}
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		name, _, _ := strings.Cut(field.Tag.Get("json"), ",")
+		if name == "-" || (!field.IsExported() && !field.Anonymous) {
+			continue
+		}
+		if field.Anonymous && name == "" && indirect(field.Type).Kind() == reflect.Struct {
+			embedded := s.object(indirect(field.Type))
+			for key, property := range embedded.Properties {
+				schema.Properties[key] = property
+			}
+			schema.Required = append(schema.Required, embedded.Required...)
+			continue
+		}
+		if name == "" {
+			name = field.Name
+		}
+		property := s.typeOf(field.Type)
+		if applyBinding(property, field.Tag.Get("binding"), indirect(field.Type).Kind()) {
+			schema.Required = append(schema.Required, name)
+		}
+		schema.Properties[name] = property
+	}
+	sort.Strings(schema.Required)
+	return schema
+}
+
+// applyBinding 把 validator 的 binding 规则转换为 Schema 约束，返回字段是否必填；dive 之后的规则作用于元素，不再处理。
+func applyBinding(schema *Schema, tag string, kind reflect.Kind) bool {
+	required := false
+	for _, rule := range strings.Split(tag, ",") {
+		name, param, _ := strings.Cut(rule, "=")
+		switch name {
+		case "dive":
+			return required
+		case "required":
+			required = true
+		case "email":
+			schema.Format = "email"
+		case "url":
+			schema.Format = "uri"
+		case "oneof":
+			schema.Enum = strings.Fields(param)
+		case "min", "max":
+			n, err := strconv.Atoi(param)
+			if err != nil {
+				continue
+			}
+			setBound(schema, name == "min", n, kind)
+		}
+	}
+	return required
+}
+
+func setBound(schema *Schema, lower bool, n int, kind reflect.Kind) {
+	switch kind {
+	case reflect.String:
+		if lower {
+			schema.MinLength = &n
+		} else {
+			schema.MaxLength = &n
+		}
+	case reflect.Slice, reflect.Array:
+		if lower {
+			schema.MinItems = &n
+		} else {
+			schema.MaxItems = &n
+		}
+	default:
+		value := float64(n)
+		if lower {
+			schema.Minimum = &value
+		} else {
+			schema.Maximum = &value
+		}
+	}
+}
+
+func indirect(t reflect.Type) reflect.Type {
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	return t
+}
+
+func exportedName(name string) string {
+	if name == "" {
+		return name
+	}
+	runes := []rune(name)
+	runes[0] = unicode.ToUpper(runes[0])
+	return string(runes)
+}
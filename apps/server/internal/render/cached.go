@@ -0,0 +1,59 @@
+package render
+
+import (
+	"context"
+	"encoding/json"
+	"strconv"
+	"time"
+
+	"github.com/lifei6671/plaindoc/apps/server/internal/cache"
+)
+
+// cacheVersion 是缓存键的一部分，修改渲染规则（扩展、消毒白名单、锚点生成）时需要递增，
+// 避免共享缓存中残留旧规则的渲染结果；可配置的消毒选项由 Renderer.Fingerprint 区分。
+const cacheVersion = "3"
+
+// Cached 按文档缓存渲染结果，键由文档 ID 与 updated_at 组成：文档更新后键随之变化，
+// 旧条目不会再被读取，由 TTL 或容量淘汰清理。cache 为 nil 时每次都直接渲染。
+type Cached struct {
+	renderer *Renderer
+	cache    cache.Cache
+	ttl      time.Duration
+}
+
+func NewCached(renderer *Renderer, c cache.Cache, ttl time.Duration) *Cached {
+	return &Cached{renderer: renderer, cache: c, ttl: ttl}
+}
+
+// RenderDocument 返回文档正文的渲染结果；缓存读写失败时直接渲染，不影响结果。
+func (c *Cached) RenderDocument(ctx context.Context, docID int64, updatedAt time.Time, source []byte) (*Result, error) {
+	return c.render(ctx, "doc", docID, updatedAt, source)
+}
+
+// RenderDraft 返回文档草稿的渲染结果，缓存键与已发布内容分开。
+func (c *Cached) RenderDraft(ctx context.Context, docID int64, updatedAt time.Time, source []byte) (*Result, error) {
+	return c.render(ctx, "draft", docID, updatedAt, source)
+}
+
+func (c *Cached) render(ctx context.Context, kind string, docID int64, updatedAt time.Time, source []byte) (*Result, error) {
+	if c.cache == nil {
+		return c.renderer.Render(source)
+	}
+	// 数据库中的时间精度为毫秒，刚写入的文档与重新读出的文档需要得到同一个键。
+	key := "render:v" + cacheVersion + ":" + c.renderer.Fingerprint() + ":" + kind + ":" + strconv.FormatInt(docID, 10) + ":" + strconv.FormatInt(updatedAt.UnixMilli(), 10)
+	if cached, ok, err := c.cache.Get(ctx, key); err == nil && ok {
+		result := &Result{}
+		if json.Unmarshal(cached, result) == nil {
+			return result, nil
+		}
+	}
+
+	result, err := c.renderer.Render(source)
+	if err != nil {
+		return nil, err
+	}
+	if encoded, err := json.Marshal(result); err == nil {
+		_ = c.cache.Set(ctx, key, encoded, c.ttl)
+	}
+	return result, nil
+}
@@ -0,0 +1,80 @@
+package render
+
+import (
+	"strings"
+	"unicode"
+
+	"github.com/yuin/goldmark/ast"
+	"github.com/yuin/goldmark/text"
+)
+
+// Excerpt 提取 Markdown 正文开头不超过 maxRunes 个字符的纯文本，用于订阅源等场景的摘要。
+// 强调、链接等标记只保留文字，代码块、图片与原始 HTML 整体跳过，连续的空白、换行与块之间合并为一个空格；
+// 截断时追加省略号。
+func (r *Renderer) Excerpt(source []byte, maxRunes int) string {
+	doc := r.markdown.Parser().Parse(text.NewReader(source))
+	excerpt := &excerptWriter{limit: maxRunes + 1}
+	_ = ast.Walk(doc, func(node ast.Node, entering bool) (ast.WalkStatus, error) {
+		if excerpt.full() {
+			return ast.WalkStop, nil
+		}
+		switch typed := node.(type) {
+		case *ast.FencedCodeBlock, *ast.CodeBlock, *ast.HTMLBlock, *ast.RawHTML, *ast.Image:
+			return ast.WalkSkipChildren, nil
+		case *ast.Text:
+			if entering {
+				excerpt.write(string(typed.Segment.Value(source)))
+				if typed.SoftLineBreak() || typed.HardLineBreak() {
+					excerpt.space = true
+				}
+			}
+		case *ast.String:
+			if entering {
+				excerpt.write(string(typed.Value))
+			}
+		default:
+			if node.Type() == ast.TypeBlock {
+				excerpt.space = true
+			}
+		}
+		return ast.WalkContinue, nil
+	})
+
+	if !excerpt.full() {
+		return excerpt.b.String()
+	}
+	runes := []rune(excerpt.b.String())
+	return strings.TrimSpace(string(runes[:maxRunes])) + "…"
+}
+
+// excerptWriter 写入纯文本并合并空白，最多保留 limit 个字符。
+type excerptWriter struct {
+	b     strings.Builder
+	runes int
+	limit int
+	// space 表示下一个可见字符前需要一个空格。
+	space bool
+}
+
+func (w *excerptWriter) full() bool {
+	return w.runes >= w.limit
+}
+
+func (w *excerptWriter) write(s string) {
+	for _, r := range s {
+		if w.full() {
+			return
+		}
+		if unicode.IsSpace(r) {
+			w.space = true
+			continue
+		}
+		if w.space && w.runes > 0 {
+			w.b.WriteByte(' ')
+			w.runes++
+		}
+		w.space = false
+		w.b.WriteRune(r)
+		w.runes++
+	}
+}
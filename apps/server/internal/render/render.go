@@ -0,0 +1,146 @@
+package render
+
+import (
+	"bytes"
+	"strconv"
+	"unicode"
+
+	chromahtml "github.com/alecthomas/chroma/v2/formatters/html"
+	"github.com/alecthomas/chroma/v2/styles"
+	"github.com/lifei6671/plaindoc/apps/server/internal/sanitize"
+	"github.com/lifei6671/plaindoc/apps/server/internal/wikilink"
+	"github.com/yuin/goldmark"
+	highlighting "github.com/yuin/goldmark-highlighting/v2"
+	"github.com/yuin/goldmark/ast"
+	"github.com/yuin/goldmark/extension"
+	"github.com/yuin/goldmark/parser"
+	"github.com/yuin/goldmark/renderer/html"
+	"github.com/yuin/goldmark/text"
+)
+
+// Heading 是目录中的一项；ID 与渲染后标题元素的 id 一致，可直接用作锚点。
+type Heading struct {
+	Level int    `json:"level"`
+	Text  string `json:"text"`
+	ID    string `json:"id"`
+}
+
+type Result struct {
+	HTML string    `json:"html"`
+	TOC  []Heading `json:"toc"`
+}
+
+// Renderer 把 Markdown 渲染为经过 sanitize 白名单消毒的 HTML，可并发使用。
+type Renderer struct {
+	markdown goldmark.Markdown
+	policy   *sanitize.Policy
+}
+
+func New(policy *sanitize.Policy) *Renderer {
+	markdown := goldmark.New(
+		goldmark.WithExtensions(
+			extension.GFM,
+			// 代码高亮只输出 chroma 的 class，配色由前端样式表决定，不需要放行 style 属性。
+			highlighting.NewHighlighting(highlighting.WithFormatOptions(chromahtml.WithClasses(true))),
+			// [[slug]] 内部链接渲染为不带地址的占位链接，读取文档时再由 wikilink.Resolve 填上地址。
+			wikilink.Extension,
+		),
+		goldmark.WithParserOptions(parser.WithAutoHeadingID()),
+		// 允许正文中的原始 HTML，安全性统一交给下面的白名单消毒。
+		goldmark.WithRendererOptions(html.WithUnsafe()),
+	)
+	return &Renderer{markdown: markdown, policy: policy}
+}
+
+// Fingerprint 标识渲染所用的消毒配置，作为渲染缓存键的一部分。
+func (r *Renderer) Fingerprint() string {
+	return r.policy.Fingerprint()
+}
+
+func (r *Renderer) Render(source []byte) (*Result, error) {
+	ctx := parser.NewContext(parser.WithIDs(&headingIDs{seen: map[string]bool{}}))
+	doc := r.markdown.Parser().Parse(text.NewReader(source), parser.WithContext(ctx))
+
+	var buf bytes.Buffer
+	if err := r.markdown.Renderer().Render(&buf, source, doc); err != nil {
+		return nil, err
+	}
+	return &Result{
+		HTML: r.policy.HTML(buf.String()),
+		TOC:  headings(doc, source),
+	}, nil
+}
+
+// HighlightCSS 返回代码高亮所用 chroma class 的样式表（github 配色）。
+func HighlightCSS() string {
+	var buf bytes.Buffer
+	_ = chromahtml.New(chromahtml.WithClasses(true)).WriteCSS(&buf, styles.Get("github"))
+	return buf.String()
+}
+
+func headings(doc ast.Node, source []byte) []Heading {
+	toc := []Heading{}
+	_ = ast.Walk(doc, func(node ast.Node, entering bool) (ast.WalkStatus, error) {
+		heading, ok := node.(*ast.Heading)
+		if !entering || !ok {
+			return ast.WalkContinue, nil
+		}
+		item := Heading{Level: heading.Level, Text: plainText(heading, source)}
+		if id, ok := heading.AttributeString("id"); ok {
+			if value, ok := id.([]byte); ok {
+				item.ID = string(value)
+			}
+		}
+		toc = append(toc, item)
+		return ast.WalkSkipChildren, nil
+	})
+	return toc
+}
+
+// plainText 拼接标题内的纯文本，忽略强调、链接等行内标记。
+func plainText(node ast.Node, source []byte) string {
+	var buf bytes.Buffer
+	for child := node.FirstChild(); child != nil; child = child.NextSibling() {
+		switch typed := child.(type) {
+		case *ast.Text:
+			buf.Write(typed.Segment.Value(source))
+		case *ast.String:
+			buf.Write(typed.Value)
+		default:
+			buf.WriteString(plainText(child, source))
+		}
+	}
+	return buf.String()
+}
+
+// headingIDs 生成标题锚点：保留中文等 Unicode 字母（goldmark 默认只保留 ASCII），重复时追加 -1、-2。
+type headingIDs struct {
+	seen map[string]bool
+}
+
+func (h *headingIDs) Generate(value []byte, kind ast.NodeKind) []byte {
+	var buf bytes.Buffer
+	for _, r := range string(bytes.TrimSpace(value)) {
+		switch {
+		case unicode.IsLetter(r) || unicode.IsDigit(r) || r == '_' || r == '-':
+			buf.WriteRune(unicode.ToLower(r))
+		case unicode.IsSpace(r):
+			buf.WriteByte('-')
+		}
+	}
+	base := buf.String()
+	if base == "" {
+		base = "heading"
+	}
+
+	id := base
+	for i := 1; h.seen[id]; i++ {
+		id = base + "-" + strconv.Itoa(i)
+	}
+	h.seen[id] = true
+	return []byte(id)
+}
+
+func (h *headingIDs) Put(value []byte) {
+	h.seen[string(value)] = true
+}
@@ -0,0 +1,79 @@
+// Package sanitize 集中定义用户内容（文档正文、评论）渲染为 HTML 后的消毒白名单，防止存储型 XSS。
+//
+// 白名单在 bluemonday 的 UGC policy 基础上调整：保留常见排版标签、图片、表格与代码块，
+// 只允许 http、https、mailto 与相对地址的链接；script、style、iframe、表单与全部事件属性都会被移除。
+// 链接一律加 rel="nofollow"，站外链接同时在新窗口打开并加 noopener；配置了可信域名时，
+// 仅允许 src 指向这些域名的 https iframe，并强制带有 sandbox：作者写的 sandbox 只保留白名单中的取值，未写时加上空的 sandbox。
+package sanitize
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"regexp"
+	"slices"
+	"strings"
+
+	"github.com/microcosm-cc/bluemonday"
+)
+
+// Options 配置白名单中可调整的部分。
+type Options struct {
+	// IframeHosts 是允许嵌入 iframe 的域名，如 www.youtube.com、player.bilibili.com；为空时不允许任何 iframe。
+	IframeHosts []string
+}
+
+// Policy 是可并发使用的消毒策略。
+type Policy struct {
+	policy      *bluemonday.Policy
+	fingerprint string
+}
+
+func New(opts Options) *Policy {
+	policy := bluemonday.UGCPolicy()
+	policy.AllowURLSchemes("http", "https", "mailto")
+	policy.RequireNoFollowOnLinks(true)
+	policy.AddTargetBlankToFullyQualifiedLinks(true)
+	// 代码高亮输出 chroma 的 class，配色由前端样式表决定，不需要放行 style 属性。
+	policy.AllowAttrs("class").Matching(regexp.MustCompile(`^[\w\- ]+$`)).OnElements("pre", "code", "span", "div")
+	policy.AllowAttrs("id").Matching(regexp.MustCompile(`^[\p{L}\p{N}_\-]+$`)).OnElements("h1", "h2", "h3", "h4", "h5", "h6")
+	// [[slug]] 内部链接的占位元素，地址在读取时由 wikilink.Resolve 填入。
+	policy.AllowAttrs("class").Matching(regexp.MustCompile(`^wikilink$`)).OnElements("a")
+	policy.AllowAttrs("data-slug").Matching(regexp.MustCompile(`^[a-z0-9]+(?:[-_][a-z0-9]+)*$`)).OnElements("a")
+	// GFM 任务列表渲染为只读的 checkbox。
+	policy.AllowAttrs("type").Matching(regexp.MustCompile(`^checkbox$`)).OnElements("input")
+	policy.AllowAttrs("checked", "disabled").OnElements("input")
+
+	hosts := make([]string, 0, len(opts.IframeHosts))
+	for _, host := range opts.IframeHosts {
+		hosts = append(hosts, regexp.QuoteMeta(strings.ToLower(host)))
+	}
+	if len(hosts) > 0 {
+		policy.AllowAttrs("src").Matching(regexp.MustCompile(`^https://(?:` + strings.Join(hosts, "|") + `)/`)).OnElements("iframe")
+		policy.AllowAttrs("width", "height").Matching(bluemonday.NumberOrPercent).OnElements("iframe")
+		policy.AllowAttrs("allowfullscreen", "sandbox").OnElements("iframe")
+		policy.RequireSandboxOnIFrame(bluemonday.SandboxAllowScripts, bluemonday.SandboxAllowSameOrigin,
+			bluemonday.SandboxAllowPopups, bluemonday.SandboxAllowPresentation)
+	}
+
+	return &Policy{policy: policy, fingerprint: fingerprint(opts)}
+}
+
+// HTML 按白名单清理 html。
+func (p *Policy) HTML(html string) string {
+	return p.policy.Sanitize(html)
+}
+
+// Fingerprint 标识可调整部分的配置，配置不同的实例得到不同的值，用于区分共享缓存中的渲染结果。
+func (p *Policy) Fingerprint() string {
+	return p.fingerprint
+}
+
+func fingerprint(opts Options) string {
+	hosts := slices.Clone(opts.IframeHosts)
+	for i := range hosts {
+		hosts[i] = strings.ToLower(hosts[i])
+	}
+	slices.Sort(hosts)
+	sum := sha256.Sum256([]byte(strings.Join(hosts, ",")))
+	return hex.EncodeToString(sum[:4])
+}
@@ -0,0 +1,180 @@
+package sanitize
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestHTML(t *testing.T) {
+	policy := New(Options{})
+	tests := []struct {
+		name   string
+		html   string
+		want   []string
+		reject []string
+	}{
+		{
+			name:   "script removed",
+			html:   `<p>hi</p><script>alert(1)</script>`,
+			want:   []string{"<p>hi</p>"},
+			reject: []string{"<script", "alert"},
+		},
+		{
+			name:   "event handler removed",
+			html:   `<img src="/uploads/a.png" onerror="alert(1)">`,
+			want:   []string{`src="/uploads/a.png"`},
+			reject: []string{"onerror"},
+		},
+		{
+			name:   "javascript link removed",
+			html:   `<a href="javascript:alert(1)">x</a>`,
+			reject: []string{"javascript:"},
+		},
+		{
+			name:   "data link removed",
+			html:   `<a href="data:text/html;base64,PHNjcmlwdD4=">x</a>`,
+			reject: []string{"data:"},
+		},
+		{
+			name: "external link gets rel and target",
+			html: `<a href="https://example.com/">x</a>`,
+			want: []string{`href="https://example.com/"`, `nofollow`, `noopener`, `target="_blank"`},
+		},
+		{
+			name:   "relative link not opened in new window",
+			html:   `<a href="/docs/intro">x</a>`,
+			want:   []string{`href="/docs/intro"`, `nofollow`},
+			reject: []string{`target=`},
+		},
+		{
+			name: "mailto link kept",
+			html: `<a href="mailto:a@example.com">mail</a>`,
+			want: []string{`href="mailto:a@example.com"`},
+		},
+		{
+			name:   "style and form removed",
+			html:   `<style>p{}</style><form action="/x"><input type="text" name="q"></form><p style="color:red">t</p>`,
+			want:   []string{"<p>t</p>"},
+			reject: []string{"<style", "<form", `type="text"`, "style="},
+		},
+		{
+			name: "code highlight classes kept",
+			html: `<pre class="chroma"><code class="language-go"><span class="kd">func</span></code></pre>`,
+			want: []string{`<pre class="chroma">`, `<code class="language-go">`, `<span class="kd">`},
+		},
+		{
+			name:   "unsafe class value removed",
+			html:   `<span class="a&quot;onclick=x">t</span>`,
+			reject: []string{"onclick"},
+		},
+		{
+			name: "heading anchor kept",
+			html: `<h2 id="安装-步骤">安装</h2>`,
+			want: []string{`<h2 id="安装-步骤">`},
+		},
+		{
+			name: "wikilink placeholder kept",
+			html: `<a class="wikilink" data-slug="getting-started">start</a>`,
+			want: []string{`class="wikilink"`, `data-slug="getting-started"`},
+		},
+		{
+			name:   "invalid wikilink slug removed",
+			html:   `<a class="wikilink" data-slug="Bad Slug">start</a>`,
+			reject: []string{"data-slug"},
+		},
+		{
+			name: "task list checkbox kept",
+			html: `<li><input checked="" disabled="" type="checkbox"> done</li>`,
+			want: []string{`type="checkbox"`, `checked`, `disabled`},
+		},
+		{
+			name:   "iframe removed without allowed hosts",
+			html:   `<iframe src="https://www.youtube.com/embed/x"></iframe>`,
+			reject: []string{"<iframe"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			checkHTML(t, policy, tt.html, tt.want, tt.reject)
+		})
+	}
+}
+
+func TestHTMLIframeHosts(t *testing.T) {
+	policy := New(Options{IframeHosts: []string{"www.YouTube.com"}})
+	tests := []struct {
+		name   string
+		html   string
+		want   []string
+		reject []string
+	}{
+		{
+			name: "allowed host gets empty sandbox",
+			html: `<iframe src="https://www.youtube.com/embed/x" width="560" height="315" allowfullscreen></iframe>`,
+			want: []string{`src="https://www.youtube.com/embed/x"`, `width="560"`, `sandbox=""`},
+		},
+		{
+			name:   "sandbox keeps only allowed values",
+			html:   `<iframe src="https://www.youtube.com/embed/x" sandbox="allow-scripts allow-top-navigation"></iframe>`,
+			want:   []string{"allow-scripts"},
+			reject: []string{"allow-top-navigation"},
+		},
+		{
+			name:   "other host removed",
+			html:   `<iframe src="https://evil.example/embed/x"></iframe>`,
+			reject: []string{"evil.example"},
+		},
+		{
+			name:   "plain http removed",
+			html:   `<iframe src="http://www.youtube.com/embed/x"></iframe>`,
+			reject: []string{"http://www.youtube.com"},
+		},
+		{
+			name:   "host suffix removed",
+			html:   `<iframe src="https://www.youtube.com.evil.example/x"></iframe>`,
+			reject: []string{"evil.example"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			checkHTML(t, policy, tt.html, tt.want, tt.reject)
+		})
+	}
+}
+
+func TestFingerprint(t *testing.T) {
+	tests := []struct {
+		name string
+		a, b []string
+		same bool
+	}{
+		{"empty", nil, []string{}, true},
+		{"order and case ignored", []string{"a.example", "B.example"}, []string{"b.example", "A.example"}, true},
+		{"different hosts", []string{"a.example"}, []string{"b.example"}, false},
+		{"subset", []string{"a.example"}, []string{"a.example", "b.example"}, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			a, b := New(Options{IframeHosts: tt.a}).Fingerprint(), New(Options{IframeHosts: tt.b}).Fingerprint()
+			if (a == b) != tt.same {
+				t.Errorf("Fingerprint(%v) = %s, Fingerprint(%v) = %s, want same = %v", tt.a, a, tt.b, b, tt.same)
+			}
+		})
+	}
+}
+
+// checkHTML 断言 want 中的片段都出现在消毒结果中，reject 中的片段都不出现。
+func checkHTML(t *testing.T, policy *Policy, html string, want, reject []string) {
+	t.Helper()
+	got := policy.HTML(html)
+	for _, w := range want {
+		if !strings.Contains(got, w) {
+			t.Errorf("HTML(%q) = %q, want it to contain %q", html, got, w)
+		}
+	}
+	for _, r := range reject {
+		if strings.Contains(got, r) {
+			t.Errorf("HTML(%q) = %q, want it not to contain %q", html, got, r)
+		}
+	}
+}
@@ -0,0 +1,54 @@
+package search
+
+import (
+	"context"
+
+	"github.com/lifei6671/plaindoc/apps/server/internal/store"
+)
+
+// DBIndexer 直接在 docs 表上做 LIKE 检索，数据即索引，Index/Remove 无需任何操作。
+type DBIndexer struct {
+	store *store.Store
+}
+
+func NewDBIndexer(s *store.Store) *DBIndexer {
+	return &DBIndexer{store: s}
+}
+
+func (i *DBIndexer) Index(ctx context.Context, doc Document) error {
+	return nil
+}
+
+func (i *DBIndexer) Remove(ctx context.Context, docID int64) error {
+	return nil
+}
+
+func (i *DBIndexer) Search(ctx context.Context, query Query) (*Result, error) {
+	terms := Tokenize(query.Text)
+	docs, total, err := i.store.SearchDocuments(ctx, store.SearchFilter{
+		Terms:       terms,
+		Space:       query.Space,
+		Viewer:      store.Viewer{ID: query.ViewerID, Role: query.ViewerRole},
+		ByRelevance: query.Sort == "" || query.Sort == SortRelevance,
+		Ascending:   query.Sort == SortUpdatedAtAsc,
+		Limit:       query.Limit,
+		Offset:      query.Offset,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	result := &Result{Hits: make([]Hit, 0, len(docs)), Total: total}
+	for _, doc := range docs {
+		result.Hits = append(result.Hits, Hit{
+			ID:        doc.ID,
+			Space:     doc.Space,
+			Slug:      doc.Slug,
+			Title:     Highlight(doc.Title, terms),
+			Snippet:   Snippet(doc.Content, terms),
+			AuthorID:  doc.AuthorID,
+			UpdatedAt: doc.UpdatedAt,
+		})
+	}
+	return result, nil
+}
@@ -0,0 +1,104 @@
+package search
+
+import (
+	"html"
+	"strings"
+	"unicode"
+)
+
+const (
+	snippetLength = 120
+	// snippetLead 是首个命中位置之前保留的上下文字符数。
+	snippetLead = 30
+)
+
+// Highlight 对整段文本做 HTML 转义并用 <mark> 包裹全部命中词，适用于标题。
+func Highlight(text string, terms []string) string {
+	runes := []rune(text)
+	return mark(runes, matchRanges(runes, terms), 0, len(runes))
+}
+
+// Snippet 截取首个命中位置附近的上下文并高亮；无正文命中时返回开头部分。
+func Snippet(text string, terms []string) string {
+	runes := []rune(strings.Join(strings.Fields(text), " "))
+	ranges := matchRanges(runes, terms)
+
+	start := 0
+	if len(ranges) > 0 {
+		start = max(ranges[0][0]-snippetLead, 0)
+	}
+	end := min(start+snippetLength, len(runes))
+
+	snippet := mark(runes, ranges, start, end)
+	if start > 0 {
+		snippet = "…" + snippet
+	}
+	if end < len(runes) {
+		snippet += "…"
+	}
+	return snippet
+}
+
+// matchRanges 返回按起点排序且已合并重叠的命中区间（以 rune 为单位，左闭右开）。
+func matchRanges(runes []rune, terms []string) [][2]int {
+	lower := make([]rune, len(runes))
+	for i, r := range runes {
+		lower[i] = unicode.ToLower(r)
+	}
+
+	covered := make([]bool, len(runes))
+	for _, term := range terms {
+		pattern := []rune(term)
+		if len(pattern) == 0 {
+			continue
+		}
+		for i := 0; i+len(pattern) <= len(lower); i++ {
+			if equalRunes(lower[i:i+len(pattern)], pattern) {
+				for j := i; j < i+len(pattern); j++ {
+					covered[j] = true
+				}
+			}
+		}
+	}
+
+	var ranges [][2]int
+	for i := 0; i < len(covered); i++ {
+		if !covered[i] {
+			continue
+		}
+		j := i
+		for j < len(covered) && covered[j] {
+			j++
+		}
+		ranges = append(ranges, [2]int{i, j})
+		i = j
+	}
+	return ranges
+}
+
+func mark(runes []rune, ranges [][2]int, start int, end int) string {
+	var b strings.Builder
+	pos := start
+	for _, r := range ranges {
+		from, to := max(r[0], start), min(r[1], end)
+		if from >= to {
+			continue
+		}
+		b.WriteString(html.EscapeString(string(runes[pos:from])))
+		b.WriteString("<mark>")
+		b.WriteString(html.EscapeString(string(runes[from:to])))
+		b.WriteString("</mark>")
+		pos = to
+	}
+	b.WriteString(html.EscapeString(string(runes[pos:end])))
+	return b.String()
+}
+
+func equalRunes(a []rune, b []rune) bool {
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,74 @@
+package search
+
+import (
+	"context"
+	"time"
+
+	"github.com/lifei6671/plaindoc/apps/server/internal/store"
+)
+
+const (
+	SortRelevance     = "relevance"
+	SortUpdatedAtDesc = "-updated_at"
+	SortUpdatedAtAsc  = "updated_at"
+)
+
+// Indexer 抽象全文检索后端。当前实现直接查询数据库；
+// 替换为 bleve、Elasticsearch 等外部索引时，文档写入后通过 Index/Remove 同步索引。
+type Indexer interface {
+	Index(ctx context.Context, doc Document) error
+	Remove(ctx context.Context, docID int64) error
+	Search(ctx context.Context, query Query) (*Result, error)
+}
+
+// Document 是写入索引所需的文档字段。
+type Document struct {
+	ID        int64
+	Space     string
+	Slug      string
+	Title     string
+	Content   string
+	AuthorID  int64
+	UpdatedAt time.Time
+}
+
+// NewDocument 取出文档中需要写入索引的字段。
+func NewDocument(doc *store.Document) Document {
+	return Document{
+		ID:        doc.ID,
+		Space:     doc.Space,
+		Slug:      doc.Slug,
+		Title:     doc.Title,
+		Content:   doc.Content,
+		AuthorID:  doc.AuthorID,
+		UpdatedAt: doc.UpdatedAt,
+	}
+}
+
+// Query 描述一次检索请求；ViewerID/ViewerRole 供后端过滤当前用户无权查看的文档。
+type Query struct {
+	Text string
+	// Space 非空时只检索该空间内的文档。
+	Space      string
+	Sort       string
+	Limit      int
+	Offset     int
+	ViewerID   int64
+	ViewerRole string
+}
+
+// Hit 是一条命中结果，Title 与 Snippet 已做 HTML 转义，关键词以 <mark> 包裹。
+type Hit struct {
+	ID        int64     `json:"id"`
+	Space     string    `json:"space"`
+	Slug      string    `json:"slug"`
+	Title     string    `json:"title"`
+	Snippet   string    `json:"snippet"`
+	AuthorID  int64     `json:"author_id"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+type Result struct {
+	Hits  []Hit
+	Total int
+}
@@ -0,0 +1,61 @@
+package search
+
+import (
+	"strings"
+	"unicode"
+)
+
+// maxTerms 限制单次查询的词数，避免超长输入生成过多 LIKE 条件。
+const maxTerms = 16
+
+// Tokenize 把查询串切分为小写检索词：字母数字按非字符边界切分；
+// 中日韩文字没有空格分隔，连续片段按二元组（bigram）切分，单字片段保留原字。
+// 例如 "全文搜索 API" 得到 ["全文", "文搜", "搜索", "api"]。
+func Tokenize(text string) []string {
+	var (
+		terms []string
+		seen  = map[string]bool{}
+		word  []rune
+		cjk   []rune
+	)
+	add := func(term string) {
+		if term != "" && !seen[term] && len(terms) < maxTerms {
+			seen[term] = true
+			terms = append(terms, term)
+		}
+	}
+	flushWord := func() {
+		add(string(word))
+		word = word[:0]
+	}
+	flushCJK := func() {
+		if len(cjk) == 1 {
+			add(string(cjk))
+		}
+		for i := 0; i+1 < len(cjk); i++ {
+			add(string(cjk[i : i+2]))
+		}
+		cjk = cjk[:0]
+	}
+
+	for _, r := range strings.ToLower(text) {
+		switch {
+		case isCJK(r):
+			flushWord()
+			cjk = append(cjk, r)
+		case unicode.IsLetter(r) || unicode.IsDigit(r):
+			flushCJK()
+			word = append(word, r)
+		default:
+			flushWord()
+			flushCJK()
+		}
+	}
+	flushWord()
+	flushCJK()
+	return terms
+}
+
+func isCJK(r rune) bool {
+	return unicode.In(r, unicode.Han, unicode.Hiragana, unicode.Katakana, unicode.Hangul)
+}
@@ -0,0 +1,29 @@
+package handler
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/lifei6671/plaindoc/apps/server/internal/feed"
+	"github.com/lifei6671/plaindoc/apps/server/internal/server/httpx"
+	"github.com/lifei6671/plaindoc/apps/server/internal/store"
+)
+
+// Feed 返回最近更新的公开文档的 Atom 订阅源，支持 space 与 tag 过滤。
+func Feed(feeds *feed.Service, ttl time.Duration) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		body, err := feeds.Atom(c.Request.Context(), feed.Query{
+			Space: strings.TrimSpace(c.Query("space")),
+			Tag:   store.NormalizeTagName(c.Query("tag")),
+		})
+		if err != nil {
+			httpx.AbortInternal(c, err)
+			return
+		}
+		c.Header("Cache-Control", "public, max-age="+strconv.Itoa(int(ttl.Seconds())))
+		c.Data(http.StatusOK, feed.ContentType, body)
+	}
+}
@@ -1,9 +1,54 @@
 package handler
 
-import "github.com/gin-gonic/gin"
+import (
+	"context"
+	"database/sql"
+	"net/http"
+	"time"
 
-func Health(c *gin.Context) {
-	c.JSON(200, gin.H{
-		"ok": true,
+	"github.com/gin-gonic/gin"
+	"github.com/lifei6671/plaindoc/apps/server/internal/health"
+)
+
+const healthCheckTimeout = 2 * time.Second
+
+// Health 探测数据库连通性：任一依赖不可用时返回 503，便于探针及时摘除流量。
+func Health(db *sql.DB) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		ctx, cancel := context.WithTimeout(c.Request.Context(), healthCheckTimeout)
+		defer cancel()
+
+		if err := db.PingContext(ctx); err != nil {
+			c.JSON(http.StatusServiceUnavailable, gin.H{
+				"status": "unhealthy",
+				"db":     "down",
+			})
+			return
+		}
+
+		c.JSON(http.StatusOK, gin.H{
+			"status": "ok",
+			"db":     "up",
+		})
+	}
+}
+
+// Livez 只表示进程仍能处理请求，不检查任何外部依赖。
+func Livez(c *gin.Context) {
+	c.JSON(http.StatusOK, gin.H{
+		"status": "ok",
 	})
 }
+
+// Readyz 并行执行所有已注册的健康检查，逐项返回各依赖的状态与耗时；关键依赖未就绪时返回 503 并在 failing 中列出，
+// 只有可选依赖失败时整体状态为 degraded，仍返回 200。
+func Readyz(checks *health.Registry) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		report := checks.Run(c.Request.Context())
+		status := http.StatusOK
+		if !report.Ready() {
+			status = http.StatusServiceUnavailable
+		}
+		c.JSON(status, report)
+	}
+}
@@ -0,0 +1,54 @@
+package handler
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/lifei6671/plaindoc/apps/server/internal/server/httpx"
+	"github.com/lifei6671/plaindoc/apps/server/internal/sitemap"
+)
+
+const sitemapContentType = "application/xml; charset=utf-8"
+
+// Sitemap 返回 /sitemap.xml：文档不多时是 urlset，超过单个文件上限时是指向分页的 sitemapindex。
+func Sitemap(sitemaps *sitemap.Service, ttl time.Duration) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		body, builtAt, err := sitemaps.Root(c.Request.Context())
+		if err != nil {
+			httpx.AbortInternal(c, err)
+			return
+		}
+		writeSitemap(c, body, builtAt, ttl)
+	}
+}
+
+// SitemapPage 返回 /sitemaps/<n>.xml 分页，不存在的分页返回 404。
+func SitemapPage(sitemaps *sitemap.Service, ttl time.Duration) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		n, err := strconv.Atoi(strings.TrimSuffix(c.Param("name"), ".xml"))
+		if err != nil || !strings.HasSuffix(c.Param("name"), ".xml") {
+			c.Status(http.StatusNotFound)
+			return
+		}
+		body, builtAt, ok, err := sitemaps.Page(c.Request.Context(), n)
+		if err != nil {
+			httpx.AbortInternal(c, err)
+			return
+		}
+		if !ok {
+			c.Status(http.StatusNotFound)
+			return
+		}
+		writeSitemap(c, body, builtAt, ttl)
+	}
+}
+
+// writeSitemap 允许爬虫与 CDN 在服务端缓存有效期内复用结果。
+func writeSitemap(c *gin.Context, body []byte, builtAt time.Time, ttl time.Duration) {
+	c.Header("Cache-Control", "public, max-age="+strconv.Itoa(int(ttl.Seconds())))
+	c.Header("Last-Modified", builtAt.UTC().Format(http.TimeFormat))
+	c.Data(http.StatusOK, sitemapContentType, body)
+}
@@ -0,0 +1,93 @@
+package v1
+
+import (
+	"errors"
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/lifei6671/plaindoc/apps/server/internal/auth"
+	"github.com/lifei6671/plaindoc/apps/server/internal/i18n"
+	"github.com/lifei6671/plaindoc/apps/server/internal/server/httpx"
+	"github.com/lifei6671/plaindoc/apps/server/internal/store"
+)
+
+// APIKey 管理当前用户供脚本与 CI 使用的 API Key。
+type APIKey struct {
+	store *store.Store
+}
+
+func NewAPIKey(s *store.Store) *APIKey {
+	return &APIKey{store: s}
+}
+
+type createAPIKeyRequest struct {
+	Name string `json:"name" binding:"required,max=64"`
+	// Scope 为 read（只读）或 write（与用户权限相同，默认）。
+	Scope string `json:"scope" binding:"omitempty,oneof=read write"`
+}
+
+type createdAPIKey struct {
+	store.APIKey
+	// Key 是明文 key，只在创建时返回一次。
+	Key string `json:"key"`
+}
+
+// Create 为当前用户创建 API Key，响应中的明文 key 之后无法再次查看。
+func (h *APIKey) Create(c *gin.Context) {
+	var req createAPIKeyRequest
+	if !httpx.BindAndValidate(c, &req) {
+		return
+	}
+	name := strings.TrimSpace(req.Name)
+	if name == "" {
+		httpx.Abort(c, http.StatusBadRequest, i18n.FieldBlank, "name")
+		return
+	}
+	if req.Scope == "" {
+		req.Scope = auth.APIKeyScopeWrite
+	}
+
+	key, hash, err := auth.NewAPIKey()
+	if err != nil {
+		httpx.AbortInternal(c, err)
+		return
+	}
+	user, _ := httpx.CurrentUser(c)
+	apiKey := store.APIKey{UserID: user.ID, Name: name, Prefix: key[:auth.APIKeyDisplayLength], Hash: hash, Scope: req.Scope}
+	if err := h.store.CreateAPIKey(c.Request.Context(), &apiKey); err != nil {
+		httpx.AbortInternal(c, err)
+		return
+	}
+	c.JSON(http.StatusCreated, createdAPIKey{APIKey: apiKey, Key: key})
+}
+
+// List 列出当前用户的全部 API Key，不含明文。
+func (h *APIKey) List(c *gin.Context) {
+	user, _ := httpx.CurrentUser(c)
+	keys, err := h.store.ListAPIKeys(c.Request.Context(), user.ID)
+	if err != nil {
+		httpx.AbortInternal(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"items": keys})
+}
+
+// Delete 吊销当前用户的一个 API Key，立即失效。
+func (h *APIKey) Delete(c *gin.Context) {
+	id, ok := httpx.ParamID(c, "id")
+	if !ok {
+		return
+	}
+	user, _ := httpx.CurrentUser(c)
+	err := h.store.DeleteAPIKey(c.Request.Context(), user.ID, id)
+	if errors.Is(err, store.ErrNotFound) {
+		httpx.Abort(c, http.StatusNotFound, i18n.APIKeyNotFound)
+		return
+	}
+	if err != nil {
+		httpx.AbortInternal(c, err)
+		return
+	}
+	c.Status(http.StatusNoContent)
+}
@@ -0,0 +1,386 @@
+package v1
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/lifei6671/plaindoc/apps/server/internal/auth"
+	"github.com/lifei6671/plaindoc/apps/server/internal/config"
+	"github.com/lifei6671/plaindoc/apps/server/internal/i18n"
+	"github.com/lifei6671/plaindoc/apps/server/internal/mailer"
+	"github.com/lifei6671/plaindoc/apps/server/internal/server/httpx"
+	"github.com/lifei6671/plaindoc/apps/server/internal/settings"
+	"github.com/lifei6671/plaindoc/apps/server/internal/store"
+)
+
+// Auth 处理注册、登录与当前会话相关接口。
+type Auth struct {
+	store        *store.Store
+	settings     *settings.Service
+	secret       string
+	accessTTL    time.Duration
+	refreshTTL   time.Duration
+	secureCookie bool
+	mailer       mailer.Mailer
+	publicURL    string
+	resetTTL     time.Duration
+	// requireVerification 为 true 时新用户需要验证邮箱后才能登录。
+	requireVerification bool
+	passwordPolicy      auth.PasswordPolicy
+	// maxLoginFailures 与 maxIPLoginFailures 是账号与 IP 连续登录失败的上限，达到后锁定 loginLockDuration；0 表示不限制。
+	maxLoginFailures   int
+	maxIPLoginFailures int
+	loginLockDuration  time.Duration
+}
+
+// refreshCookiePath 限定 refresh token cookie 只随鉴权相关请求发送。
+const refreshCookiePath = Prefix + "/auth"
+
+// errRegistrationDisabled 表示管理员已关闭新用户注册。
+var errRegistrationDisabled = errors.New("registration is disabled")
+
+func NewAuth(cfg config.Config, s *store.Store, settingsService *settings.Service, m mailer.Mailer) *Auth {
+	return &Auth{
+		store:        s,
+		settings:     settingsService,
+		secret:       cfg.JWTSecret,
+		accessTTL:    cfg.AccessTokenTTL,
+		refreshTTL:   cfg.RefreshTokenTTL,
+		secureCookie: cfg.Env == "production",
+		mailer:       m,
+		publicURL:    cfg.PublicURL,
+		resetTTL:     cfg.PasswordResetTTL,
+
+		requireVerification: cfg.RequireEmailVerification,
+		passwordPolicy: auth.PasswordPolicy{
+			MinLength:     cfg.PasswordMinLength,
+			RequireUpper:  cfg.PasswordRequireUpper,
+			RequireLower:  cfg.PasswordRequireLower,
+			RequireDigit:  cfg.PasswordRequireDigit,
+			RequireSymbol: cfg.PasswordRequireSymbol,
+			RejectNumeric: cfg.PasswordRejectNumeric,
+			RejectCommon:  cfg.PasswordRejectCommon,
+		},
+		maxLoginFailures:   cfg.LoginMaxFailures,
+		maxIPLoginFailures: cfg.LoginIPMaxFailures,
+		loginLockDuration:  cfg.LoginLockDuration,
+	}
+}
+
+type registerRequest struct {
+	Email    string `json:"email" binding:"required,email,max=191"`
+	Password string `json:"password" binding:"required,max=72"`
+	Name     string `json:"name" binding:"required,max=64"`
+}
+
+type loginRequest struct {
+	Email    string `json:"email" binding:"required"`
+	Password string `json:"password" binding:"required"`
+}
+
+type refreshRequest struct {
+	RefreshToken string `json:"refresh_token"`
+}
+
+type sessionResponse struct {
+	User         *store.User `json:"user"`
+	AccessToken  string      `json:"access_token,omitempty"`
+	ExpiresAt    *time.Time  `json:"expires_at,omitempty"`
+	RefreshToken string      `json:"refresh_token,omitempty"`
+	// RecoveryCodes 只在通过 setup 中间 token 完成两步验证绑定时返回。
+	RecoveryCodes []string `json:"recovery_codes,omitempty"`
+}
+
+func (h *Auth) Register(c *gin.Context) {
+	var req registerRequest
+	if !httpx.BindAndValidate(c, &req) {
+		return
+	}
+	if !h.checkPassword(c, req.Password) {
+		return
+	}
+
+	role, err := h.registrationRole(c.Request.Context())
+	if errors.Is(err, errRegistrationDisabled) {
+		httpx.Abort(c, http.StatusForbidden, i18n.RegistrationDisabled)
+		return
+	}
+	if err != nil {
+		httpx.AbortInternal(c, err)
+		return
+	}
+	hash, err := auth.HashPassword(req.Password)
+	if err != nil {
+		httpx.AbortInternal(c, err)
+		return
+	}
+
+	user := &store.User{
+		Email:        req.Email,
+		Name:         req.Name,
+		Role:         role,
+		PasswordHash: hash,
+	}
+	if !h.requireVerification {
+		now := time.Now().UTC()
+		user.EmailVerifiedAt = &now
+	}
+	if err := h.store.CreateUser(c.Request.Context(), user); err != nil {
+		if errors.Is(err, store.ErrDuplicate) {
+			httpx.Abort(c, http.StatusConflict, i18n.EmailTaken)
+			return
+		}
+		httpx.AbortInternal(c, err)
+		return
+	}
+	if h.emailUnverified(user) {
+		if err := h.sendVerification(c, user); err != nil {
+			_ = c.Error(err)
+		}
+		c.JSON(http.StatusCreated, pendingVerificationResponse{User: user, EmailVerification: emailVerificationPending})
+		return
+	}
+
+	h.completeLogin(c, http.StatusCreated, user)
+}
+
+// registrationRole 返回新注册用户的角色；系统配置关闭注册时返回 errRegistrationDisabled。
+func (h *Auth) registrationRole(ctx context.Context) (string, error) {
+	snapshot, err := h.settings.Get(ctx)
+	if err != nil {
+		return "", err
+	}
+	if !snapshot.Bool(settings.AllowRegistration) {
+		return "", errRegistrationDisabled
+	}
+	return snapshot.String(settings.DefaultUserRole), nil
+}
+
+func (h *Auth) Login(c *gin.Context) {
+	var req loginRequest
+	if !httpx.BindAndValidate(c, &req) {
+		return
+	}
+
+	if h.loginLocked(c, req.Email) {
+		return
+	}
+	user, err := h.store.GetUserByEmail(c.Request.Context(), req.Email)
+	if err != nil && !errors.Is(err, store.ErrNotFound) {
+		httpx.AbortInternal(c, err)
+		return
+	}
+	// 邮箱不存在时也按空哈希做一次比对，CheckPassword 会改用固定的哈希，耗时与密码错误相同。
+	passwordHash := ""
+	if user != nil {
+		passwordHash = user.PasswordHash
+	}
+	if !auth.CheckPassword(passwordHash, req.Password) || user == nil {
+		h.recordLoginFailure(c, req.Email, i18n.InvalidCredentials)
+		return
+	}
+	// 开启了两步验证时，密码正确还不算登录成功，计数留到验证码通过后再清零，否则知道密码就能无限次尝试验证码。
+	if !user.TwoFactorEnabled {
+		h.clearLoginFailures(c, req.Email)
+	}
+	if h.emailUnverified(user) {
+		httpx.Abort(c, http.StatusForbidden, i18n.EmailNotVerified)
+		return
+	}
+
+	h.completeLogin(c, http.StatusOK, user)
+}
+
+// Refresh 用 refresh token 换取新的 access token，并轮换 refresh token。
+// 优先读取 HttpOnly cookie；非浏览器客户端可在请求体中传 refresh_token，此时新令牌也通过响应体返回。
+func (h *Auth) Refresh(c *gin.Context) {
+	token, fromBody := h.refreshTokenFromRequest(c)
+	if token == "" {
+		httpx.Abort(c, http.StatusUnauthorized, i18n.RefreshTokenRequired)
+		return
+	}
+
+	ctx := c.Request.Context()
+	consumed, err := h.store.ConsumeRefreshToken(ctx, auth.HashToken(token), store.RevokeRotated)
+	switch {
+	case errors.Is(err, store.ErrRefreshTokenReused):
+		h.clearCookies(c)
+		httpx.Abort(c, http.StatusUnauthorized, i18n.RefreshTokenReused)
+		return
+	case errors.Is(err, store.ErrRefreshTokenExpired):
+		h.clearCookies(c)
+		httpx.Abort(c, http.StatusUnauthorized, i18n.RefreshTokenExpired)
+		return
+	case errors.Is(err, store.ErrNotFound):
+		h.clearCookies(c)
+		httpx.Abort(c, http.StatusUnauthorized, i18n.InvalidRefreshToken)
+		return
+	case err != nil:
+		httpx.AbortInternal(c, err)
+		return
+	}
+
+	user, err := h.store.GetUser(ctx, consumed.UserID)
+	if errors.Is(err, store.ErrNotFound) {
+		h.clearCookies(c)
+		httpx.Abort(c, http.StatusUnauthorized, i18n.RefreshTokenUserGone)
+		return
+	}
+	if err != nil {
+		httpx.AbortInternal(c, err)
+		return
+	}
+
+	resp, refreshToken, err := h.resumeSession(c, user, consumed.SessionID)
+	if errors.Is(err, store.ErrNotFound) {
+		// 令牌被认领后、新令牌写入前会话恰好被撤销。
+		h.clearCookies(c)
+		httpx.Abort(c, http.StatusUnauthorized, i18n.InvalidRefreshToken)
+		return
+	}
+	if err != nil {
+		httpx.AbortInternal(c, err)
+		return
+	}
+	if fromBody {
+		resp.RefreshToken = refreshToken
+	}
+	c.JSON(http.StatusOK, resp)
+}
+
+func (h *Auth) Logout(c *gin.Context) {
+	if token, _ := h.refreshTokenFromRequest(c); token != "" {
+		ctx := c.Request.Context()
+		consumed, err := h.store.ConsumeRefreshToken(ctx, auth.HashToken(token), store.RevokeLogout)
+		if err != nil && !errors.Is(err, store.ErrNotFound) {
+			_ = c.Error(err)
+		}
+		if consumed != nil && consumed.SessionID != nil {
+			if err := h.store.RevokeSession(ctx, consumed.UserID, *consumed.SessionID); err != nil && !errors.Is(err, store.ErrNotFound) {
+				_ = c.Error(err)
+			}
+		}
+	}
+	h.clearCookies(c)
+	c.Status(http.StatusNoContent)
+}
+
+func (h *Auth) Me(c *gin.Context) {
+	current, _ := httpx.CurrentUser(c)
+	user, err := h.store.GetUser(c.Request.Context(), current.ID)
+	if errors.Is(err, store.ErrNotFound) {
+		httpx.Abort(c, http.StatusUnauthorized, i18n.UserGone)
+		return
+	}
+	if err != nil {
+		httpx.AbortInternal(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, sessionResponse{User: user})
+}
+
+func (h *Auth) respondSession(c *gin.Context, status int, user *store.User, exposeRefresh bool) {
+	resp, refreshToken, err := h.startSession(c, user)
+	if err != nil {
+		httpx.AbortInternal(c, err)
+		return
+	}
+	if exposeRefresh {
+		resp.RefreshToken = refreshToken
+	}
+	c.JSON(status, resp)
+}
+
+// startSession 为一次新的登录创建会话，签发 access token 与 refresh token，并写入 HttpOnly cookie 供浏览器端使用。
+func (h *Auth) startSession(c *gin.Context, user *store.User) (sessionResponse, string, error) {
+	var resp sessionResponse
+	var refreshToken string
+	err := h.store.WithTx(c.Request.Context(), func(tx *store.Store) error {
+		session := &store.Session{UserID: user.ID, UserAgent: c.Request.UserAgent(), IP: httpx.ClientIP(c)}
+		if err := tx.CreateSession(c.Request.Context(), session); err != nil {
+			return err
+		}
+		var err error
+		resp, refreshToken, err = h.issueTokens(c, tx, user, session.ID)
+		return err
+	})
+	if err != nil {
+		return sessionResponse{}, "", err
+	}
+	h.setCookies(c, resp.AccessToken, refreshToken)
+	return resp, refreshToken, nil
+}
+
+// resumeSession 在刷新令牌时沿用原会话并更新其活跃信息；会话已被撤销时返回 store.ErrNotFound。
+// sessionID 为空的旧令牌会补建一个会话。
+func (h *Auth) resumeSession(c *gin.Context, user *store.User, sessionID *int64) (sessionResponse, string, error) {
+	if sessionID == nil {
+		return h.startSession(c, user)
+	}
+	var resp sessionResponse
+	var refreshToken string
+	// 更新会话与写入新令牌放在同一事务中，并发撤销该会话时要么先删掉会话、要么连同新令牌一起删除。
+	err := h.store.WithTx(c.Request.Context(), func(tx *store.Store) error {
+		if err := tx.TouchSession(c.Request.Context(), *sessionID, c.Request.UserAgent(), httpx.ClientIP(c)); err != nil {
+			return err
+		}
+		var err error
+		resp, refreshToken, err = h.issueTokens(c, tx, user, *sessionID)
+		return err
+	})
+	if err != nil {
+		return sessionResponse{}, "", err
+	}
+	h.setCookies(c, resp.AccessToken, refreshToken)
+	return resp, refreshToken, nil
+}
+
+// issueTokens 签发属于 sessionID 的 access token 与新的 refresh token，cookie 由调用方在事务提交后写入。
+func (h *Auth) issueTokens(c *gin.Context, s *store.Store, user *store.User, sessionID int64) (sessionResponse, string, error) {
+	token, expiresAt, err := auth.IssueAccessToken(h.secret, user.ID, user.Role, sessionID, h.accessTTL)
+	if err != nil {
+		return sessionResponse{}, "", err
+	}
+
+	refreshToken, refreshHash, err := auth.NewOpaqueToken()
+	if err != nil {
+		return sessionResponse{}, "", err
+	}
+	err = s.CreateRefreshToken(c.Request.Context(), &store.RefreshToken{
+		UserID:    user.ID,
+		SessionID: &sessionID,
+		TokenHash: refreshHash,
+		ExpiresAt: time.Now().Add(h.refreshTTL),
+	})
+	if err != nil {
+		return sessionResponse{}, "", err
+	}
+
+	return sessionResponse{User: user, AccessToken: token, ExpiresAt: &expiresAt}, refreshToken, nil
+}
+
+func (h *Auth) setCookies(c *gin.Context, accessToken string, refreshToken string) {
+	c.SetSameSite(http.SameSiteLaxMode)
+	c.SetCookie(auth.AccessTokenCookie, accessToken, int(h.accessTTL.Seconds()), "/", "", h.secureCookie, true)
+	c.SetCookie(auth.RefreshTokenCookie, refreshToken, int(h.refreshTTL.Seconds()), refreshCookiePath, "", h.secureCookie, true)
+}
+
+func (h *Auth) clearCookies(c *gin.Context) {
+	c.SetSameSite(http.SameSiteLaxMode)
+	c.SetCookie(auth.AccessTokenCookie, "", -1, "/", "", h.secureCookie, true)
+	c.SetCookie(auth.RefreshTokenCookie, "", -1, refreshCookiePath, "", h.secureCookie, true)
+}
+
+func (h *Auth) refreshTokenFromRequest(c *gin.Context) (string, bool) {
+	if token, err := c.Cookie(auth.RefreshTokenCookie); err == nil && token != "" {
+		return token, false
+	}
+	var req refreshRequest
+	if c.Request.ContentLength != 0 && c.ShouldBindJSON(&req) == nil && req.RefreshToken != "" {
+		return req.RefreshToken, true
+	}
+	return "", false
+}
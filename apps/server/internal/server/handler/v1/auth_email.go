@@ -0,0 +1,113 @@
+package v1
+
+import (
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/lifei6671/plaindoc/apps/server/internal/auth"
+	"github.com/lifei6671/plaindoc/apps/server/internal/i18n"
+	"github.com/lifei6671/plaindoc/apps/server/internal/server/httpx"
+	"github.com/lifei6671/plaindoc/apps/server/internal/settings"
+	"github.com/lifei6671/plaindoc/apps/server/internal/store"
+)
+
+const (
+	// emailVerificationTTL 是验证邮件中链接的有效期。
+	emailVerificationTTL = 24 * time.Hour
+	// verificationResendInterval 是同一用户两封验证邮件之间的最短间隔。
+	verificationResendInterval = time.Minute
+)
+
+// emailVerificationPending 表示注册成功但需要先验证邮箱，此时不签发会话。
+const emailVerificationPending = "pending"
+
+type pendingVerificationResponse struct {
+	User              *store.User `json:"user"`
+	EmailVerification string      `json:"email_verification"`
+}
+
+type resendVerificationRequest struct {
+	Email string `json:"email" binding:"required,email,max=191"`
+}
+
+// VerifyEmail 处理验证邮件中的链接，验证成功后用户即可登录。
+func (h *Auth) VerifyEmail(c *gin.Context) {
+	token := c.Query("token")
+	if token == "" {
+		httpx.Abort(c, http.StatusBadRequest, i18n.FieldRequired, "token")
+		return
+	}
+	ctx := c.Request.Context()
+	userID, err := h.store.VerifyEmail(ctx, auth.HashToken(token))
+	if errors.Is(err, store.ErrNotFound) {
+		httpx.Abort(c, http.StatusBadRequest, i18n.InvalidVerificationLink)
+		return
+	}
+	if err != nil {
+		httpx.AbortInternal(c, err)
+		return
+	}
+	user, err := h.store.GetUser(ctx, userID)
+	if err != nil {
+		httpx.AbortInternal(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"user": user})
+}
+
+// ResendVerification 给尚未验证邮箱的用户重发验证邮件，每分钟最多一封。
+// 与忘记密码一样，无论邮箱是否注册、是否已验证都返回 204。
+func (h *Auth) ResendVerification(c *gin.Context) {
+	var req resendVerificationRequest
+	if !httpx.BindAndValidate(c, &req) {
+		return
+	}
+	user, err := h.store.GetUserByEmail(c.Request.Context(), req.Email)
+	if err != nil && !errors.Is(err, store.ErrNotFound) {
+		httpx.AbortInternal(c, err)
+		return
+	}
+	if user != nil && user.EmailVerifiedAt == nil {
+		if err := h.sendVerification(c, user); err != nil {
+			_ = c.Error(err)
+		}
+	}
+	c.Status(http.StatusNoContent)
+}
+
+// emailUnverified 判断用户是否因邮箱未验证而不能登录；关闭 REQUIRE_EMAIL_VERIFICATION 后未验证的用户也能登录。
+func (h *Auth) emailUnverified(user *store.User) bool {
+	return h.requireVerification && user.EmailVerifiedAt == nil
+}
+
+// sendVerification 生成一次性的验证 token 并投递邮件；距上一封不足 verificationResendInterval 时不发送。
+func (h *Auth) sendVerification(c *gin.Context, user *store.User) error {
+	if h.mailer == nil {
+		return nil
+	}
+	base, err := emailURL(h.publicURL)
+	if err != nil {
+		return err
+	}
+	ctx := c.Request.Context()
+	snapshot, err := h.settings.Get(ctx)
+	if err != nil {
+		return err
+	}
+	token, hash, err := auth.NewOpaqueToken()
+	if err != nil {
+		return err
+	}
+	created, err := h.store.CreateEmailVerificationToken(ctx, user.ID, hash, time.Now().Add(emailVerificationTTL), verificationResendInterval)
+	if err != nil || !created {
+		return err
+	}
+	return h.mailer.SendTemplate(user.Email, "verify_email", map[string]any{
+		"Recipient": user.Name,
+		"SiteName":  snapshot.String(settings.SiteName),
+		"URL":       base + Prefix + "/auth/verify-email?token=" + token,
+		"Hours":     int(emailVerificationTTL.Hours()),
+	})
+}
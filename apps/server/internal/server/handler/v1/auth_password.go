@@ -0,0 +1,136 @@
+package v1
+
+import (
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/lifei6671/plaindoc/apps/server/internal/auth"
+	"github.com/lifei6671/plaindoc/apps/server/internal/i18n"
+	"github.com/lifei6671/plaindoc/apps/server/internal/server/httpx"
+	"github.com/lifei6671/plaindoc/apps/server/internal/settings"
+	"github.com/lifei6671/plaindoc/apps/server/internal/store"
+)
+
+// resetPasswordPath 是前端重置密码页面的路径，token 放在 URL fragment 中，不会随请求发往服务器或写入访问日志。
+const resetPasswordPath = "/reset-password#token="
+
+type forgotPasswordRequest struct {
+	Email string `json:"email" binding:"required,email,max=191"`
+}
+
+type resetPasswordRequest struct {
+	Token    string `json:"token" binding:"required"`
+	Password string `json:"password" binding:"required,max=72"`
+}
+
+// ForgotPassword 给邮箱对应的用户发送重置密码邮件。无论邮箱是否注册、邮件是否发出都返回 204，避免被用来探测账号；
+// 未配置 PUBLIC_URL 时无法生成重置链接，在查询邮箱之前直接返回 503。
+func (h *Auth) ForgotPassword(c *gin.Context) {
+	var req forgotPasswordRequest
+	if !httpx.BindAndValidate(c, &req) {
+		return
+	}
+	if h.publicURL == "" {
+		httpx.Abort(c, http.StatusServiceUnavailable, i18n.PasswordResetUnavailable)
+		return
+	}
+	user, err := h.store.GetUserByEmail(c.Request.Context(), req.Email)
+	if err != nil && !errors.Is(err, store.ErrNotFound) {
+		httpx.AbortInternal(c, err)
+		return
+	}
+	if user != nil {
+		if err := h.sendPasswordReset(c, user); err != nil {
+			_ = c.Error(err)
+		}
+	}
+	c.Status(http.StatusNoContent)
+}
+
+// sendPasswordReset 生成一次性的重置 token 并投递邮件，数据库只保存 token 的摘要。
+func (h *Auth) sendPasswordReset(c *gin.Context, user *store.User) error {
+	if h.mailer == nil {
+		return nil
+	}
+	ctx := c.Request.Context()
+	base, err := emailURL(h.publicURL)
+	if err != nil {
+		return err
+	}
+	snapshot, err := h.settings.Get(ctx)
+	if err != nil {
+		return err
+	}
+	token, hash, err := auth.NewOpaqueToken()
+	if err != nil {
+		return err
+	}
+	if err := h.store.CreatePasswordResetToken(ctx, user.ID, hash, time.Now().Add(h.resetTTL)); err != nil {
+		return err
+	}
+	return h.mailer.SendTemplate(user.Email, "reset_password", map[string]any{
+		"Recipient": user.Name,
+		"SiteName":  snapshot.String(settings.SiteName),
+		"URL":       base + resetPasswordPath + token,
+		"Minutes":   int(h.resetTTL.Minutes()),
+	})
+}
+
+// weakPasswordKeys 把密码策略的各条规则映射为错误消息键。
+var weakPasswordKeys = map[error]string{
+	auth.ErrPasswordNumeric:   i18n.WeakPasswordNumeric,
+	auth.ErrPasswordNoUpper:   i18n.WeakPasswordUpper,
+	auth.ErrPasswordNoLower:   i18n.WeakPasswordLower,
+	auth.ErrPasswordNoDigit:   i18n.WeakPasswordDigit,
+	auth.ErrPasswordNoSymbol:  i18n.WeakPasswordSymbol,
+	auth.ErrPasswordTooCommon: i18n.WeakPasswordCommon,
+}
+
+// checkPassword 按 PASSWORD_* 策略校验新密码，不满足时返回 400 weak_password 并指出缺少的规则。
+func (h *Auth) checkPassword(c *gin.Context, password string) bool {
+	err := auth.ValidatePassword(h.passwordPolicy, password)
+	switch {
+	case err == nil:
+		return true
+	case errors.Is(err, auth.ErrPasswordTooShort):
+		httpx.Abort(c, http.StatusBadRequest, i18n.WeakPasswordLength, h.passwordPolicy.MinLength)
+	case errors.Is(err, auth.ErrPasswordTooLong):
+		httpx.Abort(c, http.StatusBadRequest, i18n.WeakPasswordTooLong, auth.MaxPasswordBytes)
+	default:
+		key, ok := weakPasswordKeys[err]
+		if !ok {
+			key = i18n.WeakPassword
+		}
+		httpx.Abort(c, http.StatusBadRequest, key)
+	}
+	return false
+}
+
+// ResetPassword 用邮件中的 token 设置新密码；token 随即作废，用户的全部会话被撤销，需要用新密码重新登录。
+func (h *Auth) ResetPassword(c *gin.Context) {
+	var req resetPasswordRequest
+	if !httpx.BindAndValidate(c, &req) {
+		return
+	}
+	if !h.checkPassword(c, req.Password) {
+		return
+	}
+	hash, err := auth.HashPassword(req.Password)
+	if err != nil {
+		httpx.AbortInternal(c, err)
+		return
+	}
+	err = h.store.ResetPassword(c.Request.Context(), auth.HashToken(req.Token), hash)
+	if errors.Is(err, store.ErrNotFound) {
+		httpx.Abort(c, http.StatusBadRequest, i18n.InvalidResetToken)
+		return
+	}
+	if err != nil {
+		httpx.AbortInternal(c, err)
+		return
+	}
+	h.clearCookies(c)
+	c.Status(http.StatusNoContent)
+}
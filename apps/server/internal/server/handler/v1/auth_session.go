@@ -0,0 +1,65 @@
+package v1
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/lifei6671/plaindoc/apps/server/internal/i18n"
+	"github.com/lifei6671/plaindoc/apps/server/internal/server/httpx"
+	"github.com/lifei6671/plaindoc/apps/server/internal/store"
+)
+
+type sessionItem struct {
+	store.Session
+	// Current 表示发起请求的 access token 所属的会话。
+	Current bool `json:"current"`
+}
+
+// ListSessions 列出当前用户仍然有效的会话。
+func (h *Auth) ListSessions(c *gin.Context) {
+	current, _ := httpx.CurrentUser(c)
+	sessions, err := h.store.ListSessions(c.Request.Context(), current.ID)
+	if err != nil {
+		httpx.AbortInternal(c, err)
+		return
+	}
+	items := make([]sessionItem, len(sessions))
+	for i, session := range sessions {
+		items[i] = sessionItem{Session: session, Current: session.ID == current.SessionID}
+	}
+	c.JSON(http.StatusOK, gin.H{"items": items})
+}
+
+// RevokeSession 撤销当前用户的一个会话，其 refresh token 立即失效；撤销的是当前会话时同时清除 cookie。
+func (h *Auth) RevokeSession(c *gin.Context) {
+	id, ok := httpx.ParamID(c, "id")
+	if !ok {
+		return
+	}
+	current, _ := httpx.CurrentUser(c)
+	err := h.store.RevokeSession(c.Request.Context(), current.ID, id)
+	if errors.Is(err, store.ErrNotFound) {
+		httpx.Abort(c, http.StatusNotFound, i18n.SessionNotFound)
+		return
+	}
+	if err != nil {
+		httpx.AbortInternal(c, err)
+		return
+	}
+	if id == current.SessionID {
+		h.clearCookies(c)
+	}
+	c.Status(http.StatusNoContent)
+}
+
+// LogoutAll 撤销当前用户除本会话外的全部会话。
+func (h *Auth) LogoutAll(c *gin.Context) {
+	current, _ := httpx.CurrentUser(c)
+	revoked, err := h.store.RevokeOtherSessions(c.Request.Context(), current.ID, current.SessionID)
+	if err != nil {
+		httpx.AbortInternal(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"revoked": revoked})
+}
@@ -0,0 +1,371 @@
+package v1
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/lifei6671/plaindoc/apps/server/internal/auth"
+	"github.com/lifei6671/plaindoc/apps/server/internal/i18n"
+	"github.com/lifei6671/plaindoc/apps/server/internal/server/httpx"
+	"github.com/lifei6671/plaindoc/apps/server/internal/settings"
+	"github.com/lifei6671/plaindoc/apps/server/internal/store"
+)
+
+// challengeTTL 是密码校验通过后完成两步验证的时限。
+const challengeTTL = 5 * time.Minute
+
+// 登录响应中 two_factor 的取值。
+const (
+	// twoFactorVerify 表示需要用 POST /auth/2fa/login 提交验证码或恢复码。
+	twoFactorVerify = "verify"
+	// twoFactorSetup 表示角色要求开启两步验证，需要先用中间 token 完成绑定。
+	twoFactorSetup = "setup"
+)
+
+type challengeResponse struct {
+	TwoFactor      string    `json:"two_factor"`
+	ChallengeToken string    `json:"challenge_token"`
+	ExpiresAt      time.Time `json:"expires_at"`
+}
+
+type enableTwoFactorRequest struct {
+	ChallengeToken string `json:"challenge_token"`
+}
+
+type verifyTwoFactorRequest struct {
+	ChallengeToken string `json:"challenge_token"`
+	Code           string `json:"code" binding:"required"`
+}
+
+type twoFactorLoginRequest struct {
+	ChallengeToken string `json:"challenge_token" binding:"required"`
+	Code           string `json:"code" binding:"required"`
+}
+
+type twoFactorCodeRequest struct {
+	Code string `json:"code" binding:"required"`
+}
+
+// completeLogin 在密码或第三方登录通过后继续登录流程：需要两步验证时只返回中间 token，否则直接签发会话。
+func (h *Auth) completeLogin(c *gin.Context, status int, user *store.User) {
+	challenge, err := h.challenge(c.Request.Context(), user)
+	if err != nil {
+		httpx.AbortInternal(c, err)
+		return
+	}
+	if challenge != nil {
+		c.JSON(http.StatusOK, challenge)
+		return
+	}
+	h.respondSession(c, status, user, false)
+}
+
+// challenge 返回用户登录前还需完成的两步验证步骤，不需要时返回 nil。
+func (h *Auth) challenge(ctx context.Context, user *store.User) (*challengeResponse, error) {
+	step, purpose := twoFactorVerify, auth.ChallengeLogin
+	if !user.TwoFactorEnabled {
+		snapshot, err := h.settings.Get(ctx)
+		if err != nil {
+			return nil, err
+		}
+		if !snapshot.TwoFactorRequired(user.Role) {
+			return nil, nil
+		}
+		step, purpose = twoFactorSetup, auth.ChallengeSetup
+	}
+
+	token, expiresAt, err := auth.IssueChallengeToken(h.secret, user.ID, purpose, challengeTTL)
+	if err != nil {
+		return nil, err
+	}
+	return &challengeResponse{TwoFactor: step, ChallengeToken: token, ExpiresAt: expiresAt}, nil
+}
+
+// EnableTwoFactor 生成新的 TOTP 密钥并返回 otpauth 地址，前端据此显示二维码；
+// 绑定在 VerifyTwoFactor 校验通过后才生效，重复调用会替换尚未绑定的密钥。
+func (h *Auth) EnableTwoFactor(c *gin.Context) {
+	var req enableTwoFactorRequest
+	if c.Request.ContentLength != 0 {
+		if !httpx.BindAndValidate(c, &req) {
+			return
+		}
+	}
+	user, _, ok := h.setupUser(c, req.ChallengeToken)
+	if !ok {
+		return
+	}
+	if user.TwoFactorEnabled {
+		httpx.Abort(c, http.StatusConflict, i18n.TwoFactorAlreadyEnabled)
+		return
+	}
+
+	ctx := c.Request.Context()
+	snapshot, err := h.settings.Get(ctx)
+	if err != nil {
+		httpx.AbortInternal(c, err)
+		return
+	}
+	secret, err := auth.NewTOTPSecret()
+	if err != nil {
+		httpx.AbortInternal(c, err)
+		return
+	}
+	if err := h.store.BeginTOTPSetup(ctx, user.ID, secret); err != nil {
+		if errors.Is(err, store.ErrNotFound) {
+			httpx.Abort(c, http.StatusConflict, i18n.TwoFactorAlreadyEnabled)
+			return
+		}
+		httpx.AbortInternal(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"secret":      secret,
+		"otpauth_url": auth.TOTPURL(snapshot.String(settings.SiteName), user.Email, secret),
+	})
+}
+
+// VerifyTwoFactor 用 Authenticator 上的验证码完成绑定，返回只显示这一次的恢复码；
+// 通过 setup 中间 token 绑定时同时签发会话，完成登录。
+func (h *Auth) VerifyTwoFactor(c *gin.Context) {
+	var req verifyTwoFactorRequest
+	if !httpx.BindAndValidate(c, &req) {
+		return
+	}
+	user, viaChallenge, ok := h.setupUser(c, req.ChallengeToken)
+	if !ok {
+		return
+	}
+	if user.TwoFactorEnabled {
+		httpx.Abort(c, http.StatusConflict, i18n.TwoFactorAlreadyEnabled)
+		return
+	}
+	if user.TOTPSecret == "" {
+		httpx.Abort(c, http.StatusConflict, i18n.TwoFactorNotStarted)
+		return
+	}
+	step, valid := auth.ValidateTOTP(user.TOTPSecret, strings.TrimSpace(req.Code), time.Now())
+	if !valid {
+		httpx.Abort(c, http.StatusBadRequest, i18n.InvalidTwoFactorCode)
+		return
+	}
+
+	codes, hashes, err := newRecoveryCodes()
+	if err != nil {
+		httpx.AbortInternal(c, err)
+		return
+	}
+	if err := h.store.EnableTOTP(c.Request.Context(), user.ID, user.TOTPSecret, step, hashes); err != nil {
+		// 校验期间密钥被并发的 EnableTwoFactor 替换，或已在其他请求中完成绑定。
+		if errors.Is(err, store.ErrNotFound) {
+			httpx.Abort(c, http.StatusConflict, i18n.TwoFactorNotStarted)
+			return
+		}
+		httpx.AbortInternal(c, err)
+		return
+	}
+	user.TwoFactorEnabled = true
+
+	if !viaChallenge {
+		c.JSON(http.StatusOK, gin.H{"recovery_codes": codes})
+		return
+	}
+	resp, _, err := h.startSession(c, user)
+	if err != nil {
+		httpx.AbortInternal(c, err)
+		return
+	}
+	resp.RecoveryCodes = codes
+	c.JSON(http.StatusOK, resp)
+}
+
+// TwoFactorLogin 用登录返回的中间 token 与验证码（或恢复码）换取正式会话。
+func (h *Auth) TwoFactorLogin(c *gin.Context) {
+	var req twoFactorLoginRequest
+	if !httpx.BindAndValidate(c, &req) {
+		return
+	}
+	userID, err := auth.ParseChallengeToken(h.secret, req.ChallengeToken, auth.ChallengeLogin)
+	if errors.Is(err, auth.ErrTokenExpired) {
+		httpx.Abort(c, http.StatusUnauthorized, i18n.ChallengeTokenExpired)
+		return
+	}
+	if err != nil {
+		httpx.Abort(c, http.StatusUnauthorized, i18n.InvalidChallengeToken)
+		return
+	}
+
+	ctx := c.Request.Context()
+	user, err := h.store.GetUser(ctx, userID)
+	if err != nil && !errors.Is(err, store.ErrNotFound) {
+		httpx.AbortInternal(c, err)
+		return
+	}
+	// 签发中间 token 之后用户被删除或关闭了两步验证，需要重新走一遍登录。
+	if user == nil || !user.TwoFactorEnabled {
+		httpx.Abort(c, http.StatusUnauthorized, i18n.InvalidChallengeToken)
+		return
+	}
+	// 验证码错误与密码错误计入同一组账号与 IP 失败计数，锁定期内中间 token 也不能再使用。
+	if h.loginLocked(c, user.Email) {
+		return
+	}
+	valid, err := h.checkSecondFactor(ctx, user, req.Code)
+	if err != nil {
+		httpx.AbortInternal(c, err)
+		return
+	}
+	if !valid {
+		h.recordLoginFailure(c, user.Email, i18n.InvalidTwoFactorCode)
+		return
+	}
+	h.clearLoginFailures(c, user.Email)
+
+	h.respondSession(c, http.StatusOK, user, false)
+}
+
+// DisableTwoFactor 校验验证码或恢复码后关闭两步验证；角色被要求开启两步验证时不能关闭。
+func (h *Auth) DisableTwoFactor(c *gin.Context) {
+	ctx := c.Request.Context()
+	user, code, ok := h.twoFactorUser(c)
+	if !ok {
+		return
+	}
+	// 先检查强制要求再校验验证码，避免被拒绝的请求白白消耗一个恢复码。
+	snapshot, err := h.settings.Get(ctx)
+	if err != nil {
+		httpx.AbortInternal(c, err)
+		return
+	}
+	if snapshot.TwoFactorRequired(user.Role) {
+		httpx.Abort(c, http.StatusForbidden, i18n.TwoFactorEnforced)
+		return
+	}
+	if !h.requireSecondFactor(c, user, code) {
+		return
+	}
+	if err := h.store.DisableTOTP(ctx, user.ID); err != nil {
+		httpx.AbortInternal(c, err)
+		return
+	}
+	c.Status(http.StatusNoContent)
+}
+
+// RegenerateRecoveryCodes 校验验证码或恢复码后生成一组新的恢复码，旧的恢复码全部作废。
+func (h *Auth) RegenerateRecoveryCodes(c *gin.Context) {
+	user, code, ok := h.twoFactorUser(c)
+	if !ok || !h.requireSecondFactor(c, user, code) {
+		return
+	}
+	codes, hashes, err := newRecoveryCodes()
+	if err != nil {
+		httpx.AbortInternal(c, err)
+		return
+	}
+	if err := h.store.ReplaceRecoveryCodes(c.Request.Context(), user.ID, hashes); err != nil {
+		httpx.AbortInternal(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"recovery_codes": codes})
+}
+
+// setupUser 返回正在绑定两步验证的用户：已登录时取当前用户，否则要求登录时返回的 setup 中间 token，
+// 第二个返回值表示是否通过中间 token 识别。失败时已写入错误响应。
+func (h *Auth) setupUser(c *gin.Context, challengeToken string) (*store.User, bool, bool) {
+	userID, viaChallenge := int64(0), false
+	if current, ok := httpx.CurrentUser(c); ok {
+		userID = current.ID
+	} else if challengeToken != "" {
+		id, err := auth.ParseChallengeToken(h.secret, challengeToken, auth.ChallengeSetup)
+		if errors.Is(err, auth.ErrTokenExpired) {
+			httpx.Abort(c, http.StatusUnauthorized, i18n.ChallengeTokenExpired)
+			return nil, false, false
+		}
+		if err != nil {
+			httpx.Abort(c, http.StatusUnauthorized, i18n.InvalidChallengeToken)
+			return nil, false, false
+		}
+		userID, viaChallenge = id, true
+	} else {
+		httpx.Abort(c, http.StatusUnauthorized, i18n.Unauthorized)
+		return nil, false, false
+	}
+
+	user, err := h.store.GetUser(c.Request.Context(), userID)
+	if errors.Is(err, store.ErrNotFound) {
+		httpx.Abort(c, http.StatusUnauthorized, i18n.UserGone)
+		return nil, false, false
+	}
+	if err != nil {
+		httpx.AbortInternal(c, err)
+		return nil, false, false
+	}
+	return user, viaChallenge, true
+}
+
+// twoFactorUser 读取已开启两步验证的当前用户与请求体中的验证码或恢复码。失败时已写入错误响应。
+func (h *Auth) twoFactorUser(c *gin.Context) (*store.User, string, bool) {
+	var req twoFactorCodeRequest
+	if !httpx.BindAndValidate(c, &req) {
+		return nil, "", false
+	}
+	current, _ := httpx.CurrentUser(c)
+	user, err := h.store.GetUser(c.Request.Context(), current.ID)
+	if errors.Is(err, store.ErrNotFound) {
+		httpx.Abort(c, http.StatusUnauthorized, i18n.UserGone)
+		return nil, "", false
+	}
+	if err != nil {
+		httpx.AbortInternal(c, err)
+		return nil, "", false
+	}
+	if !user.TwoFactorEnabled {
+		httpx.Abort(c, http.StatusConflict, i18n.TwoFactorNotEnabled)
+		return nil, "", false
+	}
+	return user, req.Code, true
+}
+
+// requireSecondFactor 校验并作废验证码或恢复码，未通过时写入错误响应并返回 false。
+func (h *Auth) requireSecondFactor(c *gin.Context, user *store.User, code string) bool {
+	valid, err := h.checkSecondFactor(c.Request.Context(), user, code)
+	if err != nil {
+		httpx.AbortInternal(c, err)
+		return false
+	}
+	if !valid {
+		httpx.Abort(c, http.StatusBadRequest, i18n.InvalidTwoFactorCode)
+		return false
+	}
+	return true
+}
+
+// checkSecondFactor 校验 6 位验证码或恢复码，通过后立即作废，同一个码不能再次使用。
+func (h *Auth) checkSecondFactor(ctx context.Context, user *store.User, code string) (bool, error) {
+	code = strings.TrimSpace(code)
+	if auth.IsTOTPCode(code) {
+		step, valid := auth.ValidateTOTP(user.TOTPSecret, code, time.Now())
+		if !valid {
+			return false, nil
+		}
+		return h.store.UseTOTPStep(ctx, user.ID, step)
+	}
+	return h.store.UseRecoveryCode(ctx, user.ID, auth.HashToken(auth.NormalizeRecoveryCode(code)))
+}
+
+// newRecoveryCodes 生成一组恢复码及其落库用的摘要。
+func newRecoveryCodes() ([]string, []string, error) {
+	codes, err := auth.NewRecoveryCodes()
+	if err != nil {
+		return nil, nil, err
+	}
+	hashes := make([]string, len(codes))
+	for i, code := range codes {
+		hashes[i] = auth.HashToken(code)
+	}
+	return codes, hashes, nil
+}
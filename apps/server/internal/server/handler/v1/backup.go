@@ -0,0 +1,135 @@
+package v1
+
+import (
+	"errors"
+	"mime"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/lifei6671/plaindoc/apps/server/internal/backup"
+	"github.com/lifei6671/plaindoc/apps/server/internal/config"
+	"github.com/lifei6671/plaindoc/apps/server/internal/i18n"
+	"github.com/lifei6671/plaindoc/apps/server/internal/server/httpx"
+	"github.com/lifei6671/plaindoc/apps/server/internal/settings"
+	"github.com/lifei6671/plaindoc/apps/server/internal/store"
+)
+
+// restoreConfirmation 是恢复接口 confirm 字段要求的值，防止误操作覆盖全部数据。
+const restoreConfirmation = "RESTORE"
+
+// Backup 处理数据库备份与恢复，仅管理员可用。
+type Backup struct {
+	backups  *backup.Service
+	settings *settings.Service
+	maxSize  int64
+}
+
+func NewBackup(cfg config.Config, backups *backup.Service, settingsService *settings.Service) *Backup {
+	return &Backup{backups: backups, settings: settingsService, maxSize: cfg.BackupMaxSize}
+}
+
+// Create 生成一份备份：默认以附件流式返回，save=true 时保存到备份目录并返回文件信息。
+// exclude 为逗号分隔的表名，未传时使用 BACKUP_EXCLUDE_TABLES，传空值表示备份全部表。
+// 响应头发出后再出错只能中断连接，客户端会得到不完整的文件，错误写入请求日志。
+func (h *Backup) Create(c *gin.Context) {
+	exclude := h.backups.DefaultExclude()
+	if raw, ok := c.GetQuery("exclude"); ok {
+		exclude = nil
+		for _, name := range strings.Split(raw, ",") {
+			if name = strings.TrimSpace(name); name == "" {
+				continue
+			}
+			if _, ok := store.LookupBackupTable(name); !ok {
+				httpx.Abort(c, http.StatusBadRequest, i18n.UnknownBackupTable, name, strings.Join(backup.TableNames(), ", "))
+				return
+			}
+			exclude = append(exclude, name)
+		}
+	}
+
+	if c.Query("save") == "true" {
+		file, err := h.backups.Save(c.Request.Context(), exclude)
+		if err != nil {
+			h.abort(c, err)
+			return
+		}
+		c.JSON(http.StatusCreated, file)
+		return
+	}
+
+	c.Header("Content-Type", backup.ContentType)
+	c.Header("Content-Disposition", mime.FormatMediaType("attachment", map[string]string{"filename": backup.FileName(time.Now())}))
+	if _, err := h.backups.Write(c.Request.Context(), c.Writer, exclude); err != nil {
+		if c.Writer.Written() {
+			_ = c.Error(err)
+			c.Abort()
+			return
+		}
+		c.Writer.Header().Del("Content-Disposition")
+		h.abort(c, err)
+	}
+}
+
+// List 返回备份目录中保存的备份，最新的在前。
+func (h *Backup) List(c *gin.Context) {
+	files, err := h.backups.List()
+	if err != nil {
+		httpx.AbortInternal(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"items": files})
+}
+
+// Restore 接收 multipart 字段 file（备份文件）与 confirm=RESTORE，用备份替换数据库中的全部数据。
+// 恢复在一个事务中完成，失败时数据保持原样；备份时排除的表恢复后为空。
+func (h *Backup) Restore(c *gin.Context) {
+	c.Request.Body = http.MaxBytesReader(c.Writer, c.Request.Body, h.maxSize+multipartOverhead)
+	header, err := c.FormFile("file")
+	if err != nil {
+		var tooLarge *http.MaxBytesError
+		if errors.As(err, &tooLarge) {
+			httpx.Abort(c, http.StatusRequestEntityTooLarge, i18n.BackupTooLarge, h.maxSize)
+			return
+		}
+		httpx.Abort(c, http.StatusBadRequest, i18n.FieldRequired, "file")
+		return
+	}
+	if c.PostForm("confirm") != restoreConfirmation {
+		httpx.Abort(c, http.StatusBadRequest, i18n.ConfirmationRequired, "confirm", restoreConfirmation)
+		return
+	}
+
+	file, err := header.Open()
+	if err != nil {
+		httpx.AbortInternal(c, err)
+		return
+	}
+	defer file.Close()
+	result, err := h.backups.Restore(c.Request.Context(), file)
+	if err != nil {
+		h.abort(c, err)
+		return
+	}
+	h.settings.Invalidate()
+	c.JSON(http.StatusOK, result)
+}
+
+func (h *Backup) abort(c *gin.Context, err error) {
+	var driverMismatch *backup.DriverMismatchError
+	var schemaMismatch *backup.SchemaMismatchError
+	switch {
+	case errors.Is(err, backup.ErrBusy):
+		httpx.Abort(c, http.StatusConflict, i18n.BackupInProgress)
+	case errors.Is(err, backup.ErrInvalidFile):
+		_ = c.Error(err)
+		httpx.Abort(c, http.StatusBadRequest, i18n.InvalidBackup)
+	case errors.As(err, &driverMismatch):
+		httpx.Abort(c, http.StatusConflict, i18n.BackupDriverMismatch, driverMismatch.Backup, driverMismatch.Current)
+	case errors.As(err, &schemaMismatch):
+		httpx.Abort(c, http.StatusConflict, i18n.BackupSchemaMismatch, schemaMismatch.Backup, schemaMismatch.Current)
+	default:
+		httpx.AbortInternal(c, err)
+	}
+}
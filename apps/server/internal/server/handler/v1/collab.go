@@ -0,0 +1,84 @@
+package v1
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gorilla/websocket"
+	"github.com/lifei6671/plaindoc/apps/server/internal/acl"
+	"github.com/lifei6671/plaindoc/apps/server/internal/collab"
+	"github.com/lifei6671/plaindoc/apps/server/internal/i18n"
+	"github.com/lifei6671/plaindoc/apps/server/internal/server/httpx"
+	"github.com/lifei6671/plaindoc/apps/server/internal/store"
+)
+
+// Collab 处理文档实时协作的 WebSocket 连接。
+type Collab struct {
+	store    *store.Store
+	policy   *acl.Policy
+	hub      *collab.Hub
+	upgrader websocket.Upgrader
+}
+
+// NewCollab 的 allowOrigin 用于校验握手请求的 Origin：WebSocket 不受 CORS 约束，
+// 不校验的话任意站点都能借用户的 cookie 建立连接。
+func NewCollab(s *store.Store, policy *acl.Policy, hub *collab.Hub, allowOrigin func(origin string) bool) *Collab {
+	return &Collab{
+		store:  s,
+		policy: policy,
+		hub:    hub,
+		upgrader: websocket.Upgrader{
+			CheckOrigin: func(r *http.Request) bool {
+				origin := r.Header.Get("Origin")
+				return origin == "" || allowOrigin(origin)
+			},
+		},
+	}
+}
+
+// Connect 把请求升级为 WebSocket 并加入文档房间；能阅读文档的用户都可以加入，
+// 只有有写权限的用户可以广播内容变更。
+func (h *Collab) Connect(c *gin.Context) {
+	doc, ok := loadDocument(c, h.store, h.policy)
+	if !ok {
+		return
+	}
+	ctx := c.Request.Context()
+	viewer := currentViewer(c)
+	writable, err := h.policy.CanWrite(ctx, viewer, doc)
+	if err != nil {
+		httpx.AbortInternal(c, err)
+		return
+	}
+	// 只读 API Key 可以旁观，但不能广播变更。
+	if current, _ := httpx.CurrentUser(c); current.ReadOnly {
+		writable = false
+	}
+	user, err := h.store.GetUser(ctx, viewer.ID)
+	if errors.Is(err, store.ErrNotFound) {
+		httpx.Abort(c, http.StatusUnauthorized, i18n.UserGone)
+		return
+	}
+	if err != nil {
+		httpx.AbortInternal(c, err)
+		return
+	}
+	// 握手前先检查一次人数，让客户端拿到明确的 HTTP 错误；并发加入时由 Hub 再兜底。
+	if !h.hub.Available(doc.ID) {
+		httpx.Abort(c, http.StatusConflict, i18n.RoomFull)
+		return
+	}
+
+	conn, err := h.upgrader.Upgrade(c.Writer, c.Request, nil)
+	if err != nil {
+		// Upgrade 失败时已经写出了 400/403 响应。
+		_ = c.Error(err)
+		c.Abort()
+		return
+	}
+	defer conn.Close()
+	if err := h.hub.Serve(conn, doc.ID, collab.Peer{UserID: user.ID, Name: user.Name, CanWrite: writable}); err != nil && !errors.Is(err, collab.ErrRoomFull) {
+		_ = c.Error(err)
+	}
+}
@@ -0,0 +1,178 @@
+package v1
+
+import (
+	"errors"
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/lifei6671/plaindoc/apps/server/internal/acl"
+	"github.com/lifei6671/plaindoc/apps/server/internal/auth"
+	"github.com/lifei6671/plaindoc/apps/server/internal/i18n"
+	"github.com/lifei6671/plaindoc/apps/server/internal/mailer"
+	"github.com/lifei6671/plaindoc/apps/server/internal/render"
+	"github.com/lifei6671/plaindoc/apps/server/internal/server/httpx"
+	"github.com/lifei6671/plaindoc/apps/server/internal/store"
+)
+
+// Comment 处理文档评论接口；能阅读文档的登录用户都可以评论。
+type Comment struct {
+	store     *store.Store
+	policy    *acl.Policy
+	renderer  *render.Renderer
+	mailer    mailer.Mailer
+	publicURL string
+}
+
+func NewComment(s *store.Store, policy *acl.Policy, renderer *render.Renderer, m mailer.Mailer, publicURL string) *Comment {
+	return &Comment{store: s, policy: policy, renderer: renderer, mailer: m, publicURL: publicURL}
+}
+
+type createCommentRequest struct {
+	Content  string `json:"content" binding:"required,max=10000"`
+	ParentID *int64 `json:"parent_comment_id"`
+	// Anchor 标识评论针对的段落或行，格式由前端约定，例如标题 id 或 "L12"。
+	Anchor string `json:"anchor" binding:"max=255"`
+}
+
+// List 分页返回顶层评论及其回复，支持 sort=created_at|-created_at 排序。
+func (h *Comment) List(c *gin.Context) {
+	doc, ok := loadDocument(c, h.store, h.policy)
+	if !ok {
+		return
+	}
+	pagination, ok := httpx.ParsePagination(c)
+	if !ok {
+		return
+	}
+	filter := store.CommentFilter{DocID: doc.ID, Limit: pagination.Limit(), Offset: pagination.Offset()}
+	switch c.DefaultQuery("sort", "created_at") {
+	case "created_at":
+	case "-created_at":
+		filter.Newest = true
+	default:
+		httpx.Abort(c, http.StatusBadRequest, i18n.FieldOneOf, "sort", "created_at, -created_at")
+		return
+	}
+
+	comments, total, err := h.store.ListComments(c.Request.Context(), filter)
+	if err != nil {
+		httpx.AbortInternal(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, httpx.NewPagedResponse(comments, total, pagination))
+}
+
+// Create 发表评论或回复；回复只能针对同一文档的顶层评论。
+func (h *Comment) Create(c *gin.Context) {
+	doc, ok := loadDocument(c, h.store, h.policy)
+	if !ok {
+		return
+	}
+	var req createCommentRequest
+	if !httpx.BindAndValidate(c, &req) {
+		return
+	}
+	content := strings.TrimSpace(req.Content)
+	if content == "" {
+		httpx.Abort(c, http.StatusBadRequest, i18n.FieldBlank, "content")
+		return
+	}
+
+	ctx := c.Request.Context()
+	var parent *store.Comment
+	if req.ParentID != nil {
+		var err error
+		parent, err = h.store.GetComment(ctx, *req.ParentID)
+		if err != nil && !errors.Is(err, store.ErrNotFound) {
+			httpx.AbortInternal(c, err)
+			return
+		}
+		if parent == nil || parent.DocID != doc.ID || parent.ParentID != nil {
+			httpx.Abort(c, http.StatusBadRequest, i18n.InvalidParentComment)
+			return
+		}
+	}
+
+	// 评论与正文走同一套渲染与白名单消毒，content_html 可以直接插入页面。
+	rendered, err := h.renderer.Render([]byte(content))
+	if err != nil {
+		httpx.AbortInternal(c, err)
+		return
+	}
+	user, _ := httpx.CurrentUser(c)
+	comment := &store.Comment{
+		DocID:       doc.ID,
+		ParentID:    req.ParentID,
+		AuthorID:    user.ID,
+		Anchor:      strings.TrimSpace(req.Anchor),
+		Content:     content,
+		ContentHTML: rendered.HTML,
+	}
+	if err := h.store.CreateComment(ctx, comment); err != nil {
+		httpx.AbortInternal(c, err)
+		return
+	}
+	if created, err := h.store.GetComment(ctx, comment.ID); err == nil {
+		comment = created
+	}
+	if parent != nil && parent.AuthorID != user.ID {
+		h.notifyReply(c, doc, parent, comment)
+	}
+	c.JSON(http.StatusCreated, comment)
+}
+
+// notifyReply 给被回复的评论作者发送邮件提醒；提醒失败不影响评论发表，只记录错误。
+func (h *Comment) notifyReply(c *gin.Context, doc *store.Document, parent, reply *store.Comment) {
+	if h.mailer == nil {
+		return
+	}
+	base, err := emailURL(h.publicURL)
+	if err != nil {
+		_ = c.Error(err)
+		return
+	}
+	recipient, err := h.store.GetUser(c.Request.Context(), parent.AuthorID)
+	if err != nil {
+		_ = c.Error(err)
+		return
+	}
+	err = h.mailer.SendTemplate(recipient.Email, "comment_reply", map[string]any{
+		"Recipient": recipient.Name,
+		"Replier":   reply.AuthorName,
+		"DocTitle":  doc.Title,
+		"Content":   reply.Content,
+		"URL":       base + "/",
+	})
+	if err != nil {
+		_ = c.Error(err)
+	}
+}
+
+// Delete 删除评论，仅限评论作者或管理员；删除顶层评论会同时删除其回复。
+func (h *Comment) Delete(c *gin.Context) {
+	id, ok := httpx.ParamID(c, "id")
+	if !ok {
+		return
+	}
+	ctx := c.Request.Context()
+	comment, err := h.store.GetComment(ctx, id)
+	if errors.Is(err, store.ErrNotFound) {
+		httpx.Abort(c, http.StatusNotFound, i18n.CommentNotFound)
+		return
+	}
+	if err != nil {
+		httpx.AbortInternal(c, err)
+		return
+	}
+	user, _ := httpx.CurrentUser(c)
+	if comment.AuthorID != user.ID && user.Role != auth.RoleAdmin {
+		httpx.Abort(c, http.StatusForbidden, i18n.ForbiddenDeleteComment)
+		return
+	}
+	if err := h.store.DeleteComment(ctx, id); err != nil && !errors.Is(err, store.ErrNotFound) {
+		httpx.AbortInternal(c, err)
+		return
+	}
+	c.Status(http.StatusNoContent)
+}
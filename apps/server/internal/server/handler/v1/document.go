@@ -0,0 +1,552 @@
+package v1
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/lifei6671/plaindoc/apps/server/internal/acl"
+	"github.com/lifei6671/plaindoc/apps/server/internal/i18n"
+	"github.com/lifei6671/plaindoc/apps/server/internal/render"
+	"github.com/lifei6671/plaindoc/apps/server/internal/search"
+	"github.com/lifei6671/plaindoc/apps/server/internal/server/httpx"
+	"github.com/lifei6671/plaindoc/apps/server/internal/slug"
+	"github.com/lifei6671/plaindoc/apps/server/internal/store"
+	"github.com/lifei6671/plaindoc/apps/server/internal/views"
+	"github.com/lifei6671/plaindoc/apps/server/internal/webhook"
+)
+
+// Document 处理文档的增删改查接口。
+type Document struct {
+	store    *store.Store
+	indexer  search.Indexer
+	policy   *acl.Policy
+	webhooks *webhook.Dispatcher
+	renderer *render.Cached
+	views    *views.Counter
+	// maxBatch 是批量操作一次最多处理的文档数。
+	maxBatch int
+	// lockTTL 是编辑锁的有效期，持锁者每次续期后重新计算。
+	lockTTL time.Duration
+	// documentURL 生成 [[slug]] 内部链接指向的文档页面地址，通常为 config.Config.DocumentURL。
+	documentURL func(id int64, space string, slug string) string
+}
+
+func NewDocument(s *store.Store, indexer search.Indexer, policy *acl.Policy, webhooks *webhook.Dispatcher, renderer *render.Cached, counter *views.Counter, maxBatch int, lockTTL time.Duration, documentURL func(id int64, space string, slug string) string) *Document {
+	return &Document{store: s, indexer: indexer, policy: policy, webhooks: webhooks, renderer: renderer, views: counter, maxBatch: maxBatch, lockTTL: lockTTL, documentURL: documentURL}
+}
+
+type createDocumentRequest struct {
+	Space    string `json:"space" binding:"max=64"`
+	ParentID *int64 `json:"parent_id"`
+	Title    string `json:"title" binding:"required,max=255"`
+	// Slug 省略时从标题生成，与空间内已有文档冲突时自动追加 -2、-3 等后缀；手填的 slug 冲突时返回 409。
+	Slug    string `json:"slug"`
+	Content string `json:"content"`
+	// IsPrivate 与 InheritPermissions 见 store.Document；InheritPermissions 省略时默认继承父文档权限。
+	IsPrivate          bool  `json:"is_private"`
+	InheritPermissions *bool `json:"inherit_permissions"`
+	// Tags 会被归一化与去重，不存在的标签自动创建。
+	Tags []string `json:"tags" binding:"max=20"`
+	// Status 为 draft（默认）或 published；草稿发布前只有作者与有写权限者可见。
+	Status string `json:"status" binding:"omitempty,oneof=draft published"`
+}
+
+type updateDocumentRequest struct {
+	Title   *string `json:"title" binding:"omitempty,min=1,max=255"`
+	Slug    *string `json:"slug"`
+	Content *string `json:"content"`
+	Summary string  `json:"summary" binding:"max=255"`
+	// Tags 非 nil 时整体替换文档的标签，空数组表示清空。
+	Tags *[]string `json:"tags" binding:"omitempty,max=20"`
+	// Draft 为 true 时标题与正文只保存为草稿，不改动已发布的内容，之后通过 Publish 发布。
+	Draft bool `json:"draft"`
+	// Status 用于取消发布（draft）或归档（archived），重新发布通过 Publish。
+	Status *string `json:"status" binding:"omitempty,oneof=draft archived"`
+	// Version 是客户端读取时的文档版本号，也可以通过 If-Match 头传递；与当前版本不一致时拒绝修改。
+	Version *int `json:"version" binding:"omitempty,min=1"`
+}
+
+func (h *Document) Create(c *gin.Context) {
+	user, _ := httpx.CurrentUser(c)
+
+	var req createDocumentRequest
+	if !httpx.BindAndValidate(c, &req) {
+		return
+	}
+
+	doc := &store.Document{
+		Space:    strings.TrimSpace(req.Space),
+		ParentID: req.ParentID,
+		Title:    req.Title,
+		Slug:     req.Slug,
+		Content:  req.Content,
+		AuthorID: user.ID,
+
+		IsPrivate:          req.IsPrivate,
+		InheritPermissions: req.InheritPermissions == nil || *req.InheritPermissions,
+		Status:             req.Status,
+	}
+	if doc.Status == "" {
+		doc.Status = store.DocStatusDraft
+	}
+	if doc.Space == "" {
+		doc.Space = store.DefaultSpace
+	}
+	if doc.Slug != "" && !validSlug(c, doc.Slug) {
+		return
+	}
+	if !checkSpace(c, h.store, doc.Space) || !h.checkParent(c, doc.Space, doc.ParentID) {
+		return
+	}
+	tags, ok := tagNames(c, req.Tags)
+	if !ok {
+		return
+	}
+	if raw := c.Query("template"); raw != "" && !h.applyTemplate(c, doc, raw) {
+		return
+	}
+	ctx := c.Request.Context()
+	err := h.store.WithTx(ctx, func(tx *store.Store) error {
+		if doc.Slug == "" {
+			var err error
+			if doc.Slug, err = tx.FreeSlug(ctx, doc.Space, slug.Generate(doc.Title)); err != nil {
+				return err
+			}
+		}
+		if err := tx.CreateDocument(ctx, doc); err != nil {
+			return err
+		}
+		var err error
+		doc.Tags, err = tx.SetDocumentTags(ctx, doc.Space, doc.ID, tags)
+		return err
+	})
+	if err != nil {
+		abortDocumentWriteError(c, err)
+		return
+	}
+	h.index(c, doc)
+	h.publish(c, webhook.EventDocPublished, doc)
+	c.JSON(http.StatusCreated, doc)
+}
+
+// applyTemplate 用 ?template=<id> 指定的模板初始化新文档的正文；请求中已提供 content 时只校验模板，不覆盖正文。
+// 模板不存在或对当前用户不可见时返回 404。
+func (h *Document) applyTemplate(c *gin.Context, doc *store.Document, raw string) bool {
+	id, err := strconv.ParseInt(raw, 10, 64)
+	if err != nil || id <= 0 {
+		httpx.Abort(c, http.StatusBadRequest, i18n.InvalidID, "template")
+		return false
+	}
+	tmpl, ok := loadTemplate(c, h.store, id)
+	if !ok || doc.Content != "" {
+		return ok
+	}
+	author, err := h.store.GetUser(c.Request.Context(), doc.AuthorID)
+	if err != nil {
+		httpx.AbortInternal(c, err)
+		return false
+	}
+	doc.Content = expandTemplate(tmpl.Content, doc.Title, author.Name, time.Now())
+	return true
+}
+
+// Get 默认返回已发布的内容，draft=true 时返回草稿（需要写权限）。
+// 支持 If-None-Match / If-Modified-Since 条件请求，文档未变化时返回 304；ETag 以版本号开头，修改时可原样作为 If-Match。
+func (h *Document) Get(c *gin.Context) {
+	doc, ok := h.load(c)
+	if !ok || !h.loadDraft(c, doc) || !h.loadTags(c, doc) || !h.loadFavorited(c, doc) {
+		return
+	}
+	h.recordView(c, doc)
+	httpx.VersionedJSON(c, doc.Version, lastModified(doc), doc)
+}
+
+// Rendered 返回文档正文渲染并消毒后的 HTML 与目录，结果按文档的 updated_at 缓存；draft=true 时渲染草稿。
+func (h *Document) Rendered(c *gin.Context) {
+	doc, ok := h.load(c)
+	if !ok || !h.loadDraft(c, doc) {
+		return
+	}
+	render := h.renderer.RenderDocument
+	if doc.DraftUpdatedAt != nil {
+		render = h.renderer.RenderDraft
+	}
+	ctx := c.Request.Context()
+	result, err := render(ctx, doc.ID, lastModified(doc), []byte(doc.Content))
+	if err != nil {
+		httpx.AbortInternal(c, err)
+		return
+	}
+	html, err := resolveWikiLinks(ctx, h.store, currentViewer(c), doc.Space, result.HTML, h.documentURL)
+	if err != nil {
+		httpx.AbortInternal(c, err)
+		return
+	}
+	httpx.ConditionalJSON(c, lastModified(doc), gin.H{
+		"doc_id":     doc.ID,
+		"updated_at": doc.UpdatedAt,
+		"html":       html,
+		"toc":        result.TOC,
+	})
+}
+
+// Update 直接修改文档；draft=true 时只把标题与正文保存为草稿。修改状态（取消发布、归档）需要管理权限。
+// 请求必须带上读取时的版本号，文档已被他人修改时返回 409 与当前内容；编辑锁由其他用户持有时同样返回 409。
+func (h *Document) Update(c *gin.Context) {
+	doc, ok := h.load(c)
+	if !ok || !h.requireWrite(c, doc) || !h.requireUnlocked(c, doc) {
+		return
+	}
+
+	var req updateDocumentRequest
+	if !httpx.BindAndValidate(c, &req) {
+		return
+	}
+	version, ok := expectedVersion(c, req.Version)
+	if !ok {
+		return
+	}
+	if version != doc.Version {
+		h.abortVersionConflict(c, doc)
+		return
+	}
+	if req.Draft {
+		if req.Slug != nil || req.Tags != nil || req.Status != nil {
+			httpx.Abort(c, http.StatusBadRequest, i18n.DraftFieldsOnly)
+			return
+		}
+		h.saveDraft(c, doc, req.Title, req.Content)
+		return
+	}
+	if req.Status != nil && !h.requireManage(c, doc) {
+		return
+	}
+	// 只修改状态时不产生新版本。
+	edited := req.Status == nil || req.Title != nil || req.Slug != nil || req.Content != nil || req.Tags != nil
+	previousStatus := doc.Status
+	if req.Title != nil {
+		doc.Title = *req.Title
+	}
+	if req.Slug != nil && *req.Slug != doc.Slug {
+		// 早期导入的文档可能带有不合法的 slug，原样提交时不校验。
+		if !validSlug(c, *req.Slug) {
+			return
+		}
+		doc.Slug = *req.Slug
+	}
+	if req.Content != nil {
+		doc.Content = *req.Content
+	}
+
+	var tags []string
+	if req.Tags != nil {
+		if tags, ok = tagNames(c, *req.Tags); !ok {
+			return
+		}
+	}
+
+	user, _ := httpx.CurrentUser(c)
+	ctx := c.Request.Context()
+	err := h.store.WithTx(ctx, func(tx *store.Store) error {
+		if edited {
+			if err := tx.UpdateDocument(ctx, doc, user.ID, strings.TrimSpace(req.Summary)); err != nil {
+				return err
+			}
+		}
+		if req.Tags != nil {
+			var err error
+			if doc.Tags, err = tx.SetDocumentTags(ctx, doc.Space, doc.ID, tags); err != nil {
+				return err
+			}
+		}
+		if req.Status != nil && *req.Status != doc.Status {
+			return tx.SetDocumentStatus(ctx, doc, *req.Status)
+		}
+		return nil
+	})
+	if err != nil {
+		h.abortWriteError(c, doc, err)
+		return
+	}
+	h.index(c, doc)
+	// 取消发布也要通知订阅者，只有修改前后都是草稿时才不触发。
+	if previousStatus != store.DocStatusDraft || doc.Status != store.DocStatusDraft {
+		h.dispatch(c, webhook.EventDocUpdated, doc)
+	}
+	h.respond(c, doc)
+}
+
+// Delete 把文档移入回收站，可通过 Restore 恢复；其他用户正在编辑时返回 409。
+func (h *Document) Delete(c *gin.Context) {
+	doc, ok := h.load(c)
+	if !ok || !h.requireManage(c, doc) || !h.requireUnlocked(c, doc) {
+		return
+	}
+	hasChildren, err := h.store.HasChildren(c.Request.Context(), doc.ID)
+	if err != nil {
+		httpx.AbortInternal(c, err)
+		return
+	}
+	if hasChildren {
+		httpx.Abort(c, http.StatusConflict, i18n.DocHasChildren)
+		return
+	}
+	user, _ := httpx.CurrentUser(c)
+	if err := h.store.TrashDocument(c.Request.Context(), doc.ID, user.ID); err != nil && !errors.Is(err, store.ErrNotFound) {
+		httpx.AbortInternal(c, err)
+		return
+	}
+	if err := h.indexer.Remove(c.Request.Context(), doc.ID); err != nil {
+		_ = c.Error(err)
+	}
+	h.publish(c, webhook.EventDocDeleted, doc)
+	c.Status(http.StatusNoContent)
+}
+
+// List 支持 page/page_size 分页、q 关键字过滤、space 与 tag 过滤、sort=updated_at|-updated_at 排序，
+// 以及 status=published（默认）|draft|archived 按状态过滤，草稿只列出自己的。
+func (h *Document) List(c *gin.Context) {
+	pagination, ok := httpx.ParsePagination(c)
+	if !ok {
+		return
+	}
+	filter := store.DocumentFilter{
+		Space:   c.Query("space"),
+		Keyword: strings.TrimSpace(c.Query("q")),
+		Tag:     store.NormalizeTagName(c.Query("tag")),
+		Status:  c.DefaultQuery("status", store.DocStatusPublished),
+		Viewer:  currentViewer(c),
+		Limit:   pagination.Limit(),
+		Offset:  pagination.Offset(),
+	}
+	switch filter.Status {
+	case store.DocStatusPublished, store.DocStatusDraft, store.DocStatusArchived:
+	default:
+		httpx.Abort(c, http.StatusBadRequest, i18n.FieldOneOf, "status", "published, draft, archived")
+		return
+	}
+	switch c.DefaultQuery("sort", "-updated_at") {
+	case "updated_at":
+		filter.Ascending = true
+	case "-updated_at":
+	default:
+		httpx.Abort(c, http.StatusBadRequest, i18n.FieldOneOf, "sort", "updated_at, -updated_at")
+		return
+	}
+
+	docs, total, err := h.store.ListDocuments(c.Request.Context(), filter)
+	if err != nil {
+		httpx.AbortInternal(c, err)
+		return
+	}
+	if !h.attachTags(c, docs) {
+		return
+	}
+	c.JSON(http.StatusOK, httpx.NewPagedResponse(docs, total, pagination))
+}
+
+func (h *Document) load(c *gin.Context) (*store.Document, bool) {
+	return loadDocument(c, h.store, h.policy)
+}
+
+// loadDocument 按路径参数 id 读取当前用户可读的文档；不存在或无权阅读时都返回 404，不暴露私有文档是否存在。
+func loadDocument(c *gin.Context, s *store.Store, policy *acl.Policy) (*store.Document, bool) {
+	id, ok := httpx.ParamID(c, "id")
+	if !ok {
+		return nil, false
+	}
+	doc, err := s.GetDocument(c.Request.Context(), id)
+	if errors.Is(err, store.ErrNotFound) {
+		httpx.Abort(c, http.StatusNotFound, i18n.DocNotFound)
+		return nil, false
+	}
+	if err != nil {
+		httpx.AbortInternal(c, err)
+		return nil, false
+	}
+	readable, err := policy.CanRead(c.Request.Context(), currentViewer(c), doc)
+	if err != nil {
+		httpx.AbortInternal(c, err)
+		return nil, false
+	}
+	if !readable {
+		httpx.Abort(c, http.StatusNotFound, i18n.DocNotFound)
+		return nil, false
+	}
+	return doc, true
+}
+
+func (h *Document) index(c *gin.Context, doc *store.Document) {
+	indexDocument(c, h.indexer, doc)
+}
+
+// indexDocument 把已发布的文档同步到检索后端，草稿与归档文档从检索后端移除；失败只记录日志，不影响已成功的写入。
+func indexDocument(c *gin.Context, indexer search.Indexer, doc *store.Document) {
+	if doc.Status != store.DocStatusPublished {
+		if err := indexer.Remove(c.Request.Context(), doc.ID); err != nil {
+			_ = c.Error(err)
+		}
+		return
+	}
+	if err := indexer.Index(c.Request.Context(), search.NewDocument(doc)); err != nil {
+		_ = c.Error(err)
+	}
+}
+
+// publish 通知订阅了 event 的 webhook；草稿对读者不可见，不触发通知。
+func (h *Document) publish(c *gin.Context, event string, doc *store.Document) {
+	if doc.Status != store.DocStatusDraft {
+		h.dispatch(c, event, doc)
+	}
+}
+
+// dispatch 通知订阅了 event 的 webhook；失败只记录日志，不影响已成功的写入。
+func (h *Document) dispatch(c *gin.Context, event string, doc *store.Document) {
+	if h.webhooks == nil {
+		return
+	}
+	if err := h.webhooks.PublishDocument(c.Request.Context(), event, doc); err != nil {
+		_ = c.Error(err)
+	}
+}
+
+// currentViewer 返回当前请求的访问者，未登录时 ID 为 0。
+func currentViewer(c *gin.Context) store.Viewer {
+	user, _ := httpx.CurrentUser(c)
+	return store.Viewer{ID: user.ID, Role: user.Role}
+}
+
+// requireWrite 要求当前用户能修改文档内容（全局角色或文档级 write 授权）。
+func (h *Document) requireWrite(c *gin.Context, doc *store.Document) bool {
+	writable, err := h.policy.CanWrite(c.Request.Context(), currentViewer(c), doc)
+	if err != nil {
+		httpx.AbortInternal(c, err)
+		return false
+	}
+	if !writable {
+		httpx.Abort(c, http.StatusForbidden, i18n.ForbiddenModifyDocument)
+		return false
+	}
+	return true
+}
+
+// requireManage 要求当前用户是管理员或身为作者的编辑者，删除、移动与调整权限都需要它。
+func (h *Document) requireManage(c *gin.Context, doc *store.Document) bool {
+	if h.policy.CanManage(currentViewer(c), doc) {
+		return true
+	}
+	httpx.Abort(c, http.StatusForbidden, i18n.ForbiddenManageDocument)
+	return false
+}
+
+// respond 填充标签后返回单篇文档；已填充过标签（刚写入过）时不再查询。
+func (h *Document) respond(c *gin.Context, doc *store.Document) {
+	if h.loadTags(c, doc) {
+		c.JSON(http.StatusOK, doc)
+	}
+}
+
+// loadTags 在文档尚未带标签时查询并填充，失败时已返回 500。
+func (h *Document) loadTags(c *gin.Context, doc *store.Document) bool {
+	if doc.Tags != nil {
+		return true
+	}
+	tags, err := h.store.ListDocumentTags(c.Request.Context(), []int64{doc.ID})
+	if err != nil {
+		httpx.AbortInternal(c, err)
+		return false
+	}
+	doc.Tags = tags[doc.ID]
+	return true
+}
+
+// attachTags 批量为列表中的文档填充标签，失败时已返回 500。
+func (h *Document) attachTags(c *gin.Context, docs []store.Document) bool {
+	ids := make([]int64, 0, len(docs))
+	for _, doc := range docs {
+		ids = append(ids, doc.ID)
+	}
+	tags, err := h.store.ListDocumentTags(c.Request.Context(), ids)
+	if err != nil {
+		httpx.AbortInternal(c, err)
+		return false
+	}
+	for i := range docs {
+		docs[i].Tags = tags[docs[i].ID]
+	}
+	return true
+}
+
+// tagNames 归一化请求中的标签，不合法时已返回 400。
+func tagNames(c *gin.Context, names []string) ([]string, bool) {
+	tags, err := store.NormalizeTagNames(names)
+	if err != nil {
+		httpx.Abort(c, http.StatusBadRequest, i18n.InvalidTag, store.MaxTagNameLength)
+		return nil, false
+	}
+	return tags, true
+}
+
+// expectedVersion 返回客户端修改所基于的版本号：请求体的 version 优先，其次是 If-Match 头；都没有时已返回 428。
+func expectedVersion(c *gin.Context, version *int) (int, bool) {
+	if version != nil {
+		return *version, true
+	}
+	number, present, err := httpx.IfMatchVersion(c.Request)
+	if err != nil {
+		httpx.Abort(c, http.StatusBadRequest, i18n.InvalidVersion, "If-Match")
+		return 0, false
+	}
+	if !present {
+		httpx.Abort(c, http.StatusPreconditionRequired, i18n.VersionRequired)
+		return 0, false
+	}
+	return number, true
+}
+
+// versionConflictResponse 是版本冲突时的响应，Document 是服务端当前的内容，供前端提示“文档已被他人修改”并对比差异。
+type versionConflictResponse struct {
+	httpx.ErrorResponse
+	Document *store.Document `json:"document"`
+}
+
+// abortVersionConflict 返回 409 与文档 current 的内容。
+func (h *Document) abortVersionConflict(c *gin.Context, current *store.Document) {
+	if !h.loadTags(c, current) {
+		return
+	}
+	c.AbortWithStatusJSON(http.StatusConflict, versionConflictResponse{
+		ErrorResponse: httpx.ErrorResponse{
+			Code:    i18n.CodeOf(i18n.VersionConflict),
+			Message: httpx.Localize(c, i18n.VersionConflict, current.Version),
+		},
+		Document: current,
+	})
+}
+
+// abortWriteError 在写入时发现文档已被并发修改（store.ErrVersionConflict）时重新读取并返回 409 与最新内容，
+// 其余错误交给 abortDocumentWriteError。
+func (h *Document) abortWriteError(c *gin.Context, doc *store.Document, err error) {
+	if !errors.Is(err, store.ErrVersionConflict) {
+		abortDocumentWriteError(c, err)
+		return
+	}
+	current, err := h.store.GetDocument(c.Request.Context(), doc.ID)
+	if err != nil {
+		httpx.AbortInternal(c, err)
+		return
+	}
+	h.abortVersionConflict(c, current)
+}
+
+func abortDocumentWriteError(c *gin.Context, err error) {
+	if errors.Is(err, store.ErrDuplicate) {
+		httpx.Abort(c, http.StatusConflict, i18n.SlugConflict)
+		return
+	}
+	httpx.AbortInternal(c, err)
+}
@@ -0,0 +1,435 @@
+package v1
+
+import (
+	"errors"
+	"net/http"
+	"sort"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/lifei6671/plaindoc/apps/server/internal/i18n"
+	"github.com/lifei6671/plaindoc/apps/server/internal/server/httpx"
+	"github.com/lifei6671/plaindoc/apps/server/internal/store"
+	"github.com/lifei6671/plaindoc/apps/server/internal/webhook"
+)
+
+const (
+	batchMove         = "move"
+	batchAddTags      = "add-tags"
+	batchRemoveTags   = "remove-tags"
+	batchDelete       = "delete"
+	batchChangeStatus = "change-status"
+)
+
+const (
+	batchStatusSucceeded = "succeeded"
+	batchStatusSkipped   = "skipped"
+	batchStatusFailed    = "failed"
+	// batchStatusRolledBack 表示 atomic 批次因其他文档失败而整体回滚，这篇文档没有被修改。
+	batchStatusRolledBack = "rolled_back"
+)
+
+// maxDocumentTags 与创建、更新文档时 tags 的 binding 上限一致。
+const maxDocumentTags = 20
+
+type batchDocumentsRequest struct {
+	IDs    []int64 `json:"doc_ids" binding:"required,min=1,dive,min=1"`
+	Action string  `json:"action" binding:"required,oneof=move add-tags remove-tags delete change-status"`
+	// ParentID 是 move 的目标父文档，省略表示移到顶层。
+	ParentID *int64 `json:"parent_id"`
+	// Tags 是 add-tags / remove-tags 要添加或移除的标签。
+	Tags []string `json:"tags" binding:"max=20"`
+	// Status 是 change-status 的目标状态。
+	Status string `json:"status" binding:"omitempty,oneof=draft archived"`
+	// Atomic 为 true 时任一文档失败都整体回滚，否则每篇文档单独提交。
+	Atomic bool `json:"atomic"`
+}
+
+type batchItem struct {
+	ID     int64  `json:"id"`
+	Status string `json:"status"`
+	Code   string `json:"code,omitempty"`
+	Reason string `json:"reason,omitempty"`
+}
+
+type batchReport struct {
+	// Committed 只在 atomic 批次回滚时为 false。
+	Committed bool        `json:"committed"`
+	Succeeded int         `json:"succeeded"`
+	Skipped   int         `json:"skipped"`
+	Failed    int         `json:"failed"`
+	Items     []batchItem `json:"items"`
+}
+
+// batchError 是单篇文档未被执行的原因：不存在或无权操作时为 skipped，其余为 failed。
+type batchError struct {
+	status string
+	key    string
+	args   []any
+}
+
+func (e *batchError) Error() string {
+	return e.key
+}
+
+func batchSkip(key string, args ...any) *batchError {
+	return &batchError{status: batchStatusSkipped, key: key, args: args}
+}
+
+func batchFail(key string, args ...any) *batchError {
+	return &batchError{status: batchStatusFailed, key: key, args: args}
+}
+
+// batchTarget 是通过权限校验、等待写入的文档。
+type batchTarget struct {
+	item *batchItem
+	doc  *store.Document
+	// tags 是 add-tags / remove-tags 之后文档的完整标签。
+	tags []string
+	// depth 是祖先个数，delete 先处理较深的文档，使同批的父子文档都能删除。
+	depth          int
+	previousStatus string
+}
+
+// Batch 对一组文档执行同一个操作，按 doc_ids 的顺序返回每篇文档的结果。
+// 不存在、不可读或无权操作的文档跳过；atomic=true 时任一文档失败都整体回滚，否则每篇文档单独提交。
+func (h *Document) Batch(c *gin.Context) {
+	var req batchDocumentsRequest
+	if !httpx.BindAndValidate(c, &req) {
+		return
+	}
+	if len(req.IDs) > h.maxBatch {
+		httpx.Abort(c, http.StatusBadRequest, i18n.BatchTooLarge, h.maxBatch)
+		return
+	}
+	var tags []string
+	switch req.Action {
+	case batchAddTags, batchRemoveTags:
+		if len(req.Tags) == 0 {
+			httpx.Abort(c, http.StatusBadRequest, i18n.FieldRequired, "tags")
+			return
+		}
+		var ok bool
+		if tags, ok = tagNames(c, req.Tags); !ok {
+			return
+		}
+	case batchChangeStatus:
+		if req.Status == "" {
+			httpx.Abort(c, http.StatusBadRequest, i18n.FieldRequired, "status")
+			return
+		}
+	}
+
+	ids := uniqueIDs(req.IDs)
+	report := &batchReport{Committed: true, Items: make([]batchItem, len(ids))}
+	targets, ok := h.batchTargets(c, &req, tags, ids, report)
+	if !ok {
+		return
+	}
+	if req.Atomic {
+		if !h.batchAtomic(c, &req, targets, report) {
+			return
+		}
+	} else {
+		h.batchEach(c, &req, targets)
+	}
+
+	for _, target := range targets {
+		if target.item.Status == batchStatusSucceeded {
+			h.batchEffect(c, req.Action, target)
+		}
+	}
+	for _, item := range report.Items {
+		switch item.Status {
+		case batchStatusSucceeded:
+			report.Succeeded++
+		case batchStatusSkipped:
+			report.Skipped++
+		case batchStatusFailed:
+			report.Failed++
+		}
+	}
+	c.JSON(http.StatusOK, report)
+}
+
+// batchTargets 读取文档并校验权限与操作的前置条件，未通过的文档直接记入报告；失败时已返回 500。
+func (h *Document) batchTargets(c *gin.Context, req *batchDocumentsRequest, tags []string, ids []int64, report *batchReport) ([]*batchTarget, bool) {
+	ctx := c.Request.Context()
+	validParents := map[string]bool{}
+	targets := make([]*batchTarget, 0, len(ids))
+	for i, id := range ids {
+		item := &report.Items[i]
+		item.ID = id
+		target, err := h.batchTarget(c, req, item, validParents)
+		var itemErr *batchError
+		if errors.As(err, &itemErr) {
+			h.batchRecord(c, item, itemErr)
+			continue
+		}
+		if err != nil {
+			httpx.AbortInternal(c, err)
+			return nil, false
+		}
+		targets = append(targets, target)
+	}
+
+	switch req.Action {
+	case batchAddTags, batchRemoveTags:
+		docIDs := make([]int64, 0, len(targets))
+		for _, target := range targets {
+			docIDs = append(docIDs, target.doc.ID)
+		}
+		existing, err := h.store.ListDocumentTags(ctx, docIDs)
+		if err != nil {
+			httpx.AbortInternal(c, err)
+			return nil, false
+		}
+		kept := targets[:0]
+		for _, target := range targets {
+			target.tags = mergeTags(existing[target.doc.ID], tags, req.Action == batchAddTags)
+			if len(target.tags) > maxDocumentTags {
+				h.batchRecord(c, target.item, batchFail(i18n.FieldMax, "tags", strconv.Itoa(maxDocumentTags)))
+				continue
+			}
+			kept = append(kept, target)
+		}
+		targets = kept
+	case batchDelete:
+		for _, target := range targets {
+			ancestors, err := h.store.ListAncestors(ctx, target.doc.ID)
+			if err != nil {
+				httpx.AbortInternal(c, err)
+				return nil, false
+			}
+			target.depth = len(ancestors)
+		}
+		sort.SliceStable(targets, func(i, j int) bool { return targets[i].depth > targets[j].depth })
+	}
+	return targets, true
+}
+
+// batchTarget 校验单篇文档，不可执行时返回 *batchError。
+func (h *Document) batchTarget(c *gin.Context, req *batchDocumentsRequest, item *batchItem, validParents map[string]bool) (*batchTarget, error) {
+	ctx := c.Request.Context()
+	viewer := currentViewer(c)
+	doc, err := h.store.GetDocument(ctx, item.ID)
+	if errors.Is(err, store.ErrNotFound) {
+		return nil, batchSkip(i18n.DocNotFound)
+	}
+	if err != nil {
+		return nil, err
+	}
+	readable, err := h.policy.CanRead(ctx, viewer, doc)
+	if err != nil {
+		return nil, err
+	}
+	if !readable {
+		return nil, batchSkip(i18n.DocNotFound)
+	}
+
+	switch req.Action {
+	case batchAddTags, batchRemoveTags:
+		writable, err := h.policy.CanWrite(ctx, viewer, doc)
+		if err != nil {
+			return nil, err
+		}
+		if !writable {
+			return nil, batchSkip(i18n.ForbiddenModifyDocument)
+		}
+	default:
+		if !h.policy.CanManage(viewer, doc) {
+			return nil, batchSkip(i18n.ForbiddenManageDocument)
+		}
+	}
+	// 与单篇接口一致：移动只改变位置，不受编辑锁限制；其余操作会修改或删除正在编辑的文档。
+	if req.Action != batchMove {
+		lock, err := lockedByOther(c, h.store, doc)
+		if err != nil {
+			return nil, err
+		}
+		if lock != nil {
+			return nil, batchFail(i18n.DocLocked, lock.UserName)
+		}
+	}
+	if req.Action == batchMove {
+		valid, checked := validParents[doc.Space]
+		if !checked {
+			if valid, err = h.validParent(c, doc.Space, req.ParentID); err != nil {
+				return nil, err
+			}
+			validParents[doc.Space] = valid
+		}
+		if !valid {
+			return nil, batchFail(i18n.InvalidParentDocument)
+		}
+	}
+	return &batchTarget{item: item, doc: doc, previousStatus: doc.Status}, nil
+}
+
+// batchAtomic 在一个事务中依次执行，遇到失败的文档时整体回滚；数据库出错时已返回 500。
+func (h *Document) batchAtomic(c *gin.Context, req *batchDocumentsRequest, targets []*batchTarget, report *batchReport) bool {
+	failed := false
+	for _, item := range report.Items {
+		failed = failed || item.Status == batchStatusFailed
+	}
+	if !failed {
+		ctx := c.Request.Context()
+		err := h.store.WithTx(ctx, func(tx *store.Store) error {
+			for _, target := range targets {
+				err := h.batchApply(c, tx, req, target)
+				var itemErr *batchError
+				if errors.As(err, &itemErr) {
+					h.batchRecord(c, target.item, itemErr)
+					if itemErr.status == batchStatusSkipped {
+						continue
+					}
+				}
+				if err != nil {
+					return err
+				}
+				target.item.Status = batchStatusSucceeded
+			}
+			return nil
+		})
+		var itemErr *batchError
+		if err != nil && !errors.As(err, &itemErr) {
+			httpx.AbortInternal(c, err)
+			return false
+		}
+		failed = err != nil
+	}
+	if failed {
+		report.Committed = false
+		for _, target := range targets {
+			if target.item.Status == "" || target.item.Status == batchStatusSucceeded {
+				target.item.Status = batchStatusRolledBack
+			}
+		}
+	}
+	return true
+}
+
+// batchEach 为每篇文档单独开启事务，一篇失败不影响其他文档。
+func (h *Document) batchEach(c *gin.Context, req *batchDocumentsRequest, targets []*batchTarget) {
+	ctx := c.Request.Context()
+	for _, target := range targets {
+		err := h.store.WithTx(ctx, func(tx *store.Store) error {
+			return h.batchApply(c, tx, req, target)
+		})
+		var itemErr *batchError
+		switch {
+		case errors.As(err, &itemErr):
+			h.batchRecord(c, target.item, itemErr)
+		case err != nil:
+			_ = c.Error(err)
+			h.batchRecord(c, target.item, batchFail(i18n.InternalError))
+		default:
+			target.item.Status = batchStatusSucceeded
+		}
+	}
+}
+
+// batchApply 在事务 tx 中对单篇文档执行操作。
+func (h *Document) batchApply(c *gin.Context, tx *store.Store, req *batchDocumentsRequest, target *batchTarget) error {
+	ctx := c.Request.Context()
+	doc := target.doc
+	var err error
+	switch req.Action {
+	case batchMove:
+		err = tx.MoveDocument(ctx, doc, req.ParentID, -1)
+		if errors.Is(err, store.ErrCycle) {
+			return batchFail(i18n.TreeCycle)
+		}
+	case batchAddTags, batchRemoveTags:
+		doc.Tags, err = tx.SetDocumentTags(ctx, doc.Space, doc.ID, target.tags)
+	case batchDelete:
+		hasChildren, err := tx.HasChildren(ctx, doc.ID)
+		if err != nil {
+			return err
+		}
+		if hasChildren {
+			return batchFail(i18n.DocHasChildren)
+		}
+		user, _ := httpx.CurrentUser(c)
+		err = tx.TrashDocument(ctx, doc.ID, user.ID)
+		if errors.Is(err, store.ErrNotFound) {
+			return batchSkip(i18n.DocNotFound)
+		}
+		return err
+	case batchChangeStatus:
+		if req.Status == doc.Status {
+			return nil
+		}
+		err = tx.SetDocumentStatus(ctx, doc, req.Status)
+	}
+	if errors.Is(err, store.ErrNotFound) {
+		return batchSkip(i18n.DocNotFound)
+	}
+	return err
+}
+
+// batchEffect 在写入提交后同步检索后端并通知 webhook，与单篇接口的行为一致。
+func (h *Document) batchEffect(c *gin.Context, action string, target *batchTarget) {
+	doc := target.doc
+	switch action {
+	case batchMove, batchAddTags, batchRemoveTags:
+		h.publish(c, webhook.EventDocUpdated, doc)
+	case batchDelete:
+		if err := h.indexer.Remove(c.Request.Context(), doc.ID); err != nil {
+			_ = c.Error(err)
+		}
+		h.publish(c, webhook.EventDocDeleted, doc)
+	case batchChangeStatus:
+		if target.previousStatus == doc.Status {
+			return
+		}
+		h.index(c, doc)
+		if target.previousStatus != store.DocStatusDraft || doc.Status != store.DocStatusDraft {
+			h.dispatch(c, webhook.EventDocUpdated, doc)
+		}
+	}
+}
+
+// batchRecord 把未执行的原因按当前语言写入结果。
+func (h *Document) batchRecord(c *gin.Context, item *batchItem, err *batchError) {
+	item.Status, item.Code, item.Reason = err.status, i18n.CodeOf(err.key), httpx.Localize(c, err.key, err.args...)
+}
+
+// mergeTags 在文档现有的标签上添加或移除 names（均已归一化），保留现有标签的顺序。
+func mergeTags(existing []store.Tag, names []string, add bool) []string {
+	removed := make(map[string]bool, len(names))
+	if !add {
+		for _, name := range names {
+			removed[name] = true
+		}
+	}
+	merged := make([]string, 0, len(existing)+len(names))
+	seen := make(map[string]bool, len(existing))
+	for _, tag := range existing {
+		if !removed[tag.Name] {
+			merged = append(merged, tag.Name)
+		}
+		seen[tag.Name] = true
+	}
+	if add {
+		for _, name := range names {
+			if !seen[name] {
+				merged = append(merged, name)
+			}
+		}
+	}
+	return merged
+}
+
+// uniqueIDs 去掉重复的 ID，保留首次出现的顺序。
+func uniqueIDs(ids []int64) []int64 {
+	seen := make(map[int64]bool, len(ids))
+	unique := make([]int64, 0, len(ids))
+	for _, id := range ids {
+		if !seen[id] {
+			seen[id] = true
+			unique = append(unique, id)
+		}
+	}
+	return unique
+}
@@ -0,0 +1,165 @@
+package v1
+
+import (
+	"errors"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/lifei6671/plaindoc/apps/server/internal/i18n"
+	"github.com/lifei6671/plaindoc/apps/server/internal/server/httpx"
+	"github.com/lifei6671/plaindoc/apps/server/internal/store"
+	"github.com/lifei6671/plaindoc/apps/server/internal/webhook"
+)
+
+type publishDocumentRequest struct {
+	// Summary 是发布草稿时生成的版本说明。
+	Summary string `json:"summary" binding:"max=255"`
+}
+
+// Publish 发布文档：有草稿时把草稿写回文档并生成新版本，草稿或归档状态的文档改为已发布。
+// 文档已发布且没有草稿时直接返回当前内容。
+func (h *Document) Publish(c *gin.Context) {
+	doc, ok := h.load(c)
+	if !ok || !h.requireWrite(c, doc) || !h.requireUnlocked(c, doc) {
+		return
+	}
+	var req publishDocumentRequest
+	// 请求体可以省略。
+	if c.Request.ContentLength != 0 {
+		if !httpx.BindAndValidate(c, &req) {
+			return
+		}
+	}
+
+	user, _ := httpx.CurrentUser(c)
+	published, err := h.store.PublishDocument(c.Request.Context(), doc, user.ID, strings.TrimSpace(req.Summary))
+	if err != nil {
+		h.abortWriteError(c, doc, err)
+		return
+	}
+	if published {
+		h.index(c, doc)
+		h.publish(c, webhook.EventDocPublished, doc)
+	}
+	h.respond(c, doc)
+}
+
+type scheduleDocumentRequest struct {
+	// PublishAt 是 RFC 3339 格式、带时区偏移的发布时间，null 或省略时取消定时发布。
+	PublishAt *time.Time `json:"publish_at"`
+}
+
+// Schedule 设置或取消文档的定时发布：到期后由后台任务按 Publish 的规则发布，此前草稿对读者不可见。
+// 发布时间必须晚于当前时间，按 UTC 保存；已发布且没有草稿的文档没有可发布的内容，返回 409。
+func (h *Document) Schedule(c *gin.Context) {
+	doc, ok := h.load(c)
+	if !ok || !h.requireWrite(c, doc) || !h.requireUnlocked(c, doc) {
+		return
+	}
+	var req scheduleDocumentRequest
+	if !httpx.BindAndValidate(c, &req) {
+		return
+	}
+	ctx := c.Request.Context()
+	if req.PublishAt != nil {
+		if !req.PublishAt.After(time.Now()) {
+			httpx.Abort(c, http.StatusBadRequest, i18n.InvalidSchedule)
+			return
+		}
+		if doc.Status == store.DocStatusPublished {
+			_, err := h.store.GetDocumentDraft(ctx, doc.ID)
+			if errors.Is(err, store.ErrNotFound) {
+				httpx.Abort(c, http.StatusConflict, i18n.NothingToPublish)
+				return
+			}
+			if err != nil {
+				httpx.AbortInternal(c, err)
+				return
+			}
+		}
+	}
+	if err := h.store.ScheduleDocument(ctx, doc, req.PublishAt); err != nil {
+		abortDocumentWriteError(c, err)
+		return
+	}
+	h.respond(c, doc)
+}
+
+// DiscardDraft 丢弃文档尚未发布的草稿。
+func (h *Document) DiscardDraft(c *gin.Context) {
+	doc, ok := h.load(c)
+	if !ok || !h.requireWrite(c, doc) || !h.requireUnlocked(c, doc) {
+		return
+	}
+	err := h.store.DiscardDocumentDraft(c.Request.Context(), doc.ID)
+	if errors.Is(err, store.ErrNotFound) {
+		httpx.Abort(c, http.StatusNotFound, i18n.DraftNotFound)
+		return
+	}
+	if err != nil {
+		httpx.AbortInternal(c, err)
+		return
+	}
+	c.Status(http.StatusNoContent)
+}
+
+// saveDraft 把标题与正文的修改保存为草稿，未提供的字段沿用已有草稿或已发布的内容。
+func (h *Document) saveDraft(c *gin.Context, doc *store.Document, title, content *string) {
+	ctx := c.Request.Context()
+	user, _ := httpx.CurrentUser(c)
+	draft, err := h.store.GetDocumentDraft(ctx, doc.ID)
+	if errors.Is(err, store.ErrNotFound) {
+		draft, err = &store.DocumentDraft{DocID: doc.ID, Title: doc.Title, Content: doc.Content}, nil
+	}
+	if err != nil {
+		httpx.AbortInternal(c, err)
+		return
+	}
+	if title != nil {
+		draft.Title = *title
+	}
+	if content != nil {
+		draft.Content = *content
+	}
+	draft.EditorID = user.ID
+	if err := h.store.SaveDocumentDraft(ctx, draft); err != nil {
+		httpx.AbortInternal(c, err)
+		return
+	}
+	applyDraft(doc, draft)
+	h.respond(c, doc)
+}
+
+// loadDraft 在请求参数 draft=true 时用草稿覆盖文档的标题与正文，需要写权限；没有草稿时保持已发布的内容。
+func (h *Document) loadDraft(c *gin.Context, doc *store.Document) bool {
+	if c.Query("draft") != "true" {
+		return true
+	}
+	if !h.requireWrite(c, doc) {
+		return false
+	}
+	draft, err := h.store.GetDocumentDraft(c.Request.Context(), doc.ID)
+	if errors.Is(err, store.ErrNotFound) {
+		return true
+	}
+	if err != nil {
+		httpx.AbortInternal(c, err)
+		return false
+	}
+	applyDraft(doc, draft)
+	return true
+}
+
+func applyDraft(doc *store.Document, draft *store.DocumentDraft) {
+	doc.Title, doc.Content, doc.DraftUpdatedAt = draft.Title, draft.Content, &draft.UpdatedAt
+}
+
+// lastModified 返回文档（按草稿读取时包括草稿）最近一次修改的时间。
+func lastModified(doc *store.Document) time.Time {
+	if doc.DraftUpdatedAt != nil && doc.DraftUpdatedAt.After(doc.UpdatedAt) {
+		return *doc.DraftUpdatedAt
+	}
+	return doc.UpdatedAt
+}
@@ -0,0 +1,50 @@
+package v1
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/lifei6671/plaindoc/apps/server/internal/server/httpx"
+	"github.com/lifei6671/plaindoc/apps/server/internal/store"
+	"github.com/lifei6671/plaindoc/apps/server/internal/wikilink"
+)
+
+// Backlinks 按更新时间倒序分页返回正文中用 [[slug]] 链接到当前文档、且当前用户可读的文档（不含正文）。
+func (h *Document) Backlinks(c *gin.Context) {
+	doc, ok := h.load(c)
+	if !ok {
+		return
+	}
+	pagination, ok := httpx.ParsePagination(c)
+	if !ok {
+		return
+	}
+	docs, total, err := h.store.ListBacklinks(c.Request.Context(), currentViewer(c), doc.ID, pagination.Limit(), pagination.Offset())
+	if err != nil {
+		httpx.AbortInternal(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, httpx.NewPagedResponse(docs, total, pagination))
+}
+
+// resolveWikiLinks 给渲染结果中的内部链接填上目标文档的地址，目标不存在或 viewer 不可读时标记为断链。
+// 链接按 slug 指向 space 内的文档，每次读取时解析，目标的增删与改名无需让渲染缓存失效。
+func resolveWikiLinks(ctx context.Context, s *store.Store, viewer store.Viewer, space string, html string,
+	documentURL func(id int64, space string, slug string) string) (string, error) {
+	slugs := wikilink.Slugs(html)
+	if len(slugs) == 0 {
+		return html, nil
+	}
+	targets, err := s.ResolveDocumentSlugs(ctx, viewer, space, slugs)
+	if err != nil {
+		return "", err
+	}
+	return wikilink.Resolve(html, func(slug string) (string, bool) {
+		id, ok := targets[slug]
+		if !ok {
+			return "", false
+		}
+		return documentURL(id, space, slug), true
+	}), nil
+}
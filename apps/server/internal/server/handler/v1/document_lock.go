@@ -0,0 +1,109 @@
+package v1
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/lifei6671/plaindoc/apps/server/internal/auth"
+	"github.com/lifei6671/plaindoc/apps/server/internal/i18n"
+	"github.com/lifei6671/plaindoc/apps/server/internal/server/httpx"
+	"github.com/lifei6671/plaindoc/apps/server/internal/store"
+)
+
+// GetLock 返回文档当前的编辑锁，没有人编辑时 lock 为 null；前端据此把文档切换为只读并提示正在编辑的用户。
+func (h *Document) GetLock(c *gin.Context) {
+	doc, ok := h.load(c)
+	if !ok {
+		return
+	}
+	lock, err := h.store.GetDocumentLock(c.Request.Context(), doc.ID)
+	if err != nil && !errors.Is(err, store.ErrNotFound) {
+		httpx.AbortInternal(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"lock": lock})
+}
+
+// Lock 为当前用户获取文档的编辑锁，已持有时续期；编辑期间前端需在锁到期前定期调用作为心跳，
+// 持锁者断线后锁在到期时自动释放。锁由其他用户持有时返回 409。
+func (h *Document) Lock(c *gin.Context) {
+	doc, ok := h.load(c)
+	if !ok || !h.requireWrite(c, doc) {
+		return
+	}
+	user, _ := httpx.CurrentUser(c)
+	lock, err := h.store.AcquireDocumentLock(c.Request.Context(), doc.ID, user.ID, h.lockTTL)
+	if errors.Is(err, store.ErrDocumentLocked) {
+		httpx.Abort(c, http.StatusConflict, i18n.DocLocked, lock.UserName)
+		return
+	}
+	if err != nil {
+		httpx.AbortInternal(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, lock)
+}
+
+// Unlock 释放文档的编辑锁：持锁者释放自己的锁，管理员可以强制解除他人的锁；没有锁时直接返回 204。
+func (h *Document) Unlock(c *gin.Context) {
+	doc, ok := h.load(c)
+	if !ok {
+		return
+	}
+	ctx := c.Request.Context()
+	lock, err := h.store.GetDocumentLock(ctx, doc.ID)
+	if errors.Is(err, store.ErrNotFound) {
+		c.Status(http.StatusNoContent)
+		return
+	}
+	if err != nil {
+		httpx.AbortInternal(c, err)
+		return
+	}
+
+	user, _ := httpx.CurrentUser(c)
+	switch {
+	case lock.UserID == user.ID:
+		err = h.store.ReleaseDocumentLock(ctx, doc.ID, user.ID)
+	case user.Role == auth.RoleAdmin:
+		err = h.store.DeleteDocumentLock(ctx, doc.ID)
+	default:
+		httpx.Abort(c, http.StatusForbidden, i18n.ForbiddenReleaseLock)
+		return
+	}
+	if err != nil {
+		httpx.AbortInternal(c, err)
+		return
+	}
+	c.Status(http.StatusNoContent)
+}
+
+// requireUnlocked 拒绝在其他用户持有编辑锁期间修改或删除文档，返回 409；没有锁或锁由当前用户持有时放行。
+func (h *Document) requireUnlocked(c *gin.Context, doc *store.Document) bool {
+	lock, err := lockedByOther(c, h.store, doc)
+	if err != nil {
+		httpx.AbortInternal(c, err)
+		return false
+	}
+	if lock != nil {
+		httpx.Abort(c, http.StatusConflict, i18n.DocLocked, lock.UserName)
+		return false
+	}
+	return true
+}
+
+// lockedByOther 返回其他用户持有的 doc 的编辑锁，没有锁或锁由当前用户持有时返回 nil；s 可以是事务中的 Store。
+func lockedByOther(c *gin.Context, s *store.Store, doc *store.Document) (*store.DocumentLock, error) {
+	lock, err := s.GetDocumentLock(c.Request.Context(), doc.ID)
+	if errors.Is(err, store.ErrNotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if user, _ := httpx.CurrentUser(c); lock.UserID == user.ID {
+		return nil, nil
+	}
+	return lock, nil
+}
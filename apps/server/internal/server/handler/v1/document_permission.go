@@ -0,0 +1,160 @@
+package v1
+
+import (
+	"errors"
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/lifei6671/plaindoc/apps/server/internal/auth"
+	"github.com/lifei6671/plaindoc/apps/server/internal/i18n"
+	"github.com/lifei6671/plaindoc/apps/server/internal/server/httpx"
+	"github.com/lifei6671/plaindoc/apps/server/internal/store"
+)
+
+// permissionsResponse 描述文档当前生效的权限：继承时 SourceID 为提供设置的祖先，IsPrivate 与 Items 都取自它。
+type permissionsResponse struct {
+	DocID              int64                 `json:"doc_id"`
+	InheritPermissions bool                  `json:"inherit_permissions"`
+	SourceID           int64                 `json:"source_id"`
+	IsPrivate          bool                  `json:"is_private"`
+	Items              []store.DocPermission `json:"items"`
+}
+
+type grantPermissionRequest struct {
+	UserID     *int64 `json:"user_id"`
+	Role       string `json:"role"`
+	Permission string `json:"permission" binding:"required,oneof=read write"`
+}
+
+type updateACLRequest struct {
+	IsPrivate          *bool `json:"is_private"`
+	InheritPermissions *bool `json:"inherit_permissions"`
+}
+
+// ListPermissions 返回文档生效的私有设置与授权列表。
+func (h *Document) ListPermissions(c *gin.Context) {
+	doc, ok := h.load(c)
+	if !ok || !h.requireManage(c, doc) {
+		return
+	}
+	h.respondPermissions(c, doc)
+}
+
+// GrantPermission 给某个用户（user_id）或某个全局角色（role）授予 read/write 权限，重复授权会覆盖原级别。
+func (h *Document) GrantPermission(c *gin.Context) {
+	doc, ok := h.load(c)
+	if !ok || !h.requireManage(c, doc) {
+		return
+	}
+
+	var req grantPermissionRequest
+	if !httpx.BindAndValidate(c, &req) {
+		return
+	}
+	role := strings.ToLower(strings.TrimSpace(req.Role))
+	if (req.UserID == nil) == (role == "") {
+		httpx.Abort(c, http.StatusBadRequest, i18n.GranteeRequired)
+		return
+	}
+	if role != "" && !auth.ValidRole(role) {
+		httpx.Abort(c, http.StatusBadRequest, i18n.InvalidRole, strings.Join(auth.Roles, ", "))
+		return
+	}
+
+	ctx := c.Request.Context()
+	if req.UserID != nil {
+		_, err := h.store.GetUser(ctx, *req.UserID)
+		if errors.Is(err, store.ErrNotFound) {
+			httpx.Abort(c, http.StatusBadRequest, i18n.UserNotFound)
+			return
+		}
+		if err != nil {
+			httpx.AbortInternal(c, err)
+			return
+		}
+	}
+
+	user, _ := httpx.CurrentUser(c)
+	perm := &store.DocPermission{UserID: req.UserID, Role: role, Permission: req.Permission, GrantedBy: &user.ID}
+	err := h.store.GrantDocPermission(ctx, doc, perm)
+	if errors.Is(err, store.ErrPermissionsInherited) {
+		httpx.Abort(c, http.StatusConflict, i18n.PermissionsInherited)
+		return
+	}
+	if err != nil {
+		httpx.AbortInternal(c, err)
+		return
+	}
+	c.JSON(http.StatusCreated, perm)
+}
+
+func (h *Document) RevokePermission(c *gin.Context) {
+	doc, ok := h.load(c)
+	if !ok || !h.requireManage(c, doc) {
+		return
+	}
+	permissionID, ok := httpx.ParamID(c, "pid")
+	if !ok {
+		return
+	}
+	err := h.store.RevokeDocPermission(c.Request.Context(), doc.ID, permissionID)
+	if errors.Is(err, store.ErrNotFound) {
+		httpx.Abort(c, http.StatusNotFound, i18n.PermissionNotFound)
+		return
+	}
+	if err != nil {
+		httpx.AbortInternal(c, err)
+		return
+	}
+	c.Status(http.StatusNoContent)
+}
+
+// UpdateACL 修改文档的私有与继承设置，继承设置的变化会同步到继承它的子孙文档。
+func (h *Document) UpdateACL(c *gin.Context) {
+	doc, ok := h.load(c)
+	if !ok || !h.requireManage(c, doc) {
+		return
+	}
+
+	var req updateACLRequest
+	if !httpx.BindAndValidate(c, &req) {
+		return
+	}
+	isPrivate, inherit := doc.IsPrivate, doc.InheritPermissions
+	if req.IsPrivate != nil {
+		isPrivate = *req.IsPrivate
+	}
+	if req.InheritPermissions != nil {
+		inherit = *req.InheritPermissions
+	}
+	if err := h.store.UpdateDocumentACL(c.Request.Context(), doc, isPrivate, inherit); err != nil {
+		httpx.AbortInternal(c, err)
+		return
+	}
+	h.respondPermissions(c, doc)
+}
+
+func (h *Document) respondPermissions(c *gin.Context, doc *store.Document) {
+	ctx := c.Request.Context()
+	source := doc
+	if doc.ACLDocID != nil {
+		var err error
+		if source, err = h.store.GetDocument(ctx, *doc.ACLDocID); err != nil {
+			httpx.AbortInternal(c, err)
+			return
+		}
+	}
+	items, err := h.store.ListDocPermissions(ctx, source.ID)
+	if err != nil {
+		httpx.AbortInternal(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, permissionsResponse{
+		DocID:              doc.ID,
+		InheritPermissions: doc.InheritPermissions,
+		SourceID:           source.ID,
+		IsPrivate:          source.IsPrivate,
+		Items:              items,
+	})
+}
@@ -0,0 +1,44 @@
+package v1
+
+import (
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/lifei6671/plaindoc/apps/server/internal/i18n"
+	"github.com/lifei6671/plaindoc/apps/server/internal/server/httpx"
+	"github.com/lifei6671/plaindoc/apps/server/internal/slug"
+	"github.com/lifei6671/plaindoc/apps/server/internal/store"
+)
+
+// SlugAvailable 供前端实时校验 slug：不合法时返回 400；已被占用时 available 为 false，并给出可用的建议值。
+func (h *Document) SlugAvailable(c *gin.Context) {
+	value := c.Query("slug")
+	if value == "" {
+		httpx.Abort(c, http.StatusBadRequest, i18n.FieldRequired, "slug")
+		return
+	}
+	if !validSlug(c, value) {
+		return
+	}
+	space := strings.TrimSpace(c.DefaultQuery("space", store.DefaultSpace))
+	free, err := h.store.FreeSlug(c.Request.Context(), space, value)
+	if err != nil {
+		httpx.AbortInternal(c, err)
+		return
+	}
+	resp := gin.H{"slug": value, "space": space, "available": free == value}
+	if free != value {
+		resp["suggestion"] = free
+	}
+	c.JSON(http.StatusOK, resp)
+}
+
+// validSlug 校验手填的 slug，不合法时已返回 400。
+func validSlug(c *gin.Context, value string) bool {
+	if err := slug.Validate(value); err != nil {
+		httpx.Abort(c, http.StatusBadRequest, i18n.InvalidSlug, slug.MaxLength)
+		return false
+	}
+	return true
+}
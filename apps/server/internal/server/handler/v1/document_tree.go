@@ -0,0 +1,176 @@
+package v1
+
+import (
+	"errors"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/lifei6671/plaindoc/apps/server/internal/i18n"
+	"github.com/lifei6671/plaindoc/apps/server/internal/server/httpx"
+	"github.com/lifei6671/plaindoc/apps/server/internal/store"
+	"github.com/lifei6671/plaindoc/apps/server/internal/webhook"
+)
+
+// treeNode 是目录树中的一个节点，只包含导航所需的字段。
+type treeNode struct {
+	ID        int64       `json:"id"`
+	Title     string      `json:"title"`
+	Slug      string      `json:"slug"`
+	SortOrder int64       `json:"sort_order"`
+	Status    string      `json:"status"`
+	UpdatedAt time.Time   `json:"updated_at"`
+	Children  []*treeNode `json:"children"`
+}
+
+// breadcrumbNode 是面包屑中的一个节点；当前用户无权阅读的祖先只返回 id 与 restricted，不暴露标题与 slug。
+type breadcrumbNode struct {
+	ID         int64  `json:"id"`
+	Title      string `json:"title,omitempty"`
+	Slug       string `json:"slug,omitempty"`
+	Restricted bool   `json:"restricted,omitempty"`
+}
+
+type moveDocumentRequest struct {
+	ParentID *int64 `json:"parent_id"`
+	// Position 是移动后在新的同级节点中的下标，省略时放到末尾。
+	Position *int `json:"position" binding:"omitempty,min=0"`
+}
+
+// Tree 一次查询读取空间内全部已发布的文档并在内存中组装为嵌套目录树；draft=true 时包括自己的草稿。
+func (h *Document) Tree(c *gin.Context) {
+	space := strings.TrimSpace(c.DefaultQuery("space", store.DefaultSpace))
+	statuses := []string{store.DocStatusPublished}
+	if c.Query("draft") == "true" {
+		statuses = append(statuses, store.DocStatusDraft)
+	}
+	docs, err := h.store.ListDocumentTree(c.Request.Context(), space, currentViewer(c), statuses)
+	if err != nil {
+		httpx.AbortInternal(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"space": space, "items": buildTree(docs)})
+}
+
+// Breadcrumb 返回从根节点到当前文档的路径，每次请求按当前的父子关系计算，移动后立即反映新位置。
+func (h *Document) Breadcrumb(c *gin.Context) {
+	doc, ok := h.load(c)
+	if !ok {
+		return
+	}
+	ctx := c.Request.Context()
+	ancestors, err := h.store.ListAncestors(ctx, doc.ID)
+	if err != nil {
+		httpx.AbortInternal(c, err)
+		return
+	}
+	viewer := currentViewer(c)
+	items := make([]breadcrumbNode, 0, len(ancestors)+1)
+	for i := range ancestors {
+		readable, err := h.policy.CanRead(ctx, viewer, &ancestors[i])
+		if err != nil {
+			httpx.AbortInternal(c, err)
+			return
+		}
+		if !readable {
+			items = append(items, breadcrumbNode{ID: ancestors[i].ID, Restricted: true})
+			continue
+		}
+		items = append(items, breadcrumbNode{ID: ancestors[i].ID, Title: ancestors[i].Title, Slug: ancestors[i].Slug})
+	}
+	items = append(items, breadcrumbNode{ID: doc.ID, Title: doc.Title, Slug: doc.Slug})
+	c.JSON(http.StatusOK, gin.H{"space": doc.Space, "items": items})
+}
+
+// Move 修改文档的父节点与同级排序。
+func (h *Document) Move(c *gin.Context) {
+	doc, ok := h.load(c)
+	if !ok || !h.requireManage(c, doc) {
+		return
+	}
+
+	var req moveDocumentRequest
+	if !httpx.BindAndValidate(c, &req) {
+		return
+	}
+	if !h.checkParent(c, doc.Space, req.ParentID) {
+		return
+	}
+	position := -1
+	if req.Position != nil {
+		position = *req.Position
+	}
+
+	err := h.store.MoveDocument(c.Request.Context(), doc, req.ParentID, position)
+	if errors.Is(err, store.ErrCycle) {
+		httpx.Abort(c, http.StatusConflict, i18n.TreeCycle)
+		return
+	}
+	if err != nil {
+		httpx.AbortInternal(c, err)
+		return
+	}
+	h.publish(c, webhook.EventDocUpdated, doc)
+	h.respond(c, doc)
+}
+
+// checkParent 校验父节点存在、当前用户可读且与文档位于同一空间，parentID 为 nil 表示顶层。
+func (h *Document) checkParent(c *gin.Context, space string, parentID *int64) bool {
+	valid, err := h.validParent(c, space, parentID)
+	if err != nil {
+		httpx.AbortInternal(c, err)
+		return false
+	}
+	if !valid {
+		httpx.Abort(c, http.StatusBadRequest, i18n.InvalidParentDocument)
+		return false
+	}
+	return true
+}
+
+// validParent 是不写响应的 checkParent，供批量操作逐篇判断。
+func (h *Document) validParent(c *gin.Context, space string, parentID *int64) (bool, error) {
+	if parentID == nil {
+		return true, nil
+	}
+	ctx := c.Request.Context()
+	parent, err := h.store.GetDocument(ctx, *parentID)
+	if errors.Is(err, store.ErrNotFound) {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+	readable, err := h.policy.CanRead(ctx, currentViewer(c), parent)
+	return err == nil && readable && parent.Space == space, err
+}
+
+// buildTree 把按同级顺序排列的平铺列表组装为嵌套结构；父节点缺失的文档挂到顶层。
+func buildTree(docs []store.Document) []*treeNode {
+	nodes := make(map[int64]*treeNode, len(docs))
+	for _, doc := range docs {
+		nodes[doc.ID] = &treeNode{
+			ID:        doc.ID,
+			Title:     doc.Title,
+			Slug:      doc.Slug,
+			SortOrder: doc.SortOrder,
+			Status:    doc.Status,
+			UpdatedAt: doc.UpdatedAt,
+			Children:  []*treeNode{},
+		}
+	}
+
+	roots := []*treeNode{}
+	for _, doc := range docs {
+		node := nodes[doc.ID]
+		if doc.ParentID != nil {
+			if parent, ok := nodes[*doc.ParentID]; ok {
+				parent.Children = append(parent.Children, node)
+				continue
+			}
+		}
+		roots = append(roots, node)
+	}
+	return roots
+}
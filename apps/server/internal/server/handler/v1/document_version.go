@@ -0,0 +1,142 @@
+package v1
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/lifei6671/plaindoc/apps/server/internal/diff"
+	"github.com/lifei6671/plaindoc/apps/server/internal/i18n"
+	"github.com/lifei6671/plaindoc/apps/server/internal/server/httpx"
+	"github.com/lifei6671/plaindoc/apps/server/internal/store"
+	"github.com/lifei6671/plaindoc/apps/server/internal/webhook"
+)
+
+type diffResponse struct {
+	From    int         `json:"from"`
+	To      int         `json:"to"`
+	Added   int         `json:"added"`
+	Removed int         `json:"removed"`
+	Lines   []diff.Line `json:"lines"`
+}
+
+// ListVersions 按版本号倒序分页返回历史版本，不包含正文。
+func (h *Document) ListVersions(c *gin.Context) {
+	doc, ok := h.load(c)
+	if !ok {
+		return
+	}
+	pagination, ok := httpx.ParsePagination(c)
+	if !ok {
+		return
+	}
+	versions, total, err := h.store.ListDocumentVersions(c.Request.Context(), doc.ID, pagination.Limit(), pagination.Offset())
+	if err != nil {
+		httpx.AbortInternal(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, httpx.NewPagedResponse(versions, total, pagination))
+}
+
+func (h *Document) GetVersion(c *gin.Context) {
+	doc, ok := h.load(c)
+	if !ok {
+		return
+	}
+	number, ok := versionParam(c, c.Param("v"), "v")
+	if !ok {
+		return
+	}
+	version, ok := h.loadVersion(c, doc.ID, number)
+	if !ok {
+		return
+	}
+	c.JSON(http.StatusOK, version)
+}
+
+// Revert 把文档内容恢复为指定版本，回滚本身会生成一个新版本而不是删除中间版本。
+func (h *Document) Revert(c *gin.Context) {
+	doc, ok := h.load(c)
+	if !ok || !h.requireWrite(c, doc) || !h.requireUnlocked(c, doc) {
+		return
+	}
+	number, ok := versionParam(c, c.Param("v"), "v")
+	if !ok {
+		return
+	}
+	version, ok := h.loadVersion(c, doc.ID, number)
+	if !ok {
+		return
+	}
+
+	doc.Title = version.Title
+	doc.Content = version.Content
+	user, _ := httpx.CurrentUser(c)
+	summary := fmt.Sprintf("revert to version %d", version.Version)
+	if err := h.store.UpdateDocument(c.Request.Context(), doc, user.ID, summary); err != nil {
+		h.abortWriteError(c, doc, err)
+		return
+	}
+	h.index(c, doc)
+	h.publish(c, webhook.EventDocUpdated, doc)
+	h.respond(c, doc)
+}
+
+// Diff 返回 from 与 to 两个版本之间的行级差异。
+func (h *Document) Diff(c *gin.Context) {
+	doc, ok := h.load(c)
+	if !ok {
+		return
+	}
+	from, ok := versionParam(c, c.Query("from"), "from")
+	if !ok {
+		return
+	}
+	to, ok := versionParam(c, c.Query("to"), "to")
+	if !ok {
+		return
+	}
+	oldVersion, ok := h.loadVersion(c, doc.ID, from)
+	if !ok {
+		return
+	}
+	newVersion, ok := h.loadVersion(c, doc.ID, to)
+	if !ok {
+		return
+	}
+
+	resp := diffResponse{From: from, To: to, Lines: diff.Lines(oldVersion.Content, newVersion.Content)}
+	for _, line := range resp.Lines {
+		switch line.Op {
+		case diff.OpInsert:
+			resp.Added++
+		case diff.OpDelete:
+			resp.Removed++
+		}
+	}
+	c.JSON(http.StatusOK, resp)
+}
+
+func (h *Document) loadVersion(c *gin.Context, docID int64, number int) (*store.DocumentVersion, bool) {
+	version, err := h.store.GetDocumentVersion(c.Request.Context(), docID, number)
+	if errors.Is(err, store.ErrNotFound) {
+		httpx.Abort(c, http.StatusNotFound, i18n.VersionNotFound, number)
+		return nil, false
+	}
+	if err != nil {
+		httpx.AbortInternal(c, err)
+		return nil, false
+	}
+	return version, true
+}
+
+func versionParam(c *gin.Context, raw string, name string) (int, bool) {
+	number, err := strconv.Atoi(raw)
+	if err != nil || number < 1 {
+		httpx.Abort(c, http.StatusBadRequest, i18n.InvalidVersion, name)
+		return 0, false
+	}
+	return number, true
+}
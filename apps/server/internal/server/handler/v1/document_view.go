@@ -0,0 +1,51 @@
+package v1
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/lifei6671/plaindoc/apps/server/internal/i18n"
+	"github.com/lifei6671/plaindoc/apps/server/internal/server/httpx"
+	"github.com/lifei6671/plaindoc/apps/server/internal/store"
+	"github.com/lifei6671/plaindoc/apps/server/internal/views"
+)
+
+// popularPeriods 是热门文档支持的统计周期及对应的天数（含当天）。
+var popularPeriods = map[string]int{"1d": 1, "7d": 7, "30d": 30, "90d": views.RetentionDays}
+
+// Popular 按统计周期内的浏览量倒序分页返回当前访问者可读的已发布文档，period 默认 7d。
+func (h *Document) Popular(c *gin.Context) {
+	period := c.DefaultQuery("period", "7d")
+	days, ok := popularPeriods[period]
+	if !ok {
+		httpx.Abort(c, http.StatusBadRequest, i18n.FieldOneOf, "period", "1d, 7d, 30d, 90d")
+		return
+	}
+	pagination, ok := httpx.ParsePagination(c)
+	if !ok {
+		return
+	}
+	since := views.Today().AddDate(0, 0, 1-days)
+	docs, total, err := h.store.ListPopularDocuments(c.Request.Context(), currentViewer(c), since, pagination.Limit(), pagination.Offset())
+	if err != nil {
+		httpx.AbortInternal(c, err)
+		return
+	}
+	if !h.attachTags(c, docs) {
+		return
+	}
+	c.JSON(http.StatusOK, httpx.NewPagedResponse(docs, total, pagination))
+}
+
+// recordView 计入一次浏览：登录用户按用户去重，匿名访问按 IP 去重。
+func (h *Document) recordView(c *gin.Context, doc *store.Document) {
+	if h.views == nil {
+		return
+	}
+	visitor := "ip:" + httpx.ClientIP(c)
+	if user, ok := httpx.CurrentUser(c); ok {
+		visitor = "user:" + strconv.FormatInt(user.ID, 10)
+	}
+	h.views.Record(doc.ID, visitor)
+}
@@ -0,0 +1,476 @@
+package v1
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/lifei6671/plaindoc/apps/server/internal/acl"
+	"github.com/lifei6671/plaindoc/apps/server/internal/config"
+	"github.com/lifei6671/plaindoc/apps/server/internal/export"
+	"github.com/lifei6671/plaindoc/apps/server/internal/i18n"
+	"github.com/lifei6671/plaindoc/apps/server/internal/jobs"
+	"github.com/lifei6671/plaindoc/apps/server/internal/render"
+	"github.com/lifei6671/plaindoc/apps/server/internal/server/httpx"
+	"github.com/lifei6671/plaindoc/apps/server/internal/storage"
+	"github.com/lifei6671/plaindoc/apps/server/internal/store"
+)
+
+// exportBatchSize 是整空间导出时每批从数据库读取的文档数。
+const exportBatchSize = 100
+
+// exportStatuses 是整空间导出包含的文档状态，草稿不导出。
+var exportStatuses = []string{store.DocStatusPublished, store.DocStatusArchived}
+
+// maxFileNameRunes 限制按标题生成的下载文件名长度，多数文件系统的文件名上限是 255 字节。
+const maxFileNameRunes = 80
+
+// maxExportDepth 限制导出路径的目录层级，防止异常数据导致无限递归。
+const maxExportDepth = 32
+
+// jobExportSpace 是整空间导出作业的 kind。
+const jobExportSpace = "export.space"
+
+type spaceExportParams struct {
+	Space  string `json:"space"`
+	Format string `json:"format"`
+}
+
+type spaceExportResult struct {
+	File      string `json:"file"`
+	Size      int64  `json:"size"`
+	Documents int    `json:"documents"`
+	// Issues 是 confluence 与 json 格式下 report.json 中记录的转换问题数。
+	Issues      int    `json:"issues,omitempty"`
+	DownloadURL string `json:"download_url"`
+}
+
+// Export 处理文档导出接口。
+type Export struct {
+	cfg      config.Config
+	store    *store.Store
+	renderer *render.Cached
+	pdf      *export.PDFConverter
+	storage  storage.Backend
+	policy   *acl.Policy
+	queue    *jobs.Queue
+}
+
+// NewExport 同时在 queue 上注册整空间导出作业。
+func NewExport(cfg config.Config, s *store.Store, renderer *render.Cached, backend storage.Backend, policy *acl.Policy, queue *jobs.Queue) *Export {
+	h := &Export{
+		cfg:      cfg,
+		store:    s,
+		renderer: renderer,
+		pdf:      export.NewPDFConverter(cfg.WkhtmltopdfPath),
+		storage:  backend,
+		policy:   policy,
+		queue:    queue,
+	}
+	queue.Register(jobExportSpace, h.runSpaceExport)
+	return h
+}
+
+// Document 导出单篇文档，支持 format=pdf 与 format=html（自包含的单文件页面）；生成完成后才写出响应，失败时不会返回半截文件。
+func (h *Export) Document(c *gin.Context) {
+	format := c.DefaultQuery("format", "pdf")
+	if format != "pdf" && format != "html" {
+		httpx.Abort(c, http.StatusBadRequest, i18n.FieldOneOf, "format", "pdf, html")
+		return
+	}
+	doc, ok := loadDocument(c, h.store, h.policy)
+	if !ok {
+		return
+	}
+
+	rendered, err := h.renderer.RenderDocument(c.Request.Context(), doc.ID, doc.UpdatedAt, []byte(doc.Content))
+	if err == nil {
+		rendered.HTML, err = resolveWikiLinks(c.Request.Context(), h.store, currentViewer(c), doc.Space, rendered.HTML, h.cfg.DocumentURL)
+	}
+	if err != nil {
+		httpx.AbortInternal(c, err)
+		return
+	}
+	if format == "html" {
+		h.standalone(c, doc, rendered)
+		return
+	}
+	page, err := export.HTMLPage(doc.Title, rendered.HTML, h.baseURL(c))
+	if err != nil {
+		httpx.AbortInternal(c, err)
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(c.Request.Context(), h.cfg.ExportTimeout)
+	defer cancel()
+	pdf, err := h.pdf.Convert(ctx, page)
+	switch {
+	case errors.Is(err, export.ErrTimeout):
+		_ = c.Error(err)
+		httpx.Abort(c, http.StatusGatewayTimeout, i18n.ExportTimeout)
+		return
+	case errors.Is(err, export.ErrConverterUnavailable):
+		_ = c.Error(err)
+		httpx.Abort(c, http.StatusServiceUnavailable, i18n.ExportUnavailable)
+		return
+	case err != nil:
+		_ = c.Error(err)
+		httpx.Abort(c, http.StatusInternalServerError, i18n.ExportFailed)
+		return
+	}
+
+	c.Header("Content-Disposition", mime.FormatMediaType("attachment", map[string]string{"filename": doc.Slug + ".pdf"}))
+	c.Data(http.StatusOK, "application/pdf", pdf)
+}
+
+// standalone 返回内联了样式与图片的单文件 HTML，下载文件名取文档标题。
+// 超过 EXPORT_IMAGE_MAX_SIZE 的图片降级为链接，数量写在 X-Export-Skipped-Images 响应头中。
+func (h *Export) standalone(c *gin.Context, doc *store.Document, rendered *render.Result) {
+	exporter := export.NewStandaloneHTML(h.storage, h.cfg.PublicURL, siteURL(c, h.cfg.PublicURL), h.cfg.ExportImageMaxSize)
+	page, skipped, err := exporter.Page(c.Request.Context(), doc.Title, rendered)
+	if err != nil {
+		httpx.AbortInternal(c, err)
+		return
+	}
+	if len(skipped) > 0 {
+		// 记入请求日志，便于排查导出文件为何缺图。
+		_ = c.Error(fmt.Errorf("%d images exceed EXPORT_IMAGE_MAX_SIZE and are linked instead: %s", len(skipped), strings.Join(skipped, ", ")))
+		c.Header("X-Export-Skipped-Images", strconv.Itoa(len(skipped)))
+	}
+	c.Header("Content-Disposition", mime.FormatMediaType("attachment", map[string]string{"filename": titleFileName(doc.Title, doc.Slug) + ".html"}))
+	c.Data(http.StatusOK, "text/html; charset=utf-8", page)
+}
+
+// Space 把空间内当前用户可读的全部文档打包为 zip 流式返回，format 支持 markdown（默认）、
+// confluence（Confluence storage format XHTML）与 json（元数据、Markdown 原文与正文 AST），
+// 后两种格式另附 manifest.json 记录文档 id、层级与文件路径的对应，以及列出无法无损转换内容的 report.json。
+// 响应头发出后再出错只能中断连接，客户端会得到不完整的 zip，错误写入请求日志；大空间可改用 CreateSpaceJob 在后台导出。
+func (h *Export) Space(c *gin.Context) {
+	space, format, ok := exportSpaceParams(c)
+	if !ok {
+		return
+	}
+	ctx := c.Request.Context()
+	source, err := h.prepareSpace(ctx, space, format, currentViewer(c))
+	if err != nil {
+		httpx.AbortInternal(c, err)
+		return
+	}
+
+	c.Header("Content-Type", "application/zip")
+	c.Header("Content-Disposition", mime.FormatMediaType("attachment", map[string]string{"filename": spaceArchiveName(space)}))
+	c.Status(http.StatusOK)
+	if err := h.writeSpace(ctx, c.Writer, source, nil); err != nil {
+		_ = c.Error(err)
+		c.Abort()
+	}
+}
+
+// CreateSpaceJob 提交一个整空间导出作业并立即返回 202 与作业信息，参数与 Space 相同；
+// 通过 GET /api/v1/jobs/:id 查询进度，成功后 result 中的 download_url 用于下载 zip。
+func (h *Export) CreateSpaceJob(c *gin.Context) {
+	space, format, ok := exportSpaceParams(c)
+	if !ok {
+		return
+	}
+	user, _ := httpx.CurrentUser(c)
+	job, err := h.queue.Submit(c.Request.Context(), jobExportSpace, user.ID, spaceExportParams{Space: space, Format: format})
+	if errors.Is(err, jobs.ErrQueueFull) {
+		httpx.Abort(c, http.StatusServiceUnavailable, i18n.JobQueueFull)
+		return
+	}
+	if err != nil {
+		httpx.AbortInternal(c, err)
+		return
+	}
+	c.JSON(http.StatusAccepted, job)
+}
+
+// runSpaceExport 执行整空间导出作业：按提交者当前的角色与空间权限读取文档，zip 保存在作业目录中。
+func (h *Export) runSpaceExport(ctx context.Context, task *jobs.Task) (any, error) {
+	var params spaceExportParams
+	if err := task.Params(&params); err != nil {
+		return nil, err
+	}
+	if task.Job.CreatedBy == nil {
+		return nil, errors.New("submitter no longer exists")
+	}
+	user, err := h.store.GetUser(ctx, *task.Job.CreatedBy)
+	if errors.Is(err, store.ErrNotFound) {
+		return nil, errors.New("submitter no longer exists")
+	}
+	if err != nil {
+		return nil, err
+	}
+	viewer := store.Viewer{ID: user.ID, Role: user.Role}
+	accessible, err := h.store.CanAccessSpace(ctx, viewer, params.Space)
+	if err != nil {
+		return nil, err
+	}
+	if !accessible {
+		return nil, fmt.Errorf("space %q not found", params.Space)
+	}
+
+	// 早于 format 参数提交的作业只有 markdown 一种格式。
+	if params.Format == "" {
+		params.Format = "markdown"
+	}
+	source, err := h.prepareSpace(ctx, params.Space, params.Format, viewer)
+	if err != nil {
+		return nil, err
+	}
+	name := spaceArchiveName(params.Space)
+	file, err := task.CreateFile(name)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+	err = h.writeSpace(ctx, file, source, func(done int) error {
+		return task.Progress(ctx, done*100/max(source.total, 1))
+	})
+	if err != nil {
+		return nil, err
+	}
+	info, err := file.Stat()
+	if err != nil {
+		return nil, err
+	}
+	if err := file.Close(); err != nil {
+		return nil, err
+	}
+	return spaceExportResult{
+		File:        name,
+		Size:        info.Size(),
+		Documents:   source.written,
+		Issues:      source.issues,
+		DownloadURL: fmt.Sprintf("/api/v1/jobs/%d/download", task.Job.ID),
+	}, nil
+}
+
+// spaceSource 是一次整空间导出的输入：目录树计算出的路径、按 slug 索引的文档与第一批文档。
+type spaceSource struct {
+	space   string
+	format  string
+	viewer  store.Viewer
+	paths   map[int64]string
+	pages   map[string]export.PageRef
+	docs    []store.Document
+	total   int
+	written int
+	issues  int
+}
+
+// prepareSpace 读取导出需要的目录树与第一批文档，出错时还没有写出任何内容，调用方可以正常返回错误。
+func (h *Export) prepareSpace(ctx context.Context, space string, format string, viewer store.Viewer) (*spaceSource, error) {
+	// 目录树只含标题等元数据，先整体读出用于计算每篇文档在 zip 中的路径。
+	tree, err := h.store.ListDocumentTree(ctx, space, viewer, exportStatuses)
+	if err != nil {
+		return nil, err
+	}
+	docs, err := h.store.ListSpaceDocumentsAfter(ctx, space, viewer, exportStatuses, 0, exportBatchSize)
+	if err != nil {
+		return nil, err
+	}
+	pages := make(map[string]export.PageRef, len(tree))
+	for _, doc := range tree {
+		pages[doc.Slug] = export.PageRef{ID: doc.ID, Title: doc.Title}
+	}
+	return &spaceSource{space: space, format: format, viewer: viewer, paths: exportPaths(tree), pages: pages, docs: docs, total: len(tree)}, nil
+}
+
+// writeSpace 把 source 中的文档逐批写成 zip；progress 不为 nil 时每写完一批调用一次，参数为已写入的文档数。
+func (h *Export) writeSpace(ctx context.Context, w io.Writer, source *spaceSource, progress func(done int) error) error {
+	archive, err := h.spaceArchive(w, source)
+	if err != nil {
+		return err
+	}
+	authors := map[int64]*store.User{}
+	docs := source.docs
+	for len(docs) > 0 {
+		for _, doc := range docs {
+			author, err := h.author(ctx, authors, doc.AuthorID)
+			if err != nil {
+				return err
+			}
+			name, ok := source.paths[doc.ID]
+			if !ok {
+				// 读取目录树之后新建的文档放在顶层。
+				name = exportFileName(doc.Slug)
+			}
+			if err := archive.add(ctx, name, &doc, author); err != nil {
+				return err
+			}
+			source.written++
+		}
+		if progress != nil {
+			if err := progress(source.written); err != nil {
+				return err
+			}
+		}
+		if docs, err = h.store.ListSpaceDocumentsAfter(ctx, source.space, source.viewer, exportStatuses, docs[len(docs)-1].ID, exportBatchSize); err != nil {
+			return err
+		}
+	}
+	if err := archive.close(); err != nil {
+		return err
+	}
+	source.issues = archive.issues()
+	return nil
+}
+
+// spaceArchive 按导出格式写入文档，author 为 nil 表示作者已被删除。
+type spaceArchive interface {
+	add(ctx context.Context, name string, doc *store.Document, author *store.User) error
+	// issues 返回写入过程中记录的转换问题数。
+	issues() int
+	close() error
+}
+
+func (h *Export) spaceArchive(w io.Writer, source *spaceSource) (spaceArchive, error) {
+	if source.format == "markdown" {
+		return markdownSpaceArchive{export.NewMarkdownArchive(w, h.storage, h.cfg.PublicURL, h.cfg.UploadBaseURL)}, nil
+	}
+	archive, err := export.NewStructuredArchive(w, source.format, source.space, h.storage, h.cfg.PublicURL, source.pages)
+	if err != nil {
+		return nil, err
+	}
+	return structuredSpaceArchive{archive}, nil
+}
+
+type markdownSpaceArchive struct {
+	archive *export.MarkdownArchive
+}
+
+func (m markdownSpaceArchive) add(ctx context.Context, name string, doc *store.Document, author *store.User) error {
+	meta := export.FrontMatter{Title: doc.Title, Slug: doc.Slug, SortOrder: doc.SortOrder}
+	if author != nil {
+		meta.Author, meta.AuthorEmail = author.Name, author.Email
+	}
+	return m.archive.AddDocument(ctx, name, meta, doc.UpdatedAt, doc.Content)
+}
+
+func (m markdownSpaceArchive) issues() int { return 0 }
+
+func (m markdownSpaceArchive) close() error { return m.archive.Close() }
+
+type structuredSpaceArchive struct {
+	archive *export.StructuredArchive
+}
+
+func (s structuredSpaceArchive) add(ctx context.Context, name string, doc *store.Document, author *store.User) error {
+	page := export.Page{
+		ID:        doc.ID,
+		ParentID:  doc.ParentID,
+		Title:     doc.Title,
+		Slug:      doc.Slug,
+		SortOrder: doc.SortOrder,
+		UpdatedAt: doc.UpdatedAt.UTC(),
+	}
+	if author != nil {
+		page.Author, page.AuthorEmail = author.Name, author.Email
+	}
+	return s.archive.AddDocument(ctx, name, page, doc.Content)
+}
+
+func (s structuredSpaceArchive) issues() int { return s.archive.Issues() }
+
+func (s structuredSpaceArchive) close() error { return s.archive.Close() }
+
+// exportSpaceParams 校验整空间导出的 format 并返回 space 与 format 参数，不合法时已返回 400。
+func exportSpaceParams(c *gin.Context) (string, string, bool) {
+	format := c.DefaultQuery("format", "markdown")
+	if format != "markdown" && format != export.FormatConfluence && format != export.FormatJSON {
+		httpx.Abort(c, http.StatusBadRequest, i18n.FieldOneOf, "format", "markdown, confluence, json")
+		return "", "", false
+	}
+	return strings.TrimSpace(c.DefaultQuery("space", store.DefaultSpace)), format, true
+}
+
+func spaceArchiveName(space string) string {
+	return fmt.Sprintf("%s-%s.zip", space, time.Now().UTC().Format("20060102"))
+}
+
+// author 读取并缓存文档作者，作者已被删除时返回 nil。
+func (h *Export) author(ctx context.Context, cache map[int64]*store.User, id int64) (*store.User, error) {
+	if user, ok := cache[id]; ok {
+		return user, nil
+	}
+	user, err := h.store.GetUser(ctx, id)
+	if errors.Is(err, store.ErrNotFound) {
+		err = nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	cache[id] = user
+	return user, nil
+}
+
+// exportPaths 按目录树计算每篇文档在 zip 中的路径（不含扩展名）：子文档放在以父文档 slug 命名的目录下，
+// 例如 guide.md 的子文档为 guide/install.md。
+func exportPaths(tree []store.Document) map[int64]string {
+	docs := make(map[int64]store.Document, len(tree))
+	for _, doc := range tree {
+		docs[doc.ID] = doc
+	}
+
+	paths := make(map[int64]string, len(tree))
+	var resolve func(id int64, depth int) string
+	resolve = func(id int64, depth int) string {
+		if p, ok := paths[id]; ok {
+			return p
+		}
+		doc := docs[id]
+		name := exportFileName(doc.Slug)
+		if doc.ParentID != nil && depth < maxExportDepth {
+			if _, ok := docs[*doc.ParentID]; ok {
+				name = resolve(*doc.ParentID, depth+1) + "/" + name
+			}
+		}
+		paths[id] = name
+		return name
+	}
+	for _, doc := range tree {
+		resolve(doc.ID, 0)
+	}
+	return paths
+}
+
+// exportFileName 把 slug 转换为安全的 zip 内文件名。
+func exportFileName(slug string) string {
+	name := strings.NewReplacer("/", "-", "\\", "-", "..", "-").Replace(slug)
+	if name == "" || name == "." {
+		name = "untitled"
+	}
+	return name
+}
+
+// titleFileName 把文档标题转换为下载文件名：去掉各系统文件名中不允许的字符并限制长度，标题为空时使用 slug。
+func titleFileName(title string, slug string) string {
+	name := strings.Map(func(r rune) rune {
+		if r < 0x20 || r == 0x7f || strings.ContainsRune(`/\:*?"<>|`, r) {
+			return '-'
+		}
+		return r
+	}, strings.TrimSpace(title))
+	if runes := []rune(name); len(runes) > maxFileNameRunes {
+		name = string(runes[:maxFileNameRunes])
+	}
+	name = strings.Trim(name, " .")
+	if name == "" {
+		return exportFileName(slug)
+	}
+	return name
+}
+
+// baseURL 返回解析站内相对链接用的站点地址（以 / 结尾）。
+func (h *Export) baseURL(c *gin.Context) string {
+	return siteURL(c, h.cfg.PublicURL) + "/"
+}
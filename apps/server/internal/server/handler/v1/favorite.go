@@ -0,0 +1,67 @@
+package v1
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/lifei6671/plaindoc/apps/server/internal/server/httpx"
+	"github.com/lifei6671/plaindoc/apps/server/internal/store"
+)
+
+// Favorite 收藏当前用户可读的文档，重复收藏不报错。
+func (h *Document) Favorite(c *gin.Context) {
+	doc, ok := h.load(c)
+	if !ok {
+		return
+	}
+	if err := h.store.AddFavorite(c.Request.Context(), currentViewer(c).ID, doc.ID); err != nil {
+		httpx.AbortInternal(c, err)
+		return
+	}
+	c.Status(http.StatusNoContent)
+}
+
+// Unfavorite 取消收藏；不检查文档是否仍可读，失去权限后也能清理收藏。
+func (h *Document) Unfavorite(c *gin.Context) {
+	id, ok := httpx.ParamID(c, "id")
+	if !ok {
+		return
+	}
+	if err := h.store.RemoveFavorite(c.Request.Context(), currentViewer(c).ID, id); err != nil {
+		httpx.AbortInternal(c, err)
+		return
+	}
+	c.Status(http.StatusNoContent)
+}
+
+// Favorites 按收藏时间倒序分页返回当前用户收藏且仍可读的文档（不含正文）。
+func (h *Document) Favorites(c *gin.Context) {
+	pagination, ok := httpx.ParsePagination(c)
+	if !ok {
+		return
+	}
+	docs, total, err := h.store.ListFavorites(c.Request.Context(), currentViewer(c), pagination.Limit(), pagination.Offset())
+	if err != nil {
+		httpx.AbortInternal(c, err)
+		return
+	}
+	if !h.attachTags(c, docs) {
+		return
+	}
+	c.JSON(http.StatusOK, httpx.NewPagedResponse(docs, total, pagination))
+}
+
+// loadFavorited 为登录用户填充文档的收藏状态，失败时已返回 500。
+func (h *Document) loadFavorited(c *gin.Context, doc *store.Document) bool {
+	user, ok := httpx.CurrentUser(c)
+	if !ok {
+		return true
+	}
+	favorited, err := h.store.IsFavorited(c.Request.Context(), user.ID, doc.ID)
+	if err != nil {
+		httpx.AbortInternal(c, err)
+		return false
+	}
+	doc.IsFavorited = &favorited
+	return true
+}
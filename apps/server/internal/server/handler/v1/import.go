@@ -0,0 +1,386 @@
+package v1
+
+import (
+	"archive/zip"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"path"
+	"regexp"
+	"sort"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/gin-gonic/gin"
+	"github.com/lifei6671/plaindoc/apps/server/internal/acl"
+	"github.com/lifei6671/plaindoc/apps/server/internal/config"
+	"github.com/lifei6671/plaindoc/apps/server/internal/export"
+	"github.com/lifei6671/plaindoc/apps/server/internal/i18n"
+	"github.com/lifei6671/plaindoc/apps/server/internal/search"
+	"github.com/lifei6671/plaindoc/apps/server/internal/server/httpx"
+	"github.com/lifei6671/plaindoc/apps/server/internal/slug"
+	"github.com/lifei6671/plaindoc/apps/server/internal/store"
+)
+
+// slug 已存在时的冲突策略。
+const (
+	ConflictSkip      = "skip"
+	ConflictOverwrite = "overwrite"
+	ConflictRename    = "rename"
+)
+
+const (
+	importStatusCreated = "created"
+	importStatusUpdated = "updated"
+	importStatusSkipped = "skipped"
+	importStatusFailed  = "failed"
+
+	// maxImportDocumentBytes 限制 zip 内单个 .md 文件解压后的大小。
+	maxImportDocumentBytes = 5 << 20
+)
+
+// assetLink 匹配正文中指向 zip 内 assets/ 目录的相对路径。
+var assetLink = regexp.MustCompile(`(?:\.{1,2}/)*` + export.AssetsDir + `/[^\s()<>"']+`)
+
+// Import 处理 Markdown zip 导入，与 Export.Space 的导出格式对应。
+type Import struct {
+	store   *store.Store
+	indexer search.Indexer
+	uploads *Upload
+	policy  *acl.Policy
+	maxSize int64
+}
+
+func NewImport(cfg config.Config, s *store.Store, indexer search.Indexer, uploads *Upload, policy *acl.Policy) *Import {
+	return &Import{store: s, indexer: indexer, uploads: uploads, policy: policy, maxSize: cfg.ImportMaxSize}
+}
+
+type importItem struct {
+	Path   string `json:"path"`
+	Slug   string `json:"slug,omitempty"`
+	ID     int64  `json:"id,omitempty"`
+	Status string `json:"status"`
+	Reason string `json:"reason,omitempty"`
+}
+
+type importReport struct {
+	Created int          `json:"created"`
+	Updated int          `json:"updated"`
+	Skipped int          `json:"skipped"`
+	Failed  int          `json:"failed"`
+	Items   []importItem `json:"items"`
+}
+
+// pendingDocument 是解析完成、等待写入数据库的文档。
+type pendingDocument struct {
+	item *importItem
+	// path 是 zip 内去掉 .md 扩展名的路径，用于还原父子关系。
+	path      string
+	title     string
+	content   string
+	sortOrder int64
+}
+
+// Create 接收 multipart 表单字段 file（zip）、space 与 conflict=skip|overwrite|rename。
+// 解析失败的文件记入报告后跳过；数据库写入在同一个事务中完成，任何一步出错都会整体回滚。
+func (h *Import) Create(c *gin.Context) {
+	c.Request.Body = http.MaxBytesReader(c.Writer, c.Request.Body, h.maxSize+multipartOverhead)
+	header, err := c.FormFile("file")
+	if err != nil {
+		var tooLarge *http.MaxBytesError
+		if errors.As(err, &tooLarge) {
+			httpx.Abort(c, http.StatusRequestEntityTooLarge, i18n.ArchiveTooLarge, h.maxSize)
+			return
+		}
+		httpx.Abort(c, http.StatusBadRequest, i18n.FieldRequired, "file")
+		return
+	}
+	conflict := c.DefaultPostForm("conflict", ConflictSkip)
+	if conflict != ConflictSkip && conflict != ConflictOverwrite && conflict != ConflictRename {
+		httpx.Abort(c, http.StatusBadRequest, i18n.FieldOneOf, "conflict", "skip, overwrite, rename")
+		return
+	}
+	space := strings.TrimSpace(c.DefaultPostForm("space", store.DefaultSpace))
+	if space == "" || utf8.RuneCountInString(space) > 64 {
+		httpx.Abort(c, http.StatusBadRequest, i18n.FieldLength, "space", 1, 64)
+		return
+	}
+	if !checkSpace(c, h.store, space) {
+		return
+	}
+
+	file, err := header.Open()
+	if err != nil {
+		httpx.AbortInternal(c, err)
+		return
+	}
+	defer file.Close()
+	archive, err := zip.NewReader(file, header.Size)
+	if err != nil {
+		httpx.Abort(c, http.StatusBadRequest, i18n.InvalidArchive)
+		return
+	}
+
+	docs, assets := importEntries(archive)
+	report := &importReport{Items: make([]importItem, len(docs))}
+	uploaded := map[string]string{}
+	var pending []pendingDocument
+	for i, entry := range docs {
+		item := &report.Items[i]
+		item.Path = entry.Name
+		doc, err := h.prepare(c.Request.Context(), entry, assets, uploaded)
+		if err != nil {
+			item.Status, item.Reason = importStatusFailed, err.Error()
+			continue
+		}
+		item.Slug = doc.slug
+		pending = append(pending, pendingDocument{
+			item:      item,
+			path:      strings.TrimSuffix(path.Clean(entry.Name), path.Ext(entry.Name)),
+			title:     doc.title,
+			content:   doc.content,
+			sortOrder: doc.sortOrder,
+		})
+	}
+	// 先写父文档再写子文档；同级按导出时的 sort_order 排列，新建文档依次追加到末尾即可还原顺序。
+	sort.SliceStable(pending, func(i, j int) bool {
+		di, dj := strings.Count(pending[i].path, "/"), strings.Count(pending[j].path, "/")
+		if di != dj {
+			return di < dj
+		}
+		if pending[i].sortOrder != pending[j].sortOrder {
+			return pending[i].sortOrder < pending[j].sortOrder
+		}
+		return pending[i].path < pending[j].path
+	})
+
+	// 上传文件按内容哈希命名，事务回滚时最多留下未被引用的文件，不会产生脏数据。
+	var written []*store.Document
+	err = h.store.WithTx(c.Request.Context(), func(tx *store.Store) error {
+		// ids 记录 zip 内路径对应、当前用户可写的文档，a/b.md 的父文档是 a.md（逐级向上查找最近的祖先）。
+		ids := map[string]int64{}
+		for _, doc := range pending {
+			saved, parent, err := h.write(c, tx, space, conflict, doc, importParent(ids, doc.path))
+			if err != nil {
+				return err
+			}
+			if saved != nil {
+				written = append(written, saved)
+			}
+			if parent != 0 {
+				ids[doc.path] = parent
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		httpx.AbortInternal(c, err)
+		return
+	}
+
+	for _, doc := range written {
+		indexDocument(c, h.indexer, doc)
+	}
+	for _, item := range report.Items {
+		switch item.Status {
+		case importStatusCreated:
+			report.Created++
+		case importStatusUpdated:
+			report.Updated++
+		case importStatusSkipped:
+			report.Skipped++
+		case importStatusFailed:
+			report.Failed++
+		}
+	}
+	c.JSON(http.StatusOK, report)
+}
+
+type parsedDocument struct {
+	title     string
+	slug      string
+	content   string
+	sortOrder int64
+}
+
+// prepare 解析单个 .md 文件并把其中引用的 assets/ 文件走上传流程，正文链接改写为上传后的地址。
+func (h *Import) prepare(ctx context.Context, entry *zip.File, assets map[string]*zip.File, uploaded map[string]string) (*parsedDocument, error) {
+	data, err := readZipEntry(entry, maxImportDocumentBytes)
+	if err != nil {
+		return nil, err
+	}
+	meta, content, err := export.ParseDocument(data)
+	if err != nil {
+		return nil, fmt.Errorf("invalid front-matter: %w", err)
+	}
+
+	name := strings.TrimSuffix(path.Base(entry.Name), path.Ext(entry.Name))
+	doc := &parsedDocument{title: strings.TrimSpace(meta.Title), slug: strings.TrimSpace(meta.Slug), sortOrder: meta.SortOrder}
+	if doc.title == "" {
+		doc.title = name
+	}
+	// front-matter 中的 slug 原样保留，保证导出再导入不改变链接；从文件名得到的 slug 不合法时按文件名重新生成。
+	if doc.slug == "" {
+		doc.slug = name
+		if slug.Validate(name) != nil {
+			doc.slug = slug.Generate(name)
+		}
+	}
+	if utf8.RuneCountInString(doc.title) > 255 {
+		return nil, errors.New("title is longer than 255 characters")
+	}
+	if utf8.RuneCountInString(doc.slug) > slug.MaxLength {
+		return nil, fmt.Errorf("slug is longer than %d characters", slug.MaxLength)
+	}
+
+	var assetErr error
+	doc.content = assetLink.ReplaceAllStringFunc(content, func(link string) string {
+		target := path.Join(path.Dir(entry.Name), link)
+		asset, ok := assets[target]
+		if !ok || assetErr != nil {
+			return link
+		}
+		if url, ok := uploaded[target]; ok {
+			return url
+		}
+		data, err := readZipEntry(asset, h.uploads.maxSize)
+		if err != nil {
+			assetErr = fmt.Errorf("%s: %w", target, err)
+			return link
+		}
+		resp, err := h.uploads.save(ctx, data, asset.Name)
+		if err != nil {
+			assetErr = fmt.Errorf("%s: %w", target, err)
+			return link
+		}
+		uploaded[target] = resp.URL
+		return resp.URL
+	})
+	if assetErr != nil {
+		return nil, assetErr
+	}
+	return doc, nil
+}
+
+// write 按冲突策略写入一篇文档，跳过时返回 nil；返回的错误会导致整个导入回滚。已存在的文档保持原有位置。
+// parent 是可以作为 zip 内子文档父节点的文档 ID，只有当前用户可写的文档才会返回，否则为 0。
+func (h *Import) write(c *gin.Context, tx *store.Store, space string, conflict string, doc pendingDocument, parentID *int64) (saved *store.Document, parent int64, err error) {
+	ctx := c.Request.Context()
+	user, _ := httpx.CurrentUser(c)
+	item := doc.item
+
+	existing, err := tx.GetDocumentBySlug(ctx, space, item.Slug)
+	if err != nil && !errors.Is(err, store.ErrNotFound) {
+		return nil, 0, err
+	}
+	if existing != nil && conflict != ConflictRename {
+		// 与 checkParent 一致：不可读的文档按不存在处理，报告中不出现其 ID，也不能挂到它下面。
+		readable, err := h.policy.CanRead(ctx, currentViewer(c), existing)
+		if err != nil {
+			return nil, 0, err
+		}
+		if !readable {
+			item.Status, item.Reason = importStatusFailed, "slug is used by a document you cannot access"
+			return nil, 0, nil
+		}
+		writable, err := h.policy.CanWrite(ctx, currentViewer(c), existing)
+		if err != nil {
+			return nil, 0, err
+		}
+		if writable {
+			parent = existing.ID
+		}
+		item.ID = existing.ID
+		if conflict == ConflictSkip {
+			item.Status, item.Reason = importStatusSkipped, "slug already exists"
+			return nil, parent, nil
+		}
+		if !writable {
+			item.Status, item.Reason = importStatusFailed, "not allowed to overwrite this document"
+			return nil, 0, nil
+		}
+		lock, err := lockedByOther(c, tx, existing)
+		if err != nil {
+			return nil, 0, err
+		}
+		if lock != nil {
+			item.Status, item.Reason = importStatusFailed, "document is being edited by "+lock.UserName
+			return nil, 0, nil
+		}
+		existing.Title, existing.Content = doc.title, doc.content
+		if err := tx.UpdateDocument(ctx, existing, user.ID, "imported from archive"); err != nil {
+			return nil, 0, err
+		}
+		item.Status = importStatusUpdated
+		return existing, parent, nil
+	}
+	if existing != nil {
+		if item.Slug, err = tx.FreeSlug(ctx, space, item.Slug); err != nil {
+			return nil, 0, err
+		}
+	}
+
+	created := &store.Document{
+		Space:    space,
+		ParentID: parentID,
+		Title:    doc.title,
+		Slug:     item.Slug,
+		Content:  doc.content,
+		AuthorID: user.ID,
+
+		InheritPermissions: true,
+	}
+	if err := tx.CreateDocument(ctx, created); err != nil {
+		return nil, 0, err
+	}
+	item.ID, item.Status = created.ID, importStatusCreated
+	return created, created.ID, nil
+}
+
+func importParent(ids map[string]int64, docPath string) *int64 {
+	for dir := path.Dir(docPath); dir != "." && dir != "/"; dir = path.Dir(dir) {
+		if id, ok := ids[dir]; ok {
+			return &id
+		}
+	}
+	return nil
+}
+
+// importEntries 把 zip 条目分为按路径排序的 .md 文档与以路径为键的附件，忽略目录与系统生成的隐藏文件。
+func importEntries(archive *zip.Reader) ([]*zip.File, map[string]*zip.File) {
+	var docs []*zip.File
+	assets := map[string]*zip.File{}
+	for _, entry := range archive.File {
+		name := path.Clean(entry.Name)
+		if entry.FileInfo().IsDir() || strings.HasPrefix(name, "__MACOSX/") || strings.HasPrefix(path.Base(name), ".") {
+			continue
+		}
+		switch {
+		case strings.EqualFold(path.Ext(name), ".md"):
+			docs = append(docs, entry)
+		case strings.HasPrefix(name, export.AssetsDir+"/") || strings.Contains(name, "/"+export.AssetsDir+"/"):
+			assets[name] = entry
+		}
+	}
+	sort.Slice(docs, func(i, j int) bool { return docs[i].Name < docs[j].Name })
+	return docs, assets
+}
+
+// readZipEntry 读取条目内容，按实际解压字节数限制大小以防压缩炸弹。
+func readZipEntry(entry *zip.File, limit int64) ([]byte, error) {
+	r, err := entry.Open()
+	if err != nil {
+		return nil, err
+	}
+	defer r.Close()
+	data, err := io.ReadAll(io.LimitReader(r, limit+1))
+	if err != nil {
+		return nil, err
+	}
+	if int64(len(data)) > limit {
+		return nil, fmt.Errorf("file exceeds the %d bytes limit", limit)
+	}
+	return data, nil
+}
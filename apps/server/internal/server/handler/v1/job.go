@@ -0,0 +1,110 @@
+package v1
+
+import (
+	"encoding/json"
+	"errors"
+	"mime"
+	"net/http"
+	"os"
+
+	"github.com/gin-gonic/gin"
+	"github.com/lifei6671/plaindoc/apps/server/internal/auth"
+	"github.com/lifei6671/plaindoc/apps/server/internal/i18n"
+	"github.com/lifei6671/plaindoc/apps/server/internal/jobs"
+	"github.com/lifei6671/plaindoc/apps/server/internal/server/httpx"
+	"github.com/lifei6671/plaindoc/apps/server/internal/store"
+)
+
+// Job 处理异步作业的查询与结果下载：用户只能看到自己提交的作业，管理员可以查看任意作业。
+type Job struct {
+	store *store.Store
+	queue *jobs.Queue
+}
+
+func NewJob(s *store.Store, queue *jobs.Queue) *Job {
+	return &Job{store: s, queue: queue}
+}
+
+// List 按提交时间倒序分页返回当前用户提交的作业。
+func (h *Job) List(c *gin.Context) {
+	pagination, ok := httpx.ParsePagination(c)
+	if !ok {
+		return
+	}
+	user, _ := httpx.CurrentUser(c)
+	items, total, err := h.store.ListJobs(c.Request.Context(), user.ID, pagination.Limit(), pagination.Offset())
+	if err != nil {
+		httpx.AbortInternal(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, httpx.NewPagedResponse(items, total, pagination))
+}
+
+// Get 返回作业的状态、进度与结果，客户端轮询直到 status 为 succeeded 或 failed。
+func (h *Job) Get(c *gin.Context) {
+	job, ok := h.load(c)
+	if !ok {
+		return
+	}
+	c.JSON(http.StatusOK, job)
+}
+
+// Download 下载作业生成的文件，作业未成功结束时返回 409，文件已被清理时返回 404。
+func (h *Job) Download(c *gin.Context) {
+	job, ok := h.load(c)
+	if !ok {
+		return
+	}
+	if job.Status != store.JobSucceeded {
+		httpx.Abort(c, http.StatusConflict, i18n.JobNotFinished, job.Status)
+		return
+	}
+	var result struct {
+		File string `json:"file"`
+	}
+	if err := json.Unmarshal(job.Result, &result); err != nil || result.File == "" {
+		httpx.Abort(c, http.StatusNotFound, i18n.JobFileNotFound)
+		return
+	}
+	file, err := os.Open(h.queue.FilePath(job.ID, result.File))
+	if errors.Is(err, os.ErrNotExist) {
+		httpx.Abort(c, http.StatusNotFound, i18n.JobFileNotFound)
+		return
+	}
+	if err != nil {
+		httpx.AbortInternal(c, err)
+		return
+	}
+	defer file.Close()
+	info, err := file.Stat()
+	if err != nil {
+		httpx.AbortInternal(c, err)
+		return
+	}
+	c.Header("Content-Disposition", mime.FormatMediaType("attachment", map[string]string{"filename": result.File}))
+	http.ServeContent(c.Writer, c.Request, result.File, info.ModTime(), file)
+}
+
+// load 读取路径中的作业；其他用户提交的作业与不存在的作业一样返回 404。
+func (h *Job) load(c *gin.Context) (*store.Job, bool) {
+	id, ok := httpx.ParamID(c, "id")
+	if !ok {
+		return nil, false
+	}
+	job, err := h.store.GetJob(c.Request.Context(), id)
+	if err == nil {
+		viewer := currentViewer(c)
+		if viewer.Role != auth.RoleAdmin && (job.CreatedBy == nil || *job.CreatedBy != viewer.ID) {
+			err = store.ErrNotFound
+		}
+	}
+	if errors.Is(err, store.ErrNotFound) {
+		httpx.Abort(c, http.StatusNotFound, i18n.JobNotFound)
+		return nil, false
+	}
+	if err != nil {
+		httpx.AbortInternal(c, err)
+		return nil, false
+	}
+	return job, true
+}
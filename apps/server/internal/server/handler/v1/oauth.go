@@ -0,0 +1,231 @@
+package v1
+
+import (
+	"context"
+	"crypto/subtle"
+	"errors"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/lifei6671/plaindoc/apps/server/internal/auth"
+	"github.com/lifei6671/plaindoc/apps/server/internal/config"
+	"github.com/lifei6671/plaindoc/apps/server/internal/i18n"
+	"github.com/lifei6671/plaindoc/apps/server/internal/oauth"
+	"github.com/lifei6671/plaindoc/apps/server/internal/server/httpx"
+	"github.com/lifei6671/plaindoc/apps/server/internal/store"
+)
+
+const (
+	// oauthStateCookie 保存发起登录时生成的 state，回调时比对以防 CSRF；只在 OAuth 路由下发送。
+	oauthStateCookie = "plaindoc_oauth_state"
+	oauthCookiePath  = Prefix + "/auth/oauth"
+	oauthStateTTL    = 10 * time.Minute
+	maxUserNameRunes = 64
+)
+
+var errEmailUnverified = errors.New("oauth email is not verified")
+
+// OAuth 处理第三方账号登录：跳转授权、处理回调并签发本站会话。
+type OAuth struct {
+	auth         *Auth
+	store        *store.Store
+	providers    map[string]*oauth.Provider
+	publicURL    string
+	redirectURL  string
+	secureCookie bool
+}
+
+func NewOAuth(cfg config.Config, s *store.Store, authHandler *Auth) *OAuth {
+	providers := map[string]*oauth.Provider{}
+	if cfg.OAuthGitHubClientID != "" && cfg.OAuthGitHubClientSecret != "" {
+		providers["github"] = oauth.GitHub(cfg.OAuthGitHubClientID, cfg.OAuthGitHubClientSecret)
+	}
+	if cfg.OAuthGoogleClientID != "" && cfg.OAuthGoogleClientSecret != "" {
+		providers["google"] = oauth.Google(cfg.OAuthGoogleClientID, cfg.OAuthGoogleClientSecret)
+	}
+	return &OAuth{
+		auth:         authHandler,
+		store:        s,
+		providers:    providers,
+		publicURL:    cfg.PublicURL,
+		redirectURL:  cfg.OAuthRedirectURL,
+		secureCookie: cfg.Env == "production",
+	}
+}
+
+// Start 生成 state 写入 cookie 后跳转到第三方授权页。
+func (h *OAuth) Start(c *gin.Context) {
+	provider, ok := h.provider(c)
+	if !ok {
+		return
+	}
+	state, _, err := auth.NewOpaqueToken()
+	if err != nil {
+		httpx.AbortInternal(c, err)
+		return
+	}
+	// 回调是从第三方站点跳回的顶层 GET 请求，SameSite=Lax 的 cookie 仍会携带。
+	c.SetSameSite(http.SameSiteLaxMode)
+	c.SetCookie(oauthStateCookie, provider.Name+":"+state, int(oauthStateTTL.Seconds()), oauthCookiePath, "", h.secureCookie, true)
+	c.Redirect(http.StatusFound, provider.AuthCodeURL(state, h.callbackURL(c, provider)))
+}
+
+// Callback 校验 state、换取第三方用户资料并登录对应的本地用户，完成后带着会话 cookie 跳回前端；
+// 失败时同样跳回前端，并通过 oauth_error 查询参数告知原因。
+func (h *OAuth) Callback(c *gin.Context) {
+	provider, ok := h.provider(c)
+	if !ok {
+		return
+	}
+	expected, _ := c.Cookie(oauthStateCookie)
+	c.SetSameSite(http.SameSiteLaxMode)
+	c.SetCookie(oauthStateCookie, "", -1, oauthCookiePath, "", h.secureCookie, true)
+
+	if c.Query("error") != "" {
+		h.fail(c, "oauth_denied")
+		return
+	}
+	state := c.Query("state")
+	if state == "" || expected == "" || subtle.ConstantTimeCompare([]byte(expected), []byte(provider.Name+":"+state)) != 1 {
+		h.fail(c, "oauth_invalid_state")
+		return
+	}
+
+	ctx := c.Request.Context()
+	identity, err := provider.Exchange(ctx, c.Query("code"), h.callbackURL(c, provider))
+	if errors.Is(err, oauth.ErrNoEmail) {
+		h.fail(c, "oauth_no_email")
+		return
+	}
+	if err != nil {
+		_ = c.Error(err)
+		h.fail(c, "oauth_failed")
+		return
+	}
+
+	user, err := h.resolveUser(ctx, identity)
+	if errors.Is(err, errEmailUnverified) {
+		h.fail(c, "oauth_email_unverified")
+		return
+	}
+	if errors.Is(err, errRegistrationDisabled) {
+		h.fail(c, "registration_disabled")
+		return
+	}
+	if err != nil {
+		_ = c.Error(err)
+		h.fail(c, "oauth_failed")
+		return
+	}
+	challenge, err := h.auth.challenge(ctx, user)
+	if err != nil {
+		_ = c.Error(err)
+		h.fail(c, "oauth_failed")
+		return
+	}
+	if challenge != nil {
+		h.redirectChallenge(c, challenge)
+		return
+	}
+	if _, _, err := h.auth.startSession(c, user); err != nil {
+		_ = c.Error(err)
+		h.fail(c, "oauth_failed")
+		return
+	}
+	c.Redirect(http.StatusFound, h.redirectURL)
+}
+
+// redirectChallenge 跳回前端继续两步验证；中间 token 放在 fragment 中，不会出现在服务器日志与 Referer 里。
+func (h *OAuth) redirectChallenge(c *gin.Context, challenge *challengeResponse) {
+	target, err := url.Parse(h.redirectURL)
+	if err != nil {
+		h.fail(c, "oauth_failed")
+		return
+	}
+	target.Fragment = url.Values{"two_factor": {challenge.TwoFactor}, "challenge_token": {challenge.ChallengeToken}}.Encode()
+	c.Redirect(http.StatusFound, target.String())
+}
+
+// resolveUser 返回第三方账号对应的本地用户：已绑定的直接使用；未绑定时按邮箱匹配已有用户并绑定，
+// 没有同邮箱用户则自动注册。只信任第三方已验证的邮箱，否则他人可以用未验证的邮箱接管同名的本地账号。
+func (h *OAuth) resolveUser(ctx context.Context, identity *oauth.Identity) (*store.User, error) {
+	var user *store.User
+	err := h.store.WithTx(ctx, func(tx *store.Store) error {
+		var err error
+		user, err = tx.GetUserByIdentity(ctx, identity.Provider, identity.Subject)
+		if !errors.Is(err, store.ErrNotFound) {
+			return err
+		}
+		if !identity.EmailVerified {
+			return errEmailUnverified
+		}
+
+		user, err = tx.GetUserByEmail(ctx, identity.Email)
+		if errors.Is(err, store.ErrNotFound) {
+			// 第三方注册的用户没有密码，只能通过第三方登录；与本地注册一样受注册开关与默认角色约束。
+			var role string
+			if role, err = h.auth.registrationRole(ctx); err != nil {
+				return err
+			}
+			now := time.Now().UTC()
+			user = &store.User{Email: identity.Email, EmailVerifiedAt: &now, Name: oauthUserName(identity), Role: role}
+			err = tx.CreateUser(ctx, user)
+		} else if err == nil && user.EmailVerifiedAt == nil {
+			// 第三方已验证同一个邮箱，视为本地账号也完成了邮箱验证。
+			err = tx.MarkEmailVerified(ctx, user.ID)
+		}
+		if err != nil {
+			return err
+		}
+		return tx.CreateUserIdentity(ctx, &store.UserIdentity{
+			UserID:   user.ID,
+			Provider: identity.Provider,
+			Subject:  identity.Subject,
+			Email:    identity.Email,
+		})
+	})
+	return user, err
+}
+
+func (h *OAuth) provider(c *gin.Context) (*oauth.Provider, bool) {
+	provider, ok := h.providers[c.Param("provider")]
+	if !ok {
+		httpx.Abort(c, http.StatusNotFound, i18n.OAuthProviderNotFound)
+		return nil, false
+	}
+	return provider, true
+}
+
+func (h *OAuth) callbackURL(c *gin.Context, provider *oauth.Provider) string {
+	return siteURL(c, h.publicURL) + oauthCookiePath + "/" + provider.Name + "/callback"
+}
+
+func (h *OAuth) fail(c *gin.Context, code string) {
+	target, err := url.Parse(h.redirectURL)
+	if err != nil {
+		httpx.AbortError(c, http.StatusBadRequest, code, httpx.Localize(c, i18n.OAuthFailed))
+		return
+	}
+	if target.Path == "" {
+		target.Path = "/"
+	}
+	query := target.Query()
+	query.Set("oauth_error", code)
+	target.RawQuery = query.Encode()
+	c.Redirect(http.StatusFound, target.String())
+}
+
+// oauthUserName 取第三方昵称作为用户名，没有昵称时用邮箱的本地部分。
+func oauthUserName(identity *oauth.Identity) string {
+	name := strings.TrimSpace(identity.Name)
+	if name == "" {
+		name, _, _ = strings.Cut(identity.Email, "@")
+	}
+	if runes := []rune(name); len(runes) > maxUserNameRunes {
+		name = string(runes[:maxUserNameRunes])
+	}
+	return name
+}
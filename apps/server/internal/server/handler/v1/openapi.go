@@ -0,0 +1,739 @@
+package v1
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/lifei6671/plaindoc/apps/server/internal/auth"
+	"github.com/lifei6671/plaindoc/apps/server/internal/backup"
+	"github.com/lifei6671/plaindoc/apps/server/internal/export"
+	"github.com/lifei6671/plaindoc/apps/server/internal/i18n"
+	"github.com/lifei6671/plaindoc/apps/server/internal/openapi"
+	"github.com/lifei6671/plaindoc/apps/server/internal/render"
+	"github.com/lifei6671/plaindoc/apps/server/internal/search"
+	"github.com/lifei6671/plaindoc/apps/server/internal/store"
+)
+
+var (
+	errDocNotFound     = openapi.E(http.StatusNotFound, i18n.DocNotFound)
+	errSpaceNotFound   = openapi.E(http.StatusNotFound, i18n.SpaceNotFound)
+	errModifyDocument  = openapi.E(http.StatusForbidden, i18n.ForbiddenModifyDocument)
+	errManageDocument  = openapi.E(http.StatusForbidden, i18n.ForbiddenManageDocument)
+	errSlugConflict    = openapi.E(http.StatusConflict, i18n.SlugConflict)
+	errDocLocked       = openapi.E(http.StatusConflict, i18n.DocLocked)
+	errVersionConflict = openapi.E(http.StatusConflict, i18n.VersionConflict)
+	errInvalidID       = openapi.E(http.StatusBadRequest, i18n.InvalidID)
+	errInvalidVersion  = openapi.E(http.StatusBadRequest, i18n.InvalidVersion)
+	errUserGone        = openapi.E(http.StatusUnauthorized, i18n.UserGone)
+	errInvalidQuery    = openapi.E(http.StatusBadRequest, i18n.FieldOneOf)
+	errChallengeToken  = openapi.E(http.StatusUnauthorized, i18n.InvalidChallengeToken, i18n.ChallengeTokenExpired)
+	errTwoFactorCode   = openapi.E(http.StatusBadRequest, i18n.InvalidTwoFactorCode)
+	errWeakPassword    = openapi.E(http.StatusBadRequest, i18n.WeakPasswordLength, i18n.WeakPassword)
+	errManageSpace     = openapi.E(http.StatusForbidden, i18n.ForbiddenManageSpace)
+	errTemplateMissing = openapi.E(http.StatusNotFound, i18n.TemplateNotFound)
+	errWebhookMissing  = openapi.E(http.StatusNotFound, i18n.WebhookNotFound)
+	errWebhookInvalid  = openapi.E(http.StatusBadRequest, i18n.InvalidWebhookURL, i18n.InvalidWebhookEvents)
+	errBackupConflict  = openapi.E(http.StatusConflict, i18n.BackupInProgress, i18n.BackupDriverMismatch, i18n.BackupSchemaMismatch)
+
+	spaceQuery        = openapi.Param{Name: "space", Description: "空间 key"}
+	draftQuery        = openapi.Param{Name: "draft", Type: "boolean", Description: "为 true 时叠加未发布的草稿，需要写权限"}
+	exportFormatQuery = openapi.Param{Name: "format", Enum: []string{"markdown", export.FormatConfluence, export.FormatJSON}, Description: "导出格式，默认 markdown"}
+	writers           = []string{auth.RoleAdmin, auth.RoleEditor}
+	admins            = []string{auth.RoleAdmin}
+)
+
+// OpenAPI 返回 v1 全部接口的规范元信息，键为 "方法 路径"（路径不含 Prefix）。
+// 新增或修改路由时同步修改这里，路由与登记不一致时服务无法启动。
+func OpenAPI() map[string]openapi.Operation {
+	session := oneOf(sessionResponse{}, challengeResponse{})
+	return map[string]openapi.Operation{
+		// 账号与登录。
+		"POST /auth/register": {
+			Tag: "auth", Summary: "注册账号",
+			Description: "需要验证邮箱时返回 email_verification=pending 且不签发会话；需要两步验证时返回 challenge。",
+			Body:        registerRequest{}, Status: http.StatusCreated,
+			Response: oneOf(sessionResponse{}, challengeResponse{}, pendingVerificationResponse{}),
+			Errors: []openapi.Error{
+				errWeakPassword,
+				openapi.E(http.StatusForbidden, i18n.RegistrationDisabled),
+				openapi.E(http.StatusConflict, i18n.EmailTaken),
+			},
+		},
+		"POST /auth/login": {
+			Tag: "auth", Summary: "邮箱密码登录",
+			Description: "已开启两步验证时只返回 challenge_token，用 /auth/2fa/login 完成登录。",
+			Body:        loginRequest{}, Response: session,
+			Errors: []openapi.Error{
+				openapi.E(http.StatusUnauthorized, i18n.InvalidCredentials),
+				openapi.E(http.StatusForbidden, i18n.EmailNotVerified),
+				openapi.E(http.StatusTooManyRequests, i18n.AccountLocked, i18n.LoginIPLocked),
+			},
+		},
+		"POST /auth/refresh": {
+			Tag: "auth", Summary: "刷新 access token",
+			Description: "优先读取 refresh token cookie；请求体传 refresh_token 时新的 refresh token 也在响应体中返回。",
+			Body:        refreshRequest{}, BodyOptional: true, Response: sessionResponse{},
+			Errors: []openapi.Error{openapi.E(http.StatusUnauthorized,
+				i18n.RefreshTokenRequired, i18n.InvalidRefreshToken, i18n.RefreshTokenExpired, i18n.RefreshTokenReused, i18n.RefreshTokenUserGone)},
+		},
+		"POST /auth/logout": {
+			Tag: "auth", Summary: "退出当前会话",
+			Body: refreshRequest{}, BodyOptional: true,
+		},
+		"POST /auth/forgot-password": {
+			Tag: "auth", Summary: "发送重置密码邮件",
+			Description: "邮箱是否注册都返回 204；未配置 PUBLIC_URL 时返回 503。",
+			Body:        forgotPasswordRequest{},
+			Errors:      []openapi.Error{openapi.E(http.StatusServiceUnavailable, i18n.PasswordResetUnavailable)},
+		},
+		"POST /auth/reset-password": {
+			Tag: "auth", Summary: "用邮件中的 token 重置密码",
+			Body:   resetPasswordRequest{},
+			Errors: []openapi.Error{openapi.E(http.StatusBadRequest, i18n.InvalidResetToken), errWeakPassword},
+		},
+		"GET /auth/verify-email": {
+			Tag: "auth", Summary: "验证邮箱",
+			Query:    []openapi.Param{{Name: "token", Required: true, Description: "验证邮件中的 token"}},
+			Response: openapi.Object{"user": store.User{}},
+			Errors:   []openapi.Error{openapi.E(http.StatusBadRequest, i18n.FieldRequired, i18n.InvalidVerificationLink)},
+		},
+		"POST /auth/resend-verification": {
+			Tag: "auth", Summary: "重新发送验证邮件",
+			Body: resendVerificationRequest{},
+		},
+		"POST /auth/2fa/enable": {
+			Tag: "auth", Summary: "开始绑定两步验证",
+			Description: "登录用户或携带登录时返回的 setup challenge_token 调用，返回 TOTP 密钥。",
+			Auth:        openapi.AuthSession, Body: enableTwoFactorRequest{}, BodyOptional: true,
+			Response: openapi.Object{"secret": "", "otpauth_url": ""},
+			Errors:   []openapi.Error{errChallengeToken, errUserGone, openapi.E(http.StatusConflict, i18n.TwoFactorAlreadyEnabled)},
+		},
+		"POST /auth/2fa/verify": {
+			Tag: "auth", Summary: "确认绑定两步验证",
+			Description: "返回只显示一次的恢复码；通过 setup challenge_token 绑定时同时签发会话。",
+			Auth:        openapi.AuthSession, Body: verifyTwoFactorRequest{},
+			Response: oneOf(openapi.Object{"recovery_codes": []string{}}, sessionResponse{}),
+			Errors: []openapi.Error{
+				errTwoFactorCode, errChallengeToken, errUserGone,
+				openapi.E(http.StatusConflict, i18n.TwoFactorAlreadyEnabled, i18n.TwoFactorNotStarted),
+			},
+		},
+		"POST /auth/2fa/login": {
+			Tag: "auth", Summary: "用两步验证码完成登录",
+			Body: twoFactorLoginRequest{}, Response: sessionResponse{},
+			Errors: []openapi.Error{
+				errChallengeToken, openapi.E(http.StatusUnauthorized, i18n.InvalidTwoFactorCode),
+				openapi.E(http.StatusTooManyRequests, i18n.AccountLocked, i18n.LoginIPLocked),
+			},
+		},
+		"POST /auth/2fa/disable": {
+			Tag: "auth", Summary: "关闭两步验证",
+			Auth: openapi.AuthSession, Body: twoFactorCodeRequest{},
+			Errors: []openapi.Error{
+				errTwoFactorCode, errUserGone,
+				openapi.E(http.StatusForbidden, i18n.TwoFactorEnforced),
+				openapi.E(http.StatusConflict, i18n.TwoFactorNotEnabled),
+			},
+		},
+		"POST /auth/2fa/recovery-codes": {
+			Tag: "auth", Summary: "重新生成恢复码",
+			Auth: openapi.AuthSession, Body: twoFactorCodeRequest{},
+			Response: openapi.Object{"recovery_codes": []string{}},
+			Errors:   []openapi.Error{errTwoFactorCode, errUserGone, openapi.E(http.StatusConflict, i18n.TwoFactorNotEnabled)},
+		},
+		"GET /auth/oauth/:provider": {
+			Tag: "auth", Summary: "跳转到第三方登录",
+			Status: http.StatusFound,
+			Errors: []openapi.Error{openapi.E(http.StatusNotFound, i18n.OAuthProviderNotFound)},
+		},
+		"GET /auth/oauth/:provider/callback": {
+			Tag: "auth", Summary: "第三方登录回调",
+			Description: "完成登录后跳转回前端，失败时在跳转地址上带 error 参数。",
+			Query: []openapi.Param{
+				{Name: "code"}, {Name: "state"}, {Name: "error"},
+			},
+			Status: http.StatusFound,
+			Errors: []openapi.Error{openapi.E(http.StatusNotFound, i18n.OAuthProviderNotFound)},
+		},
+		"GET /auth/me": {
+			Tag: "auth", Summary: "当前用户",
+			Auth: openapi.AuthUser, Response: sessionResponse{},
+			Errors: []openapi.Error{errUserGone},
+		},
+		"GET /auth/sessions": {
+			Tag: "auth", Summary: "列出当前用户的会话",
+			Auth: openapi.AuthSession, Response: openapi.Items(sessionItem{}),
+		},
+		"DELETE /auth/sessions/:id": {
+			Tag: "auth", Summary: "撤销会话",
+			Auth:   openapi.AuthSession,
+			Errors: []openapi.Error{errInvalidID, openapi.E(http.StatusNotFound, i18n.SessionNotFound)},
+		},
+		"POST /auth/logout-all": {
+			Tag: "auth", Summary: "退出全部会话",
+			Auth: openapi.AuthSession, Response: openapi.Object{"revoked": 0},
+		},
+		"GET /apikeys": {
+			Tag: "api-keys", Summary: "列出 API Key",
+			Auth: openapi.AuthSession, Response: openapi.Items(store.APIKey{}),
+		},
+		"POST /apikeys": {
+			Tag: "api-keys", Summary: "创建 API Key",
+			Description: "明文 key 只在创建时返回一次。",
+			Auth:        openapi.AuthSession, Body: createAPIKeyRequest{}, Status: http.StatusCreated, Response: createdAPIKey{},
+			Errors: []openapi.Error{openapi.E(http.StatusBadRequest, i18n.FieldBlank)},
+		},
+		"DELETE /apikeys/:id": {
+			Tag: "api-keys", Summary: "吊销 API Key",
+			Auth:   openapi.AuthSession,
+			Errors: []openapi.Error{errInvalidID, openapi.E(http.StatusNotFound, i18n.APIKeyNotFound)},
+		},
+
+		// 文档。
+		"GET /docs": {
+			Tag: "docs", Summary: "分页列出文档",
+			Query: openapi.Pagination(
+				spaceQuery,
+				openapi.Param{Name: "q", Description: "按标题关键词过滤"},
+				openapi.Param{Name: "tag", Description: "按标签过滤"},
+				openapi.Param{Name: "status", Enum: []string{store.DocStatusPublished, store.DocStatusDraft, store.DocStatusArchived}, Description: "默认 published"},
+				openapi.Param{Name: "sort", Enum: []string{"-updated_at", "updated_at"}},
+			),
+			Response: openapi.Paged(store.Document{}),
+			Errors:   []openapi.Error{errInvalidQuery, errSpaceNotFound},
+		},
+		"POST /docs": {
+			Tag: "docs", Summary: "创建文档",
+			Auth: openapi.AuthUser, Roles: writers,
+			Query:  []openapi.Param{{Name: "template", Type: "integer", Description: "以模板内容作为正文"}},
+			Body:   createDocumentRequest{},
+			Status: http.StatusCreated, Response: store.Document{},
+			Errors: []openapi.Error{
+				openapi.E(http.StatusBadRequest, i18n.InvalidID, i18n.InvalidSlug, i18n.InvalidTag, i18n.InvalidParentDocument),
+				openapi.E(http.StatusNotFound, i18n.SpaceNotFound, i18n.TemplateNotFound),
+				errSlugConflict,
+			},
+		},
+		"GET /docs/tree": {
+			Tag: "docs", Summary: "空间的文档树",
+			Query:    []openapi.Param{spaceQuery, draftQuery},
+			Response: openapi.Object{"space": "", "items": []treeNode{}},
+			Errors:   []openapi.Error{errSpaceNotFound},
+		},
+		"GET /docs/popular": {
+			Tag: "docs", Summary: "按浏览量排行的文档",
+			Query:    openapi.Pagination(spaceQuery, openapi.Param{Name: "period", Enum: []string{"1d", "7d", "30d", "90d"}, Description: "默认 7d"}),
+			Response: openapi.Paged(store.Document{}),
+			Errors:   []openapi.Error{errInvalidQuery, errSpaceNotFound},
+		},
+		"GET /docs/slug-available": {
+			Tag: "docs", Summary: "检查 slug 是否可用",
+			Description: "不可用时返回 suggestion。",
+			Auth:        openapi.AuthUser, Roles: writers,
+			Query:    []openapi.Param{{Name: "slug", Required: true}, spaceQuery},
+			Response: openapi.Object{"slug": "", "space": "", "available": false, "suggestion": ""},
+			Errors:   []openapi.Error{openapi.E(http.StatusBadRequest, i18n.FieldRequired, i18n.InvalidSlug), errSpaceNotFound},
+		},
+		"GET /docs/:id": {
+			Tag: "docs", Summary: "读取文档",
+			Description: "支持 If-None-Match / If-Modified-Since，未修改时返回 304。",
+			Query:       []openapi.Param{draftQuery},
+			Response:    store.Document{},
+			Errors:      []openapi.Error{errInvalidID, errDocNotFound, errModifyDocument},
+		},
+		"PUT /docs/:id": {
+			Tag: "docs", Summary: "修改文档",
+			Description: "draft=true 时只把标题与正文保存为草稿；修改状态需要管理权限。" +
+				"必须在请求体 version 或 If-Match 头（GET 返回的 ETag，或单独的版本号）中带上读取时的版本号，" +
+				"文档已被他人修改时返回 409 version_conflict，响应的 document 字段是服务端当前的内容。",
+			Auth: openapi.AuthUser, Body: updateDocumentRequest{}, Response: store.Document{},
+			Errors: []openapi.Error{
+				errInvalidID,
+				openapi.E(http.StatusBadRequest, i18n.DraftFieldsOnly, i18n.InvalidSlug, i18n.InvalidTag, i18n.InvalidVersion),
+				errDocNotFound, errModifyDocument, errManageDocument, errSlugConflict,
+				errDocLocked, errVersionConflict,
+				openapi.E(http.StatusPreconditionRequired, i18n.VersionRequired),
+			},
+		},
+		"DELETE /docs/:id": {
+			Tag: "docs", Summary: "把文档移入回收站",
+			Auth:   openapi.AuthUser,
+			Errors: []openapi.Error{errInvalidID, errDocNotFound, errManageDocument, openapi.E(http.StatusConflict, i18n.DocHasChildren), errDocLocked},
+		},
+		"GET /docs/:id/rendered": {
+			Tag: "docs", Summary: "读取渲染后的 HTML 与目录",
+			Query:    []openapi.Param{draftQuery},
+			Response: openapi.Object{"doc_id": int64(0), "updated_at": time.Time{}, "html": "", "toc": []render.Heading{}},
+			Errors:   []openapi.Error{errInvalidID, errDocNotFound, errModifyDocument},
+		},
+		"GET /docs/:id/breadcrumb": {
+			Tag: "docs", Summary: "文档的祖先路径",
+			Description: "无权阅读的祖先只返回 id 与 restricted=true。",
+			Response:    openapi.Object{"space": "", "items": []breadcrumbNode{}},
+			Errors:      []openapi.Error{errInvalidID, errDocNotFound},
+		},
+		"GET /docs/:id/backlinks": {
+			Tag: "docs", Summary: "链接到该文档的文档",
+			Query:    openapi.Pagination(),
+			Response: openapi.Paged(store.Document{}),
+			Errors:   []openapi.Error{errInvalidID, errDocNotFound},
+		},
+		"POST /docs/:id/publish": {
+			Tag: "docs", Summary: "发布文档",
+			Description: "有草稿时把草稿写回文档并生成新版本，草稿或归档状态的文档改为已发布。",
+			Auth:        openapi.AuthUser, Body: publishDocumentRequest{}, BodyOptional: true, Response: store.Document{},
+			Errors: []openapi.Error{errInvalidID, errDocNotFound, errModifyDocument, errSlugConflict, errDocLocked, errVersionConflict},
+		},
+		"POST /docs/:id/schedule": {
+			Tag: "docs", Summary: "设置或取消定时发布",
+			Description: "publish_at 为带时区偏移的 RFC 3339 时间，null 取消定时发布。",
+			Auth:        openapi.AuthUser, Body: scheduleDocumentRequest{}, Response: store.Document{},
+			Errors: []openapi.Error{
+				errInvalidID, openapi.E(http.StatusBadRequest, i18n.InvalidSchedule),
+				errDocNotFound, errModifyDocument, openapi.E(http.StatusConflict, i18n.NothingToPublish),
+				errDocLocked,
+			},
+		},
+		"DELETE /docs/:id/draft": {
+			Tag: "docs", Summary: "丢弃草稿",
+			Auth:   openapi.AuthUser,
+			Errors: []openapi.Error{errInvalidID, errDocNotFound, errModifyDocument, openapi.E(http.StatusNotFound, i18n.DraftNotFound), errDocLocked},
+		},
+		"GET /docs/:id/lock": {
+			Tag: "docs", Summary: "查询编辑锁",
+			Description: "没有人编辑时 lock 为 null。",
+			Auth:        openapi.AuthUser, Response: openapi.Object{"lock": &store.DocumentLock{}},
+			Errors: []openapi.Error{errInvalidID, errDocNotFound},
+		},
+		"POST /docs/:id/lock": {
+			Tag: "docs", Summary: "获取或续期编辑锁",
+			Description: "锁在有效期（DOC_LOCK_TTL，默认 5 分钟）内未续期时自动释放，编辑期间需定期调用作为心跳。",
+			Auth:        openapi.AuthUser, Response: store.DocumentLock{},
+			Errors: []openapi.Error{errInvalidID, errDocNotFound, errModifyDocument, errDocLocked},
+		},
+		"DELETE /docs/:id/lock": {
+			Tag: "docs", Summary: "释放编辑锁",
+			Description: "持锁者释放自己的锁，管理员可以强制解除他人的锁；没有锁时也返回 204。",
+			Auth:        openapi.AuthUser,
+			Errors:      []openapi.Error{errInvalidID, errDocNotFound, openapi.E(http.StatusForbidden, i18n.ForbiddenReleaseLock)},
+		},
+		"GET /docs/:id/versions": {
+			Tag: "docs", Summary: "分页列出历史版本",
+			Query:    openapi.Pagination(),
+			Response: openapi.Paged(store.DocumentVersion{}),
+			Errors:   []openapi.Error{errInvalidID, errDocNotFound},
+		},
+		"GET /docs/:id/versions/:v": {
+			Tag: "docs", Summary: "读取历史版本",
+			Response: store.DocumentVersion{},
+			Errors:   []openapi.Error{errInvalidID, errInvalidVersion, errDocNotFound, openapi.E(http.StatusNotFound, i18n.VersionNotFound)},
+		},
+		"GET /docs/:id/diff": {
+			Tag: "docs", Summary: "比较两个版本",
+			Query: []openapi.Param{
+				{Name: "from", Type: "integer", Required: true},
+				{Name: "to", Type: "integer", Required: true},
+			},
+			Response: diffResponse{},
+			Errors:   []openapi.Error{errInvalidID, errInvalidVersion, errDocNotFound, openapi.E(http.StatusNotFound, i18n.VersionNotFound)},
+		},
+		"POST /docs/:id/revert/:v": {
+			Tag: "docs", Summary: "回滚到历史版本",
+			Description: "以历史版本的内容生成一个新版本。",
+			Auth:        openapi.AuthUser, Response: store.Document{},
+			Errors: []openapi.Error{
+				errInvalidID, errInvalidVersion, errDocNotFound, errModifyDocument,
+				openapi.E(http.StatusNotFound, i18n.VersionNotFound), errSlugConflict,
+				errDocLocked, errVersionConflict,
+			},
+		},
+		"POST /docs/:id/move": {
+			Tag: "docs", Summary: "移动文档",
+			Auth: openapi.AuthUser, Body: moveDocumentRequest{}, Response: store.Document{},
+			Errors: []openapi.Error{
+				errInvalidID, openapi.E(http.StatusBadRequest, i18n.InvalidParentDocument),
+				errDocNotFound, errManageDocument, openapi.E(http.StatusConflict, i18n.TreeCycle),
+			},
+		},
+		"POST /docs/batch": {
+			Tag: "docs", Summary: "批量操作文档",
+			Description: "逐篇处理并返回每篇的结果；atomic=true 时任一篇失败则全部回滚。",
+			Auth:        openapi.AuthUser, Body: batchDocumentsRequest{}, Response: batchReport{},
+			Errors: []openapi.Error{openapi.E(http.StatusBadRequest, i18n.BatchTooLarge, i18n.FieldRequired, i18n.InvalidTag)},
+		},
+		"GET /docs/:id/permissions": {
+			Tag: "docs", Summary: "列出文档授权",
+			Auth: openapi.AuthUser, Response: permissionsResponse{},
+			Errors: []openapi.Error{errInvalidID, errDocNotFound, errManageDocument},
+		},
+		"POST /docs/:id/permissions": {
+			Tag: "docs", Summary: "授予用户或角色权限",
+			Auth: openapi.AuthUser, Body: grantPermissionRequest{}, Status: http.StatusCreated, Response: store.DocPermission{},
+			Errors: []openapi.Error{
+				errInvalidID,
+				openapi.E(http.StatusBadRequest, i18n.GranteeRequired, i18n.InvalidRole, i18n.UserNotFound),
+				errDocNotFound, errManageDocument, openapi.E(http.StatusConflict, i18n.PermissionsInherited),
+			},
+		},
+		"PATCH /docs/:id/permissions": {
+			Tag: "docs", Summary: "修改私有与继承设置",
+			Auth: openapi.AuthUser, Body: updateACLRequest{}, Response: permissionsResponse{},
+			Errors: []openapi.Error{errInvalidID, errDocNotFound, errManageDocument},
+		},
+		"DELETE /docs/:id/permissions/:pid": {
+			Tag: "docs", Summary: "撤销授权",
+			Auth:   openapi.AuthUser,
+			Errors: []openapi.Error{errInvalidID, errDocNotFound, errManageDocument, openapi.E(http.StatusNotFound, i18n.PermissionNotFound)},
+		},
+		"GET /docs/:id/export": {
+			Tag: "export", Summary: "导出单篇文档",
+			Query:       []openapi.Param{{Name: "format", Enum: []string{"pdf", "html"}, Description: "默认 pdf"}},
+			ContentType: "application/pdf",
+			Description: "format=html 时返回自包含的 text/html 页面。",
+			Errors: []openapi.Error{
+				errInvalidQuery, errInvalidID, errDocNotFound,
+				openapi.E(http.StatusInternalServerError, i18n.ExportFailed),
+				openapi.E(http.StatusServiceUnavailable, i18n.ExportUnavailable),
+				openapi.E(http.StatusGatewayTimeout, i18n.ExportTimeout),
+			},
+		},
+		"GET /docs/:id/ws": {
+			Tag: "collab", Summary: "实时协作 WebSocket",
+			Auth: openapi.AuthUser, Status: http.StatusSwitchingProtocols,
+			Errors: []openapi.Error{errInvalidID, errUserGone, errDocNotFound, openapi.E(http.StatusConflict, i18n.RoomFull)},
+		},
+
+		// 评论、收藏与回收站。
+		"GET /docs/:id/comments": {
+			Tag: "comments", Summary: "分页列出评论",
+			Query:    openapi.Pagination(openapi.Param{Name: "sort", Enum: []string{"created_at", "-created_at"}}),
+			Response: openapi.Paged(store.Comment{}),
+			Errors:   []openapi.Error{errInvalidQuery, errInvalidID, errDocNotFound},
+		},
+		"POST /docs/:id/comments": {
+			Tag: "comments", Summary: "发表评论",
+			Auth: openapi.AuthUser, Body: createCommentRequest{}, Status: http.StatusCreated, Response: store.Comment{},
+			Errors: []openapi.Error{errInvalidID, openapi.E(http.StatusBadRequest, i18n.FieldBlank, i18n.InvalidParentComment), errDocNotFound},
+		},
+		"DELETE /comments/:id": {
+			Tag: "comments", Summary: "删除评论",
+			Auth: openapi.AuthUser,
+			Errors: []openapi.Error{
+				errInvalidID,
+				openapi.E(http.StatusForbidden, i18n.ForbiddenDeleteComment),
+				openapi.E(http.StatusNotFound, i18n.CommentNotFound),
+			},
+		},
+		"POST /docs/:id/favorite": {
+			Tag: "favorites", Summary: "收藏文档",
+			Auth:   openapi.AuthUser,
+			Errors: []openapi.Error{errInvalidID, errDocNotFound},
+		},
+		"DELETE /docs/:id/favorite": {
+			Tag: "favorites", Summary: "取消收藏",
+			Auth:   openapi.AuthUser,
+			Errors: []openapi.Error{errInvalidID},
+		},
+		"GET /favorites": {
+			Tag: "favorites", Summary: "分页列出收藏的文档",
+			Auth: openapi.AuthUser, Query: openapi.Pagination(), Response: openapi.Paged(store.Document{}),
+		},
+		"GET /trash": {
+			Tag: "trash", Summary: "分页列出回收站",
+			Description: "非管理员只能看到自己创建或删除的文档。",
+			Auth:        openapi.AuthUser, Query: openapi.Pagination(spaceQuery), Response: openapi.Paged(store.Document{}),
+			Errors: []openapi.Error{errSpaceNotFound},
+		},
+		"POST /docs/:id/restore": {
+			Tag: "trash", Summary: "从回收站恢复",
+			Auth: openapi.AuthUser, Response: store.Document{},
+			Errors: []openapi.Error{errInvalidID, openapi.E(http.StatusNotFound, i18n.DocNotFoundInTrash), errSlugConflict},
+		},
+		"DELETE /docs/:id/purge": {
+			Tag: "trash", Summary: "彻底删除回收站中的文档",
+			Auth: openapi.AuthUser, Roles: admins,
+			Errors: []openapi.Error{
+				errInvalidID,
+				openapi.E(http.StatusNotFound, i18n.DocNotFoundInTrash),
+				openapi.E(http.StatusConflict, i18n.DocHasChildrenInTrash),
+			},
+		},
+
+		// 模板、标签与空间。
+		"GET /templates": {
+			Tag: "templates", Summary: "列出可用模板",
+			Auth: openapi.AuthUser, Response: openapi.Items(store.Template{}),
+		},
+		"GET /templates/:id": {
+			Tag: "templates", Summary: "读取模板",
+			Auth: openapi.AuthUser, Response: store.Template{},
+			Errors: []openapi.Error{errInvalidID, errTemplateMissing},
+		},
+		"POST /templates": {
+			Tag: "templates", Summary: "创建模板",
+			Auth: openapi.AuthUser, Roles: admins, Body: createTemplateRequest{}, Status: http.StatusCreated, Response: store.Template{},
+			Errors: []openapi.Error{
+				openapi.E(http.StatusBadRequest, i18n.FieldRequired, i18n.InvalidRole, i18n.UserNotFound),
+				openapi.E(http.StatusConflict, i18n.TemplateNameTaken),
+			},
+		},
+		"PATCH /templates/:id": {
+			Tag: "templates", Summary: "修改模板",
+			Auth: openapi.AuthUser, Roles: admins, Body: updateTemplateRequest{}, Response: store.Template{},
+			Errors: []openapi.Error{
+				errInvalidID,
+				openapi.E(http.StatusBadRequest, i18n.FieldRequired, i18n.InvalidRole, i18n.UserNotFound),
+				errTemplateMissing, openapi.E(http.StatusConflict, i18n.TemplateNameTaken),
+			},
+		},
+		"DELETE /templates/:id": {
+			Tag: "templates", Summary: "删除模板",
+			Auth: openapi.AuthUser, Roles: admins,
+			Errors: []openapi.Error{errInvalidID, errTemplateMissing},
+		},
+		"GET /tags": {
+			Tag: "tags", Summary: "列出标签及文档数",
+			Query: []openapi.Param{spaceQuery}, Response: openapi.Items(store.Tag{}),
+			Errors: []openapi.Error{errSpaceNotFound},
+		},
+		"PATCH /tags/:id": {
+			Tag: "tags", Summary: "修改标签颜色",
+			Auth: openapi.AuthUser, Roles: writers, Body: updateTagRequest{}, Response: store.Tag{},
+			Errors: []openapi.Error{errInvalidID, openapi.E(http.StatusBadRequest, i18n.InvalidColor), openapi.E(http.StatusNotFound, i18n.TagNotFound)},
+		},
+		"GET /spaces": {
+			Tag: "spaces", Summary: "列出可访问的空间",
+			Response: openapi.Items(store.Space{}),
+		},
+		"POST /spaces": {
+			Tag: "spaces", Summary: "创建空间",
+			Description: "创建者成为空间所有者。",
+			Auth:        openapi.AuthUser, Roles: writers, Body: createSpaceRequest{}, Status: http.StatusCreated, Response: store.Space{},
+			Errors: []openapi.Error{
+				openapi.E(http.StatusBadRequest, i18n.FieldRequired, i18n.InvalidSlug),
+				openapi.E(http.StatusConflict, i18n.SpaceKeyTaken),
+			},
+		},
+		"GET /spaces/:id": {
+			Tag: "spaces", Summary: "读取空间",
+			Response: store.Space{},
+			Errors:   []openapi.Error{errInvalidID, errSpaceNotFound},
+		},
+		"PATCH /spaces/:id": {
+			Tag: "spaces", Summary: "修改空间",
+			Auth: openapi.AuthUser, Body: updateSpaceRequest{}, Response: store.Space{},
+			Errors: []openapi.Error{errInvalidID, openapi.E(http.StatusBadRequest, i18n.FieldRequired), errManageSpace, errSpaceNotFound},
+		},
+		"GET /spaces/:id/members": {
+			Tag: "spaces", Summary: "列出空间成员",
+			Auth: openapi.AuthUser, Response: openapi.Items(store.SpaceMember{}),
+			Errors: []openapi.Error{errInvalidID, openapi.E(http.StatusForbidden, i18n.ForbiddenSpaceMembers), errSpaceNotFound},
+		},
+		"POST /spaces/:id/members": {
+			Tag: "spaces", Summary: "添加成员或修改成员角色",
+			Auth: openapi.AuthUser, Body: spaceMemberRequest{}, Status: http.StatusCreated, Response: openapi.Items(store.SpaceMember{}),
+			Errors: []openapi.Error{
+				errInvalidID, openapi.E(http.StatusBadRequest, i18n.UserNotFound), errManageSpace, errSpaceNotFound,
+				openapi.E(http.StatusConflict, i18n.LastSpaceOwner),
+			},
+		},
+		"DELETE /spaces/:id/members/:uid": {
+			Tag: "spaces", Summary: "移除成员",
+			Auth: openapi.AuthUser,
+			Errors: []openapi.Error{
+				errInvalidID, errManageSpace, openapi.E(http.StatusNotFound, i18n.SpaceNotFound, i18n.UserNotFound),
+				openapi.E(http.StatusConflict, i18n.LastSpaceOwner),
+			},
+		},
+
+		// 检索、渲染与主题。
+		"GET /search": {
+			Tag: "search", Summary: "全文检索",
+			Query: openapi.Pagination(
+				openapi.Param{Name: "q", Required: true},
+				spaceQuery,
+				openapi.Param{Name: "sort", Enum: []string{search.SortRelevance, search.SortUpdatedAtDesc, search.SortUpdatedAtAsc}},
+			),
+			Response: openapi.Paged(search.Hit{}),
+			Errors:   []openapi.Error{openapi.E(http.StatusBadRequest, i18n.SearchTermsRequired, i18n.FieldOneOf), errSpaceNotFound},
+		},
+		"POST /render": {
+			Tag: "render", Summary: "渲染 Markdown 预览",
+			Body: renderRequest{}, Response: render.Result{},
+		},
+		"GET /theme": {
+			Tag: "theme", Summary: "读取主题",
+			Response: themeResponse{},
+		},
+		"PUT /theme": {
+			Tag: "theme", Summary: "修改个人主题偏好",
+			Description: "theme 为空时跟随全站主题。",
+			Auth:        openapi.AuthUser, Body: updateUserThemeRequest{}, Response: themeResponse{},
+			Errors: []openapi.Error{openapi.E(http.StatusBadRequest, i18n.InvalidTheme), errUserGone},
+		},
+		"PUT /admin/theme": {
+			Tag: "theme", Summary: "修改全站主题",
+			Auth: openapi.AuthUser, Roles: admins, Body: updateSiteThemeRequest{}, Response: themeResponse{},
+			Errors: []openapi.Error{openapi.E(http.StatusBadRequest, i18n.InvalidThemeSetting)},
+		},
+
+		// 导入导出、上传与异步作业。
+		"GET /export": {
+			Tag: "export", Summary: "同步导出整个空间",
+			Description: "以 zip 流式返回空间内的文档：markdown 为带 front-matter 的 .md 文件，confluence 为 Confluence storage format 的 .xhtml 文件，json 为包含正文 AST 的 .json 文件；后两种格式另附 manifest.json 与列出无法无损转换内容的 report.json。大空间建议用 POST /export/jobs。",
+			Auth:        openapi.AuthUser,
+			Query:       []openapi.Param{spaceQuery, exportFormatQuery},
+			ContentType: "application/zip",
+			Errors:      []openapi.Error{errInvalidQuery, errSpaceNotFound},
+		},
+		"POST /export/jobs": {
+			Tag: "export", Summary: "提交整空间导出作业",
+			Auth:   openapi.AuthUser,
+			Query:  []openapi.Param{spaceQuery, exportFormatQuery},
+			Status: http.StatusAccepted, Response: store.Job{},
+			Errors: []openapi.Error{errInvalidQuery, errSpaceNotFound, openapi.E(http.StatusServiceUnavailable, i18n.JobQueueFull)},
+		},
+		"GET /jobs": {
+			Tag: "jobs", Summary: "分页列出自己提交的作业",
+			Auth: openapi.AuthUser, Query: openapi.Pagination(), Response: openapi.Paged(store.Job{}),
+		},
+		"GET /jobs/:id": {
+			Tag: "jobs", Summary: "查询作业状态",
+			Auth: openapi.AuthUser, Response: store.Job{},
+			Errors: []openapi.Error{errInvalidID, openapi.E(http.StatusNotFound, i18n.JobNotFound)},
+		},
+		"GET /jobs/:id/download": {
+			Tag: "jobs", Summary: "下载作业生成的文件",
+			Auth: openapi.AuthUser, ContentType: "application/octet-stream",
+			Errors: []openapi.Error{
+				errInvalidID,
+				openapi.E(http.StatusNotFound, i18n.JobNotFound, i18n.JobFileNotFound),
+				openapi.E(http.StatusConflict, i18n.JobNotFinished),
+			},
+		},
+		"POST /uploads": {
+			Tag: "uploads", Summary: "上传文件",
+			Auth: openapi.AuthUser, Roles: writers,
+			Form:   []openapi.Param{{Name: "file", Type: "file", Required: true}},
+			Status: http.StatusCreated, Response: uploadResponse{},
+			Errors: []openapi.Error{
+				openapi.E(http.StatusBadRequest, i18n.FieldRequired, i18n.FileEmpty),
+				openapi.E(http.StatusRequestEntityTooLarge, i18n.FileTooLarge),
+				openapi.E(http.StatusUnsupportedMediaType, i18n.UnsupportedFileType),
+			},
+		},
+		"POST /import": {
+			Tag: "import", Summary: "导入 Markdown 压缩包",
+			Auth: openapi.AuthUser, Roles: writers,
+			Form: []openapi.Param{
+				{Name: "file", Type: "file", Required: true, Description: "包含 Markdown 与图片的 zip"},
+				{Name: "space", Description: "目标空间，默认 default"},
+				{Name: "conflict", Enum: []string{ConflictSkip, ConflictOverwrite, ConflictRename}, Description: "slug 冲突时的处理方式，默认 skip"},
+			},
+			Response: importReport{},
+			Errors: []openapi.Error{
+				openapi.E(http.StatusBadRequest, i18n.FieldRequired, i18n.FieldOneOf, i18n.FieldLength, i18n.InvalidArchive),
+				errSpaceNotFound,
+				openapi.E(http.StatusRequestEntityTooLarge, i18n.ArchiveTooLarge),
+			},
+		},
+
+		// 系统管理。
+		"GET /admin/users": {
+			Tag: "users", Summary: "分页列出用户",
+			Auth: openapi.AuthUser, Roles: admins, Query: openapi.Pagination(), Response: openapi.Paged(store.User{}),
+		},
+		"PUT /admin/users/:id/role": {
+			Tag: "users", Summary: "修改用户角色",
+			Auth: openapi.AuthUser, Roles: admins, Body: updateRoleRequest{}, Response: store.User{},
+			Errors: []openapi.Error{
+				errInvalidID, openapi.E(http.StatusBadRequest, i18n.InvalidRole),
+				openapi.E(http.StatusNotFound, i18n.UserNotFound), openapi.E(http.StatusConflict, i18n.LastAdmin),
+			},
+		},
+		"POST /admin/users/:id/unlock": {
+			Tag: "users", Summary: "解除登录锁定",
+			Auth: openapi.AuthUser, Roles: admins,
+			Errors: []openapi.Error{errInvalidID, openapi.E(http.StatusNotFound, i18n.UserNotFound)},
+		},
+		"GET /admin/config": {
+			Tag: "config", Summary: "列出系统配置",
+			Auth: openapi.AuthUser, Roles: admins, Response: openapi.Items(configItem{}),
+		},
+		"PUT /admin/config": {
+			Tag: "config", Summary: "批量修改系统配置",
+			Description: "请求体为 {\"key\": value}，任一项不合法时整体不生效。",
+			Auth:        openapi.AuthUser, Roles: admins, Body: map[string]any{}, Response: updateConfigResponse{},
+			Errors: []openapi.Error{openapi.E(http.StatusBadRequest, i18n.ConfigKeysRequired, i18n.InvalidConfig)},
+		},
+		"GET /admin/config/audit": {
+			Tag: "config", Summary: "分页列出配置变更历史",
+			Auth: openapi.AuthUser, Roles: admins,
+			Query:    openapi.Pagination(openapi.Param{Name: "key", Description: "只看该配置项"}),
+			Response: openapi.Paged(store.ConfigAuditLog{}),
+		},
+		"GET /admin/webhooks": {
+			Tag: "webhooks", Summary: "列出 webhook",
+			Auth: openapi.AuthUser, Roles: admins, Response: openapi.Items(store.Webhook{}),
+		},
+		"POST /admin/webhooks": {
+			Tag: "webhooks", Summary: "创建 webhook",
+			Description: "签名密钥只在创建时返回一次。",
+			Auth:        openapi.AuthUser, Roles: admins, Body: createWebhookRequest{}, Status: http.StatusCreated, Response: createdWebhook{},
+			Errors: []openapi.Error{errWebhookInvalid},
+		},
+		"PATCH /admin/webhooks/:id": {
+			Tag: "webhooks", Summary: "修改 webhook",
+			Auth: openapi.AuthUser, Roles: admins, Body: updateWebhookRequest{}, Response: store.Webhook{},
+			Errors: []openapi.Error{errInvalidID, errWebhookInvalid, errWebhookMissing},
+		},
+		"DELETE /admin/webhooks/:id": {
+			Tag: "webhooks", Summary: "删除 webhook",
+			Auth: openapi.AuthUser, Roles: admins,
+			Errors: []openapi.Error{errInvalidID, errWebhookMissing},
+		},
+		"GET /admin/webhooks/:id/deliveries": {
+			Tag: "webhooks", Summary: "分页列出投递记录",
+			Auth: openapi.AuthUser, Roles: admins, Query: openapi.Pagination(), Response: openapi.Paged(store.WebhookDelivery{}),
+			Errors: []openapi.Error{errInvalidID, errWebhookMissing},
+		},
+		"GET /admin/backups": {
+			Tag: "backups", Summary: "列出保存的备份",
+			Auth: openapi.AuthUser, Roles: admins, Response: openapi.Items(backup.File{}),
+		},
+		"POST /admin/backup": {
+			Tag: "backups", Summary: "备份数据库",
+			Description: "默认直接下载备份文件；save=true 时保存到备份目录并返回 201 与文件信息。",
+			Auth:        openapi.AuthUser, Roles: admins,
+			Query: []openapi.Param{
+				{Name: "save", Type: "boolean"},
+				{Name: "exclude", Description: "逗号分隔的表名，备份时跳过这些表"},
+			},
+			ContentType: backup.ContentType,
+			Errors: []openapi.Error{
+				openapi.E(http.StatusBadRequest, i18n.UnknownBackupTable, i18n.InvalidBackup),
+				errBackupConflict,
+			},
+		},
+		"POST /admin/restore": {
+			Tag: "backups", Summary: "从备份恢复数据库",
+			Description: "用备份替换数据库中的全部数据，失败时数据保持原样。",
+			Auth:        openapi.AuthUser, Roles: admins,
+			Form: []openapi.Param{
+				{Name: "file", Type: "file", Required: true},
+				{Name: "confirm", Required: true, Enum: []string{restoreConfirmation}},
+			},
+			Response: backup.RestoreResult{},
+			Errors: []openapi.Error{
+				openapi.E(http.StatusBadRequest, i18n.FieldRequired, i18n.ConfirmationRequired, i18n.InvalidBackup),
+				openapi.E(http.StatusRequestEntityTooLarge, i18n.BackupTooLarge),
+				errBackupConflict,
+			},
+		},
+	}
+}
+
+// oneOf 是 openapi.OneOf 的简写。
+func oneOf(items ...any) openapi.OneOf {
+	return openapi.OneOf(items)
+}
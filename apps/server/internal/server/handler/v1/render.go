@@ -0,0 +1,34 @@
+package v1
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/lifei6671/plaindoc/apps/server/internal/render"
+	"github.com/lifei6671/plaindoc/apps/server/internal/server/httpx"
+)
+
+// maxRenderBytes 限制单次渲染的请求体大小。
+const maxRenderBytes = 2 << 20
+
+type renderRequest struct {
+	Markdown string `json:"markdown"`
+}
+
+// Render 把 Markdown 渲染为消毒后的 HTML，并返回标题目录。
+func Render(renderer *render.Renderer) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		c.Request.Body = http.MaxBytesReader(c.Writer, c.Request.Body, maxRenderBytes)
+
+		var req renderRequest
+		if !httpx.BindAndValidate(c, &req) {
+			return
+		}
+		result, err := renderer.Render([]byte(req.Markdown))
+		if err != nil {
+			httpx.AbortInternal(c, err)
+			return
+		}
+		c.JSON(http.StatusOK, result)
+	}
+}
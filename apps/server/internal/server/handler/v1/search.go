@@ -0,0 +1,57 @@
+package v1
+
+import (
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/lifei6671/plaindoc/apps/server/internal/i18n"
+	"github.com/lifei6671/plaindoc/apps/server/internal/search"
+	"github.com/lifei6671/plaindoc/apps/server/internal/server/httpx"
+)
+
+// Search 处理全文检索接口，具体检索后端由 search.Indexer 决定。
+type Search struct {
+	indexer search.Indexer
+}
+
+func NewSearch(indexer search.Indexer) *Search {
+	return &Search{indexer: indexer}
+}
+
+// Search 支持 q 关键字、space 空间、page/page_size 分页以及 sort=relevance|-updated_at|updated_at 排序。
+func (h *Search) Search(c *gin.Context) {
+	text := strings.TrimSpace(c.Query("q"))
+	if len(search.Tokenize(text)) == 0 {
+		httpx.Abort(c, http.StatusBadRequest, i18n.SearchTermsRequired)
+		return
+	}
+
+	sort := c.DefaultQuery("sort", search.SortRelevance)
+	switch sort {
+	case search.SortRelevance, search.SortUpdatedAtDesc, search.SortUpdatedAtAsc:
+	default:
+		httpx.Abort(c, http.StatusBadRequest, i18n.FieldOneOf, "sort", "relevance, updated_at, -updated_at")
+		return
+	}
+
+	pagination, ok := httpx.ParsePagination(c)
+	if !ok {
+		return
+	}
+	user, _ := httpx.CurrentUser(c)
+	result, err := h.indexer.Search(c.Request.Context(), search.Query{
+		Text:       text,
+		Space:      c.Query("space"),
+		Sort:       sort,
+		Limit:      pagination.Limit(),
+		Offset:     pagination.Offset(),
+		ViewerID:   user.ID,
+		ViewerRole: user.Role,
+	})
+	if err != nil {
+		httpx.AbortInternal(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, httpx.NewPagedResponse(result.Hits, result.Total, pagination))
+}
@@ -0,0 +1,259 @@
+package v1
+
+import (
+	"errors"
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/lifei6671/plaindoc/apps/server/internal/auth"
+	"github.com/lifei6671/plaindoc/apps/server/internal/i18n"
+	"github.com/lifei6671/plaindoc/apps/server/internal/server/httpx"
+	"github.com/lifei6671/plaindoc/apps/server/internal/store"
+)
+
+// Space 处理空间与成员接口：编辑者可创建空间并成为所有者，空间设置与成员由所有者或管理员管理。
+type Space struct {
+	store *store.Store
+}
+
+func NewSpace(s *store.Store) *Space {
+	return &Space{store: s}
+}
+
+type createSpaceRequest struct {
+	// Key 是文档与标签引用空间时使用的标识，规则与文档 slug 相同，创建后不可修改。
+	Key         string `json:"key" binding:"required,max=64"`
+	Name        string `json:"name" binding:"required,max=100"`
+	Description string `json:"description" binding:"max=255"`
+	// IsPrivate 默认为 true，私有空间只对成员与管理员可见。
+	IsPrivate *bool `json:"is_private"`
+}
+
+type updateSpaceRequest struct {
+	Name        *string `json:"name" binding:"omitempty,max=100"`
+	Description *string `json:"description" binding:"omitempty,max=255"`
+	IsPrivate   *bool   `json:"is_private"`
+}
+
+type spaceMemberRequest struct {
+	UserID int64 `json:"user_id" binding:"required"`
+	// Role 默认为 member。
+	Role string `json:"role" binding:"omitempty,oneof=owner member"`
+}
+
+// List 返回当前用户可访问的空间：管理员为全部空间，其他人为公开空间与自己加入的空间。
+func (h *Space) List(c *gin.Context) {
+	spaces, err := h.store.ListSpaces(c.Request.Context(), currentViewer(c))
+	if err != nil {
+		httpx.AbortInternal(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"items": spaces})
+}
+
+func (h *Space) Get(c *gin.Context) {
+	space, ok := h.load(c)
+	if !ok {
+		return
+	}
+	c.JSON(http.StatusOK, space)
+}
+
+func (h *Space) Create(c *gin.Context) {
+	var req createSpaceRequest
+	if !httpx.BindAndValidate(c, &req) {
+		return
+	}
+	user, _ := httpx.CurrentUser(c)
+	space := &store.Space{
+		Key:         strings.ToLower(strings.TrimSpace(req.Key)),
+		Description: strings.TrimSpace(req.Description),
+		IsPrivate:   req.IsPrivate == nil || *req.IsPrivate,
+		CreatedBy:   &user.ID,
+	}
+	if !validSlug(c, space.Key) {
+		return
+	}
+	var ok bool
+	if space.Name, ok = spaceName(c, req.Name); !ok {
+		return
+	}
+	if err := h.store.CreateSpace(c.Request.Context(), space); err != nil {
+		abortSpaceError(c, err)
+		return
+	}
+	c.JSON(http.StatusCreated, space)
+}
+
+// Update 修改空间名称、说明与可见范围，未提供的字段保持不变。
+func (h *Space) Update(c *gin.Context) {
+	space, ok := h.loadManaged(c)
+	if !ok {
+		return
+	}
+	var req updateSpaceRequest
+	if !httpx.BindAndValidate(c, &req) {
+		return
+	}
+	if req.Name != nil {
+		if space.Name, ok = spaceName(c, *req.Name); !ok {
+			return
+		}
+	}
+	if req.Description != nil {
+		space.Description = strings.TrimSpace(*req.Description)
+	}
+	if req.IsPrivate != nil {
+		space.IsPrivate = *req.IsPrivate
+	}
+	if err := h.store.UpdateSpace(c.Request.Context(), space); err != nil {
+		abortSpaceError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, space)
+}
+
+// Members 返回空间成员，只有成员与管理员可以查看。
+func (h *Space) Members(c *gin.Context) {
+	space, ok := h.load(c)
+	if !ok {
+		return
+	}
+	if space.MemberRole == "" && currentViewer(c).Role != auth.RoleAdmin {
+		httpx.Abort(c, http.StatusForbidden, i18n.ForbiddenSpaceMembers)
+		return
+	}
+	members, err := h.store.ListSpaceMembers(c.Request.Context(), space.ID)
+	if err != nil {
+		httpx.AbortInternal(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"items": members})
+}
+
+// AddMember 把用户加入空间，已是成员时修改其角色，返回更新后的成员列表。
+func (h *Space) AddMember(c *gin.Context) {
+	space, ok := h.loadManaged(c)
+	if !ok {
+		return
+	}
+	var req spaceMemberRequest
+	if !httpx.BindAndValidate(c, &req) {
+		return
+	}
+	if req.Role == "" {
+		req.Role = store.SpaceRoleMember
+	}
+	ctx := c.Request.Context()
+	if _, err := h.store.GetUser(ctx, req.UserID); err != nil {
+		if errors.Is(err, store.ErrNotFound) {
+			httpx.Abort(c, http.StatusBadRequest, i18n.UserNotFound)
+			return
+		}
+		httpx.AbortInternal(c, err)
+		return
+	}
+	if err := h.store.SetSpaceMember(ctx, space.ID, req.UserID, req.Role); err != nil {
+		abortSpaceError(c, err)
+		return
+	}
+	members, err := h.store.ListSpaceMembers(ctx, space.ID)
+	if err != nil {
+		httpx.AbortInternal(c, err)
+		return
+	}
+	c.JSON(http.StatusCreated, gin.H{"items": members})
+}
+
+// RemoveMember 把用户移出空间，空间至少要保留一个所有者。
+func (h *Space) RemoveMember(c *gin.Context) {
+	space, ok := h.loadManaged(c)
+	if !ok {
+		return
+	}
+	userID, ok := httpx.ParamID(c, "uid")
+	if !ok {
+		return
+	}
+	if err := h.store.RemoveSpaceMember(c.Request.Context(), space.ID, userID); err != nil {
+		if errors.Is(err, store.ErrNotFound) {
+			httpx.Abort(c, http.StatusNotFound, i18n.UserNotFound)
+			return
+		}
+		abortSpaceError(c, err)
+		return
+	}
+	c.Status(http.StatusNoContent)
+}
+
+// load 读取路径中的空间并填充当前用户的成员角色；不可访问的私有空间与不存在的空间一样返回 404。
+func (h *Space) load(c *gin.Context) (*store.Space, bool) {
+	id, ok := httpx.ParamID(c, "id")
+	if !ok {
+		return nil, false
+	}
+	ctx := c.Request.Context()
+	viewer := currentViewer(c)
+	space, err := h.store.GetSpace(ctx, id)
+	if err == nil && viewer.ID != 0 {
+		space.MemberRole, err = h.store.SpaceMemberRole(ctx, space.ID, viewer.ID)
+	}
+	if err == nil && space.IsPrivate && space.MemberRole == "" && viewer.Role != auth.RoleAdmin {
+		err = store.ErrNotFound
+	}
+	if err != nil {
+		abortSpaceError(c, err)
+		return nil, false
+	}
+	return space, true
+}
+
+// loadManaged 在 load 的基础上要求当前用户是空间所有者或管理员。
+func (h *Space) loadManaged(c *gin.Context) (*store.Space, bool) {
+	space, ok := h.load(c)
+	if !ok {
+		return nil, false
+	}
+	if space.MemberRole != store.SpaceRoleOwner && currentViewer(c).Role != auth.RoleAdmin {
+		httpx.Abort(c, http.StatusForbidden, i18n.ForbiddenManageSpace)
+		return nil, false
+	}
+	return space, true
+}
+
+// checkSpace 确认 key 对应的空间存在且当前用户可访问，否则返回 404，用于在空间中创建文档。
+func checkSpace(c *gin.Context, s *store.Store, key string) bool {
+	accessible, err := s.CanAccessSpace(c.Request.Context(), currentViewer(c), key)
+	if err != nil {
+		httpx.AbortInternal(c, err)
+		return false
+	}
+	if !accessible {
+		httpx.Abort(c, http.StatusNotFound, i18n.SpaceNotFound)
+		return false
+	}
+	return true
+}
+
+func spaceName(c *gin.Context, name string) (string, bool) {
+	name = strings.TrimSpace(name)
+	if name == "" {
+		httpx.Abort(c, http.StatusBadRequest, i18n.FieldRequired, "name")
+		return "", false
+	}
+	return name, true
+}
+
+func abortSpaceError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, store.ErrNotFound):
+		httpx.Abort(c, http.StatusNotFound, i18n.SpaceNotFound)
+	case errors.Is(err, store.ErrDuplicate):
+		httpx.Abort(c, http.StatusConflict, i18n.SpaceKeyTaken)
+	case errors.Is(err, store.ErrLastSpaceOwner):
+		httpx.Abort(c, http.StatusConflict, i18n.LastSpaceOwner)
+	default:
+		httpx.AbortInternal(c, err)
+	}
+}
@@ -0,0 +1,170 @@
+package v1
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/lifei6671/plaindoc/apps/server/internal/i18n"
+	"github.com/lifei6671/plaindoc/apps/server/internal/server/httpx"
+	"github.com/lifei6671/plaindoc/apps/server/internal/settings"
+	"github.com/lifei6671/plaindoc/apps/server/internal/store"
+)
+
+// SystemConfig 处理管理员的系统配置接口，路由层需先经过 RequireRole(admin)。
+type SystemConfig struct {
+	store    *store.Store
+	settings *settings.Service
+}
+
+func NewSystemConfig(s *store.Store, settingsService *settings.Service) *SystemConfig {
+	return &SystemConfig{store: s, settings: settingsService}
+}
+
+type configItem struct {
+	Key       string     `json:"key"`
+	Type      string     `json:"type"`
+	Value     any        `json:"value"`
+	Default   any        `json:"default"`
+	Options   []string   `json:"options,omitempty"`
+	Sensitive bool       `json:"sensitive"`
+	UpdatedBy *int64     `json:"updated_by"`
+	UpdatedAt *time.Time `json:"updated_at"`
+}
+
+type updateConfigResponse struct {
+	Items   []configItem           `json:"items"`
+	Changes []store.ConfigAuditLog `json:"changes"`
+}
+
+// List 返回全部可修改的配置及其当前值，未保存过的配置取默认值，敏感配置已脱敏。
+func (h *SystemConfig) List(c *gin.Context) {
+	snapshot, err := h.settings.Get(c.Request.Context())
+	if err != nil {
+		httpx.AbortInternal(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"items": configItems(snapshot)})
+}
+
+// Update 批量修改配置，请求体为 {"key": value}；任一项不合法时整体不生效并返回 400。
+func (h *SystemConfig) Update(c *gin.Context) {
+	var req map[string]json.RawMessage
+	if !httpx.BindAndValidate(c, &req) {
+		return
+	}
+	if len(req) == 0 {
+		httpx.Abort(c, http.StatusBadRequest, i18n.ConfigKeysRequired)
+		return
+	}
+	values := make(map[string]string, len(req))
+	for key, raw := range req {
+		value, err := configValueString(raw)
+		if err != nil {
+			httpx.Abort(c, http.StatusBadRequest, i18n.InvalidConfig, key, err.Error())
+			return
+		}
+		values[key] = value
+	}
+
+	user, _ := httpx.CurrentUser(c)
+	changes, err := h.settings.Update(c.Request.Context(), values, user.ID)
+	var invalid *settings.ValidationError
+	if errors.As(err, &invalid) {
+		httpx.Abort(c, http.StatusBadRequest, i18n.InvalidConfig, invalid.Key, invalid.Reason)
+		return
+	}
+	if err != nil {
+		httpx.AbortInternal(c, err)
+		return
+	}
+	snapshot, err := h.settings.Get(c.Request.Context())
+	if err != nil {
+		httpx.AbortInternal(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, updateConfigResponse{Items: configItems(snapshot), Changes: changes})
+}
+
+// Audit 按时间倒序分页返回配置变更历史，可用 key 过滤单个配置。
+func (h *SystemConfig) Audit(c *gin.Context) {
+	pagination, ok := httpx.ParsePagination(c)
+	if !ok {
+		return
+	}
+	logs, total, err := h.store.ListConfigAuditLogs(c.Request.Context(), store.ConfigAuditFilter{
+		Key:    strings.TrimSpace(c.Query("key")),
+		Limit:  pagination.Limit(),
+		Offset: pagination.Offset(),
+	})
+	if err != nil {
+		httpx.AbortInternal(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, httpx.NewPagedResponse(logs, total, pagination))
+}
+
+func configItems(snapshot settings.Snapshot) []configItem {
+	items := make([]configItem, 0, len(settings.Definitions))
+	for _, def := range settings.Definitions {
+		item := configItem{
+			Key:       def.Key,
+			Type:      string(def.Type),
+			Value:     typedConfigValue(def, snapshot.String(def.Key)),
+			Default:   typedConfigValue(def, def.Default),
+			Options:   def.Options,
+			Sensitive: def.Sensitive,
+		}
+		if stored, ok := snapshot.Stored(def.Key); ok {
+			item.UpdatedBy, item.UpdatedAt = stored.UpdatedBy, &stored.UpdatedAt
+		}
+		if def.Sensitive {
+			item.Default = nil
+			if item.Value != "" {
+				item.Value = store.MaskedConfigValue
+			}
+		}
+		items = append(items, item)
+	}
+	return items
+}
+
+// typedConfigValue 把布尔与整数配置按 JSON 原生类型输出。
+func typedConfigValue(def settings.Definition, value string) any {
+	switch def.Type {
+	case settings.TypeBool:
+		if b, err := strconv.ParseBool(value); err == nil {
+			return b
+		}
+	case settings.TypeInt:
+		if n, err := strconv.ParseInt(value, 10, 64); err == nil {
+			return n
+		}
+	}
+	return value
+}
+
+// configValueString 接受 JSON 字符串、布尔或数字，统一转换为字符串交给 settings 校验。
+func configValueString(raw json.RawMessage) (string, error) {
+	var value any
+	decoder := json.NewDecoder(strings.NewReader(string(raw)))
+	decoder.UseNumber()
+	if err := decoder.Decode(&value); err != nil {
+		return "", err
+	}
+	switch v := value.(type) {
+	case string:
+		return v, nil
+	case bool:
+		return strconv.FormatBool(v), nil
+	case json.Number:
+		return v.String(), nil
+	default:
+		return "", fmt.Errorf("value must be a string, boolean or number")
+	}
+}
@@ -0,0 +1,78 @@
+package v1
+
+import (
+	"errors"
+	"net/http"
+	"regexp"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/lifei6671/plaindoc/apps/server/internal/i18n"
+	"github.com/lifei6671/plaindoc/apps/server/internal/server/httpx"
+	"github.com/lifei6671/plaindoc/apps/server/internal/store"
+)
+
+var tagColorPattern = regexp.MustCompile(`^#[0-9a-f]{6}$`)
+
+// Tag 处理标签列表与标签属性修改；标签本身随文档的 tags 字段创建。
+type Tag struct {
+	store *store.Store
+}
+
+func NewTag(s *store.Store) *Tag {
+	return &Tag{store: s}
+}
+
+type updateTagRequest struct {
+	// Color 为 #rrggbb，空串表示使用前端默认配色。
+	Color *string `json:"color" binding:"required"`
+}
+
+// List 返回当前访问者可访问空间中的标签（可用 space 限定空间）及各自关联的文档数，只统计当前访问者可见的文档。
+func (h *Tag) List(c *gin.Context) {
+	tags, err := h.store.ListTags(c.Request.Context(), currentViewer(c), c.Query("space"))
+	if err != nil {
+		httpx.AbortInternal(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"items": tags})
+}
+
+func (h *Tag) Update(c *gin.Context) {
+	id, ok := httpx.ParamID(c, "id")
+	if !ok {
+		return
+	}
+	var req updateTagRequest
+	if !httpx.BindAndValidate(c, &req) {
+		return
+	}
+	color := strings.ToLower(strings.TrimSpace(*req.Color))
+	if color != "" && !tagColorPattern.MatchString(color) {
+		httpx.Abort(c, http.StatusBadRequest, i18n.InvalidColor)
+		return
+	}
+
+	// 不可访问的空间中的标签与不存在的标签一样返回 404。
+	ctx := c.Request.Context()
+	tag, err := h.store.GetTag(ctx, id)
+	if err == nil {
+		var accessible bool
+		if accessible, err = h.store.CanAccessSpace(ctx, currentViewer(c), tag.Space); err == nil && !accessible {
+			err = store.ErrNotFound
+		}
+	}
+	if err == nil {
+		err = h.store.UpdateTagColor(ctx, id, color)
+	}
+	if errors.Is(err, store.ErrNotFound) {
+		httpx.Abort(c, http.StatusNotFound, i18n.TagNotFound)
+		return
+	}
+	if err != nil {
+		httpx.AbortInternal(c, err)
+		return
+	}
+	tag.Color = color
+	c.JSON(http.StatusOK, tag)
+}
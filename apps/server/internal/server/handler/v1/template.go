@@ -0,0 +1,232 @@
+package v1
+
+import (
+	"errors"
+	"net/http"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/lifei6671/plaindoc/apps/server/internal/auth"
+	"github.com/lifei6671/plaindoc/apps/server/internal/i18n"
+	"github.com/lifei6671/plaindoc/apps/server/internal/server/httpx"
+	"github.com/lifei6671/plaindoc/apps/server/internal/store"
+)
+
+// Template 处理文档模板接口：登录用户可查看可见的模板，增删改仅限管理员，路由层需先经过 RequireRole(admin)。
+type Template struct {
+	store *store.Store
+}
+
+func NewTemplate(s *store.Store) *Template {
+	return &Template{store: s}
+}
+
+type createTemplateRequest struct {
+	Name        string `json:"name" binding:"required,max=100"`
+	Description string `json:"description" binding:"max=255"`
+	// Content 中的 {{date}}、{{author}}、{{title}} 在创建文档时替换为当天日期、作者名与文档标题。
+	Content string `json:"content"`
+	// IsPrivate 为 true 时模板只对创建者、管理员以及 UserIDs、Roles 中的用户与角色可见。
+	IsPrivate bool     `json:"is_private"`
+	UserIDs   []int64  `json:"user_ids" binding:"max=100"`
+	Roles     []string `json:"roles"`
+}
+
+type updateTemplateRequest struct {
+	Name        *string `json:"name" binding:"omitempty,max=100"`
+	Description *string `json:"description" binding:"omitempty,max=255"`
+	Content     *string `json:"content"`
+	IsPrivate   *bool   `json:"is_private"`
+	// UserIDs 与 Roles 非 nil 时整体替换对应的授权对象。
+	UserIDs *[]int64  `json:"user_ids" binding:"omitempty,max=100"`
+	Roles   *[]string `json:"roles"`
+}
+
+// List 返回当前用户可见的全部模板。
+func (h *Template) List(c *gin.Context) {
+	templates, err := h.store.ListTemplates(c.Request.Context(), currentViewer(c))
+	if err != nil {
+		httpx.AbortInternal(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"items": templates})
+}
+
+func (h *Template) Get(c *gin.Context) {
+	tmpl, ok := h.load(c)
+	if !ok {
+		return
+	}
+	c.JSON(http.StatusOK, tmpl)
+}
+
+func (h *Template) Create(c *gin.Context) {
+	var req createTemplateRequest
+	if !httpx.BindAndValidate(c, &req) {
+		return
+	}
+	user, _ := httpx.CurrentUser(c)
+	tmpl := &store.Template{
+		Description: strings.TrimSpace(req.Description),
+		Content:     req.Content,
+		IsPrivate:   req.IsPrivate,
+		CreatedBy:   &user.ID,
+	}
+	var ok bool
+	if tmpl.Name, ok = templateName(c, req.Name); !ok {
+		return
+	}
+	if tmpl.UserIDs, ok = h.templateUsers(c, req.UserIDs); !ok {
+		return
+	}
+	if tmpl.Roles, ok = templateRoles(c, req.Roles); !ok {
+		return
+	}
+	if err := h.store.CreateTemplate(c.Request.Context(), tmpl); err != nil {
+		abortTemplateError(c, err)
+		return
+	}
+	c.JSON(http.StatusCreated, tmpl)
+}
+
+// Update 修改模板，未提供的字段保持不变。
+func (h *Template) Update(c *gin.Context) {
+	tmpl, ok := h.load(c)
+	if !ok {
+		return
+	}
+	var req updateTemplateRequest
+	if !httpx.BindAndValidate(c, &req) {
+		return
+	}
+	if req.Name != nil {
+		if tmpl.Name, ok = templateName(c, *req.Name); !ok {
+			return
+		}
+	}
+	if req.Description != nil {
+		tmpl.Description = strings.TrimSpace(*req.Description)
+	}
+	if req.Content != nil {
+		tmpl.Content = *req.Content
+	}
+	if req.IsPrivate != nil {
+		tmpl.IsPrivate = *req.IsPrivate
+	}
+	if req.UserIDs != nil {
+		if tmpl.UserIDs, ok = h.templateUsers(c, *req.UserIDs); !ok {
+			return
+		}
+	}
+	if req.Roles != nil {
+		if tmpl.Roles, ok = templateRoles(c, *req.Roles); !ok {
+			return
+		}
+	}
+	if err := h.store.UpdateTemplate(c.Request.Context(), tmpl); err != nil {
+		abortTemplateError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, tmpl)
+}
+
+func (h *Template) Delete(c *gin.Context) {
+	id, ok := httpx.ParamID(c, "id")
+	if !ok {
+		return
+	}
+	if err := h.store.DeleteTemplate(c.Request.Context(), id); err != nil {
+		abortTemplateError(c, err)
+		return
+	}
+	c.Status(http.StatusNoContent)
+}
+
+// load 读取路径中的模板；对当前用户不可见的私有模板与不存在的模板一样返回 404。
+func (h *Template) load(c *gin.Context) (*store.Template, bool) {
+	id, ok := httpx.ParamID(c, "id")
+	if !ok {
+		return nil, false
+	}
+	return loadTemplate(c, h.store, id)
+}
+
+func loadTemplate(c *gin.Context, s *store.Store, id int64) (*store.Template, bool) {
+	tmpl, err := s.GetTemplate(c.Request.Context(), id)
+	if err == nil && !tmpl.VisibleTo(currentViewer(c)) {
+		err = store.ErrNotFound
+	}
+	if err != nil {
+		abortTemplateError(c, err)
+		return nil, false
+	}
+	return tmpl, true
+}
+
+// expandTemplate 替换模板内容中的占位符，未知的占位符原样保留。
+func expandTemplate(content string, title string, author string, now time.Time) string {
+	return strings.NewReplacer(
+		"{{date}}", now.Format(time.DateOnly),
+		"{{author}}", author,
+		"{{title}}", title,
+	).Replace(content)
+}
+
+func templateName(c *gin.Context, name string) (string, bool) {
+	name = strings.TrimSpace(name)
+	if name == "" {
+		httpx.Abort(c, http.StatusBadRequest, i18n.FieldRequired, "name")
+		return "", false
+	}
+	return name, true
+}
+
+// templateUsers 去重并确认授权的用户都存在，不合法时已返回 400。
+func (h *Template) templateUsers(c *gin.Context, ids []int64) ([]int64, bool) {
+	users := []int64{}
+	for _, id := range ids {
+		if slices.Contains(users, id) {
+			continue
+		}
+		_, err := h.store.GetUser(c.Request.Context(), id)
+		if errors.Is(err, store.ErrNotFound) {
+			httpx.Abort(c, http.StatusBadRequest, i18n.UserNotFound)
+			return nil, false
+		}
+		if err != nil {
+			httpx.AbortInternal(c, err)
+			return nil, false
+		}
+		users = append(users, id)
+	}
+	return users, true
+}
+
+// templateRoles 归一化、去重并校验授权的角色，不合法时已返回 400。
+func templateRoles(c *gin.Context, names []string) ([]string, bool) {
+	roles := []string{}
+	for _, name := range names {
+		role := strings.ToLower(strings.TrimSpace(name))
+		if !auth.ValidRole(role) {
+			httpx.Abort(c, http.StatusBadRequest, i18n.InvalidRole, strings.Join(auth.Roles, ", "))
+			return nil, false
+		}
+		if !slices.Contains(roles, role) {
+			roles = append(roles, role)
+		}
+	}
+	return roles, true
+}
+
+func abortTemplateError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, store.ErrNotFound):
+		httpx.Abort(c, http.StatusNotFound, i18n.TemplateNotFound)
+	case errors.Is(err, store.ErrDuplicate):
+		httpx.Abort(c, http.StatusConflict, i18n.TemplateNameTaken)
+	default:
+		httpx.AbortInternal(c, err)
+	}
+}
@@ -0,0 +1,143 @@
+package v1
+
+import (
+	"errors"
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/lifei6671/plaindoc/apps/server/internal/i18n"
+	"github.com/lifei6671/plaindoc/apps/server/internal/server/httpx"
+	"github.com/lifei6671/plaindoc/apps/server/internal/settings"
+	"github.com/lifei6671/plaindoc/apps/server/internal/store"
+	"github.com/lifei6671/plaindoc/apps/server/internal/theme"
+)
+
+// Theme 处理全站主题与用户偏好主题。
+type Theme struct {
+	store    *store.Store
+	settings *settings.Service
+}
+
+func NewTheme(s *store.Store, settingsService *settings.Service) *Theme {
+	return &Theme{store: s, settings: settingsService}
+}
+
+// themeResponse 一次性返回前端渲染外观所需的全部信息，避免首屏先按默认主题渲染再切换。
+type themeResponse struct {
+	SiteName  string        `json:"site_name"`
+	SiteTheme string        `json:"site_theme"`
+	UserTheme string        `json:"user_theme"`
+	Active    theme.Theme   `json:"active"`
+	Themes    []theme.Theme `json:"themes"`
+}
+
+type updateSiteThemeRequest struct {
+	Theme string `json:"theme" binding:"required"`
+	// LogoURL 为 nil 表示不修改，空串表示恢复内置 logo。
+	LogoURL *string `json:"logo_url"`
+}
+
+type updateUserThemeRequest struct {
+	Theme string `json:"theme"`
+}
+
+// Get 返回当前生效的主题：登录用户设置了偏好时以偏好为准，否则使用全站默认主题。
+func (h *Theme) Get(c *gin.Context) {
+	h.respond(c)
+}
+
+// UpdateSite 切换全站默认主题，仅限管理员；修改会记入配置审计日志。
+func (h *Theme) UpdateSite(c *gin.Context) {
+	var req updateSiteThemeRequest
+	if !httpx.BindAndValidate(c, &req) {
+		return
+	}
+	values := map[string]string{settings.Theme: strings.TrimSpace(req.Theme)}
+	if req.LogoURL != nil {
+		values[settings.LogoURL] = strings.TrimSpace(*req.LogoURL)
+	}
+
+	user, _ := httpx.CurrentUser(c)
+	_, err := h.settings.Update(c.Request.Context(), values, user.ID)
+	var invalid *settings.ValidationError
+	if errors.As(err, &invalid) {
+		httpx.Abort(c, http.StatusBadRequest, i18n.InvalidThemeSetting, invalid.Key, invalid.Reason)
+		return
+	}
+	if err != nil {
+		httpx.AbortInternal(c, err)
+		return
+	}
+	h.respond(c)
+}
+
+// UpdatePreference 设置当前用户的偏好主题，theme 为空串时恢复跟随全站默认。
+func (h *Theme) UpdatePreference(c *gin.Context) {
+	var req updateUserThemeRequest
+	if !httpx.BindAndValidate(c, &req) {
+		return
+	}
+	id := strings.TrimSpace(req.Theme)
+	if _, ok := theme.Lookup(id); id != "" && !ok {
+		httpx.Abort(c, http.StatusBadRequest, i18n.InvalidTheme, strings.Join(theme.IDs(), ", "))
+		return
+	}
+
+	user, _ := httpx.CurrentUser(c)
+	if err := h.store.UpdateUserTheme(c.Request.Context(), user.ID, id); err != nil {
+		if errors.Is(err, store.ErrNotFound) {
+			httpx.Abort(c, http.StatusUnauthorized, i18n.UserGone)
+			return
+		}
+		httpx.AbortInternal(c, err)
+		return
+	}
+	h.respond(c)
+}
+
+func (h *Theme) respond(c *gin.Context) {
+	ctx := c.Request.Context()
+	snapshot, err := h.settings.Get(ctx)
+	if err != nil {
+		httpx.AbortInternal(c, err)
+		return
+	}
+
+	resp := themeResponse{
+		SiteName:  snapshot.String(settings.SiteName),
+		SiteTheme: snapshot.String(settings.Theme),
+		Themes:    make([]theme.Theme, 0, len(theme.Builtin)),
+	}
+	if current, ok := httpx.CurrentUser(c); ok {
+		user, err := h.store.GetUser(ctx, current.ID)
+		if err != nil && !errors.Is(err, store.ErrNotFound) {
+			httpx.AbortInternal(c, err)
+			return
+		}
+		if user != nil {
+			resp.UserTheme = user.Theme
+		}
+	}
+
+	logoURL := snapshot.String(settings.LogoURL)
+	for _, t := range theme.Builtin {
+		if logoURL != "" {
+			t.LogoURL = logoURL
+		}
+		resp.Themes = append(resp.Themes, t)
+	}
+	// 偏好或全站设置指向已下线的主题时依次回退，保证 active 总是有效主题。
+	resp.Active = resp.Themes[0]
+fallback:
+	for _, id := range []string{resp.UserTheme, resp.SiteTheme, theme.DefaultID} {
+		for _, t := range resp.Themes {
+			if t.ID == id {
+				resp.Active = t
+				break fallback
+			}
+		}
+	}
+	c.Header("Cache-Control", "private, no-cache")
+	c.JSON(http.StatusOK, resp)
+}
@@ -0,0 +1,105 @@
+package v1
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/lifei6671/plaindoc/apps/server/internal/i18n"
+	"github.com/lifei6671/plaindoc/apps/server/internal/server/httpx"
+	"github.com/lifei6671/plaindoc/apps/server/internal/store"
+	"github.com/lifei6671/plaindoc/apps/server/internal/webhook"
+)
+
+// Trash 按删除时间倒序分页返回回收站中的文档；非管理员只能看到自己创建或删除的文档。
+func (h *Document) Trash(c *gin.Context) {
+	pagination, ok := httpx.ParsePagination(c)
+	if !ok {
+		return
+	}
+	docs, total, err := h.store.ListTrash(c.Request.Context(), store.TrashFilter{
+		Space:  c.Query("space"),
+		Viewer: currentViewer(c),
+		Limit:  pagination.Limit(),
+		Offset: pagination.Offset(),
+	})
+	if err != nil {
+		httpx.AbortInternal(c, err)
+		return
+	}
+	if !h.attachTags(c, docs) {
+		return
+	}
+	c.JSON(http.StatusOK, httpx.NewPagedResponse(docs, total, pagination))
+}
+
+// Restore 把回收站中的文档恢复到原位置，原父文档仍在回收站时恢复到顶层。
+func (h *Document) Restore(c *gin.Context) {
+	doc, ok := h.loadTrashed(c)
+	if !ok {
+		return
+	}
+	if !h.policy.CanManage(currentViewer(c), doc) {
+		httpx.Abort(c, http.StatusNotFound, i18n.DocNotFoundInTrash)
+		return
+	}
+	if err := h.store.RestoreDocument(c.Request.Context(), doc); err != nil {
+		if errors.Is(err, store.ErrNotFound) {
+			httpx.Abort(c, http.StatusNotFound, i18n.DocNotFoundInTrash)
+			return
+		}
+		abortDocumentWriteError(c, err)
+		return
+	}
+	h.index(c, doc)
+	if doc.Status == store.DocStatusPublished {
+		h.publish(c, webhook.EventDocPublished, doc)
+	}
+	h.respond(c, doc)
+}
+
+// Purge 彻底删除回收站中的文档及其历史版本，仅限管理员。
+func (h *Document) Purge(c *gin.Context) {
+	id, ok := httpx.ParamID(c, "id")
+	if !ok {
+		return
+	}
+	err := h.store.PurgeDocument(c.Request.Context(), id)
+	switch {
+	case errors.Is(err, store.ErrNotFound):
+		httpx.Abort(c, http.StatusNotFound, i18n.DocNotFoundInTrash)
+		return
+	case errors.Is(err, store.ErrHasChildren):
+		httpx.Abort(c, http.StatusConflict, i18n.DocHasChildrenInTrash)
+		return
+	case err != nil:
+		httpx.AbortInternal(c, err)
+		return
+	}
+	c.Status(http.StatusNoContent)
+}
+
+func (h *Document) loadTrashed(c *gin.Context) (*store.Document, bool) {
+	id, ok := httpx.ParamID(c, "id")
+	if !ok {
+		return nil, false
+	}
+	ctx := c.Request.Context()
+	doc, err := h.store.GetTrashedDocument(ctx, id)
+	if err == nil {
+		// 不可访问空间中的文档与不存在的一样处理。
+		var accessible bool
+		if accessible, err = h.store.CanAccessSpace(ctx, currentViewer(c), doc.Space); err == nil && !accessible {
+			err = store.ErrNotFound
+		}
+	}
+	if errors.Is(err, store.ErrNotFound) {
+		httpx.Abort(c, http.StatusNotFound, i18n.DocNotFoundInTrash)
+		return nil, false
+	}
+	if err != nil {
+		httpx.AbortInternal(c, err)
+		return nil, false
+	}
+	return doc, true
+}
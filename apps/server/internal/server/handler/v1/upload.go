@@ -0,0 +1,176 @@
+package v1
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/lifei6671/plaindoc/apps/server/internal/config"
+	"github.com/lifei6671/plaindoc/apps/server/internal/i18n"
+	"github.com/lifei6671/plaindoc/apps/server/internal/server/httpx"
+	"github.com/lifei6671/plaindoc/apps/server/internal/storage"
+)
+
+// multipartOverhead 为 multipart 边界与其他表单字段预留的请求体空间。
+const multipartOverhead = 1 << 20
+
+// uploadExtensions 列出各 MIME 类型可用的扩展名，第一个为默认值；
+// 文件扩展名由嗅探出的类型决定，原始文件名只用于在候选项中挑选。
+var uploadExtensions = map[string][]string{
+	"image/png":       {".png"},
+	"image/jpeg":      {".jpg", ".jpeg"},
+	"image/gif":       {".gif"},
+	"image/webp":      {".webp"},
+	"application/pdf": {".pdf"},
+	"application/zip": {".zip", ".docx", ".xlsx", ".pptx"},
+	"text/plain":      {".txt", ".md", ".csv", ".log"},
+}
+
+// executableMagic 是常见可执行文件的文件头：PE、ELF、Mach-O 与脚本 shebang。
+var executableMagic = [][]byte{
+	[]byte("MZ"),
+	[]byte("\x7fELF"),
+	{0xfe, 0xed, 0xfa, 0xce},
+	{0xfe, 0xed, 0xfa, 0xcf},
+	{0xcf, 0xfa, 0xed, 0xfe},
+	{0xce, 0xfa, 0xed, 0xfe},
+	{0xca, 0xfe, 0xba, 0xbe},
+	[]byte("#!"),
+}
+
+// Upload 处理图片与附件上传，文件内容交给 storage.Backend 保存。
+type Upload struct {
+	backend storage.Backend
+	maxSize int64
+	allowed []string
+}
+
+func NewUpload(cfg config.Config, backend storage.Backend) *Upload {
+	return &Upload{backend: backend, maxSize: cfg.UploadMaxSize, allowed: cfg.UploadAllowedTypes}
+}
+
+type uploadResponse struct {
+	URL         string `json:"url"`
+	Key         string `json:"key"`
+	Name        string `json:"name"`
+	Size        int64  `json:"size"`
+	ContentType string `json:"content_type"`
+}
+
+// Create 接收 multipart 表单中的 file 字段；同一天内容相同的文件得到同一个 key。
+func (h *Upload) Create(c *gin.Context) {
+	c.Request.Body = http.MaxBytesReader(c.Writer, c.Request.Body, h.maxSize+multipartOverhead)
+	header, err := c.FormFile("file")
+	if err != nil {
+		var tooLarge *http.MaxBytesError
+		if errors.As(err, &tooLarge) {
+			h.abortTooLarge(c)
+			return
+		}
+		httpx.Abort(c, http.StatusBadRequest, i18n.FieldRequired, "file")
+		return
+	}
+	if header.Size > h.maxSize {
+		h.abortTooLarge(c)
+		return
+	}
+
+	file, err := header.Open()
+	if err != nil {
+		httpx.AbortInternal(c, err)
+		return
+	}
+	defer file.Close()
+
+	data, err := io.ReadAll(io.LimitReader(file, h.maxSize+1))
+	if err != nil {
+		httpx.AbortInternal(c, err)
+		return
+	}
+	if int64(len(data)) > h.maxSize {
+		h.abortTooLarge(c)
+		return
+	}
+	if len(data) == 0 {
+		httpx.Abort(c, http.StatusBadRequest, i18n.FileEmpty)
+		return
+	}
+
+	resp, err := h.save(c.Request.Context(), data, header.Filename)
+	var unsupported *unsupportedTypeError
+	if errors.As(err, &unsupported) {
+		httpx.Abort(c, http.StatusUnsupportedMediaType, i18n.UnsupportedFileType, unsupported.contentType)
+		return
+	}
+	if err != nil {
+		httpx.AbortInternal(c, err)
+		return
+	}
+	c.JSON(http.StatusCreated, resp)
+}
+
+// unsupportedTypeError 表示嗅探出的文件类型不在允许范围内。
+type unsupportedTypeError struct {
+	contentType string
+}
+
+func (e *unsupportedTypeError) Error() string {
+	return fmt.Sprintf("file type %s is not allowed", e.contentType)
+}
+
+// save 校验文件类型并保存，导入等其他入口也经由这里写入上传文件。
+func (h *Upload) save(ctx context.Context, data []byte, filename string) (*uploadResponse, error) {
+	contentType, ext, ok := h.detect(data, filename)
+	if !ok {
+		return nil, &unsupportedTypeError{contentType: contentType}
+	}
+
+	sum := sha256.Sum256(data)
+	key := time.Now().UTC().Format("2006/01/02") + "/" + hex.EncodeToString(sum[:]) + ext
+	if err := h.backend.Save(ctx, key, bytes.NewReader(data)); err != nil {
+		return nil, err
+	}
+	return &uploadResponse{
+		URL:         h.backend.URL(key),
+		Key:         key,
+		Name:        filepath.Base(filename),
+		Size:        int64(len(data)),
+		ContentType: contentType,
+	}, nil
+}
+
+// detect 以文件内容嗅探出的 MIME 类型为准，不信任客户端声明的类型与扩展名。
+func (h *Upload) detect(data []byte, filename string) (string, string, bool) {
+	for _, magic := range executableMagic {
+		if bytes.HasPrefix(data, magic) {
+			return "application/x-executable", "", false
+		}
+	}
+
+	contentType, _, _ := mime.ParseMediaType(http.DetectContentType(data))
+	extensions, known := uploadExtensions[contentType]
+	if !known || !slices.Contains(h.allowed, contentType) {
+		return contentType, "", false
+	}
+
+	ext := strings.ToLower(filepath.Ext(filename))
+	if !slices.Contains(extensions, ext) {
+		ext = extensions[0]
+	}
+	return contentType, ext, true
+}
+
+func (h *Upload) abortTooLarge(c *gin.Context) {
+	httpx.Abort(c, http.StatusRequestEntityTooLarge, i18n.FileTooLarge, h.maxSize)
+}
@@ -0,0 +1,32 @@
+package v1
+
+import (
+	"errors"
+
+	"github.com/gin-gonic/gin"
+)
+
+// siteURL 返回服务对外的访问地址（不含末尾 /），优先使用 PUBLIC_URL，未配置时取请求的 Host。
+// 只用于直接返回给发起请求者的内容，邮件中的链接必须用 emailURL 生成。
+func siteURL(c *gin.Context, publicURL string) string {
+	if publicURL != "" {
+		return publicURL
+	}
+	scheme := "http"
+	if c.Request.TLS != nil {
+		scheme = "https"
+	}
+	return scheme + "://" + c.Request.Host
+}
+
+// errNoPublicURL 表示未配置 PUBLIC_URL，带链接的邮件不会发送。
+var errNoPublicURL = errors.New("PUBLIC_URL is not configured, refusing to build email links from the request Host")
+
+// emailURL 返回邮件中链接的站点地址。邮件发给的不是发起请求的人，不能像 siteURL 那样取请求的 Host，
+// 否则伪造 Host 即可让重置密码等带 token 的链接指向任意站点；未配置 PUBLIC_URL 时返回 errNoPublicURL。
+func emailURL(publicURL string) (string, error) {
+	if publicURL == "" {
+		return "", errNoPublicURL
+	}
+	return publicURL, nil
+}
@@ -0,0 +1,113 @@
+package v1
+
+import (
+	"errors"
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/lifei6671/plaindoc/apps/server/internal/auth"
+	"github.com/lifei6671/plaindoc/apps/server/internal/i18n"
+	"github.com/lifei6671/plaindoc/apps/server/internal/server/httpx"
+	"github.com/lifei6671/plaindoc/apps/server/internal/store"
+)
+
+// User 处理管理员的用户管理接口，路由层需先经过 RequireRole(admin)。
+type User struct {
+	store *store.Store
+}
+
+func NewUser(s *store.Store) *User {
+	return &User{store: s}
+}
+
+type updateRoleRequest struct {
+	Role string `json:"role" binding:"required"`
+}
+
+func (h *User) List(c *gin.Context) {
+	pagination, ok := httpx.ParsePagination(c)
+	if !ok {
+		return
+	}
+	users, total, err := h.store.ListUsers(c.Request.Context(), pagination.Limit(), pagination.Offset())
+	if err != nil {
+		httpx.AbortInternal(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, httpx.NewPagedResponse(users, total, pagination))
+}
+
+// Unlock 解除用户因连续登录失败而被锁定的状态，并清零失败计数；按 IP 的锁定不受影响。
+func (h *User) Unlock(c *gin.Context) {
+	id, ok := httpx.ParamID(c, "id")
+	if !ok {
+		return
+	}
+	ctx := c.Request.Context()
+	user, err := h.store.GetUser(ctx, id)
+	if errors.Is(err, store.ErrNotFound) {
+		httpx.Abort(c, http.StatusNotFound, i18n.UserNotFound)
+		return
+	}
+	if err != nil {
+		httpx.AbortInternal(c, err)
+		return
+	}
+	if err := h.store.ClearLoginAttempts(ctx, store.LoginAccountKey(user.Email)); err != nil {
+		httpx.AbortInternal(c, err)
+		return
+	}
+	c.Status(http.StatusNoContent)
+}
+
+// UpdateRole 修改用户的全局角色，并撤销其 refresh token，迫使其在 access token 过期后以新角色重新登录。
+func (h *User) UpdateRole(c *gin.Context) {
+	id, ok := httpx.ParamID(c, "id")
+	if !ok {
+		return
+	}
+	var req updateRoleRequest
+	if !httpx.BindAndValidate(c, &req) {
+		return
+	}
+	role := strings.ToLower(strings.TrimSpace(req.Role))
+	if !auth.ValidRole(role) {
+		httpx.Abort(c, http.StatusBadRequest, i18n.InvalidRole, strings.Join(auth.Roles, ", "))
+		return
+	}
+
+	ctx := c.Request.Context()
+	user, err := h.store.GetUser(ctx, id)
+	if errors.Is(err, store.ErrNotFound) {
+		httpx.Abort(c, http.StatusNotFound, i18n.UserNotFound)
+		return
+	}
+	if err != nil {
+		httpx.AbortInternal(c, err)
+		return
+	}
+	if user.Role == role {
+		c.JSON(http.StatusOK, user)
+		return
+	}
+
+	err = h.store.UpdateUserRole(ctx, id, role)
+	if errors.Is(err, store.ErrLastAdmin) {
+		httpx.Abort(c, http.StatusConflict, i18n.LastAdmin)
+		return
+	}
+	if err != nil {
+		httpx.AbortInternal(c, err)
+		return
+	}
+	if err := h.store.RevokeUserRefreshTokens(ctx, id, store.RevokeRoleChanged); err != nil {
+		_ = c.Error(err)
+	}
+
+	if user, err = h.store.GetUser(ctx, id); err != nil {
+		httpx.AbortInternal(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, user)
+}
@@ -0,0 +1,6 @@
+// Package v1 实现 /api/v1 下的接口。接口字段或语义需要不兼容地变化时，在 handler/v2 中新建实现并挂到 /api/v2，
+// v1 保持原样继续服务老客户端，两个版本共享中间件与 store 等下层依赖。
+package v1
+
+// Prefix 是 v1 接口的路由前缀，cookie 的 Path 等也以它为准。
+const Prefix = "/api/v1"
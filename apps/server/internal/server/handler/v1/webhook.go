@@ -0,0 +1,214 @@
+package v1
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"net/http"
+	"net/url"
+	"slices"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/lifei6671/plaindoc/apps/server/internal/i18n"
+	"github.com/lifei6671/plaindoc/apps/server/internal/server/httpx"
+	"github.com/lifei6671/plaindoc/apps/server/internal/store"
+	"github.com/lifei6671/plaindoc/apps/server/internal/webhook"
+)
+
+// Webhook 处理管理员的 webhook 管理接口，路由层需先经过 RequireRole(admin)。
+type Webhook struct {
+	store *store.Store
+}
+
+func NewWebhook(s *store.Store) *Webhook {
+	return &Webhook{store: s}
+}
+
+type createWebhookRequest struct {
+	URL    string   `json:"url" binding:"required,max=2048"`
+	Events []string `json:"events" binding:"required"`
+	// Secret 省略时自动生成；创建响应中会返回一次，之后不再返回。
+	Secret   string `json:"secret" binding:"max=255"`
+	IsActive *bool  `json:"is_active"`
+}
+
+type updateWebhookRequest struct {
+	URL      *string   `json:"url" binding:"omitempty,max=2048"`
+	Events   *[]string `json:"events"`
+	Secret   *string   `json:"secret" binding:"omitempty,min=1,max=255"`
+	IsActive *bool     `json:"is_active"`
+}
+
+// createdWebhook 是创建接口的响应，比普通响应多出明文 secret。
+type createdWebhook struct {
+	*store.Webhook
+	Secret string `json:"secret"`
+}
+
+func (h *Webhook) List(c *gin.Context) {
+	webhooks, err := h.store.ListWebhooks(c.Request.Context())
+	if err != nil {
+		httpx.AbortInternal(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"items": webhooks})
+}
+
+func (h *Webhook) Create(c *gin.Context) {
+	var req createWebhookRequest
+	if !httpx.BindAndValidate(c, &req) {
+		return
+	}
+	target, ok := webhookURL(c, req.URL)
+	if !ok {
+		return
+	}
+	events, ok := webhookEvents(c, req.Events)
+	if !ok {
+		return
+	}
+	secret := req.Secret
+	if secret == "" {
+		random := make([]byte, 32)
+		if _, err := rand.Read(random); err != nil {
+			httpx.AbortInternal(c, err)
+			return
+		}
+		secret = hex.EncodeToString(random)
+	}
+
+	user, _ := httpx.CurrentUser(c)
+	hook := &store.Webhook{
+		URL:       target,
+		Secret:    secret,
+		Events:    events,
+		IsActive:  req.IsActive == nil || *req.IsActive,
+		CreatedBy: &user.ID,
+	}
+	if err := h.store.CreateWebhook(c.Request.Context(), hook); err != nil {
+		httpx.AbortInternal(c, err)
+		return
+	}
+	c.JSON(http.StatusCreated, createdWebhook{Webhook: hook, Secret: hook.Secret})
+}
+
+// Update 修改 webhook，未提供的字段保持不变。
+func (h *Webhook) Update(c *gin.Context) {
+	hook, ok := h.load(c)
+	if !ok {
+		return
+	}
+	var req updateWebhookRequest
+	if !httpx.BindAndValidate(c, &req) {
+		return
+	}
+	if req.URL != nil {
+		if hook.URL, ok = webhookURL(c, *req.URL); !ok {
+			return
+		}
+	}
+	if req.Events != nil {
+		if hook.Events, ok = webhookEvents(c, *req.Events); !ok {
+			return
+		}
+	}
+	if req.Secret != nil {
+		hook.Secret = *req.Secret
+	}
+	if req.IsActive != nil {
+		hook.IsActive = *req.IsActive
+	}
+
+	if err := h.store.UpdateWebhook(c.Request.Context(), hook); err != nil {
+		abortWebhookError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, hook)
+}
+
+func (h *Webhook) Delete(c *gin.Context) {
+	id, ok := httpx.ParamID(c, "id")
+	if !ok {
+		return
+	}
+	if err := h.store.DeleteWebhook(c.Request.Context(), id); err != nil {
+		abortWebhookError(c, err)
+		return
+	}
+	c.Status(http.StatusNoContent)
+}
+
+// Deliveries 按时间倒序分页返回投递记录，用于排查失败的投递。
+func (h *Webhook) Deliveries(c *gin.Context) {
+	hook, ok := h.load(c)
+	if !ok {
+		return
+	}
+	pagination, ok := httpx.ParsePagination(c)
+	if !ok {
+		return
+	}
+	deliveries, total, err := h.store.ListWebhookDeliveries(c.Request.Context(), store.WebhookDeliveryFilter{
+		WebhookID: hook.ID,
+		Limit:     pagination.Limit(),
+		Offset:    pagination.Offset(),
+	})
+	if err != nil {
+		httpx.AbortInternal(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, httpx.NewPagedResponse(deliveries, total, pagination))
+}
+
+func (h *Webhook) load(c *gin.Context) (*store.Webhook, bool) {
+	id, ok := httpx.ParamID(c, "id")
+	if !ok {
+		return nil, false
+	}
+	hook, err := h.store.GetWebhook(c.Request.Context(), id)
+	if err != nil {
+		abortWebhookError(c, err)
+		return nil, false
+	}
+	return hook, true
+}
+
+// webhookURL 校验投递地址必须是完整的 http(s) URL，不合法时已返回 400。
+func webhookURL(c *gin.Context, raw string) (string, bool) {
+	raw = strings.TrimSpace(raw)
+	u, err := url.Parse(raw)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		httpx.Abort(c, http.StatusBadRequest, i18n.InvalidWebhookURL)
+		return "", false
+	}
+	return raw, true
+}
+
+// webhookEvents 去重并校验订阅的事件，不合法时已返回 400。
+func webhookEvents(c *gin.Context, names []string) ([]string, bool) {
+	events := []string{}
+	for _, name := range names {
+		name = strings.TrimSpace(name)
+		if !slices.Contains(webhook.Events, name) {
+			events = nil
+			break
+		}
+		if !slices.Contains(events, name) {
+			events = append(events, name)
+		}
+	}
+	if len(events) == 0 {
+		httpx.Abort(c, http.StatusBadRequest, i18n.InvalidWebhookEvents, strings.Join(webhook.Events, ", "))
+		return nil, false
+	}
+	return events, true
+}
+
+func abortWebhookError(c *gin.Context, err error) {
+	if errors.Is(err, store.ErrNotFound) {
+		httpx.Abort(c, http.StatusNotFound, i18n.WebhookNotFound)
+		return
+	}
+	httpx.AbortInternal(c, err)
+}
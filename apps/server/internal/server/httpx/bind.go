@@ -0,0 +1,131 @@
+package httpx
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"reflect"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gin-gonic/gin/binding"
+	"github.com/go-playground/validator/v10"
+	"github.com/lifei6671/plaindoc/apps/server/internal/i18n"
+)
+
+// 校验错误中的字段名取 json 标签，与请求体里的字段名保持一致。
+func init() {
+	if engine, ok := binding.Validator.Engine().(*validator.Validate); ok {
+		engine.RegisterTagNameFunc(func(field reflect.StructField) string {
+			name, _, _ := strings.Cut(field.Tag.Get("json"), ",")
+			if name == "" || name == "-" {
+				return field.Name
+			}
+			return name
+		})
+	}
+}
+
+// BindAndValidate 绑定并校验 JSON 请求体，失败时已通过 AbortBind 返回错误响应。
+func BindAndValidate(c *gin.Context, obj any) bool {
+	if err := c.ShouldBindJSON(obj); err != nil {
+		AbortBind(c, err)
+		return false
+	}
+	return true
+}
+
+// AbortBind 把 ShouldBindJSON 等返回的错误转换为本地化的 400 invalid_request：message 提示第一个不合法的字段，
+// errors 逐个列出全部不合法的字段，供前端展示在对应的输入框旁；
+// 请求体超过 middleware.MaxBodySize 的上限时返回 413 request_too_large。
+func AbortBind(c *gin.Context, err error) {
+	var (
+		invalid  validator.ValidationErrors
+		mismatch *json.UnmarshalTypeError
+		tooLarge *http.MaxBytesError
+	)
+	switch {
+	case errors.As(err, &invalid) && len(invalid) > 0:
+		fields := make([]FieldError, 0, len(invalid))
+		for _, field := range invalid {
+			key, args := fieldMessage(field)
+			fields = append(fields, FieldError{Field: fieldPath(field), Message: Localize(c, key, args...)})
+		}
+		key, args := bindMessage(invalid[0])
+		abortFields(c, key, fields, args...)
+	case errors.As(err, &mismatch) && mismatch.Field != "":
+		abortFields(c, i18n.FieldInvalid, []FieldError{{Field: mismatch.Field, Message: Localize(c, i18n.ValidationType)}}, mismatch.Field)
+	case errors.As(err, &tooLarge):
+		Abort(c, http.StatusRequestEntityTooLarge, i18n.RequestTooLarge, tooLarge.Limit)
+	case errors.Is(err, io.EOF):
+		Abort(c, http.StatusBadRequest, i18n.InvalidRequestBody)
+	default:
+		Abort(c, http.StatusBadRequest, i18n.InvalidRequest, err.Error())
+	}
+}
+
+func abortFields(c *gin.Context, key string, fields []FieldError, args ...any) {
+	c.AbortWithStatusJSON(http.StatusBadRequest, ErrorResponse{
+		Code:    i18n.CodeOf(key),
+		Message: Localize(c, key, args...),
+		Errors:  fields,
+	})
+}
+
+// bindMessage 返回概括整个请求的 message，带上字段名。
+func bindMessage(field validator.FieldError) (string, []any) {
+	name := fieldPath(field)
+	switch field.Tag() {
+	case "required":
+		return i18n.FieldRequired, []any{name}
+	case "min":
+		return i18n.FieldMin, []any{name, field.Param()}
+	case "max":
+		return i18n.FieldMax, []any{name, field.Param()}
+	case "email":
+		return i18n.FieldEmail, []any{name}
+	case "oneof":
+		return i18n.FieldOneOf, []any{name, oneOfValues(field)}
+	default:
+		return i18n.FieldInvalid, []any{name}
+	}
+}
+
+// fieldMessage 返回单个字段的提示（不带字段名），min/max 按字段类型区分字符数、元素个数与数值。
+func fieldMessage(field validator.FieldError) (string, []any) {
+	switch field.Tag() {
+	case "required":
+		return i18n.ValidationRequired, nil
+	case "email":
+		return i18n.ValidationEmail, nil
+	case "oneof":
+		return i18n.ValidationOneOf, []any{oneOfValues(field)}
+	case "min", "max":
+		key := i18n.ValidationMin
+		if field.Tag() == "max" {
+			key = i18n.ValidationMax
+		}
+		switch field.Kind() {
+		case reflect.String:
+			key += "_length"
+		case reflect.Slice, reflect.Array, reflect.Map:
+			key += "_items"
+		}
+		return key, []any{field.Param()}
+	default:
+		return i18n.ValidationInvalid, nil
+	}
+}
+
+// fieldPath 返回字段在请求体中的路径，去掉开头的结构体类型名，如 tags[0]、items[1].name。
+func fieldPath(field validator.FieldError) string {
+	if _, path, ok := strings.Cut(field.Namespace(), "."); ok {
+		return path
+	}
+	return field.Field()
+}
+
+func oneOfValues(field validator.FieldError) string {
+	return strings.ReplaceAll(field.Param(), " ", ", ")
+}
@@ -0,0 +1,88 @@
+package httpx
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// ConditionalJSON 输出带 ETag 与 Last-Modified 的 200 JSON 响应；请求中的 If-None-Match
+// 或 If-Modified-Since 表明客户端缓存仍然有效时返回空 body 的 304。
+//
+// ETag 取响应体的哈希，标签颜色等不影响 lastModified 的变化也能被识别。响应可能包含
+// 需要鉴权才能看到的内容，统一使用 "private, no-cache"：禁止 CDN 等共享缓存保存，
+// 浏览器每次使用前都要回源校验，权限被收回后不会继续展示旧内容。
+func ConditionalJSON(c *gin.Context, lastModified time.Time, obj any) {
+	conditionalJSON(c, "", lastModified, obj)
+}
+
+// VersionedJSON 与 ConditionalJSON 相同，但 ETag 以资源的版本号开头（如 "3-<hash>"），
+// 客户端修改资源时可以把它原样放进 If-Match，由 IfMatchVersion 取回读取时的版本号。
+func VersionedJSON(c *gin.Context, version int, lastModified time.Time, obj any) {
+	conditionalJSON(c, strconv.Itoa(version)+"-", lastModified, obj)
+}
+
+// IfMatchVersion 从 If-Match 头取出客户端读取时的版本号，接受 VersionedJSON 输出的 ETag 或单独的版本号（如 "3"）；
+// 没有该头时 ok 为 false，无法解析时返回错误。
+func IfMatchVersion(r *http.Request) (version int, ok bool, err error) {
+	raw := strings.TrimSpace(r.Header.Get("If-Match"))
+	if raw == "" {
+		return 0, false, nil
+	}
+	raw = strings.Trim(strings.TrimPrefix(raw, "W/"), `"`)
+	raw, _, _ = strings.Cut(raw, "-")
+	version, err = strconv.Atoi(raw)
+	if err != nil || version < 1 {
+		return 0, true, errors.New("invalid If-Match version")
+	}
+	return version, true, nil
+}
+
+func conditionalJSON(c *gin.Context, etagPrefix string, lastModified time.Time, obj any) {
+	body, err := json.Marshal(obj)
+	if err != nil {
+		AbortInternal(c, err)
+		return
+	}
+	sum := sha256.Sum256(body)
+	etag := `"` + etagPrefix + hex.EncodeToString(sum[:16]) + `"`
+	lastModified = lastModified.UTC().Truncate(time.Second)
+
+	header := c.Writer.Header()
+	header.Set("ETag", etag)
+	header.Set("Cache-Control", "private, no-cache")
+	if !lastModified.IsZero() {
+		header.Set("Last-Modified", lastModified.Format(http.TimeFormat))
+	}
+	if notModified(c.Request, etag, lastModified) {
+		c.Status(http.StatusNotModified)
+		return
+	}
+	c.Data(http.StatusOK, "application/json; charset=utf-8", body)
+}
+
+// notModified 按 RFC 9110 判断条件请求：存在 If-None-Match 时忽略 If-Modified-Since。
+func notModified(r *http.Request, etag string, lastModified time.Time) bool {
+	if match := r.Header.Get("If-None-Match"); match != "" {
+		for _, candidate := range strings.Split(match, ",") {
+			candidate = strings.TrimSpace(candidate)
+			// If-None-Match 使用弱比较，W/ 前缀不影响匹配。
+			if candidate == "*" || strings.TrimPrefix(candidate, "W/") == etag {
+				return true
+			}
+		}
+		return false
+	}
+	if since := r.Header.Get("If-Modified-Since"); since != "" && !lastModified.IsZero() {
+		t, err := http.ParseTime(since)
+		return err == nil && !lastModified.After(t)
+	}
+	return false
+}
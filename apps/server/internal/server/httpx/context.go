@@ -0,0 +1,64 @@
+package httpx
+
+import (
+	"context"
+	"net/netip"
+
+	"github.com/gin-gonic/gin"
+)
+
+type contextKey int
+
+const requestIDKey contextKey = iota
+
+// RequestIDContextKey 是请求 ID 在 gin.Context 中的键名。
+const RequestIDContextKey = "request_id"
+
+func WithRequestID(ctx context.Context, id string) context.Context {
+	return context.WithValue(ctx, requestIDKey, id)
+}
+
+// RequestIDFromContext 读取当前请求的 ID，未经过 RequestID 中间件时返回空字符串。
+func RequestIDFromContext(ctx context.Context) string {
+	id, _ := ctx.Value(requestIDKey).(string)
+	return id
+}
+
+// User 是鉴权中间件解析出的当前登录用户。
+type User struct {
+	ID   int64
+	Role string
+	// SessionID 是 access token 所属的会话，旧 token 为 0。
+	SessionID int64
+	// APIKeyID 是请求使用的 API Key，使用 access token 时为 0；ReadOnly 表示该 key 只能发起只读请求。
+	APIKeyID int64
+	ReadOnly bool
+}
+
+const currentUserKey = "current_user"
+
+func SetCurrentUser(c *gin.Context, user User) {
+	c.Set(currentUserKey, user)
+}
+
+// CurrentUser 返回当前登录用户，未登录时第二个返回值为 false。
+func CurrentUser(c *gin.Context) (User, bool) {
+	user, ok := c.Get(currentUserKey)
+	if !ok {
+		return User{}, false
+	}
+	typed, ok := user.(User)
+	return typed, ok
+}
+
+// ClientIP 返回请求的客户端 IP，限流、登录锁定、会话与请求日志统一使用。
+// 只有直连地址属于 TRUSTED_PROXIES 时才采信 X-Forwarded-For 与 X-Real-IP，否则为直连地址；
+// IPv4 映射的 IPv6 地址（::ffff:1.2.3.4）转换为 IPv4 形式，保证同一客户端的取值一致。
+func ClientIP(c *gin.Context) string {
+	ip := c.ClientIP()
+	addr, err := netip.ParseAddr(ip)
+	if err != nil {
+		return ip
+	}
+	return addr.Unmap().String()
+}
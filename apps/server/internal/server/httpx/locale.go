@@ -0,0 +1,28 @@
+package httpx
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/lifei6671/plaindoc/apps/server/internal/i18n"
+)
+
+const languageKey = "language"
+
+// SetLanguage 记录当前请求的语言，供 Localize 使用。
+func SetLanguage(c *gin.Context, lang i18n.Lang) {
+	c.Set(languageKey, lang)
+}
+
+// Language 返回 Language 中间件协商出的语言；未经过该中间件时直接按 Accept-Language 协商，找不到时使用基准语言。
+func Language(c *gin.Context) i18n.Lang {
+	if lang, ok := c.Get(languageKey); ok {
+		if typed, ok := lang.(i18n.Lang); ok {
+			return typed
+		}
+	}
+	return i18n.Negotiate(c.GetHeader("Accept-Language"), i18n.Base)
+}
+
+// Localize 按当前请求的语言返回消息键对应的文本，args 用于填充消息模板中的参数。
+func Localize(c *gin.Context, key string, args ...any) string {
+	return i18n.Message(Language(c), key, args...)
+}
@@ -0,0 +1,66 @@
+package httpx
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/lifei6671/plaindoc/apps/server/internal/i18n"
+)
+
+const (
+	DefaultPageSize = 20
+	MaxPageSize     = 100
+	// maxPage 防止超大页码换算出溢出或毫无意义的 OFFSET。
+	maxPage = 1_000_000
+)
+
+// Pagination 是解析后的分页参数，Page 从 1 开始。
+type Pagination struct {
+	Page     int
+	PageSize int
+}
+
+func (p Pagination) Limit() int {
+	return p.PageSize
+}
+
+func (p Pagination) Offset() int {
+	return (p.Page - 1) * p.PageSize
+}
+
+// PagedResponse 是所有列表接口统一的分页响应结构。
+type PagedResponse struct {
+	Items    any `json:"items"`
+	Total    int `json:"total"`
+	Page     int `json:"page"`
+	PageSize int `json:"page_size"`
+}
+
+func NewPagedResponse(items any, total int, p Pagination) PagedResponse {
+	return PagedResponse{Items: items, Total: total, Page: p.Page, PageSize: p.PageSize}
+}
+
+// ParsePagination 读取 page/page_size 查询参数：省略时取默认值，page_size 超过 MaxPageSize 时按上限处理；
+// 非数字、小于 1 或页码过大时返回 400 并返回 false。
+func ParsePagination(c *gin.Context) (Pagination, bool) {
+	p := Pagination{Page: 1, PageSize: DefaultPageSize}
+	if raw := c.Query("page"); raw != "" {
+		page, err := strconv.Atoi(raw)
+		if err != nil || page < 1 || page > maxPage {
+			Abort(c, http.StatusBadRequest, i18n.InvalidPage, maxPage)
+			return Pagination{}, false
+		}
+		p.Page = page
+	}
+	if raw := c.Query("page_size"); raw != "" {
+		size, err := strconv.Atoi(raw)
+		if err != nil || size < 1 {
+			Abort(c, http.StatusBadRequest, i18n.InvalidPageSize)
+			return Pagination{}, false
+		}
+		p.PageSize = min(size, MaxPageSize)
+	}
+	return p, true
+}
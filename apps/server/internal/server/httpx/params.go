@@ -0,0 +1,20 @@
+package httpx
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/lifei6671/plaindoc/apps/server/internal/i18n"
+)
+
+// ParamID 解析路径中的正整数 ID，非法时直接返回 400 并返回 false。
+func ParamID(c *gin.Context, name string) (int64, bool) {
+	id, err := strconv.ParseInt(c.Param(name), 10, 64)
+	if err != nil || id <= 0 {
+		Abort(c, http.StatusBadRequest, i18n.InvalidID, name)
+		return 0, false
+	}
+	return id, true
+}
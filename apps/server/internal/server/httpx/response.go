@@ -0,0 +1,38 @@
+package httpx
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/lifei6671/plaindoc/apps/server/internal/i18n"
+)
+
+// ErrorResponse 是接口统一的错误响应结构，code 供前端做分支判断，message 按请求语言本地化。
+// 请求体校验失败时 errors 逐个列出不合法的字段。
+type ErrorResponse struct {
+	Code    string       `json:"code"`
+	Message string       `json:"message"`
+	Errors  []FieldError `json:"errors,omitempty"`
+}
+
+// FieldError 是请求体中一个不合法的字段，Field 为 JSON 中的字段路径，Message 为不带字段名的本地化提示。
+type FieldError struct {
+	Field   string `json:"field"`
+	Message string `json:"message"`
+}
+
+// AbortError 终止后续 handler 并输出统一格式的错误响应。
+func AbortError(c *gin.Context, status int, code string, message string) {
+	c.AbortWithStatusJSON(status, ErrorResponse{Code: code, Message: message})
+}
+
+// Abort 以消息键输出错误响应：code 取键中点号之前的部分，message 按请求语言填充 args。
+func Abort(c *gin.Context, status int, key string, args ...any) {
+	AbortError(c, status, i18n.CodeOf(key), Localize(c, key, args...))
+}
+
+// AbortInternal 记录错误并返回不暴露细节的 500。
+func AbortInternal(c *gin.Context, err error) {
+	_ = c.Error(err)
+	Abort(c, http.StatusInternalServerError, i18n.InternalError)
+}
@@ -0,0 +1,134 @@
+package middleware
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/lifei6671/plaindoc/apps/server/internal/auth"
+	"github.com/lifei6671/plaindoc/apps/server/internal/i18n"
+	"github.com/lifei6671/plaindoc/apps/server/internal/server/httpx"
+)
+
+// APIKeyAuthenticator 校验明文 API Key 并返回所属用户，key 不存在或已吊销时第二个返回值为 false。
+type APIKeyAuthenticator func(ctx context.Context, key string) (httpx.User, bool, error)
+
+// OptionalAuth 尝试解析请求凭证并写入当前用户，凭证缺失或无效时不拦截请求。
+// 用于让限流、日志等全局中间件在公开接口上也能识别已登录用户。
+// 只读 API Key 在这里就拒绝非只读请求，公开接口也不例外。
+func OptionalAuth(secret string, apiKeys APIKeyAuthenticator) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		credential, isAPIKey := credentialFromRequest(c)
+		switch {
+		case credential == "":
+		case isAPIKey:
+			if user, ok, err := apiKeys(c.Request.Context(), credential); err == nil && ok {
+				httpx.SetCurrentUser(c, user)
+			}
+		default:
+			if claims, err := auth.ParseAccessToken(secret, credential); err == nil {
+				httpx.SetCurrentUser(c, httpx.User{ID: claims.UserID(), Role: claims.Role, SessionID: claims.SessionID})
+			}
+		}
+		if user, ok := httpx.CurrentUser(c); ok && !allowedByScope(c, user) {
+			return
+		}
+		c.Next()
+	}
+}
+
+// Auth 要求请求携带有效的 access token 或 API Key，access token 过期时返回 code=token_expired 便于前端刷新。
+func Auth(secret string, apiKeys APIKeyAuthenticator) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		user, ok := httpx.CurrentUser(c)
+		if !ok {
+			if user, ok = authenticate(c, secret, apiKeys); !ok {
+				return
+			}
+			httpx.SetCurrentUser(c, user)
+		}
+		if allowedByScope(c, user) {
+			c.Next()
+		}
+	}
+}
+
+// RejectAPIKey 拒绝使用 API Key 的请求。账号安全相关的接口只接受登录会话，
+// 避免泄露的 key 被用来创建新 key、管理会话或修改两步验证。
+func RejectAPIKey() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if user, ok := httpx.CurrentUser(c); ok && user.APIKeyID != 0 {
+			httpx.Abort(c, http.StatusForbidden, i18n.APIKeyNotAllowed)
+			return
+		}
+		c.Next()
+	}
+}
+
+// authenticate 校验请求凭证，失败时已返回 401（查询 API Key 出错时为 500）。
+func authenticate(c *gin.Context, secret string, apiKeys APIKeyAuthenticator) (httpx.User, bool) {
+	credential, isAPIKey := credentialFromRequest(c)
+	if credential == "" {
+		httpx.Abort(c, http.StatusUnauthorized, i18n.Unauthorized)
+		return httpx.User{}, false
+	}
+
+	if isAPIKey {
+		user, ok, err := apiKeys(c.Request.Context(), credential)
+		if err != nil {
+			httpx.AbortInternal(c, err)
+			return httpx.User{}, false
+		}
+		if !ok {
+			httpx.Abort(c, http.StatusUnauthorized, i18n.InvalidAPIKey)
+			return httpx.User{}, false
+		}
+		return user, true
+	}
+
+	claims, err := auth.ParseAccessToken(secret, credential)
+	if errors.Is(err, auth.ErrTokenExpired) {
+		httpx.Abort(c, http.StatusUnauthorized, i18n.TokenExpired)
+		return httpx.User{}, false
+	}
+	if err != nil {
+		httpx.Abort(c, http.StatusUnauthorized, i18n.InvalidAccessToken)
+		return httpx.User{}, false
+	}
+	return httpx.User{ID: claims.UserID(), Role: claims.Role, SessionID: claims.SessionID}, true
+}
+
+// allowedByScope 在只读 API Key 发起非只读请求时返回 403 并返回 false。
+func allowedByScope(c *gin.Context, user httpx.User) bool {
+	switch c.Request.Method {
+	case http.MethodGet, http.MethodHead, http.MethodOptions:
+		return true
+	}
+	if user.ReadOnly {
+		httpx.Abort(c, http.StatusForbidden, i18n.APIKeyReadOnly)
+		return false
+	}
+	return true
+}
+
+// credentialFromRequest 依次读取 Authorization: Bearer <access token>、Authorization: ApiKey <key>、
+// X-API-Key 头与 access_token cookie，第二个返回值表示凭证是 API Key。
+func credentialFromRequest(c *gin.Context) (string, bool) {
+	if header := c.GetHeader("Authorization"); header != "" {
+		scheme, credential, ok := strings.Cut(header, " ")
+		switch {
+		case ok && strings.EqualFold(scheme, "Bearer"):
+			return strings.TrimSpace(credential), false
+		case ok && strings.EqualFold(scheme, "ApiKey"):
+			return strings.TrimSpace(credential), true
+		}
+		return "", false
+	}
+	if key := c.GetHeader("X-API-Key"); key != "" {
+		return strings.TrimSpace(key), true
+	}
+	token, _ := c.Cookie(auth.AccessTokenCookie)
+	return token, false
+}
@@ -0,0 +1,34 @@
+package middleware
+
+import (
+	"io"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+// rawBodyKey 保存未被 MaxBodySize 包装的原始请求体，路由组上再次注册时据此重新设置上限。
+const rawBodyKey = "raw_body"
+
+// MaxBodySize 用 http.MaxBytesReader 限制整个请求体不超过 n 字节，n<=0 表示不限制；
+// 读取超限时 httpx.AbortBind 返回 413 request_too_large。
+//
+// 全局注册一次作为默认上限；上传、导入等接口在路由上再次注册即可放宽，后注册的一层替换前一层而不是叠加。
+// 因此这里不按 Content-Length 提前拒绝，而是在 handler 读取请求体时才生效。这类接口的单个文件大小仍由 handler 自行校验。
+func MaxBodySize(n int64) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		body := c.Request.Body
+		if raw, ok := c.Get(rawBodyKey); ok {
+			body = raw.(io.ReadCloser)
+		} else {
+			c.Set(rawBodyKey, body)
+		}
+		if n <= 0 {
+			c.Request.Body = body
+			c.Next()
+			return
+		}
+		c.Request.Body = http.MaxBytesReader(c.Writer, body, n)
+		c.Next()
+	}
+}
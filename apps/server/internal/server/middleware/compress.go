@@ -0,0 +1,239 @@
+package middleware
+
+import (
+	"compress/gzip"
+	"io"
+	"mime"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/andybalholm/brotli"
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	encodingGzip   = "gzip"
+	encodingBrotli = "br"
+)
+
+// CompressOptions 描述响应压缩参数；Level <= 0 表示不压缩。
+type CompressOptions struct {
+	// Level 是压缩级别（1-9），gzip 与 br 使用同一个值。
+	Level int
+	// MinSize 以下的响应不压缩，压缩收益抵不过额外的 CPU 与头部开销。
+	MinSize int
+}
+
+// Compress 按 Accept-Encoding 对响应做 br 或 gzip 压缩，两者都接受时优先 br。
+//
+// 只压缩文本、JSON、JavaScript、XML 等可压缩的类型，图片、zip、PDF 等已压缩的内容原样输出；
+// handler 已设置 Content-Encoding、响应为 206 或 WebSocket 握手时也不压缩。压缩后的响应在
+// ETag 的引号内追加 "-gzip"/"-br"，不同编码的响应不会共用同一个缓存键；请求的 If-None-Match
+// 会先去掉该后缀再交给 handler 比较。
+func Compress(opts CompressOptions) gin.HandlerFunc {
+	if opts.Level <= 0 {
+		return func(c *gin.Context) { c.Next() }
+	}
+	if opts.Level > 9 {
+		opts.Level = 9
+	}
+
+	return func(c *gin.Context) {
+		if c.Request.Method == http.MethodHead || c.GetHeader("Upgrade") != "" {
+			c.Next()
+			return
+		}
+		c.Writer.Header().Add("Vary", "Accept-Encoding")
+		encoding := negotiateEncoding(c.GetHeader("Accept-Encoding"))
+		if encoding == "" {
+			c.Next()
+			return
+		}
+
+		writer := &compressWriter{ResponseWriter: c.Writer, encoding: encoding, opts: opts}
+		if match := c.GetHeader("If-None-Match"); match != "" {
+			stripped := stripETagEncoding(match, encoding)
+			writer.conditional = stripped != match
+			c.Request.Header.Set("If-None-Match", stripped)
+		}
+		c.Writer = writer
+		defer func() {
+			writer.close()
+			c.Writer = writer.ResponseWriter
+		}()
+		c.Next()
+	}
+}
+
+// negotiateEncoding 返回 Accept-Encoding 中权重最高的受支持编码，权重相同时优先 br。
+func negotiateEncoding(header string) string {
+	best, bestQuality := "", 0.0
+	for _, part := range strings.Split(header, ",") {
+		name, params, _ := strings.Cut(strings.TrimSpace(part), ";")
+		name = strings.ToLower(strings.TrimSpace(name))
+		quality := 1.0
+		if value, ok := strings.CutPrefix(strings.TrimSpace(params), "q="); ok {
+			parsed, err := strconv.ParseFloat(value, 64)
+			if err != nil {
+				continue
+			}
+			quality = parsed
+		}
+		if name == "*" {
+			name = encodingBrotli
+		}
+		if (name != encodingGzip && name != encodingBrotli) || quality <= 0 {
+			continue
+		}
+		if quality > bestQuality || (quality == bestQuality && name == encodingBrotli) {
+			best, bestQuality = name, quality
+		}
+	}
+	return best
+}
+
+// stripETagEncoding 去掉 If-None-Match 中各个 ETag 引号内的 "-<encoding>" 后缀。
+func stripETagEncoding(match, encoding string) string {
+	suffix := "-" + encoding + `"`
+	tags := strings.Split(match, ",")
+	for i, tag := range tags {
+		tag = strings.TrimSpace(tag)
+		if strings.HasSuffix(tag, suffix) {
+			tag = strings.TrimSuffix(tag, suffix) + `"`
+		}
+		tags[i] = tag
+	}
+	return strings.Join(tags, ", ")
+}
+
+// compressible 报告 Content-Type 是否值得压缩。
+func compressible(contentType string) bool {
+	mediaType, _, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		return false
+	}
+	if strings.HasPrefix(mediaType, "text/") {
+		return true
+	}
+	for _, marker := range []string{"json", "javascript", "xml", "yaml"} {
+		if strings.Contains(mediaType, marker) {
+			return true
+		}
+	}
+	return false
+}
+
+// compressWriter 先缓存 MinSize 字节再决定是否压缩；Written 按 handler 视角报告，
+// 保证 Timeout 等内层中间件不会在已缓存的内容之后再写入错误响应。
+type compressWriter struct {
+	gin.ResponseWriter
+	encoding string
+	opts     CompressOptions
+	// conditional 表示请求的 If-None-Match 带有本编码的后缀，304 响应的 ETag 需要加回后缀。
+	conditional bool
+
+	started bool
+	decided bool
+	buffer  []byte
+	encoder io.WriteCloser
+}
+
+func (w *compressWriter) Written() bool {
+	return w.started || w.ResponseWriter.Written()
+}
+
+func (w *compressWriter) WriteHeaderNow() {
+	w.started = true
+	w.decide()
+	w.ResponseWriter.WriteHeaderNow()
+}
+
+func (w *compressWriter) Write(data []byte) (int, error) {
+	w.started = true
+	if !w.decided {
+		w.buffer = append(w.buffer, data...)
+		if len(w.buffer) >= w.opts.MinSize {
+			if err := w.decide(); err != nil {
+				return 0, err
+			}
+		}
+		return len(data), nil
+	}
+	if w.encoder != nil {
+		return w.encoder.Write(data)
+	}
+	return w.ResponseWriter.Write(data)
+}
+
+func (w *compressWriter) WriteString(s string) (int, error) {
+	return w.Write([]byte(s))
+}
+
+// Flush 用于流式响应：立即决定是否压缩并把已压缩的内容推给客户端。
+func (w *compressWriter) Flush() {
+	w.decide()
+	if flusher, ok := w.encoder.(interface{ Flush() error }); ok {
+		_ = flusher.Flush()
+	}
+	w.ResponseWriter.Flush()
+}
+
+// decide 根据状态码、响应头与已缓存的长度决定是否压缩，并输出缓存的内容。
+func (w *compressWriter) decide() error {
+	if w.decided {
+		return nil
+	}
+	w.decided = true
+
+	header := w.ResponseWriter.Header()
+	status := w.ResponseWriter.Status()
+	if status == http.StatusNotModified && w.conditional {
+		setETagEncoding(header, w.encoding)
+	}
+	if len(w.buffer) >= w.opts.MinSize && len(w.buffer) > 0 && status != http.StatusPartialContent &&
+		header.Get("Content-Encoding") == "" && compressible(header.Get("Content-Type")) {
+		header.Set("Content-Encoding", w.encoding)
+		header.Del("Content-Length")
+		header.Del("Accept-Ranges")
+		setETagEncoding(header, w.encoding)
+		w.encoder = w.newEncoder()
+	}
+
+	buffered := w.buffer
+	w.buffer = nil
+	if len(buffered) == 0 {
+		return nil
+	}
+	var err error
+	if w.encoder != nil {
+		_, err = w.encoder.Write(buffered)
+	} else {
+		_, err = w.ResponseWriter.Write(buffered)
+	}
+	return err
+}
+
+func (w *compressWriter) newEncoder() io.WriteCloser {
+	if w.encoding == encodingBrotli {
+		return brotli.NewWriterLevel(w.ResponseWriter, w.opts.Level)
+	}
+	encoder, _ := gzip.NewWriterLevel(w.ResponseWriter, w.opts.Level)
+	return encoder
+}
+
+// close 在 handler 返回后输出剩余的缓存并结束压缩流。
+func (w *compressWriter) close() {
+	w.decide()
+	if w.encoder != nil {
+		_ = w.encoder.Close()
+	}
+}
+
+// setETagEncoding 在 ETag 的引号内追加编码后缀，如 "abc" 变为 "abc-gzip"。
+func setETagEncoding(header http.Header, encoding string) {
+	etag := header.Get("ETag")
+	if strings.HasSuffix(etag, `"`) && len(etag) >= 2 {
+		header.Set("ETag", strings.TrimSuffix(etag, `"`)+"-"+encoding+`"`)
+	}
+}
@@ -1,19 +1,103 @@
 package middleware
 
-import "github.com/gin-gonic/gin"
+import (
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	corsAllowHeaders  = "Authorization, Content-Type, X-API-Key, X-Request-ID, If-None-Match, If-Modified-Since"
+	corsExposeHeaders = "X-Request-ID, ETag"
+	corsAllowMethods  = "GET, POST, PUT, PATCH, DELETE, OPTIONS"
+	corsMaxAge        = "600"
+)
+
+// CORS 按白名单匹配请求的 Origin 并回写该来源（携带 cookie 时不能使用 *）。
+// 白名单项支持 https://*.example.com 形式的通配子域，不匹配 example.com 本身。
+func CORS(origins []string) gin.HandlerFunc {
+	matcher := newOriginMatcher(origins)
 
-func CORS(allowOrigin string) gin.HandlerFunc {
 	return func(c *gin.Context) {
-		c.Writer.Header().Set("Access-Control-Allow-Origin", allowOrigin)
-		c.Writer.Header().Set("Access-Control-Allow-Credentials", "true")
-		c.Writer.Header().Set("Access-Control-Allow-Headers", "Authorization, Content-Type")
-		c.Writer.Header().Set("Access-Control-Allow-Methods", "GET, POST, PUT, PATCH, DELETE, OPTIONS")
+		origin := c.GetHeader("Origin")
+		allowed := origin != "" && matcher.match(origin)
 
-		if c.Request.Method == "OPTIONS" {
-			c.AbortWithStatus(204)
+		header := c.Writer.Header()
+		header.Add("Vary", "Origin")
+		if allowed {
+			header.Set("Access-Control-Allow-Origin", origin)
+			header.Set("Access-Control-Allow-Credentials", "true")
+			header.Set("Access-Control-Expose-Headers", corsExposeHeaders)
+		}
+
+		if c.Request.Method == http.MethodOptions && c.GetHeader("Access-Control-Request-Method") != "" {
+			if allowed {
+				header.Set("Access-Control-Allow-Methods", corsAllowMethods)
+				header.Set("Access-Control-Allow-Headers", corsAllowHeaders)
+				header.Set("Access-Control-Max-Age", corsMaxAge)
+			}
+			c.AbortWithStatus(http.StatusNoContent)
 			return
 		}
 
 		c.Next()
 	}
 }
+
+// OriginChecker 返回判断来源是否在 origins 白名单中的函数，规则与 CORS 相同；
+// 供 WebSocket 握手这类不经过 CORS 预检的请求校验 Origin。
+func OriginChecker(origins []string) func(origin string) bool {
+	return newOriginMatcher(origins).match
+}
+
+type wildcardOrigin struct {
+	scheme string
+	suffix string
+	port   string
+}
+
+type originMatcher struct {
+	exact     map[string]struct{}
+	wildcards []wildcardOrigin
+}
+
+func newOriginMatcher(origins []string) originMatcher {
+	m := originMatcher{exact: map[string]struct{}{}}
+	for _, origin := range origins {
+		origin = strings.ToLower(strings.TrimRight(strings.TrimSpace(origin), "/"))
+		if origin == "" {
+			continue
+		}
+		scheme, rest, ok := strings.Cut(origin, "://*.")
+		if !ok {
+			m.exact[origin] = struct{}{}
+			continue
+		}
+		host, port, _ := strings.Cut(rest, ":")
+		m.wildcards = append(m.wildcards, wildcardOrigin{scheme: scheme, suffix: "." + host, port: port})
+	}
+	return m
+}
+
+func (m originMatcher) match(origin string) bool {
+	origin = strings.ToLower(origin)
+	if _, ok := m.exact[origin]; ok {
+		return true
+	}
+	if len(m.wildcards) == 0 {
+		return false
+	}
+
+	u, err := url.Parse(origin)
+	if err != nil || u.Host == "" {
+		return false
+	}
+	for _, w := range m.wildcards {
+		if u.Scheme == w.scheme && u.Port() == w.port && strings.HasSuffix(u.Hostname(), w.suffix) && len(u.Hostname()) > len(w.suffix) {
+			return true
+		}
+	}
+	return false
+}
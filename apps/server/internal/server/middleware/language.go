@@ -0,0 +1,15 @@
+package middleware
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/lifei6671/plaindoc/apps/server/internal/i18n"
+	"github.com/lifei6671/plaindoc/apps/server/internal/server/httpx"
+)
+
+// Language 按 Accept-Language 协商错误消息的语言，没有受支持的语言时使用 fallback。
+func Language(fallback i18n.Lang) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		httpx.SetLanguage(c, i18n.Negotiate(c.GetHeader("Accept-Language"), fallback))
+		c.Next()
+	}
+}
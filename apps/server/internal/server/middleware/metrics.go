@@ -0,0 +1,27 @@
+package middleware
+
+import (
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/lifei6671/plaindoc/apps/server/internal/metrics"
+)
+
+// unmatchedRoute 是未命中任何路由（404）的请求使用的 path 标签。
+const unmatchedRoute = "unmatched"
+
+// Metrics 为每个请求采集请求数、延迟与在途请求数，path 标签取 gin 的路由模板。
+func Metrics(m *metrics.Metrics) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		start := time.Now()
+		m.RequestStarted()
+
+		c.Next()
+
+		route := c.FullPath()
+		if route == "" {
+			route = unmatchedRoute
+		}
+		m.RequestFinished(c.Request.Method, route, c.Writer.Status(), time.Since(start))
+	}
+}
@@ -0,0 +1,107 @@
+package middleware
+
+import (
+	"math"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/lifei6671/plaindoc/apps/server/internal/i18n"
+	"github.com/lifei6671/plaindoc/apps/server/internal/server/httpx"
+	"golang.org/x/time/rate"
+)
+
+const (
+	rateLimiterIdleTTL       = 10 * time.Minute
+	rateLimiterSweepInterval = time.Minute
+)
+
+// RateLimitOptions 描述一个限流档位；RPS <= 0 表示不限流。
+type RateLimitOptions struct {
+	RPS   float64
+	Burst int
+	// Key 决定限流维度，默认已登录用户按 user_id、匿名请求按客户端 IP。
+	Key func(c *gin.Context) string
+}
+
+// RateLimit 基于令牌桶按 Key 维度限流，超限返回 429 并带 Retry-After。
+// 每次调用都会创建独立的桶集合，不同路由组可以使用不同档位。
+func RateLimit(opts RateLimitOptions) gin.HandlerFunc {
+	if opts.RPS <= 0 {
+		return func(c *gin.Context) { c.Next() }
+	}
+	if opts.Burst <= 0 {
+		opts.Burst = int(math.Ceil(opts.RPS))
+	}
+	if opts.Key == nil {
+		opts.Key = userOrIPKey
+	}
+
+	buckets := newRateBuckets(rate.Limit(opts.RPS), opts.Burst)
+
+	return func(c *gin.Context) {
+		reservation := buckets.get(opts.Key(c)).Reserve()
+		if delay := reservation.Delay(); delay > 0 {
+			reservation.Cancel()
+			c.Header("Retry-After", strconv.Itoa(int(math.Ceil(delay.Seconds()))))
+			httpx.Abort(c, http.StatusTooManyRequests, i18n.RateLimited)
+			return
+		}
+		c.Next()
+	}
+}
+
+type rateBucket struct {
+	limiter  *rate.Limiter
+	lastSeen time.Time
+}
+
+type rateBuckets struct {
+	mu      sync.Mutex
+	limit   rate.Limit
+	burst   int
+	buckets map[string]*rateBucket
+	swept   time.Time
+}
+
+func newRateBuckets(limit rate.Limit, burst int) *rateBuckets {
+	return &rateBuckets{
+		limit:   limit,
+		burst:   burst,
+		buckets: map[string]*rateBucket{},
+		swept:   time.Now(),
+	}
+}
+
+func (b *rateBuckets) get(key string) *rate.Limiter {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	now := time.Now()
+	// 顺带清理长时间未访问的桶，避免按 IP 维度无限增长。
+	if now.Sub(b.swept) > rateLimiterSweepInterval {
+		for k, bucket := range b.buckets {
+			if now.Sub(bucket.lastSeen) > rateLimiterIdleTTL {
+				delete(b.buckets, k)
+			}
+		}
+		b.swept = now
+	}
+
+	bucket, ok := b.buckets[key]
+	if !ok {
+		bucket = &rateBucket{limiter: rate.NewLimiter(b.limit, b.burst)}
+		b.buckets[key] = bucket
+	}
+	bucket.lastSeen = now
+	return bucket.limiter
+}
+
+func userOrIPKey(c *gin.Context) string {
+	if user, ok := httpx.CurrentUser(c); ok {
+		return "user:" + strconv.FormatInt(user.ID, 10)
+	}
+	return "ip:" + httpx.ClientIP(c)
+}
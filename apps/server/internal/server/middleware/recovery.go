@@ -0,0 +1,36 @@
+package middleware
+
+import (
+	"log/slog"
+	"net/http"
+	"runtime/debug"
+
+	"github.com/gin-gonic/gin"
+	"github.com/lifei6671/plaindoc/apps/server/internal/server/httpx"
+)
+
+// Recovery 捕获 handler panic，记录带 request_id 的堆栈并返回 500。
+func Recovery(logger *slog.Logger) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		defer func() {
+			recovered := recover()
+			if recovered == nil {
+				return
+			}
+			if recovered == http.ErrAbortHandler {
+				panic(recovered)
+			}
+
+			logger.ErrorContext(c.Request.Context(), "panic recovered",
+				slog.Any("panic", recovered),
+				slog.String("request_id", httpx.RequestIDFromContext(c.Request.Context())),
+				slog.String("method", c.Request.Method),
+				slog.String("path", c.Request.URL.Path),
+				slog.String("stack", string(debug.Stack())),
+			)
+			c.AbortWithStatus(http.StatusInternalServerError)
+		}()
+
+		c.Next()
+	}
+}
@@ -0,0 +1,41 @@
+package middleware
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/lifei6671/plaindoc/apps/server/internal/server/httpx"
+)
+
+const (
+	RequestIDHeader       = "X-Request-ID"
+	maxRequestIDHeaderLen = 128
+)
+
+// RequestID 透传或生成请求 ID，写入 gin.Context、request context 以及响应头。
+func RequestID() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		id := c.GetHeader(RequestIDHeader)
+		if !validRequestID(id) {
+			id = uuid.NewString()
+		}
+
+		c.Set(httpx.RequestIDContextKey, id)
+		c.Request = c.Request.WithContext(httpx.WithRequestID(c.Request.Context(), id))
+		c.Writer.Header().Set(RequestIDHeader, id)
+
+		c.Next()
+	}
+}
+
+// validRequestID 只接受长度受限的可见 ASCII，避免日志注入。
+func validRequestID(id string) bool {
+	if id == "" || len(id) > maxRequestIDHeaderLen {
+		return false
+	}
+	for i := 0; i < len(id); i++ {
+		if id[i] < 0x21 || id[i] > 0x7e {
+			return false
+		}
+	}
+	return true
+}
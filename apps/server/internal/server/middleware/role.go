@@ -0,0 +1,29 @@
+package middleware
+
+import (
+	"net/http"
+	"slices"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/lifei6671/plaindoc/apps/server/internal/i18n"
+	"github.com/lifei6671/plaindoc/apps/server/internal/server/httpx"
+)
+
+// RequireRole 只放行全局角色属于 roles 之一的用户，需挂在 Auth 之后。
+// 角色取自 access token，变更角色后最迟在 token 过期时生效。
+func RequireRole(roles ...string) gin.HandlerFunc {
+	allowed := strings.Join(roles, ", ")
+	return func(c *gin.Context) {
+		user, ok := httpx.CurrentUser(c)
+		if !ok {
+			httpx.Abort(c, http.StatusUnauthorized, i18n.Unauthorized)
+			return
+		}
+		if !slices.Contains(roles, user.Role) {
+			httpx.Abort(c, http.StatusForbidden, i18n.InsufficientRole, allowed)
+			return
+		}
+		c.Next()
+	}
+}
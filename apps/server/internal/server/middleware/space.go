@@ -0,0 +1,36 @@
+package middleware
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/lifei6671/plaindoc/apps/server/internal/i18n"
+	"github.com/lifei6671/plaindoc/apps/server/internal/server/httpx"
+)
+
+// SpaceChecker 判断 user（未登录时为零值）能否访问 key 对应的空间，空间不存在时第一个返回值为 false。
+type SpaceChecker func(ctx context.Context, user httpx.User, key string) (bool, error)
+
+// RequireSpaceAccess 校验查询参数 space 指定的空间对当前用户可访问，不存在与无权访问的空间都返回 404，不暴露私有空间是否存在。
+// 未指定空间的请求直接放行，由各接口按可访问的空间过滤结果。
+func RequireSpaceAccess(spaces SpaceChecker) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		key := c.Query("space")
+		if key == "" {
+			c.Next()
+			return
+		}
+		user, _ := httpx.CurrentUser(c)
+		accessible, err := spaces(c.Request.Context(), user, key)
+		if err != nil {
+			httpx.AbortInternal(c, err)
+			return
+		}
+		if !accessible {
+			httpx.Abort(c, http.StatusNotFound, i18n.SpaceNotFound)
+			return
+		}
+		c.Next()
+	}
+}
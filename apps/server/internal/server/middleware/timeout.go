@@ -0,0 +1,89 @@
+package middleware
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/lifei6671/plaindoc/apps/server/internal/i18n"
+	"github.com/lifei6671/plaindoc/apps/server/internal/server/httpx"
+)
+
+// Timeout 为请求的 context 设置超时，d<=0 表示不限制。
+//
+// handler 在同一个 goroutine 中执行（gin.Context 不是并发安全的），超时后依靠 context 取消
+// 让数据库查询等下游调用尽快返回。超时前尚未写出任何响应时，handler 之后的输出会被丢弃，
+// 统一返回 504 request_timeout；已经开始写响应（如流式下载）时保留已写出的内容，不再改写。
+func Timeout(d time.Duration) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if d <= 0 {
+			c.Next()
+			return
+		}
+		ctx, cancel := context.WithTimeout(c.Request.Context(), d)
+		defer cancel()
+		c.Request = c.Request.WithContext(ctx)
+
+		writer := &timeoutWriter{ResponseWriter: c.Writer, ctx: ctx}
+		c.Writer = writer
+		c.Next()
+		c.Writer = writer.ResponseWriter
+
+		if writer.discarding() {
+			header := c.Writer.Header()
+			for _, key := range []string{"Content-Disposition", "Content-Length", "Cache-Control", "ETag", "Last-Modified"} {
+				header.Del(key)
+			}
+			httpx.Abort(c, http.StatusGatewayTimeout, i18n.RequestTimeout)
+		}
+	}
+}
+
+// timeoutWriter 在超时且尚未写出响应后丢弃 handler 的输出，由 Timeout 改写为 504。
+type timeoutWriter struct {
+	gin.ResponseWriter
+	ctx      context.Context
+	timedOut bool
+}
+
+// discarding 报告是否丢弃后续输出；一旦判定为超时便保持不变，避免响应写到一半被截断。
+func (w *timeoutWriter) discarding() bool {
+	if !w.timedOut && !w.ResponseWriter.Written() && errors.Is(w.ctx.Err(), context.DeadlineExceeded) {
+		w.timedOut = true
+	}
+	return w.timedOut
+}
+
+func (w *timeoutWriter) WriteHeader(code int) {
+	if !w.discarding() {
+		w.ResponseWriter.WriteHeader(code)
+	}
+}
+
+func (w *timeoutWriter) WriteHeaderNow() {
+	if !w.discarding() {
+		w.ResponseWriter.WriteHeaderNow()
+	}
+}
+
+func (w *timeoutWriter) Write(data []byte) (int, error) {
+	if w.discarding() {
+		return len(data), nil
+	}
+	return w.ResponseWriter.Write(data)
+}
+
+func (w *timeoutWriter) WriteString(s string) (int, error) {
+	if w.discarding() {
+		return len(s), nil
+	}
+	return w.ResponseWriter.WriteString(s)
+}
+
+func (w *timeoutWriter) Flush() {
+	if !w.discarding() {
+		w.ResponseWriter.Flush()
+	}
+}
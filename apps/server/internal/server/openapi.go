@@ -0,0 +1,59 @@
+package server
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/lifei6671/plaindoc/apps/server/internal/config"
+	"github.com/lifei6671/plaindoc/apps/server/internal/openapi"
+	v1 "github.com/lifei6671/plaindoc/apps/server/internal/server/handler/v1"
+)
+
+// OpenAPIPath 是 OpenAPI 规范的地址，与版本无关，始终提供。
+const OpenAPIPath = "/api/openapi.json"
+
+// swaggerUIPage 从 CDN 加载 Swagger UI，不随服务端打包静态资源。
+const swaggerUIPage = `<!doctype html>
+<html lang="en">
+<head>
+<meta charset="utf-8">
+<title>PlainDoc API</title>
+<link rel="stylesheet" href="https://unpkg.com/swagger-ui-dist@5/swagger-ui.css">
+</head>
+<body>
+<div id="swagger-ui"></div>
+<script src="https://unpkg.com/swagger-ui-dist@5/swagger-ui-bundle.js" crossorigin></script>
+<script>
+window.ui = SwaggerUIBundle({ url: "` + OpenAPIPath + `", dom_id: "#swagger-ui", withCredentials: true });
+</script>
+</body>
+</html>
+`
+
+// registerOpenAPI 按已注册的 v1 路由生成规范并挂载到 OpenAPIPath，必须在 registerV1 之后调用；
+// 路由与 v1.OpenAPI 的登记不一致时 panic，保证规范不会与实际接口脱节。
+func registerOpenAPI(router *gin.Engine, cfg config.Config) {
+	doc, err := openapi.Build(openapi.Options{
+		Title:       "PlainDoc API",
+		Version:     "v1",
+		Description: "错误响应统一为 {\"code\", \"message\"}，message 按 Accept-Language 或 lang 参数本地化，客户端应按 code 判断错误类型。",
+		Prefix:      v1.Prefix,
+	}, router.Routes(), v1.OpenAPI())
+	if err != nil {
+		panic("build openapi spec: " + err.Error())
+	}
+	spec, err := json.Marshal(doc)
+	if err != nil {
+		panic("encode openapi spec: " + err.Error())
+	}
+
+	router.GET(OpenAPIPath, func(c *gin.Context) {
+		c.Data(http.StatusOK, "application/json; charset=utf-8", spec)
+	})
+	if cfg.SwaggerUI {
+		router.GET("/api/swagger", func(c *gin.Context) {
+			c.Data(http.StatusOK, "text/html; charset=utf-8", []byte(swaggerUIPage))
+		})
+	}
+}
@@ -0,0 +1,27 @@
+package server
+
+import (
+	"log/slog"
+	"net/http/pprof"
+
+	"github.com/gin-gonic/gin"
+	"github.com/lifei6671/plaindoc/apps/server/internal/auth"
+	"github.com/lifei6671/plaindoc/apps/server/internal/config"
+	"github.com/lifei6671/plaindoc/apps/server/internal/server/middleware"
+)
+
+// registerPprof 在 /debug/pprof 下挂载 net/http/pprof，独立于 /api 前缀；
+// profiling 会暴露内存内容与调用栈并带来额外开销，因此只允许管理员访问。
+func registerPprof(router *gin.Engine, cfg config.Config, apiKeys middleware.APIKeyAuthenticator, logger *slog.Logger) {
+	logger.Warn("pprof endpoints enabled at /debug/pprof (admin only); they expose stacks and memory details and add overhead, disable ENABLE_PPROF when not debugging")
+
+	debug := router.Group("/debug/pprof", middleware.Auth(cfg.JWTSecret, apiKeys), middleware.RequireRole(auth.RoleAdmin))
+	debug.GET("/", gin.WrapF(pprof.Index))
+	debug.GET("/cmdline", gin.WrapF(pprof.Cmdline))
+	debug.GET("/profile", gin.WrapF(pprof.Profile))
+	debug.GET("/symbol", gin.WrapF(pprof.Symbol))
+	debug.POST("/symbol", gin.WrapF(pprof.Symbol))
+	debug.GET("/trace", gin.WrapF(pprof.Trace))
+	// heap、goroutine、allocs 等具名 profile 由 pprof.Index 按路径分发。
+	debug.GET("/:name", gin.WrapF(pprof.Index))
+}
@@ -1,26 +1,178 @@
 package server
 
 import (
+	"context"
+	"database/sql"
+	"errors"
+	"log/slog"
+	"net/http"
+	"strings"
+
 	"github.com/gin-gonic/gin"
+	"github.com/lifei6671/plaindoc/apps/server/internal/auth"
+	"github.com/lifei6671/plaindoc/apps/server/internal/backup"
+	"github.com/lifei6671/plaindoc/apps/server/internal/cache"
+	"github.com/lifei6671/plaindoc/apps/server/internal/collab"
 	"github.com/lifei6671/plaindoc/apps/server/internal/config"
+	"github.com/lifei6671/plaindoc/apps/server/internal/feed"
+	"github.com/lifei6671/plaindoc/apps/server/internal/health"
+	"github.com/lifei6671/plaindoc/apps/server/internal/i18n"
+	"github.com/lifei6671/plaindoc/apps/server/internal/jobs"
+	"github.com/lifei6671/plaindoc/apps/server/internal/mailer"
+	"github.com/lifei6671/plaindoc/apps/server/internal/metrics"
+	"github.com/lifei6671/plaindoc/apps/server/internal/render"
+	"github.com/lifei6671/plaindoc/apps/server/internal/sanitize"
+	"github.com/lifei6671/plaindoc/apps/server/internal/search"
 	"github.com/lifei6671/plaindoc/apps/server/internal/server/handler"
+	v1 "github.com/lifei6671/plaindoc/apps/server/internal/server/handler/v1"
+	"github.com/lifei6671/plaindoc/apps/server/internal/server/httpx"
 	"github.com/lifei6671/plaindoc/apps/server/internal/server/middleware"
+	"github.com/lifei6671/plaindoc/apps/server/internal/settings"
+	"github.com/lifei6671/plaindoc/apps/server/internal/sitemap"
+	"github.com/lifei6671/plaindoc/apps/server/internal/storage"
+	"github.com/lifei6671/plaindoc/apps/server/internal/store"
+	"github.com/lifei6671/plaindoc/apps/server/internal/views"
+	"github.com/lifei6671/plaindoc/apps/server/internal/webhook"
 )
 
-func NewRouter(cfg config.Config) *gin.Engine {
+// Dependencies 汇总路由层需要的外部依赖，由 main 负责创建与释放。
+type Dependencies struct {
+	Logger  *slog.Logger
+	DB      *sql.DB
+	Store   *store.Store
+	Indexer search.Indexer
+	Storage storage.Backend
+	// Metrics 为 nil 表示未开启指标采集。
+	Metrics *metrics.Metrics
+	// Collab 管理实时协作房间，服务关闭时由调用方 Close。
+	Collab *collab.Hub
+	Mailer mailer.Mailer
+	// Webhooks 为 nil 时文档事件不会触发 webhook。
+	Webhooks *webhook.Dispatcher
+	// Cache 用于缓存文档渲染结果，nil 表示关闭缓存。
+	Cache   cache.Cache
+	Backups *backup.Service
+	// Views 为 nil 时不统计文档浏览量。
+	Views *views.Counter
+	// Health 保存就绪探针执行的依赖检查，需要探测的新依赖在创建路由前注册。
+	Health *health.Registry
+	// Jobs 执行异步作业，handler 在创建路由时注册各自的作业类型，Run 由调用方在注册完成后启动。
+	Jobs *jobs.Queue
+}
+
+func NewRouter(cfg config.Config, deps Dependencies) *gin.Engine {
 	if cfg.Env == "production" {
 		gin.SetMode(gin.ReleaseMode)
 	}
 
 	router := gin.New()
-	router.Use(gin.Logger())
-	router.Use(gin.Recovery())
-	router.Use(middleware.CORS(cfg.WebOrigin))
+	// TRUSTED_PROXIES 已由 config.Validate 校验，这里出错说明两边的解析规则不一致。
+	if err := router.SetTrustedProxies(cfg.TrustedProxies); err != nil {
+		panic("set trusted proxies: " + err.Error())
+	}
+	router.Use(middleware.RequestID())
+	router.Use(middleware.Language(i18n.Lang(cfg.DefaultLanguage)))
+	router.Use(middleware.Logger(deps.Logger))
+	router.Use(middleware.MaxBodySize(cfg.RequestMaxBodySize))
+	if deps.Metrics != nil {
+		router.Use(middleware.Metrics(deps.Metrics))
+	}
+	router.Use(middleware.Compress(middleware.CompressOptions{
+		Level:   cfg.CompressLevel,
+		MinSize: int(cfg.CompressMinSize),
+	}))
+	router.Use(middleware.Recovery(deps.Logger))
+	router.Use(middleware.CORS(cfg.WebOrigins))
+	apiKeys := apiKeyAuthenticator(deps.Store)
+	router.Use(middleware.OptionalAuth(cfg.JWTSecret, apiKeys))
+	// 全局默认档位；更严格的档位在对应路由组上叠加。
+	router.Use(middleware.RateLimit(middleware.RateLimitOptions{
+		RPS:   cfg.RateLimitRPS,
+		Burst: cfg.RateLimitBurst,
+	}))
+
+	// 未单独配置 METRICS_ADDR 时指标挂在主端口，需由网关或防火墙限制外部访问。
+	if deps.Metrics != nil && cfg.MetricsAddr == "" {
+		router.GET("/metrics", gin.WrapH(deps.Metrics.Handler()))
+	}
+
+	if cfg.EnablePprof {
+		registerPprof(router, cfg, apiKeys, deps.Logger)
+	}
 
-	api := router.Group("/api")
+	// 本地存储直接由服务端提供上传文件的访问；生产环境也可以交给 Nginx 等反向代理。
+	if local, ok := deps.Storage.(*storage.Local); ok && strings.HasPrefix(cfg.UploadBaseURL, "/") {
+		files := http.StripPrefix(cfg.UploadBaseURL, local.Handler())
+		router.GET(cfg.UploadBaseURL+"/*filepath", gin.WrapH(files))
+		router.HEAD(cfg.UploadBaseURL+"/*filepath", gin.WrapH(files))
+	}
+
+	// 与版本无关的基础接口直接挂在 /api 下。
+	base := router.Group("/api")
 	{
-		api.GET("/healthz", handler.Health)
+		base.GET("/healthz", handler.Health(deps.DB))
+		base.GET("/livez", handler.Livez)
+		base.GET("/readyz", handler.Readyz(deps.Health))
+	}
+
+	settingsService := settings.New(deps.Store)
+	renderer := render.New(sanitize.New(sanitize.Options{IframeHosts: cfg.SanitizeIframeHosts}))
+
+	// sitemap 与订阅源中的地址必须是站点的正式地址，未配置 PUBLIC_URL 时不提供，避免按请求的 Host 生成。
+	if cfg.PublicURL != "" {
+		sitemaps := sitemap.New(deps.Store, sitemap.Options{
+			BaseURL:     cfg.PublicURL,
+			DocumentURL: cfg.DocumentURL,
+			TTL:         cfg.SitemapCacheTTL,
+		})
+		router.GET("/sitemap.xml", handler.Sitemap(sitemaps, cfg.SitemapCacheTTL))
+		router.GET(sitemap.PagePrefix+":name", handler.SitemapPage(sitemaps, cfg.SitemapCacheTTL))
+
+		feeds := feed.New(deps.Store, settingsService, renderer, feed.Options{
+			BaseURL:     cfg.PublicURL,
+			DocumentURL: cfg.DocumentURL,
+			Size:        cfg.FeedSize,
+			TTL:         cfg.FeedCacheTTL,
+		})
+		router.GET(feed.Path, handler.Feed(feeds, cfg.FeedCacheTTL))
+	}
+
+	// 各版本接口挂在 /api/vN 下共享上面的全局中间件；新增版本时增加 registerV2，与 v1 并存。
+	registerV1(router.Group(v1.Prefix), cfg, deps, settingsService, renderer)
+	registerOpenAPI(router, cfg)
+
+	// 配置 STATIC_DIR 时由服务端直接托管前端，未命中的页面路由回退到 index.html。
+	if cfg.StaticDir != "" {
+		reserved := []string{"/api", "/metrics", "/debug", strings.TrimSuffix(sitemap.PagePrefix, "/")}
+		if strings.HasPrefix(cfg.UploadBaseURL, "/") {
+			reserved = append(reserved, cfg.UploadBaseURL)
+		}
+		registerStatic(router, cfg.StaticDir, reserved, deps.Logger)
 	}
 
 	return router
 }
+
+// apiKeyAuthenticator 用 store 校验 API Key；角色取自用户当前的角色，吊销或变更角色后立即生效。
+func apiKeyAuthenticator(s *store.Store) middleware.APIKeyAuthenticator {
+	return func(ctx context.Context, key string) (httpx.User, bool, error) {
+		if !auth.IsAPIKey(key) {
+			return httpx.User{}, false, nil
+		}
+		apiKey, role, err := s.UseAPIKey(ctx, auth.HashToken(key))
+		if errors.Is(err, store.ErrNotFound) {
+			return httpx.User{}, false, nil
+		}
+		if err != nil {
+			return httpx.User{}, false, err
+		}
+		return httpx.User{ID: apiKey.UserID, Role: role, APIKeyID: apiKey.ID, ReadOnly: apiKey.Scope == auth.APIKeyScopeRead}, true, nil
+	}
+}
+
+// spaceChecker 用 store 判断空间的访问权限，成员变更立即生效。
+func spaceChecker(s *store.Store) middleware.SpaceChecker {
+	return func(ctx context.Context, user httpx.User, key string) (bool, error) {
+		return s.CanAccessSpace(ctx, store.Viewer{ID: user.ID, Role: user.Role}, key)
+	}
+}
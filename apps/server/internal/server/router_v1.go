@@ -0,0 +1,187 @@
+package server
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/lifei6671/plaindoc/apps/server/internal/acl"
+	"github.com/lifei6671/plaindoc/apps/server/internal/auth"
+	"github.com/lifei6671/plaindoc/apps/server/internal/cache"
+	"github.com/lifei6671/plaindoc/apps/server/internal/config"
+	"github.com/lifei6671/plaindoc/apps/server/internal/render"
+	v1 "github.com/lifei6671/plaindoc/apps/server/internal/server/handler/v1"
+	"github.com/lifei6671/plaindoc/apps/server/internal/server/middleware"
+	"github.com/lifei6671/plaindoc/apps/server/internal/settings"
+)
+
+// registerV1 注册 /api/v1 下的全部接口；settingsService 与 renderer 由 NewRouter 创建，与站点级路由共用。
+func registerV1(api *gin.RouterGroup, cfg config.Config, deps Dependencies, settingsService *settings.Service, renderer *render.Renderer) {
+	authHandler := v1.NewAuth(cfg, deps.Store, settingsService, deps.Mailer)
+	oauthHandler := v1.NewOAuth(cfg, deps.Store, authHandler)
+	policy := acl.New(deps.Store)
+	renderCache := deps.Cache
+	if renderCache != nil && deps.Metrics != nil {
+		renderCache = cache.Observe(renderCache, "render", deps.Metrics)
+	}
+	docRenderer := render.NewCached(renderer, renderCache, cfg.RenderCacheTTL)
+	docHandler := v1.NewDocument(deps.Store, deps.Indexer, policy, deps.Webhooks, docRenderer, deps.Views, cfg.BatchMaxDocs, cfg.DocLockTTL, cfg.DocumentURL)
+	searchHandler := v1.NewSearch(deps.Indexer)
+	uploadHandler := v1.NewUpload(cfg, deps.Storage)
+	exportHandler := v1.NewExport(cfg, deps.Store, docRenderer, deps.Storage, policy, deps.Jobs)
+	importHandler := v1.NewImport(cfg, deps.Store, deps.Indexer, uploadHandler, policy)
+	userHandler := v1.NewUser(deps.Store)
+	configHandler := v1.NewSystemConfig(deps.Store, settingsService)
+	themeHandler := v1.NewTheme(deps.Store, settingsService)
+	tagHandler := v1.NewTag(deps.Store)
+	webhookHandler := v1.NewWebhook(deps.Store)
+	templateHandler := v1.NewTemplate(deps.Store)
+	spaceHandler := v1.NewSpace(deps.Store)
+	jobHandler := v1.NewJob(deps.Store, deps.Jobs)
+	commentHandler := v1.NewComment(deps.Store, policy, renderer, deps.Mailer, cfg.PublicURL)
+	backupHandler := v1.NewBackup(cfg, deps.Backups, settingsService)
+	apiKeyHandler := v1.NewAPIKey(deps.Store)
+	collabHandler := v1.NewCollab(deps.Store, policy, deps.Collab, middleware.OriginChecker(cfg.WebOrigins))
+	strictLimit := middleware.RateLimit(middleware.RateLimitOptions{
+		RPS:   cfg.RateLimitAuthRPS,
+		Burst: cfg.RateLimitAuthBurst,
+	})
+	authenticate := middleware.Auth(cfg.JWTSecret, apiKeyAuthenticator(deps.Store))
+	sessionOnly := middleware.RejectAPIKey()
+	timeout := middleware.Timeout(cfg.RequestTimeout)
+	longTimeout := middleware.Timeout(cfg.LongRequestTimeout)
+	requireWriter := middleware.RequireRole(auth.RoleAdmin, auth.RoleEditor)
+	requireAdmin := middleware.RequireRole(auth.RoleAdmin)
+	// 带 space 查询参数的列表类接口先确认空间可访问，跨空间访问返回 404。
+	spaceAccess := middleware.RequireSpaceAccess(spaceChecker(deps.Store))
+
+	// 公开接口：无需登录即可访问。
+	public := api.Group("", timeout)
+	{
+		public.POST("/auth/register", strictLimit, authHandler.Register)
+		public.POST("/auth/login", strictLimit, authHandler.Login)
+		public.POST("/auth/refresh", strictLimit, authHandler.Refresh)
+		public.POST("/auth/logout", authHandler.Logout)
+		public.POST("/auth/forgot-password", strictLimit, authHandler.ForgotPassword)
+		public.POST("/auth/reset-password", strictLimit, authHandler.ResetPassword)
+		public.GET("/auth/verify-email", strictLimit, authHandler.VerifyEmail)
+		public.POST("/auth/resend-verification", strictLimit, authHandler.ResendVerification)
+		// 2fa/enable 与 2fa/verify 也接受登录时返回的 setup 中间 token，供被要求开启两步验证的用户在登录前绑定。
+		public.POST("/auth/2fa/enable", strictLimit, sessionOnly, authHandler.EnableTwoFactor)
+		public.POST("/auth/2fa/verify", strictLimit, sessionOnly, authHandler.VerifyTwoFactor)
+		public.POST("/auth/2fa/login", strictLimit, authHandler.TwoFactorLogin)
+		public.GET("/auth/oauth/:provider", strictLimit, oauthHandler.Start)
+		public.GET("/auth/oauth/:provider/callback", strictLimit, oauthHandler.Callback)
+
+		public.GET("/docs", spaceAccess, docHandler.List)
+		public.GET("/docs/tree", spaceAccess, docHandler.Tree)
+		public.GET("/docs/popular", spaceAccess, docHandler.Popular)
+		public.GET("/docs/:id", docHandler.Get)
+		public.GET("/docs/:id/rendered", docHandler.Rendered)
+		public.GET("/docs/:id/breadcrumb", docHandler.Breadcrumb)
+		public.GET("/docs/:id/backlinks", docHandler.Backlinks)
+		public.GET("/docs/:id/versions", docHandler.ListVersions)
+		public.GET("/docs/:id/versions/:v", docHandler.GetVersion)
+		public.GET("/docs/:id/diff", docHandler.Diff)
+		public.GET("/docs/:id/comments", commentHandler.List)
+
+		public.GET("/tags", spaceAccess, tagHandler.List)
+		public.GET("/spaces", spaceHandler.List)
+		public.GET("/spaces/:id", spaceHandler.Get)
+		public.GET("/theme", themeHandler.Get)
+		public.GET("/search", spaceAccess, searchHandler.Search)
+		public.POST("/render", v1.Render(renderer))
+	}
+
+	// 需要登录的接口统一挂在 authed 下。
+	authed := api.Group("", authenticate, timeout)
+	{
+		authed.GET("/auth/me", authHandler.Me)
+		authed.GET("/auth/sessions", sessionOnly, authHandler.ListSessions)
+		authed.DELETE("/auth/sessions/:id", sessionOnly, authHandler.RevokeSession)
+		authed.POST("/auth/logout-all", sessionOnly, authHandler.LogoutAll)
+		authed.POST("/auth/2fa/disable", strictLimit, sessionOnly, authHandler.DisableTwoFactor)
+		authed.POST("/auth/2fa/recovery-codes", strictLimit, sessionOnly, authHandler.RegenerateRecoveryCodes)
+		authed.GET("/apikeys", sessionOnly, apiKeyHandler.List)
+		authed.POST("/apikeys", sessionOnly, apiKeyHandler.Create)
+		authed.DELETE("/apikeys/:id", sessionOnly, apiKeyHandler.Delete)
+		authed.PUT("/theme", themeHandler.UpdatePreference)
+
+		authed.PUT("/docs/:id", docHandler.Update)
+		authed.DELETE("/docs/:id", docHandler.Delete)
+		authed.POST("/docs/:id/publish", docHandler.Publish)
+		authed.POST("/docs/:id/schedule", docHandler.Schedule)
+		authed.DELETE("/docs/:id/draft", docHandler.DiscardDraft)
+		authed.GET("/docs/:id/lock", docHandler.GetLock)
+		authed.POST("/docs/:id/lock", docHandler.Lock)
+		authed.DELETE("/docs/:id/lock", docHandler.Unlock)
+		authed.POST("/docs/:id/revert/:v", docHandler.Revert)
+		authed.POST("/docs/:id/move", docHandler.Move)
+		authed.POST("/docs/batch", docHandler.Batch)
+		authed.GET("/docs/:id/permissions", docHandler.ListPermissions)
+		authed.POST("/docs/:id/permissions", docHandler.GrantPermission)
+		authed.PATCH("/docs/:id/permissions", docHandler.UpdateACL)
+		authed.DELETE("/docs/:id/permissions/:pid", docHandler.RevokePermission)
+		authed.POST("/docs/:id/comments", commentHandler.Create)
+		authed.DELETE("/comments/:id", commentHandler.Delete)
+		authed.POST("/docs/:id/restore", docHandler.Restore)
+		authed.DELETE("/docs/:id/purge", requireAdmin, docHandler.Purge)
+		authed.GET("/trash", spaceAccess, docHandler.Trash)
+		authed.POST("/docs/:id/favorite", docHandler.Favorite)
+		authed.DELETE("/docs/:id/favorite", docHandler.Unfavorite)
+		authed.GET("/favorites", docHandler.Favorites)
+		authed.GET("/templates", templateHandler.List)
+		authed.GET("/templates/:id", templateHandler.Get)
+		authed.POST("/templates", requireAdmin, templateHandler.Create)
+		authed.PATCH("/templates/:id", requireAdmin, templateHandler.Update)
+		authed.DELETE("/templates/:id", requireAdmin, templateHandler.Delete)
+		authed.PATCH("/spaces/:id", spaceHandler.Update)
+		authed.GET("/spaces/:id/members", spaceHandler.Members)
+		authed.POST("/spaces/:id/members", spaceHandler.AddMember)
+		authed.DELETE("/spaces/:id/members/:uid", spaceHandler.RemoveMember)
+		authed.POST("/export/jobs", spaceAccess, exportHandler.CreateSpaceJob)
+		authed.GET("/jobs", jobHandler.List)
+		authed.GET("/jobs/:id", jobHandler.Get)
+	}
+
+	// 创建内容的接口只对编辑者与管理员开放，viewer 只读。
+	writers := authed.Group("", requireWriter)
+	{
+		writers.POST("/docs", docHandler.Create)
+		writers.POST("/spaces", spaceHandler.Create)
+		writers.GET("/docs/slug-available", spaceAccess, docHandler.SlugAvailable)
+		writers.PATCH("/tags/:id", tagHandler.Update)
+	}
+
+	// 系统管理接口仅限管理员。
+	admin := authed.Group("/admin", requireAdmin)
+	{
+		admin.GET("/users", userHandler.List)
+		admin.PUT("/users/:id/role", userHandler.UpdateRole)
+		admin.POST("/users/:id/unlock", userHandler.Unlock)
+		admin.GET("/config", configHandler.List)
+		admin.PUT("/config", configHandler.Update)
+		admin.GET("/config/audit", configHandler.Audit)
+		admin.PUT("/theme", themeHandler.UpdateSite)
+		admin.GET("/webhooks", webhookHandler.List)
+		admin.POST("/webhooks", webhookHandler.Create)
+		admin.PATCH("/webhooks/:id", webhookHandler.Update)
+		admin.DELETE("/webhooks/:id", webhookHandler.Delete)
+		admin.GET("/webhooks/:id/deliveries", webhookHandler.Deliveries)
+		admin.GET("/backups", backupHandler.List)
+	}
+
+	// 上传、导入、导出、备份这类耗时接口使用更长的超时。
+	api.GET("/docs/:id/export", longTimeout, exportHandler.Document)
+	long := api.Group("", authenticate, longTimeout)
+	{
+		// 上传类接口的请求体由 handler 按文件大小上限加 multipart 开销限制，不受全局的 REQUEST_MAX_BODY_SIZE 约束。
+		fileBody := middleware.MaxBodySize(0)
+		long.GET("/export", spaceAccess, exportHandler.Space)
+		long.GET("/jobs/:id/download", jobHandler.Download)
+		long.POST("/uploads", fileBody, requireWriter, uploadHandler.Create)
+		long.POST("/import", fileBody, requireWriter, importHandler.Create)
+		long.POST("/admin/backup", requireAdmin, backupHandler.Create)
+		long.POST("/admin/restore", fileBody, requireAdmin, backupHandler.Restore)
+	}
+
+	// WebSocket 是长连接，不设置请求超时。
+	api.GET("/docs/:id/ws", authenticate, collabHandler.Connect)
+}
@@ -0,0 +1,85 @@
+package server
+
+import (
+	"log/slog"
+	"mime"
+	"net/http"
+	"os"
+	"path"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+// hashedName 匹配构建工具生成的带内容哈希的文件名，如 index-DiwrgTda.js、main.3f2a9b1c.css。
+var hashedName = regexp.MustCompile(`[.-]([0-9A-Za-z_-]{8,})\.[0-9A-Za-z]+$`)
+
+func init() {
+	// 系统的 mime.types 不一定包含这些前端常见的扩展名。
+	for ext, contentType := range map[string]string{
+		".woff":        "font/woff",
+		".woff2":       "font/woff2",
+		".map":         "application/json",
+		".webmanifest": "application/manifest+json",
+	} {
+		_ = mime.AddExtensionType(ext, contentType)
+	}
+}
+
+// registerStatic 托管 dir 下打包好的前端：存在的文件直接返回；不带扩展名的未命中路径回退到
+// index.html，交给前端的 history 路由处理；reserved 前缀（/api 等后端路由）以及带扩展名的资源
+// 未命中时仍返回 404，避免把缺失的脚本或接口错误地响应成页面。
+func registerStatic(router *gin.Engine, dir string, reserved []string, logger *slog.Logger) {
+	if _, err := os.Stat(filepath.Join(dir, "index.html")); err != nil {
+		logger.Warn("static directory has no index.html, SPA fallback will return 404", "dir", dir, "error", err)
+	}
+	root := http.Dir(dir)
+
+	router.NoRoute(func(c *gin.Context) {
+		if c.Request.Method != http.MethodGet && c.Request.Method != http.MethodHead {
+			return
+		}
+		name := path.Clean("/" + c.Request.URL.Path)
+		for _, prefix := range reserved {
+			if name == prefix || strings.HasPrefix(name, prefix+"/") {
+				return
+			}
+		}
+		if serveStatic(c, root, name) || path.Ext(name) != "" {
+			return
+		}
+		serveStatic(c, root, "/index.html")
+	})
+}
+
+// serveStatic 输出 root 下的普通文件，文件不存在或是目录时返回 false。
+// 带哈希的文件内容不会变化，可以长期缓存；其余文件（包括 index.html）每次都需要回源校验。
+func serveStatic(c *gin.Context, root http.FileSystem, name string) bool {
+	file, err := root.Open(name)
+	if err != nil {
+		return false
+	}
+	defer file.Close()
+	info, err := file.Stat()
+	if err != nil || info.IsDir() {
+		return false
+	}
+
+	header := c.Writer.Header()
+	if immutable(path.Base(name)) {
+		header.Set("Cache-Control", "public, max-age=31536000, immutable")
+	} else {
+		header.Set("Cache-Control", "no-cache")
+	}
+	header.Set("X-Content-Type-Options", "nosniff")
+	http.ServeContent(c.Writer, c.Request, info.Name(), info.ModTime(), file)
+	return true
+}
+
+// immutable 判断文件名是否带有内容哈希：哈希段须包含数字或大写字母，避免把 -component.js 这类普通文件名误判为哈希。
+func immutable(base string) bool {
+	match := hashedName.FindStringSubmatch(base)
+	return match != nil && strings.ContainsAny(match[1], "0123456789ABCDEFGHIJKLMNOPQRSTUVWXYZ")
+}
@@ -0,0 +1,267 @@
+// Package settings 管理保存在 system_configs 中、可由管理员在线修改的系统配置。
+package settings
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/lifei6671/plaindoc/apps/server/internal/auth"
+	"github.com/lifei6671/plaindoc/apps/server/internal/store"
+	"github.com/lifei6671/plaindoc/apps/server/internal/theme"
+)
+
+type Type string
+
+const (
+	TypeBool   Type = "bool"
+	TypeInt    Type = "int"
+	TypeString Type = "string"
+	TypeEnum   Type = "enum"
+)
+
+const (
+	SiteName          = "site_name"
+	AllowRegistration = "allow_registration"
+	DefaultUserRole   = "default_user_role"
+	Theme             = "theme"
+	LogoURL           = "logo_url"
+	RequireTwoFactor  = "require_two_factor"
+)
+
+// RequireTwoFactor 的取值：none 不强制，admin 要求管理员开启两步验证，all 要求全部用户开启。
+const (
+	TwoFactorNone  = "none"
+	TwoFactorAdmin = "admin"
+	TwoFactorAll   = "all"
+)
+
+// cacheTTL 限制多实例部署时其他实例的修改最迟多久后生效；本实例修改后立即刷新。
+const cacheTTL = 30 * time.Second
+
+// maxStringLength 与 system_configs.config_value 的 TEXT 上限保持足够距离。
+const maxStringLength = 4096
+
+// Definition 描述一项可在线修改的配置。
+type Definition struct {
+	Key     string
+	Type    Type
+	Default string
+	// Options 是枚举类型的可选值。
+	Options []string
+	// Sensitive 的配置在接口与审计日志中脱敏。
+	Sensitive bool
+	// Check 在类型校验通过后做额外校验，可为空。
+	Check func(value string) error
+}
+
+// Definitions 是全部可在线修改的配置，未列出的键不能写入。
+var Definitions = []Definition{
+	{Key: SiteName, Type: TypeString, Default: "PlainDoc"},
+	{Key: AllowRegistration, Type: TypeBool, Default: "true"},
+	{Key: DefaultUserRole, Type: TypeEnum, Default: auth.RoleEditor, Options: []string{auth.RoleViewer, auth.RoleEditor}},
+	{Key: Theme, Type: TypeEnum, Default: theme.DefaultID, Options: theme.IDs()},
+	// LogoURL 非空时覆盖所有主题的 logo。
+	{Key: LogoURL, Type: TypeString, Check: checkURL},
+	{Key: RequireTwoFactor, Type: TypeEnum, Default: TwoFactorNone, Options: []string{TwoFactorNone, TwoFactorAdmin, TwoFactorAll}},
+}
+
+func init() {
+	for i := range Definitions {
+		Definitions[i].Sensitive = Definitions[i].Sensitive || store.IsSensitiveConfig(Definitions[i].Key)
+	}
+}
+
+// Lookup 按键查找配置定义。
+func Lookup(key string) (Definition, bool) {
+	for _, def := range Definitions {
+		if def.Key == key {
+			return def, true
+		}
+	}
+	return Definition{}, false
+}
+
+// Normalize 校验 raw 是否符合定义的类型，并返回统一格式后的值（布尔为 true/false，整数去掉前导零等）。
+func (d Definition) Normalize(raw string) (string, error) {
+	switch d.Type {
+	case TypeBool:
+		value, err := strconv.ParseBool(strings.TrimSpace(raw))
+		if err != nil {
+			return "", fmt.Errorf("must be a boolean")
+		}
+		return strconv.FormatBool(value), nil
+	case TypeInt:
+		value, err := strconv.ParseInt(strings.TrimSpace(raw), 10, 64)
+		if err != nil {
+			return "", fmt.Errorf("must be an integer")
+		}
+		return strconv.FormatInt(value, 10), nil
+	case TypeEnum:
+		value := strings.TrimSpace(raw)
+		if !slices.Contains(d.Options, value) {
+			return "", fmt.Errorf("must be one of %s", strings.Join(d.Options, ", "))
+		}
+		return value, nil
+	default:
+		if len(raw) > maxStringLength {
+			return "", fmt.Errorf("must be at most %d bytes", maxStringLength)
+		}
+		if d.Check != nil {
+			if err := d.Check(raw); err != nil {
+				return "", err
+			}
+		}
+		return raw, nil
+	}
+}
+
+// checkURL 允许空串、站内绝对路径或 http(s) 地址。
+func checkURL(value string) error {
+	if value == "" || (strings.HasPrefix(value, "/") && !strings.HasPrefix(value, "//")) {
+		return nil
+	}
+	u, err := url.Parse(value)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return fmt.Errorf("must be a path starting with / or an absolute http(s) URL")
+	}
+	return nil
+}
+
+// ValidationError 指出批量更新中第一个不合法的配置。
+type ValidationError struct {
+	Key    string
+	Reason string
+}
+
+func (e *ValidationError) Error() string {
+	return e.Key + ": " + e.Reason
+}
+
+// Snapshot 是某一时刻全部配置的只读视图，未保存过的配置取默认值。
+type Snapshot struct {
+	values map[string]store.SystemConfig
+}
+
+func (s Snapshot) String(key string) string {
+	if cfg, ok := s.values[key]; ok {
+		return cfg.Value
+	}
+	def, _ := Lookup(key)
+	return def.Default
+}
+
+func (s Snapshot) Bool(key string) bool {
+	value, _ := strconv.ParseBool(s.String(key))
+	return value
+}
+
+func (s Snapshot) Int(key string) int64 {
+	value, _ := strconv.ParseInt(s.String(key), 10, 64)
+	return value
+}
+
+// TwoFactorRequired 判断 RequireTwoFactor 是否要求 role 角色的用户开启两步验证。
+func (s Snapshot) TwoFactorRequired(role string) bool {
+	switch s.String(RequireTwoFactor) {
+	case TwoFactorAll:
+		return true
+	case TwoFactorAdmin:
+		return role == auth.RoleAdmin
+	}
+	return false
+}
+
+// Stored 返回数据库中保存的配置记录，未保存过时第二个返回值为 false。
+func (s Snapshot) Stored(key string) (store.SystemConfig, bool) {
+	cfg, ok := s.values[key]
+	return cfg, ok
+}
+
+// Service 在内存中缓存系统配置，缓存过期或本实例更新配置后重新从数据库加载。
+type Service struct {
+	store *store.Store
+
+	mu       sync.Mutex
+	snapshot Snapshot
+	loadedAt time.Time
+}
+
+func New(s *store.Store) *Service {
+	return &Service{store: s}
+}
+
+// Get 返回当前配置，缓存有效时不访问数据库。
+func (s *Service) Get(ctx context.Context) (Snapshot, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.snapshot.values != nil && time.Since(s.loadedAt) < cacheTTL {
+		return s.snapshot, nil
+	}
+	return s.reload(ctx)
+}
+
+// Invalidate 丢弃缓存，下次 Get 时重新从数据库加载；用于恢复备份等绕过 Update 直接改写配置表之后。
+func (s *Service) Invalidate() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.snapshot = Snapshot{}
+}
+
+// Update 校验并批量写入配置，任何一项不合法时返回 *ValidationError 且不写入；
+// 敏感配置传入占位值 ****** 表示保持不变。返回本次实际发生的变更。
+func (s *Service) Update(ctx context.Context, values map[string]string, userID int64) ([]store.ConfigAuditLog, error) {
+	keys := make([]string, 0, len(values))
+	for key := range values {
+		keys = append(keys, key)
+	}
+	slices.Sort(keys)
+
+	normalized := make(map[string]string, len(values))
+	for _, key := range keys {
+		raw := values[key]
+		def, ok := Lookup(key)
+		if !ok {
+			return nil, &ValidationError{Key: key, Reason: "unknown config key"}
+		}
+		if def.Sensitive && raw == store.MaskedConfigValue {
+			continue
+		}
+		value, err := def.Normalize(raw)
+		if err != nil {
+			return nil, &ValidationError{Key: key, Reason: err.Error()}
+		}
+		normalized[key] = value
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	logs, err := s.store.UpdateSystemConfigs(ctx, normalized, userID)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := s.reload(ctx); err != nil {
+		// 写入已成功，让下一次读取重新加载。
+		s.snapshot = Snapshot{}
+	}
+	return logs, nil
+}
+
+// reload 调用方需持有 s.mu。
+func (s *Service) reload(ctx context.Context) (Snapshot, error) {
+	configs, err := s.store.ListSystemConfigs(ctx)
+	if err != nil {
+		return Snapshot{}, err
+	}
+	values := make(map[string]store.SystemConfig, len(configs))
+	for _, cfg := range configs {
+		values[cfg.Key] = cfg
+	}
+	s.snapshot, s.loadedAt = Snapshot{values: values}, time.Now()
+	return s.snapshot, nil
+}
@@ -0,0 +1,217 @@
+// Package sitemap 按 sitemap 协议（https://www.sitemaps.org/protocol.html）列出匿名访客可读的已发布文档。
+// 文档数不超过 MaxURLs 时 /sitemap.xml 直接是 urlset，否则是指向 /sitemaps/<n>.xml 分页的 sitemapindex。
+package sitemap
+
+import (
+	"bytes"
+	"context"
+	"encoding/xml"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/lifei6671/plaindoc/apps/server/internal/store"
+)
+
+const (
+	// MaxURLs 是协议规定的单个 sitemap 文件的 URL 上限。
+	MaxURLs = 50000
+	// PagePrefix 是分页 sitemap 的路径前缀，分页从 1 开始编号。
+	PagePrefix = "/sitemaps/"
+	namespace  = "http://www.sitemaps.org/schemas/sitemap/0.9"
+	batchSize  = 1000
+)
+
+type urlSet struct {
+	XMLName xml.Name   `xml:"urlset"`
+	Xmlns   string     `xml:"xmlns,attr"`
+	URLs    []urlEntry `xml:"url"`
+}
+
+type urlEntry struct {
+	Loc        string `xml:"loc"`
+	LastMod    string `xml:"lastmod"`
+	ChangeFreq string `xml:"changefreq"`
+	Priority   string `xml:"priority"`
+}
+
+type sitemapIndex struct {
+	XMLName  xml.Name     `xml:"sitemapindex"`
+	Xmlns    string       `xml:"xmlns,attr"`
+	Sitemaps []indexEntry `xml:"sitemap"`
+}
+
+type indexEntry struct {
+	Loc     string `xml:"loc"`
+	LastMod string `xml:"lastmod"`
+}
+
+// Options 配置站点地址、文档页面地址与缓存时间。
+type Options struct {
+	// BaseURL 是站点对外地址（PUBLIC_URL），不带末尾的 /。
+	BaseURL string
+	// DocumentURL 返回文档页面的完整地址，通常为 config.Config.DocumentURL。
+	DocumentURL func(id int64, space string, slug string) string
+	// TTL 是生成结果的缓存时间，0 表示每次都重新生成。
+	TTL time.Duration
+}
+
+// Service 生成并缓存 sitemap。缓存过期后由第一个请求重新扫描文档表，其余并发请求等待同一次生成的结果。
+type Service struct {
+	store *store.Store
+	opts  Options
+
+	mu       sync.Mutex
+	snapshot *snapshot
+}
+
+// snapshot 是一次生成的全部结果；pages 为空表示 root 本身就是 urlset。
+type snapshot struct {
+	root    []byte
+	pages   [][]byte
+	builtAt time.Time
+}
+
+func New(s *store.Store, opts Options) *Service {
+	return &Service{store: s, opts: opts}
+}
+
+// Root 返回 /sitemap.xml 的内容及其生成时间。
+func (s *Service) Root(ctx context.Context) ([]byte, time.Time, error) {
+	snap, err := s.load(ctx)
+	if err != nil {
+		return nil, time.Time{}, err
+	}
+	return snap.root, snap.builtAt, nil
+}
+
+// Page 返回第 n 个分页 sitemap；文档数未超过 MaxURLs 或 n 越界时第三个返回值为 false。
+func (s *Service) Page(ctx context.Context, n int) ([]byte, time.Time, bool, error) {
+	snap, err := s.load(ctx)
+	if err != nil {
+		return nil, time.Time{}, false, err
+	}
+	if n < 1 || n > len(snap.pages) {
+		return nil, time.Time{}, false, nil
+	}
+	return snap.pages[n-1], snap.builtAt, true, nil
+}
+
+func (s *Service) load(ctx context.Context) (*snapshot, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	now := time.Now().UTC()
+	if s.snapshot != nil && now.Sub(s.snapshot.builtAt) < s.opts.TTL {
+		return s.snapshot, nil
+	}
+	snap, err := s.build(ctx, now)
+	if err != nil {
+		return nil, err
+	}
+	s.snapshot = snap
+	return snap, nil
+}
+
+// build 按 doc_id 分批读取全部公开文档，每 MaxURLs 条编码为一页。
+func (s *Service) build(ctx context.Context, now time.Time) (*snapshot, error) {
+	snap := &snapshot{builtAt: now}
+	var index []indexEntry
+	page := make([]urlEntry, 0, batchSize)
+	var pageModified time.Time
+	flush := func() error {
+		body, err := encode(urlSet{Xmlns: namespace, URLs: page})
+		if err != nil {
+			return err
+		}
+		snap.pages = append(snap.pages, body)
+		index = append(index, indexEntry{
+			Loc:     s.opts.BaseURL + PagePrefix + strconv.Itoa(len(snap.pages)) + ".xml",
+			LastMod: pageModified.UTC().Format(time.RFC3339),
+		})
+		page, pageModified = page[:0], time.Time{}
+		return nil
+	}
+
+	var afterID int64
+	for {
+		docs, err := s.store.ListPublicDocumentsAfter(ctx, afterID, batchSize)
+		if err != nil {
+			return nil, err
+		}
+		for _, doc := range docs {
+			page = append(page, urlEntry{
+				Loc:        s.opts.DocumentURL(doc.ID, doc.Space, doc.Slug),
+				LastMod:    doc.UpdatedAt.UTC().Format(time.RFC3339),
+				ChangeFreq: changeFreq(now.Sub(doc.UpdatedAt)),
+				Priority:   priority(&doc),
+			})
+			if doc.UpdatedAt.After(pageModified) {
+				pageModified = doc.UpdatedAt
+			}
+			if len(page) == MaxURLs {
+				if err := flush(); err != nil {
+					return nil, err
+				}
+			}
+		}
+		if len(docs) < batchSize {
+			break
+		}
+		afterID = docs[len(docs)-1].ID
+	}
+
+	if len(snap.pages) == 0 {
+		body, err := encode(urlSet{Xmlns: namespace, URLs: page})
+		if err != nil {
+			return nil, err
+		}
+		snap.root = body
+		return snap, nil
+	}
+	if len(page) > 0 {
+		if err := flush(); err != nil {
+			return nil, err
+		}
+	}
+	if len(snap.pages) == 1 {
+		snap.root, snap.pages = snap.pages[0], nil
+		return snap, nil
+	}
+	body, err := encode(sitemapIndex{Xmlns: namespace, Sitemaps: index})
+	if err != nil {
+		return nil, err
+	}
+	snap.root = body
+	return snap, nil
+}
+
+// changeFreq 按最近一次修改距今的时长估计更新频率。
+func changeFreq(age time.Duration) string {
+	switch {
+	case age < 7*24*time.Hour:
+		return "daily"
+	case age < 30*24*time.Hour:
+		return "weekly"
+	case age < 365*24*time.Hour:
+		return "monthly"
+	default:
+		return "yearly"
+	}
+}
+
+// priority 让顶层文档排在子文档之前。
+func priority(doc *store.Document) string {
+	if doc.ParentID == nil {
+		return "0.8"
+	}
+	return "0.5"
+}
+
+func encode(v any) ([]byte, error) {
+	var buf bytes.Buffer
+	buf.WriteString(xml.Header)
+	if err := xml.NewEncoder(&buf).Encode(v); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
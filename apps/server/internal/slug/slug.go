@@ -0,0 +1,83 @@
+// Package slug 生成与校验文档 slug：slug 只由小写字母、数字组成，单词之间用一个 - 或 _ 分隔。
+package slug
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"golang.org/x/text/runes"
+	"golang.org/x/text/transform"
+	"golang.org/x/text/unicode/norm"
+)
+
+// MaxLength 与 docs.slug 列的长度一致。
+const MaxLength = 191
+
+// fallback 是标题中没有可用字符（如纯中文或符号）时生成的 slug。
+const fallback = "doc"
+
+var (
+	ErrEmpty   = errors.New("slug must not be empty")
+	ErrTooLong = fmt.Errorf("slug must be at most %d characters", MaxLength)
+	ErrInvalid = errors.New("slug may only contain lowercase letters, digits and single '-' or '_' between words")
+)
+
+var pattern = regexp.MustCompile(`^[a-z0-9]+(?:[-_][a-z0-9]+)*$`)
+
+// Validate 校验手填的 slug，不合法时返回 ErrEmpty、ErrTooLong 或 ErrInvalid。
+func Validate(s string) error {
+	switch {
+	case s == "":
+		return ErrEmpty
+	case len(s) > MaxLength:
+		return ErrTooLong
+	case !pattern.MatchString(s):
+		return ErrInvalid
+	}
+	return nil
+}
+
+// Generate 从标题生成合法的 slug：去掉字母上的重音符号并转为小写，其余字符（空格、标点、中文等）
+// 视为分隔符，连续的分隔符合并为一个 -；没有剩下任何字母或数字时返回 "doc"。
+func Generate(title string) string {
+	folded, _, err := transform.String(transform.Chain(norm.NFD, runes.Remove(runes.In(unicode.Mn)), norm.NFC), title)
+	if err != nil {
+		folded = title
+	}
+	var b strings.Builder
+	pending := false
+	for _, r := range strings.ToLower(folded) {
+		if !(r >= 'a' && r <= 'z' || r >= '0' && r <= '9') {
+			pending = b.Len() > 0
+			continue
+		}
+		if pending {
+			if b.Len()+2 > MaxLength {
+				break
+			}
+			b.WriteByte('-')
+			pending = false
+		}
+		if b.Len() >= MaxLength {
+			break
+		}
+		b.WriteRune(r)
+	}
+	if b.Len() == 0 {
+		return fallback
+	}
+	return b.String()
+}
+
+// WithSuffix 返回追加 -n 后缀的 slug，必要时截短 base 以保证总字符数不超过 MaxLength。
+func WithSuffix(base string, n int) string {
+	suffix := "-" + strconv.Itoa(n)
+	if chars := []rune(base); len(chars)+len(suffix) > MaxLength {
+		base = strings.TrimRight(string(chars[:MaxLength-len(suffix)]), "-_")
+	}
+	return base + suffix
+}
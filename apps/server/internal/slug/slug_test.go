@@ -0,0 +1,115 @@
+package slug
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestValidate(t *testing.T) {
+	tests := []struct {
+		name string
+		slug string
+		want error
+	}{
+		{"simple", "getting-started", nil},
+		{"underscore", "api_v2", nil},
+		{"digits only", "2024", nil},
+		{"max length", strings.Repeat("a", MaxLength), nil},
+		{"empty", "", ErrEmpty},
+		{"too long", strings.Repeat("a", MaxLength+1), ErrTooLong},
+		{"uppercase", "Getting-Started", ErrInvalid},
+		{"leading separator", "-intro", ErrInvalid},
+		{"trailing separator", "intro_", ErrInvalid},
+		{"double separator", "intro--guide", ErrInvalid},
+		{"mixed separators", "intro-_guide", ErrInvalid},
+		{"space", "intro guide", ErrInvalid},
+		{"slash", "intro/guide", ErrInvalid},
+		{"non ascii", "文档", ErrInvalid},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := Validate(tt.slug); !errors.Is(err, tt.want) {
+				t.Errorf("Validate(%q) = %v, want %v", tt.slug, err, tt.want)
+			}
+		})
+	}
+}
+
+func TestGenerate(t *testing.T) {
+	tests := []struct {
+		title string
+		want  string
+	}{
+		{"Getting Started", "getting-started"},
+		{"  Hello,   World!  ", "hello-world"},
+		{"Café Crème", "cafe-creme"},
+		{"API v2 -- Reference", "api-v2-reference"},
+		{"snake_case_title", "snake-case-title"},
+		{"Go 语言入门", "go"},
+		{"使用指南 2024", "2024"},
+		{"中文标题", fallback},
+		{"!!!", fallback},
+		{"", fallback},
+	}
+	for _, tt := range tests {
+		t.Run(tt.title, func(t *testing.T) {
+			got := Generate(tt.title)
+			if got != tt.want {
+				t.Errorf("Generate(%q) = %q, want %q", tt.title, got, tt.want)
+			}
+			if err := Validate(got); err != nil {
+				t.Errorf("Generate(%q) = %q is not a valid slug: %v", tt.title, got, err)
+			}
+		})
+	}
+}
+
+// 截断到 MaxLength 时不能以分隔符结尾。
+func TestGenerateTruncates(t *testing.T) {
+	tests := []struct {
+		name  string
+		title string
+	}{
+		{"single word", strings.Repeat("a", MaxLength+50)},
+		{"separator at limit", strings.Repeat("a", MaxLength-1) + " bbb"},
+		{"many words", strings.Repeat("ab ", MaxLength)},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := Generate(tt.title)
+			if len(got) > MaxLength {
+				t.Fatalf("Generate() returned %d characters, want at most %d", len(got), MaxLength)
+			}
+			if err := Validate(got); err != nil {
+				t.Errorf("Generate() = %q is not a valid slug: %v", got, err)
+			}
+		})
+	}
+}
+
+func TestWithSuffix(t *testing.T) {
+	tests := []struct {
+		name string
+		base string
+		n    int
+		want string
+	}{
+		{"short", "intro", 2, "intro-2"},
+		{"multi digit", "intro", 12, "intro-12"},
+		{"exactly fits", strings.Repeat("a", MaxLength-2), 2, strings.Repeat("a", MaxLength-2) + "-2"},
+		{"truncated", strings.Repeat("a", MaxLength), 3, strings.Repeat("a", MaxLength-2) + "-3"},
+		{"trims separator", strings.Repeat("a", MaxLength-4) + "-bbb", 10, strings.Repeat("a", MaxLength-4) + "-10"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := WithSuffix(tt.base, tt.n)
+			if got != tt.want {
+				t.Errorf("WithSuffix(%q, %d) = %q, want %q", tt.base, tt.n, got, tt.want)
+			}
+			if err := Validate(got); err != nil {
+				t.Errorf("WithSuffix(%q, %d) = %q is not a valid slug: %v", tt.base, tt.n, got, err)
+			}
+		})
+	}
+}
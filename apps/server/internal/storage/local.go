@@ -0,0 +1,142 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"io"
+	"io/fs"
+	"net/http"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+)
+
+// Local 把文件保存在本地目录，并通过 Handler 以 baseURL 为前缀对外提供访问。
+type Local struct {
+	dir     string
+	baseURL string
+}
+
+func NewLocal(dir string, baseURL string) (*Local, error) {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, err
+	}
+	return &Local{dir: dir, baseURL: strings.TrimRight(baseURL, "/")}, nil
+}
+
+// Save 先写入同目录下的临时文件再重命名，避免读取方看到写了一半的文件。
+func (l *Local) Save(ctx context.Context, key string, r io.Reader) error {
+	target, err := l.path(key)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(target), 0o755); err != nil {
+		return err
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(target), ".upload-*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err := io.Copy(tmp, r); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	if err := os.Chmod(tmp.Name(), 0o644); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), target)
+}
+
+func (l *Local) Open(ctx context.Context, key string) (io.ReadCloser, error) {
+	target, err := l.path(key)
+	if err != nil {
+		return nil, err
+	}
+	return os.Open(target)
+}
+
+func (l *Local) Delete(ctx context.Context, key string) error {
+	target, err := l.path(key)
+	if err != nil {
+		return err
+	}
+	if err := os.Remove(target); err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return err
+	}
+	return nil
+}
+
+func (l *Local) URL(key string) string {
+	return l.baseURL + "/" + key
+}
+
+func (l *Local) KeyFromURL(url string) (string, bool) {
+	key, ok := strings.CutPrefix(url, l.baseURL+"/")
+	if !ok {
+		return "", false
+	}
+	if _, err := l.path(key); err != nil {
+		return "", false
+	}
+	return key, true
+}
+
+// Ping 在上传目录中创建并删除一个临时文件，确认目录存在且可写，用于健康检查。
+func (l *Local) Ping(ctx context.Context) error {
+	tmp, err := os.CreateTemp(l.dir, ".health-*")
+	if err != nil {
+		return err
+	}
+	name := tmp.Name()
+	if err := tmp.Close(); err != nil {
+		os.Remove(name)
+		return err
+	}
+	return os.Remove(name)
+}
+
+// Handler 提供上传目录的只读访问：不列目录，并禁止浏览器做 MIME 嗅探。
+func (l *Local) Handler() http.Handler {
+	files := http.FileServer(noDirFS{http.Dir(l.dir)})
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("X-Content-Type-Options", "nosniff")
+		w.Header().Set("Content-Security-Policy", "default-src 'none'; style-src 'unsafe-inline'; sandbox")
+		files.ServeHTTP(w, r)
+	})
+}
+
+// path 把 key 映射到 dir 下的文件路径，拒绝绝对路径与 .. 等越界访问。
+func (l *Local) path(key string) (string, error) {
+	if key == "" || strings.HasPrefix(key, "/") || path.Clean(key) != key || strings.HasPrefix(key, "../") || key == ".." {
+		return "", ErrInvalidKey
+	}
+	return filepath.Join(l.dir, filepath.FromSlash(key)), nil
+}
+
+type noDirFS struct {
+	fs http.FileSystem
+}
+
+func (n noDirFS) Open(name string) (http.File, error) {
+	file, err := n.fs.Open(name)
+	if err != nil {
+		return nil, err
+	}
+	info, err := file.Stat()
+	if err != nil {
+		file.Close()
+		return nil, err
+	}
+	if info.IsDir() {
+		file.Close()
+		return nil, fs.ErrNotExist
+	}
+	return file, nil
+}
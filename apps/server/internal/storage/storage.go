@@ -0,0 +1,22 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"io"
+)
+
+var ErrInvalidKey = errors.New("invalid storage key")
+
+// Backend 抽象上传文件的存储位置。key 是以 / 分隔的相对路径，例如 2024/05/01/<hash>.png；
+// 本地磁盘之外的 S3、OSS 等实现只需把 key 映射为对象名并返回对应的访问地址。
+type Backend interface {
+	Save(ctx context.Context, key string, r io.Reader) error
+	// Open 读取已保存的文件，key 不存在时返回 fs.ErrNotExist。
+	Open(ctx context.Context, key string) (io.ReadCloser, error)
+	Delete(ctx context.Context, key string) error
+	// URL 返回 key 对应的公开访问地址。
+	URL(key string) string
+	// KeyFromURL 是 URL 的逆操作，地址不属于该存储时返回 false。
+	KeyFromURL(url string) (string, bool)
+}
@@ -0,0 +1,88 @@
+package store
+
+import (
+	"context"
+	"time"
+)
+
+// apiKeyTouchInterval 是更新 last_used_at 的最小间隔，避免每个请求都写库。
+const apiKeyTouchInterval = time.Minute
+
+type APIKey struct {
+	ID     int64  `json:"id"`
+	UserID int64  `json:"-"`
+	Name   string `json:"name"`
+	// Prefix 是明文 key 的开头几位，用于在列表中辨认。
+	Prefix     string     `json:"prefix"`
+	Hash       string     `json:"-"`
+	Scope      string     `json:"scope"`
+	LastUsedAt *time.Time `json:"last_used_at"`
+	CreatedAt  time.Time  `json:"created_at"`
+}
+
+const apiKeyColumns = "key_id, user_id, name, key_prefix, key_hash, scope, last_used_at, created_at"
+
+func apiKeyFields(key *APIKey) []any {
+	return []any{&key.ID, &key.UserID, &key.Name, &key.Prefix, &key.Hash, &key.Scope, &key.LastUsedAt, &key.CreatedAt}
+}
+
+func (s *Store) CreateAPIKey(ctx context.Context, key *APIKey) error {
+	key.CreatedAt = time.Now().UTC()
+	id, err := s.insert(ctx, "key_id",
+		"INSERT INTO api_keys (user_id, name, key_prefix, key_hash, scope, created_at) VALUES (?, ?, ?, ?, ?, ?)",
+		key.UserID, key.Name, key.Prefix, key.Hash, key.Scope, key.CreatedAt)
+	if err != nil {
+		return err
+	}
+	key.ID = id
+	return nil
+}
+
+// ListAPIKeys 按创建时间倒序返回用户的全部 API Key。
+func (s *Store) ListAPIKeys(ctx context.Context, userID int64) ([]APIKey, error) {
+	rows, err := s.query(ctx, "SELECT "+apiKeyColumns+" FROM api_keys WHERE user_id = ? ORDER BY created_at DESC, key_id DESC", userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	keys := []APIKey{}
+	for rows.Next() {
+		var key APIKey
+		if err := rows.Scan(apiKeyFields(&key)...); err != nil {
+			return nil, err
+		}
+		keys = append(keys, key)
+	}
+	return keys, rows.Err()
+}
+
+// UseAPIKey 按摘要查找 API Key 并返回其所属用户当前的角色，同时更新最后使用时间；key 不存在时返回 ErrNotFound。
+func (s *Store) UseAPIKey(ctx context.Context, hash string) (*APIKey, string, error) {
+	key := &APIKey{}
+	var role string
+	err := s.queryRow(ctx, "SELECT k.key_id, k.user_id, k.name, k.key_prefix, k.key_hash, k.scope, k.last_used_at, k.created_at, u.role"+
+		" FROM api_keys k JOIN users u ON u.user_id = k.user_id WHERE k.key_hash = ?", hash).
+		Scan(append(apiKeyFields(key), &role)...)
+	if err != nil {
+		return nil, "", notFound(err)
+	}
+
+	now := time.Now().UTC()
+	if key.LastUsedAt == nil || now.Sub(*key.LastUsedAt) >= apiKeyTouchInterval {
+		if _, err := s.exec(ctx, "UPDATE api_keys SET last_used_at = ? WHERE key_id = ?", now, key.ID); err != nil {
+			return nil, "", err
+		}
+		key.LastUsedAt = &now
+	}
+	return key, role, nil
+}
+
+// DeleteAPIKey 吊销 API Key，key 不存在或不属于该用户时返回 ErrNotFound。
+func (s *Store) DeleteAPIKey(ctx context.Context, userID int64, id int64) error {
+	result, err := s.exec(ctx, "DELETE FROM api_keys WHERE key_id = ? AND user_id = ?", id, userID)
+	if err != nil {
+		return err
+	}
+	return requireAffected(result)
+}
@@ -0,0 +1,250 @@
+package store
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"io"
+	"slices"
+	"strings"
+)
+
+// BackupTable 描述参与备份与恢复的业务表。IDColumn 为自增主键（没有时为空），
+// SelfRef 为引用本表的外键列，恢复时先写入 NULL，整表写完后再回填，避免父行尚未写入。
+type BackupTable struct {
+	Name     string
+	IDColumn string
+	SelfRef  string
+}
+
+// BackupTables 是全部业务表，按外键依赖排序（被引用的表在前）；新增表时需要同步维护。
+// schema_migrations 不在其中，备份文件另外记录迁移版本。
+var BackupTables = []BackupTable{
+	{Name: "users", IDColumn: "user_id"},
+	{Name: "sessions", IDColumn: "session_id"},
+	{Name: "refresh_tokens", IDColumn: "token_id"},
+	{Name: "refresh_token_blacklist"},
+	{Name: "system_configs"},
+	{Name: "docs", IDColumn: "doc_id", SelfRef: "parent_id"},
+	{Name: "doc_versions", IDColumn: "version_id"},
+	{Name: "doc_drafts"},
+	{Name: "doc_permissions", IDColumn: "permission_id"},
+	{Name: "user_identities", IDColumn: "identity_id"},
+	{Name: "config_audit_logs", IDColumn: "audit_id"},
+	{Name: "doc_comments", IDColumn: "comment_id", SelfRef: "parent_comment_id"},
+	{Name: "tags", IDColumn: "tag_id"},
+	{Name: "doc_tags"},
+	{Name: "webhooks", IDColumn: "webhook_id"},
+	{Name: "webhook_deliveries", IDColumn: "delivery_id"},
+	{Name: "user_recovery_codes"},
+	{Name: "user_favorites"},
+	{Name: "doc_views"},
+	{Name: "api_keys", IDColumn: "key_id"},
+	{Name: "password_reset_tokens", IDColumn: "token_id"},
+	{Name: "email_verification_tokens", IDColumn: "token_id"},
+	{Name: "login_attempts"},
+	{Name: "doc_templates", IDColumn: "template_id"},
+	{Name: "doc_template_grants"},
+	{Name: "doc_links"},
+	{Name: "spaces", IDColumn: "space_id"},
+	{Name: "space_members"},
+	{Name: "jobs", IDColumn: "job_id"},
+	{Name: "doc_locks"},
+}
+
+// LookupBackupTable 按表名查找 BackupTables 中的表。
+func LookupBackupTable(name string) (BackupTable, bool) {
+	index := slices.IndexFunc(BackupTables, func(t BackupTable) bool { return t.Name == name })
+	if index < 0 {
+		return BackupTable{}, false
+	}
+	return BackupTables[index], true
+}
+
+// ErrInvalidBackupData 表示待恢复的数据与当前表结构不一致（未知的表或列、顺序错误等）。
+var ErrInvalidBackupData = errors.New("invalid backup data")
+
+// TableDumper 接收 DumpTables 逐表读出的数据：每张表先调用一次 Table，再逐行调用 Row。
+type TableDumper interface {
+	Table(name string, columns []string) error
+	Row(values []any) error
+}
+
+// TableSource 为 RestoreTables 按 BackupTables 的顺序提供数据。
+type TableSource interface {
+	// NextTable 返回下一张表的表名与列名，没有更多表时返回 io.EOF。
+	NextTable() (string, []string, error)
+	// NextRow 返回当前表的下一行，当前表读完时返回 io.EOF。
+	NextRow() ([]any, error)
+}
+
+// DumpTables 在同一个只读快照中依次读出 tables 的全部数据，保证各表之间的引用一致。
+func (s *Store) DumpTables(ctx context.Context, tables []BackupTable, dumper TableDumper) error {
+	tx, err := s.db.BeginTx(ctx, &sql.TxOptions{Isolation: s.dialect.SnapshotIsolation()})
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+	snapshot := s.withQuerier(tx)
+
+	for _, table := range tables {
+		if err := snapshot.dumpTable(ctx, table, dumper); err != nil {
+			return fmt.Errorf("dump %s: %w", table.Name, err)
+		}
+	}
+	return tx.Commit()
+}
+
+func (s *Store) dumpTable(ctx context.Context, table BackupTable, dumper TableDumper) error {
+	query := "SELECT * FROM " + table.Name
+	if table.IDColumn != "" {
+		query += " ORDER BY " + table.IDColumn
+	}
+	rows, err := s.query(ctx, query)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	columns, err := rows.Columns()
+	if err != nil {
+		return err
+	}
+	if err := dumper.Table(table.Name, columns); err != nil {
+		return err
+	}
+	values := make([]any, len(columns))
+	pointers := make([]any, len(columns))
+	for i := range values {
+		pointers[i] = &values[i]
+	}
+	for rows.Next() {
+		if err := rows.Scan(pointers...); err != nil {
+			return err
+		}
+		for i, value := range values {
+			// MySQL 驱动把文本列扫描为 []byte，统一转成字符串。
+			if raw, ok := value.([]byte); ok {
+				values[i] = string(raw)
+			}
+		}
+		if err := dumper.Row(values); err != nil {
+			return err
+		}
+	}
+	return rows.Err()
+}
+
+// RestoreTables 在一个事务中清空全部 BackupTables，再写入 source 提供的数据，返回每张表写入的行数。
+// source 中没有出现的表（如备份时排除的审计日志）恢复后为空；任何一步失败都会整体回滚。
+func (s *Store) RestoreTables(ctx context.Context, source TableSource) (map[string]int, error) {
+	counts := map[string]int{}
+	err := s.WithTx(ctx, func(tx *Store) error {
+		for i := len(BackupTables) - 1; i >= 0; i-- {
+			table := BackupTables[i]
+			// MySQL 逐行检查外键，先断开自引用再整表删除。
+			if table.SelfRef != "" {
+				if _, err := tx.exec(ctx, "UPDATE "+table.Name+" SET "+table.SelfRef+" = NULL"); err != nil {
+					return err
+				}
+			}
+			if _, err := tx.exec(ctx, "DELETE FROM "+table.Name); err != nil {
+				return fmt.Errorf("clear %s: %w", table.Name, err)
+			}
+		}
+
+		last := -1
+		for {
+			name, columns, err := source.NextTable()
+			if errors.Is(err, io.EOF) {
+				break
+			}
+			if err != nil {
+				return err
+			}
+			index := slices.IndexFunc(BackupTables, func(t BackupTable) bool { return t.Name == name })
+			if index <= last {
+				return fmt.Errorf("%w: unexpected table %q", ErrInvalidBackupData, name)
+			}
+			last = index
+			count, err := tx.restoreTable(ctx, BackupTables[index], columns, source)
+			if err != nil {
+				return fmt.Errorf("restore %s: %w", name, err)
+			}
+			counts[name] = count
+		}
+
+		for _, table := range BackupTables {
+			if query := s.dialect.ResetSequence(table.Name, table.IDColumn); query != "" {
+				if _, err := tx.exec(ctx, query); err != nil {
+					return fmt.Errorf("reset sequence of %s: %w", table.Name, err)
+				}
+			}
+		}
+		return nil
+	})
+	return counts, err
+}
+
+func (s *Store) restoreTable(ctx context.Context, table BackupTable, columns []string, source TableSource) (int, error) {
+	// 列名会拼进 SQL，只接受当前表结构中存在的列。
+	rows, err := s.query(ctx, "SELECT * FROM "+table.Name+" WHERE 1 = 0")
+	if err != nil {
+		return 0, err
+	}
+	existing, err := rows.Columns()
+	rows.Close()
+	if err != nil {
+		return 0, err
+	}
+	idIndex, selfRefIndex := -1, -1
+	for i, column := range columns {
+		if !slices.Contains(existing, column) || slices.Index(columns, column) != i {
+			return 0, fmt.Errorf("%w: unknown column %q", ErrInvalidBackupData, column)
+		}
+		switch column {
+		case table.IDColumn:
+			idIndex = i
+		case table.SelfRef:
+			selfRefIndex = i
+		}
+	}
+	if table.SelfRef != "" && selfRefIndex >= 0 && idIndex < 0 {
+		return 0, fmt.Errorf("%w: %s requires column %q", ErrInvalidBackupData, table.Name, table.IDColumn)
+	}
+
+	insert := "INSERT INTO " + table.Name + " (" + strings.Join(columns, ", ") + ") VALUES (" +
+		strings.TrimSuffix(strings.Repeat("?, ", len(columns)), ", ") + ")"
+	type reference struct{ id, parent any }
+	var references []reference
+	count := 0
+	for {
+		values, err := source.NextRow()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return count, err
+		}
+		if len(values) != len(columns) {
+			return count, fmt.Errorf("%w: row has %d values, want %d", ErrInvalidBackupData, len(values), len(columns))
+		}
+		if selfRefIndex >= 0 && values[selfRefIndex] != nil {
+			references = append(references, reference{id: values[idIndex], parent: values[selfRefIndex]})
+			values[selfRefIndex] = nil
+		}
+		if _, err := s.exec(ctx, insert, values...); err != nil {
+			return count, err
+		}
+		count++
+	}
+
+	for _, ref := range references {
+		if _, err := s.exec(ctx, "UPDATE "+table.Name+" SET "+table.SelfRef+" = ? WHERE "+table.IDColumn+" = ?",
+			ref.parent, ref.id); err != nil {
+			return count, err
+		}
+	}
+	return count, nil
+}
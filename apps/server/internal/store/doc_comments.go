@@ -0,0 +1,126 @@
+package store
+
+import (
+	"context"
+	"strings"
+	"time"
+)
+
+// Comment 是文档上的一条评论；ParentID 非空表示对顶层评论的回复。
+type Comment struct {
+	ID          int64     `json:"id"`
+	DocID       int64     `json:"doc_id"`
+	ParentID    *int64    `json:"parent_comment_id"`
+	AuthorID    int64     `json:"author_id"`
+	AuthorName  string    `json:"author_name"`
+	Anchor      string    `json:"anchor"`
+	Content     string    `json:"content"`
+	ContentHTML string    `json:"content_html"`
+	CreatedAt   time.Time `json:"created_at"`
+	// Replies 只在列表接口中填充顶层评论的回复。
+	Replies []Comment `json:"replies,omitempty"`
+}
+
+// CommentFilter 描述顶层评论列表的排序与分页条件。
+type CommentFilter struct {
+	DocID int64
+	// Newest 为 true 时按时间倒序，否则正序；回复始终按时间正序。
+	Newest bool
+	Limit  int
+	Offset int
+}
+
+const commentColumns = "c.comment_id, c.doc_id, c.parent_comment_id, c.author_id, COALESCE(u.name, ''), c.anchor, c.content, c.content_html, c.created_at"
+
+const commentFrom = " FROM doc_comments c LEFT JOIN users u ON u.user_id = c.author_id"
+
+func scanComment(row scanner) (*Comment, error) {
+	comment := &Comment{}
+	err := row.Scan(&comment.ID, &comment.DocID, &comment.ParentID, &comment.AuthorID, &comment.AuthorName,
+		&comment.Anchor, &comment.Content, &comment.ContentHTML, &comment.CreatedAt)
+	if err != nil {
+		return nil, notFound(err)
+	}
+	return comment, nil
+}
+
+func (s *Store) CreateComment(ctx context.Context, comment *Comment) error {
+	comment.CreatedAt = time.Now().UTC()
+	id, err := s.insert(ctx, "comment_id",
+		"INSERT INTO doc_comments (doc_id, parent_comment_id, author_id, anchor, content, content_html, created_at) VALUES (?, ?, ?, ?, ?, ?, ?)",
+		comment.DocID, comment.ParentID, comment.AuthorID, comment.Anchor, comment.Content, comment.ContentHTML, comment.CreatedAt)
+	if err != nil {
+		return err
+	}
+	comment.ID = id
+	return nil
+}
+
+func (s *Store) GetComment(ctx context.Context, id int64) (*Comment, error) {
+	return scanComment(s.queryRow(ctx, "SELECT "+commentColumns+commentFrom+" WHERE c.comment_id = ?", id))
+}
+
+// ListComments 返回一页顶层评论（附带各自的全部回复）及顶层评论总数。
+func (s *Store) ListComments(ctx context.Context, filter CommentFilter) ([]Comment, int, error) {
+	var total int
+	if err := s.queryRow(ctx, "SELECT COUNT(*) FROM doc_comments WHERE doc_id = ? AND parent_comment_id IS NULL",
+		filter.DocID).Scan(&total); err != nil {
+		return nil, 0, err
+	}
+
+	order := " ORDER BY c.created_at, c.comment_id"
+	if filter.Newest {
+		order = " ORDER BY c.created_at DESC, c.comment_id DESC"
+	}
+	comments, err := s.listComments(ctx,
+		" WHERE c.doc_id = ? AND c.parent_comment_id IS NULL"+order+" LIMIT ? OFFSET ?",
+		filter.DocID, filter.Limit, filter.Offset)
+	if err != nil || len(comments) == 0 {
+		return comments, total, err
+	}
+
+	ids := make([]any, 0, len(comments))
+	index := make(map[int64]int, len(comments))
+	for i, comment := range comments {
+		ids = append(ids, comment.ID)
+		index[comment.ID] = i
+	}
+	placeholders := strings.TrimSuffix(strings.Repeat("?, ", len(ids)), ", ")
+	replies, err := s.listComments(ctx,
+		" WHERE c.parent_comment_id IN ("+placeholders+") ORDER BY c.created_at, c.comment_id", ids...)
+	if err != nil {
+		return nil, 0, err
+	}
+	for _, reply := range replies {
+		parent := &comments[index[*reply.ParentID]]
+		parent.Replies = append(parent.Replies, reply)
+	}
+	return comments, total, nil
+}
+
+func (s *Store) listComments(ctx context.Context, where string, args ...any) ([]Comment, error) {
+	rows, err := s.query(ctx, "SELECT "+commentColumns+commentFrom+where, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	comments := []Comment{}
+	for rows.Next() {
+		comment, err := scanComment(rows)
+		if err != nil {
+			return nil, err
+		}
+		comments = append(comments, *comment)
+	}
+	return comments, rows.Err()
+}
+
+// DeleteComment 删除评论，顶层评论的回复由外键级联删除。
+func (s *Store) DeleteComment(ctx context.Context, id int64) error {
+	result, err := s.exec(ctx, "DELETE FROM doc_comments WHERE comment_id = ?", id)
+	if err != nil {
+		return err
+	}
+	return requireAffected(result)
+}
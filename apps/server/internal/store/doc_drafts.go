@@ -0,0 +1,127 @@
+package store
+
+import (
+	"context"
+	"errors"
+	"time"
+)
+
+// DocumentDraft 是文档尚未发布的标题与正文修改，每篇文档最多一份。
+type DocumentDraft struct {
+	DocID     int64     `json:"doc_id"`
+	Title     string    `json:"title"`
+	Content   string    `json:"content"`
+	EditorID  int64     `json:"editor_id"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+func scanDocumentDraft(row scanner) (*DocumentDraft, error) {
+	draft := &DocumentDraft{}
+	if err := row.Scan(&draft.DocID, &draft.Title, &draft.Content, &draft.EditorID, &draft.UpdatedAt); err != nil {
+		return nil, notFound(err)
+	}
+	return draft, nil
+}
+
+// GetDocumentDraft 返回文档的草稿，没有未发布的修改时返回 ErrNotFound。
+func (s *Store) GetDocumentDraft(ctx context.Context, docID int64) (*DocumentDraft, error) {
+	return scanDocumentDraft(s.queryRow(ctx,
+		"SELECT doc_id, title, content, editor_id, updated_at FROM doc_drafts WHERE doc_id = ?", docID))
+}
+
+// SaveDocumentDraft 写入或覆盖文档的草稿，不改动已发布的内容与版本号。
+func (s *Store) SaveDocumentDraft(ctx context.Context, draft *DocumentDraft) error {
+	draft.UpdatedAt = time.Now().UTC()
+	return s.WithTx(ctx, func(tx *Store) error {
+		_, err := tx.GetDocumentDraft(ctx, draft.DocID)
+		switch {
+		case errors.Is(err, ErrNotFound):
+			_, err = tx.exec(ctx,
+				"INSERT INTO doc_drafts (doc_id, title, content, editor_id, updated_at) VALUES (?, ?, ?, ?, ?)",
+				draft.DocID, draft.Title, draft.Content, draft.EditorID, draft.UpdatedAt)
+		case err == nil:
+			_, err = tx.exec(ctx,
+				"UPDATE doc_drafts SET title = ?, content = ?, editor_id = ?, updated_at = ? WHERE doc_id = ?",
+				draft.Title, draft.Content, draft.EditorID, draft.UpdatedAt, draft.DocID)
+		}
+		return err
+	})
+}
+
+// DiscardDocumentDraft 删除文档的草稿，没有草稿时返回 ErrNotFound。
+func (s *Store) DiscardDocumentDraft(ctx context.Context, docID int64) error {
+	result, err := s.exec(ctx, "DELETE FROM doc_drafts WHERE doc_id = ?", docID)
+	if err != nil {
+		return err
+	}
+	return requireAffected(result)
+}
+
+// PublishDocument 发布文档：有草稿时把草稿的标题与正文写回文档，版本号加 1 并保存快照，随后删除草稿；
+// 状态改为 published 并记录发布时间。文档已发布且没有草稿时不做任何修改，返回 false。
+func (s *Store) PublishDocument(ctx context.Context, doc *Document, editorID int64, summary string) (bool, error) {
+	published := false
+	err := s.WithTx(ctx, func(tx *Store) error {
+		var err error
+		published, err = tx.publishDocument(ctx, doc, editorID, summary)
+		return err
+	})
+	return published, err
+}
+
+// publishDocument 是 PublishDocument 在事务中的实现；发布同时取消文档的定时发布。
+func (s *Store) publishDocument(ctx context.Context, doc *Document, editorID int64, summary string) (bool, error) {
+	draft, err := s.GetDocumentDraft(ctx, doc.ID)
+	if err != nil && !errors.Is(err, ErrNotFound) {
+		return false, err
+	}
+	if draft == nil && doc.Status == DocStatusPublished {
+		if doc.PublishAt != nil {
+			// 定时发布之后草稿被丢弃，已经没有要发布的内容。
+			doc.PublishAt = nil
+			_, err := s.exec(ctx, "UPDATE docs SET publish_at = NULL WHERE doc_id = ?", doc.ID)
+			return false, err
+		}
+		return false, nil
+	}
+
+	now := time.Now().UTC()
+	doc.Status, doc.PublishedAt, doc.PublishAt, doc.DraftUpdatedAt = DocStatusPublished, &now, nil, nil
+	if draft == nil {
+		doc.UpdatedAt = now
+		result, err := s.exec(ctx,
+			"UPDATE docs SET status = ?, published_at = ?, publish_at = NULL, updated_at = ? WHERE doc_id = ? AND deleted_at IS NULL",
+			doc.Status, doc.PublishedAt, doc.UpdatedAt, doc.ID)
+		if err != nil {
+			return false, err
+		}
+		return true, requireAffected(result)
+	}
+
+	doc.Title, doc.Content = draft.Title, draft.Content
+	if err := s.UpdateDocument(ctx, doc, editorID, summary); err != nil {
+		return false, err
+	}
+	if _, err := s.exec(ctx, "UPDATE docs SET status = ?, published_at = ?, publish_at = NULL WHERE doc_id = ?", doc.Status, doc.PublishedAt, doc.ID); err != nil {
+		return false, err
+	}
+	if _, err := s.exec(ctx, "DELETE FROM doc_drafts WHERE doc_id = ?", doc.ID); err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+// SetDocumentStatus 修改文档状态（取消发布或归档），不改动内容与版本号。
+func (s *Store) SetDocumentStatus(ctx context.Context, doc *Document, status string) error {
+	doc.UpdatedAt = time.Now().UTC()
+	result, err := s.exec(ctx, "UPDATE docs SET status = ?, updated_at = ? WHERE doc_id = ? AND deleted_at IS NULL",
+		status, doc.UpdatedAt, doc.ID)
+	if err != nil {
+		return err
+	}
+	if err := requireAffected(result); err != nil {
+		return err
+	}
+	doc.Status = status
+	return nil
+}
@@ -0,0 +1,165 @@
+package store
+
+import (
+	"context"
+	"strings"
+
+	"github.com/lifei6671/plaindoc/apps/server/internal/wikilink"
+)
+
+// backfillBatchSize 是补建内部链接时每批读取的文档数。
+const backfillBatchSize = 100
+
+// syncDocumentLinks 按 doc 当前的正文重建它发出的内部链接，并让其他文档中指向 doc 的链接跟随 doc 的 slug。
+// 由 CreateDocument 与 UpdateDocument 在同一个事务中调用。
+func (s *Store) syncDocumentLinks(ctx context.Context, doc *Document) error {
+	if _, err := s.exec(ctx, "DELETE FROM doc_links WHERE source_doc_id = ?", doc.ID); err != nil {
+		return err
+	}
+	for _, target := range wikilink.Extract([]byte(doc.Content)) {
+		if target == doc.Slug {
+			continue
+		}
+		_, err := s.exec(ctx,
+			"INSERT INTO doc_links (source_doc_id, target_slug, target_doc_id) VALUES (?, ?, (SELECT doc_id FROM docs WHERE space = ? AND slug = ? AND deleted_at IS NULL))",
+			doc.ID, target, doc.Space, target)
+		if err != nil {
+			return err
+		}
+	}
+	return s.relinkDocument(ctx, doc)
+}
+
+// relinkDocument 让指向 doc 的链接与 doc 的 slug 保持一致：按旧 slug 写的链接标记为断链，
+// 同一空间内按当前 slug 写的断链接到 doc 上。
+func (s *Store) relinkDocument(ctx context.Context, doc *Document) error {
+	if _, err := s.exec(ctx, "UPDATE doc_links SET target_doc_id = NULL WHERE target_doc_id = ? AND target_slug <> ?",
+		doc.ID, doc.Slug); err != nil {
+		return err
+	}
+	_, err := s.exec(ctx,
+		"UPDATE doc_links SET target_doc_id = ? WHERE target_slug = ? AND target_doc_id IS NULL AND source_doc_id <> ? AND source_doc_id IN (SELECT doc_id FROM docs WHERE space = ?)",
+		doc.ID, doc.Slug, doc.ID, doc.Space)
+	return err
+}
+
+// unlinkDocument 把指向 doc 的链接标记为断链，用于移入回收站；恢复时由 relinkDocument 重新接上。
+func (s *Store) unlinkDocument(ctx context.Context, docID int64) error {
+	_, err := s.exec(ctx, "UPDATE doc_links SET target_doc_id = NULL WHERE target_doc_id = ?", docID)
+	return err
+}
+
+// ListBacklinks 按更新时间倒序返回一页链接到 targetID 且 viewer 可读的文档（不含正文与回收站中的文档）及总数。
+func (s *Store) ListBacklinks(ctx context.Context, viewer Viewer, targetID int64, limit int, offset int) ([]Document, int, error) {
+	from := " FROM docs JOIN (SELECT source_doc_id FROM doc_links WHERE target_doc_id = ?) l ON l.source_doc_id = docs.doc_id"
+	conditions, args := []string{"deleted_at IS NULL"}, []any{targetID}
+	if condition, conditionArgs := visibleCondition(viewer); condition != "" {
+		conditions = append(conditions, condition)
+		args = append(args, conditionArgs...)
+	}
+	condition, conditionArgs := statusCondition(viewer, DocStatusPublished, DocStatusArchived)
+	conditions = append(conditions, condition)
+	args = append(args, conditionArgs...)
+	where := " WHERE " + strings.Join(conditions, " AND ")
+
+	var total int
+	if err := s.queryRow(ctx, "SELECT COUNT(*)"+from+where, args...).Scan(&total); err != nil {
+		return nil, 0, err
+	}
+	rows, err := s.query(ctx,
+		"SELECT "+documentSummaryColumns+from+where+" ORDER BY updated_at DESC, doc_id DESC LIMIT ? OFFSET ?",
+		append(args, limit, offset)...)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer rows.Close()
+
+	docs := []Document{}
+	for rows.Next() {
+		doc, err := scanDocument(rows)
+		if err != nil {
+			return nil, 0, err
+		}
+		docs = append(docs, *doc)
+	}
+	return docs, total, rows.Err()
+}
+
+// ResolveDocumentSlugs 返回 space 内 slugs 对应的、viewer 可读的文档 ID，不存在或不可读的 slug 不在结果中。
+// 草稿只对作者（与管理员）可见，与文档列表一致。
+func (s *Store) ResolveDocumentSlugs(ctx context.Context, viewer Viewer, space string, slugs []string) (map[string]int64, error) {
+	ids := map[string]int64{}
+	if len(slugs) == 0 {
+		return ids, nil
+	}
+	placeholders := strings.TrimSuffix(strings.Repeat("?, ", len(slugs)), ", ")
+	conditions, args := []string{"deleted_at IS NULL", "space = ?", "slug IN (" + placeholders + ")"}, []any{space}
+	for _, value := range slugs {
+		args = append(args, value)
+	}
+	if condition, conditionArgs := visibleCondition(viewer); condition != "" {
+		conditions = append(conditions, condition)
+		args = append(args, conditionArgs...)
+	}
+	condition, conditionArgs := statusCondition(viewer, DocStatusPublished, DocStatusArchived, DocStatusDraft)
+	conditions = append(conditions, condition)
+	args = append(args, conditionArgs...)
+
+	rows, err := s.query(ctx, "SELECT doc_id, slug FROM docs WHERE "+strings.Join(conditions, " AND "), args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var (
+			id    int64
+			value string
+		)
+		if err := rows.Scan(&id, &value); err != nil {
+			return nil, err
+		}
+		ids[value] = id
+	}
+	return ids, rows.Err()
+}
+
+// BackfillDocumentLinks 为正文中可能含有内部链接、但还没有链接记录的文档补建链接（如升级前创建的文档），返回处理的文档数。
+// 只有代码块中出现 [[ 的文档每次都会被重新检查，解析开销很小。
+func (s *Store) BackfillDocumentLinks(ctx context.Context) (int, error) {
+	var (
+		lastID int64
+		count  int
+	)
+	for {
+		rows, err := s.query(ctx,
+			"SELECT doc_id, space, slug, content FROM docs WHERE doc_id > ? AND content LIKE ? AND NOT EXISTS (SELECT 1 FROM doc_links WHERE source_doc_id = docs.doc_id) ORDER BY doc_id LIMIT ?",
+			lastID, "%[[%", backfillBatchSize)
+		if err != nil {
+			return count, err
+		}
+		var batch []Document
+		for rows.Next() {
+			var doc Document
+			if err := rows.Scan(&doc.ID, &doc.Space, &doc.Slug, &doc.Content); err != nil {
+				rows.Close()
+				return count, err
+			}
+			batch = append(batch, doc)
+		}
+		rows.Close()
+		if err := rows.Err(); err != nil {
+			return count, err
+		}
+
+		for i := range batch {
+			if err := s.WithTx(ctx, func(tx *Store) error { return tx.syncDocumentLinks(ctx, &batch[i]) }); err != nil {
+				return count, err
+			}
+			lastID = batch[i].ID
+			count++
+		}
+		if len(batch) < backfillBatchSize {
+			return count, nil
+		}
+	}
+}
@@ -0,0 +1,81 @@
+package store
+
+import (
+	"context"
+	"errors"
+	"time"
+)
+
+// ErrDocumentLocked 表示文档的编辑锁由其他用户持有且尚未过期。
+var ErrDocumentLocked = errors.New("document is locked by another user")
+
+// DocumentLock 是文档的编辑锁，ExpiresAt 之前由 UserID 持有；UserName 取自持锁用户当前的名称。
+type DocumentLock struct {
+	DocID      int64     `json:"doc_id"`
+	UserID     int64     `json:"user_id"`
+	UserName   string    `json:"user_name"`
+	AcquiredAt time.Time `json:"acquired_at"`
+	ExpiresAt  time.Time `json:"expires_at"`
+}
+
+// GetDocumentLock 返回文档当前有效的编辑锁，没有或已过期时返回 ErrNotFound。
+func (s *Store) GetDocumentLock(ctx context.Context, docID int64) (*DocumentLock, error) {
+	lock := &DocumentLock{}
+	err := s.queryRow(ctx,
+		`SELECT l.doc_id, l.user_id, u.name, l.acquired_at, l.expires_at
+		FROM doc_locks l JOIN users u ON u.user_id = l.user_id
+		WHERE l.doc_id = ? AND l.expires_at > ?`,
+		docID, time.Now().UTC()).Scan(&lock.DocID, &lock.UserID, &lock.UserName, &lock.AcquiredAt, &lock.ExpiresAt)
+	if err != nil {
+		return nil, notFound(err)
+	}
+	return lock, nil
+}
+
+// AcquireDocumentLock 为 userID 获取文档的编辑锁，有效期为 ttl：持有未过期的锁时续期并保留获取时间，锁已过期时直接接管。
+// 锁由其他用户持有时返回当前的锁与 ErrDocumentLocked。
+func (s *Store) AcquireDocumentLock(ctx context.Context, docID, userID int64, ttl time.Duration) (*DocumentLock, error) {
+	now := time.Now().UTC()
+	expiresAt := now.Add(ttl)
+	// MySQL 按书写顺序赋值，acquired_at 必须在 user_id 与 expires_at 之前计算，才能读到修改前的值。
+	result, err := s.exec(ctx,
+		`UPDATE doc_locks SET acquired_at = CASE WHEN user_id = ? AND expires_at > ? THEN acquired_at ELSE ? END, user_id = ?, expires_at = ?
+		WHERE doc_id = ? AND (user_id = ? OR expires_at <= ?)`,
+		userID, now, now, userID, expiresAt, docID, userID, now)
+	if err != nil {
+		return nil, err
+	}
+	err = requireAffected(result)
+	if errors.Is(err, ErrNotFound) {
+		_, err = s.exec(ctx,
+			"INSERT INTO doc_locks (doc_id, user_id, acquired_at, expires_at) VALUES (?, ?, ?, ?)",
+			docID, userID, now, expiresAt)
+	}
+	if errors.Is(err, ErrDuplicate) {
+		// 其他用户持有未过期的锁，或同时抢先写入了锁。
+		lock, err := s.GetDocumentLock(ctx, docID)
+		if err != nil {
+			return nil, err
+		}
+		if lock.UserID != userID {
+			return lock, ErrDocumentLocked
+		}
+		return lock, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return s.GetDocumentLock(ctx, docID)
+}
+
+// ReleaseDocumentLock 释放 userID 持有的文档编辑锁；未持有时不做任何修改。
+func (s *Store) ReleaseDocumentLock(ctx context.Context, docID, userID int64) error {
+	_, err := s.exec(ctx, "DELETE FROM doc_locks WHERE doc_id = ? AND user_id = ?", docID, userID)
+	return err
+}
+
+// DeleteDocumentLock 删除文档的编辑锁，不论持有者是谁，用于管理员强制解锁。
+func (s *Store) DeleteDocumentLock(ctx context.Context, docID int64) error {
+	_, err := s.exec(ctx, "DELETE FROM doc_locks WHERE doc_id = ?", docID)
+	return err
+}
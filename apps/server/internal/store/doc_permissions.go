@@ -0,0 +1,223 @@
+package store
+
+import (
+	"context"
+	"errors"
+	"strconv"
+	"time"
+
+	"github.com/lifei6671/plaindoc/apps/server/internal/auth"
+)
+
+const (
+	PermissionRead  = "read"
+	PermissionWrite = "write"
+)
+
+// ErrPermissionsInherited 表示文档继承父文档权限，不能单独授权。
+var ErrPermissionsInherited = errors.New("document inherits permissions from its parent")
+
+// Viewer 是发起访问的用户，ID 为 0 表示未登录访客。
+type Viewer struct {
+	ID   int64
+	Role string
+}
+
+// DocPermission 是文档上的一条授权，授权对象为某个用户（UserID）或某个全局角色（Role）。
+type DocPermission struct {
+	ID         int64     `json:"id"`
+	DocID      int64     `json:"doc_id"`
+	UserID     *int64    `json:"user_id,omitempty"`
+	Role       string    `json:"role,omitempty"`
+	Permission string    `json:"permission"`
+	GrantedBy  *int64    `json:"granted_by"`
+	CreatedAt  time.Time `json:"created_at"`
+}
+
+const docPermissionColumns = "permission_id, doc_id, user_id, COALESCE(role, ''), permission, granted_by_user_id, created_at"
+
+func scanDocPermission(row scanner) (*DocPermission, error) {
+	perm := &DocPermission{}
+	err := row.Scan(&perm.ID, &perm.DocID, &perm.UserID, &perm.Role, &perm.Permission, &perm.GrantedBy, &perm.CreatedAt)
+	if err != nil {
+		return nil, notFound(err)
+	}
+	return perm, nil
+}
+
+// ACLSource 返回实际决定 doc 权限的文档 id。
+func ACLSource(doc *Document) int64 {
+	if doc.ACLDocID != nil {
+		return *doc.ACLDocID
+	}
+	return doc.ID
+}
+
+func (s *Store) ListDocPermissions(ctx context.Context, docID int64) ([]DocPermission, error) {
+	rows, err := s.query(ctx,
+		"SELECT "+docPermissionColumns+" FROM doc_permissions WHERE doc_id = ? ORDER BY permission_id", docID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	perms := []DocPermission{}
+	for rows.Next() {
+		perm, err := scanDocPermission(rows)
+		if err != nil {
+			return nil, err
+		}
+		perms = append(perms, *perm)
+	}
+	return perms, rows.Err()
+}
+
+// GrantDocPermission 给用户或角色授权；同一对象已有授权时覆盖其权限级别。
+// 继承父文档权限的文档返回 ErrPermissionsInherited。
+func (s *Store) GrantDocPermission(ctx context.Context, doc *Document, perm *DocPermission) error {
+	if doc.ACLDocID != nil {
+		return ErrPermissionsInherited
+	}
+	grantee := "role:" + perm.Role
+	if perm.UserID != nil {
+		grantee = "user:" + strconv.FormatInt(*perm.UserID, 10)
+	}
+	var role *string
+	if perm.Role != "" {
+		role = &perm.Role
+	}
+	perm.DocID = doc.ID
+	perm.CreatedAt = time.Now().UTC()
+
+	return s.WithTx(ctx, func(tx *Store) error {
+		existing, err := scanDocPermission(tx.queryRow(ctx,
+			"SELECT "+docPermissionColumns+" FROM doc_permissions WHERE doc_id = ? AND grantee = ?", doc.ID, grantee))
+		if err != nil && !errors.Is(err, ErrNotFound) {
+			return err
+		}
+		if existing != nil {
+			_, err := tx.exec(ctx, "UPDATE doc_permissions SET permission = ?, granted_by_user_id = ? WHERE permission_id = ?",
+				perm.Permission, perm.GrantedBy, existing.ID)
+			perm.ID, perm.CreatedAt = existing.ID, existing.CreatedAt
+			return err
+		}
+		id, err := tx.insert(ctx, "permission_id",
+			"INSERT INTO doc_permissions (doc_id, user_id, role, grantee, permission, granted_by_user_id, created_at) VALUES (?, ?, ?, ?, ?, ?, ?)",
+			perm.DocID, perm.UserID, role, grantee, perm.Permission, perm.GrantedBy, perm.CreatedAt)
+		perm.ID = id
+		return err
+	})
+}
+
+func (s *Store) RevokeDocPermission(ctx context.Context, docID int64, permissionID int64) error {
+	result, err := s.exec(ctx, "DELETE FROM doc_permissions WHERE doc_id = ? AND permission_id = ?", docID, permissionID)
+	if err != nil {
+		return err
+	}
+	return requireAffected(result)
+}
+
+// HasDocPermission 判断 viewer 本人或其角色在 docID 上是否有 permission 授权；write 授权同时包含 read。
+func (s *Store) HasDocPermission(ctx context.Context, docID int64, viewer Viewer, permission string) (bool, error) {
+	if viewer.ID == 0 {
+		return false, nil
+	}
+	var count int
+	err := s.queryRow(ctx,
+		"SELECT COUNT(*) FROM doc_permissions WHERE doc_id = ? AND (user_id = ? OR role = ?) AND permission IN (?, ?)",
+		docID, viewer.ID, viewer.Role, permission, PermissionWrite).Scan(&count)
+	return count > 0, err
+}
+
+// UpdateDocumentACL 修改文档的私有与继承设置，并把新的权限来源同步到继承它的子孙文档。
+func (s *Store) UpdateDocumentACL(ctx context.Context, doc *Document, isPrivate bool, inherit bool) error {
+	return s.WithTx(ctx, func(tx *Store) error {
+		result, err := tx.exec(ctx, "UPDATE docs SET is_private = ?, inherit_permissions = ? WHERE doc_id = ?",
+			isPrivate, inherit, doc.ID)
+		if err != nil {
+			return err
+		}
+		if err := requireAffected(result); err != nil {
+			return err
+		}
+		doc.IsPrivate, doc.InheritPermissions = isPrivate, inherit
+		return tx.refreshACL(ctx, doc)
+	})
+}
+
+// inheritedACL 计算 doc 按当前父节点与继承设置应使用的权限来源，nil 表示使用自身设置。
+func (s *Store) inheritedACL(ctx context.Context, doc *Document) (*int64, error) {
+	if !doc.InheritPermissions || doc.ParentID == nil {
+		return nil, nil
+	}
+	var source int64
+	if err := s.queryRow(ctx, "SELECT COALESCE(acl_doc_id, doc_id) FROM docs WHERE doc_id = ?", *doc.ParentID).Scan(&source); err != nil {
+		return nil, notFound(err)
+	}
+	return &source, nil
+}
+
+// refreshACL 重新计算 doc 的权限来源，并逐层下发给继承权限的子孙；不继承的子树保持各自的设置。
+func (s *Store) refreshACL(ctx context.Context, doc *Document) error {
+	source, err := s.inheritedACL(ctx, doc)
+	if err != nil {
+		return err
+	}
+	if _, err := s.exec(ctx, "UPDATE docs SET acl_doc_id = ? WHERE doc_id = ?", source, doc.ID); err != nil {
+		return err
+	}
+	doc.ACLDocID = source
+	effective := ACLSource(doc)
+
+	parents := []int64{doc.ID}
+	for depth := 0; len(parents) > 0; depth++ {
+		if depth > maxTreeDepth {
+			return ErrCycle
+		}
+		var children []int64
+		for _, parentID := range parents {
+			if _, err := s.exec(ctx, "UPDATE docs SET acl_doc_id = ? WHERE parent_id = ? AND inherit_permissions = ?",
+				effective, parentID, true); err != nil {
+				return err
+			}
+			ids, err := s.inheritingChildren(ctx, parentID)
+			if err != nil {
+				return err
+			}
+			children = append(children, ids...)
+		}
+		parents = children
+	}
+	return nil
+}
+
+func (s *Store) inheritingChildren(ctx context.Context, parentID int64) ([]int64, error) {
+	rows, err := s.query(ctx, "SELECT doc_id FROM docs WHERE parent_id = ? AND inherit_permissions = ?", parentID, true)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var ids []int64
+	for rows.Next() {
+		var id int64
+		if err := rows.Scan(&id); err != nil {
+			return nil, err
+		}
+		ids = append(ids, id)
+	}
+	return ids, rows.Err()
+}
+
+// visibleCondition 返回限定 viewer 可读文档的 WHERE 条件，管理员不受限制时返回空串。
+// 可读 = 所在空间可访问，且权限来源不是私有文档，或自己是作者，或本人/所属角色在权限来源上有授权；与 acl.Policy 的判断保持一致。
+func visibleCondition(viewer Viewer) (string, []any) {
+	if viewer.Role == auth.RoleAdmin {
+		return "", nil
+	}
+	space, args := spaceCondition(viewer)
+	return space + " AND (COALESCE(acl_doc_id, doc_id) IN (SELECT doc_id FROM docs WHERE is_private = ?)" +
+			" OR author_id = ?" +
+			" OR COALESCE(acl_doc_id, doc_id) IN (SELECT doc_id FROM doc_permissions WHERE user_id = ? OR role = ?))",
+		append(args, false, viewer.ID, viewer.ID, viewer.Role)
+}
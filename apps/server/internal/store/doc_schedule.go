@@ -0,0 +1,84 @@
+package store
+
+import (
+	"context"
+	"errors"
+	"time"
+)
+
+// ScheduledPublishSummary 是定时发布草稿时生成的版本说明。
+const ScheduledPublishSummary = "scheduled publish"
+
+// ScheduleDocument 设置文档的定时发布时间（UTC），at 为 nil 时取消定时发布。
+func (s *Store) ScheduleDocument(ctx context.Context, doc *Document, at *time.Time) error {
+	if at != nil {
+		utc := at.UTC()
+		at = &utc
+	}
+	now := time.Now().UTC()
+	result, err := s.exec(ctx, "UPDATE docs SET publish_at = ?, updated_at = ? WHERE doc_id = ? AND deleted_at IS NULL", at, now, doc.ID)
+	if err != nil {
+		return err
+	}
+	if err := requireAffected(result); err != nil {
+		return err
+	}
+	doc.PublishAt, doc.UpdatedAt = at, now
+	return nil
+}
+
+// ListDueScheduledDocuments 返回定时发布时间不晚于 now、不在回收站中的文档，最早到期的在前。
+func (s *Store) ListDueScheduledDocuments(ctx context.Context, now time.Time, limit int) ([]Document, error) {
+	rows, err := s.query(ctx,
+		"SELECT "+documentColumns+" FROM docs WHERE publish_at IS NOT NULL AND publish_at <= ? AND deleted_at IS NULL ORDER BY publish_at, doc_id LIMIT ?",
+		now, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	docs := []Document{}
+	for rows.Next() {
+		doc, err := scanDocument(rows)
+		if err != nil {
+			return nil, err
+		}
+		docs = append(docs, *doc)
+	}
+	return docs, rows.Err()
+}
+
+// PublishScheduledDocument 发布一篇到期的定时发布文档，doc 更新为发布后的内容。
+// 先以清空 publish_at 认领，多个实例同时扫描时只有一个会发布；已被手动发布、取消或改期时返回 false。
+// 有草稿时以草稿的最后编辑者作为新版本的编辑者。
+func (s *Store) PublishScheduledDocument(ctx context.Context, doc *Document, now time.Time) (bool, error) {
+	published := false
+	err := s.WithTx(ctx, func(tx *Store) error {
+		result, err := tx.exec(ctx, "UPDATE docs SET publish_at = NULL WHERE doc_id = ? AND publish_at <= ? AND deleted_at IS NULL", doc.ID, now)
+		if err != nil {
+			return err
+		}
+		if err := requireAffected(result); errors.Is(err, ErrNotFound) {
+			return nil
+		} else if err != nil {
+			return err
+		}
+
+		current, err := tx.GetDocument(ctx, doc.ID)
+		if err != nil {
+			return err
+		}
+		*doc = *current
+		editorID := doc.AuthorID
+		draft, err := tx.GetDocumentDraft(ctx, doc.ID)
+		switch {
+		case err == nil:
+			editorID = draft.EditorID
+		case !errors.Is(err, ErrNotFound):
+			return err
+		}
+		published, err = tx.publishDocument(ctx, doc, editorID, ScheduledPublishSummary)
+		return err
+	})
+	return published, err
+}
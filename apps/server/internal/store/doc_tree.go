@@ -0,0 +1,193 @@
+package store
+
+import (
+	"context"
+	"errors"
+)
+
+// sortOrderGap 是同级节点 sort_order 的默认间隔，移动时在相邻两项之间取中值。
+const sortOrderGap = 1024
+
+// maxTreeDepth 防止数据异常成环时祖先遍历陷入死循环。
+const maxTreeDepth = 1000
+
+var ErrCycle = errors.New("document cannot be moved under itself or its descendants")
+
+// ListDocumentTree 返回空间内 viewer 可读且状态属于 statuses 的全部文档（不含正文），按同级顺序排列，供一次性构建目录树。
+func (s *Store) ListDocumentTree(ctx context.Context, space string, viewer Viewer, statuses []string) ([]Document, error) {
+	where, args := "space = ? AND deleted_at IS NULL", []any{space}
+	if condition, conditionArgs := visibleCondition(viewer); condition != "" {
+		where += " AND " + condition
+		args = append(args, conditionArgs...)
+	}
+	condition, conditionArgs := statusCondition(viewer, statuses...)
+	where += " AND " + condition
+	args = append(args, conditionArgs...)
+	rows, err := s.query(ctx,
+		"SELECT "+documentSummaryColumns+" FROM docs WHERE "+where+" ORDER BY sort_order, doc_id", args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	docs := []Document{}
+	for rows.Next() {
+		doc, err := scanDocument(rows)
+		if err != nil {
+			return nil, err
+		}
+		docs = append(docs, *doc)
+	}
+	return docs, rows.Err()
+}
+
+// HasChildren 判断文档下是否还有未删除的子文档。
+func (s *Store) HasChildren(ctx context.Context, id int64) (bool, error) {
+	var count int
+	if err := s.queryRow(ctx, "SELECT COUNT(*) FROM docs WHERE parent_id = ? AND deleted_at IS NULL", id).Scan(&count); err != nil {
+		return false, err
+	}
+	return count > 0, nil
+}
+
+// ListAncestors 用一条递归查询返回文档的全部祖先（不含正文），从根节点到直接父节点依次排列；顶层文档返回空切片。
+func (s *Store) ListAncestors(ctx context.Context, id int64) ([]Document, error) {
+	rows, err := s.query(ctx, `WITH RECURSIVE ancestors (ancestor_id, depth) AS (
+  SELECT parent_id, 1 FROM docs WHERE doc_id = ?
+  UNION ALL
+  SELECT docs.parent_id, ancestors.depth + 1 FROM docs JOIN ancestors ON docs.doc_id = ancestors.ancestor_id
+  WHERE docs.parent_id IS NOT NULL AND ancestors.depth < ?
+)
+SELECT `+documentSummaryColumns+` FROM ancestors JOIN docs ON docs.doc_id = ancestors.ancestor_id ORDER BY ancestors.depth DESC`,
+		id, maxTreeDepth)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	docs := []Document{}
+	for rows.Next() {
+		doc, err := scanDocument(rows)
+		if err != nil {
+			return nil, err
+		}
+		docs = append(docs, *doc)
+	}
+	return docs, rows.Err()
+}
+
+// MoveDocument 把文档移动到 parentID 下（nil 表示顶层），position 是移动后在同级中的下标，越界时放到末尾。
+// 新父节点为自身或其子孙时返回 ErrCycle。
+func (s *Store) MoveDocument(ctx context.Context, doc *Document, parentID *int64, position int) error {
+	return s.WithTx(ctx, func(tx *Store) error {
+		if err := tx.checkAncestors(ctx, doc.ID, parentID); err != nil {
+			return err
+		}
+
+		siblings, err := tx.siblingOrders(ctx, doc.Space, parentID, doc.ID)
+		if err != nil {
+			return err
+		}
+		order, ok := orderAt(siblings, position)
+		if !ok {
+			// 相邻两项之间已没有空位，按默认间隔重排同级节点后再插入。
+			for i, sibling := range siblings {
+				siblings[i].order = int64(i+1) * sortOrderGap
+				if _, err := tx.exec(ctx, "UPDATE docs SET sort_order = ? WHERE doc_id = ?", siblings[i].order, sibling.id); err != nil {
+					return err
+				}
+			}
+			order, _ = orderAt(siblings, position)
+		}
+
+		result, err := tx.exec(ctx, "UPDATE docs SET parent_id = ?, sort_order = ? WHERE doc_id = ?", parentID, order, doc.ID)
+		if err != nil {
+			return err
+		}
+		if err := requireAffected(result); err != nil {
+			return err
+		}
+		doc.ParentID, doc.SortOrder = parentID, order
+		// 换了父节点后，继承权限的文档及其子孙要改用新祖先的权限设置。
+		return tx.refreshACL(ctx, doc)
+	})
+}
+
+// checkAncestors 沿 parentID 向上遍历到根，途中遇到 id 说明移动会成环。
+func (s *Store) checkAncestors(ctx context.Context, id int64, parentID *int64) error {
+	for depth := 0; parentID != nil; depth++ {
+		if *parentID == id || depth > maxTreeDepth {
+			return ErrCycle
+		}
+		var next *int64
+		if err := s.queryRow(ctx, "SELECT parent_id FROM docs WHERE doc_id = ?", *parentID).Scan(&next); err != nil {
+			return notFound(err)
+		}
+		parentID = next
+	}
+	return nil
+}
+
+type siblingOrder struct {
+	id    int64
+	order int64
+}
+
+func (s *Store) siblingOrders(ctx context.Context, space string, parentID *int64, excludeID int64) ([]siblingOrder, error) {
+	query := "SELECT doc_id, sort_order FROM docs WHERE space = ? AND parent_id IS NULL AND doc_id <> ? AND deleted_at IS NULL ORDER BY sort_order, doc_id"
+	args := []any{space, excludeID}
+	if parentID != nil {
+		query = "SELECT doc_id, sort_order FROM docs WHERE space = ? AND parent_id = ? AND doc_id <> ? AND deleted_at IS NULL ORDER BY sort_order, doc_id"
+		args = []any{space, *parentID, excludeID}
+	}
+	rows, err := s.query(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var siblings []siblingOrder
+	for rows.Next() {
+		var sibling siblingOrder
+		if err := rows.Scan(&sibling.id, &sibling.order); err != nil {
+			return nil, err
+		}
+		siblings = append(siblings, sibling)
+	}
+	return siblings, rows.Err()
+}
+
+// orderAt 计算插入到 siblings[position] 之前所需的 sort_order；与前后相邻项之间没有空位时返回 false。
+func orderAt(siblings []siblingOrder, position int) (int64, bool) {
+	if position < 0 || position > len(siblings) {
+		position = len(siblings)
+	}
+	switch {
+	case len(siblings) == 0:
+		return sortOrderGap, true
+	case position == 0:
+		return siblings[0].order - sortOrderGap, true
+	case position == len(siblings):
+		return siblings[len(siblings)-1].order + sortOrderGap, true
+	}
+	prev, next := siblings[position-1].order, siblings[position].order
+	if next-prev < 2 {
+		return 0, false
+	}
+	return prev + (next-prev)/2, true
+}
+
+// nextSortOrder 返回排在同级末尾所需的 sort_order。
+func (s *Store) nextSortOrder(ctx context.Context, space string, parentID *int64) (int64, error) {
+	query := "SELECT COALESCE(MAX(sort_order), 0) FROM docs WHERE space = ? AND parent_id IS NULL AND deleted_at IS NULL"
+	args := []any{space}
+	if parentID != nil {
+		query = "SELECT COALESCE(MAX(sort_order), 0) FROM docs WHERE space = ? AND parent_id = ? AND deleted_at IS NULL"
+		args = append(args, *parentID)
+	}
+	var order int64
+	if err := s.queryRow(ctx, query, args...).Scan(&order); err != nil {
+		return 0, err
+	}
+	return order + sortOrderGap, nil
+}
@@ -0,0 +1,67 @@
+package store
+
+import (
+	"context"
+	"time"
+)
+
+// DocumentVersion 是文档某次保存后的全量快照。
+type DocumentVersion struct {
+	ID        int64     `json:"-"`
+	DocID     int64     `json:"doc_id"`
+	Version   int       `json:"version"`
+	Title     string    `json:"title"`
+	Content   string    `json:"content,omitempty"`
+	EditorID  int64     `json:"editor_id"`
+	Summary   string    `json:"summary"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+func scanDocumentVersion(row scanner) (*DocumentVersion, error) {
+	version := &DocumentVersion{}
+	err := row.Scan(&version.ID, &version.DocID, &version.Version, &version.Title, &version.Content,
+		&version.EditorID, &version.Summary, &version.CreatedAt)
+	if err != nil {
+		return nil, notFound(err)
+	}
+	return version, nil
+}
+
+func (s *Store) createVersion(ctx context.Context, doc *Document, editorID int64, summary string) error {
+	_, err := s.exec(ctx,
+		"INSERT INTO doc_versions (doc_id, version, title, content, editor_id, summary, created_at) VALUES (?, ?, ?, ?, ?, ?, ?)",
+		doc.ID, doc.Version, doc.Title, doc.Content, editorID, summary, doc.UpdatedAt)
+	return err
+}
+
+// ListDocumentVersions 按版本号倒序返回一页历史版本（不含正文）及总数。
+func (s *Store) ListDocumentVersions(ctx context.Context, docID int64, limit int, offset int) ([]DocumentVersion, int, error) {
+	var total int
+	if err := s.queryRow(ctx, "SELECT COUNT(*) FROM doc_versions WHERE doc_id = ?", docID).Scan(&total); err != nil {
+		return nil, 0, err
+	}
+
+	rows, err := s.query(ctx,
+		"SELECT version_id, doc_id, version, title, '' AS content, editor_id, summary, created_at FROM doc_versions WHERE doc_id = ? ORDER BY version DESC LIMIT ? OFFSET ?",
+		docID, limit, offset)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer rows.Close()
+
+	versions := []DocumentVersion{}
+	for rows.Next() {
+		version, err := scanDocumentVersion(rows)
+		if err != nil {
+			return nil, 0, err
+		}
+		versions = append(versions, *version)
+	}
+	return versions, total, rows.Err()
+}
+
+func (s *Store) GetDocumentVersion(ctx context.Context, docID int64, version int) (*DocumentVersion, error) {
+	return scanDocumentVersion(s.queryRow(ctx,
+		"SELECT version_id, doc_id, version, title, content, editor_id, summary, created_at FROM doc_versions WHERE doc_id = ? AND version = ?",
+		docID, version))
+}
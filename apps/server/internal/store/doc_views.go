@@ -0,0 +1,84 @@
+package store
+
+import (
+	"context"
+	"sort"
+	"strings"
+	"time"
+)
+
+// AddDocumentViews 把 day 当天各文档新增的浏览量累加到 doc_views；已彻底删除的文档被忽略。
+func (s *Store) AddDocumentViews(ctx context.Context, day time.Time, views map[int64]int64) error {
+	ids := make([]int64, 0, len(views))
+	for id := range views {
+		ids = append(ids, id)
+	}
+	// 固定加锁顺序，避免多个实例同时写入时死锁。
+	sort.Slice(ids, func(i, j int) bool { return ids[i] < ids[j] })
+
+	return s.WithTx(ctx, func(tx *Store) error {
+		for _, id := range ids {
+			result, err := tx.exec(ctx, "UPDATE doc_views SET views = views + ? WHERE doc_id = ? AND view_date = ?", views[id], id, day)
+			if err != nil {
+				return err
+			}
+			affected, err := result.RowsAffected()
+			if err != nil {
+				return err
+			}
+			if affected > 0 {
+				continue
+			}
+			if _, err := tx.exec(ctx, "INSERT INTO doc_views (doc_id, view_date, views) SELECT doc_id, ?, ? FROM docs WHERE doc_id = ?",
+				day, views[id], id); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// PurgeDocumentViewsBefore 删除 day 之前的每日浏览量，返回删除的行数。
+func (s *Store) PurgeDocumentViewsBefore(ctx context.Context, day time.Time) (int64, error) {
+	result, err := s.exec(ctx, "DELETE FROM doc_views WHERE view_date < ?", day)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+// ListPopularDocuments 按 since 当天起的浏览量倒序返回一页 viewer 可读的已发布文档（不含正文）及总数。
+func (s *Store) ListPopularDocuments(ctx context.Context, viewer Viewer, since time.Time, limit int, offset int) ([]Document, int, error) {
+	// 子查询改名避免与 docs 的 doc_id 重名，visibleCondition 等条件使用的是不带表名的列。
+	from := " FROM docs JOIN (SELECT doc_id AS view_doc_id, SUM(views) AS view_count FROM doc_views WHERE view_date >= ? GROUP BY doc_id) v ON v.view_doc_id = docs.doc_id"
+	conditions, args := []string{"deleted_at IS NULL", "status = ?"}, []any{since, DocStatusPublished}
+	if condition, conditionArgs := visibleCondition(viewer); condition != "" {
+		conditions = append(conditions, condition)
+		args = append(args, conditionArgs...)
+	}
+	where := " WHERE " + strings.Join(conditions, " AND ")
+
+	var total int
+	if err := s.queryRow(ctx, "SELECT COUNT(*)"+from+where, args...).Scan(&total); err != nil {
+		return nil, 0, err
+	}
+	rows, err := s.query(ctx,
+		"SELECT "+documentSummaryColumns+", view_count"+from+where+" ORDER BY view_count DESC, doc_id DESC LIMIT ? OFFSET ?",
+		append(args, limit, offset)...)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer rows.Close()
+
+	docs := []Document{}
+	for rows.Next() {
+		var doc Document
+		var views int64
+		if err := rows.Scan(append(documentFields(&doc), &views)...); err != nil {
+			return nil, 0, err
+		}
+		doc.ViewCount = &views
+		docs = append(docs, doc)
+	}
+	return docs, total, rows.Err()
+}
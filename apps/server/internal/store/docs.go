@@ -0,0 +1,333 @@
+package store
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/lifei6671/plaindoc/apps/server/internal/auth"
+	"github.com/lifei6671/plaindoc/apps/server/internal/slug"
+)
+
+// DefaultSpace 是未指定空间时文档归属的空间。
+const DefaultSpace = "default"
+
+// ErrVersionConflict 表示文档在读取之后已被他人修改，版本号与读取时不一致。
+var ErrVersionConflict = errors.New("document was modified by someone else")
+
+// 文档状态：草稿只有作者与有写权限者可见；归档文档不出现在列表与搜索中，但链接仍可访问。
+const (
+	DocStatusDraft     = "draft"
+	DocStatusPublished = "published"
+	DocStatusArchived  = "archived"
+)
+
+type Document struct {
+	ID        int64  `json:"id"`
+	Space     string `json:"space"`
+	ParentID  *int64 `json:"parent_id"`
+	SortOrder int64  `json:"sort_order"`
+	IsPrivate bool   `json:"is_private"`
+	// InheritPermissions 为 true 时沿用父文档的权限设置（顶层文档即自身设置）。
+	InheritPermissions bool `json:"inherit_permissions"`
+	// ACLDocID 是实际生效的权限来源文档，nil 表示文档自身。
+	ACLDocID  *int64    `json:"-"`
+	Title     string    `json:"title"`
+	Slug      string    `json:"slug"`
+	Content   string    `json:"content"`
+	Version   int       `json:"version"`
+	Status    string    `json:"status"`
+	AuthorID  int64     `json:"author_id"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+	// PublishedAt 是最近一次发布的时间，从未发布过时为 nil。
+	PublishedAt *time.Time `json:"published_at"`
+	// PublishAt 是定时发布时间（UTC），到期后由后台任务发布；没有定时发布时为 nil。
+	PublishAt *time.Time `json:"publish_at"`
+	// DraftUpdatedAt 只在按草稿读取且有未发布的修改时有值，此时 Title 与 Content 为草稿内容。
+	DraftUpdatedAt *time.Time `json:"draft_updated_at,omitempty"`
+	// DeletedAt 与 DeletedBy 只在回收站中的文档上有值。
+	DeletedAt *time.Time `json:"deleted_at,omitempty"`
+	DeletedBy *int64     `json:"deleted_by,omitempty"`
+	// IsFavorited 只在登录用户读取单篇文档时有值；FavoritedAt 只在收藏列表中有值。
+	IsFavorited *bool      `json:"is_favorited,omitempty"`
+	FavoritedAt *time.Time `json:"favorited_at,omitempty"`
+	// ViewCount 只在热门文档列表中有值，为统计周期内的浏览量。
+	ViewCount *int64 `json:"view_count,omitempty"`
+	// Tags 不随文档查询读取，由 ListDocumentTags 按需填充；未填充时为 null。
+	Tags []Tag `json:"tags"`
+}
+
+// DocumentFilter 描述文档列表的过滤、排序与分页条件。
+type DocumentFilter struct {
+	Space   string
+	Keyword string
+	// Tag 为归一化后的标签名，非空时只返回带该标签的文档。
+	Tag string
+	// Status 为空时只返回已发布的文档；草稿只返回 Viewer 自己的（管理员可见全部）。
+	Status string
+	// Viewer 是当前访问者，只返回其有权阅读的文档。
+	Viewer Viewer
+	// Ascending 为 true 时按 updated_at 升序，默认降序（最近更新在前）。
+	Ascending bool
+	// WithContent 为 true 时同时读取正文，默认只读摘要字段。
+	WithContent bool
+	Limit       int
+	Offset      int
+}
+
+const documentColumns = "doc_id, space, parent_id, sort_order, is_private, inherit_permissions, acl_doc_id, title, slug, content, version, status, published_at, publish_at, author_id, created_at, updated_at, deleted_at, deleted_by_user_id"
+
+// documentSummaryColumns 用于列表查询，不读取正文以减少传输量。
+const documentSummaryColumns = "doc_id, space, parent_id, sort_order, is_private, inherit_permissions, acl_doc_id, title, slug, '' AS content, version, status, published_at, publish_at, author_id, created_at, updated_at, deleted_at, deleted_by_user_id"
+
+func scanDocument(row scanner) (*Document, error) {
+	doc := &Document{}
+	if err := row.Scan(documentFields(doc)...); err != nil {
+		return nil, notFound(err)
+	}
+	return doc, nil
+}
+
+// documentFields 返回与 documentColumns 顺序一致的扫描目标。
+func documentFields(doc *Document) []any {
+	return []any{&doc.ID, &doc.Space, &doc.ParentID, &doc.SortOrder, &doc.IsPrivate, &doc.InheritPermissions,
+		&doc.ACLDocID, &doc.Title, &doc.Slug, &doc.Content,
+		&doc.Version, &doc.Status, &doc.PublishedAt, &doc.PublishAt, &doc.AuthorID, &doc.CreatedAt, &doc.UpdatedAt, &doc.DeletedAt, &doc.DeletedBy}
+}
+
+// CreateDocument 写入新文档及其第 1 个版本快照，文档排在同级末尾；未指定状态时直接发布。同时建立正文中的内部链接。
+// 同一空间内 slug 冲突时返回 ErrDuplicate。
+func (s *Store) CreateDocument(ctx context.Context, doc *Document) error {
+	now := time.Now().UTC()
+	if doc.Space == "" {
+		doc.Space = DefaultSpace
+	}
+	if doc.Status == "" {
+		doc.Status = DocStatusPublished
+	}
+	doc.Version = 1
+	doc.CreatedAt, doc.UpdatedAt = now, now
+	if doc.Status != DocStatusDraft {
+		doc.PublishedAt = &now
+	}
+
+	return s.WithTx(ctx, func(tx *Store) error {
+		order, err := tx.nextSortOrder(ctx, doc.Space, doc.ParentID)
+		if err != nil {
+			return err
+		}
+		doc.SortOrder = order
+		if doc.ACLDocID, err = tx.inheritedACL(ctx, doc); err != nil {
+			return err
+		}
+
+		id, err := tx.insert(ctx, "doc_id",
+			"INSERT INTO docs (space, parent_id, sort_order, is_private, inherit_permissions, acl_doc_id, title, slug, content, version, status, published_at, author_id, created_at, updated_at) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)",
+			doc.Space, doc.ParentID, doc.SortOrder, doc.IsPrivate, doc.InheritPermissions, doc.ACLDocID, doc.Title, doc.Slug, doc.Content, doc.Version, doc.Status, doc.PublishedAt, doc.AuthorID, doc.CreatedAt, doc.UpdatedAt)
+		if err != nil {
+			return err
+		}
+		doc.ID = id
+		if err := tx.syncDocumentLinks(ctx, doc); err != nil {
+			return err
+		}
+		return tx.createVersion(ctx, doc, doc.AuthorID, "created")
+	})
+}
+
+func (s *Store) GetDocument(ctx context.Context, id int64) (*Document, error) {
+	return scanDocument(s.queryRow(ctx, "SELECT "+documentColumns+" FROM docs WHERE doc_id = ? AND deleted_at IS NULL", id))
+}
+
+func (s *Store) GetDocumentBySlug(ctx context.Context, space string, slug string) (*Document, error) {
+	return scanDocument(s.queryRow(ctx, "SELECT "+documentColumns+" FROM docs WHERE space = ? AND slug = ? AND deleted_at IS NULL", space, slug))
+}
+
+// FreeSlug 返回 space 内未被占用的 slug：base 可用时原样返回，否则依次尝试 base-2、base-3……
+func (s *Store) FreeSlug(ctx context.Context, space string, base string) (string, error) {
+	candidate := base
+	for i := 2; ; i++ {
+		_, err := s.GetDocumentBySlug(ctx, space, candidate)
+		if errors.Is(err, ErrNotFound) {
+			return candidate, nil
+		}
+		if err != nil {
+			return "", err
+		}
+		candidate = slug.WithSuffix(base, i)
+	}
+}
+
+// UpdateDocument 覆盖文档的标题、slug 与正文，版本号加 1 并保存一份快照，同时重建内部链接；slug 冲突时返回 ErrDuplicate。
+// 以 doc.Version 做比较并交换：读取之后文档已被修改（版本号不同）时不做任何修改，返回 ErrVersionConflict。
+func (s *Store) UpdateDocument(ctx context.Context, doc *Document, editorID int64, summary string) error {
+	updatedAt := time.Now().UTC()
+	return s.WithTx(ctx, func(tx *Store) error {
+		result, err := tx.exec(ctx,
+			"UPDATE docs SET title = ?, slug = ?, content = ?, version = version + 1, updated_at = ? WHERE doc_id = ? AND version = ? AND deleted_at IS NULL",
+			doc.Title, doc.Slug, doc.Content, updatedAt, doc.ID, doc.Version)
+		if err != nil {
+			return err
+		}
+		if err := requireAffected(result); errors.Is(err, ErrNotFound) {
+			if _, err := tx.GetDocument(ctx, doc.ID); err != nil {
+				return err
+			}
+			return ErrVersionConflict
+		} else if err != nil {
+			return err
+		}
+		doc.Version++
+		doc.UpdatedAt = updatedAt
+		if err := tx.syncDocumentLinks(ctx, doc); err != nil {
+			return err
+		}
+		return tx.createVersion(ctx, doc, editorID, summary)
+	})
+}
+
+// ListDocuments 返回符合条件的一页文档（不含正文与回收站中的文档）及总数；关键字对标题与正文做不区分大小写的模糊匹配。
+func (s *Store) ListDocuments(ctx context.Context, filter DocumentFilter) ([]Document, int, error) {
+	var (
+		conditions = []string{"deleted_at IS NULL"}
+		args       []any
+	)
+	if filter.Space != "" {
+		conditions = append(conditions, "space = ?")
+		args = append(args, filter.Space)
+	}
+	if condition, conditionArgs := visibleCondition(filter.Viewer); condition != "" {
+		conditions = append(conditions, condition)
+		args = append(args, conditionArgs...)
+	}
+	status := filter.Status
+	if status == "" {
+		status = DocStatusPublished
+	}
+	condition, conditionArgs := statusCondition(filter.Viewer, status)
+	conditions = append(conditions, condition)
+	args = append(args, conditionArgs...)
+	if filter.Keyword != "" {
+		pattern := likePattern(filter.Keyword)
+		conditions = append(conditions, "(LOWER(title) LIKE ? ESCAPE '!' OR LOWER(content) LIKE ? ESCAPE '!')")
+		args = append(args, pattern, pattern)
+	}
+	if filter.Tag != "" {
+		conditions = append(conditions, "doc_id IN (SELECT dt.doc_id FROM doc_tags dt JOIN tags t ON t.tag_id = dt.tag_id WHERE t.name = ?)")
+		args = append(args, filter.Tag)
+	}
+
+	where := " WHERE " + strings.Join(conditions, " AND ")
+
+	var total int
+	if err := s.queryRow(ctx, "SELECT COUNT(*) FROM docs"+where, args...).Scan(&total); err != nil {
+		return nil, 0, err
+	}
+
+	order := " ORDER BY updated_at DESC, doc_id DESC"
+	if filter.Ascending {
+		order = " ORDER BY updated_at ASC, doc_id ASC"
+	}
+	columns := documentSummaryColumns
+	if filter.WithContent {
+		columns = documentColumns
+	}
+	rows, err := s.query(ctx, "SELECT "+columns+" FROM docs"+where+order+" LIMIT ? OFFSET ?",
+		append(args, filter.Limit, filter.Offset)...)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer rows.Close()
+
+	docs := []Document{}
+	for rows.Next() {
+		doc, err := scanDocument(rows)
+		if err != nil {
+			return nil, 0, err
+		}
+		docs = append(docs, *doc)
+	}
+	return docs, total, rows.Err()
+}
+
+// ListSpaceDocumentsAfter 按 doc_id 升序返回空间内 doc_id 大于 afterID、viewer 可读且状态属于 statuses 的一批完整文档，
+// 用于分批遍历整个空间。
+func (s *Store) ListSpaceDocumentsAfter(ctx context.Context, space string, viewer Viewer, statuses []string, afterID int64, limit int) ([]Document, error) {
+	where, args := "space = ? AND doc_id > ? AND deleted_at IS NULL", []any{space, afterID}
+	if condition, conditionArgs := visibleCondition(viewer); condition != "" {
+		where += " AND " + condition
+		args = append(args, conditionArgs...)
+	}
+	condition, conditionArgs := statusCondition(viewer, statuses...)
+	where += " AND " + condition
+	args = append(args, conditionArgs...)
+	rows, err := s.query(ctx,
+		"SELECT "+documentColumns+" FROM docs WHERE "+where+" ORDER BY doc_id LIMIT ?", append(args, limit)...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	docs := []Document{}
+	for rows.Next() {
+		doc, err := scanDocument(rows)
+		if err != nil {
+			return nil, err
+		}
+		docs = append(docs, *doc)
+	}
+	return docs, rows.Err()
+}
+
+// ListPublicDocumentsAfter 按 doc_id 升序返回 doc_id 大于 afterID、匿名访客可读的已发布文档（不含正文），
+// 用于分批生成 sitemap。
+func (s *Store) ListPublicDocumentsAfter(ctx context.Context, afterID int64, limit int) ([]Document, error) {
+	anonymous := Viewer{}
+	where, args := "doc_id > ? AND deleted_at IS NULL", []any{afterID}
+	condition, conditionArgs := visibleCondition(anonymous)
+	where += " AND " + condition
+	args = append(args, conditionArgs...)
+	condition, conditionArgs = statusCondition(anonymous, DocStatusPublished)
+	where += " AND " + condition
+	args = append(args, conditionArgs...)
+	rows, err := s.query(ctx,
+		"SELECT "+documentSummaryColumns+" FROM docs WHERE "+where+" ORDER BY doc_id LIMIT ?", append(args, limit)...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	docs := []Document{}
+	for rows.Next() {
+		doc, err := scanDocument(rows)
+		if err != nil {
+			return nil, err
+		}
+		docs = append(docs, *doc)
+	}
+	return docs, rows.Err()
+}
+
+// statusCondition 返回限定文档状态属于 statuses 的 WHERE 条件；非管理员只能看到自己的草稿。
+func statusCondition(viewer Viewer, statuses ...string) (string, []any) {
+	parts := make([]string, 0, len(statuses))
+	args := make([]any, 0, len(statuses)*2)
+	for _, status := range statuses {
+		if status == DocStatusDraft && viewer.Role != auth.RoleAdmin {
+			parts = append(parts, "(status = ? AND author_id = ?)")
+			args = append(args, status, viewer.ID)
+			continue
+		}
+		parts = append(parts, "status = ?")
+		args = append(args, status)
+	}
+	return "(" + strings.Join(parts, " OR ") + ")", args
+}
+
+// likePattern 构造小写的包含匹配模式，以 ! 作为转义符（各数据库的默认转义符不一致）。
+func likePattern(keyword string) string {
+	replacer := strings.NewReplacer("!", "!!", "%", "!%", "_", "!_")
+	return "%" + replacer.Replace(strings.ToLower(keyword)) + "%"
+}
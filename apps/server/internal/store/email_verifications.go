@@ -0,0 +1,57 @@
+package store
+
+import (
+	"context"
+	"time"
+)
+
+// CreateEmailVerificationToken 为用户写入新的验证 token 摘要并替换之前未使用的 token。
+// 距上一次生成不足 interval 时不写入并返回 false，用于限制重发频率。
+func (s *Store) CreateEmailVerificationToken(ctx context.Context, userID int64, tokenHash string, expiresAt time.Time, interval time.Duration) (bool, error) {
+	created := false
+	err := s.WithTx(ctx, func(tx *Store) error {
+		now := time.Now().UTC()
+		var recent int
+		err := tx.queryRow(ctx, "SELECT COUNT(*) FROM email_verification_tokens WHERE user_id = ? AND created_at > ?",
+			userID, now.Add(-interval)).Scan(&recent)
+		if err != nil || recent > 0 {
+			return err
+		}
+		if _, err := tx.exec(ctx, "DELETE FROM email_verification_tokens WHERE user_id = ?", userID); err != nil {
+			return err
+		}
+		_, err = tx.insert(ctx, "token_id",
+			"INSERT INTO email_verification_tokens (user_id, token_hash, expires_at, created_at) VALUES (?, ?, ?, ?)",
+			userID, tokenHash, expiresAt.UTC(), now)
+		created = err == nil
+		return err
+	})
+	return created, err
+}
+
+// VerifyEmail 用未过期的验证 token 标记邮箱已验证并删除该用户的全部验证 token，返回用户 ID。
+// token 不存在、已使用或已过期时返回 ErrNotFound。
+func (s *Store) VerifyEmail(ctx context.Context, tokenHash string) (int64, error) {
+	var userID int64
+	err := s.WithTx(ctx, func(tx *Store) error {
+		now := time.Now().UTC()
+		err := tx.queryRow(ctx, "SELECT user_id FROM email_verification_tokens WHERE token_hash = ? AND expires_at > ?",
+			tokenHash, now).Scan(&userID)
+		if err != nil {
+			return notFound(err)
+		}
+		if _, err := tx.exec(ctx, "DELETE FROM email_verification_tokens WHERE user_id = ?", userID); err != nil {
+			return err
+		}
+		return tx.MarkEmailVerified(ctx, userID)
+	})
+	return userID, err
+}
+
+// MarkEmailVerified 记录用户邮箱的验证时间，已验证的用户保持原来的时间。
+func (s *Store) MarkEmailVerified(ctx context.Context, userID int64) error {
+	now := time.Now().UTC()
+	_, err := s.exec(ctx, "UPDATE users SET email_verified_at = ?, updated_at = ? WHERE user_id = ? AND email_verified_at IS NULL",
+		now, now, userID)
+	return err
+}
@@ -0,0 +1,70 @@
+package store
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"time"
+)
+
+// AddFavorite 收藏文档，已收藏时不做修改。
+func (s *Store) AddFavorite(ctx context.Context, userID int64, docID int64) error {
+	_, err := s.exec(ctx, "INSERT INTO user_favorites (user_id, doc_id, created_at) VALUES (?, ?, ?)",
+		userID, docID, time.Now().UTC())
+	if errors.Is(err, ErrDuplicate) {
+		return nil
+	}
+	return err
+}
+
+// RemoveFavorite 取消收藏，未收藏时不报错。
+func (s *Store) RemoveFavorite(ctx context.Context, userID int64, docID int64) error {
+	_, err := s.exec(ctx, "DELETE FROM user_favorites WHERE user_id = ? AND doc_id = ?", userID, docID)
+	return err
+}
+
+func (s *Store) IsFavorited(ctx context.Context, userID int64, docID int64) (bool, error) {
+	var count int
+	err := s.queryRow(ctx, "SELECT COUNT(*) FROM user_favorites WHERE user_id = ? AND doc_id = ?", userID, docID).Scan(&count)
+	return count > 0, err
+}
+
+// ListFavorites 按收藏时间倒序返回 viewer 收藏的一页文档（不含正文）及总数；
+// 回收站中的文档与已无权阅读的文档不出现在列表中，收藏记录仍保留。
+func (s *Store) ListFavorites(ctx context.Context, viewer Viewer, limit int, offset int) ([]Document, int, error) {
+	// 子查询改名避免与 docs 的 doc_id、created_at 重名，visibleCondition 等条件使用的是不带表名的列。
+	from := " FROM docs JOIN (SELECT doc_id AS fav_doc_id, created_at AS favorited_at FROM user_favorites WHERE user_id = ?) f ON f.fav_doc_id = docs.doc_id"
+	conditions, args := []string{"deleted_at IS NULL"}, []any{viewer.ID}
+	if condition, conditionArgs := visibleCondition(viewer); condition != "" {
+		conditions = append(conditions, condition)
+		args = append(args, conditionArgs...)
+	}
+	condition, conditionArgs := statusCondition(viewer, DocStatusPublished, DocStatusArchived, DocStatusDraft)
+	conditions = append(conditions, condition)
+	args = append(args, conditionArgs...)
+	where := " WHERE " + strings.Join(conditions, " AND ")
+
+	var total int
+	if err := s.queryRow(ctx, "SELECT COUNT(*)"+from+where, args...).Scan(&total); err != nil {
+		return nil, 0, err
+	}
+	rows, err := s.query(ctx,
+		"SELECT "+documentSummaryColumns+", favorited_at"+from+where+" ORDER BY favorited_at DESC, doc_id DESC LIMIT ? OFFSET ?",
+		append(args, limit, offset)...)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer rows.Close()
+
+	docs := []Document{}
+	for rows.Next() {
+		var doc Document
+		var favoritedAt time.Time
+		if err := rows.Scan(append(documentFields(&doc), &favoritedAt)...); err != nil {
+			return nil, 0, err
+		}
+		doc.FavoritedAt = &favoritedAt
+		docs = append(docs, doc)
+	}
+	return docs, total, rows.Err()
+}
@@ -0,0 +1,210 @@
+package store
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"time"
+)
+
+// 异步作业的状态。
+const (
+	JobPending   = "pending"
+	JobRunning   = "running"
+	JobSucceeded = "succeeded"
+	JobFailed    = "failed"
+)
+
+// Job 是一个异步作业，Params 与 Result 是 kind 对应的执行逻辑约定的 JSON。
+type Job struct {
+	ID         int64           `json:"id"`
+	Kind       string          `json:"kind"`
+	Status     string          `json:"status"`
+	Progress   int             `json:"progress"`
+	Params     json.RawMessage `json:"params"`
+	Result     json.RawMessage `json:"result"`
+	Error      string          `json:"error"`
+	CreatedBy  *int64          `json:"created_by"`
+	CreatedAt  time.Time       `json:"created_at"`
+	StartedAt  *time.Time      `json:"started_at"`
+	FinishedAt *time.Time      `json:"finished_at"`
+	UpdatedAt  time.Time       `json:"updated_at"`
+}
+
+// Finished 判断作业是否已结束（成功或失败）。
+func (j *Job) Finished() bool {
+	return j.Status == JobSucceeded || j.Status == JobFailed
+}
+
+const jobColumns = "job_id, kind, status, progress, params, result, error, created_by_user_id, created_at, started_at, finished_at, updated_at"
+
+func scanJob(row scanner) (*Job, error) {
+	job := &Job{}
+	var (
+		params string
+		result sql.NullString
+	)
+	err := row.Scan(&job.ID, &job.Kind, &job.Status, &job.Progress, &params, &result, &job.Error,
+		&job.CreatedBy, &job.CreatedAt, &job.StartedAt, &job.FinishedAt, &job.UpdatedAt)
+	if err != nil {
+		return nil, notFound(err)
+	}
+	job.Params = json.RawMessage(params)
+	if result.Valid {
+		job.Result = json.RawMessage(result.String)
+	}
+	return job, nil
+}
+
+// CreateJob 写入一个待执行的作业。
+func (s *Store) CreateJob(ctx context.Context, job *Job) error {
+	now := time.Now().UTC()
+	job.Status, job.Progress = JobPending, 0
+	job.CreatedAt, job.UpdatedAt = now, now
+	id, err := s.insert(ctx, "job_id",
+		"INSERT INTO jobs (kind, status, progress, params, created_by_user_id, created_at, updated_at) VALUES (?, ?, ?, ?, ?, ?, ?)",
+		job.Kind, job.Status, job.Progress, string(job.Params), job.CreatedBy, job.CreatedAt, job.UpdatedAt)
+	if err != nil {
+		return err
+	}
+	job.ID = id
+	return nil
+}
+
+func (s *Store) GetJob(ctx context.Context, id int64) (*Job, error) {
+	return scanJob(s.queryRow(ctx, "SELECT "+jobColumns+" FROM jobs WHERE job_id = ?", id))
+}
+
+// ListJobs 按创建时间倒序返回 userID 提交的一页作业及总数。
+func (s *Store) ListJobs(ctx context.Context, userID int64, limit int, offset int) ([]Job, int, error) {
+	var total int
+	if err := s.queryRow(ctx, "SELECT COUNT(*) FROM jobs WHERE created_by_user_id = ?", userID).Scan(&total); err != nil {
+		return nil, 0, err
+	}
+	rows, err := s.query(ctx, "SELECT "+jobColumns+" FROM jobs WHERE created_by_user_id = ? ORDER BY job_id DESC LIMIT ? OFFSET ?",
+		userID, limit, offset)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer rows.Close()
+
+	jobs := []Job{}
+	for rows.Next() {
+		job, err := scanJob(rows)
+		if err != nil {
+			return nil, 0, err
+		}
+		jobs = append(jobs, *job)
+	}
+	return jobs, total, rows.Err()
+}
+
+// CountPendingJobs 返回排队等待执行的作业数。
+func (s *Store) CountPendingJobs(ctx context.Context) (int, error) {
+	var count int
+	err := s.queryRow(ctx, "SELECT COUNT(*) FROM jobs WHERE status = ?", JobPending).Scan(&count)
+	return count, err
+}
+
+// ClaimNextJob 把最早提交的一个待执行作业标记为运行中并返回，没有待执行作业时返回 ErrNotFound。
+// 以 status 作为条件更新，多个 worker（或实例）同时认领时只有一个能成功，失败的一方继续认领下一个。
+func (s *Store) ClaimNextJob(ctx context.Context) (*Job, error) {
+	for {
+		job, err := scanJob(s.queryRow(ctx, "SELECT "+jobColumns+" FROM jobs WHERE status = ? ORDER BY job_id LIMIT 1", JobPending))
+		if err != nil {
+			return nil, err
+		}
+		now := time.Now().UTC()
+		result, err := s.exec(ctx, "UPDATE jobs SET status = ?, started_at = ?, updated_at = ? WHERE job_id = ? AND status = ?",
+			JobRunning, now, now, job.ID, JobPending)
+		if err != nil {
+			return nil, err
+		}
+		affected, err := result.RowsAffected()
+		if err != nil {
+			return nil, err
+		}
+		if affected > 0 {
+			job.Status, job.StartedAt, job.UpdatedAt = JobRunning, &now, now
+			return job, nil
+		}
+	}
+}
+
+// UpdateJobProgress 保存运行中作业的进度（0-100）并刷新 updated_at，updated_at 同时作为作业仍在运行的心跳。
+func (s *Store) UpdateJobProgress(ctx context.Context, id int64, progress int) error {
+	_, err := s.exec(ctx, "UPDATE jobs SET progress = ?, updated_at = ? WHERE job_id = ? AND status = ?",
+		progress, time.Now().UTC(), id, JobRunning)
+	return err
+}
+
+// FinishJob 保存运行中作业的结果：Status 为 succeeded 时进度记为 100，failed 时记录 Error。
+func (s *Store) FinishJob(ctx context.Context, job *Job) error {
+	now := time.Now().UTC()
+	job.FinishedAt, job.UpdatedAt = &now, now
+	if job.Status == JobSucceeded {
+		job.Progress = 100
+	}
+	var result *string
+	if job.Result != nil {
+		value := string(job.Result)
+		result = &value
+	}
+	res, err := s.exec(ctx,
+		"UPDATE jobs SET status = ?, progress = ?, result = ?, error = ?, finished_at = ?, updated_at = ? WHERE job_id = ? AND status = ?",
+		job.Status, job.Progress, result, job.Error, now, now, job.ID, JobRunning)
+	if err != nil {
+		return err
+	}
+	return requireAffected(res)
+}
+
+// FailStaleJobs 把 before 之后没有再刷新心跳的运行中作业标记为失败，用于回收进程退出时被中断的作业，返回处理的条数。
+func (s *Store) FailStaleJobs(ctx context.Context, before time.Time, reason string) (int64, error) {
+	now := time.Now().UTC()
+	result, err := s.exec(ctx, "UPDATE jobs SET status = ?, error = ?, finished_at = ?, updated_at = ? WHERE status = ? AND updated_at < ?",
+		JobFailed, reason, now, now, JobRunning, before)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+// PurgeJobsBefore 删除 before 之前已结束的作业，返回被删除作业的 ID，调用方据此清理作业产生的文件。
+func (s *Store) PurgeJobsBefore(ctx context.Context, before time.Time) ([]int64, error) {
+	rows, err := s.query(ctx, "SELECT job_id FROM jobs WHERE status IN (?, ?) AND finished_at < ?", JobSucceeded, JobFailed, before)
+	if err != nil {
+		return nil, err
+	}
+	var ids []int64
+	for rows.Next() {
+		var id int64
+		if err := rows.Scan(&id); err != nil {
+			rows.Close()
+			return nil, err
+		}
+		ids = append(ids, id)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	purged := make([]int64, 0, len(ids))
+	for _, id := range ids {
+		result, err := s.exec(ctx, "DELETE FROM jobs WHERE job_id = ?", id)
+		if err == nil {
+			err = requireAffected(result)
+		}
+		if errors.Is(err, ErrNotFound) {
+			// 已被其他实例删除。
+			continue
+		}
+		if err != nil {
+			return purged, err
+		}
+		purged = append(purged, id)
+	}
+	return purged, nil
+}
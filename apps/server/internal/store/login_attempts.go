@@ -0,0 +1,75 @@
+package store
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"time"
+)
+
+// LoginAccountKey 与 LoginIPKey 生成 login_attempts 中按账号与按客户端 IP 计数的键。
+func LoginAccountKey(email string) string {
+	return "email:" + strings.ToLower(strings.TrimSpace(email))
+}
+
+func LoginIPKey(ip string) string {
+	return "ip:" + ip
+}
+
+// LoginLockedUntil 返回 key 的锁定截止时间，未锁定或锁定已过期时返回 nil。
+func (s *Store) LoginLockedUntil(ctx context.Context, key string) (*time.Time, error) {
+	var lockedUntil *time.Time
+	err := s.queryRow(ctx, "SELECT locked_until FROM login_attempts WHERE attempt_key = ? AND locked_until > ?",
+		key, time.Now().UTC()).Scan(&lockedUntil)
+	if err = notFound(err); errors.Is(err, ErrNotFound) {
+		return nil, nil
+	}
+	return lockedUntil, err
+}
+
+// RecordLoginFailure 给 key 的连续失败次数加 1，距上一次失败超过 window 时重新计数；
+// 次数达到 maxFailures 时锁定 key 到 window 之后并清零计数，返回 true。
+// 同时清理已过期且不再锁定的记录，避免按 IP 计数的记录无限增长。
+func (s *Store) RecordLoginFailure(ctx context.Context, key string, maxFailures int, window time.Duration) (bool, error) {
+	locked := false
+	err := s.WithTx(ctx, func(tx *Store) error {
+		now := time.Now().UTC()
+		stale := now.Add(-window)
+		if _, err := tx.exec(ctx, "DELETE FROM login_attempts WHERE updated_at < ? AND (locked_until IS NULL OR locked_until < ?)",
+			stale, now); err != nil {
+			return err
+		}
+		result, err := tx.exec(ctx,
+			"UPDATE login_attempts SET failures = CASE WHEN updated_at > ? THEN failures + 1 ELSE 1 END, updated_at = ? WHERE attempt_key = ?",
+			stale, now, key)
+		if err != nil {
+			return err
+		}
+		if err := requireAffected(result); errors.Is(err, ErrNotFound) {
+			_, err = tx.exec(ctx, "INSERT INTO login_attempts (attempt_key, failures, updated_at) VALUES (?, 1, ?)", key, now)
+			if err != nil {
+				return err
+			}
+		} else if err != nil {
+			return err
+		}
+
+		var failures int
+		if err := tx.queryRow(ctx, "SELECT failures FROM login_attempts WHERE attempt_key = ?", key).Scan(&failures); err != nil {
+			return err
+		}
+		if failures < maxFailures {
+			return nil
+		}
+		locked = true
+		_, err = tx.exec(ctx, "UPDATE login_attempts SET failures = 0, locked_until = ? WHERE attempt_key = ?", now.Add(window), key)
+		return err
+	})
+	return locked, err
+}
+
+// ClearLoginAttempts 删除 key 的失败计数与锁定，用于登录成功与管理员手动解锁。
+func (s *Store) ClearLoginAttempts(ctx context.Context, key string) error {
+	_, err := s.exec(ctx, "DELETE FROM login_attempts WHERE attempt_key = ?", key)
+	return err
+}
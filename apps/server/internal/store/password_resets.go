@@ -0,0 +1,56 @@
+package store
+
+import (
+	"context"
+	"time"
+)
+
+// CreatePasswordResetToken 为用户写入新的重置 token 摘要，并删除该用户之前未使用的 token，只有最新一封邮件中的链接有效。
+func (s *Store) CreatePasswordResetToken(ctx context.Context, userID int64, tokenHash string, expiresAt time.Time) error {
+	return s.WithTx(ctx, func(tx *Store) error {
+		if _, err := tx.exec(ctx, "DELETE FROM password_reset_tokens WHERE user_id = ?", userID); err != nil {
+			return err
+		}
+		_, err := tx.insert(ctx, "token_id",
+			"INSERT INTO password_reset_tokens (user_id, token_hash, expires_at, created_at) VALUES (?, ?, ?, ?)",
+			userID, tokenHash, expiresAt.UTC(), time.Now().UTC())
+		return err
+	})
+}
+
+// ResetPassword 用未过期的重置 token 修改密码，同时删除该用户的全部重置 token 并撤销全部会话。
+// token 不存在、已使用或已过期时返回 ErrNotFound。
+func (s *Store) ResetPassword(ctx context.Context, tokenHash string, passwordHash string) error {
+	return s.WithTx(ctx, func(tx *Store) error {
+		now := time.Now().UTC()
+		var userID int64
+		err := tx.queryRow(ctx, "SELECT user_id FROM password_reset_tokens WHERE token_hash = ? AND expires_at > ?",
+			tokenHash, now).Scan(&userID)
+		if err != nil {
+			return notFound(err)
+		}
+		// 按摘要条件删除，并发提交同一个 token 时只有一次成功。
+		result, err := tx.exec(ctx, "DELETE FROM password_reset_tokens WHERE token_hash = ?", tokenHash)
+		if err != nil {
+			return err
+		}
+		if err := requireAffected(result); err != nil {
+			return err
+		}
+		if _, err := tx.exec(ctx, "DELETE FROM password_reset_tokens WHERE user_id = ?", userID); err != nil {
+			return err
+		}
+		result, err = tx.exec(ctx, "UPDATE users SET password_hash = ?, updated_at = ? WHERE user_id = ?", passwordHash, now, userID)
+		if err != nil {
+			return err
+		}
+		if err := requireAffected(result); err != nil {
+			return err
+		}
+		if err := tx.revokeRefreshTokens(ctx, userID, RevokePasswordReset, "user_id = ?", userID); err != nil {
+			return err
+		}
+		_, err = tx.exec(ctx, "DELETE FROM sessions WHERE user_id = ?", userID)
+		return err
+	})
+}
@@ -0,0 +1,152 @@
+package store
+
+import (
+	"context"
+	"errors"
+	"time"
+)
+
+// 作废原因，写入 refresh_token_blacklist.reason。
+const (
+	RevokeRotated = "rotated"
+	RevokeLogout  = "logout"
+	RevokeReuse   = "reuse"
+	// RevokeRoleChanged 表示管理员修改了用户角色，需要重新登录以获得新角色的 token。
+	RevokeRoleChanged = "role_changed"
+	// RevokePasswordReset 表示用户通过忘记密码流程重置了密码。
+	RevokePasswordReset = "password_reset"
+)
+
+var (
+	ErrRefreshTokenExpired = errors.New("refresh token expired")
+	// ErrRefreshTokenReused 表示已轮换的旧令牌被再次使用，可能已被盗用。
+	ErrRefreshTokenReused = errors.New("refresh token reused")
+)
+
+type RefreshToken struct {
+	ID     int64
+	UserID int64
+	// SessionID 为空表示升级前签发、尚未归属会话的令牌。
+	SessionID *int64
+	TokenHash string
+	ExpiresAt time.Time
+	CreatedAt time.Time
+}
+
+func (s *Store) CreateRefreshToken(ctx context.Context, token *RefreshToken) error {
+	token.CreatedAt = time.Now().UTC()
+	id, err := s.insert(ctx, "token_id",
+		"INSERT INTO refresh_tokens (user_id, session_id, token_hash, expires_at, created_at) VALUES (?, ?, ?, ?, ?)",
+		token.UserID, token.SessionID, token.TokenHash, token.ExpiresAt.UTC(), token.CreatedAt)
+	if err != nil {
+		return err
+	}
+	token.ID = id
+	return nil
+}
+
+// ConsumeRefreshToken 校验并作废一个 refresh token，返回其归属信息。
+// 令牌因轮换进入黑名单后又被使用时视为复用攻击，撤销该用户全部令牌并返回 ErrRefreshTokenReused。
+func (s *Store) ConsumeRefreshToken(ctx context.Context, tokenHash string, reason string) (*RefreshToken, error) {
+	var consumed *RefreshToken
+	err := s.WithTx(ctx, func(tx *Store) error {
+		token := &RefreshToken{}
+		err := tx.queryRow(ctx,
+			"SELECT token_id, user_id, session_id, token_hash, expires_at, created_at FROM refresh_tokens WHERE token_hash = ?",
+			tokenHash).Scan(&token.ID, &token.UserID, &token.SessionID, &token.TokenHash, &token.ExpiresAt, &token.CreatedAt)
+		if err != nil {
+			return notFound(err)
+		}
+
+		// 以 DELETE 的影响行数作为并发下的原子“认领”，同一令牌只会成功一次。
+		result, err := tx.exec(ctx, "DELETE FROM refresh_tokens WHERE token_id = ?", token.ID)
+		if err != nil {
+			return err
+		}
+		if affected, err := result.RowsAffected(); err != nil || affected == 0 {
+			return ErrNotFound
+		}
+		if !token.ExpiresAt.After(time.Now()) {
+			return ErrRefreshTokenExpired
+		}
+		if err := tx.blacklist(ctx, token.TokenHash, token.UserID, reason); err != nil {
+			return err
+		}
+		consumed = token
+		return nil
+	})
+	if errors.Is(err, ErrNotFound) {
+		// 复用检测需在独立事务中提交撤销结果，不能随上面的事务一起回滚。
+		return nil, s.detectReuse(ctx, tokenHash)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return consumed, nil
+}
+
+// RevokeUserRefreshTokens 作废用户全部仍有效的 refresh token 并删除全部会话。
+func (s *Store) RevokeUserRefreshTokens(ctx context.Context, userID int64, reason string) error {
+	return s.WithTx(ctx, func(tx *Store) error {
+		if err := tx.revokeRefreshTokens(ctx, userID, reason, "user_id = ?", userID); err != nil {
+			return err
+		}
+		_, err := tx.exec(ctx, "DELETE FROM sessions WHERE user_id = ?", userID)
+		return err
+	})
+}
+
+// revokeRefreshTokens 把 refresh_tokens 中满足 where 条件的令牌移入黑名单并删除，需在事务中调用。
+func (s *Store) revokeRefreshTokens(ctx context.Context, userID int64, reason string, where string, args ...any) error {
+	rows, err := s.query(ctx, "SELECT token_hash FROM refresh_tokens WHERE "+where, args...)
+	if err != nil {
+		return err
+	}
+	var hashes []string
+	for rows.Next() {
+		var hash string
+		if err := rows.Scan(&hash); err != nil {
+			rows.Close()
+			return err
+		}
+		hashes = append(hashes, hash)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return err
+	}
+
+	for _, hash := range hashes {
+		if err := s.blacklist(ctx, hash, userID, reason); err != nil {
+			return err
+		}
+	}
+	_, err = s.exec(ctx, "DELETE FROM refresh_tokens WHERE "+where, args...)
+	return err
+}
+
+// detectReuse 在令牌不在有效表中时调用：命中因轮换作废的令牌返回 ErrRefreshTokenReused，否则返回 ErrNotFound。
+func (s *Store) detectReuse(ctx context.Context, tokenHash string) error {
+	var userID int64
+	var reason string
+	err := notFound(s.queryRow(ctx, "SELECT user_id, reason FROM refresh_token_blacklist WHERE token_hash = ?", tokenHash).Scan(&userID, &reason))
+	if err != nil {
+		return err
+	}
+	// 只有已轮换的旧令牌再次出现才说明令牌被复制；登出、撤销会话、改角色、重置密码等作废的令牌
+	// 仍会被原设备自动刷新，这属于预期内的使用，不能因此撤销用户的其他会话。
+	if reason != RevokeRotated {
+		return ErrNotFound
+	}
+	if err := s.RevokeUserRefreshTokens(ctx, userID, RevokeReuse); err != nil {
+		return err
+	}
+	return ErrRefreshTokenReused
+}
+
+func (s *Store) blacklist(ctx context.Context, tokenHash string, userID int64, reason string) error {
+	_, err := s.exec(ctx,
+		"INSERT INTO refresh_token_blacklist (token_hash, user_id, reason, revoked_at) VALUES (?, ?, ?, ?)",
+		tokenHash, userID, reason, time.Now().UTC())
+	return err
+}
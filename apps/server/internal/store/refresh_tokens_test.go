@@ -0,0 +1,151 @@
+package store
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+func newTestRefreshToken(t *testing.T, s *Store, userID int64, sessionID *int64, hash string, ttl time.Duration) {
+	t.Helper()
+	token := &RefreshToken{UserID: userID, SessionID: sessionID, TokenHash: hash, ExpiresAt: time.Now().Add(ttl)}
+	if err := s.CreateRefreshToken(context.Background(), token); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestConsumeRefreshToken(t *testing.T) {
+	ctx := context.Background()
+	s := newTestStore(t)
+	user := newTestUser(t, s)
+	newTestRefreshToken(t, s, user.ID, nil, "valid", time.Hour)
+	newTestRefreshToken(t, s, user.ID, nil, "expired", -time.Minute)
+
+	tests := []struct {
+		name string
+		hash string
+		want error
+	}{
+		{"valid", "valid", nil},
+		{"unknown", "unknown", ErrNotFound},
+		{"expired", "expired", ErrRefreshTokenExpired},
+		// 过期时事务回滚，令牌不会进入黑名单，再次使用仍返回过期而不会被当作复用。
+		{"expired again", "expired", ErrRefreshTokenExpired},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			token, err := s.ConsumeRefreshToken(ctx, tt.hash, RevokeRotated)
+			if !errors.Is(err, tt.want) {
+				t.Fatalf("ConsumeRefreshToken(%q) error = %v, want %v", tt.hash, err, tt.want)
+			}
+			if tt.want == nil && (token == nil || token.UserID != user.ID) {
+				t.Fatalf("ConsumeRefreshToken(%q) = %+v, want a token of user %d", tt.hash, token, user.ID)
+			}
+		})
+	}
+}
+
+// 只有已轮换的令牌再次出现才视为复用并撤销用户的全部令牌，其他原因作废的令牌只是失效。
+func TestConsumeRefreshTokenReuse(t *testing.T) {
+	ctx := context.Background()
+	tests := []struct {
+		reason string
+		want   error
+		// revokesOthers 表示复用检测是否连带撤销了用户的其他令牌。
+		revokesOthers bool
+	}{
+		{RevokeRotated, ErrRefreshTokenReused, true},
+		{RevokeLogout, ErrNotFound, false},
+		{RevokeSession, ErrNotFound, false},
+		{RevokeRoleChanged, ErrNotFound, false},
+		{RevokePasswordReset, ErrNotFound, false},
+		{RevokeReuse, ErrNotFound, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.reason, func(t *testing.T) {
+			s := newTestStore(t)
+			user := newTestUser(t, s)
+			newTestRefreshToken(t, s, user.ID, nil, "old", time.Hour)
+			newTestRefreshToken(t, s, user.ID, nil, "other", time.Hour)
+
+			if _, err := s.ConsumeRefreshToken(ctx, "old", tt.reason); err != nil {
+				t.Fatalf("first use: %v", err)
+			}
+			if _, err := s.ConsumeRefreshToken(ctx, "old", RevokeRotated); !errors.Is(err, tt.want) {
+				t.Fatalf("second use error = %v, want %v", err, tt.want)
+			}
+
+			_, err := s.ConsumeRefreshToken(ctx, "other", RevokeRotated)
+			switch {
+			case tt.revokesOthers && !errors.Is(err, ErrNotFound):
+				t.Fatalf("other token after reuse: error = %v, want ErrNotFound", err)
+			case !tt.revokesOthers && err != nil:
+				t.Fatalf("other token: error = %v, want it to stay valid", err)
+			}
+		})
+	}
+}
+
+// 轮换链：每个令牌只能换一次，用旧令牌再换就会撤销整条链上仍有效的新令牌。
+func TestRefreshTokenRotationChain(t *testing.T) {
+	ctx := context.Background()
+	s := newTestStore(t)
+	user := newTestUser(t, s)
+	session := &Session{UserID: user.ID}
+	if err := s.CreateSession(ctx, session); err != nil {
+		t.Fatal(err)
+	}
+
+	hashes := []string{"r0", "r1", "r2", "r3"}
+	newTestRefreshToken(t, s, user.ID, &session.ID, hashes[0], time.Hour)
+	for i := 1; i < len(hashes); i++ {
+		token, err := s.ConsumeRefreshToken(ctx, hashes[i-1], RevokeRotated)
+		if err != nil {
+			t.Fatalf("rotate %s: %v", hashes[i-1], err)
+		}
+		if token.SessionID == nil || *token.SessionID != session.ID {
+			t.Fatalf("rotate %s: session = %v, want %d", hashes[i-1], token.SessionID, session.ID)
+		}
+		newTestRefreshToken(t, s, user.ID, token.SessionID, hashes[i], time.Hour)
+	}
+
+	if _, err := s.ConsumeRefreshToken(ctx, hashes[1], RevokeRotated); !errors.Is(err, ErrRefreshTokenReused) {
+		t.Fatalf("reuse of %s: error = %v, want ErrRefreshTokenReused", hashes[1], err)
+	}
+	if _, err := s.ConsumeRefreshToken(ctx, hashes[len(hashes)-1], RevokeRotated); !errors.Is(err, ErrNotFound) {
+		t.Fatalf("latest token after reuse: error = %v, want ErrNotFound", err)
+	}
+	sessions, err := s.ListSessions(ctx, user.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(sessions) != 0 {
+		t.Fatalf("sessions after reuse = %d, want 0", len(sessions))
+	}
+}
+
+// 撤销一个会话后，该设备继续用旧令牌刷新只会失败，不能影响同一用户的其他会话。
+func TestRevokedSessionTokenIsNotReuse(t *testing.T) {
+	ctx := context.Background()
+	s := newTestStore(t)
+	user := newTestUser(t, s)
+	revoked, kept := &Session{UserID: user.ID}, &Session{UserID: user.ID}
+	for _, session := range []*Session{revoked, kept} {
+		if err := s.CreateSession(ctx, session); err != nil {
+			t.Fatal(err)
+		}
+	}
+	newTestRefreshToken(t, s, user.ID, &revoked.ID, "revoked", time.Hour)
+	newTestRefreshToken(t, s, user.ID, &kept.ID, "kept", time.Hour)
+
+	if err := s.RevokeSession(ctx, user.ID, revoked.ID); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := s.ConsumeRefreshToken(ctx, "revoked", RevokeRotated); !errors.Is(err, ErrNotFound) {
+		t.Fatalf("revoked session token: error = %v, want ErrNotFound", err)
+	}
+	if _, err := s.ConsumeRefreshToken(ctx, "kept", RevokeRotated); err != nil {
+		t.Fatalf("other session token: error = %v, want it to stay valid", err)
+	}
+}
@@ -0,0 +1,92 @@
+package store
+
+import (
+	"context"
+	"strings"
+)
+
+// SearchFilter 描述全文检索条件：每个词都必须出现在标题或正文中。
+type SearchFilter struct {
+	Terms []string
+	// Space 非空时只检索该空间内的文档。
+	Space string
+	// Viewer 是当前访问者，只返回其有权阅读的文档。
+	Viewer Viewer
+	// ByRelevance 为 true 时按命中得分排序，否则按 updated_at 排序。
+	ByRelevance bool
+	Ascending   bool
+	Limit       int
+	Offset      int
+}
+
+// SearchDocuments 基于 LIKE 检索已发布的文档，返回一页带正文的文档（用于生成命中片段）及总数。
+func (s *Store) SearchDocuments(ctx context.Context, filter SearchFilter) ([]Document, int, error) {
+	if len(filter.Terms) == 0 {
+		return []Document{}, 0, nil
+	}
+
+	var (
+		conditions = []string{"deleted_at IS NULL"}
+		scores     []string
+		whereArgs  []any
+		scoreArgs  []any
+	)
+	for _, term := range filter.Terms {
+		pattern := likePattern(term)
+		conditions = append(conditions, "(LOWER(title) LIKE ? ESCAPE '!' OR LOWER(content) LIKE ? ESCAPE '!')")
+		whereArgs = append(whereArgs, pattern, pattern)
+		// 相关度：标题命中记 3 分、正文命中记 1 分，按所有词累加。
+		scores = append(scores,
+			"(CASE WHEN LOWER(title) LIKE ? ESCAPE '!' THEN 3 ELSE 0 END)",
+			"(CASE WHEN LOWER(content) LIKE ? ESCAPE '!' THEN 1 ELSE 0 END)")
+		scoreArgs = append(scoreArgs, pattern, pattern)
+	}
+	if filter.Space != "" {
+		conditions = append(conditions, "space = ?")
+		whereArgs = append(whereArgs, filter.Space)
+	}
+	if condition, args := visibleCondition(filter.Viewer); condition != "" {
+		conditions = append(conditions, condition)
+		whereArgs = append(whereArgs, args...)
+	}
+	// 草稿与归档文档不出现在搜索结果中。
+	condition, statusArgs := statusCondition(filter.Viewer, DocStatusPublished)
+	conditions = append(conditions, condition)
+	whereArgs = append(whereArgs, statusArgs...)
+	where := " WHERE " + strings.Join(conditions, " AND ")
+
+	var total int
+	if err := s.queryRow(ctx, "SELECT COUNT(*) FROM docs"+where, whereArgs...).Scan(&total); err != nil {
+		return nil, 0, err
+	}
+
+	order := " ORDER BY updated_at DESC, doc_id DESC"
+	switch {
+	case filter.ByRelevance:
+		order = " ORDER BY score DESC, updated_at DESC, doc_id DESC"
+	case filter.Ascending:
+		order = " ORDER BY updated_at ASC, doc_id ASC"
+	}
+
+	args := append(append(scoreArgs, whereArgs...), filter.Limit, filter.Offset)
+	rows, err := s.query(ctx,
+		"SELECT "+documentColumns+", "+strings.Join(scores, " + ")+" AS score FROM docs"+where+order+" LIMIT ? OFFSET ?",
+		args...)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer rows.Close()
+
+	docs := []Document{}
+	for rows.Next() {
+		var (
+			doc   Document
+			score int
+		)
+		if err := rows.Scan(append(documentFields(&doc), &score)...); err != nil {
+			return nil, 0, err
+		}
+		docs = append(docs, doc)
+	}
+	return docs, total, rows.Err()
+}
@@ -0,0 +1,112 @@
+package store
+
+import (
+	"context"
+	"time"
+)
+
+// RevokeSession 表示用户在会话管理中撤销了该会话。
+const RevokeSession = "session_revoked"
+
+// 与 sessions 表的列长度一致。
+const (
+	maxUserAgentLength = 255
+	maxIPLength        = 45
+)
+
+// Session 是一次登录产生的会话，refresh token 轮换时沿用同一个会话。
+type Session struct {
+	ID           int64     `json:"id"`
+	UserID       int64     `json:"-"`
+	UserAgent    string    `json:"user_agent"`
+	IP           string    `json:"ip"`
+	CreatedAt    time.Time `json:"created_at"`
+	LastActiveAt time.Time `json:"last_active_at"`
+}
+
+func (s *Store) CreateSession(ctx context.Context, session *Session) error {
+	now := time.Now().UTC()
+	session.UserAgent, session.IP = truncate(session.UserAgent, maxUserAgentLength), truncate(session.IP, maxIPLength)
+	session.CreatedAt, session.LastActiveAt = now, now
+	id, err := s.insert(ctx, "session_id",
+		"INSERT INTO sessions (user_id, user_agent, ip, created_at, last_active_at) VALUES (?, ?, ?, ?, ?)",
+		session.UserID, session.UserAgent, session.IP, session.CreatedAt, session.LastActiveAt)
+	if err != nil {
+		return err
+	}
+	session.ID = id
+	return nil
+}
+
+// TouchSession 在刷新令牌时更新会话的最后活跃时间与设备信息，会话已被撤销时返回 ErrNotFound。
+func (s *Store) TouchSession(ctx context.Context, id int64, userAgent string, ip string) error {
+	result, err := s.exec(ctx, "UPDATE sessions SET user_agent = ?, ip = ?, last_active_at = ? WHERE session_id = ?",
+		truncate(userAgent, maxUserAgentLength), truncate(ip, maxIPLength), time.Now().UTC(), id)
+	if err != nil {
+		return err
+	}
+	return requireAffected(result)
+}
+
+// ListSessions 按最后活跃时间倒序返回用户仍持有未过期 refresh token 的会话。
+func (s *Store) ListSessions(ctx context.Context, userID int64) ([]Session, error) {
+	rows, err := s.query(ctx, `SELECT session_id, user_id, user_agent, ip, created_at, last_active_at FROM sessions
+WHERE user_id = ? AND EXISTS (SELECT 1 FROM refresh_tokens WHERE refresh_tokens.session_id = sessions.session_id AND expires_at > ?)
+ORDER BY last_active_at DESC, session_id DESC`, userID, time.Now().UTC())
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	sessions := []Session{}
+	for rows.Next() {
+		var session Session
+		if err := rows.Scan(&session.ID, &session.UserID, &session.UserAgent, &session.IP, &session.CreatedAt, &session.LastActiveAt); err != nil {
+			return nil, err
+		}
+		sessions = append(sessions, session)
+	}
+	return sessions, rows.Err()
+}
+
+// RevokeSession 作废会话的 refresh token 并删除会话，会话不存在或不属于该用户时返回 ErrNotFound。
+func (s *Store) RevokeSession(ctx context.Context, userID int64, sessionID int64) error {
+	return s.WithTx(ctx, func(tx *Store) error {
+		result, err := tx.exec(ctx, "DELETE FROM sessions WHERE session_id = ? AND user_id = ?", sessionID, userID)
+		if err != nil {
+			return err
+		}
+		if err := requireAffected(result); err != nil {
+			return err
+		}
+		return tx.revokeRefreshTokens(ctx, userID, RevokeSession, "session_id = ?", sessionID)
+	})
+}
+
+// RevokeOtherSessions 撤销用户除 keepID 之外的全部会话，包括尚未归属会话的旧令牌，返回撤销的会话数。
+// keepID 为 0 时撤销全部会话。
+func (s *Store) RevokeOtherSessions(ctx context.Context, userID int64, keepID int64) (int64, error) {
+	var revoked int64
+	err := s.WithTx(ctx, func(tx *Store) error {
+		err := tx.revokeRefreshTokens(ctx, userID, RevokeSession,
+			"user_id = ? AND (session_id IS NULL OR session_id <> ?)", userID, keepID)
+		if err != nil {
+			return err
+		}
+		result, err := tx.exec(ctx, "DELETE FROM sessions WHERE user_id = ? AND session_id <> ?", userID, keepID)
+		if err != nil {
+			return err
+		}
+		revoked, err = result.RowsAffected()
+		return err
+	})
+	return revoked, err
+}
+
+// truncate 按字符截断 s，使其不超过 limit 个字符。
+func truncate(s string, limit int) string {
+	if runes := []rune(s); len(runes) > limit {
+		return string(runes[:limit])
+	}
+	return s
+}
@@ -0,0 +1,236 @@
+package store
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/lifei6671/plaindoc/apps/server/internal/auth"
+)
+
+const (
+	SpaceRoleOwner  = "owner"
+	SpaceRoleMember = "member"
+)
+
+// ErrLastSpaceOwner 表示操作会让空间失去最后一个所有者。
+var ErrLastSpaceOwner = errors.New("space must keep at least one owner")
+
+// Space 是一组文档、标签的归属，Key 即 docs.space 与 tags.space 中的取值。
+// 公开空间对所有人可见（文档仍受各自的私有设置约束），私有空间只对成员与管理员可见。
+type Space struct {
+	ID          int64     `json:"id"`
+	Key         string    `json:"key"`
+	Name        string    `json:"name"`
+	Description string    `json:"description"`
+	IsPrivate   bool      `json:"is_private"`
+	CreatedBy   *int64    `json:"created_by"`
+	CreatedAt   time.Time `json:"created_at"`
+	UpdatedAt   time.Time `json:"updated_at"`
+	// MemberRole 只在空间列表中填充，为当前用户在空间中的角色，非成员为空串。
+	MemberRole string `json:"member_role"`
+}
+
+// SpaceMember 是空间成员，Name 与 Email 来自 users。
+type SpaceMember struct {
+	UserID    int64     `json:"user_id"`
+	Name      string    `json:"name"`
+	Email     string    `json:"email"`
+	Role      string    `json:"role"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+const spaceColumns = "space_id, space_key, name, description, is_private, created_by_user_id, created_at, updated_at"
+
+func scanSpace(row scanner, extra ...any) (*Space, error) {
+	space := &Space{}
+	dest := append([]any{&space.ID, &space.Key, &space.Name, &space.Description, &space.IsPrivate,
+		&space.CreatedBy, &space.CreatedAt, &space.UpdatedAt}, extra...)
+	if err := row.Scan(dest...); err != nil {
+		return nil, notFound(err)
+	}
+	return space, nil
+}
+
+// spaceCondition 返回限定 viewer 可访问空间的 WHERE 条件（作用于当前表的 space 列），管理员不受限制时返回空串。
+func spaceCondition(viewer Viewer) (string, []any) {
+	if viewer.Role == auth.RoleAdmin {
+		return "", nil
+	}
+	return "space IN (SELECT space_key FROM spaces WHERE is_private = ? OR space_id IN (SELECT space_id FROM space_members WHERE user_id = ?))",
+		[]any{false, viewer.ID}
+}
+
+// CreateSpace 创建空间并把创建者登记为所有者，key 已存在时返回 ErrDuplicate。
+func (s *Store) CreateSpace(ctx context.Context, space *Space) error {
+	now := time.Now().UTC()
+	space.CreatedAt, space.UpdatedAt = now, now
+	return s.WithTx(ctx, func(tx *Store) error {
+		id, err := tx.insert(ctx, "space_id",
+			"INSERT INTO spaces (space_key, name, description, is_private, created_by_user_id, created_at, updated_at) VALUES (?, ?, ?, ?, ?, ?, ?)",
+			space.Key, space.Name, space.Description, space.IsPrivate, space.CreatedBy, space.CreatedAt, space.UpdatedAt)
+		if err != nil {
+			return err
+		}
+		space.ID = id
+		if space.CreatedBy == nil {
+			return nil
+		}
+		space.MemberRole = SpaceRoleOwner
+		_, err = tx.exec(ctx, "INSERT INTO space_members (space_id, user_id, role, created_at) VALUES (?, ?, ?, ?)",
+			space.ID, *space.CreatedBy, SpaceRoleOwner, now)
+		return err
+	})
+}
+
+// UpdateSpace 保存名称、说明与可见范围，key 创建后不可修改。
+func (s *Store) UpdateSpace(ctx context.Context, space *Space) error {
+	space.UpdatedAt = time.Now().UTC()
+	result, err := s.exec(ctx, "UPDATE spaces SET name = ?, description = ?, is_private = ?, updated_at = ? WHERE space_id = ?",
+		space.Name, space.Description, space.IsPrivate, space.UpdatedAt, space.ID)
+	if err != nil {
+		return err
+	}
+	return requireAffected(result)
+}
+
+func (s *Store) GetSpace(ctx context.Context, id int64) (*Space, error) {
+	return scanSpace(s.queryRow(ctx, "SELECT "+spaceColumns+" FROM spaces WHERE space_id = ?", id))
+}
+
+func (s *Store) GetSpaceByKey(ctx context.Context, key string) (*Space, error) {
+	return scanSpace(s.queryRow(ctx, "SELECT "+spaceColumns+" FROM spaces WHERE space_key = ?", key))
+}
+
+// ListSpaces 按 key 返回 viewer 可访问的空间：管理员为全部空间，其他人为公开空间与自己加入的空间。
+func (s *Store) ListSpaces(ctx context.Context, viewer Viewer) ([]Space, error) {
+	query, args := "SELECT "+spaceColumns+", COALESCE((SELECT role FROM space_members m WHERE m.space_id = spaces.space_id AND m.user_id = ?), '') FROM spaces",
+		[]any{viewer.ID}
+	if viewer.Role != auth.RoleAdmin {
+		query += " WHERE is_private = ? OR space_id IN (SELECT space_id FROM space_members WHERE user_id = ?)"
+		args = append(args, false, viewer.ID)
+	}
+	rows, err := s.query(ctx, query+" ORDER BY space_key", args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	spaces := []Space{}
+	for rows.Next() {
+		var role string
+		space, err := scanSpace(rows, &role)
+		if err != nil {
+			return nil, err
+		}
+		space.MemberRole = role
+		spaces = append(spaces, *space)
+	}
+	return spaces, rows.Err()
+}
+
+// SpaceMemberRole 返回 userID 在空间中的角色，不是成员时返回空串。
+func (s *Store) SpaceMemberRole(ctx context.Context, spaceID int64, userID int64) (string, error) {
+	var role string
+	err := s.queryRow(ctx, "SELECT role FROM space_members WHERE space_id = ? AND user_id = ?", spaceID, userID).Scan(&role)
+	if err = notFound(err); errors.Is(err, ErrNotFound) {
+		return "", nil
+	}
+	return role, err
+}
+
+// CanAccessSpace 判断 viewer 能否访问 key 对应的空间，空间不存在时返回 false。
+func (s *Store) CanAccessSpace(ctx context.Context, viewer Viewer, key string) (bool, error) {
+	space, err := s.GetSpaceByKey(ctx, key)
+	if errors.Is(err, ErrNotFound) {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+	if !space.IsPrivate || viewer.Role == auth.RoleAdmin {
+		return true, nil
+	}
+	if viewer.ID == 0 {
+		return false, nil
+	}
+	role, err := s.SpaceMemberRole(ctx, space.ID, viewer.ID)
+	return role != "", err
+}
+
+// ListSpaceMembers 返回空间的全部成员，所有者在前。
+func (s *Store) ListSpaceMembers(ctx context.Context, spaceID int64) ([]SpaceMember, error) {
+	rows, err := s.query(ctx,
+		"SELECT m.user_id, u.name, u.email, m.role, m.created_at FROM space_members m JOIN users u ON u.user_id = m.user_id"+
+			" WHERE m.space_id = ? ORDER BY CASE WHEN m.role = ? THEN 0 ELSE 1 END, m.created_at, m.user_id",
+		spaceID, SpaceRoleOwner)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	members := []SpaceMember{}
+	for rows.Next() {
+		var member SpaceMember
+		if err := rows.Scan(&member.UserID, &member.Name, &member.Email, &member.Role, &member.CreatedAt); err != nil {
+			return nil, err
+		}
+		members = append(members, member)
+	}
+	return members, rows.Err()
+}
+
+// SetSpaceMember 把用户加入空间，已是成员时修改其角色；把最后一个所有者降为成员时返回 ErrLastSpaceOwner。
+func (s *Store) SetSpaceMember(ctx context.Context, spaceID int64, userID int64, role string) error {
+	return s.WithTx(ctx, func(tx *Store) error {
+		current, err := tx.SpaceMemberRole(ctx, spaceID, userID)
+		if err != nil {
+			return err
+		}
+		if current == "" {
+			_, err = tx.exec(ctx, "INSERT INTO space_members (space_id, user_id, role, created_at) VALUES (?, ?, ?, ?)",
+				spaceID, userID, role, time.Now().UTC())
+			return err
+		}
+		if current == SpaceRoleOwner && role != SpaceRoleOwner {
+			if err := tx.requireOtherOwner(ctx, spaceID, userID); err != nil {
+				return err
+			}
+		}
+		_, err = tx.exec(ctx, "UPDATE space_members SET role = ? WHERE space_id = ? AND user_id = ?", role, spaceID, userID)
+		return err
+	})
+}
+
+// RemoveSpaceMember 把用户移出空间，不是成员时返回 ErrNotFound，移除最后一个所有者时返回 ErrLastSpaceOwner。
+func (s *Store) RemoveSpaceMember(ctx context.Context, spaceID int64, userID int64) error {
+	return s.WithTx(ctx, func(tx *Store) error {
+		current, err := tx.SpaceMemberRole(ctx, spaceID, userID)
+		if err != nil {
+			return err
+		}
+		if current == "" {
+			return ErrNotFound
+		}
+		if current == SpaceRoleOwner {
+			if err := tx.requireOtherOwner(ctx, spaceID, userID); err != nil {
+				return err
+			}
+		}
+		_, err = tx.exec(ctx, "DELETE FROM space_members WHERE space_id = ? AND user_id = ?", spaceID, userID)
+		return err
+	})
+}
+
+func (s *Store) requireOtherOwner(ctx context.Context, spaceID int64, userID int64) error {
+	var owners int
+	err := s.queryRow(ctx, "SELECT COUNT(*) FROM space_members WHERE space_id = ? AND role = ? AND user_id <> ?",
+		spaceID, SpaceRoleOwner, userID).Scan(&owners)
+	if err != nil {
+		return err
+	}
+	if owners == 0 {
+		return ErrLastSpaceOwner
+	}
+	return nil
+}
@@ -0,0 +1,189 @@
+package store
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"log/slog"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/lifei6671/plaindoc/apps/server/internal/database"
+)
+
+// maxSQLDigestBytes 限制慢查询日志中 SQL 摘要的长度。
+const maxSQLDigestBytes = 512
+
+var (
+	ErrNotFound  = errors.New("record not found")
+	ErrDuplicate = errors.New("record already exists")
+)
+
+type querier interface {
+	ExecContext(ctx context.Context, query string, args ...any) (sql.Result, error)
+	QueryContext(ctx context.Context, query string, args ...any) (*sql.Rows, error)
+	QueryRowContext(ctx context.Context, query string, args ...any) *sql.Row
+}
+
+// Store 封装全部数据访问；事务内通过 WithTx 得到共享同一 *sql.Tx 的 Store。
+// SQL 统一以 ? 作为占位符书写，由 dialect 在执行前转换为目标数据库的写法。
+// 所有查询都使用调用方的 ctx 执行，请求超时或客户端断开时查询随之取消，连接尽快归还连接池。
+type Store struct {
+	db      *sql.DB
+	q       querier
+	dialect database.Dialect
+	opts    Options
+	logger  *slog.Logger
+}
+
+// Options 控制 Store 的可选行为。
+type Options struct {
+	// SlowQueryThreshold 是记录慢查询 warning 日志的耗时阈值，0 表示不记录。
+	SlowQueryThreshold time.Duration
+}
+
+func New(db *sql.DB, dialect database.Dialect, opts Options, logger *slog.Logger) *Store {
+	return &Store{db: db, q: db, dialect: dialect, opts: opts, logger: logger}
+}
+
+// withQuerier 返回在 q（通常是事务）上执行查询、其余配置相同的 Store。
+func (s *Store) withQuerier(q querier) *Store {
+	clone := *s
+	clone.q = q
+	return &clone
+}
+
+// WithTx 在事务中执行 fn，fn 返回错误时回滚；已在事务内时直接复用当前事务。
+func (s *Store) WithTx(ctx context.Context, fn func(tx *Store) error) error {
+	if _, ok := s.q.(*sql.Tx); ok {
+		return fn(s)
+	}
+
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if err := fn(s.withQuerier(tx)); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+func (s *Store) exec(ctx context.Context, query string, args ...any) (sql.Result, error) {
+	start := time.Now()
+	result, err := s.q.ExecContext(ctx, s.dialect.Rebind(query), args...)
+	s.logSlow(ctx, query, start, err)
+	return result, s.translate(err)
+}
+
+// query 的耗时只统计到返回第一批结果为止，不含调用方遍历 rows 的时间。
+func (s *Store) query(ctx context.Context, query string, args ...any) (*sql.Rows, error) {
+	start := time.Now()
+	rows, err := s.q.QueryContext(ctx, s.dialect.Rebind(query), args...)
+	s.logSlow(ctx, query, start, err)
+	return rows, s.translate(err)
+}
+
+func (s *Store) queryRow(ctx context.Context, query string, args ...any) *sql.Row {
+	start := time.Now()
+	row := s.q.QueryRowContext(ctx, s.dialect.Rebind(query), args...)
+	s.logSlow(ctx, query, start, row.Err())
+	return row
+}
+
+// logSlow 在查询耗时达到 SlowQueryThreshold 时记录 warning 日志；只记录 SQL 摘要，不记录参数，避免把密码哈希、令牌等写入日志。
+func (s *Store) logSlow(ctx context.Context, query string, start time.Time, err error) {
+	if s.opts.SlowQueryThreshold <= 0 {
+		return
+	}
+	elapsed := time.Since(start)
+	if elapsed < s.opts.SlowQueryThreshold {
+		return
+	}
+	attrs := []slog.Attr{
+		slog.String("sql", sqlDigest(query)),
+		slog.Float64("duration_ms", float64(elapsed.Microseconds())/1000),
+		slog.Float64("threshold_ms", float64(s.opts.SlowQueryThreshold.Microseconds())/1000),
+	}
+	if _, ok := s.q.(*sql.Tx); ok {
+		attrs = append(attrs, slog.Bool("in_tx", true))
+	}
+	if err != nil && !errors.Is(err, sql.ErrNoRows) {
+		attrs = append(attrs, slog.String("error", err.Error()))
+	}
+	s.logger.LogAttrs(ctx, slog.LevelWarn, "slow query", attrs...)
+}
+
+// sqlDigest 把 SQL 中连续的空白合并为一个空格，超长时截断，便于在日志中按语句聚合。
+func sqlDigest(query string) string {
+	digest := strings.Join(strings.Fields(query), " ")
+	if len(digest) <= maxSQLDigestBytes {
+		return digest
+	}
+	cut := maxSQLDigestBytes
+	for cut > 0 && !utf8.RuneStart(digest[cut]) {
+		cut--
+	}
+	return digest[:cut] + "..."
+}
+
+// insert 执行 INSERT 并返回自增主键 idColumn 的值；PostgreSQL 不支持 LastInsertId，改用 RETURNING 取回。
+func (s *Store) insert(ctx context.Context, idColumn, query string, args ...any) (int64, error) {
+	if s.dialect.Returning() {
+		var id int64
+		err := s.queryRow(ctx, query+" RETURNING "+idColumn, args...).Scan(&id)
+		return id, s.translate(err)
+	}
+	result, err := s.exec(ctx, query, args...)
+	if err != nil {
+		return 0, err
+	}
+	return result.LastInsertId()
+}
+
+// scanner 同时适配 *sql.Row 与 *sql.Rows。
+type scanner interface {
+	Scan(dest ...any) error
+}
+
+// MaxErrorLength 是 jobs.error 与 webhook_deliveries.last_error 等错误信息列的长度上限。
+const MaxErrorLength = 1024
+
+// ErrorText 返回适合写入错误信息列的文本：超过 MaxErrorLength 字节时截断，并去掉截断处残留的不完整 UTF-8 字符。
+func ErrorText(err error) string {
+	text := err.Error()
+	if len(text) <= MaxErrorLength {
+		return text
+	}
+	return strings.ToValidUTF8(text[:MaxErrorLength], "")
+}
+
+// notFound 把 sql.ErrNoRows 统一转换为 ErrNotFound。
+func notFound(err error) error {
+	if errors.Is(err, sql.ErrNoRows) {
+		return ErrNotFound
+	}
+	return err
+}
+
+func (s *Store) translate(err error) error {
+	if err != nil && s.dialect.IsDuplicate(err) {
+		return errors.Join(ErrDuplicate, err)
+	}
+	return err
+}
+
+// requireAffected 在 UPDATE/DELETE 未命中任何行时返回 ErrNotFound。
+func requireAffected(result sql.Result) error {
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if affected == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
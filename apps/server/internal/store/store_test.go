@@ -0,0 +1,186 @@
+package store
+
+import (
+	"context"
+	"errors"
+	"io"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"reflect"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/lifei6671/plaindoc/apps/server/internal/auth"
+	"github.com/lifei6671/plaindoc/apps/server/internal/config"
+	"github.com/lifei6671/plaindoc/apps/server/internal/database"
+	"github.com/lifei6671/plaindoc/apps/server/internal/migrate"
+)
+
+// testDSNs 是在 PostgreSQL 与 MySQL 上运行测试所用连接串的环境变量，未设置时跳过对应方言的测试。
+// 测试会清空库中的全部数据，请指向专用的空库，并用 -p 1 避免多个包同时操作同一个库。
+var testDSNs = map[database.Dialect]string{
+	database.Postgres: "PLAINDOC_TEST_POSTGRES_DSN",
+	database.MySQL:    "PLAINDOC_TEST_MYSQL_DSN",
+}
+
+// newTestStore 返回连接到已迁移的临时 SQLite 数据库的 Store。
+func newTestStore(t *testing.T) *Store {
+	t.Helper()
+	return openTestStore(t, database.SQLite)
+}
+
+// openTestStore 返回连接到 dialect 测试库的 Store：SQLite 使用临时目录中的新库，
+// 其他方言读取 testDSNs 中的环境变量，并回滚全部迁移后重新执行，保证从空库开始。
+func openTestStore(t *testing.T, dialect database.Dialect) *Store {
+	t.Helper()
+	ctx := context.Background()
+	dsn := filepath.Join(t.TempDir(), "plaindoc.db")
+	if dialect != database.SQLite {
+		if dsn = os.Getenv(testDSNs[dialect]); dsn == "" {
+			t.Skipf("%s not set", testDSNs[dialect])
+		}
+	}
+	db, dialect, err := database.Open(config.Config{DBDriver: string(dialect), DBDSN: dsn})
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { db.Close() })
+	migrator, err := migrate.New(db, dialect, time.Minute)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if dialect != database.SQLite {
+		if err := migrator.Up(ctx); err != nil {
+			t.Fatal(err)
+		}
+		if err := migrator.Down(ctx, len(migrator.Migrations())); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := migrator.Up(ctx); err != nil {
+		t.Fatal(err)
+	}
+	return New(db, dialect, Options{}, slog.New(slog.NewTextHandler(io.Discard, nil)))
+}
+
+func newTestUser(t *testing.T, s *Store) *User {
+	t.Helper()
+	user := &User{Email: "user@example.com", Name: "user", Role: "editor"}
+	if err := s.CreateUser(context.Background(), user); err != nil {
+		t.Fatal(err)
+	}
+	return user
+}
+
+// 在每种方言上走一遍常用的读写路径，覆盖各方言 SQL 差异最大的部分：自增主键、唯一约束、外键、LIKE 转义、分页与行锁。
+func TestStoreDialects(t *testing.T) {
+	ctx := context.Background()
+	for _, dialect := range []database.Dialect{database.SQLite, database.Postgres, database.MySQL} {
+		t.Run(string(dialect), func(t *testing.T) {
+			s := openTestStore(t, dialect)
+
+			admin := &User{Email: "Admin@Example.com", Name: "admin", Role: auth.RoleAdmin}
+			if err := s.CreateUser(ctx, admin); err != nil {
+				t.Fatalf("CreateUser() = %v", err)
+			}
+			if err := s.CreateUser(ctx, &User{Email: "admin@example.com", Name: "again", Role: auth.RoleAdmin}); !errors.Is(err, ErrDuplicate) {
+				t.Fatalf("CreateUser() with a taken email = %v, want ErrDuplicate", err)
+			}
+			got, err := s.GetUserByEmail(ctx, "ADMIN@example.com")
+			if err != nil || got.ID != admin.ID {
+				t.Fatalf("GetUserByEmail() = %+v, %v, want user %d", got, err, admin.ID)
+			}
+
+			// updated_by_user_id 引用 users.user_id，两边列类型不一致时这里会在 MySQL 上失败。
+			logs, err := s.UpdateSystemConfigs(ctx, map[string]string{"site_name": "PlainDoc"}, admin.ID)
+			if err != nil || len(logs) != 1 {
+				t.Fatalf("UpdateSystemConfigs() = %d logs, %v, want 1 log", len(logs), err)
+			}
+
+			guide := &Document{Title: "Guide", Slug: "guide", Content: "100% plain text", AuthorID: admin.ID}
+			intro := &Document{Title: "Intro", Slug: "intro", Content: "see [[guide]]", AuthorID: admin.ID}
+			for _, doc := range []*Document{guide, intro} {
+				if err := s.CreateDocument(ctx, doc); err != nil {
+					t.Fatalf("CreateDocument(%s) = %v", doc.Slug, err)
+				}
+			}
+			if intro.ID == guide.ID || intro.SortOrder <= guide.SortOrder {
+				t.Fatalf("documents got id %d/%d, sort order %d/%d", guide.ID, intro.ID, guide.SortOrder, intro.SortOrder)
+			}
+			if err := s.CreateDocument(ctx, &Document{Title: "Again", Slug: "guide", AuthorID: admin.ID}); !errors.Is(err, ErrDuplicate) {
+				t.Fatalf("CreateDocument() with a taken slug = %v, want ErrDuplicate", err)
+			}
+
+			stale := *guide
+			guide.Content = "100% updated"
+			if err := s.UpdateDocument(ctx, guide, admin.ID, "edit"); err != nil {
+				t.Fatalf("UpdateDocument() = %v", err)
+			}
+			if err := s.UpdateDocument(ctx, &stale, admin.ID, "stale"); !errors.Is(err, ErrVersionConflict) {
+				t.Fatalf("UpdateDocument() with a stale version = %v, want ErrVersionConflict", err)
+			}
+			if got, err := s.GetDocumentBySlug(ctx, DefaultSpace, "guide"); err != nil || got.Version != 2 || got.Content != guide.Content {
+				t.Fatalf("GetDocumentBySlug() = %+v, %v, want version 2", got, err)
+			}
+
+			if _, err := s.SetDocumentTags(ctx, DefaultSpace, intro.ID, []string{"start"}); err != nil {
+				t.Fatalf("SetDocumentTags() = %v", err)
+			}
+			viewer := Viewer{ID: admin.ID, Role: admin.Role}
+			lists := []struct {
+				name   string
+				filter DocumentFilter
+				total  int
+				want   []int64
+			}{
+				{"all", DocumentFilter{Viewer: viewer, Limit: 10}, 2, []int64{guide.ID, intro.ID}},
+				{"keyword ignores case", DocumentFilter{Viewer: viewer, Keyword: "INTRO", Limit: 10}, 1, []int64{intro.ID}},
+				{"keyword escapes wildcards", DocumentFilter{Viewer: viewer, Keyword: "100%", Limit: 10}, 1, []int64{guide.ID}},
+				{"tag", DocumentFilter{Viewer: viewer, Tag: "start", Limit: 10}, 1, []int64{intro.ID}},
+			}
+			for _, tt := range lists {
+				docs, total, err := s.ListDocuments(ctx, tt.filter)
+				if err != nil {
+					t.Fatalf("ListDocuments(%s) = %v", tt.name, err)
+				}
+				if ids := documentIDs(docs); total != tt.total || !reflect.DeepEqual(ids, tt.want) {
+					t.Errorf("ListDocuments(%s) = %v of %d, want %v of %d", tt.name, ids, total, tt.want, tt.total)
+				}
+			}
+			var paged []Document
+			for offset := 0; offset < 3; offset++ {
+				docs, total, err := s.ListDocuments(ctx, DocumentFilter{Viewer: viewer, Limit: 1, Offset: offset})
+				if err != nil || total != 2 {
+					t.Fatalf("ListDocuments(offset %d) = %d, %v, want a total of 2", offset, total, err)
+				}
+				paged = append(paged, docs...)
+			}
+			if ids := documentIDs(paged); !reflect.DeepEqual(ids, []int64{guide.ID, intro.ID}) {
+				t.Errorf("ListDocuments() pages = %v, want %v", ids, []int64{guide.ID, intro.ID})
+			}
+			if backlinks, total, err := s.ListBacklinks(ctx, viewer, guide.ID, 10, 0); err != nil || total != 1 || backlinks[0].ID != intro.ID {
+				t.Fatalf("ListBacklinks() = %d backlinks, %v, want intro", total, err)
+			}
+
+			newTestRefreshToken(t, s, admin.ID, nil, "smoke", time.Hour)
+			if _, err := s.ConsumeRefreshToken(ctx, "smoke", RevokeRotated); err != nil {
+				t.Fatalf("ConsumeRefreshToken() = %v", err)
+			}
+			if _, err := s.ConsumeRefreshToken(ctx, "smoke", RevokeRotated); !errors.Is(err, ErrRefreshTokenReused) {
+				t.Fatalf("ConsumeRefreshToken() again = %v, want ErrRefreshTokenReused", err)
+			}
+		})
+	}
+}
+
+// documentIDs 返回 docs 的 ID，按升序排列，便于与期望值比较而不依赖排序字段的精度。
+func documentIDs(docs []Document) []int64 {
+	ids := make([]int64, len(docs))
+	for i, doc := range docs {
+		ids[i] = doc.ID
+	}
+	slices.Sort(ids)
+	return ids
+}
@@ -0,0 +1,180 @@
+package store
+
+import (
+	"context"
+	"errors"
+	"sort"
+	"strings"
+	"time"
+)
+
+// MaskedConfigValue 是敏感配置在审计日志与接口中的占位值。
+const MaskedConfigValue = "******"
+
+// sensitiveConfigMarkers 出现在配置键中即视为密钥类配置。
+var sensitiveConfigMarkers = []string{"secret", "password", "token", "private_key", "api_key"}
+
+// SystemConfig 是 system_configs 中的一项配置。
+type SystemConfig struct {
+	Key       string    `json:"key"`
+	Value     string    `json:"value"`
+	UpdatedBy *int64    `json:"updated_by"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// ConfigAuditLog 记录一次配置变更；敏感配置的新旧值已脱敏。
+type ConfigAuditLog struct {
+	ID        int64     `json:"id"`
+	Key       string    `json:"key"`
+	OldValue  *string   `json:"old_value"`
+	NewValue  string    `json:"new_value"`
+	UpdatedBy *int64    `json:"updated_by"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// ConfigAuditFilter 描述审计日志列表的过滤与分页条件。
+type ConfigAuditFilter struct {
+	Key    string
+	Limit  int
+	Offset int
+}
+
+const (
+	systemConfigColumns   = "config_key, config_value, updated_by_user_id, updated_at"
+	configAuditLogColumns = "audit_id, config_key, old_value, new_value, updated_by_user_id, created_at"
+)
+
+// IsSensitiveConfig 判断配置键是否属于密钥类配置。
+func IsSensitiveConfig(key string) bool {
+	key = strings.ToLower(key)
+	for _, marker := range sensitiveConfigMarkers {
+		if strings.Contains(key, marker) {
+			return true
+		}
+	}
+	return false
+}
+
+func scanSystemConfig(row scanner) (*SystemConfig, error) {
+	cfg := &SystemConfig{}
+	if err := row.Scan(&cfg.Key, &cfg.Value, &cfg.UpdatedBy, &cfg.UpdatedAt); err != nil {
+		return nil, notFound(err)
+	}
+	return cfg, nil
+}
+
+func scanConfigAuditLog(row scanner) (*ConfigAuditLog, error) {
+	log := &ConfigAuditLog{}
+	if err := row.Scan(&log.ID, &log.Key, &log.OldValue, &log.NewValue, &log.UpdatedBy, &log.CreatedAt); err != nil {
+		return nil, notFound(err)
+	}
+	return log, nil
+}
+
+func (s *Store) ListSystemConfigs(ctx context.Context) ([]SystemConfig, error) {
+	rows, err := s.query(ctx, "SELECT "+systemConfigColumns+" FROM system_configs ORDER BY config_key")
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	configs := []SystemConfig{}
+	for rows.Next() {
+		cfg, err := scanSystemConfig(rows)
+		if err != nil {
+			return nil, err
+		}
+		configs = append(configs, *cfg)
+	}
+	return configs, rows.Err()
+}
+
+// UpdateSystemConfigs 在同一事务中写入 values 并为每个实际变化的配置记录一条审计日志，
+// 值未变化的键被忽略；返回写入的审计日志（按键排序）。
+func (s *Store) UpdateSystemConfigs(ctx context.Context, values map[string]string, userID int64) ([]ConfigAuditLog, error) {
+	keys := make([]string, 0, len(values))
+	for key := range values {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	logs := []ConfigAuditLog{}
+	err := s.WithTx(ctx, func(tx *Store) error {
+		now := time.Now().UTC()
+		for _, key := range keys {
+			value := values[key]
+			existing, err := scanSystemConfig(tx.queryRow(ctx,
+				"SELECT "+systemConfigColumns+" FROM system_configs WHERE config_key = ?", key))
+			if err != nil && !errors.Is(err, ErrNotFound) {
+				return err
+			}
+
+			var oldValue *string
+			if existing != nil {
+				if existing.Value == value {
+					continue
+				}
+				oldValue = &existing.Value
+				_, err = tx.exec(ctx, "UPDATE system_configs SET config_value = ?, updated_by_user_id = ?, updated_at = ? WHERE config_key = ?",
+					value, userID, now, key)
+			} else {
+				_, err = tx.exec(ctx, "INSERT INTO system_configs (config_key, config_value, updated_by_user_id, updated_at) VALUES (?, ?, ?, ?)",
+					key, value, userID, now)
+			}
+			if err != nil {
+				return err
+			}
+
+			log := ConfigAuditLog{Key: key, OldValue: oldValue, NewValue: value, UpdatedBy: &userID, CreatedAt: now}
+			if IsSensitiveConfig(key) {
+				masked := MaskedConfigValue
+				log.NewValue = masked
+				if log.OldValue != nil {
+					log.OldValue = &masked
+				}
+			}
+			log.ID, err = tx.insert(ctx, "audit_id",
+				"INSERT INTO config_audit_logs (config_key, old_value, new_value, updated_by_user_id, created_at) VALUES (?, ?, ?, ?, ?)",
+				log.Key, log.OldValue, log.NewValue, log.UpdatedBy, log.CreatedAt)
+			if err != nil {
+				return err
+			}
+			logs = append(logs, log)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return logs, nil
+}
+
+// ListConfigAuditLogs 按时间倒序返回一页审计日志及总数。
+func (s *Store) ListConfigAuditLogs(ctx context.Context, filter ConfigAuditFilter) ([]ConfigAuditLog, int, error) {
+	where, args := "", []any{}
+	if filter.Key != "" {
+		where, args = " WHERE config_key = ?", append(args, filter.Key)
+	}
+
+	var total int
+	if err := s.queryRow(ctx, "SELECT COUNT(*) FROM config_audit_logs"+where, args...).Scan(&total); err != nil {
+		return nil, 0, err
+	}
+	rows, err := s.query(ctx,
+		"SELECT "+configAuditLogColumns+" FROM config_audit_logs"+where+" ORDER BY audit_id DESC LIMIT ? OFFSET ?",
+		append(args, filter.Limit, filter.Offset)...)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer rows.Close()
+
+	logs := []ConfigAuditLog{}
+	for rows.Next() {
+		log, err := scanConfigAuditLog(rows)
+		if err != nil {
+			return nil, 0, err
+		}
+		logs = append(logs, *log)
+	}
+	return logs, total, rows.Err()
+}
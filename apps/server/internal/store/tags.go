@@ -0,0 +1,183 @@
+package store
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+// MaxTagNameLength 是归一化后标签名的最大字符数，与 tags.name 列宽一致。
+const MaxTagNameLength = 64
+
+// ErrInvalidTag 表示标签名归一化后为空或超长。
+var ErrInvalidTag = errors.New("invalid tag name")
+
+// Tag 属于某个空间，同名标签在不同空间中是不同的标签。
+type Tag struct {
+	ID    int64  `json:"id"`
+	Space string `json:"space"`
+	Name  string `json:"name"`
+	Color string `json:"color"`
+	// DocCount 只在标签列表中填充，为访问者可见的文档数。
+	DocCount  *int      `json:"doc_count,omitempty"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+const tagColumns = "tag_id, space, name, color, created_at"
+
+func scanTag(row scanner) (*Tag, error) {
+	tag := &Tag{}
+	if err := row.Scan(&tag.ID, &tag.Space, &tag.Name, &tag.Color, &tag.CreatedAt); err != nil {
+		return nil, notFound(err)
+	}
+	return tag, nil
+}
+
+// NormalizeTagName 去掉首尾空白、把连续空白合并为一个空格并转为小写。
+func NormalizeTagName(name string) string {
+	return strings.ToLower(strings.Join(strings.Fields(name), " "))
+}
+
+// NormalizeTagNames 归一化并去重，保留首次出现的顺序；任一标签归一化后为空或超长时返回 ErrInvalidTag。
+func NormalizeTagNames(names []string) ([]string, error) {
+	seen := make(map[string]bool, len(names))
+	normalized := make([]string, 0, len(names))
+	for _, name := range names {
+		name = NormalizeTagName(name)
+		if name == "" || utf8.RuneCountInString(name) > MaxTagNameLength {
+			return nil, ErrInvalidTag
+		}
+		if !seen[name] {
+			seen[name] = true
+			normalized = append(normalized, name)
+		}
+	}
+	return normalized, nil
+}
+
+func (s *Store) GetTag(ctx context.Context, id int64) (*Tag, error) {
+	return scanTag(s.queryRow(ctx, "SELECT "+tagColumns+" FROM tags WHERE tag_id = ?", id))
+}
+
+// ListTags 按名称返回 viewer 可访问空间中的标签（space 非空时只返回该空间的）及各自关联的、viewer 可见且已发布的文档数，
+// 与按标签过滤的文档列表一致。
+func (s *Store) ListTags(ctx context.Context, viewer Viewer, space string) ([]Tag, error) {
+	docs, args := "SELECT doc_id FROM docs WHERE deleted_at IS NULL", []any{}
+	if condition, conditionArgs := visibleCondition(viewer); condition != "" {
+		docs += " AND " + condition
+		args = append(args, conditionArgs...)
+	}
+	condition, conditionArgs := statusCondition(viewer, DocStatusPublished)
+	docs += " AND " + condition
+	args = append(args, conditionArgs...)
+	var conditions []string
+	if space != "" {
+		conditions = append(conditions, "space = ?")
+		args = append(args, space)
+	}
+	if condition, conditionArgs := spaceCondition(viewer); condition != "" {
+		conditions = append(conditions, condition)
+		args = append(args, conditionArgs...)
+	}
+	where := ""
+	if len(conditions) > 0 {
+		where = " WHERE " + strings.Join(conditions, " AND ")
+	}
+	rows, err := s.query(ctx,
+		"SELECT "+tagColumns+", (SELECT COUNT(*) FROM doc_tags dt WHERE dt.tag_id = tags.tag_id AND dt.doc_id IN ("+docs+")) FROM tags"+where+" ORDER BY name, space",
+		args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	tags := []Tag{}
+	for rows.Next() {
+		var tag Tag
+		var count int
+		if err := rows.Scan(&tag.ID, &tag.Space, &tag.Name, &tag.Color, &tag.CreatedAt, &count); err != nil {
+			return nil, err
+		}
+		tag.DocCount = &count
+		tags = append(tags, tag)
+	}
+	return tags, rows.Err()
+}
+
+// UpdateTagColor 修改标签颜色，color 为空串表示使用前端默认配色。
+func (s *Store) UpdateTagColor(ctx context.Context, id int64, color string) error {
+	result, err := s.exec(ctx, "UPDATE tags SET color = ? WHERE tag_id = ?", color, id)
+	if err != nil {
+		return err
+	}
+	return requireAffected(result)
+}
+
+// SetDocumentTags 把文档的标签替换为 names（需已归一化），标签取自文档所在的 space，不存在的会被创建，返回按名称排序的标签。
+func (s *Store) SetDocumentTags(ctx context.Context, space string, docID int64, names []string) ([]Tag, error) {
+	err := s.WithTx(ctx, func(tx *Store) error {
+		if _, err := tx.exec(ctx, "DELETE FROM doc_tags WHERE doc_id = ?", docID); err != nil {
+			return err
+		}
+		for _, name := range names {
+			tag, err := tx.ensureTag(ctx, space, name)
+			if err != nil {
+				return err
+			}
+			if _, err := tx.exec(ctx, "INSERT INTO doc_tags (doc_id, tag_id) VALUES (?, ?)", docID, tag.ID); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	tags, err := s.ListDocumentTags(ctx, []int64{docID})
+	return tags[docID], err
+}
+
+// ensureTag 返回 space 中名为 name 的标签，不存在时创建。
+func (s *Store) ensureTag(ctx context.Context, space string, name string) (*Tag, error) {
+	tag, err := scanTag(s.queryRow(ctx, "SELECT "+tagColumns+" FROM tags WHERE space = ? AND name = ?", space, name))
+	if !errors.Is(err, ErrNotFound) {
+		return tag, err
+	}
+	tag = &Tag{Space: space, Name: name, CreatedAt: time.Now().UTC()}
+	tag.ID, err = s.insert(ctx, "tag_id", "INSERT INTO tags (space, name, color, created_at) VALUES (?, ?, ?, ?)",
+		tag.Space, tag.Name, tag.Color, tag.CreatedAt)
+	return tag, err
+}
+
+// ListDocumentTags 批量读取多篇文档的标签，每篇文档的标签按名称排序；没有标签的文档对应空切片。
+func (s *Store) ListDocumentTags(ctx context.Context, docIDs []int64) (map[int64][]Tag, error) {
+	result := make(map[int64][]Tag, len(docIDs))
+	if len(docIDs) == 0 {
+		return result, nil
+	}
+	args := make([]any, 0, len(docIDs))
+	for _, id := range docIDs {
+		result[id] = []Tag{}
+		args = append(args, id)
+	}
+	placeholders := strings.TrimSuffix(strings.Repeat("?, ", len(args)), ", ")
+	rows, err := s.query(ctx,
+		"SELECT dt.doc_id, t.tag_id, t.space, t.name, t.color, t.created_at FROM doc_tags dt JOIN tags t ON t.tag_id = dt.tag_id"+
+			" WHERE dt.doc_id IN ("+placeholders+") ORDER BY t.name", args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var docID int64
+		var tag Tag
+		if err := rows.Scan(&docID, &tag.ID, &tag.Space, &tag.Name, &tag.Color, &tag.CreatedAt); err != nil {
+			return nil, err
+		}
+		result[docID] = append(result[docID], tag)
+	}
+	return result, rows.Err()
+}
@@ -0,0 +1,188 @@
+package store
+
+import (
+	"context"
+	"slices"
+	"strconv"
+	"time"
+
+	"github.com/lifei6671/plaindoc/apps/server/internal/auth"
+)
+
+// Template 是管理员维护的文档模板，Content 中的占位符在创建文档时替换。
+// 私有模板只对创建者、管理员以及 UserIDs、Roles 中的用户与角色可见；公开模板忽略这两项。
+type Template struct {
+	ID          int64     `json:"id"`
+	Name        string    `json:"name"`
+	Description string    `json:"description"`
+	Content     string    `json:"content"`
+	IsPrivate   bool      `json:"is_private"`
+	UserIDs     []int64   `json:"user_ids"`
+	Roles       []string  `json:"roles"`
+	CreatedBy   *int64    `json:"created_by"`
+	CreatedAt   time.Time `json:"created_at"`
+	UpdatedAt   time.Time `json:"updated_at"`
+}
+
+// VisibleTo 判断 viewer 能否查看并使用模板。
+func (t *Template) VisibleTo(viewer Viewer) bool {
+	if !t.IsPrivate || viewer.Role == auth.RoleAdmin {
+		return true
+	}
+	if viewer.ID == 0 {
+		return false
+	}
+	return (t.CreatedBy != nil && *t.CreatedBy == viewer.ID) ||
+		slices.Contains(t.UserIDs, viewer.ID) || slices.Contains(t.Roles, viewer.Role)
+}
+
+const templateColumns = "template_id, name, description, content, is_private, created_by_user_id, created_at, updated_at"
+
+func scanTemplate(row scanner) (*Template, error) {
+	tmpl := &Template{UserIDs: []int64{}, Roles: []string{}}
+	err := row.Scan(&tmpl.ID, &tmpl.Name, &tmpl.Description, &tmpl.Content, &tmpl.IsPrivate,
+		&tmpl.CreatedBy, &tmpl.CreatedAt, &tmpl.UpdatedAt)
+	if err != nil {
+		return nil, notFound(err)
+	}
+	return tmpl, nil
+}
+
+// CreateTemplate 写入模板及其授权对象，名称已存在时返回 ErrDuplicate。
+func (s *Store) CreateTemplate(ctx context.Context, tmpl *Template) error {
+	now := time.Now().UTC()
+	tmpl.CreatedAt, tmpl.UpdatedAt = now, now
+	return s.WithTx(ctx, func(tx *Store) error {
+		id, err := tx.insert(ctx, "template_id",
+			"INSERT INTO doc_templates (name, description, content, is_private, created_by_user_id, created_at, updated_at) VALUES (?, ?, ?, ?, ?, ?, ?)",
+			tmpl.Name, tmpl.Description, tmpl.Content, tmpl.IsPrivate, tmpl.CreatedBy, tmpl.CreatedAt, tmpl.UpdatedAt)
+		if err != nil {
+			return err
+		}
+		tmpl.ID = id
+		return tx.insertTemplateGrants(ctx, tmpl)
+	})
+}
+
+// UpdateTemplate 保存名称、说明、内容与可见范围，授权对象整体替换；名称已存在时返回 ErrDuplicate。
+func (s *Store) UpdateTemplate(ctx context.Context, tmpl *Template) error {
+	tmpl.UpdatedAt = time.Now().UTC()
+	return s.WithTx(ctx, func(tx *Store) error {
+		result, err := tx.exec(ctx,
+			"UPDATE doc_templates SET name = ?, description = ?, content = ?, is_private = ?, updated_at = ? WHERE template_id = ?",
+			tmpl.Name, tmpl.Description, tmpl.Content, tmpl.IsPrivate, tmpl.UpdatedAt, tmpl.ID)
+		if err != nil {
+			return err
+		}
+		if err := requireAffected(result); err != nil {
+			return err
+		}
+		if _, err := tx.exec(ctx, "DELETE FROM doc_template_grants WHERE template_id = ?", tmpl.ID); err != nil {
+			return err
+		}
+		return tx.insertTemplateGrants(ctx, tmpl)
+	})
+}
+
+func (s *Store) insertTemplateGrants(ctx context.Context, tmpl *Template) error {
+	for _, userID := range tmpl.UserIDs {
+		_, err := s.exec(ctx, "INSERT INTO doc_template_grants (template_id, user_id, grantee) VALUES (?, ?, ?)",
+			tmpl.ID, userID, "user:"+strconv.FormatInt(userID, 10))
+		if err != nil {
+			return err
+		}
+	}
+	for _, role := range tmpl.Roles {
+		_, err := s.exec(ctx, "INSERT INTO doc_template_grants (template_id, role, grantee) VALUES (?, ?, ?)",
+			tmpl.ID, role, "role:"+role)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// DeleteTemplate 删除模板，授权对象由外键级联删除；已用模板创建的文档不受影响。
+func (s *Store) DeleteTemplate(ctx context.Context, id int64) error {
+	result, err := s.exec(ctx, "DELETE FROM doc_templates WHERE template_id = ?", id)
+	if err != nil {
+		return err
+	}
+	return requireAffected(result)
+}
+
+func (s *Store) GetTemplate(ctx context.Context, id int64) (*Template, error) {
+	tmpl, err := scanTemplate(s.queryRow(ctx, "SELECT "+templateColumns+" FROM doc_templates WHERE template_id = ?", id))
+	if err != nil {
+		return nil, err
+	}
+	if err := s.loadTemplateGrants(ctx, map[int64]*Template{tmpl.ID: tmpl}, " WHERE template_id = ?", tmpl.ID); err != nil {
+		return nil, err
+	}
+	return tmpl, nil
+}
+
+// ListTemplates 按名称返回 viewer 可见的全部模板。模板数量有限，可见性在读出授权对象后判断。
+func (s *Store) ListTemplates(ctx context.Context, viewer Viewer) ([]Template, error) {
+	rows, err := s.query(ctx, "SELECT "+templateColumns+" FROM doc_templates ORDER BY name")
+	if err != nil {
+		return nil, err
+	}
+	var all []*Template
+	byID := map[int64]*Template{}
+	for rows.Next() {
+		tmpl, err := scanTemplate(rows)
+		if err != nil {
+			rows.Close()
+			return nil, err
+		}
+		all = append(all, tmpl)
+		byID[tmpl.ID] = tmpl
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	if err := s.loadTemplateGrants(ctx, byID, ""); err != nil {
+		return nil, err
+	}
+
+	templates := []Template{}
+	for _, tmpl := range all {
+		if tmpl.VisibleTo(viewer) {
+			templates = append(templates, *tmpl)
+		}
+	}
+	return templates, nil
+}
+
+// loadTemplateGrants 把 where 条件选中的授权对象填入 templates 中对应的模板。
+func (s *Store) loadTemplateGrants(ctx context.Context, templates map[int64]*Template, where string, args ...any) error {
+	rows, err := s.query(ctx,
+		"SELECT template_id, user_id, COALESCE(role, '') FROM doc_template_grants"+where+" ORDER BY template_id, grantee", args...)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var (
+			templateID int64
+			userID     *int64
+			role       string
+		)
+		if err := rows.Scan(&templateID, &userID, &role); err != nil {
+			return err
+		}
+		tmpl, ok := templates[templateID]
+		if !ok {
+			continue
+		}
+		if userID != nil {
+			tmpl.UserIDs = append(tmpl.UserIDs, *userID)
+		} else {
+			tmpl.Roles = append(tmpl.Roles, role)
+		}
+	}
+	return rows.Err()
+}
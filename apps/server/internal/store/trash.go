@@ -0,0 +1,159 @@
+package store
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/lifei6671/plaindoc/apps/server/internal/auth"
+)
+
+// ErrHasChildren 表示文档下仍有子文档（包括回收站中的），不能彻底删除。
+var ErrHasChildren = errors.New("document still has child documents")
+
+// maxPurgeRounds 限制清理过期回收站时由叶子向上逐层删除的轮数。
+const maxPurgeRounds = maxTreeDepth
+
+// TrashFilter 描述回收站列表的过滤与分页条件。
+type TrashFilter struct {
+	Space string
+	// Viewer 非管理员时只返回可访问空间中自己创建或删除的文档。
+	Viewer Viewer
+	Limit  int
+	Offset int
+}
+
+// TrashDocument 把文档移入回收站；deleted_key 写入 doc_id 以释放 slug，指向它的内部链接变为断链。
+func (s *Store) TrashDocument(ctx context.Context, id int64, userID int64) error {
+	return s.WithTx(ctx, func(tx *Store) error {
+		result, err := tx.exec(ctx,
+			"UPDATE docs SET deleted_at = ?, deleted_by_user_id = ?, deleted_key = doc_id WHERE doc_id = ? AND deleted_at IS NULL",
+			time.Now().UTC(), userID, id)
+		if err != nil {
+			return err
+		}
+		if err := requireAffected(result); err != nil {
+			return err
+		}
+		return tx.unlinkDocument(ctx, id)
+	})
+}
+
+func (s *Store) GetTrashedDocument(ctx context.Context, id int64) (*Document, error) {
+	return scanDocument(s.queryRow(ctx, "SELECT "+documentColumns+" FROM docs WHERE doc_id = ? AND deleted_at IS NOT NULL", id))
+}
+
+// ListTrash 按删除时间倒序返回一页回收站中的文档（不含正文）及总数。
+func (s *Store) ListTrash(ctx context.Context, filter TrashFilter) ([]Document, int, error) {
+	conditions, args := []string{"deleted_at IS NOT NULL"}, []any{}
+	if filter.Space != "" {
+		conditions = append(conditions, "space = ?")
+		args = append(args, filter.Space)
+	}
+	if filter.Viewer.Role != auth.RoleAdmin {
+		condition, conditionArgs := spaceCondition(filter.Viewer)
+		conditions = append(conditions, condition, "(author_id = ? OR deleted_by_user_id = ?)")
+		args = append(append(args, conditionArgs...), filter.Viewer.ID, filter.Viewer.ID)
+	}
+	where := " WHERE " + strings.Join(conditions, " AND ")
+
+	var total int
+	if err := s.queryRow(ctx, "SELECT COUNT(*) FROM docs"+where, args...).Scan(&total); err != nil {
+		return nil, 0, err
+	}
+	rows, err := s.query(ctx,
+		"SELECT "+documentSummaryColumns+" FROM docs"+where+" ORDER BY deleted_at DESC, doc_id DESC LIMIT ? OFFSET ?",
+		append(args, filter.Limit, filter.Offset)...)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer rows.Close()
+
+	docs := []Document{}
+	for rows.Next() {
+		doc, err := scanDocument(rows)
+		if err != nil {
+			return nil, 0, err
+		}
+		docs = append(docs, *doc)
+	}
+	return docs, total, rows.Err()
+}
+
+// RestoreDocument 把回收站中的文档恢复到原位置；父文档已不在原处（仍在回收站）时恢复到顶层末尾，按 slug 指向它的断链重新接上。
+// 原 slug 已被新文档占用时返回 ErrDuplicate。
+func (s *Store) RestoreDocument(ctx context.Context, doc *Document) error {
+	return s.WithTx(ctx, func(tx *Store) error {
+		if doc.ParentID != nil {
+			_, err := tx.GetDocument(ctx, *doc.ParentID)
+			if errors.Is(err, ErrNotFound) {
+				order, err := tx.nextSortOrder(ctx, doc.Space, nil)
+				if err != nil {
+					return err
+				}
+				doc.ParentID, doc.SortOrder = nil, order
+			} else if err != nil {
+				return err
+			}
+		}
+
+		result, err := tx.exec(ctx,
+			"UPDATE docs SET parent_id = ?, sort_order = ?, deleted_at = NULL, deleted_by_user_id = NULL, deleted_key = 0 WHERE doc_id = ? AND deleted_at IS NOT NULL",
+			doc.ParentID, doc.SortOrder, doc.ID)
+		if err != nil {
+			return err
+		}
+		if err := requireAffected(result); err != nil {
+			return err
+		}
+		doc.DeletedAt, doc.DeletedBy = nil, nil
+		if err := tx.relinkDocument(ctx, doc); err != nil {
+			return err
+		}
+		return tx.refreshACL(ctx, doc)
+	})
+}
+
+// PurgeDocument 彻底删除回收站中的文档及其版本与授权；仍有子文档时返回 ErrHasChildren。
+func (s *Store) PurgeDocument(ctx context.Context, id int64) error {
+	return s.WithTx(ctx, func(tx *Store) error {
+		var children int
+		if err := tx.queryRow(ctx, "SELECT COUNT(*) FROM docs WHERE parent_id = ?", id).Scan(&children); err != nil {
+			return err
+		}
+		if children > 0 {
+			return ErrHasChildren
+		}
+		result, err := tx.exec(ctx, "DELETE FROM docs WHERE doc_id = ? AND deleted_at IS NOT NULL", id)
+		if err != nil {
+			return err
+		}
+		return requireAffected(result)
+	})
+}
+
+// PurgeTrashBefore 彻底删除 before 之前移入回收站的文档，返回删除的数量。
+// 每轮只删除没有子文档的节点，由叶子向上逐层进行；子文档尚未过期的父文档会保留到下次清理。
+func (s *Store) PurgeTrashBefore(ctx context.Context, before time.Time) (int, error) {
+	var purged int
+	for round := 0; round < maxPurgeRounds; round++ {
+		// 派生表让 MySQL 先物化子查询，避免 DELETE 引用自身表时的 Error 1093。
+		result, err := s.exec(ctx,
+			"DELETE FROM docs WHERE deleted_at IS NOT NULL AND deleted_at < ?"+
+				" AND doc_id NOT IN (SELECT parent_id FROM (SELECT parent_id FROM docs WHERE parent_id IS NOT NULL) AS parents)",
+			before)
+		if err != nil {
+			return purged, err
+		}
+		affected, err := result.RowsAffected()
+		if err != nil {
+			return purged, err
+		}
+		if affected == 0 {
+			break
+		}
+		purged += int(affected)
+	}
+	return purged, nil
+}
@@ -0,0 +1,37 @@
+package store
+
+import (
+	"context"
+	"time"
+)
+
+// UserIdentity 记录本地用户绑定的第三方账号。
+type UserIdentity struct {
+	ID        int64     `json:"id"`
+	UserID    int64     `json:"user_id"`
+	Provider  string    `json:"provider"`
+	Subject   string    `json:"subject"`
+	Email     string    `json:"email"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// GetUserByIdentity 按第三方账号查找已绑定的本地用户，未绑定时返回 ErrNotFound。
+func (s *Store) GetUserByIdentity(ctx context.Context, provider string, subject string) (*User, error) {
+	return scanUser(s.queryRow(ctx,
+		"SELECT "+userColumns+" FROM users WHERE user_id = (SELECT user_id FROM user_identities WHERE provider = ? AND subject = ?)",
+		provider, subject))
+}
+
+// CreateUserIdentity 绑定第三方账号，同一账号已绑定其他用户时返回 ErrDuplicate。
+func (s *Store) CreateUserIdentity(ctx context.Context, identity *UserIdentity) error {
+	identity.Email = NormalizeEmail(identity.Email)
+	identity.CreatedAt = time.Now().UTC()
+	id, err := s.insert(ctx, "identity_id",
+		"INSERT INTO user_identities (user_id, provider, subject, email, created_at) VALUES (?, ?, ?, ?, ?)",
+		identity.UserID, identity.Provider, identity.Subject, identity.Email, identity.CreatedAt)
+	if err != nil {
+		return err
+	}
+	identity.ID = id
+	return nil
+}
@@ -0,0 +1,97 @@
+package store
+
+import (
+	"context"
+	"time"
+)
+
+// BeginTOTPSetup 为尚未开启两步验证的用户写入待绑定的密钥，重复调用会替换之前未完成绑定的密钥；
+// 用户已开启两步验证时返回 ErrNotFound。
+func (s *Store) BeginTOTPSetup(ctx context.Context, userID int64, secret string) error {
+	result, err := s.exec(ctx, "UPDATE users SET totp_secret = ?, updated_at = ? WHERE user_id = ? AND totp_enabled_at IS NULL",
+		secret, time.Now().UTC(), userID)
+	if err != nil {
+		return err
+	}
+	return requireAffected(result)
+}
+
+// EnableTOTP 完成绑定：记录开启时间与本次验证码的时间步，并用 codeHashes 替换全部恢复码。
+// 用户已开启两步验证或密钥已被替换时返回 ErrNotFound。
+func (s *Store) EnableTOTP(ctx context.Context, userID int64, secret string, step int64, codeHashes []string) error {
+	return s.WithTx(ctx, func(tx *Store) error {
+		now := time.Now().UTC()
+		result, err := tx.exec(ctx, `UPDATE users SET totp_enabled_at = ?, totp_last_step = ?, updated_at = ?
+WHERE user_id = ? AND totp_secret = ? AND totp_enabled_at IS NULL`, now, step, now, userID, secret)
+		if err != nil {
+			return err
+		}
+		if err := requireAffected(result); err != nil {
+			return err
+		}
+		return tx.ReplaceRecoveryCodes(ctx, userID, codeHashes)
+	})
+}
+
+// ReplaceRecoveryCodes 删除用户的全部恢复码（包括未使用的）并写入 codeHashes。
+func (s *Store) ReplaceRecoveryCodes(ctx context.Context, userID int64, codeHashes []string) error {
+	return s.WithTx(ctx, func(tx *Store) error {
+		if _, err := tx.exec(ctx, "DELETE FROM user_recovery_codes WHERE user_id = ?", userID); err != nil {
+			return err
+		}
+		now := time.Now().UTC()
+		for _, hash := range codeHashes {
+			if _, err := tx.exec(ctx, "INSERT INTO user_recovery_codes (user_id, code_hash, created_at) VALUES (?, ?, ?)",
+				userID, hash, now); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// UseTOTPStep 记录通过校验的时间步；step 不晚于上次记录时返回 false，表示验证码被重复使用。
+func (s *Store) UseTOTPStep(ctx context.Context, userID int64, step int64) (bool, error) {
+	// 条件更新让并发提交的同一个验证码只有一次成功。
+	result, err := s.exec(ctx, "UPDATE users SET totp_last_step = ? WHERE user_id = ? AND totp_enabled_at IS NOT NULL AND totp_last_step < ?",
+		step, userID, step)
+	if err != nil {
+		return false, err
+	}
+	affected, err := result.RowsAffected()
+	return affected > 0, err
+}
+
+// UseRecoveryCode 作废一个未使用的恢复码，摘要不存在或已使用时返回 false。
+func (s *Store) UseRecoveryCode(ctx context.Context, userID int64, codeHash string) (bool, error) {
+	result, err := s.exec(ctx, "UPDATE user_recovery_codes SET used_at = ? WHERE user_id = ? AND code_hash = ? AND used_at IS NULL",
+		time.Now().UTC(), userID, codeHash)
+	if err != nil {
+		return false, err
+	}
+	affected, err := result.RowsAffected()
+	return affected > 0, err
+}
+
+// CountRecoveryCodes 返回用户剩余可用的恢复码个数。
+func (s *Store) CountRecoveryCodes(ctx context.Context, userID int64) (int, error) {
+	var count int
+	err := s.queryRow(ctx, "SELECT COUNT(*) FROM user_recovery_codes WHERE user_id = ? AND used_at IS NULL", userID).Scan(&count)
+	return count, err
+}
+
+// DisableTOTP 关闭两步验证并删除全部恢复码。
+func (s *Store) DisableTOTP(ctx context.Context, userID int64) error {
+	return s.WithTx(ctx, func(tx *Store) error {
+		result, err := tx.exec(ctx, "UPDATE users SET totp_secret = '', totp_enabled_at = NULL, totp_last_step = 0, updated_at = ? WHERE user_id = ?",
+			time.Now().UTC(), userID)
+		if err != nil {
+			return err
+		}
+		if err := requireAffected(result); err != nil {
+			return err
+		}
+		_, err = tx.exec(ctx, "DELETE FROM user_recovery_codes WHERE user_id = ?", userID)
+		return err
+	})
+}
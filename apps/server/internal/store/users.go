@@ -0,0 +1,143 @@
+package store
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/lifei6671/plaindoc/apps/server/internal/auth"
+)
+
+// ErrLastAdmin 表示操作会让系统中不再有管理员。
+var ErrLastAdmin = errors.New("at least one admin must remain")
+
+type User struct {
+	ID               int64      `json:"id"`
+	Email            string     `json:"email"`
+	EmailVerifiedAt  *time.Time `json:"email_verified_at"`
+	Name             string     `json:"name"`
+	Role             string     `json:"role"`
+	Theme            string     `json:"theme"`
+	PasswordHash     string     `json:"-"`
+	TOTPSecret       string     `json:"-"`
+	TOTPEnabledAt    *time.Time `json:"-"`
+	TOTPLastStep     int64      `json:"-"`
+	TwoFactorEnabled bool       `json:"two_factor_enabled"`
+	CreatedAt        time.Time  `json:"created_at"`
+	UpdatedAt        time.Time  `json:"updated_at"`
+}
+
+const userColumns = "user_id, email, email_verified_at, name, role, theme, password_hash, totp_secret, totp_enabled_at, totp_last_step, created_at, updated_at"
+
+func scanUser(row scanner) (*User, error) {
+	user := &User{}
+	err := row.Scan(&user.ID, &user.Email, &user.EmailVerifiedAt, &user.Name, &user.Role, &user.Theme, &user.PasswordHash,
+		&user.TOTPSecret, &user.TOTPEnabledAt, &user.TOTPLastStep, &user.CreatedAt, &user.UpdatedAt)
+	if err != nil {
+		return nil, notFound(err)
+	}
+	user.TwoFactorEnabled = user.TOTPEnabledAt != nil
+	return user, nil
+}
+
+// NormalizeEmail 统一邮箱大小写与首尾空白，保证唯一索引语义一致。
+func NormalizeEmail(email string) string {
+	return strings.ToLower(strings.TrimSpace(email))
+}
+
+// CreateUser 写入新用户，邮箱已存在时返回 ErrDuplicate；EmailVerifiedAt 由调用方决定。
+func (s *Store) CreateUser(ctx context.Context, user *User) error {
+	now := time.Now().UTC()
+	user.Email = NormalizeEmail(user.Email)
+	user.CreatedAt, user.UpdatedAt = now, now
+
+	id, err := s.insert(ctx, "user_id",
+		"INSERT INTO users (email, email_verified_at, name, role, password_hash, created_at, updated_at) VALUES (?, ?, ?, ?, ?, ?, ?)",
+		user.Email, user.EmailVerifiedAt, user.Name, user.Role, user.PasswordHash, user.CreatedAt, user.UpdatedAt)
+	if err != nil {
+		return err
+	}
+	user.ID = id
+	return nil
+}
+
+func (s *Store) GetUser(ctx context.Context, id int64) (*User, error) {
+	return scanUser(s.queryRow(ctx, "SELECT "+userColumns+" FROM users WHERE user_id = ?", id))
+}
+
+func (s *Store) GetUserByEmail(ctx context.Context, email string) (*User, error) {
+	return scanUser(s.queryRow(ctx, "SELECT "+userColumns+" FROM users WHERE email = ?", NormalizeEmail(email)))
+}
+
+// CountUsersByRole 统计指定角色的用户数。
+func (s *Store) CountUsersByRole(ctx context.Context, role string) (int, error) {
+	var count int
+	err := s.queryRow(ctx, "SELECT COUNT(*) FROM users WHERE role = ?", role).Scan(&count)
+	return count, err
+}
+
+// ResetUserCredentials 重置用户密码与角色。
+func (s *Store) ResetUserCredentials(ctx context.Context, id int64, passwordHash string, role string) error {
+	result, err := s.exec(ctx, "UPDATE users SET password_hash = ?, role = ?, updated_at = ? WHERE user_id = ?",
+		passwordHash, role, time.Now().UTC(), id)
+	if err != nil {
+		return err
+	}
+	return requireAffected(result)
+}
+
+// ListUsers 按注册时间返回一页用户及总数。
+func (s *Store) ListUsers(ctx context.Context, limit int, offset int) ([]User, int, error) {
+	var total int
+	if err := s.queryRow(ctx, "SELECT COUNT(*) FROM users").Scan(&total); err != nil {
+		return nil, 0, err
+	}
+
+	rows, err := s.query(ctx, "SELECT "+userColumns+" FROM users ORDER BY user_id LIMIT ? OFFSET ?", limit, offset)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer rows.Close()
+
+	users := []User{}
+	for rows.Next() {
+		user, err := scanUser(rows)
+		if err != nil {
+			return nil, 0, err
+		}
+		users = append(users, *user)
+	}
+	return users, total, rows.Err()
+}
+
+// UpdateUserTheme 保存用户的偏好主题，空串表示跟随全站默认。
+func (s *Store) UpdateUserTheme(ctx context.Context, id int64, theme string) error {
+	result, err := s.exec(ctx, "UPDATE users SET theme = ?, updated_at = ? WHERE user_id = ?", theme, time.Now().UTC(), id)
+	if err != nil {
+		return err
+	}
+	return requireAffected(result)
+}
+
+// UpdateUserRole 修改用户角色；把最后一个管理员改为其他角色时返回 ErrLastAdmin。
+func (s *Store) UpdateUserRole(ctx context.Context, id int64, role string) error {
+	// 管理员数量检查与更新放在同一条语句中，避免并发降级时两人都通过检查。
+	// 子查询多包一层派生表，绕开 MySQL 不允许在 UPDATE 中直接查询同一张表的限制（Error 1093）。
+	result, err := s.exec(ctx, `UPDATE users SET role = ?, updated_at = ?
+WHERE user_id = ? AND (role <> ? OR ? = ?
+  OR (SELECT COUNT(*) FROM (SELECT user_id FROM users WHERE role = ?) AS admins) > 1)`,
+		role, time.Now().UTC(), id, auth.RoleAdmin, role, auth.RoleAdmin, auth.RoleAdmin)
+	if err != nil {
+		return err
+	}
+	if affected, err := result.RowsAffected(); err != nil || affected > 0 {
+		return err
+	}
+
+	// 未更新任何行：要么用户不存在，要么被最后一个管理员的保护条件拦下。
+	if _, err := s.GetUser(ctx, id); err != nil {
+		return err
+	}
+	return ErrLastAdmin
+}
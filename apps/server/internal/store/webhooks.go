@@ -0,0 +1,245 @@
+package store
+
+import (
+	"context"
+	"slices"
+	"strings"
+	"time"
+)
+
+// Webhook 投递状态。
+const (
+	DeliveryPending   = "pending"
+	DeliverySucceeded = "succeeded"
+	DeliveryFailed    = "failed"
+)
+
+// Webhook 是管理员注册的事件订阅；Secret 只在创建时返回一次。
+type Webhook struct {
+	ID        int64     `json:"id"`
+	URL       string    `json:"url"`
+	Secret    string    `json:"-"`
+	Events    []string  `json:"events"`
+	IsActive  bool      `json:"is_active"`
+	CreatedBy *int64    `json:"created_by"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// Subscribes 判断 webhook 是否订阅了 event。
+func (w *Webhook) Subscribes(event string) bool {
+	return slices.Contains(w.Events, event)
+}
+
+// WebhookDelivery 是一次事件向某个 webhook 的投递；Attempts 为已发起的请求次数，
+// ResponseStatus 与 LastError 记录最近一次请求的结果。
+type WebhookDelivery struct {
+	ID             int64      `json:"id"`
+	WebhookID      int64      `json:"webhook_id"`
+	Event          string     `json:"event"`
+	Payload        string     `json:"payload"`
+	Status         string     `json:"status"`
+	Attempts       int        `json:"attempts"`
+	ResponseStatus *int       `json:"response_status"`
+	LastError      string     `json:"last_error"`
+	NextAttemptAt  *time.Time `json:"next_attempt_at"`
+	CreatedAt      time.Time  `json:"created_at"`
+	UpdatedAt      time.Time  `json:"updated_at"`
+}
+
+// WebhookDeliveryFilter 描述某个 webhook 投递记录的分页条件。
+type WebhookDeliveryFilter struct {
+	WebhookID int64
+	Limit     int
+	Offset    int
+}
+
+const (
+	webhookColumns         = "webhook_id, url, secret, events, is_active, created_by_user_id, created_at, updated_at"
+	webhookDeliveryColumns = "delivery_id, webhook_id, event, payload, status, attempts, response_status, last_error, next_attempt_at, created_at, updated_at"
+)
+
+func scanWebhook(row scanner) (*Webhook, error) {
+	webhook := &Webhook{}
+	var events string
+	err := row.Scan(&webhook.ID, &webhook.URL, &webhook.Secret, &events, &webhook.IsActive,
+		&webhook.CreatedBy, &webhook.CreatedAt, &webhook.UpdatedAt)
+	if err != nil {
+		return nil, notFound(err)
+	}
+	webhook.Events = strings.Split(events, ",")
+	return webhook, nil
+}
+
+func scanWebhookDelivery(row scanner) (*WebhookDelivery, error) {
+	delivery := &WebhookDelivery{}
+	err := row.Scan(&delivery.ID, &delivery.WebhookID, &delivery.Event, &delivery.Payload, &delivery.Status,
+		&delivery.Attempts, &delivery.ResponseStatus, &delivery.LastError, &delivery.NextAttemptAt,
+		&delivery.CreatedAt, &delivery.UpdatedAt)
+	if err != nil {
+		return nil, notFound(err)
+	}
+	return delivery, nil
+}
+
+func (s *Store) CreateWebhook(ctx context.Context, webhook *Webhook) error {
+	now := time.Now().UTC()
+	webhook.CreatedAt, webhook.UpdatedAt = now, now
+	id, err := s.insert(ctx, "webhook_id",
+		"INSERT INTO webhooks (url, secret, events, is_active, created_by_user_id, created_at, updated_at) VALUES (?, ?, ?, ?, ?, ?, ?)",
+		webhook.URL, webhook.Secret, strings.Join(webhook.Events, ","), webhook.IsActive, webhook.CreatedBy,
+		webhook.CreatedAt, webhook.UpdatedAt)
+	if err != nil {
+		return err
+	}
+	webhook.ID = id
+	return nil
+}
+
+func (s *Store) GetWebhook(ctx context.Context, id int64) (*Webhook, error) {
+	return scanWebhook(s.queryRow(ctx, "SELECT "+webhookColumns+" FROM webhooks WHERE webhook_id = ?", id))
+}
+
+func (s *Store) ListWebhooks(ctx context.Context) ([]Webhook, error) {
+	rows, err := s.query(ctx, "SELECT "+webhookColumns+" FROM webhooks ORDER BY webhook_id")
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	webhooks := []Webhook{}
+	for rows.Next() {
+		webhook, err := scanWebhook(rows)
+		if err != nil {
+			return nil, err
+		}
+		webhooks = append(webhooks, *webhook)
+	}
+	return webhooks, rows.Err()
+}
+
+// UpdateWebhook 保存地址、密钥、订阅事件与启用状态。
+func (s *Store) UpdateWebhook(ctx context.Context, webhook *Webhook) error {
+	webhook.UpdatedAt = time.Now().UTC()
+	result, err := s.exec(ctx,
+		"UPDATE webhooks SET url = ?, secret = ?, events = ?, is_active = ?, updated_at = ? WHERE webhook_id = ?",
+		webhook.URL, webhook.Secret, strings.Join(webhook.Events, ","), webhook.IsActive, webhook.UpdatedAt, webhook.ID)
+	if err != nil {
+		return err
+	}
+	return requireAffected(result)
+}
+
+// DeleteWebhook 删除 webhook，其投递记录由外键级联删除。
+func (s *Store) DeleteWebhook(ctx context.Context, id int64) error {
+	result, err := s.exec(ctx, "DELETE FROM webhooks WHERE webhook_id = ?", id)
+	if err != nil {
+		return err
+	}
+	return requireAffected(result)
+}
+
+// EnqueueWebhookEvent 为每个订阅了 event 的启用中的 webhook 写入一条待投递记录，返回写入的条数。
+func (s *Store) EnqueueWebhookEvent(ctx context.Context, event string, payload string) (int, error) {
+	webhooks, err := s.ListWebhooks(ctx)
+	if err != nil {
+		return 0, err
+	}
+	now := time.Now().UTC()
+	queued := 0
+	err = s.WithTx(ctx, func(tx *Store) error {
+		for _, webhook := range webhooks {
+			if !webhook.IsActive || !webhook.Subscribes(event) {
+				continue
+			}
+			_, err := tx.exec(ctx,
+				"INSERT INTO webhook_deliveries (webhook_id, event, payload, status, next_attempt_at, created_at, updated_at) VALUES (?, ?, ?, ?, ?, ?, ?)",
+				webhook.ID, event, payload, DeliveryPending, now, now, now)
+			if err != nil {
+				return err
+			}
+			queued++
+		}
+		return nil
+	})
+	return queued, err
+}
+
+// ListDueWebhookDeliveries 返回 next_attempt_at 不晚于 now 的待投递记录，最早的在前。
+func (s *Store) ListDueWebhookDeliveries(ctx context.Context, now time.Time, limit int) ([]WebhookDelivery, error) {
+	return s.listWebhookDeliveries(ctx,
+		" WHERE status = ? AND next_attempt_at <= ? ORDER BY next_attempt_at, delivery_id LIMIT ?",
+		DeliveryPending, now, limit)
+}
+
+// ClaimWebhookDelivery 把一条到期的投递记录的下次投递时间推迟到 leaseUntil 并累加尝试次数，
+// 以 attempts 作为版本号保证多个实例中只有一个能认领成功；认领成功后即使进程退出，租约到期后也会被重新投递。
+func (s *Store) ClaimWebhookDelivery(ctx context.Context, delivery *WebhookDelivery, leaseUntil time.Time) (bool, error) {
+	now := time.Now().UTC()
+	result, err := s.exec(ctx,
+		"UPDATE webhook_deliveries SET attempts = attempts + 1, next_attempt_at = ?, updated_at = ? WHERE delivery_id = ? AND status = ? AND attempts = ?",
+		leaseUntil, now, delivery.ID, DeliveryPending, delivery.Attempts)
+	if err != nil {
+		return false, err
+	}
+	affected, err := result.RowsAffected()
+	if err != nil || affected == 0 {
+		return false, err
+	}
+	delivery.Attempts++
+	delivery.NextAttemptAt = &leaseUntil
+	delivery.UpdatedAt = now
+	return true, nil
+}
+
+// FinishWebhookDeliveryAttempt 记录一次投递请求的结果：Status 仍为 pending 时按 NextAttemptAt 重试。
+func (s *Store) FinishWebhookDeliveryAttempt(ctx context.Context, delivery *WebhookDelivery) error {
+	delivery.UpdatedAt = time.Now().UTC()
+	result, err := s.exec(ctx,
+		"UPDATE webhook_deliveries SET status = ?, response_status = ?, last_error = ?, next_attempt_at = ?, updated_at = ? WHERE delivery_id = ?",
+		delivery.Status, delivery.ResponseStatus, delivery.LastError, delivery.NextAttemptAt, delivery.UpdatedAt, delivery.ID)
+	if err != nil {
+		return err
+	}
+	return requireAffected(result)
+}
+
+// ListWebhookDeliveries 按时间倒序返回某个 webhook 的一页投递记录及总数。
+func (s *Store) ListWebhookDeliveries(ctx context.Context, filter WebhookDeliveryFilter) ([]WebhookDelivery, int, error) {
+	var total int
+	if err := s.queryRow(ctx, "SELECT COUNT(*) FROM webhook_deliveries WHERE webhook_id = ?",
+		filter.WebhookID).Scan(&total); err != nil {
+		return nil, 0, err
+	}
+	deliveries, err := s.listWebhookDeliveries(ctx,
+		" WHERE webhook_id = ? ORDER BY delivery_id DESC LIMIT ? OFFSET ?",
+		filter.WebhookID, filter.Limit, filter.Offset)
+	return deliveries, total, err
+}
+
+// PurgeWebhookDeliveriesBefore 删除 before 之前已结束（成功或最终失败）的投递记录，返回删除条数。
+func (s *Store) PurgeWebhookDeliveriesBefore(ctx context.Context, before time.Time) (int64, error) {
+	result, err := s.exec(ctx, "DELETE FROM webhook_deliveries WHERE status <> ? AND updated_at < ?", DeliveryPending, before)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+func (s *Store) listWebhookDeliveries(ctx context.Context, where string, args ...any) ([]WebhookDelivery, error) {
+	rows, err := s.query(ctx, "SELECT "+webhookDeliveryColumns+" FROM webhook_deliveries"+where, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	deliveries := []WebhookDelivery{}
+	for rows.Next() {
+		delivery, err := scanWebhookDelivery(rows)
+		if err != nil {
+			return nil, err
+		}
+		deliveries = append(deliveries, *delivery)
+	}
+	return deliveries, rows.Err()
+}
@@ -0,0 +1,41 @@
+// Package theme 定义站点可选的界面主题。
+package theme
+
+// Theme 描述一套界面主题；前端按 PrimaryColor 与 Dark 生成配色，LogoURL 为空时使用内置 logo。
+type Theme struct {
+	ID           string `json:"id"`
+	Name         string `json:"name"`
+	PrimaryColor string `json:"primary_color"`
+	LogoURL      string `json:"logo_url"`
+	Dark         bool   `json:"dark"`
+}
+
+// DefaultID 是未做任何配置时的全站主题。
+const DefaultID = "light"
+
+// Builtin 是内置主题列表，顺序即前端展示顺序。
+var Builtin = []Theme{
+	{ID: "light", Name: "Light", PrimaryColor: "#2563eb"},
+	{ID: "dark", Name: "Dark", PrimaryColor: "#60a5fa", Dark: true},
+	{ID: "forest", Name: "Forest", PrimaryColor: "#15803d"},
+	{ID: "midnight", Name: "Midnight", PrimaryColor: "#a78bfa", Dark: true},
+}
+
+// Lookup 按 id 查找内置主题。
+func Lookup(id string) (Theme, bool) {
+	for _, t := range Builtin {
+		if t.ID == id {
+			return t, true
+		}
+	}
+	return Theme{}, false
+}
+
+// IDs 返回全部内置主题的 id。
+func IDs() []string {
+	ids := make([]string, 0, len(Builtin))
+	for _, t := range Builtin {
+		ids = append(ids, t.ID)
+	}
+	return ids
+}
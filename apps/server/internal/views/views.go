@@ -0,0 +1,127 @@
+// Package views 统计文档浏览量：读取文档时只在内存中去重与累加，由后台任务定期批量写入 doc_views，
+// 统计本身不会阻塞或影响文档读取。去重记录保存在进程内，多实例部署时同一访问者在每个实例各计一次。
+package views
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"sync"
+	"time"
+
+	"github.com/lifei6671/plaindoc/apps/server/internal/store"
+)
+
+// RetentionDays 是每日浏览量的保留天数，也是热门文档支持的最长统计周期。
+const RetentionDays = 90
+
+const (
+	flushInterval = time.Minute
+	// maxVisitors 限制当天去重记录的条数，超过后当天不再计入新的访问，防止刷量占满内存。
+	maxVisitors = 200000
+)
+
+type visit struct {
+	docID   int64
+	visitor string
+}
+
+// Counter 在内存中按天累加浏览量，可并发使用。
+type Counter struct {
+	store  *store.Store
+	logger *slog.Logger
+
+	mu      sync.Mutex
+	day     time.Time
+	seen    map[visit]struct{}
+	pending map[time.Time]map[int64]int64
+}
+
+func New(s *store.Store, logger *slog.Logger) *Counter {
+	return &Counter{
+		store:   s,
+		logger:  logger,
+		seen:    make(map[visit]struct{}),
+		pending: make(map[time.Time]map[int64]int64),
+	}
+}
+
+// Today 返回当前 UTC 日期，浏览量按 UTC 日期汇总。
+func Today() time.Time {
+	return time.Now().UTC().Truncate(24 * time.Hour)
+}
+
+// Record 记录一次浏览，visitor 标识访问者（如用户 ID 或 IP）；同一访问者当天重复浏览同一文档只计一次。
+func (c *Counter) Record(docID int64, visitor string) {
+	day := Today()
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if !day.Equal(c.day) {
+		c.day = day
+		c.seen = make(map[visit]struct{})
+	}
+	key := visit{docID: docID, visitor: visitor}
+	if _, ok := c.seen[key]; ok || len(c.seen) >= maxVisitors {
+		return
+	}
+	c.seen[key] = struct{}{}
+	c.add(day, docID, 1)
+}
+
+// Run 每分钟把累加的浏览量写入数据库，每天清理一次超过 RetentionDays 的数据，直到 ctx 取消。
+func (c *Counter) Run(ctx context.Context) {
+	ticker := time.NewTicker(flushInterval)
+	defer ticker.Stop()
+	lastPurge := time.Time{}
+
+	for {
+		if err := c.Flush(ctx); err != nil {
+			c.logger.Error("flush document views failed", "error", err)
+		}
+		if time.Since(lastPurge) > 24*time.Hour {
+			lastPurge = time.Now()
+			if purged, err := c.store.PurgeDocumentViewsBefore(ctx, Today().AddDate(0, 0, -RetentionDays)); err != nil {
+				c.logger.Error("purge document views failed", "error", err)
+			} else if purged > 0 {
+				c.logger.Info("purged document views", "count", purged)
+			}
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// Flush 立即写入累加的浏览量，写入失败的部分留到下次重试；服务关闭前调用以免丢失最后一批计数。
+func (c *Counter) Flush(ctx context.Context) error {
+	c.mu.Lock()
+	pending := c.pending
+	c.pending = make(map[time.Time]map[int64]int64)
+	c.mu.Unlock()
+
+	var errs []error
+	for day, views := range pending {
+		if err := c.store.AddDocumentViews(ctx, day, views); err != nil {
+			errs = append(errs, err)
+			c.mu.Lock()
+			for id, n := range views {
+				c.add(day, id, n)
+			}
+			c.mu.Unlock()
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// add 把 n 次浏览累加到 day 的待写入计数，调用方需持有锁。
+func (c *Counter) add(day time.Time, docID int64, n int64) {
+	counts := c.pending[day]
+	if counts == nil {
+		counts = make(map[int64]int64)
+		c.pending[day] = counts
+	}
+	counts[docID] += n
+}
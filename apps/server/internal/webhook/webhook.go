@@ -0,0 +1,264 @@
+// Package webhook 把文档事件异步投递给管理员注册的外部地址。事件先写入 webhook_deliveries，
+// 再由后台任务逐条 POST；失败时按指数退避重试，投递记录保留在数据库中，服务重启也不会丢失。
+package webhook
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/lifei6671/plaindoc/apps/server/internal/store"
+)
+
+// 支持订阅的事件。
+const (
+	EventDocPublished = "doc.published"
+	EventDocUpdated   = "doc.updated"
+	EventDocDeleted   = "doc.deleted"
+)
+
+// Events 是全部可订阅的事件。
+var Events = []string{EventDocPublished, EventDocUpdated, EventDocDeleted}
+
+// 投递请求头；签名为 "sha256=" 加请求体的 HMAC-SHA256（十六进制），密钥是 webhook 的 secret。
+const (
+	HeaderEvent     = "X-PlainDoc-Event"
+	HeaderDelivery  = "X-PlainDoc-Delivery"
+	HeaderSignature = "X-PlainDoc-Signature"
+)
+
+const (
+	pollInterval    = 5 * time.Second
+	batchSize       = 20
+	requestTimeout  = 10 * time.Second
+	leaseDuration   = time.Minute
+	maxAttempts     = 8
+	firstRetryDelay = 30 * time.Second
+	// deliveryRetention 之后清理已结束的投递记录。
+	deliveryRetention = 30 * 24 * time.Hour
+)
+
+// Payload 是投递请求体。
+type Payload struct {
+	Event      string          `json:"event"`
+	OccurredAt time.Time       `json:"occurred_at"`
+	Document   DocumentPayload `json:"document"`
+}
+
+// DocumentPayload 是事件中的文档元数据，不含正文。
+type DocumentPayload struct {
+	ID        int64     `json:"id"`
+	Space     string    `json:"space"`
+	ParentID  *int64    `json:"parent_id"`
+	Title     string    `json:"title"`
+	Slug      string    `json:"slug"`
+	Version   int       `json:"version"`
+	Status    string    `json:"status"`
+	IsPrivate bool      `json:"is_private"`
+	AuthorID  int64     `json:"author_id"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// Dispatcher 负责写入待投递记录并在后台执行投递。
+type Dispatcher struct {
+	store  *store.Store
+	logger *slog.Logger
+	client *http.Client
+	wake   chan struct{}
+}
+
+func NewDispatcher(s *store.Store, logger *slog.Logger) *Dispatcher {
+	return &Dispatcher{
+		store:  s,
+		logger: logger,
+		client: &http.Client{
+			Timeout: requestTimeout,
+			// 重定向会让签名失去意义，3xx 按失败处理。
+			CheckRedirect: func(req *http.Request, via []*http.Request) error {
+				return http.ErrUseLastResponse
+			},
+		},
+		wake: make(chan struct{}, 1),
+	}
+}
+
+// Sign 计算 body 的签名头取值，接收方用同一个 secret 计算后比较即可验签。
+func Sign(secret string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write(body)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+// PublishDocument 为订阅了 event 的 webhook 写入投递记录并唤醒后台任务，不等待投递结果。
+func (d *Dispatcher) PublishDocument(ctx context.Context, event string, doc *store.Document) error {
+	body, err := json.Marshal(Payload{
+		Event:      event,
+		OccurredAt: time.Now().UTC(),
+		Document: DocumentPayload{
+			ID:        doc.ID,
+			Space:     doc.Space,
+			ParentID:  doc.ParentID,
+			Title:     doc.Title,
+			Slug:      doc.Slug,
+			Version:   doc.Version,
+			Status:    doc.Status,
+			IsPrivate: doc.IsPrivate,
+			AuthorID:  doc.AuthorID,
+			UpdatedAt: doc.UpdatedAt,
+		},
+	})
+	if err != nil {
+		return err
+	}
+	queued, err := d.store.EnqueueWebhookEvent(ctx, event, string(body))
+	if err != nil {
+		return fmt.Errorf("enqueue webhook event %s: %w", event, err)
+	}
+	if queued > 0 {
+		select {
+		case d.wake <- struct{}{}:
+		default:
+		}
+	}
+	return nil
+}
+
+// Run 定期投递到期的记录并清理过期的投递记录，直到 ctx 取消。
+func (d *Dispatcher) Run(ctx context.Context) {
+	ticker := time.NewTicker(pollInterval)
+	defer ticker.Stop()
+	lastPurge := time.Time{}
+
+	for {
+		d.deliverDue(ctx)
+		if time.Since(lastPurge) > time.Hour {
+			lastPurge = time.Now()
+			if purged, err := d.store.PurgeWebhookDeliveriesBefore(ctx, time.Now().UTC().Add(-deliveryRetention)); err != nil {
+				d.logger.Error("purge webhook deliveries failed", "error", err)
+			} else if purged > 0 {
+				d.logger.Info("purged webhook deliveries", "count", purged)
+			}
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		case <-d.wake:
+		}
+	}
+}
+
+// deliverDue 逐批投递到期记录，直到没有到期记录或 ctx 取消。
+func (d *Dispatcher) deliverDue(ctx context.Context) {
+	for ctx.Err() == nil {
+		due, err := d.store.ListDueWebhookDeliveries(ctx, time.Now().UTC(), batchSize)
+		if err != nil {
+			d.logger.Error("list webhook deliveries failed", "error", err)
+			return
+		}
+		if len(due) == 0 {
+			return
+		}
+		webhooks := map[int64]*store.Webhook{}
+		for i := 0; i < len(due) && ctx.Err() == nil; i++ {
+			d.deliver(ctx, &due[i], webhooks)
+		}
+	}
+}
+
+func (d *Dispatcher) deliver(ctx context.Context, delivery *store.WebhookDelivery, webhooks map[int64]*store.Webhook) {
+	claimed, err := d.store.ClaimWebhookDelivery(ctx, delivery, time.Now().UTC().Add(leaseDuration))
+	if err != nil {
+		d.logger.Error("claim webhook delivery failed", "delivery_id", delivery.ID, "error", err)
+		return
+	}
+	if !claimed {
+		return
+	}
+
+	webhook, ok := webhooks[delivery.WebhookID]
+	if !ok {
+		webhook, err = d.store.GetWebhook(ctx, delivery.WebhookID)
+		if err != nil && !errors.Is(err, store.ErrNotFound) {
+			d.logger.Error("load webhook failed", "webhook_id", delivery.WebhookID, "error", err)
+			return
+		}
+		webhooks[delivery.WebhookID] = webhook
+	}
+
+	var status int
+	switch {
+	case webhook == nil:
+		err = errors.New("webhook was deleted")
+	case !webhook.IsActive:
+		err = errors.New("webhook is disabled")
+	default:
+		status, err = d.post(ctx, webhook, delivery)
+	}
+	d.finish(ctx, delivery, webhook, status, err)
+}
+
+// post 发送一次投递请求，返回响应状态码；非 2xx 响应返回错误。
+func (d *Dispatcher) post(ctx context.Context, webhook *store.Webhook, delivery *store.WebhookDelivery) (int, error) {
+	body := []byte(delivery.Payload)
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, webhook.URL, bytes.NewReader(body))
+	if err != nil {
+		return 0, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", "PlainDoc-Webhook")
+	req.Header.Set(HeaderEvent, delivery.Event)
+	req.Header.Set(HeaderDelivery, strconv.FormatInt(delivery.ID, 10))
+	req.Header.Set(HeaderSignature, Sign(webhook.Secret, body))
+
+	resp, err := d.client.Do(req)
+	if err != nil {
+		return 0, err
+	}
+	defer resp.Body.Close()
+	snippet, _ := io.ReadAll(io.LimitReader(resp.Body, 256))
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return resp.StatusCode, fmt.Errorf("unexpected status %s: %s", resp.Status, bytes.TrimSpace(snippet))
+	}
+	return resp.StatusCode, nil
+}
+
+// finish 保存投递结果；失败且未达到重试上限时，下次投递时间为 30s、1m、2m……的指数退避。
+func (d *Dispatcher) finish(ctx context.Context, delivery *store.WebhookDelivery, webhook *store.Webhook, status int, err error) {
+	delivery.ResponseStatus = nil
+	if status != 0 {
+		delivery.ResponseStatus = &status
+	}
+	switch {
+	case err == nil:
+		delivery.Status = store.DeliverySucceeded
+		delivery.LastError = ""
+		delivery.NextAttemptAt = nil
+	case webhook == nil || !webhook.IsActive || delivery.Attempts >= maxAttempts:
+		delivery.Status = store.DeliveryFailed
+		delivery.LastError = store.ErrorText(err)
+		delivery.NextAttemptAt = nil
+		d.logger.Warn("webhook delivery failed", "delivery_id", delivery.ID, "webhook_id", delivery.WebhookID,
+			"event", delivery.Event, "attempts", delivery.Attempts, "error", err)
+	default:
+		next := time.Now().UTC().Add(firstRetryDelay << (delivery.Attempts - 1))
+		delivery.Status = store.DeliveryPending
+		delivery.LastError = store.ErrorText(err)
+		delivery.NextAttemptAt = &next
+	}
+	// 服务关闭时 ctx 已取消，投递结果仍要写入，否则要等租约过期才会重试。
+	if err := d.store.FinishWebhookDeliveryAttempt(context.WithoutCancel(ctx), delivery); err != nil {
+		d.logger.Error("save webhook delivery failed", "delivery_id", delivery.ID, "error", err)
+	}
+}
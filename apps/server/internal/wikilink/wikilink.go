@@ -0,0 +1,140 @@
+// Package wikilink 实现文档间的内部链接语法 [[slug]] 与 [[slug|显示文本]]，链接指向同一空间内的文档。
+//
+// 渲染时只输出不带地址的 <a class="wikilink" data-slug="...">：目标是否存在、地址是什么要在读取时才知道，
+// 因此渲染结果可以按文档缓存，再由 Resolve 按当前的文档数据填上链接或标记为断链。
+package wikilink
+
+import (
+	"bytes"
+	"html"
+	"regexp"
+	"slices"
+	"strings"
+
+	"github.com/lifei6671/plaindoc/apps/server/internal/slug"
+	"github.com/yuin/goldmark"
+	"github.com/yuin/goldmark/ast"
+	"github.com/yuin/goldmark/extension"
+	"github.com/yuin/goldmark/parser"
+	"github.com/yuin/goldmark/renderer"
+	"github.com/yuin/goldmark/text"
+	"github.com/yuin/goldmark/util"
+)
+
+// BrokenClass 是目标文档不存在（或当前用户不可见）的链接额外带上的 class。
+const BrokenClass = "wikilink-broken"
+
+var (
+	KindWikiLink = ast.NewNodeKind("WikiLink")
+
+	// Extension 是注册到 goldmark 的扩展，优先级高于普通链接，[[ 开头的内容先按内部链接解析。
+	Extension goldmark.Extender = extender{}
+
+	// linkPattern 匹配消毒后的渲染结果中尚未解析的内部链接，属性顺序与 renderLink 的输出一致。
+	linkPattern = regexp.MustCompile(`<a class="wikilink" data-slug="([^"]*)">`)
+
+	// extractor 只用于解析，扩展与文档渲染保持一致，保证代码块、表格中的解析结果相同。
+	extractor = goldmark.New(goldmark.WithExtensions(extension.GFM, Extension))
+)
+
+// Node 是正文中的一个内部链接。
+type Node struct {
+	ast.BaseInline
+	Slug  string
+	Label string
+}
+
+func (n *Node) Kind() ast.NodeKind {
+	return KindWikiLink
+}
+
+func (n *Node) Dump(source []byte, level int) {
+	ast.DumpHelper(n, source, level, map[string]string{"Slug": n.Slug, "Label": n.Label}, nil)
+}
+
+type extender struct{}
+
+func (extender) Extend(m goldmark.Markdown) {
+	m.Parser().AddOptions(parser.WithInlineParsers(util.Prioritized(inlineParser{}, 199)))
+	m.Renderer().AddOptions(renderer.WithNodeRenderers(util.Prioritized(nodeRenderer{}, 500)))
+}
+
+type inlineParser struct{}
+
+func (inlineParser) Trigger() []byte {
+	return []byte{'['}
+}
+
+// Parse 解析同一行内的 [[slug]] 或 [[slug|text]]；slug 不区分大小写，不是合法 slug 时按普通文本处理。
+func (inlineParser) Parse(parent ast.Node, block text.Reader, pc parser.Context) ast.Node {
+	line, _ := block.PeekLine()
+	if !bytes.HasPrefix(line, []byte("[[")) {
+		return nil
+	}
+	end := bytes.Index(line[2:], []byte("]]"))
+	if end < 0 {
+		return nil
+	}
+	target, label, _ := strings.Cut(string(line[2:2+end]), "|")
+	target = strings.ToLower(strings.TrimSpace(target))
+	if slug.Validate(target) != nil {
+		return nil
+	}
+	label = strings.TrimSpace(label)
+	if label == "" {
+		label = target
+	}
+	block.Advance(2 + end + 2)
+	return &Node{Slug: target, Label: label}
+}
+
+type nodeRenderer struct{}
+
+func (nodeRenderer) RegisterFuncs(reg renderer.NodeRendererFuncRegisterer) {
+	reg.Register(KindWikiLink, renderLink)
+}
+
+func renderLink(w util.BufWriter, source []byte, node ast.Node, entering bool) (ast.WalkStatus, error) {
+	if !entering {
+		return ast.WalkContinue, nil
+	}
+	link := node.(*Node)
+	_, _ = w.WriteString(`<a class="wikilink" data-slug="` + html.EscapeString(link.Slug) + `">` + html.EscapeString(link.Label) + `</a>`)
+	return ast.WalkSkipChildren, nil
+}
+
+// Extract 返回 Markdown 正文中引用的全部 slug（去重，按首次出现的顺序），代码块与行内代码中的不算。
+func Extract(source []byte) []string {
+	doc := extractor.Parser().Parse(text.NewReader(source))
+	var slugs []string
+	_ = ast.Walk(doc, func(node ast.Node, entering bool) (ast.WalkStatus, error) {
+		if link, ok := node.(*Node); ok && entering && !slices.Contains(slugs, link.Slug) {
+			slugs = append(slugs, link.Slug)
+		}
+		return ast.WalkContinue, nil
+	})
+	return slugs
+}
+
+// Slugs 返回渲染结果中尚未解析的内部链接的 slug（去重）。
+func Slugs(rendered string) []string {
+	var slugs []string
+	for _, match := range linkPattern.FindAllStringSubmatch(rendered, -1) {
+		if value := html.UnescapeString(match[1]); !slices.Contains(slugs, value) {
+			slugs = append(slugs, value)
+		}
+	}
+	return slugs
+}
+
+// Resolve 给渲染结果中的内部链接填上地址：href 返回 false 时表示目标不存在，链接加上 BrokenClass 且不带地址。
+func Resolve(rendered string, href func(slug string) (string, bool)) string {
+	return linkPattern.ReplaceAllStringFunc(rendered, func(tag string) string {
+		value := linkPattern.FindStringSubmatch(tag)[1]
+		target, ok := href(html.UnescapeString(value))
+		if !ok {
+			return `<a class="wikilink ` + BrokenClass + `" data-slug="` + value + `">`
+		}
+		return `<a class="wikilink" data-slug="` + value + `" href="` + html.EscapeString(target) + `">`
+	})
+}
@@ -19,7 +19,7 @@ export function getDataGateway(): DataGateway {
 
   const driver = resolveDriver();
   if (driver === "http") {
-    const baseUrl = import.meta.env.VITE_API_BASE_URL ?? "/api";
+    const baseUrl = import.meta.env.VITE_API_BASE_URL ?? "/api/v1";
     singletonGateway = createHttpAdapter({ baseUrl });
     return singletonGateway;
   }